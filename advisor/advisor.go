@@ -0,0 +1,321 @@
+// Package advisor analyzes the shape of an index and suggests tuning changes.
+package advisor
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// Priority ranks how much a recommendation is expected to help.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityMedium
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// MarshalText encodes the priority as its name in JSON output.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Thresholds used to decide when a recommendation is worth emitting.
+const (
+	chunksPerFileThreshold = 8.0  // average chunks per file for an extension
+	ignoreShareThreshold   = 0.05 // share of chunks in a build/vendor directory
+	overlapRatioThreshold  = 0.25 // share of chunk lines duplicated by overlap
+)
+
+// suspiciousDirs are directory names that usually hold build output or
+// third-party code that is rarely useful in search results.
+var suspiciousDirs = map[string]bool{
+	"dist": true, "build": true, "out": true, "target": true,
+	"vendor": true, "node_modules": true, "coverage": true,
+	"generated": true, ".next": true, ".nuxt": true, "third_party": true,
+}
+
+// Input is the index data the advisor works on.
+type Input struct {
+	Files          []store.FileStats
+	Chunks         []store.Chunk
+	Projects       []store.ProjectInfo
+	CurrentProject string // project ID of the current project
+	Boost          config.BoostConfig
+	ChunkSize      int
+	Overlap        int
+	// PathExists reports whether a project root still exists on disk. It is
+	// only asked about project IDs that are absolute paths.
+	PathExists func(string) bool
+}
+
+// Recommendation is a single actionable suggestion.
+type Recommendation struct {
+	Priority Priority `json:"priority"`
+	Category string   `json:"category"`
+	Message  string   `json:"message"`
+	Action   string   `json:"action,omitempty"`
+}
+
+// ExtensionStats summarizes chunk distribution for one file extension.
+type ExtensionStats struct {
+	Extension     string  `json:"extension"`
+	Files         int     `json:"files"`
+	Chunks        int     `json:"chunks"`
+	ChunksPerFile float64 `json:"chunks_per_file"`
+}
+
+// RuleStats records how many indexed files a boost rule applies to.
+type RuleStats struct {
+	Pattern      string  `json:"pattern"`
+	Factor       float32 `json:"factor"`
+	MatchedFiles int     `json:"matched_files"`
+}
+
+// Report is the result of analyzing an index.
+type Report struct {
+	TotalFiles      int              `json:"total_files"`
+	TotalChunks     int              `json:"total_chunks"`
+	ChunksPerFile   float64          `json:"chunks_per_file"`
+	MaxChunksFile   string           `json:"max_chunks_file,omitempty"`
+	MaxChunks       int              `json:"max_chunks"`
+	OverlapRatio    float64          `json:"overlap_ratio"`
+	Extensions      []ExtensionStats `json:"extensions"`
+	BoostRules      []RuleStats      `json:"boost_rules,omitempty"`
+	DeadProjects    []string         `json:"dead_projects,omitempty"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// Analyze inspects the index and returns statistics with prioritized
+// recommendations, highest priority first.
+func Analyze(in Input) *Report {
+	r := &Report{
+		TotalFiles:      len(in.Files),
+		Recommendations: []Recommendation{},
+	}
+
+	for _, f := range in.Files {
+		r.TotalChunks += f.ChunkCount
+		if f.ChunkCount > r.MaxChunks {
+			r.MaxChunks = f.ChunkCount
+			r.MaxChunksFile = f.Path
+		}
+	}
+	if r.TotalFiles > 0 {
+		r.ChunksPerFile = float64(r.TotalChunks) / float64(r.TotalFiles)
+	}
+
+	r.analyzeExtensions(in)
+	r.analyzeDirectories(in)
+	r.analyzeOverlap(in)
+	r.analyzeBoostRules(in)
+	r.analyzeProjects(in)
+
+	sort.SliceStable(r.Recommendations, func(i, j int) bool {
+		return r.Recommendations[i].Priority > r.Recommendations[j].Priority
+	})
+
+	return r
+}
+
+// analyzeExtensions flags extensions whose files produce many chunks each.
+func (r *Report) analyzeExtensions(in Input) {
+	byExt := make(map[string]*ExtensionStats)
+	for _, f := range in.Files {
+		ext := strings.ToLower(filepath.Ext(f.Path))
+		if ext == "" {
+			ext = "(none)"
+		}
+		es, ok := byExt[ext]
+		if !ok {
+			es = &ExtensionStats{Extension: ext}
+			byExt[ext] = es
+		}
+		es.Files++
+		es.Chunks += f.ChunkCount
+	}
+
+	for _, es := range byExt {
+		es.ChunksPerFile = float64(es.Chunks) / float64(es.Files)
+		r.Extensions = append(r.Extensions, *es)
+	}
+	sort.Slice(r.Extensions, func(i, j int) bool {
+		if r.Extensions[i].Chunks != r.Extensions[j].Chunks {
+			return r.Extensions[i].Chunks > r.Extensions[j].Chunks
+		}
+		return r.Extensions[i].Extension < r.Extensions[j].Extension
+	})
+
+	for _, es := range r.Extensions {
+		if es.ChunksPerFile < chunksPerFileThreshold || r.TotalChunks == 0 {
+			continue
+		}
+		share := float64(es.Chunks) / float64(r.TotalChunks)
+		priority := PriorityMedium
+		if share >= 0.25 {
+			priority = PriorityHigh
+		}
+		r.Recommendations = append(r.Recommendations, Recommendation{
+			Priority: priority,
+			Category: "chunking",
+			Message: fmt.Sprintf("raise chunk size for %s: %.1f chunks per file (%s of chunks)",
+				es.Extension, es.ChunksPerFile, percent(share)),
+			Action: fmt.Sprintf("increase index.chunking.size (currently %d) or ignore large %s files", in.ChunkSize, es.Extension),
+		})
+	}
+}
+
+// analyzeDirectories flags build/vendor directories that hold a noticeable
+// share of the index.
+func (r *Report) analyzeDirectories(in Input) {
+	if r.TotalChunks == 0 {
+		return
+	}
+
+	byDir := make(map[string]int)
+	for _, f := range in.Files {
+		dirs := strings.Split(path.Dir(filepath.ToSlash(f.Path)), "/")
+		for i, d := range dirs {
+			if suspiciousDirs[d] {
+				byDir[strings.Join(dirs[:i+1], "/")] += f.ChunkCount
+				break
+			}
+		}
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for d := range byDir {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if byDir[dirs[i]] != byDir[dirs[j]] {
+			return byDir[dirs[i]] > byDir[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	for _, d := range dirs {
+		share := float64(byDir[d]) / float64(r.TotalChunks)
+		if share < ignoreShareThreshold {
+			continue
+		}
+		priority := PriorityMedium
+		if share >= 0.2 {
+			priority = PriorityHigh
+		}
+		r.Recommendations = append(r.Recommendations, Recommendation{
+			Priority: priority,
+			Category: "ignore",
+			Message:  fmt.Sprintf("add %s/ to ignore: %s of chunks", d, percent(share)),
+			Action:   fmt.Sprintf("add %q to index.ignore in .agentdx/config.yaml", path.Base(d)),
+		})
+	}
+}
+
+// analyzeOverlap measures how many chunk lines are duplicated by overlap
+// between consecutive chunks of the same file.
+func (r *Report) analyzeOverlap(in Input) {
+	byFile := make(map[string][]store.Chunk)
+	for _, c := range in.Chunks {
+		byFile[c.FilePath] = append(byFile[c.FilePath], c)
+	}
+
+	totalLines, overlapLines := 0, 0
+	for _, chunks := range byFile {
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i].StartLine < chunks[j].StartLine })
+		for i, c := range chunks {
+			totalLines += c.EndLine - c.StartLine + 1
+			if i > 0 && chunks[i-1].EndLine >= c.StartLine {
+				overlapLines += chunks[i-1].EndLine - c.StartLine + 1
+			}
+		}
+	}
+	if totalLines == 0 {
+		return
+	}
+
+	r.OverlapRatio = float64(overlapLines) / float64(totalLines)
+	if r.OverlapRatio >= overlapRatioThreshold {
+		r.Recommendations = append(r.Recommendations, Recommendation{
+			Priority: PriorityMedium,
+			Category: "chunking",
+			Message:  fmt.Sprintf("%s of chunk lines are duplicated by overlap", percent(r.OverlapRatio)),
+			Action:   fmt.Sprintf("reduce index.chunking.overlap (currently %d)", in.Overlap),
+		})
+	}
+}
+
+// analyzeBoostRules reports boost rules that never apply to indexed files.
+func (r *Report) analyzeBoostRules(in Input) {
+	if !in.Boost.Enabled || len(in.Files) == 0 {
+		return
+	}
+
+	var unused []string
+	rules := append(append([]config.BoostRule{}, in.Boost.Penalties...), in.Boost.Bonuses...)
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			continue // kind and recency rules match any path
+		}
+		stats := RuleStats{Pattern: rule.Pattern, Factor: rule.Factor}
+		for _, f := range in.Files {
+			if strings.Contains(f.Path, rule.Pattern) {
+				stats.MatchedFiles++
+			}
+		}
+		r.BoostRules = append(r.BoostRules, stats)
+		if stats.MatchedFiles == 0 {
+			unused = append(unused, rule.Pattern)
+		}
+	}
+
+	if len(unused) > 0 {
+		r.Recommendations = append(r.Recommendations, Recommendation{
+			Priority: PriorityLow,
+			Category: "boost",
+			Message:  fmt.Sprintf("%d boost rule(s) match no indexed files: %s", len(unused), strings.Join(unused, ", ")),
+			Action:   "remove or adjust these patterns under index.search.boost",
+		})
+	}
+}
+
+// analyzeProjects reports project IDs whose root no longer exists on disk.
+// IDs that are not paths, as the uuid and git strategies make, are skipped.
+func (r *Report) analyzeProjects(in Input) {
+	if in.PathExists == nil {
+		return
+	}
+	for _, p := range in.Projects {
+		if p.ID == in.CurrentProject || !filepath.IsAbs(p.ID) || in.PathExists(p.ID) {
+			continue
+		}
+		r.DeadProjects = append(r.DeadProjects, p.ID)
+		r.Recommendations = append(r.Recommendations, Recommendation{
+			Priority: PriorityLow,
+			Category: "projects",
+			Message:  fmt.Sprintf("project %s no longer exists on disk (%d files indexed)", p.ID, p.FileCount),
+			Action:   "delete its rows from the shared database to reclaim space",
+		})
+	}
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
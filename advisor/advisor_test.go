@@ -0,0 +1,122 @@
+package advisor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func hasRecommendation(r *Report, substr string) bool {
+	for _, rec := range r.Recommendations {
+		if strings.Contains(rec.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	r := Analyze(Input{})
+	if r.TotalFiles != 0 || r.TotalChunks != 0 {
+		t.Errorf("expected empty report, got %+v", r)
+	}
+	if len(r.Recommendations) != 0 {
+		t.Errorf("expected no recommendations, got %v", r.Recommendations)
+	}
+}
+
+func TestAnalyze_ChunksPerExtension(t *testing.T) {
+	r := Analyze(Input{
+		Files: []store.FileStats{
+			{Path: "data/big.json", ChunkCount: 40},
+			{Path: "main.go", ChunkCount: 2},
+			{Path: "util.go", ChunkCount: 3},
+		},
+		ChunkSize: 512,
+	})
+
+	if r.TotalChunks != 45 {
+		t.Errorf("expected 45 chunks, got %d", r.TotalChunks)
+	}
+	if r.MaxChunksFile != "data/big.json" {
+		t.Errorf("expected max chunks file data/big.json, got %s", r.MaxChunksFile)
+	}
+	if !hasRecommendation(r, "raise chunk size for .json") {
+		t.Errorf("expected .json chunk size recommendation, got %v", r.Recommendations)
+	}
+	if hasRecommendation(r, "raise chunk size for .go") {
+		t.Error("did not expect .go recommendation")
+	}
+	if r.Recommendations[0].Priority != PriorityHigh {
+		t.Errorf("expected high priority first, got %s", r.Recommendations[0].Priority)
+	}
+}
+
+func TestAnalyze_SuspiciousDirectories(t *testing.T) {
+	r := Analyze(Input{
+		Files: []store.FileStats{
+			{Path: "web/dist/app.js", ChunkCount: 34},
+			{Path: "web/src/app.ts", ChunkCount: 66},
+		},
+	})
+
+	if !hasRecommendation(r, "add web/dist/ to ignore: 34% of chunks") {
+		t.Errorf("expected dist ignore recommendation, got %v", r.Recommendations)
+	}
+}
+
+func TestAnalyze_OverlapRatio(t *testing.T) {
+	r := Analyze(Input{
+		Chunks: []store.Chunk{
+			{FilePath: "a.go", StartLine: 1, EndLine: 10},
+			{FilePath: "a.go", StartLine: 6, EndLine: 15},
+			{FilePath: "b.go", StartLine: 1, EndLine: 10},
+		},
+		Overlap: 50,
+	})
+
+	// 5 overlapping lines out of 30
+	if r.OverlapRatio < 0.16 || r.OverlapRatio > 0.17 {
+		t.Errorf("expected overlap ratio ~0.167, got %f", r.OverlapRatio)
+	}
+	if hasRecommendation(r, "duplicated by overlap") {
+		t.Error("did not expect overlap recommendation below threshold")
+	}
+}
+
+func TestAnalyze_UnusedBoostRules(t *testing.T) {
+	r := Analyze(Input{
+		Files: []store.FileStats{{Path: "pkg/foo_test.go", ChunkCount: 1}},
+		Boost: config.BoostConfig{
+			Enabled:   true,
+			Penalties: []config.BoostRule{{Pattern: "_test.", Factor: 0.5}, {Pattern: "/mocks/", Factor: 0.4}},
+		},
+	})
+
+	if len(r.BoostRules) != 2 || r.BoostRules[0].MatchedFiles != 1 {
+		t.Errorf("unexpected rule stats: %+v", r.BoostRules)
+	}
+	if !hasRecommendation(r, "/mocks/") {
+		t.Errorf("expected unused rule recommendation, got %v", r.Recommendations)
+	}
+}
+
+func TestAnalyze_DeadProjects(t *testing.T) {
+	r := Analyze(Input{
+		Projects: []store.ProjectInfo{
+			{ID: "/current", FileCount: 10},
+			{ID: "/alive", FileCount: 5},
+			{ID: "/gone", FileCount: 7},
+			{ID: "3f2b9c1e-8d4a-4e7b-9a6c-2d1e0f5b7c3a", FileCount: 4},
+			{ID: "github.com/acme/app", FileCount: 3},
+		},
+		CurrentProject: "/current",
+		PathExists:     func(p string) bool { return p == "/alive" },
+	})
+
+	if len(r.DeadProjects) != 1 || r.DeadProjects[0] != "/gone" {
+		t.Errorf("expected /gone as dead project, got %v", r.DeadProjects)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doveaia/agentdx/advisor"
+	"github.com/doveaia/agentdx/config"
+	"github.com/spf13/cobra"
+)
+
+var adviseJSON bool
+
+var adviseCmd = &cobra.Command{
+	Use:   "advise",
+	Short: "Analyze the index and recommend tuning changes",
+	Long: `Analyze the index and print prioritized recommendations.
+
+The advisor looks at:
+- Chunks per file, grouped by extension
+- Build and vendor directories that dominate the index
+- How much content is duplicated by chunk overlap
+- Boost rules that never match an indexed file
+- Projects in the shared database whose root no longer exists`,
+	RunE: runAdvise,
+}
+
+func init() {
+	adviseCmd.Flags().BoolVar(&adviseJSON, "json", false, "Output report in JSON format")
+	rootCmd.AddCommand(adviseCmd)
+}
+
+func runAdvise(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	st, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+
+	files, err := st.ListFilesWithStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	chunks, err := st.GetAllChunks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chunks: %w", err)
+	}
+
+	projects, err := st.GetAllProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	report := advisor.Analyze(advisor.Input{
+		Files:          files,
+		Chunks:         chunks,
+		Projects:       projects,
+		CurrentProject: cfg.ProjectID(projectRoot),
+		Boost:          cfg.Index.Search.Boost,
+		ChunkSize:      cfg.Index.Chunking.Size,
+		Overlap:        cfg.Index.Chunking.Overlap,
+		PathExists: func(p string) bool {
+			_, err := os.Stat(p)
+			return err == nil
+		},
+	})
+
+	if adviseJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	displayAdviseReport(report)
+	return nil
+}
+
+func displayAdviseReport(r *advisor.Report) {
+	fmt.Println("Index summary")
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("Files:            %d\n", r.TotalFiles)
+	fmt.Printf("Chunks:           %d (%.1f per file)\n", r.TotalChunks, r.ChunksPerFile)
+	if r.MaxChunksFile != "" {
+		fmt.Printf("Largest file:     %s (%d chunks)\n", r.MaxChunksFile, r.MaxChunks)
+	}
+	fmt.Printf("Overlap ratio:    %.0f%%\n", r.OverlapRatio*100)
+
+	if len(r.Extensions) > 0 {
+		fmt.Println("\nTop extensions:")
+		for i, es := range r.Extensions {
+			if i >= 5 {
+				break
+			}
+			fmt.Printf("  %-10s %6d files %8d chunks %6.1f/file\n", es.Extension, es.Files, es.Chunks, es.ChunksPerFile)
+		}
+	}
+
+	fmt.Printf("\nRecommendations (%d):\n", len(r.Recommendations))
+	fmt.Println(strings.Repeat("-", 60))
+	if len(r.Recommendations) == 0 {
+		fmt.Println("No recommendations. The index looks well tuned.")
+		return
+	}
+	for i, rec := range r.Recommendations {
+		fmt.Printf("%d. [%s] %s\n", i+1, rec.Priority, rec.Message)
+		if rec.Action != "" {
+			fmt.Printf("   → %s\n", rec.Action)
+		}
+	}
+}
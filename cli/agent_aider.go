@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// aiderConfigPath is the Aider configuration file, whose read list makes
+	// Aider load the agentdx instructions
+	aiderConfigPath = ".aider.conf.yml"
+	// aiderConventionsPath holds the instructions, following Aider's
+	// convention for coding guidelines
+	aiderConventionsPath = "CONVENTIONS.md"
+	// aiderReadMarker is the line comment marking the read entry agentdx added
+	aiderReadMarker = "# agentdx"
+)
+
+// addAiderRead adds CONVENTIONS.md to the files .aider.conf.yml makes Aider
+// read, creating the file when needed. The YAML is edited as a node tree so
+// that the user's settings and comments are kept. It returns "create" or
+// "update", or "" when CONVENTIONS.md was already read.
+func addAiderRead(cwd string) (string, error) {
+	path := filepath.Join(cwd, aiderConfigPath)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	action := "update"
+	if os.IsNotExist(err) {
+		action = "create"
+	}
+
+	doc, root, err := parseAiderConfig(data)
+	if err != nil {
+		return "", err
+	}
+	entry := &yaml.Node{Kind: yaml.ScalarNode, Value: aiderConventionsPath, LineComment: aiderReadMarker}
+	_, read := mappingEntry(root, "read")
+	switch {
+	case read == nil:
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "read"},
+			&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{entry}})
+	case read.Kind == yaml.ScalarNode:
+		if read.Value == aiderConventionsPath {
+			return "", nil
+		}
+		existing := *read
+		*read = yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{&existing, entry}}
+	case read.Kind == yaml.SequenceNode:
+		for _, item := range read.Content {
+			if item.Value == aiderConventionsPath {
+				return "", nil
+			}
+		}
+		// The marker comment needs one entry per line
+		read.Style = 0
+		read.Content = append(read.Content, entry)
+	default:
+		return "", fmt.Errorf("read must be a file name or a list of file names")
+	}
+
+	if err := writeAiderConfig(path, doc); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// removeAiderRead removes the read entry added by addAiderRead, deleting
+// .aider.conf.yml when nothing else is configured in it. It reports whether
+// the file changed.
+func removeAiderRead(cwd string) (bool, error) {
+	path := filepath.Join(cwd, aiderConfigPath)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	doc, root, err := parseAiderConfig(data)
+	if err != nil {
+		return false, err
+	}
+
+	added := func(n *yaml.Node) bool {
+		return n.Kind == yaml.ScalarNode && n.Value == aiderConventionsPath && strings.Contains(n.LineComment, aiderReadMarker)
+	}
+	i, read := mappingEntry(root, "read")
+	switch {
+	case read == nil:
+		return false, nil
+	case read.Kind == yaml.SequenceNode:
+		kept := make([]*yaml.Node, 0, len(read.Content))
+		for _, item := range read.Content {
+			if !added(item) {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) == len(read.Content) {
+			return false, nil
+		}
+		read.Content = kept
+		if len(kept) == 0 {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		}
+	case added(read):
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+	default:
+		return false, nil
+	}
+
+	if len(root.Content) == 0 {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove file: %w", err)
+		}
+		fmt.Printf("  [remove] %s\n", aiderConfigPath)
+		return true, nil
+	}
+	if err := writeAiderConfig(path, doc); err != nil {
+		return false, err
+	}
+	fmt.Printf("  [update] %s (stopped reading %s)\n", aiderConfigPath, aiderConventionsPath)
+	return true, nil
+}
+
+// parseAiderConfig parses .aider.conf.yml into its document node and top
+// level mapping. Empty files yield an empty mapping.
+func parseAiderConfig(data []byte) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("expected a mapping of settings")
+	}
+	return &doc, root, nil
+}
+
+// mappingEntry returns the index of key in a mapping node and its value
+// node, or nil when the key is absent.
+func mappingEntry(mapping *yaml.Node, key string) (int, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i, mapping.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+// writeAiderConfig writes the document node to path.
+func writeAiderConfig(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
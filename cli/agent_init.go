@@ -1,18 +1,25 @@
 package cli
 
 import (
-	"embed"
+	"bufio"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
-)
+	"unicode"
+
+	"github.com/charmbracelet/x/term"
 
-//go:embed templates/agents/*
-var agentTemplates embed.FS
+	"github.com/doveaia/agentdx/errcode"
+)
 
 // AgentConfig represents a coding agent configuration
 type AgentConfig struct {
+	ID          string // Name used by --agent
 	Name        string
 	Description string
 	Files       []AgentFile
@@ -21,15 +28,17 @@ type AgentConfig struct {
 
 // AgentFile represents a file to be generated for an agent
 type AgentFile struct {
-	TemplateName string // Name in embedded templates
+	TemplateName string // Name in templates/agents, see readTemplate
 	DestPath     string // Destination path relative to project root
 	Description  string // Human-readable description
+	Shared       bool   // May hold the user's own content; removal only strips the agentdx part
 }
 
 // SupportedAgentConfigs returns all supported coding agent configurations
 func SupportedAgentConfigs() []AgentConfig {
 	return []AgentConfig{
 		{
+			ID:          "claude",
 			Name:        "Claude Code",
 			Description: "Anthropic's CLI coding assistant",
 			Directories: []string{
@@ -41,13 +50,14 @@ func SupportedAgentConfigs() []AgentConfig {
 				".claude/hooks/agentdx/stop",
 			},
 			Files: []AgentFile{
-				{TemplateName: "CLAUDE.md", DestPath: "CLAUDE.md", Description: "Main instructions"},
+				{TemplateName: "CLAUDE.md", DestPath: "CLAUDE.md", Description: "Main instructions", Shared: true},
 				{TemplateName: "claude_settings.json", DestPath: ".claude/settings.json", Description: "Hook configuration"},
 				{TemplateName: "claude_rules_agentdx.md", DestPath: ".claude/rules/agentdx.md", Description: "Search rules"},
 				{TemplateName: "claude_agents_deep-explore.md", DestPath: ".claude/agents/deep-explore.md", Description: "Deep explore subagent"},
 			},
 		},
 		{
+			ID:          "cursor",
 			Name:        "Cursor",
 			Description: "Cursor AI editor",
 			Directories: []string{
@@ -55,11 +65,12 @@ func SupportedAgentConfigs() []AgentConfig {
 				".cursor/rules",
 			},
 			Files: []AgentFile{
-				{TemplateName: "cursorrules", DestPath: ".cursorrules", Description: "Legacy rules (deprecated)"},
+				{TemplateName: "cursorrules", DestPath: ".cursorrules", Description: "Legacy rules (deprecated)", Shared: true},
 				{TemplateName: "cursor_rules_agentdx.mdc", DestPath: ".cursor/rules/agentdx.mdc", Description: "MDC rules (recommended)"},
 			},
 		},
 		{
+			ID:          "windsurf",
 			Name:        "Windsurf",
 			Description: "Codeium Windsurf editor",
 			Directories: []string{
@@ -67,11 +78,12 @@ func SupportedAgentConfigs() []AgentConfig {
 				".windsurf/rules",
 			},
 			Files: []AgentFile{
-				{TemplateName: "windsurfrules", DestPath: ".windsurfrules", Description: "Main rules"},
+				{TemplateName: "windsurfrules", DestPath: ".windsurfrules", Description: "Main rules", Shared: true},
 				{TemplateName: "windsurf_rules_agentdx.md", DestPath: ".windsurf/rules/agentdx.md", Description: "Workspace rules"},
 			},
 		},
 		{
+			ID:          "codex",
 			Name:        "Codex CLI / GitHub Copilot",
 			Description: "OpenAI Codex CLI and GitHub Copilot",
 			Directories: []string{
@@ -79,29 +91,114 @@ func SupportedAgentConfigs() []AgentConfig {
 				".github/instructions",
 			},
 			Files: []AgentFile{
-				{TemplateName: "AGENTS.md", DestPath: "AGENTS.md", Description: "Agent instructions"},
-				{TemplateName: "copilot-instructions.md", DestPath: ".github/copilot-instructions.md", Description: "Copilot instructions"},
+				{TemplateName: "AGENTS.md", DestPath: "AGENTS.md", Description: "Agent instructions", Shared: true},
+				{TemplateName: "copilot-instructions.md", DestPath: ".github/copilot-instructions.md", Description: "Copilot instructions", Shared: true},
 				{TemplateName: "github_instructions_agentdx.md", DestPath: ".github/instructions/agentdx.instructions.md", Description: "Additional instructions"},
 			},
 		},
 		{
+			ID:          "gemini",
 			Name:        "Gemini",
 			Description: "Google Gemini CLI and Code Assist",
 			Directories: []string{
 				".gemini",
 			},
 			Files: []AgentFile{
-				{TemplateName: "GEMINI.md", DestPath: "GEMINI.md", Description: "Main instructions"},
+				{TemplateName: "GEMINI.md", DestPath: "GEMINI.md", Description: "Main instructions", Shared: true},
+			},
+		},
+		{
+			ID:          "jetbrains",
+			Name:        "JetBrains AI Assistant",
+			Description: "AI Assistant and Junie in JetBrains IDEs",
+			Directories: []string{
+				".aiassistant",
+				".aiassistant/rules",
+			},
+			Files: []AgentFile{
+				{TemplateName: "aiassistant_rules_agentdx.md", DestPath: ".aiassistant/rules/agentdx.md", Description: "Project rules"},
 			},
 		},
+		{
+			ID:          "zed",
+			Name:        "Zed",
+			Description: "Zed editor agent",
+			Files: []AgentFile{
+				{TemplateName: "zed_rules", DestPath: ".rules", Description: "Project rules", Shared: true},
+			},
+		},
+		{
+			ID:          "aider",
+			Name:        "Aider",
+			Description: "Aider pair programming CLI",
+			Files: []AgentFile{
+				{TemplateName: "CONVENTIONS.md", DestPath: aiderConventionsPath, Description: "Coding conventions", Shared: true},
+				{DestPath: aiderConfigPath, Description: "Reads CONVENTIONS.md", Shared: true},
+			},
+		},
+	}
+}
+
+// ResolveAgentConfigs returns the configurations of the agents with the
+// given IDs, in the order they are supported.
+func ResolveAgentConfigs(ids []string) ([]AgentConfig, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[strings.ToLower(strings.TrimSpace(id))] = true
+	}
+
+	var agents []AgentConfig
+	for _, agent := range SupportedAgentConfigs() {
+		if wanted[agent.ID] {
+			agents = append(agents, agent)
+			delete(wanted, agent.ID)
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := slices.Sorted(maps.Keys(wanted))
+		return nil, errcode.New(errcode.InvalidArgs, "unknown agent %q (supported: %s)", unknown[0], strings.Join(agentIDs(SupportedAgentConfigs()), ", "))
+	}
+	return agents, nil
+}
+
+// agentIDs returns the IDs of agents.
+func agentIDs(agents []AgentConfig) []string {
+	ids := make([]string, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.ID
+	}
+	return ids
+}
+
+// agentNames returns the names of agents, comma separated.
+func agentNames(agents []AgentConfig) string {
+	names := make([]string, len(agents))
+	for i, agent := range agents {
+		names[i] = agent.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// hasAgent reports whether agents include the agent with id.
+func hasAgent(agents []AgentConfig, id string) bool {
+	for _, agent := range agents {
+		if agent.ID == id {
+			return true
+		}
 	}
+	return false
 }
 
 // GenerateAgentConfigs creates configuration files for all supported coding agents
 func GenerateAgentConfigs(cwd string) error {
+	return GenerateAgentConfigsFor(cwd, SupportedAgentConfigs())
+}
+
+// GenerateAgentConfigsFor creates configuration files for the given coding
+// agents only
+func GenerateAgentConfigsFor(cwd string, agents []AgentConfig) error {
 	fmt.Println("\nGenerating coding agent configurations...")
 
-	agents := SupportedAgentConfigs()
 	totalFiles := 0
 	createdFiles := 0
 	skippedFiles := 0
@@ -122,6 +219,22 @@ func GenerateAgentConfigs(cwd string) error {
 			totalFiles++
 			destPath := filepath.Join(cwd, file.DestPath)
 
+			// The Aider configuration is YAML shared with the user's settings
+			if file.DestPath == aiderConfigPath {
+				action, err := addAiderRead(cwd)
+				switch {
+				case err != nil:
+					fmt.Printf("  [warn] %s: %v\n", file.DestPath, err)
+				case action == "":
+					fmt.Printf("  [skip] %s (already configured)\n", file.DestPath)
+					skippedFiles++
+				default:
+					fmt.Printf("  [%s] %s\n", action, file.DestPath)
+					createdFiles++
+				}
+				continue
+			}
+
 			// Check if file already exists
 			if _, err := os.Stat(destPath); err == nil {
 				// File exists - check if it already has agentdx content
@@ -133,7 +246,7 @@ func GenerateAgentConfigs(cwd string) error {
 				}
 
 				// File exists but doesn't have agentdx - we'll update it
-				if err := updateAgentFile(destPath, file.TemplateName); err != nil {
+				if err := updateAgentFile(cwd, destPath, file.TemplateName); err != nil {
 					fmt.Printf("  [warn] %s: %v\n", file.DestPath, err)
 					continue
 				}
@@ -142,8 +255,13 @@ func GenerateAgentConfigs(cwd string) error {
 				continue
 			}
 
-			// File doesn't exist - create it
-			if err := createAgentFile(destPath, file.TemplateName); err != nil {
+			// File doesn't exist - create it, marking the agentdx section of
+			// files the user may add to
+			create := createAgentFile
+			if file.Shared {
+				create = updateAgentFile
+			}
+			if err := create(cwd, destPath, file.TemplateName); err != nil {
 				fmt.Printf("  [warn] %s: %v\n", file.DestPath, err)
 				continue
 			}
@@ -153,19 +271,22 @@ func GenerateAgentConfigs(cwd string) error {
 	}
 
 	// Install Claude Code session hooks
-	if err := installClaudeSessionHooks(cwd); err != nil {
-		fmt.Printf("\n[warn] Could not install session hooks: %v\n", err)
+	if hasAgent(agents, "claude") {
+		if err := installClaudeSessionHooks(cwd); err != nil {
+			fmt.Printf("\n[warn] Could not install session hooks: %v\n", err)
+		}
 	}
 
 	fmt.Printf("\nAgent configurations: %d created, %d skipped, %d total\n", createdFiles, skippedFiles, totalFiles)
 	return nil
 }
 
-// createAgentFile creates a new agent configuration file from a template
-func createAgentFile(destPath, templateName string) error {
-	content, err := agentTemplates.ReadFile("templates/agents/" + templateName)
+// createAgentFile creates a new agent configuration file from a template,
+// read with the overrides of the project at root
+func createAgentFile(root, destPath, templateName string) error {
+	content, err := readTemplate(root, "agents/"+templateName)
 	if err != nil {
-		return fmt.Errorf("template not found: %w", err)
+		return err
 	}
 
 	// Determine file permissions based on extension
@@ -174,39 +295,44 @@ func createAgentFile(destPath, templateName string) error {
 		perm = 0755
 	}
 
-	return os.WriteFile(destPath, content, perm)
+	return os.WriteFile(destPath, []byte(content), perm)
 }
 
-// updateAgentFile appends or prepends agentdx content to an existing file
-func updateAgentFile(destPath, templateName string) error {
+// updateAgentFile appends or prepends the agentdx section of a template to a
+// file, creating it when it does not exist
+func updateAgentFile(root, destPath, templateName string) error {
 	// Read existing content
 	existing, err := os.ReadFile(destPath)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read existing file: %w", err)
 	}
 
 	// Get template content
-	template, err := agentTemplates.ReadFile("templates/agents/" + templateName)
+	name := "agents/" + templateName
+	template, err := readTemplate(root, name)
 	if err != nil {
-		return fmt.Errorf("template not found: %w", err)
+		return err
 	}
+	section := []byte(markSection(name, template))
 
 	// For markdown files that are primary docs (CLAUDE.md, AGENTS.md, GEMINI.md),
 	// prepend the agentdx instructions
 	var newContent []byte
 	baseName := filepath.Base(destPath)
-	if baseName == "CLAUDE.md" || baseName == "AGENTS.md" || baseName == "GEMINI.md" {
+	if len(existing) == 0 {
+		newContent = section
+	} else if baseName == "CLAUDE.md" || baseName == "AGENTS.md" || baseName == "GEMINI.md" {
 		// Prepend: template first, then existing
-		newContent = append(template, '\n')
+		newContent = append(section, '\n')
 		newContent = append(newContent, existing...)
 	} else {
 		// Append: existing first, then template
 		newContent = existing
-		if len(newContent) > 0 && newContent[len(newContent)-1] != '\n' {
+		if newContent[len(newContent)-1] != '\n' {
 			newContent = append(newContent, '\n')
 		}
 		newContent = append(newContent, '\n')
-		newContent = append(newContent, template...)
+		newContent = append(newContent, section...)
 	}
 
 	return os.WriteFile(destPath, newContent, 0644)
@@ -273,3 +399,63 @@ exit 0
 	fmt.Println("\nInstalled Claude Code session hooks")
 	return nil
 }
+
+// selectAgents returns the agents named by ids, or asks which agents to
+// configure (or remove) when stdin is a terminal. Without either, setup
+// configures every agent while removal refuses to guess.
+func selectAgents(ids []string, action string, remove bool) ([]AgentConfig, error) {
+	if len(ids) > 0 {
+		return ResolveAgentConfigs(ids)
+	}
+	if stdinIsTerminal() {
+		return promptAgentSelection(os.Stdin, os.Stdout, action)
+	}
+	if remove {
+		return nil, errcode.New(errcode.InvalidArgs, "--remove requires --agent when not run interactively (supported: %s)", strings.Join(agentIDs(SupportedAgentConfigs()), ", "))
+	}
+	return SupportedAgentConfigs(), nil
+}
+
+// promptAgentSelection lists the supported agents and reads a selection of
+// numbers or IDs. An empty answer selects every agent.
+func promptAgentSelection(in io.Reader, out io.Writer, action string) ([]AgentConfig, error) {
+	agents := SupportedAgentConfigs()
+	fmt.Fprintf(out, "Select coding agents to %s:\n", action)
+	for i, agent := range agents {
+		fmt.Fprintf(out, "  %d) %-9s %s\n", i+1, agent.ID, agent.Name)
+	}
+	fmt.Fprint(out, "Agents (numbers or names, comma separated; Enter for all): ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read selection: %w", err)
+	}
+	return parseAgentSelection(answer)
+}
+
+// parseAgentSelection resolves a comma or space separated list of agent
+// numbers (as listed by promptAgentSelection) and IDs.
+func parseAgentSelection(answer string) ([]AgentConfig, error) {
+	agents := SupportedAgentConfigs()
+	fields := strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return agents, nil
+	}
+
+	ids := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 || n > len(agents) {
+				return nil, errcode.New(errcode.InvalidArgs, "no agent number %d (choose 1-%d)", n, len(agents))
+			}
+			field = agents[n-1].ID
+		}
+		ids = append(ids, field)
+	}
+	return ResolveAgentConfigs(ids)
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
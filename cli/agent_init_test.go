@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveAgentConfigs(t *testing.T) {
+	agents, err := ResolveAgentConfigs([]string{"Gemini", " cursor"})
+	if err != nil {
+		t.Fatalf("failed to resolve agents: %v", err)
+	}
+	if got := agentIDs(agents); !slices.Equal(got, []string{"cursor", "gemini"}) {
+		t.Errorf("expected agents in supported order, got %v", got)
+	}
+
+	if _, err := ResolveAgentConfigs([]string{"claude", "vscode"}); err == nil || !strings.Contains(err.Error(), `"vscode"`) {
+		t.Errorf("expected unknown agent error, got %v", err)
+	}
+}
+
+func TestParseAgentSelection(t *testing.T) {
+	agents, err := parseAgentSelection("\n")
+	if err != nil || len(agents) != len(SupportedAgentConfigs()) {
+		t.Errorf("expected an empty answer to select every agent, got %v, %v", agentIDs(agents), err)
+	}
+
+	agents, err = parseAgentSelection("1, windsurf 5\n")
+	if err != nil {
+		t.Fatalf("failed to parse selection: %v", err)
+	}
+	if got := agentIDs(agents); !slices.Equal(got, []string{"claude", "windsurf", "gemini"}) {
+		t.Errorf("unexpected selection: %v", got)
+	}
+
+	if _, err := parseAgentSelection("9"); err == nil {
+		t.Error("expected an error for an agent number out of range")
+	}
+}
+
+func TestGenerateAgentConfigsFor_OnlySelected(t *testing.T) {
+	tmpDir := t.TempDir()
+	agents, _ := ResolveAgentConfigs([]string{"cursor"})
+
+	if err := GenerateAgentConfigsFor(tmpDir, agents); err != nil {
+		t.Fatalf("failed to generate agent configs: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".cursor", "rules", "agentdx.mdc")); err != nil {
+		t.Errorf("cursor rules not created: %v", err)
+	}
+	for _, path := range []string{".claude", "CLAUDE.md", ".windsurfrules", "AGENTS.md", "GEMINI.md"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err == nil {
+			t.Errorf("%s created for an agent that was not selected", path)
+		}
+	}
+}
+
+func TestRemoveAgentConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	userNotes := "# Notes\n\nOur own instructions.\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "AGENTS.md"), []byte(userNotes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, _ := ResolveAgentConfigs([]string{"claude", "codex"})
+	if err := GenerateAgentConfigsFor(tmpDir, agents); err != nil {
+		t.Fatalf("failed to generate agent configs: %v", err)
+	}
+	if err := RemoveAgentConfigs(tmpDir, agents); err != nil {
+		t.Fatalf("failed to remove agent configs: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("shared file was removed: %v", err)
+	}
+	if string(content) != userNotes {
+		t.Errorf("expected the user's content to be restored, got:\n%s", content)
+	}
+	for _, path := range []string{".claude", "CLAUDE.md", ".github"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err == nil {
+			t.Errorf("%s left behind", path)
+		}
+	}
+}
+
+func TestRemoveClaudeSettings_KeepsUserSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{
+  "permissions": {"allow": ["Bash(ls)"]},
+  "hooks": {
+    "Stop": [{"matcher": "", "hooks": [{"type": "command", "command": "echo bye", "timeout": 5}]}],
+    "PostToolUse": [{"matcher": "Bash", "hooks": [{"type": "command", "command": ".claude/hooks/agentdx/agentdx-fallback.sh"}]}],
+    "PreToolUse": [{"matcher": "Grep", "hooks": [{"type": "command", "command": "echo 'AGENTDX FALLBACK: use agentdx'"}]}]
+  }
+}`
+	if err := os.WriteFile(settingsPath, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := removeClaudeSettings(tmpDir)
+	if err != nil || !changed {
+		t.Fatalf("expected settings to change, got %v, %v", changed, err)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("settings file was removed: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid settings JSON: %v", err)
+	}
+	if _, ok := got["permissions"]; !ok {
+		t.Error("permissions were dropped")
+	}
+	hooks := got["hooks"].(map[string]any)
+	if len(hooks) != 1 || hooks["Stop"] == nil {
+		t.Errorf("expected only the user's Stop hook to remain, got %v", hooks)
+	}
+	if !strings.Contains(string(data), `"timeout": 5`) {
+		t.Error("unknown hook fields were dropped")
+	}
+
+	if changed, _ := removeClaudeSettings(tmpDir); changed {
+		t.Error("expected a second removal to change nothing")
+	}
+}
+
+func TestAiderRead_KeepsUserSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, aiderConfigPath)
+	userConfig := "# Team settings\nmodel: sonnet\nread: STYLE.md\n"
+	if err := os.WriteFile(configPath, []byte(userConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	action, err := addAiderRead(tmpDir)
+	if err != nil || action != "update" {
+		t.Fatalf("expected the config to be updated, got %q, %v", action, err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Team settings", "model: sonnet", "- STYLE.md", "- CONVENTIONS.md " + aiderReadMarker} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in config, got:\n%s", want, data)
+		}
+	}
+
+	if action, err := addAiderRead(tmpDir); err != nil || action != "" {
+		t.Errorf("expected a second run to change nothing, got %q, %v", action, err)
+	}
+
+	changed, err := removeAiderRead(tmpDir)
+	if err != nil || !changed {
+		t.Fatalf("expected the config to change, got %v, %v", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("config file was removed: %v", err)
+	}
+	if strings.Contains(string(data), aiderConventionsPath) || !strings.Contains(string(data), "STYLE.md") {
+		t.Errorf("expected only the agentdx entry to be removed, got:\n%s", data)
+	}
+}
+
+func TestGenerateAgentConfigsFor_JetBrainsZedAider(t *testing.T) {
+	tmpDir := t.TempDir()
+	agents, _ := ResolveAgentConfigs([]string{"jetbrains", "zed", "aider"})
+
+	for range 2 {
+		if err := GenerateAgentConfigsFor(tmpDir, agents); err != nil {
+			t.Fatalf("failed to generate agent configs: %v", err)
+		}
+	}
+	for _, path := range []string{".aiassistant/rules/agentdx.md", ".rules", aiderConventionsPath} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, path))
+		if err != nil {
+			t.Fatalf("%s not created: %v", path, err)
+		}
+		if strings.Count(string(content), "agentdx search") < 1 {
+			t.Errorf("%s lacks agentdx instructions", path)
+		}
+	}
+	config, err := os.ReadFile(filepath.Join(tmpDir, aiderConfigPath))
+	if err != nil {
+		t.Fatalf("aider config not created: %v", err)
+	}
+	if strings.Count(string(config), aiderConventionsPath) != 1 {
+		t.Errorf("expected CONVENTIONS.md to be read once, got:\n%s", config)
+	}
+
+	if err := RemoveAgentConfigs(tmpDir, agents); err != nil {
+		t.Fatalf("failed to remove agent configs: %v", err)
+	}
+	for _, path := range []string{".aiassistant", ".rules", aiderConventionsPath, aiderConfigPath} {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err == nil {
+			t.Errorf("%s left behind", path)
+		}
+	}
+}
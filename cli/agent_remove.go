@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doveaia/agentdx/hooks"
+)
+
+// claudeSettingsPath is the Claude Code settings file, which agentdx shares
+// with the user's own hooks and plugins
+const claudeSettingsPath = ".claude/settings.json"
+
+// RemoveAgentConfigs uninstalls the agentdx configuration of the given
+// coding agents: files agentdx owns are deleted, the agentdx instructions are
+// stripped from shared files and directories left empty are removed.
+func RemoveAgentConfigs(cwd string, agents []AgentConfig) error {
+	fmt.Println("\nRemoving coding agent configurations...")
+
+	removed := 0
+	for _, agent := range agents {
+		fmt.Printf("\n%s:\n", agent.Name)
+		before := removed
+
+		for _, file := range agent.Files {
+			var changed bool
+			var err error
+			switch file.DestPath {
+			case claudeSettingsPath:
+				changed, err = removeClaudeSettings(cwd)
+			case aiderConfigPath:
+				changed, err = removeAiderRead(cwd)
+			default:
+				changed, err = removeAgentFile(cwd, file)
+			}
+			if err != nil {
+				fmt.Printf("  [warn] %s: %v\n", file.DestPath, err)
+				continue
+			}
+			if changed {
+				removed++
+			}
+		}
+
+		if agent.ID == "claude" {
+			// Session and fallback hooks installed by setup and init
+			hooksDir := filepath.Join(cwd, hooks.AgentdxHooksDir)
+			if _, err := os.Stat(hooksDir); err == nil {
+				if err := os.RemoveAll(hooksDir); err != nil {
+					fmt.Printf("  [warn] %s: %v\n", hooks.AgentdxHooksDir, err)
+				} else {
+					fmt.Printf("  [remove] %s/\n", hooks.AgentdxHooksDir)
+					removed++
+				}
+			}
+		}
+
+		if removed == before {
+			fmt.Println("  nothing to remove")
+		}
+
+		// Remove the agent's directories deepest first, if agentdx left them empty
+		for i := len(agent.Directories) - 1; i >= 0; i-- {
+			_ = os.Remove(filepath.Join(cwd, agent.Directories[i]))
+		}
+		if agent.ID == "claude" {
+			_ = os.Remove(filepath.Join(cwd, ".claude", "hooks"))
+			_ = os.Remove(filepath.Join(cwd, ".claude"))
+		}
+	}
+
+	fmt.Printf("\nAgent configurations: %d removed or updated\n", removed)
+	return nil
+}
+
+// removeAgentFile deletes a file owned by agentdx, or strips the agentdx
+// instructions from a shared file. It reports whether the file changed.
+func removeAgentFile(cwd string, file AgentFile) (bool, error) {
+	path := filepath.Join(cwd, file.DestPath)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	if !strings.Contains(string(content), "agentdx") {
+		return false, nil
+	}
+
+	if !file.Shared {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove file: %w", err)
+		}
+		fmt.Printf("  [remove] %s\n", file.DestPath)
+		return true, nil
+	}
+
+	// Instructions are marked sections, or unmarked ones written by older
+	// versions from the template (init) or by setup
+	stripped := stripMarkedSections(string(content))
+	if stripped == string(content) {
+		for _, name := range []string{"agents/" + file.TemplateName, setupInstructionsTemplate} {
+			template, err := readTemplate(cwd, name)
+			if err != nil {
+				return false, err
+			}
+			stripped = stripSection(stripped, template)
+		}
+	}
+	if stripped == string(content) {
+		fmt.Printf("  [keep] %s (agentdx instructions were edited, remove them manually)\n", file.DestPath)
+		return false, nil
+	}
+
+	if strings.TrimSpace(stripped) == "" {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove file: %w", err)
+		}
+		fmt.Printf("  [remove] %s\n", file.DestPath)
+		return true, nil
+	}
+	if err := os.WriteFile(path, []byte(stripped), 0644); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+	fmt.Printf("  [update] %s (removed agentdx instructions)\n", file.DestPath)
+	return true, nil
+}
+
+// stripSection removes section from content along with the blank line that
+// separated it from the user's content when it was prepended or appended.
+func stripSection(content, section string) string {
+	for _, s := range []string{section + "\n", "\n" + section, section} {
+		if strings.Contains(content, s) {
+			return strings.Replace(content, s, "", 1)
+		}
+	}
+	return content
+}
+
+// removeClaudeSettings removes the agentdx hooks from .claude/settings.json,
+// deleting the file when nothing else is configured in it. The JSON is
+// edited generically so that settings agentdx does not know are kept.
+func removeClaudeSettings(cwd string) (bool, error) {
+	path := filepath.Join(cwd, claudeSettingsPath)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return false, fmt.Errorf("failed to parse settings JSON: %w", err)
+	}
+	if !removeAgentdxHookEntries(settings) {
+		return false, nil
+	}
+
+	if len(settings) == 0 {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove settings file: %w", err)
+		}
+		fmt.Printf("  [remove] %s\n", claudeSettingsPath)
+		return true, nil
+	}
+
+	output, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize settings JSON: %w", err)
+	}
+	if err := os.WriteFile(path, output, 0644); err != nil {
+		return false, fmt.Errorf("failed to write settings file: %w", err)
+	}
+	fmt.Printf("  [update] %s (removed agentdx hooks)\n", claudeSettingsPath)
+	return true, nil
+}
+
+// removeAgentdxHookEntries removes the hook entries running agentdx commands
+// from decoded settings, dropping events and the hooks object left empty. It
+// reports whether anything was removed.
+func removeAgentdxHookEntries(settings map[string]any) bool {
+	events, ok := settings["hooks"].(map[string]any)
+	if !ok {
+		return false
+	}
+
+	removed := false
+	for event, value := range events {
+		entries, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		kept := make([]any, 0, len(entries))
+		for _, entry := range entries {
+			if isAgentdxHookEntry(entry) {
+				removed = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		if len(kept) == 0 {
+			delete(events, event)
+		} else {
+			events[event] = kept
+		}
+	}
+	if len(events) == 0 {
+		delete(settings, "hooks")
+	}
+	return removed
+}
+
+// isAgentdxHookEntry reports whether a decoded hook entry runs an agentdx
+// command
+func isAgentdxHookEntry(entry any) bool {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return false
+	}
+	actions, _ := m["hooks"].([]any)
+	for _, action := range actions {
+		if a, ok := action.(map[string]any); ok {
+			if command, ok := a["command"].(string); ok && isAgentdxHookCommand(command) {
+				return true
+			}
+		}
+	}
+	return false
+}
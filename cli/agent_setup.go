@@ -1,113 +1,45 @@
 package cli
 
 import (
-	_ "embed"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
 	"github.com/doveaia/agentdx/hooks"
 	"github.com/spf13/cobra"
 )
 
-// Embedded template files
-var (
-	//go:embed templates/agentdx-fallback.sh
-	fallbackHook string
-)
-
 // Marker strings for detecting existing configuration
 const (
-	fullTextMarker         = "## agentdx - Full-Text Search"
+	fullTextMarker         = "## agentdx - PostgreSQL Full-Text Search"
 	fullTextSubagentMarker = "name: deep-explore"
 	ruleMarker             = "# AgentDX Rule"
 	hookMarker             = "PostToolUse hook for Bash tool"
 )
 
-// FTS-only templates
-const (
-	fullTextInstructions = `
-## agentdx - PostgreSQL Full-Text Search
-
-This project uses agentdx for fast full-text code search optimized for AI agents.
-
-### Quick Reference
-
-agentdx search "pattern" --json --compact
-agentdx files "*.go" --json --compact
-agentdx trace callers "FunctionName" --json
-agentdx trace callees "FunctionName" --json
-
-### Search Tips
-
-- Use exact code identifiers for best results
-- FTS works well with symbol names, function names, and string literals
-- Combine with trace commands for deeper code understanding
-- Add --json --compact for AI-friendly output
-
-agentdx uses PostgreSQL Full Text Search with structural boosting for fast, relevant results.
-`
-
-	fullTextSubagent = `name: deep-explore
-description: Full-text code search specialist using agentdx
-
-You are a code exploration specialist with access to agentdx's PostgreSQL Full-Text Search index.
-
-### First Step: Start Session
-
-Before any search or trace command, ensure the agentdx session is running:
-
-` + "```" + `bash
-agentdx session start
-` + "```" + `
-
-This command is idempotent - safe to run multiple times.
-
-### Search Strategy
-
-1. **Use exact identifiers**: Function names, variable names, type names search best
-2. **Combine with trace**: Use trace commands to understand call relationships
-3. **Leverage file patterns**: Narrow scope by file type or directory
-4. **Use parallel searches**: For multiple terms, run separate searches in parallel
-
-### Available Commands
-
-agentdx search "func Login" --json --compact
-agentdx files "**/*.go" --json --compact
-agentdx trace callers "FunctionName" --json
-agentdx trace callees "FunctionName" --json
-agentdx trace graph "SymbolName" --depth 2 --json
-
-### IMPORTANT: No Regex OR Patterns
-
-agentdx does NOT support regex patterns. For multiple terms, use parallel searches:
-
-CORRECT: Run parallel searches
-  agentdx search "Login" --json --compact &
-  agentdx search "Auth" --json --compact &
-  agentdx search "Session" --json --compact
-
-WRONG: Regex OR syntax (will not work)
-  agentdx search "Login\|Auth\|Session"
-
-### Key Difference
-
-This mode uses **PostgreSQL Full Text Search** optimized for code:
-- Fast text-based search on indexed code
-- Structural boosting for relevant results
-- No vector embeddings required
-- Lower token usage for AI interactions
-`
+// initMarkers are the headings of the instructions 'agentdx init' writes,
+// which setup must not duplicate
+var initMarkers = []string{
+	"## Code Search: Use agentdx",
+	"## MANDATORY: Use agentdx",
+}
 
-	fullTextRule = `# AgentDX Rule
-- When user asks about code structure: Use agentdx trace commands
-- When searching for specific functions: Use exact function names with agentdx search
-- Always use --json --compact for AI-friendly output
-- Combine search + trace for complete understanding
-`
-)
+// hasAgentdxInstructions reports whether content already holds agentdx
+// instructions written by setup or init.
+func hasAgentdxInstructions(content string) bool {
+	if hasMarkedSection(content) {
+		return true
+	}
+	for _, marker := range append([]string{fullTextMarker}, initMarkers...) {
+		if strings.Contains(content, marker) {
+			return true
+		}
+	}
+	return false
+}
 
 var agentSetupCmd = &cobra.Command{
 	Use:   "setup",
@@ -115,8 +47,11 @@ var agentSetupCmd = &cobra.Command{
 	Long: `Configure AI agent environments to leverage agentdx for context retrieval.
 
 This command will:
-- Detect agent configuration files (.cursorrules, .windsurfrules, CLAUDE.md, GEMINI.md, AGENTS.md)
+- Detect agent configuration files (.cursorrules, .windsurfrules, CLAUDE.md,
+  GEMINI.md, AGENTS.md, .rules, CONVENTIONS.md)
 - Append instructions for using agentdx search
+- Create .aiassistant/rules/agentdx.md for JetBrains AI Assistant
+- Make Aider read CONVENTIONS.md through .aider.conf.yml
 - Create .claude/rules/agentdx.md for Claude Code rules
 - Create .claude/hooks/agentdx-fallback.sh for empty result handling
 - Create/update .claude/settings.json with agentdx hooks
@@ -124,14 +59,123 @@ This command will:
 - Install session management hooks for automatic daemon start/stop
 - Ensure idempotence (won't add duplicate instructions)
 
-All configurations are project-scoped (installed in current directory).`,
+All configurations are project-scoped (installed in current directory).
+
+Use --agent (repeatable) to configure only some agents; when run in a
+terminal without --agent, setup asks which agents to configure. Claude Code
+files, hooks and settings are only created when Claude Code is selected.
+With --remove, the agentdx configuration of the selected agents is
+uninstalled: files agentdx owns are deleted and its instructions and hooks
+are removed from shared files.
+
+The instructions, subagent, rules and hooks come from templates built into
+agentdx; a file of the same name in .agentdx/templates/ (e.g.
+fulltext_instructions.md or agents/CLAUDE.md) replaces one. In shared files,
+the agentdx instructions sit between <!-- agentdx:begin --> and
+<!-- agentdx:end --> markers. With --refresh, setup regenerates them from the
+current templates, keeping edits outside the markers, and rewrites the files
+it owns.
+
+Examples:
+  agentdx setup --agent cursor
+  agentdx setup --agent claude,gemini
+  agentdx setup --refresh
+  agentdx setup --remove --agent windsurf`,
 	RunE: runAgentSetup,
 }
 
-// getTemplates returns the FTS search templates.
-// Returns (instructions, subagent, marker, subagentMarker, rule).
-func getTemplates() (string, string, string, string, string) {
-	return fullTextInstructions, fullTextSubagent, fullTextMarker, fullTextSubagentMarker, fullTextRule
+var (
+	setupAgents  []string
+	setupRemove  bool
+	setupRefresh bool
+)
+
+func init() {
+	agentSetupCmd.Flags().StringSliceVar(&setupAgents, "agent", nil,
+		"Configure only this agent, repeatable ("+strings.Join(agentIDs(SupportedAgentConfigs()), ", ")+")")
+	agentSetupCmd.Flags().BoolVar(&setupRemove, "remove", false, "Remove the agentdx configuration of the selected agents")
+	agentSetupCmd.Flags().BoolVar(&setupRefresh, "refresh", false, "Regenerate the agentdx instructions and files from the current templates")
+}
+
+// setupFiles are the instruction files setup appends to when they exist,
+// with the agent each belongs to
+var setupFiles = []struct {
+	path  string
+	agent string
+}{
+	{".cursorrules", "cursor"},
+	{".windsurfrules", "windsurf"},
+	{"CLAUDE.md", "claude"},
+	{".claude/settings.md", "claude"},
+	{"GEMINI.md", "gemini"},
+	{"AGENTS.md", "codex"},
+	{".rules", "zed"},
+	{aiderConventionsPath, "aider"},
+}
+
+// isSetupFile reports whether setup appends its instructions to path.
+func isSetupFile(path string) bool {
+	for _, f := range setupFiles {
+		if f.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// setupInstructionsTemplate is the template of the instructions setup adds to
+// the agents' instruction files.
+const setupInstructionsTemplate = "fulltext_instructions.md"
+
+// getTemplates returns the FTS search templates with the overrides of the
+// project at root.
+func getTemplates(root string) (instructions, subagent, rule string, err error) {
+	if instructions, err = readTemplate(root, setupInstructionsTemplate); err != nil {
+		return "", "", "", err
+	}
+	if subagent, err = readTemplate(root, "fulltext_subagent.md"); err != nil {
+		return "", "", "", err
+	}
+	if rule, err = readTemplate(root, "fulltext_rule.md"); err != nil {
+		return "", "", "", err
+	}
+	return instructions, subagent, rule, nil
+}
+
+// sharedTemplates returns the templates setup and init write into the shared
+// file path, whose unmarked copies a refresh replaces.
+func sharedTemplates(path string) []string {
+	names := []string{setupInstructionsTemplate}
+	for _, agent := range SupportedAgentConfigs() {
+		for _, file := range agent.Files {
+			if file.DestPath == path && file.Shared && file.TemplateName != "" {
+				names = append(names, "agents/"+file.TemplateName)
+			}
+		}
+	}
+	return names
+}
+
+// refreshInstructions regenerates the agentdx sections of the shared file
+// path, relative to cwd, from the templates of the project at root. It
+// reports whether the file holds sections it could refresh.
+func refreshInstructions(cwd, root, path string) (bool, error) {
+	full := filepath.Join(cwd, path)
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	refreshed, n, err := refreshSections(root, string(content), sharedTemplates(path))
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if refreshed == string(content) {
+		return true, nil
+	}
+	if err := os.WriteFile(full, []byte(refreshed), 0644); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+	return true, nil
 }
 
 func runAgentSetup(cmd *cobra.Command, args []string) error {
@@ -140,10 +184,22 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
+	action := "configure"
+	if setupRemove {
+		action = "remove"
+	}
+	agents, err := selectAgents(setupAgents, action, setupRemove)
+	if err != nil {
+		return err
+	}
+	if setupRemove {
+		return RemoveAgentConfigs(cwd, agents)
+	}
+
 	// Find project root (walks up parent directories to find .agentdx/config.yaml)
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
-		return fmt.Errorf("agentdx configuration not found. Run 'agentdx init' first")
+		return errcode.New(errcode.NoProject, "agentdx configuration not found. Run 'agentdx init' first")
 	}
 
 	// Load configuration
@@ -155,15 +211,17 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 	_ = cfg // Config is loaded to verify project is initialized
 
 	// Always use FTS search
-	instructions, subagent, _, subagentMarker, rule := getTemplates()
+	instructions, subagent, rule, err := getTemplates(projectRoot)
+	if err != nil {
+		return err
+	}
+	section := markSection(setupInstructionsTemplate, instructions)
 
-	agentFiles := []string{
-		".cursorrules",
-		".windsurfrules",
-		"CLAUDE.md",
-		".claude/settings.md",
-		"GEMINI.md",
-		"AGENTS.md",
+	var agentFiles []string
+	for _, f := range setupFiles {
+		if hasAgent(agents, f.agent) {
+			agentFiles = append(agentFiles, f.path)
+		}
 	}
 
 	found := false
@@ -187,9 +245,22 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		// Check if already configured (full-text marker)
-		if strings.Contains(string(content), fullTextMarker) {
-			fmt.Printf("  Already configured, skipping\n")
+		// Check if already configured by setup or init
+		if hasAgentdxInstructions(string(content)) {
+			if !setupRefresh {
+				fmt.Printf("  Already configured, skipping\n")
+				continue
+			}
+			ok, err := refreshInstructions(cwd, projectRoot, file)
+			switch {
+			case err != nil:
+				fmt.Printf("  Warning: could not refresh %s: %v\n", file, err)
+			case ok:
+				fmt.Printf("  Refreshed agentdx instructions\n")
+				modified++
+			default:
+				fmt.Printf("  agentdx instructions were edited without markers, refresh them manually\n")
+			}
 			continue
 		}
 
@@ -198,7 +269,7 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 		if file == "CLAUDE.md" {
 			// Prepend: instructions first, then existing content
 			var newContent strings.Builder
-			newContent.WriteString(instructions)
+			newContent.WriteString(section)
 			newContent.WriteString("\n")
 			if len(content) > 0 {
 				newContent.Write(content)
@@ -220,7 +291,7 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 				_, writeErr = f.WriteString("\n")
 			}
 			if writeErr == nil {
-				_, writeErr = f.WriteString(instructions)
+				_, writeErr = f.WriteString(section)
 			}
 			f.Close()
 		}
@@ -238,7 +309,7 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nUpdated %d file(s).\n", modified)
 	} else if found {
 		fmt.Println("\nAll files already configured.")
-	} else {
+	} else if len(agentFiles) > 0 {
 		fmt.Println("No agent configuration files found.")
 		fmt.Println("\nSupported files:")
 		for _, file := range agentFiles {
@@ -248,27 +319,70 @@ func runAgentSetup(cmd *cobra.Command, args []string) error {
 		fmt.Println("or manually add instructions for using 'agentdx search'.")
 	}
 
-	// Create Claude Code subagent (always)
-	if err := createSubagent(cwd, subagent, subagentMarker); err != nil {
+	// Instruction files only init writes, which setup does not add to
+	if setupRefresh {
+		for _, agent := range agents {
+			for _, file := range agent.Files {
+				if !file.Shared || file.TemplateName == "" || isSetupFile(file.DestPath) {
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(cwd, file.DestPath)); err != nil {
+					continue
+				}
+				if ok, err := refreshInstructions(cwd, projectRoot, file.DestPath); err != nil {
+					fmt.Printf("Warning: could not refresh %s: %v\n", file.DestPath, err)
+				} else if ok {
+					fmt.Printf("Refreshed agentdx instructions: %s\n", file.DestPath)
+				}
+			}
+		}
+	}
+
+	// JetBrains AI Assistant reads rules from a directory of its own
+	if hasAgent(agents, "jetbrains") {
+		if err := createJetBrainsRule(cwd, projectRoot); err != nil {
+			fmt.Printf("Warning: could not create JetBrains rule: %v\n", err)
+		}
+	}
+
+	// Aider only reads CONVENTIONS.md when its configuration says so
+	if hasAgent(agents, "aider") {
+		if _, err := os.Stat(filepath.Join(cwd, aiderConventionsPath)); err == nil {
+			action, err := addAiderRead(cwd)
+			if err != nil {
+				fmt.Printf("Warning: could not update %s: %v\n", aiderConfigPath, err)
+			} else if action != "" {
+				fmt.Printf("Configured %s to read %s\n", aiderConfigPath, aiderConventionsPath)
+			}
+		}
+	}
+
+	// The remaining files, hooks and settings are Claude Code's
+	if !hasAgent(agents, "claude") {
+		return nil
+	}
+
+	// Create Claude Code subagent
+	if err := createSubagent(cwd, subagent, fullTextSubagentMarker); err != nil {
 		fmt.Printf("Warning: could not create subagent: %v\n", err)
 	}
 
-	// Create Claude Code rule (always)
+	// Create Claude Code rule
 	if err := createRule(cwd, rule); err != nil {
 		fmt.Printf("Warning: could not create rule: %v\n", err)
 	}
 
-	// Create Claude Code hook for fallback behavior (always)
-	if err := createHook(cwd); err != nil {
+	// Create Claude Code hook for fallback behavior
+	if err := createHook(cwd, projectRoot); err != nil {
 		fmt.Printf("Warning: could not create hook: %v\n", err)
 	}
 
-	// Create or update Claude Code settings.json with agentdx hooks (always)
+	// Create or update Claude Code settings.json with agentdx hooks
 	if err := createSettings(cwd); err != nil {
 		fmt.Printf("Warning: could not create/update settings: %v\n", err)
 	}
 
-	// Install session management hooks (always)
+	// Install session management hooks
 	if err := installSessionHooks(cwd); err != nil {
 		fmt.Printf("Warning: could not install session hooks: %v\n", err)
 	}
@@ -283,7 +397,7 @@ func createSubagent(cwd string, subagent, _ string) error {
 
 	// Check if subagent already exists and contains marker
 	if content, err := os.ReadFile(subagentPath); err == nil {
-		if strings.Contains(string(content), "name: deep-explore") {
+		if !setupRefresh && strings.Contains(string(content), "name: deep-explore") {
 			fmt.Printf("Subagent already exists: %s\n", subagentPath)
 			return nil
 		}
@@ -310,7 +424,7 @@ func createRule(cwd string, rule string) error {
 
 	// Check if rule already exists and contains marker
 	if content, err := os.ReadFile(rulePath); err == nil {
-		if strings.Contains(string(content), ruleMarker) {
+		if !setupRefresh && strings.Contains(string(content), ruleMarker) {
 			fmt.Printf("Rule already exists: %s\n", rulePath)
 			return nil
 		}
@@ -330,14 +444,39 @@ func createRule(cwd string, rule string) error {
 	return nil
 }
 
-func createHook(cwd string) error {
+// jetBrainsRulePath is the JetBrains AI Assistant rule written by setup.
+const jetBrainsRulePath = ".aiassistant/rules/agentdx.md"
+
+func createJetBrainsRule(cwd, root string) error {
+	rulePath := filepath.Join(cwd, jetBrainsRulePath)
+
+	// Check if rule already exists and mentions agentdx
+	if content, err := os.ReadFile(rulePath); err == nil {
+		if !setupRefresh && strings.Contains(string(content), "agentdx") {
+			fmt.Printf("JetBrains rule already exists: %s\n", rulePath)
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rulePath), 0755); err != nil {
+		return fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	if err := createAgentFile(root, rulePath, "aiassistant_rules_agentdx.md"); err != nil {
+		return fmt.Errorf("failed to write rule file: %w", err)
+	}
+
+	fmt.Printf("Created JetBrains rule: %s\n", rulePath)
+	return nil
+}
+
+func createHook(cwd, root string) error {
 	// Define paths - all agentdx hooks go in .claude/hooks/agentdx/
 	hooksDir := filepath.Join(cwd, ".claude", "hooks", "agentdx")
 	hookPath := filepath.Join(hooksDir, "agentdx-fallback.sh")
 
 	// Check if hook already exists and contains marker
 	if content, err := os.ReadFile(hookPath); err == nil {
-		if strings.Contains(string(content), hookMarker) {
+		if !setupRefresh && strings.Contains(string(content), hookMarker) {
 			fmt.Printf("Hook already exists: %s\n", hookPath)
 			return nil
 		}
@@ -348,8 +487,13 @@ func createHook(cwd string) error {
 		return fmt.Errorf("failed to create hooks directory: %w", err)
 	}
 
+	hook, err := readTemplate(root, "agentdx-fallback.sh")
+	if err != nil {
+		return err
+	}
+
 	// Write the hook file with executable permissions
-	if err := os.WriteFile(hookPath, []byte(fallbackHook), 0755); err != nil {
+	if err := os.WriteFile(hookPath, []byte(hook), 0755); err != nil {
 		return fmt.Errorf("failed to write hook file: %w", err)
 	}
 
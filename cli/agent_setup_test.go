@@ -11,7 +11,7 @@ func TestCreateSubagent(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Test creating subagent with FTS template
-	err := createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err := createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("failed to create subagent: %v", err)
 	}
@@ -45,12 +45,12 @@ func TestCreateSubagentIdempotent(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create subagent twice
-	err := createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err := createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("first creation failed: %v", err)
 	}
 
-	err = createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err = createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("second creation failed: %v", err)
 	}
@@ -73,7 +73,7 @@ func TestCreateSubagentDirectoryStructure(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Ensure .claude/agents/ directory is created
-	err := createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err := createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("failed to create subagent: %v", err)
 	}
@@ -92,7 +92,7 @@ func TestCreateSubagentDirectoryStructure(t *testing.T) {
 func TestCreateSubagentTemplateContent(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	err := createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err := createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("failed to create subagent: %v", err)
 	}
@@ -123,13 +123,13 @@ func TestCreateSubagentIdempotentAcrossTypes(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create subagent first
-	err := createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err := createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("first creation failed: %v", err)
 	}
 
 	// Try to create again - should be skipped (idempotent)
-	err = createSubagent(tmpDir, fullTextSubagent, fullTextSubagentMarker)
+	err = createSubagent(tmpDir, defaultTemplate(t, "fulltext_subagent.md"), fullTextSubagentMarker)
 	if err != nil {
 		t.Fatalf("second creation failed: %v", err)
 	}
@@ -148,22 +148,32 @@ func TestCreateSubagentIdempotentAcrossTypes(t *testing.T) {
 	}
 }
 
+// defaultTemplate returns the content of the default template name.
+func defaultTemplate(t *testing.T, name string) string {
+	t.Helper()
+	template, err := readTemplate("", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
 // Tests for getTemplates function
 
 func TestGetTemplates_FullText(t *testing.T) {
-	instructions, subagent, marker, subagentMarker, rule := getTemplates()
+	instructions, subagent, rule, err := getTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !strings.Contains(instructions, "Full-Text Search") {
 		t.Error("instructions should contain 'Full-Text Search'")
 	}
-	if marker != fullTextMarker {
-		t.Errorf("marker = %q, want %q", marker, fullTextMarker)
+	if !strings.Contains(instructions, fullTextMarker) {
+		t.Error("instructions should contain the instructions marker")
 	}
-	if subagentMarker != fullTextSubagentMarker {
-		t.Errorf("subagentMarker = %q, want %q", subagentMarker, fullTextSubagentMarker)
-	}
-	if subagent == "" {
-		t.Error("subagent template should not be empty")
+	if !strings.Contains(subagent, fullTextSubagentMarker) {
+		t.Error("subagent template should contain the subagent marker")
 	}
 	if rule == "" {
 		t.Error("rule template should not be empty")
@@ -184,11 +194,28 @@ func TestTemplateMarkers_Unique(t *testing.T) {
 	}
 }
 
+func TestHasAgentdxInstructions(t *testing.T) {
+	if !hasAgentdxInstructions("# Mine\n" + defaultTemplate(t, setupInstructionsTemplate)) {
+		t.Error("instructions added by setup should be detected")
+	}
+	for _, name := range []string{"CLAUDE.md", "GEMINI.md", "AGENTS.md", "windsurfrules", "zed_rules", "CONVENTIONS.md"} {
+		if !hasAgentdxInstructions(defaultTemplate(t, "agents/"+name)) {
+			t.Errorf("instructions written by init from %s should be detected", name)
+		}
+	}
+	if !hasAgentdxInstructions("# Mine\n\n" + markSection("fulltext_instructions.md", "Edited instructions")) {
+		t.Error("marked instructions should be detected")
+	}
+	if hasAgentdxInstructions("# Project notes\n\nRun make test.\n") {
+		t.Error("files without agentdx instructions should not be detected")
+	}
+}
+
 func TestCreateRule(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Test creating rule with FTS template
-	err := createRule(tmpDir, fullTextRule)
+	err := createRule(tmpDir, defaultTemplate(t, "fulltext_rule.md"))
 	if err != nil {
 		t.Fatalf("failed to create rule: %v", err)
 	}
@@ -214,12 +241,12 @@ func TestCreateRuleIdempotent(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Create rule twice
-	err := createRule(tmpDir, fullTextRule)
+	err := createRule(tmpDir, defaultTemplate(t, "fulltext_rule.md"))
 	if err != nil {
 		t.Fatalf("first creation failed: %v", err)
 	}
 
-	err = createRule(tmpDir, fullTextRule)
+	err = createRule(tmpDir, defaultTemplate(t, "fulltext_rule.md"))
 	if err != nil {
 		t.Fatalf("second creation failed: %v", err)
 	}
@@ -239,6 +266,8 @@ func TestCreateRuleIdempotent(t *testing.T) {
 }
 
 func TestFullTextInstructions_NoEmbeddingReferences(t *testing.T) {
+	fullTextInstructions := defaultTemplate(t, setupInstructionsTemplate)
+
 	// Check for forbidden terms that shouldn't appear in FTS instructions
 	forbidden := []string{"vector similarity", "embedding model"}
 	instructionsLower := strings.ToLower(fullTextInstructions)
@@ -256,7 +285,7 @@ func TestFullTextInstructions_NoEmbeddingReferences(t *testing.T) {
 
 func TestFullTextInstructions_HasSearchExamples(t *testing.T) {
 	// Check for search examples
-	if !strings.Contains(fullTextInstructions, `agentdx search "`) {
+	if !strings.Contains(defaultTemplate(t, setupInstructionsTemplate), `agentdx search "`) {
 		t.Error("fullTextInstructions should contain agentdx search examples")
 	}
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/doveaia/agentdx/mcp"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var capabilitiesJSON bool
+
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities",
+	Short: "Describe all commands, flags and MCP tools",
+	Long: `Describe the commands, flags and MCP tools of this agentdx build.
+
+With --json the description is machine-readable, including the JSON Schema of
+each MCP tool's parameters, so orchestration frameworks can generate tool
+definitions for agents at runtime instead of hardcoding them.
+
+Examples:
+  agentdx capabilities
+  agentdx capabilities --json | jq '.mcp_tools[].name'`,
+	Args: cobra.NoArgs,
+	RunE: runCapabilities,
+}
+
+func init() {
+	capabilitiesCmd.Flags().BoolVar(&capabilitiesJSON, "json", false, "Output in JSON format")
+	rootCmd.AddCommand(capabilitiesCmd)
+}
+
+// Capabilities describes the commands and MCP tools of an agentdx build.
+type Capabilities struct {
+	Name        string        `json:"name"`
+	Version     string        `json:"version"`
+	GlobalFlags []FlagInfo    `json:"global_flags"`
+	Commands    []CommandInfo `json:"commands"`
+	MCPTools    []MCPToolInfo `json:"mcp_tools"`
+}
+
+// CommandInfo describes a command. Path is the full command line prefix,
+// e.g. "agentdx trace callers".
+type CommandInfo struct {
+	Path     string     `json:"path"`
+	Usage    string     `json:"usage"`
+	Short    string     `json:"short"`
+	Aliases  []string   `json:"aliases,omitempty"`
+	Runnable bool       `json:"runnable"`
+	Flags    []FlagInfo `json:"flags"`
+}
+
+// FlagInfo describes a command-line flag.
+type FlagInfo struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default"`
+	Usage     string `json:"usage"`
+}
+
+// MCPToolInfo describes an MCP tool and the JSON Schema of its parameters.
+type MCPToolInfo struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	InputSchema mcpgo.ToolArgumentsSchema `json:"input_schema"`
+}
+
+func runCapabilities(_ *cobra.Command, _ []string) error {
+	caps, err := describeCapabilities(rootCmd)
+	if err != nil {
+		return err
+	}
+
+	if capabilitiesJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(caps)
+	}
+
+	fmt.Printf("%s %s\n\nCommands:\n", caps.Name, caps.Version)
+	for _, c := range caps.Commands {
+		if c.Runnable {
+			fmt.Printf("  %-28s %s\n", c.Path, c.Short)
+		}
+	}
+	fmt.Println("\nMCP tools:")
+	for _, t := range caps.MCPTools {
+		fmt.Printf("  %s\n", t.Name)
+	}
+	fmt.Println("\nUse --json for flags and tool parameter schemas.")
+	return nil
+}
+
+// describeCapabilities walks the command tree under root and lists the MCP
+// tools served by 'agentdx serve'.
+func describeCapabilities(root *cobra.Command) (*Capabilities, error) {
+	caps := &Capabilities{
+		Name:        root.Name(),
+		Version:     version,
+		GlobalFlags: describeFlags(root.PersistentFlags()),
+		Commands:    []CommandInfo{},
+		MCPTools:    []MCPToolInfo{},
+	}
+	if caps.Version == "" {
+		caps.Version = "dev"
+	}
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			if sub.Hidden || sub.Name() == "help" || sub.Name() == "completion" {
+				continue
+			}
+			caps.Commands = append(caps.Commands, CommandInfo{
+				Path:     sub.CommandPath(),
+				Usage:    sub.UseLine(),
+				Short:    sub.Short,
+				Aliases:  sub.Aliases,
+				Runnable: sub.Runnable(),
+				Flags:    describeFlags(sub.LocalFlags()),
+			})
+			walk(sub)
+		}
+	}
+	walk(root)
+	sort.Slice(caps.Commands, func(i, j int) bool {
+		return caps.Commands[i].Path < caps.Commands[j].Path
+	})
+
+	// The tools are registered without opening the index, so this works
+	// outside a project
+	srv, err := mcp.NewServer("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP server: %w", err)
+	}
+	for _, tool := range srv.Tools() {
+		caps.MCPTools = append(caps.MCPTools, MCPToolInfo{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: mcpgo.ToolArgumentsSchema(tool.InputSchema),
+		})
+	}
+	return caps, nil
+}
+
+// describeFlags lists the visible flags of fs sorted by name.
+func describeFlags(fs *pflag.FlagSet) []FlagInfo {
+	flags := []FlagInfo{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		flags = append(flags, FlagInfo{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			Default:   f.DefValue,
+			Usage:     f.Usage,
+		})
+	})
+	return flags
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeCapabilities(t *testing.T) {
+	caps, err := describeCapabilities(rootCmd)
+	require.NoError(t, err)
+
+	var search *CommandInfo
+	for i, c := range caps.Commands {
+		assert.NotEqual(t, "agentdx help", c.Path)
+		if c.Path == "agentdx search" {
+			search = &caps.Commands[i]
+		}
+	}
+	require.NotNil(t, search, "search command missing")
+	assert.True(t, search.Runnable)
+
+	var names []string
+	for _, f := range search.Flags {
+		names = append(names, f.Name)
+		assert.NotEqual(t, "path-style", f.Name, "global flags belong to global_flags")
+	}
+	assert.Contains(t, names, "json")
+	assert.Contains(t, names, "include")
+
+	require.Len(t, caps.GlobalFlags, 1)
+	assert.Equal(t, "path-style", caps.GlobalFlags[0].Name)
+
+	var tool *MCPToolInfo
+	for i, tl := range caps.MCPTools {
+		if tl.Name == "agentdx_search" {
+			tool = &caps.MCPTools[i]
+		}
+	}
+	require.NotNil(t, tool, "agentdx_search tool missing")
+	assert.Contains(t, tool.InputSchema.Properties, "query")
+	assert.Contains(t, tool.InputSchema.Properties, "queries")
+
+	data, err := json.Marshal(caps)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"input_schema":{`)
+}
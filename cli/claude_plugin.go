@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/hooks"
+	"github.com/spf13/cobra"
+)
+
+// pluginName is the name of the Claude Code plugin and of the marketplace
+// listing it.
+const pluginName = "agentdx"
+
+// pluginDescription describes the plugin in its manifest and marketplace.
+const pluginDescription = "Full-text code search and call graph tracing with agentdx: MCP tools, a search skill, a deep-explore subagent and hooks that start the session daemon"
+
+// pluginRootVar is expanded by Claude Code to the directory of an installed
+// plugin; hook commands must not depend on the project layout.
+const pluginRootVar = "${CLAUDE_PLUGIN_ROOT}"
+
+var (
+	pluginOutput string
+	pluginForce  bool
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Package agentdx for coding agents",
+}
+
+var pluginBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a Claude Code plugin for agentdx",
+	Long: `Write a ready-to-install Claude Code plugin that bundles the agentdx
+configuration 'agentdx setup' writes into each project:
+
+  .claude-plugin/plugin.json       plugin manifest
+  .claude-plugin/marketplace.json  single-plugin marketplace, to install from
+  .mcp.json                        the agentdx MCP server (agentdx serve)
+  skills/agentdx/SKILL.md          full-text search skill
+  agents/deep-explore.md           exploration subagent
+  hooks/hooks.json                 Grep/Glob reminders, empty result fallback
+                                   and session daemon start
+  scripts/                         the hook scripts
+
+Installing the plugin replaces running 'agentdx setup --agent claude' in
+every project: the hooks only act in projects initialized with 'agentdx
+init'. The agentdx binary must be on the PATH. Run inside a project, the
+skill, subagent and fallback hook come from its .agentdx/templates/
+overrides when it has them.`,
+	Example: `  agentdx plugin build --output ~/agentdx-plugin
+  claude plugin marketplace add ~/agentdx-plugin
+  claude plugin install agentdx@agentdx
+
+  # Try it for one session without installing
+  claude --plugin-dir ~/agentdx-plugin`,
+	Args: cobra.NoArgs,
+	RunE: runPluginBuild,
+}
+
+func init() {
+	pluginBuildCmd.Flags().StringVarP(&pluginOutput, "output", "o", "agentdx-claude-plugin", "Directory to write the plugin to")
+	pluginBuildCmd.Flags().BoolVarP(&pluginForce, "force", "f", false, "Overwrite the plugin files in a non-empty directory")
+
+	pluginCmd.AddCommand(pluginBuildCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+func runPluginBuild(_ *cobra.Command, _ []string) error {
+	// Inside a project, its template overrides customize the plugin
+	root, err := config.FindProjectRoot()
+	if err != nil {
+		root = ""
+	}
+	files, err := buildClaudePlugin(pluginOutput, root, pluginForce)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Printf("Created %s\n", filepath.Join(pluginOutput, f))
+	}
+	abs, err := filepath.Abs(pluginOutput)
+	if err != nil {
+		abs = pluginOutput
+	}
+	fmt.Printf("\nInstall it with:\n  claude plugin marketplace add %s\n  claude plugin install %s@%s\n", abs, pluginName, pluginName)
+	return nil
+}
+
+// pluginManifest is .claude-plugin/plugin.json.
+type pluginManifest struct {
+	Name        string        `json:"name"`
+	Version     string        `json:"version,omitempty"`
+	Description string        `json:"description"`
+	Author      *pluginAuthor `json:"author,omitempty"`
+	Homepage    string        `json:"homepage,omitempty"`
+	Repository  string        `json:"repository,omitempty"`
+	License     string        `json:"license,omitempty"`
+	Keywords    []string      `json:"keywords,omitempty"`
+}
+
+type pluginAuthor struct {
+	Name string `json:"name"`
+}
+
+// pluginMarketplace is .claude-plugin/marketplace.json, listing the plugin
+// of the same directory.
+type pluginMarketplace struct {
+	Name    string              `json:"name"`
+	Owner   pluginAuthor        `json:"owner"`
+	Plugins []marketplacePlugin `json:"plugins"`
+}
+
+type marketplacePlugin struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Description string `json:"description"`
+	Version     string `json:"version,omitempty"`
+}
+
+// mcpServerConfig is a server of .mcp.json.
+type mcpServerConfig struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// pluginVersion returns the agentdx version as a semantic version, or ""
+// for development builds.
+func pluginVersion() string {
+	v := strings.TrimPrefix(version, "v")
+	if v == "" || v == "dev" {
+		return ""
+	}
+	return v
+}
+
+// pluginHooks returns hooks/hooks.json: the hooks of 'agentdx setup', with
+// their scripts run from the plugin directory.
+func pluginHooks() *ClaudeSettings {
+	return &ClaudeSettings{
+		Hooks: &SettingsHooks{
+			UserPromptSubmit: []ToolHook{
+				{Hooks: []HookAction{{Type: "command", Command: pluginRootVar + "/scripts/agentdx-session-start.sh"}}},
+			},
+			PreToolUse: agentdxPreToolUseHooks,
+			PostToolUse: []ToolHook{
+				{Matcher: "Bash", Hooks: []HookAction{{Type: "command", Command: pluginRootVar + "/scripts/agentdx-fallback.sh"}}},
+			},
+		},
+	}
+}
+
+// buildClaudePlugin writes the agentdx Claude Code plugin to dir, from the
+// templates with the overrides of the project at root, which may be empty,
+// and returns the paths of the files written, relative to dir. A non-empty
+// dir is refused unless force is set.
+func buildClaudePlugin(dir, root string, force bool) ([]string, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !force {
+		return nil, errcode.New(errcode.InvalidArgs, "%s is not empty; use --force to overwrite the plugin files", dir)
+	}
+
+	sessionStart, err := hooks.GetHookScript("claude-code", "start")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session hook script: %w", err)
+	}
+	subagent, err := readTemplate(root, "agents/claude_agents_deep-explore.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subagent template: %w", err)
+	}
+	skill, err := readTemplate(root, "fulltext_skill.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skill template: %w", err)
+	}
+	fallback, err := readTemplate(root, "agentdx-fallback.sh")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fallback hook template: %w", err)
+	}
+
+	v := pluginVersion()
+	manifest := pluginManifest{
+		Name:        pluginName,
+		Version:     v,
+		Description: pluginDescription,
+		Author:      &pluginAuthor{Name: "agentdx"},
+		Homepage:    "https://github.com/doveaia/agentdx",
+		Repository:  "https://github.com/doveaia/agentdx",
+		License:     "MIT",
+		Keywords:    []string{"search", "code-search", "full-text", "call-graph", "mcp"},
+	}
+	marketplace := pluginMarketplace{
+		Name:  pluginName,
+		Owner: pluginAuthor{Name: "agentdx"},
+		Plugins: []marketplacePlugin{
+			{Name: pluginName, Source: "./", Description: pluginDescription, Version: v},
+		},
+	}
+	mcpServers := map[string]map[string]mcpServerConfig{
+		"mcpServers": {pluginName: {Command: "agentdx", Args: []string{"serve"}}},
+	}
+
+	files := []struct {
+		path    string
+		content any // []byte, string or a value written as JSON
+		mode    os.FileMode
+	}{
+		{".claude-plugin/plugin.json", manifest, 0644},
+		{".claude-plugin/marketplace.json", marketplace, 0644},
+		{".mcp.json", mcpServers, 0644},
+		{"skills/agentdx/SKILL.md", skill, 0644},
+		{"agents/deep-explore.md", subagent, 0644},
+		{"hooks/hooks.json", pluginHooks(), 0644},
+		{"scripts/agentdx-session-start.sh", sessionStart, 0755},
+		{"scripts/agentdx-fallback.sh", fallback, 0755},
+	}
+
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		var data []byte
+		switch c := f.content.(type) {
+		case []byte:
+			data = c
+		case string:
+			data = []byte(c)
+		default:
+			if data, err = json.MarshalIndent(c, "", "  "); err != nil {
+				return written, fmt.Errorf("failed to encode %s: %w", f.path, err)
+			}
+			data = append(data, '\n')
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+		}
+		if err := os.WriteFile(path, data, f.mode); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+		// WriteFile keeps the mode of existing files
+		if err := os.Chmod(path, f.mode); err != nil {
+			return written, fmt.Errorf("failed to set the mode of %s: %w", f.path, err)
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildClaudePlugin(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+
+	files, err := buildClaudePlugin(dir, "", false)
+	require.NoError(t, err)
+	assert.Contains(t, files, ".claude-plugin/plugin.json")
+	assert.Contains(t, files, "skills/agentdx/SKILL.md")
+
+	var manifest pluginManifest
+	data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, "agentdx", manifest.Name)
+
+	var servers map[string]map[string]mcpServerConfig
+	data, err = os.ReadFile(filepath.Join(dir, ".mcp.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &servers))
+	assert.Equal(t, []string{"serve"}, servers["mcpServers"]["agentdx"].Args)
+
+	// Hook scripts run from the plugin directory and are executable
+	data, err = os.ReadFile(filepath.Join(dir, "hooks", "hooks.json"))
+	require.NoError(t, err)
+	settings, err := parseSettings(data)
+	require.NoError(t, err)
+	for _, hooks := range [][]ToolHook{settings.Hooks.UserPromptSubmit, settings.Hooks.PostToolUse} {
+		for _, hook := range hooks {
+			for _, action := range hook.Hooks {
+				require.True(t, strings.HasPrefix(action.Command, "${CLAUDE_PLUGIN_ROOT}/scripts/"), action.Command)
+				script := strings.TrimPrefix(action.Command, "${CLAUDE_PLUGIN_ROOT}/")
+				info, err := os.Stat(filepath.Join(dir, script))
+				require.NoError(t, err)
+				assert.NotZero(t, info.Mode()&0100, "%s is not executable", script)
+			}
+		}
+	}
+	assert.Len(t, settings.Hooks.PreToolUse, 2)
+
+	skill, err := os.ReadFile(filepath.Join(dir, "skills", "agentdx", "SKILL.md"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(skill), "---\nname: agentdx\n"))
+
+	// A non-empty directory is only overwritten with force
+	_, err = buildClaudePlugin(dir, "", false)
+	assert.Equal(t, errcode.InvalidArgs, errcode.Of(err))
+	_, err = buildClaudePlugin(dir, "", true)
+	assert.NoError(t, err)
+}
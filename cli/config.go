@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/session"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configJSON bool
+
+var configCmd = &cobra.Command{
+	Use:   "config <subcommand>",
+	Short: "Read and change the project configuration",
+	Long: `Read and change .agentdx/config.yaml by dotted key, such as
+index.search.max_per_file or dashboard.port.
+
+set converts the value to the type of the setting: booleans, numbers,
+comma-separated lists of strings, or YAML for lists of sections and maps.
+The configuration is validated before it is written, so an invalid value
+leaves the file untouched; comments and other settings are kept. unset
+removes a setting so that its default applies.
+
+When a watch daemon is running, it is sent SIGHUP to reload the
+configuration. Settings that only apply when watch starts (e.g. the store,
+chunking or ignore rules) are reported with a reminder to restart it.
+
+Examples:
+  agentdx config get index.search.max_per_file
+  agentdx config set index.search.max_per_file 5
+  agentdx config set index.ignore "vendor,dist,*.min.js"
+  agentdx config set profiles.frontend "[web/**, packages/ui/**]"
+  agentdx config unset index.search.stale_after_seconds
+  agentdx config validate`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting, with defaults applied",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting so that its default applies",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration file",
+	Long: `Check .agentdx/config.yaml the way every command loads it, and also
+report keys that are not part of the configuration, such as misspelled
+settings that would otherwise be ignored. Exits with E_CONFIG when the
+configuration is invalid.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configGetCmd.Flags().BoolVar(&configJSON, "json", false, "Output the value in JSON format")
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigGet(_ *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	value, err := cfg.Get(args[0])
+	if err != nil {
+		return errcode.Wrap(errcode.InvalidArgs, err)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		if configJSON {
+			fmt.Println("null")
+		}
+	case string, bool, int, int64, float32, float64:
+		if configJSON {
+			return json.NewEncoder(os.Stdout).Encode(v)
+		}
+		fmt.Println(v)
+	default:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", args[0], err)
+		}
+		if !configJSON {
+			fmt.Print(string(data))
+			return nil
+		}
+		// Sections are keyed like the config file, not like the Go fields
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", args[0], err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(generic)
+	}
+	return nil
+}
+
+func runConfigSet(_ *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cur, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	next, err := config.SetKey(projectRoot, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Set %s\n", args[0])
+	notifyConfigChange(projectRoot, cur, next)
+	return nil
+}
+
+func runConfigUnset(_ *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cur, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	next, removed, err := config.UnsetKey(projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Printf("%s is not set\n", args[0])
+		return nil
+	}
+	fmt.Printf("Unset %s\n", args[0])
+	notifyConfigChange(projectRoot, cur, next)
+	return nil
+}
+
+func runConfigValidate(_ *cobra.Command, _ []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	if _, err := config.ValidateFile(projectRoot); err != nil {
+		return err
+	}
+	fmt.Printf("Configuration is valid: %s\n", config.GetConfigPath(projectRoot))
+	return nil
+}
+
+// notifyConfigChange asks a running watch daemon to reload the
+// configuration and lists the changed settings it only applies on restart.
+func notifyConfigChange(projectRoot string, cur, next *config.Config) {
+	pidFile := session.NewPIDFile(projectRoot)
+	if running, _ := pidFile.IsProcessRunning(); !running {
+		return
+	}
+	pid, err := pidFile.Read()
+	if err == nil {
+		var proc *os.Process
+		if proc, err = os.FindProcess(pid); err == nil {
+			err = proc.Signal(syscall.SIGHUP)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to reload the watch daemon: %v; restart it with 'agentdx session stop' and 'agentdx session start'\n", err)
+		return
+	}
+	fmt.Printf("Reloaded the watch daemon (PID %d)\n", pid)
+
+	// keepRestartSettings restores the settings it reports into its copy
+	restarted := *next
+	for _, name := range keepRestartSettings(cur, &restarted) {
+		fmt.Fprintf(os.Stderr, "Warning: %s changed; restart the daemon with 'agentdx session stop' and 'agentdx session start' to apply it\n", name)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/spf13/cobra"
+)
+
+// reportedError is an error the output of the command already reported,
+// e.g. as a JSON payload.
+type reportedError struct {
+	error
+}
+
+func (e reportedError) Unwrap() error {
+	return e.error
+}
+
+// ReportError prints err to stderr, unless it was already reported as JSON
+// or only signals an empty result, and returns the exit status of its code.
+func ReportError(err error) int {
+	var reported reportedError
+	if err != nil && !errors.As(err, &reported) && !errors.Is(err, errcode.ErrNoResults) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return errcode.ExitCode(err)
+}
+
+// reportJSONError writes err as a JSON payload to stdout when cmd was asked
+// for JSON output, so agents parsing stdout can branch on its code.
+func reportJSONError(cmd *cobra.Command, err error) error {
+	var reported reportedError
+	if cmd == nil || errors.As(err, &reported) || errors.Is(err, errcode.ErrNoResults) {
+		return err
+	}
+	if f := cmd.Flags().Lookup("json"); f == nil || f.Value.String() != "true" {
+		return err
+	}
+	if writeJSONError(os.Stdout, err) != nil {
+		return err
+	}
+	return reportedError{err}
+}
+
+// writeJSONError writes the {"error": ..., "code": ...} payload of err to w.
+func writeJSONError(w io.Writer, err error) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(errcode.PayloadOf(err))
+}
+
+// classifyUsageErrors gives argument and flag errors raised by cobra the
+// E_INVALID_ARGS code, for cmd and all its subcommands.
+func classifyUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return errcode.Wrap(errcode.InvalidArgs, validate(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		classifyUsageErrors(sub)
+	}
+}
+
+// usageError classifies the errors cobra raises before running a command.
+// Unknown subcommands are only recognizable by their message.
+func usageError(err error) error {
+	if strings.HasPrefix(err.Error(), "unknown command ") {
+		return errcode.Wrap(errcode.InvalidArgs, err)
+	}
+	return err
+}
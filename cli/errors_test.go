@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportError_ExitCodes(t *testing.T) {
+	assert.Equal(t, 0, ReportError(nil))
+	assert.Equal(t, 1, ReportError(errors.New("boom")))
+	assert.Equal(t, 3, ReportError(errcode.ErrNoResults))
+	assert.Equal(t, 6, ReportError(fmt.Errorf("failed to open index store: %w", errcode.New(errcode.BackendDown, "connection refused"))))
+	assert.Equal(t, 9, ReportError(reportedError{errcode.New(errcode.NotFound, "symbol not found: Foo")}))
+}
+
+func TestWriteJSONError(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeJSONError(&buf, fmt.Errorf("failed to load configuration: %w", errcode.New(errcode.Config, "bad yaml"))))
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &payload))
+	assert.Equal(t, "failed to load configuration: bad yaml", payload["error"])
+	assert.Equal(t, "E_CONFIG", payload["code"])
+}
+
+func TestClassifyUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub", Args: cobra.ExactArgs(1), RunE: func(*cobra.Command, []string) error { return nil }}
+	root.AddCommand(sub)
+	classifyUsageErrors(root)
+
+	err := sub.ValidateArgs(nil)
+	require.Error(t, err)
+	assert.Equal(t, errcode.InvalidArgs, errcode.Of(err))
+	assert.NoError(t, sub.ValidateArgs([]string{"a"}))
+
+	assert.Equal(t, errcode.InvalidArgs, errcode.Of(usageError(errors.New(`unknown command "nope" for "agentdx"`))))
+	assert.Equal(t, errcode.Internal, errcode.Of(usageError(errors.New("boom"))))
+}
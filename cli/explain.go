@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainQuery     string
+	explainCallers   int
+	explainWorkspace string
+	explainJSON      bool
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <file:line>",
+	Short: "Show the index entries and callers of a code location",
+	Long: `Show what the index holds about a line of a file: the chunks covering
+it, the symbols defined in those chunks or enclosing the line, and the
+callers of each symbol. Agents that already know a location get its
+context in one call instead of a search and a trace per symbol.
+
+With --query, each chunk is given the score it has for that query, or none
+when it does not match it. Callers are those attributed to the definition,
+as with 'agentdx trace callers --exhaustive'; --callers caps them per
+symbol. Symbols and callers need the symbol index built by 'agentdx watch'.
+
+Examples:
+  agentdx explain cli/root.go:42
+  agentdx explain internal/auth/login.go:88 --query "session expiry" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	explainCmd.Flags().StringVarP(&explainQuery, "query", "q", "", "Score the chunks for this search query")
+	explainCmd.Flags().IntVar(&explainCallers, "callers", 10, "Maximum number of callers per symbol (0 for no limit)")
+	explainCmd.Flags().StringVarP(&explainWorkspace, "workspace", "w", "", "Look the file up in the named workspace (see workspaces in config)")
+	explainCmd.Flags().BoolVar(&explainJSON, "json", false, "Output the explanation in JSON format")
+	rootCmd.AddCommand(explainCmd)
+}
+
+// parseLocation parses "<file>:<line>".
+func parseLocation(location string) (string, int, error) {
+	idx := strings.LastIndex(location, ":")
+	if idx <= 0 {
+		return "", 0, errcode.New(errcode.InvalidArgs, "invalid location %q: expected <file>:<line>", location)
+	}
+	line, err := strconv.Atoi(location[idx+1:])
+	if err != nil || line < 1 {
+		return "", 0, errcode.New(errcode.InvalidArgs, "invalid line in %q", location)
+	}
+	return location[:idx], line, nil
+}
+
+func runExplain(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if explainCallers < 0 {
+		return errcode.New(errcode.InvalidArgs, "--callers must not be negative, got %d", explainCallers)
+	}
+	file, line, err := parseLocation(args[0])
+	if err != nil {
+		return err
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	if file, err = relativeToRoot(projectRoot, file); err != nil {
+		return err
+	}
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	st, err := openWorkspaceStore(ctx, cfg, projectRoot, explainWorkspace)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+
+	// Without a symbol index, the chunks are still explained
+	var symbolStore trace.SymbolStore
+	if ss, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot); err == nil {
+		defer ss.Close()
+		if stats, err := ss.GetStats(ctx); err == nil && stats.TotalSymbols > 0 {
+			symbolStore = ss
+		}
+	}
+	if symbolStore == nil {
+		fmt.Fprintln(os.Stderr, "Warning: symbol index is empty; run 'agentdx watch' to list the symbols and callers of the location")
+	}
+
+	exp, err := search.Explain(ctx, st, symbolStore, file, line, explainQuery, explainCallers)
+	if err != nil {
+		return err
+	}
+	displayExplanation(exp, paths)
+
+	if explainJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exp)
+	}
+	printExplanation(exp)
+	return nil
+}
+
+// displayExplanation rewrites the file paths of exp in the --path-style
+// style.
+func displayExplanation(exp *search.Explanation, paths *search.Paths) {
+	exp.File = paths.Display(exp.File)
+	for i := range exp.Symbols {
+		sym := &exp.Symbols[i]
+		sym.File = paths.Display(sym.File)
+		for j := range sym.Callers {
+			sym.Callers[j].Symbol.File = paths.Display(sym.Callers[j].Symbol.File)
+			sym.Callers[j].CallSite.File = paths.Display(sym.Callers[j].CallSite.File)
+		}
+	}
+}
+
+func printExplanation(exp *search.Explanation) {
+	fmt.Printf("%s:%d\n", exp.File, exp.Line)
+
+	fmt.Printf("\nChunks (%d):\n", len(exp.Chunks))
+	fmt.Println(strings.Repeat("-", 60))
+	for _, c := range exp.Chunks {
+		fmt.Printf("\nLines %d-%d", c.StartLine, c.EndLine)
+		if c.Type != "" {
+			fmt.Printf(" (%s)", c.Type)
+		}
+		fmt.Println()
+		if c.Symbol != "" {
+			fmt.Printf("   Symbol: %s (%s)\n", c.Symbol, c.SymbolKind)
+		}
+		if c.Package != "" {
+			fmt.Printf("   Package: %s\n", c.Package)
+		}
+		switch {
+		case c.Score != nil:
+			fmt.Printf("   Score: %.4f for %q\n", *c.Score, exp.Query)
+		case exp.Query != "":
+			fmt.Printf("   Score: no match for %q\n", exp.Query)
+		}
+		fmt.Printf("   First line: %s\n", truncate(firstCodeLine(c.Content), 80))
+	}
+
+	fmt.Printf("\nSymbols (%d):\n", len(exp.Symbols))
+	fmt.Println(strings.Repeat("-", 60))
+	if len(exp.Symbols) == 0 {
+		fmt.Println("No symbols found.")
+		return
+	}
+	for _, sym := range exp.Symbols {
+		name := sym.Name
+		if sym.Receiver != "" {
+			name = "(" + sym.Receiver + ")." + name
+		}
+		fmt.Printf("\n%s (%s) @ %s:%d\n", name, sym.Kind, sym.File, sym.Line)
+		if sym.Signature != "" {
+			fmt.Printf("   %s\n", truncate(sym.Signature, 100))
+		}
+		if sym.TotalCallers > len(sym.Callers) {
+			fmt.Printf("Callers (%d, showing %d, use --callers to see more):\n", sym.TotalCallers, len(sym.Callers))
+		} else {
+			fmt.Printf("Callers (%d):\n", sym.TotalCallers)
+		}
+		printCallers(sym.Callers)
+	}
+}
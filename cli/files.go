@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
 	"github.com/doveaia/agentdx/store"
 	"github.com/spf13/cobra"
 )
@@ -18,17 +21,23 @@ var (
 	filesLimit   int
 	filesJSON    bool
 	filesCompact bool
+	filesFormat  string
+	filesSince   string
+	filesSort    string
 )
 
 // FileResultJSON is the full output struct for JSON mode
 type FileResultJSON struct {
 	Path    string `json:"path"`
+	AbsPath string `json:"abs_path"`
 	ModTime string `json:"mod_time"`
+	Chunks  int    `json:"chunks"`
 }
 
 // FileResultCompactJSON is the minimal output struct for compact mode
 type FileResultCompactJSON struct {
-	Path string `json:"path"`
+	Path    string `json:"path"`
+	AbsPath string `json:"abs_path"`
 }
 
 var filesCmd = &cobra.Command{
@@ -42,7 +51,17 @@ Patterns without path separators are matched recursively by default:
 
 Use explicit paths to limit scope:
   internal/**   - All files under internal/
-  cli/*.go      - Go files only in cli/ directory`,
+  cli/*.go      - Go files only in cli/ directory
+
+--since lists the files that changed lately: those modified within an age
+(2h, 7d, 2w) when they were indexed, or those git reports as changed
+between a revision (main, HEAD~5, a tag) and the working tree. --sort
+orders files by path, by modification time (mtime, newest first) or by
+number of chunks (chunks, largest first).
+
+Examples:
+  agentdx files "*" --since 2h --sort mtime
+  agentdx files "*.go" --since main --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFiles,
 }
@@ -51,6 +70,9 @@ func init() {
 	filesCmd.Flags().IntVarP(&filesLimit, "limit", "n", 0, "Maximum number of results (0 = unlimited)")
 	filesCmd.Flags().BoolVarP(&filesJSON, "json", "j", false, "Output results in JSON format")
 	filesCmd.Flags().BoolVarP(&filesCompact, "compact", "c", false, "Output minimal JSON (requires --json)")
+	filesCmd.Flags().StringVar(&filesFormat, "format", formatText, "Output format: text, md (Markdown table) or csv")
+	filesCmd.Flags().StringVar(&filesSince, "since", "", "Only list files modified within an age (2h, 7d) or changed since a git revision")
+	filesCmd.Flags().StringVar(&filesSort, "sort", search.FileSortPath, "Order: "+strings.Join(search.FileSorts, ", "))
 }
 
 func runFiles(cmd *cobra.Command, args []string) error {
@@ -59,73 +81,98 @@ func runFiles(cmd *cobra.Command, args []string) error {
 
 	// Validate flag combination
 	if filesCompact && !filesJSON {
-		return fmt.Errorf("--compact flag requires --json flag")
+		return errcode.New(errcode.InvalidArgs, "--compact flag requires --json flag")
+	}
+	if err := validateFormat(filesFormat, filesJSON, formatMarkdown, formatCSV); err != nil {
+		return err
 	}
 
 	// Find project root
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
-		if filesJSON {
-			return outputFilesError(err)
-		}
+		return err
+	}
+
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
 		return err
 	}
 
 	// Load configuration
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
-		if filesJSON {
-			return outputFilesError(fmt.Errorf("failed to load configuration: %w", err))
-		}
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize PostgreSQL FTS store
-	st, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, projectRoot)
+	// Open index store
+	st, err := openStore(ctx, cfg, projectRoot)
 	if err != nil {
-		if filesJSON {
-			return outputFilesError(fmt.Errorf("failed to connect to postgres: %w", err))
-		}
-		return fmt.Errorf("failed to connect to postgres: %w", err)
+		return fmt.Errorf("failed to open index store: %w", err)
 	}
 	defer st.Close()
 
 	// Get all files with stats
 	allFiles, err := st.ListFilesWithStats(ctx)
 	if err != nil {
-		if filesJSON {
-			return outputFilesError(fmt.Errorf("failed to list files: %w", err))
-		}
 		return fmt.Errorf("failed to list files: %w", err)
 	}
 
+	// Tell an empty index from a pattern without matches
+	if len(allFiles) == 0 {
+		return search.ErrNoIndex
+	}
+
 	// Filter by glob pattern
 	matched, err := filterByGlob(allFiles, pattern)
 	if err != nil {
-		if filesJSON {
-			return outputFilesError(err)
-		}
 		return err
 	}
 
-	// Sort alphabetically by path
-	sort.Slice(matched, func(i, j int) bool {
-		return matched[i].Path < matched[j].Path
-	})
+	// Keep the files that changed lately
+	if filesSince != "" {
+		if matched, err = search.FilesSince(matched, projectRoot, filesSince, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := search.SortFiles(matched, filesSort); err != nil {
+		return err
+	}
 
 	// Apply limit if specified
 	if filesLimit > 0 && len(matched) > filesLimit {
 		matched = matched[:filesLimit]
 	}
 
-	// Output results
+	if err := outputFiles(matched, paths, pattern); err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		return errcode.ErrNoResults
+	}
+	return nil
+}
+
+// outputFiles prints the matched files in the selected format.
+func outputFiles(matched []store.FileStats, paths *search.Paths, pattern string) error {
 	if filesJSON {
 		if filesCompact {
-			return outputFilesCompactJSON(matched)
+			return outputFilesCompactJSON(matched, paths)
 		}
-		return outputFilesJSON(matched)
+		return outputFilesJSON(matched, paths)
 	}
 
+	matched = displayFiles(matched, paths)
+	switch filesFormat {
+	case formatMarkdown:
+		return writeFilesMarkdown(os.Stdout, matched)
+	case formatCSV:
+		return writeFilesCSV(os.Stdout, matched)
+	}
+
+	if filesSince != "" || filesSort != search.FileSortPath {
+		return outputFilesTable(matched, pattern, time.Now())
+	}
 	outputFilesText(matched, pattern)
 	return nil
 }
@@ -151,7 +198,7 @@ func filterByGlob(files []store.FileStats, pattern string) ([]store.FileStats, e
 	for _, f := range files {
 		ok, err := doublestar.Match(normalizedPattern, f.Path)
 		if err != nil {
-			return nil, fmt.Errorf("invalid glob pattern: %w", err)
+			return nil, errcode.New(errcode.InvalidArgs, "invalid glob pattern: %v", err)
 		}
 		if ok {
 			matched = append(matched, f)
@@ -172,13 +219,30 @@ func outputFilesText(files []store.FileStats, pattern string) {
 	}
 }
 
+// outputFilesTable outputs files with when they were modified and their
+// number of chunks, for listings by recency or size.
+func outputFilesTable(files []store.FileStats, pattern string, now time.Time) error {
+	if len(files) == 0 {
+		fmt.Println("No files found matching pattern.")
+		return nil
+	}
+	fmt.Printf("Found %d files matching %q:\n\n", len(files), pattern)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range files {
+		fmt.Fprintf(w, "%s\t%s ago\t%d chunks\n", f.Path, formatUptime(now.Sub(f.ModTime)), f.ChunkCount)
+	}
+	return w.Flush()
+}
+
 // outputFilesJSON outputs files in full JSON format
-func outputFilesJSON(files []store.FileStats) error {
+func outputFilesJSON(files []store.FileStats, paths *search.Paths) error {
 	results := make([]FileResultJSON, len(files))
 	for i, f := range files {
 		results[i] = FileResultJSON{
 			Path:    f.Path,
+			AbsPath: paths.Abs(f.Path),
 			ModTime: f.ModTime.Format("2006-01-02T15:04:05Z"),
+			Chunks:  f.ChunkCount,
 		}
 	}
 
@@ -188,11 +252,12 @@ func outputFilesJSON(files []store.FileStats) error {
 }
 
 // outputFilesCompactJSON outputs files in minimal JSON format
-func outputFilesCompactJSON(files []store.FileStats) error {
+func outputFilesCompactJSON(files []store.FileStats, paths *search.Paths) error {
 	results := make([]FileResultCompactJSON, len(files))
 	for i, f := range files {
 		results[i] = FileResultCompactJSON{
-			Path: f.Path,
+			Path:    f.Path,
+			AbsPath: paths.Abs(f.Path),
 		}
 	}
 
@@ -200,11 +265,3 @@ func outputFilesCompactJSON(files []store.FileStats) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(results)
 }
-
-// outputFilesError outputs an error in JSON format
-func outputFilesError(err error) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	_ = encoder.Encode(map[string]string{"error": err.Error()})
-	return nil
-}
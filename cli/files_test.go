@@ -149,7 +149,8 @@ func TestFileResultJSONStruct(t *testing.T) {
 
 func TestFileResultCompactJSONStruct(t *testing.T) {
 	result := FileResultCompactJSON{
-		Path: "path/to/file.go",
+		Path:    "path/to/file.go",
+		AbsPath: "/repo/path/to/file.go",
 	}
 
 	data, err := json.Marshal(result)
@@ -164,9 +165,9 @@ func TestFileResultCompactJSONStruct(t *testing.T) {
 		t.Error("expected 'path' field to be present")
 	}
 
-	// Verify only path field exists
-	if len(decoded) != 1 {
-		t.Errorf("expected only 1 field in compact struct, got %d", len(decoded))
+	// Verify only the path fields exist
+	if len(decoded) != 2 {
+		t.Errorf("expected only path and abs_path in compact struct, got %d fields", len(decoded))
 	}
 }
 
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// Output formats accepted by --format.
+const (
+	formatText     = "text"
+	formatMarkdown = "md"
+	formatCSV      = "csv"
+	formatSARIF    = "sarif"
+)
+
+// validateFormat checks a --format value against text and the other
+// formats the command supports, and its combination with --json.
+func validateFormat(format string, jsonOutput bool, formats ...string) error {
+	if format == formatText {
+		return nil
+	}
+	for _, f := range formats {
+		if f != format {
+			continue
+		}
+		if jsonOutput {
+			return errcode.New(errcode.InvalidArgs, "--format %s cannot be combined with --json", format)
+		}
+		return nil
+	}
+	expected := append([]string{formatText}, formats...)
+	list := strings.Join(expected[:len(expected)-1], ", ") + " or " + expected[len(expected)-1]
+	return errcode.New(errcode.InvalidArgs, "unknown format %q (expected %s)", format, list)
+}
+
+// outputPaths returns the resolver for paths in the --path-style style.
+func outputPaths(projectRoot string) (*search.Paths, error) {
+	return search.NewPaths(projectRoot, pathStyle)
+}
+
+// displaySearchResults returns a copy of results with file paths in the
+// --path-style style.
+func displaySearchResults(results []store.SearchResult, paths *search.Paths) []store.SearchResult {
+	shown := make([]store.SearchResult, len(results))
+	for i, r := range results {
+		r.Chunk.FilePath = paths.Display(r.Chunk.FilePath)
+		shown[i] = r
+	}
+	return shown
+}
+
+// displayFiles returns a copy of files with paths in the --path-style style.
+func displayFiles(files []store.FileStats, paths *search.Paths) []store.FileStats {
+	shown := make([]store.FileStats, len(files))
+	for i, f := range files {
+		f.Path = paths.Display(f.Path)
+		shown[i] = f
+	}
+	return shown
+}
+
+// markdownLink returns a link to a path for use in a Markdown table cell.
+// Spaces and parentheses are percent-encoded so they do not end the target.
+func markdownLink(text, path, fragment string) string {
+	text = strings.NewReplacer(`\`, `\\`, "|", `\|`, "[", `\[`, "]", `\]`).Replace(text)
+	target := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "|", "%7C").Replace(path)
+	return fmt.Sprintf("[%s](%s%s)", text, target, fragment)
+}
+
+// markdownCode returns s as an inline code span for a Markdown table cell.
+// The fence is one backtick longer than the longest run inside s.
+func markdownCode(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// firstCodeLine returns the first non-empty line of a chunk, skipping the
+// "File: xxx" header added by the chunker.
+func firstCodeLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "File: ") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// chunkCode returns the content of a chunk without the "File: xxx" header
+// added by the chunker.
+func chunkCode(content string) string {
+	if strings.HasPrefix(content, "File: ") {
+		if _, rest, ok := strings.Cut(content, "\n"); ok {
+			return strings.TrimPrefix(rest, "\n")
+		}
+		return ""
+	}
+	return content
+}
+
+// markdownCodeBlock returns code as a fenced Markdown code block tagged
+// with the extension of path. The fence is longer than any backtick run
+// inside code.
+func markdownCodeBlock(path, code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	lang := strings.TrimPrefix(filepath.Ext(path), ".")
+	return fence + lang + "\n" + strings.TrimRight(code, "\n") + "\n" + fence + "\n"
+}
+
+// writeSearchMarkdown writes search results for agents: a heading with a
+// path:line link per result, its score and notes, and its code in a fenced
+// block. note, if any, ends the output.
+func writeSearchMarkdown(w io.Writer, query string, results []store.SearchResult, note string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Search results for %q\n", query)
+	if len(results) == 0 {
+		b.WriteString("\nNo results found.\n")
+	}
+	for i, r := range results {
+		ref := fmt.Sprintf("%s:%d-%d", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+		fragment := fmt.Sprintf("#L%d-L%d", r.Chunk.StartLine, r.Chunk.EndLine)
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, markdownLink(ref, r.Chunk.FilePath, fragment))
+		fmt.Fprintf(&b, "Score: %.4f", r.Score)
+		if r.Confidence != "" {
+			fmt.Fprintf(&b, " (%s confidence)", r.Confidence)
+		}
+		b.WriteString("\n")
+		if cells := search.FormatCells(r.Cells); cells != "" {
+			fmt.Fprintf(&b, "\nNotebook: %s\n", cells)
+		}
+		if r.Blame != nil {
+			fmt.Fprintf(&b, "\nLast change: %s\n", r.Blame.Summary())
+		}
+		for _, n := range r.Notes {
+			fmt.Fprintf(&b, "\n> Note #%d (lines %d-%d): %s\n", n.ID, n.StartLine, n.EndLine, n.Text)
+		}
+		b.WriteString("\n" + markdownCodeBlock(r.Chunk.FilePath, chunkCode(r.Chunk.Content)))
+	}
+	if note != "" {
+		fmt.Fprintf(&b, "\n> Note: %s\n", note)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTraceMarkdown writes a trace result for agents: the traced symbol,
+// then a section per kind of result with path:line links and the code of
+// each call site in a fenced block.
+func writeTraceMarkdown(w io.Writer, result trace.TraceResult) error {
+	var b strings.Builder
+	symbolLink := func(sym trace.Symbol) string {
+		return markdownLink(fmt.Sprintf("%s:%d", sym.File, sym.Line), sym.File, fmt.Sprintf("#L%d", sym.Line))
+	}
+	callers := func(title string, infos []trace.CallerInfo) {
+		fmt.Fprintf(&b, "\n## %s (%d)\n", title, len(infos))
+		for i, c := range infos {
+			site := markdownLink(fmt.Sprintf("%s:%d", c.CallSite.File, c.CallSite.Line), c.CallSite.File, fmt.Sprintf("#L%d", c.CallSite.Line))
+			fmt.Fprintf(&b, "\n%d. %s at %s%s\n", i+1, markdownCode(c.Symbol.Name), site, traceLevel(c.Depth, c.Via, c.Cycle))
+			if c.CallSite.Context != "" {
+				b.WriteString("\n" + markdownCodeBlock(c.CallSite.File, c.CallSite.Context))
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "# Trace of %q\n", result.Query)
+	if result.Symbol != nil {
+		fmt.Fprintf(&b, "\n%s (%s) defined at %s\n", markdownCode(result.Symbol.Name), result.Symbol.Kind, symbolLink(*result.Symbol))
+	}
+	if result.Size() == 0 {
+		b.WriteString("\nNo results found.\n")
+	}
+	if len(result.Callers) > 0 {
+		callers("Callers", result.Callers)
+	}
+	for _, d := range result.Definitions {
+		if len(d.Callers) == 0 {
+			continue
+		}
+		callers(fmt.Sprintf("Callers of %s:%d", d.Symbol.File, d.Symbol.Line), d.Callers)
+	}
+	if len(result.Unresolved) > 0 {
+		callers("Unresolved callers", result.Unresolved)
+	}
+	if len(result.Callees) > 0 {
+		fmt.Fprintf(&b, "\n## Callees (%d)\n", len(result.Callees))
+		for i, c := range result.Callees {
+			site := markdownLink(fmt.Sprintf("%s:%d", c.CallSite.File, c.CallSite.Line), c.CallSite.File, fmt.Sprintf("#L%d", c.CallSite.Line))
+			fmt.Fprintf(&b, "\n%d. %s at %s%s", i+1, markdownCode(c.Symbol.Name), site, traceLevel(c.Depth, c.Via, c.Cycle))
+			if c.Symbol.File != "" {
+				fmt.Fprintf(&b, ", defined at %s", symbolLink(c.Symbol))
+			}
+			b.WriteString("\n")
+			if c.CallSite.Context != "" {
+				b.WriteString("\n" + markdownCodeBlock(c.CallSite.File, c.CallSite.Context))
+			}
+		}
+	}
+	if result.Graph != nil && len(result.Graph.Edges) > 0 {
+		fmt.Fprintf(&b, "\n## Call graph (depth %d, %d edges)\n\n", result.Graph.Depth, len(result.Graph.Edges))
+		for _, e := range result.Graph.Edges {
+			site := markdownLink(fmt.Sprintf("%s:%d", e.File, e.Line), e.File, fmt.Sprintf("#L%d", e.Line))
+			fmt.Fprintf(&b, "- %s → %s at %s\n", markdownCode(e.Caller), markdownCode(e.Callee), site)
+		}
+	}
+	if len(result.Implementations) > 0 {
+		fmt.Fprintf(&b, "\n## Implementations (%d)\n\n", len(result.Implementations))
+		for _, impl := range result.Implementations {
+			name, decl := impl.Type.Name, impl.Type
+			if impl.Method != nil {
+				name, decl = impl.Type.Name+"."+impl.Method.Name, *impl.Method
+			}
+			fmt.Fprintf(&b, "- %s implements %s at %s\n", markdownCode(name), markdownCode(impl.Interface.Name), symbolLink(decl))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSearchCSV writes search results as CSV with a header row.
+func writeSearchCSV(w io.Writer, results []store.SearchResult) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"file_path", "start_line", "end_line", "score", "content"})
+	for _, r := range results {
+		_ = cw.Write([]string{
+			r.Chunk.FilePath,
+			strconv.Itoa(r.Chunk.StartLine),
+			strconv.Itoa(r.Chunk.EndLine),
+			strconv.FormatFloat(float64(r.Score), 'f', 4, 32),
+			r.Chunk.Content,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeFilesMarkdown writes indexed files as a Markdown table.
+func writeFilesMarkdown(w io.Writer, files []store.FileStats) error {
+	var b strings.Builder
+	b.WriteString("| File | Modified |\n")
+	b.WriteString("|------|----------|\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "| %s | %s |\n", markdownLink(f.Path, f.Path, ""), f.ModTime.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFilesCSV writes indexed files as CSV with a header row.
+func writeFilesCSV(w io.Writer, files []store.FileStats) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"path", "mod_time"})
+	for _, f := range files {
+		_ = cw.Write([]string{f.Path, f.ModTime.UTC().Format("2006-01-02T15:04:05Z")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, validateFormat("text", false))
+	assert.NoError(t, validateFormat("text", true))
+	assert.NoError(t, validateFormat("md", false, formatMarkdown, formatCSV))
+	assert.NoError(t, validateFormat("csv", false, formatMarkdown, formatCSV))
+	assert.Error(t, validateFormat("md", true, formatMarkdown, formatCSV))
+	assert.Error(t, validateFormat("xml", false, formatMarkdown, formatCSV))
+
+	err := validateFormat("sarif", false, formatMarkdown, formatCSV)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected text, md or csv")
+	assert.NoError(t, validateFormat("sarif", false, formatMarkdown, formatSARIF))
+}
+
+func TestWriteSearchMarkdown(t *testing.T) {
+	results := []store.SearchResult{
+		{
+			Chunk: store.Chunk{
+				FilePath:  "cli/search.go",
+				StartLine: 10,
+				EndLine:   12,
+				Content:   "File: cli/search.go\n\nif a || b {\n\treturn ```x```\n}",
+			},
+			Score:      0.95,
+			Confidence: "high",
+			Notes:      []store.Note{{ID: 2, StartLine: 10, EndLine: 11, Text: "keep in sync"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSearchMarkdown(&buf, "auth", results, "low confidence"))
+
+	want := "# Search results for \"auth\"\n" +
+		"\n## 1. [cli/search.go:10-12](cli/search.go#L10-L12)\n\n" +
+		"Score: 0.9500 (high confidence)\n" +
+		"\n> Note #2 (lines 10-11): keep in sync\n" +
+		"\n````go\nif a || b {\n\treturn ```x```\n}\n````\n" +
+		"\n> Note: low confidence\n"
+	assert.Equal(t, want, buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeSearchMarkdown(&buf, "auth", nil, ""))
+	assert.Equal(t, "# Search results for \"auth\"\n\nNo results found.\n", buf.String())
+}
+
+func TestWriteTraceMarkdown(t *testing.T) {
+	result := trace.TraceResult{
+		Query:  "Login",
+		Symbol: &trace.Symbol{Name: "Login", Kind: trace.KindFunction, File: "auth/login.go", Line: 12},
+		Callers: []trace.CallerInfo{{
+			Symbol:   trace.Symbol{Name: "Handle"},
+			CallSite: trace.CallSite{File: "api/handler.go", Line: 40, Context: "err := Login(ctx, user)"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTraceMarkdown(&buf, result))
+	assert.Equal(t, "# Trace of \"Login\"\n"+
+		"\n`Login` (function) defined at [auth/login.go:12](auth/login.go#L12)\n"+
+		"\n## Callers (1)\n"+
+		"\n1. `Handle` at [api/handler.go:40](api/handler.go#L40)\n"+
+		"\n```go\nerr := Login(ctx, user)\n```\n", buf.String())
+}
+
+func TestWriteSearchSARIF(t *testing.T) {
+	results := []store.SearchResult{{
+		Chunk: store.Chunk{FilePath: "cli/my search.go", StartLine: 3, EndLine: 5, Content: "File: cli/my search.go\n\nfunc A() {}"},
+		Score: 2.5,
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSearchSARIF(&buf, "/src/app", "auth", results))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "agentdx", run.Tool.Driver.Name)
+	assert.Equal(t, "file:///src/app/", run.OriginalURIBaseIDs["SRCROOT"].URI)
+	require.Len(t, run.Results, 1)
+	r := run.Results[0]
+	assert.Equal(t, "search-result", r.RuleID)
+	assert.Equal(t, "note", r.Level)
+	loc := r.Locations[0].PhysicalLocation
+	assert.Equal(t, "cli/my%20search.go", loc.ArtifactLocation.URI)
+	assert.Equal(t, "SRCROOT", loc.ArtifactLocation.URIBaseID)
+	assert.Equal(t, &sarifRegion{StartLine: 3, EndLine: 5, Snippet: &sarifMessage{Text: "func A() {}"}}, loc.Region)
+}
+
+func TestWriteTraceSARIF(t *testing.T) {
+	result := trace.TraceResult{
+		Query:  "Store",
+		Symbol: &trace.Symbol{Name: "Store", Kind: trace.KindInterface, File: "store/store.go", Line: 10},
+		Implementations: []trace.Implementation{{
+			Interface: trace.Symbol{Name: "Store", File: "store/store.go", Line: 10},
+			Type:      trace.Symbol{Name: "Disk", File: "store/disk.go", Line: 4},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTraceSARIF(&buf, "/src/app", result))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs[0].Results, 1)
+	r := log.Runs[0].Results[0]
+	assert.Equal(t, "trace-implementation", r.RuleID)
+	assert.Equal(t, "Disk implements Store", r.Message.Text)
+	assert.Equal(t, "store/disk.go", r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Len(t, r.RelatedLocations, 1)
+	assert.Equal(t, "store/store.go", r.RelatedLocations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestMarkdownCode(t *testing.T) {
+	assert.Equal(t, "`x := 1`", markdownCode("x := 1"))
+	assert.Equal(t, "`` return `x` ``", markdownCode("return `x`"))
+}
+
+func TestWriteSearchCSV(t *testing.T) {
+	results := []store.SearchResult{
+		{
+			Chunk: store.Chunk{FilePath: "a.go", StartLine: 1, EndLine: 3, Content: "func A() {\n\tb(\"x,y\")\n}"},
+			Score: 1.5,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSearchCSV(&buf, results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"file_path", "start_line", "end_line", "score", "content"}, records[0])
+	assert.Equal(t, []string{"a.go", "1", "3", "1.5000", results[0].Chunk.Content}, records[1])
+}
+
+func TestWriteFilesMarkdownAndCSV(t *testing.T) {
+	modTime := time.Date(2026, 1, 19, 10, 30, 0, 0, time.UTC)
+	files := []store.FileStats{
+		{Path: "docs/my notes.md", ModTime: modTime},
+		{Path: "cli/files.go", ModTime: modTime},
+	}
+
+	var md bytes.Buffer
+	require.NoError(t, writeFilesMarkdown(&md, files))
+	assert.Equal(t, "| File | Modified |\n"+
+		"|------|----------|\n"+
+		"| [docs/my notes.md](docs/my%20notes.md) | 2026-01-19T10:30:00Z |\n"+
+		"| [cli/files.go](cli/files.go) | 2026-01-19T10:30:00Z |\n", md.String())
+
+	var out bytes.Buffer
+	require.NoError(t, writeFilesCSV(&out, files))
+	assert.Equal(t, "path,mod_time\ndocs/my notes.md,2026-01-19T10:30:00Z\ncli/files.go,2026-01-19T10:30:00Z\n", out.String())
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fallbackTool   string
+	fallbackQuery  string
+	fallbackReason string
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook <subcommand>",
+	Short: "Commands run by agent hooks",
+}
+
+var hookReportFallbackCmd = &cobra.Command{
+	Use:   "report-fallback",
+	Short: "Record a search an agent ran without agentdx",
+	Long: `Record a fallback incident in .agentdx/fallbacks.log: an agent searching
+with its own tools (Grep, Glob) instead of agentdx, or an agentdx search
+that found nothing or failed. 'agentdx stats fallbacks' shows where agents
+fall back, so that search can be tuned there.
+
+The hooks installed by 'agentdx setup' run it. The tool name, query and
+session are read from the hook input, the JSON a Claude Code hook receives
+on stdin, and --tool, --query and --reason override them. The command
+prints nothing and never fails, so that it cannot get in the agent's way.`,
+	Example: `  agentdx hook report-fallback < hook-input.json
+  agentdx hook report-fallback --tool "agentdx search" --query "billing" --reason empty`,
+	Args: cobra.NoArgs,
+	RunE: runHookReportFallback,
+}
+
+func init() {
+	hookReportFallbackCmd.Flags().StringVar(&fallbackTool, "tool", "", "Tool the agent used instead of agentdx, or the agentdx command that failed")
+	hookReportFallbackCmd.Flags().StringVar(&fallbackQuery, "query", "", "Pattern or query of the search")
+	hookReportFallbackCmd.Flags().StringVar(&fallbackReason, "reason", "", "Why agentdx was not used: empty or an error code")
+
+	hookCmd.AddCommand(hookReportFallbackCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+// hookInput is the part of the JSON a Claude Code hook receives on stdin
+// that describes the tool call.
+type hookInput struct {
+	SessionID string `json:"session_id"`
+	Cwd       string `json:"cwd"`
+	ToolName  string `json:"tool_name"`
+	ToolInput struct {
+		Pattern string `json:"pattern"`
+		Path    string `json:"path"`
+		Glob    string `json:"glob"`
+		Query   string `json:"query"`
+	} `json:"tool_input"`
+}
+
+// fallbackRecord builds the record of a hook call from its input and the
+// flags overriding it.
+func fallbackRecord(in hookInput, now time.Time) search.FallbackRecord {
+	rec := search.FallbackRecord{
+		Time:    now,
+		Tool:    in.ToolName,
+		Query:   in.ToolInput.Pattern,
+		Path:    in.ToolInput.Path,
+		Reason:  fallbackReason,
+		Session: in.SessionID,
+	}
+	if rec.Query == "" {
+		rec.Query = in.ToolInput.Query
+	}
+	if rec.Path == "" {
+		rec.Path = in.ToolInput.Glob
+	}
+	if fallbackTool != "" {
+		rec.Tool = fallbackTool
+	}
+	if fallbackQuery != "" {
+		rec.Query = fallbackQuery
+	}
+	if rec.Tool == "" {
+		rec.Tool = "unknown"
+	}
+	return rec
+}
+
+func runHookReportFallback(_ *cobra.Command, _ []string) error {
+	var in hookInput
+	if !stdinIsTerminal() {
+		data, err := io.ReadAll(io.LimitReader(os.Stdin, 1<<20))
+		if err == nil && len(data) > 0 {
+			// Hook input is optional; flags describe the call without it
+			_ = json.Unmarshal(data, &in)
+		}
+	}
+
+	dir := in.Cwd
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return nil
+		}
+	}
+	projectRoot, err := config.FindProjectRootFrom(dir)
+	if err != nil {
+		// Not an agentdx project: nothing to report to
+		return nil
+	}
+
+	if err := search.RecordFallback(config.GetFallbackLogPath(projectRoot), fallbackRecord(in, time.Now())); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
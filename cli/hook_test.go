@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackRecord(t *testing.T) {
+	var in hookInput
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"session_id": "s1",
+		"cwd": "/project",
+		"hook_event_name": "PreToolUse",
+		"tool_name": "Grep",
+		"tool_input": {"pattern": "func Charge", "path": "billing"}
+	}`), &in))
+
+	now := time.Now()
+	rec := fallbackRecord(in, now)
+	assert.Equal(t, "Grep", rec.Tool)
+	assert.Equal(t, "func Charge", rec.Query)
+	assert.Equal(t, "billing", rec.Path)
+	assert.Equal(t, "s1", rec.Session)
+	assert.True(t, rec.Time.Equal(now))
+
+	// Flags override the hook input
+	fallbackTool, fallbackQuery, fallbackReason = "agentdx search", "billing", "empty"
+	t.Cleanup(func() { fallbackTool, fallbackQuery, fallbackReason = "", "", "" })
+	rec = fallbackRecord(hookInput{}, now)
+	assert.Equal(t, "agentdx search", rec.Tool)
+	assert.Equal(t, "billing", rec.Query)
+	assert.Equal(t, "empty", rec.Reason)
+}
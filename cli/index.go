@@ -0,0 +1,411 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"github.com/spf13/cobra"
+)
+
+// gcGracePeriod protects chunks saved by an indexer that has not yet
+// written the document referencing them.
+const gcGracePeriod = time.Hour
+
+var (
+	indexChangedSince  string
+	indexPruneDeleted  bool
+	indexJSON          bool
+	indexProfile       string
+	indexGCJSON        bool
+	indexVerifyFix     bool
+	indexVerifyJSON    bool
+	indexVerifyProfile string
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index the project once, or maintain the index",
+	Long: `Bring the index up to date with the files on disk and exit, without
+watching for changes: new and modified files are indexed and files gone
+from disk are removed.
+
+With --changed-since, only the files that git diff reports as changed
+between the given revision and the working tree are read. CI jobs that keep
+a shared PostgreSQL index per branch use it to update the index with the
+files changed since the last indexed commit instead of scanning the whole
+repository. Files deleted since the revision stay in the index unless
+--prune-deleted is given.
+
+The call graph is not updated; it is maintained by 'agentdx watch'.`,
+	Example: `  agentdx index
+  agentdx index --changed-since origin/main
+  agentdx index --changed-since "$BASE_SHA" --prune-deleted --json`,
+	Args: cobra.NoArgs,
+	RunE: runIndex,
+}
+
+var indexGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove orphaned chunks and compact the index",
+	Long: `Remove chunks that no document references, such as chunks of deleted
+projects and stale chunk IDs left by interrupted indexing, drop full-text
+index rows without a chunk, and compact the index tables.
+
+The pass covers every project in the index database. Chunks updated in the
+last hour are kept, so a concurrent indexer is never affected. 'agentdx
+watch' runs the same pass every index.watch.gc_interval_hours hours.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexGC,
+}
+
+var indexVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the index against the files on disk",
+	Long: `Cross-check the indexed documents with their chunks and with the files on
+disk of the project and its workspaces, and report:
+
+  missing_chunks  indexed files whose chunks are not in the index
+  hash_mismatch   files changed on disk since they were indexed
+  not_indexed     files on disk that are not in the index
+  not_on_disk     indexed files that no longer exist or are now ignored
+
+Run it after the watch daemon crashed or was killed during an update. With
+--fix, the affected files are indexed again and files gone from disk are
+removed. The command fails when discrepancies remain.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexVerify,
+}
+
+func init() {
+	indexCmd.Flags().StringVar(&indexChangedSince, "changed-since", "", "Only index files changed since this git revision")
+	indexCmd.Flags().BoolVar(&indexPruneDeleted, "prune-deleted", false, "With --changed-since, remove files deleted since the revision")
+	indexCmd.Flags().BoolVar(&indexJSON, "json", false, "Output in JSON format")
+	indexCmd.Flags().StringVar(&indexProfile, "profile", "", "Only index the paths of this indexing profile (see profiles in config; default: $"+config.IndexProfileEnv+")")
+	indexGCCmd.Flags().BoolVar(&indexGCJSON, "json", false, "Output in JSON format")
+	indexVerifyCmd.Flags().BoolVar(&indexVerifyFix, "fix", false, "Re-index or remove the files with discrepancies")
+	indexVerifyCmd.Flags().BoolVar(&indexVerifyJSON, "json", false, "Output in JSON format")
+	indexVerifyCmd.Flags().StringVar(&indexVerifyProfile, "profile", "", "Only verify the paths of this indexing profile (see profiles in config; default: $"+config.IndexProfileEnv+")")
+
+	indexCmd.AddCommand(indexGCCmd)
+	indexCmd.AddCommand(indexVerifyCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+// IndexJSON is the JSON output of 'agentdx index' for one index.
+type IndexJSON struct {
+	Workspace    string `json:"workspace,omitempty"`
+	FilesIndexed int    `json:"files_indexed"`
+	FilesRemoved int    `json:"files_removed"`
+	FilesSkipped int    `json:"files_skipped"`
+	Chunks       int    `json:"chunks"`
+}
+
+func runIndex(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if indexPruneDeleted && indexChangedSince == "" {
+		return errcode.New(errcode.InvalidArgs, "--prune-deleted requires --changed-since")
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var diff *indexer.Diff
+	if indexChangedSince != "" {
+		diff, err = indexer.GitDiff(projectRoot, indexChangedSince)
+		if err != nil {
+			return errcode.Wrap(errcode.InvalidArgs, fmt.Errorf("failed to list files changed since %s: %w", indexChangedSince, err))
+		}
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner, err := profileScanner(cfg, indexer.NewScanner(projectRoot, ignoreMatcher), indexProfile)
+	if err != nil {
+		return err
+	}
+	chunker, err := newFileChunker(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	st, err := store.Open(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+	indexes, err := openWorkspaceIndexes(ctx, cfg, projectRoot, opts, st, chunker, scanner)
+	if err != nil {
+		return err
+	}
+	defer closeWorkspaceIndexes(indexes)
+	checkIndexFingerprints(ctx, cfg, indexes)
+
+	// A progress bar only helps on a terminal; CI logs keep every redraw
+	progressFormat := progressFormatNone
+	if !indexJSON && diff == nil && term.IsTerminal(os.Stdout.Fd()) {
+		progressFormat = progressFormatBar
+	}
+	progress := newScanProgress(progressFormat, false, nil)
+
+	var results []IndexJSON
+	for _, wi := range indexes {
+		var stats *indexer.IndexStats
+		if diff != nil {
+			stats, err = wi.indexer.IndexDiff(ctx, diff, indexPruneDeleted)
+		} else {
+			stats, err = wi.indexer.IndexAllWithProgress(ctx, func(info indexer.ProgressInfo) {
+				progress.emit(indexer.ProgressEvent{
+					Phase:        indexer.PhaseIndex,
+					Workspace:    wi.name,
+					File:         info.CurrentFile,
+					Current:      info.Current,
+					Total:        info.Total,
+					Chunks:       info.Chunks,
+					ChunksPerSec: info.ChunksPerSec,
+				})
+			})
+			progress.clear()
+		}
+		if err != nil {
+			if wi.name != "" {
+				return fmt.Errorf("workspace %s: %w", wi.name, err)
+			}
+			return err
+		}
+		results = append(results, IndexJSON{
+			Workspace:    wi.name,
+			FilesIndexed: stats.FilesIndexed,
+			FilesRemoved: stats.FilesRemoved,
+			FilesSkipped: stats.FilesSkipped,
+			Chunks:       stats.ChunksCreated,
+		})
+	}
+
+	if indexJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	if diff != nil {
+		fmt.Printf("%d files changed and %d deleted since %s\n", len(diff.Changed), len(diff.Deleted), indexChangedSince)
+	}
+	for _, r := range results {
+		label := "Project"
+		if r.Workspace != "" {
+			label = "Workspace " + r.Workspace
+		}
+		fmt.Printf("%s: indexed %d files (%d chunks), removed %d\n", label, r.FilesIndexed, r.Chunks, r.FilesRemoved)
+	}
+	return nil
+}
+
+// IndexGCJSON is the JSON output of 'agentdx index gc'.
+type IndexGCJSON struct {
+	OrphanChunks    int   `json:"orphan_chunks"`
+	OrphanIndexRows int   `json:"orphan_index_rows"`
+	SizeBefore      int64 `json:"size_before"`
+	SizeAfter       int64 `json:"size_after"`
+	Reclaimed       int64 `json:"reclaimed"`
+}
+
+// newIndexer returns an indexer of the files scanner finds into st that
+// records the dominant symbol and the package of each chunk.
+func newIndexer(cfg *config.Config, projectRoot string, st store.CodeStore, chunker indexer.FileChunker, scanner *indexer.Scanner) *indexer.Indexer {
+	idx := indexer.NewIndexer(projectRoot, st, chunker, scanner)
+	// The symbol index warns when the trace mode is unavailable
+	extractor, err := trace.NewExtractor(cfg.Index.Trace.Mode)
+	if err != nil {
+		extractor, _ = trace.NewExtractor(trace.ModeFast)
+	}
+	packages := search.NewPackageResolver(projectRoot)
+	idx.SetMetadata(extractor, func(path string) string {
+		return packages.Resolve(path).Name
+	})
+	return idx
+}
+
+// newFileChunker returns the chunker of the configured strategy, stripping
+// boilerplate headers first when index.chunking.strip is set.
+func newFileChunker(cfg *config.Config) (indexer.FileChunker, error) {
+	chunking := cfg.Index.Chunking
+	chunker := indexer.NewFileChunker(chunking.Strategy, chunking.Size, chunking.Overlap)
+	if !chunking.Strip.Enabled() {
+		return chunker, nil
+	}
+	var patterns []string
+	if chunking.Strip.Headers {
+		patterns = append(patterns, indexer.DefaultHeaderPatterns...)
+	}
+	patterns = append(patterns, chunking.Strip.Patterns...)
+	filter, err := indexer.NewHeaderFilter(patterns, chunking.Strip.CommentLines, chunking.Strip.MaxLines)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, err)
+	}
+	return indexer.NewStrippingChunker(chunker, filter), nil
+}
+
+func runIndexGC(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := store.Open(ctx, store.OptionsFromConfig(cfg, projectRoot))
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+
+	stats, err := collectGarbage(ctx, st)
+	if err != nil {
+		return err
+	}
+
+	if indexGCJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(IndexGCJSON{
+			OrphanChunks:    stats.OrphanChunks,
+			OrphanIndexRows: stats.OrphanIndexRows,
+			SizeBefore:      stats.SizeBefore,
+			SizeAfter:       stats.SizeAfter,
+			Reclaimed:       stats.Reclaimed(),
+		})
+	}
+	fmt.Printf("Removed %d orphaned chunks and %d orphaned index rows\n", stats.OrphanChunks, stats.OrphanIndexRows)
+	fmt.Printf("Index size: %s -> %s (reclaimed %s)\n", formatBytes(stats.SizeBefore), formatBytes(stats.SizeAfter), formatBytes(stats.Reclaimed()))
+	return nil
+}
+
+// collectGarbage runs a garbage collection pass over the index database.
+func collectGarbage(ctx context.Context, st store.SearchStore) (*store.GCStats, error) {
+	stats, err := st.CollectGarbage(ctx, time.Now().Add(-gcGracePeriod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect garbage: %w", err)
+	}
+	return stats, nil
+}
+
+// IndexVerifyJSON is the JSON output of 'agentdx index verify' for one index.
+type IndexVerifyJSON struct {
+	Workspace string `json:"workspace,omitempty"`
+	indexer.VerifyReport
+	Fixed int `json:"fixed"`
+}
+
+func runIndexVerify(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner, err := profileScanner(cfg, indexer.NewScanner(projectRoot, ignoreMatcher), indexVerifyProfile)
+	if err != nil {
+		return err
+	}
+	chunker, err := newFileChunker(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	st, err := store.Open(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+	indexes, err := openWorkspaceIndexes(ctx, cfg, projectRoot, opts, st, chunker, scanner)
+	if err != nil {
+		return err
+	}
+	defer closeWorkspaceIndexes(indexes)
+
+	var results []IndexVerifyJSON
+	remaining := 0
+	for _, wi := range indexes {
+		report, err := wi.indexer.Verify(ctx)
+		if err != nil {
+			return err
+		}
+		result := IndexVerifyJSON{Workspace: wi.name, VerifyReport: *report}
+		if indexVerifyFix && len(report.Issues) > 0 {
+			result.Fixed, err = wi.indexer.Fix(ctx, report.Issues)
+			if err != nil && !indexVerifyJSON {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		remaining += len(report.Issues) - result.Fixed
+		results = append(results, result)
+	}
+
+	if indexVerifyJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			prefix := ""
+			if r.Workspace != "" {
+				prefix = "Workspace " + r.Workspace + ": "
+			}
+			fmt.Printf("%sChecked %d indexed files against %d files on disk\n", prefix, r.Documents, r.Files)
+			for _, issue := range r.Issues {
+				line := fmt.Sprintf("  %-15s %s", issue.Kind, issue.Path)
+				if issue.Detail != "" {
+					line += " (" + issue.Detail + ")"
+				}
+				fmt.Println(line)
+			}
+			if r.Fixed > 0 {
+				fmt.Printf("%sFixed %d of %d discrepancies\n", prefix, r.Fixed, len(r.Issues))
+			}
+		}
+	}
+
+	if remaining > 0 {
+		if indexVerifyFix {
+			return fmt.Errorf("%d discrepancies could not be fixed", remaining)
+		}
+		return fmt.Errorf("found %d discrepancies; run 'agentdx index verify --fix' to repair them", remaining)
+	}
+	if !indexVerifyJSON {
+		fmt.Println("Index is consistent with the files on disk")
+	}
+	return nil
+}
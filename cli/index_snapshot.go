@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	indexSnapshotRef    string
+	indexSnapshotList   bool
+	indexSnapshotDelete string
+	indexSnapshotJSON   bool
+)
+
+var indexSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Keep a copy of the index tagged with a commit",
+	Long: `Copy the current index of the project and its workspaces and tag the copy
+with the SHA of a commit (HEAD by default). 'agentdx search --at <commit>'
+then searches the code as it was indexed at that commit, after a refactor
+changed it, without checking out the old revision.
+
+The snapshot holds the indexed content, not the content of the commit:
+take it while the index matches the commit, for example before starting a
+refactor or in CI after indexing a release. A warning is printed when files
+on disk differ from the commit. Taking a snapshot of the same commit again
+replaces it.
+
+--list lists the snapshots of the project and --delete removes one.`,
+	Example: `  agentdx index snapshot
+  agentdx index snapshot --ref v1.4.0
+  agentdx search "token refresh" --at v1.4.0
+  agentdx index snapshot --list
+  agentdx index snapshot --delete 3f2a9c1`,
+	Args: cobra.NoArgs,
+	RunE: runIndexSnapshot,
+}
+
+func init() {
+	indexSnapshotCmd.Flags().StringVar(&indexSnapshotRef, "ref", "HEAD", "Commit to tag the snapshot with (SHA, branch or tag)")
+	indexSnapshotCmd.Flags().BoolVar(&indexSnapshotList, "list", false, "List the snapshots of the project")
+	indexSnapshotCmd.Flags().StringVar(&indexSnapshotDelete, "delete", "", "Delete the snapshot of this commit")
+	indexSnapshotCmd.Flags().BoolVar(&indexSnapshotJSON, "json", false, "Output in JSON format")
+	indexCmd.AddCommand(indexSnapshotCmd)
+}
+
+// IndexSnapshotJSON is the JSON output of 'agentdx index snapshot' for one
+// index.
+type IndexSnapshotJSON struct {
+	Workspace string `json:"workspace,omitempty"`
+	Commit    string `json:"commit"`
+	Files     int    `json:"files"`
+}
+
+// snapshotIndex is the store of the root project or of a workspace.
+type snapshotIndex struct {
+	name  string // workspace name, empty for the root project
+	store store.SearchStore
+}
+
+func runIndexSnapshot(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if indexSnapshotList && indexSnapshotDelete != "" {
+		return errcode.New(errcode.InvalidArgs, "--list cannot be combined with --delete")
+	}
+	if cmd.Flags().Changed("ref") && (indexSnapshotList || indexSnapshotDelete != "") {
+		return errcode.New(errcode.InvalidArgs, "--ref cannot be combined with --list or --delete")
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	indexes, err := openSnapshotIndexes(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, si := range indexes {
+			si.store.Close()
+		}
+	}()
+
+	switch {
+	case indexSnapshotList:
+		return listSnapshots(ctx, indexes[0].store)
+	case indexSnapshotDelete != "":
+		return deleteSnapshot(ctx, indexes, projectRoot, indexSnapshotDelete)
+	}
+
+	commit, err := indexer.GitCommit(projectRoot, indexSnapshotRef)
+	if err != nil {
+		return errcode.Wrap(errcode.InvalidArgs, err)
+	}
+	if diff, err := indexer.GitDiff(projectRoot, commit); err == nil && len(diff.Changed)+len(diff.Deleted) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d files on disk differ from %s; the snapshot holds their indexed content\n",
+			len(diff.Changed)+len(diff.Deleted), shortCommit(commit))
+	}
+
+	var results []IndexSnapshotJSON
+	for _, si := range indexes {
+		files, err := si.store.SaveSnapshot(ctx, commit)
+		if err != nil {
+			if si.name != "" {
+				return fmt.Errorf("workspace %s: failed to save snapshot: %w", si.name, err)
+			}
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		results = append(results, IndexSnapshotJSON{Workspace: si.name, Commit: commit, Files: files})
+	}
+
+	if indexSnapshotJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	for _, r := range results {
+		label := "Project"
+		if r.Workspace != "" {
+			label = "Workspace " + r.Workspace
+		}
+		fmt.Printf("%s: saved snapshot %s (%d files)\n", label, shortCommit(r.Commit), r.Files)
+	}
+	fmt.Printf("Search it with: agentdx search <query> --at %s\n", shortCommit(commit))
+	return nil
+}
+
+// openSnapshotIndexes opens the stores of the root project and of every
+// workspace.
+func openSnapshotIndexes(ctx context.Context, cfg *config.Config, projectRoot string) ([]snapshotIndex, error) {
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	st, err := store.Open(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index store: %w", err)
+	}
+	indexes := []snapshotIndex{{store: st}}
+	for _, ws := range cfg.Workspaces {
+		wsOpts := opts
+		wsOpts.ProjectID = ws.ProjectID(opts.ProjectID)
+		st, err := store.Open(ctx, wsOpts)
+		if err != nil {
+			for _, si := range indexes {
+				si.store.Close()
+			}
+			return nil, fmt.Errorf("failed to open index store for workspace %s: %w", ws.Name, err)
+		}
+		indexes = append(indexes, snapshotIndex{name: ws.Name, store: st})
+	}
+	return indexes, nil
+}
+
+// listSnapshots prints the snapshots of the root project.
+func listSnapshots(ctx context.Context, st store.Snapshotter) error {
+	snapshots, err := st.ListSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	if indexSnapshotJSON {
+		if snapshots == nil {
+			snapshots = []store.Snapshot{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(snapshots)
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots. Take one with: agentdx index snapshot")
+		return nil
+	}
+	for _, s := range snapshots {
+		fmt.Printf("%s  %s  %d files\n", shortCommit(s.Commit), s.CreatedAt.Local().Format("2006-01-02 15:04"), s.Files)
+	}
+	return nil
+}
+
+// deleteSnapshot removes the snapshot rev names from every index that has
+// one.
+func deleteSnapshot(ctx context.Context, indexes []snapshotIndex, projectRoot, rev string) error {
+	snap, err := resolveSnapshot(ctx, indexes[0].store, projectRoot, rev)
+	if err != nil {
+		return err
+	}
+	for _, si := range indexes {
+		if err := si.store.DeleteSnapshot(ctx, snap.Commit); err != nil && (si.name == "" || errcode.Of(err) != errcode.NotFound) {
+			return err
+		}
+	}
+	if !indexSnapshotJSON {
+		fmt.Printf("Deleted snapshot %s\n", shortCommit(snap.Commit))
+		return nil
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snap)
+}
+
+// resolveSnapshot returns the snapshot of st that rev names: a git revision
+// of the project (branch, tag, SHA) or the commit of a snapshot, possibly
+// abbreviated.
+func resolveSnapshot(ctx context.Context, st store.Snapshotter, projectRoot, rev string) (store.Snapshot, error) {
+	snapshots, err := st.ListSnapshots(ctx)
+	if err != nil {
+		return store.Snapshot{}, err
+	}
+	commit, err := indexer.GitCommit(projectRoot, rev)
+	if err != nil {
+		return store.ResolveSnapshot(snapshots, rev)
+	}
+	snap, err := store.ResolveSnapshot(snapshots, commit)
+	if errcode.Of(err) == errcode.NotFound {
+		return snap, errcode.New(errcode.NotFound, "no snapshot of %s (%s); take one with 'agentdx index snapshot --ref %s'", rev, shortCommit(commit), rev)
+	}
+	return snap, err
+}
+
+// openSnapshotStore opens the snapshot rev names of the root project or the
+// named workspace, for 'agentdx search --at'.
+func openSnapshotStore(ctx context.Context, cfg *config.Config, projectRoot, workspace, rev string) (store.SearchStore, error) {
+	live, err := openWorkspaceStore(ctx, cfg, projectRoot, workspace)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := resolveSnapshot(ctx, live, projectRoot, rev)
+	projectID := live.ProjectID()
+	live.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	opts.ProjectID = store.SnapshotProjectID(projectID, snap.Commit)
+	return openProfiledStore(ctx, cfg, opts)
+}
+
+// shortCommit abbreviates a commit SHA for display, as blame does.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
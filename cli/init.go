@@ -9,12 +9,15 @@ import (
 	"github.com/doveaia/agentdx/config"
 	"github.com/doveaia/agentdx/indexer"
 	"github.com/doveaia/agentdx/localsetup"
+	"github.com/doveaia/agentdx/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	initNonInteractive bool
 	initLocal          bool
+	initLite           bool
+	initAgents         []string
 )
 
 var initCmd = &cobra.Command{
@@ -26,13 +29,31 @@ This command will:
 - Create .agentdx/config.yaml with PostgreSQL Full Text Search settings
 - Auto-configure PostgreSQL via Docker if available
 - Prompt for PostgreSQL DSN if Docker is not available
-- Add .agentdx/ to .gitignore if present`,
+- Add .agentdx/ to .gitignore if present
+
+Use --lite to store the index in a local SQLite file (.agentdx/index.db)
+instead of PostgreSQL. No Docker or database server is required.
+
+Use --agent (repeatable) to generate configuration for some coding agents
+only; interactive init asks which agents to configure.`,
 	RunE: runInit,
 }
 
 func init() {
 	initCmd.Flags().BoolVar(&initNonInteractive, "yes", false, "Use defaults without prompting")
 	initCmd.Flags().BoolVarP(&initLocal, "local", "l", false, "Non-interactive local setup with PostgreSQL FTS")
+	initCmd.Flags().BoolVar(&initLite, "lite", false, "Use an SQLite FTS5 index file instead of PostgreSQL (no Docker required)")
+	initCmd.Flags().StringSliceVar(&initAgents, "agent", nil,
+		"Generate configuration for this coding agent only, repeatable ("+strings.Join(agentIDs(SupportedAgentConfigs()), ", ")+")")
+}
+
+// initAgentConfigs returns the coding agents init configures: those named
+// by --agent, those chosen at the prompt in interactive mode, or all.
+func initAgentConfigs(interactive bool) ([]AgentConfig, error) {
+	if len(initAgents) == 0 && !interactive {
+		return SupportedAgentConfigs(), nil
+	}
+	return selectAgents(initAgents, "configure", false)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -41,9 +62,22 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
+	// Handle --lite flag
+	if initLite {
+		agents, err := initAgentConfigs(false)
+		if err != nil {
+			return err
+		}
+		return runLiteInit(cwd, agents)
+	}
+
 	// Handle --local flag
 	if initLocal {
-		return runLocalInit(cwd)
+		agents, err := initAgentConfigs(false)
+		if err != nil {
+			return err
+		}
+		return runLocalInit(cwd, agents)
 	}
 
 	// Check if already initialized
@@ -53,6 +87,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	agents, err := initAgentConfigs(!initNonInteractive)
+	if err != nil {
+		return err
+	}
+
 	cfg := config.DefaultConfig()
 
 	// Always use PostgreSQL FTS (configured in DefaultConfig)
@@ -90,7 +129,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 Options:
   1. Install Docker and ensure it's running
   2. Use interactive mode: agentdx init
-  3. Use local setup: agentdx init -l`)
+  3. Use local setup: agentdx init -l
+  4. Use SQLite without Docker: agentdx init --lite`)
 		}
 		cfg.Index.Store.Postgres.DSN = result.DSN
 		fmt.Printf("Auto-configured PostgreSQL FTS (container: %s)\n", result.ContainerName)
@@ -114,7 +154,7 @@ Options:
 	}
 
 	// Generate coding agent configurations
-	if err := GenerateAgentConfigs(cwd); err != nil {
+	if err := GenerateAgentConfigsFor(cwd, agents); err != nil {
 		fmt.Printf("Warning: could not generate agent configs: %v\n", err)
 	}
 
@@ -124,7 +164,7 @@ Options:
 	fmt.Println("  2. Search your code: agentdx search \"your query\"")
 
 	fmt.Println("\nUsing PostgreSQL Full Text Search (no external embedding service needed).")
-	fmt.Println("Coding agent configurations generated for: Claude Code, Cursor, Windsurf, Codex CLI, GitHub Copilot, Gemini")
+	fmt.Printf("Coding agent configurations generated for: %s\n", agentNames(agents))
 
 	return nil
 }
@@ -148,7 +188,7 @@ func setupPostgresBackend(cwd string) (*localsetup.SetupResult, error) {
 }
 
 // runLocalInit handles the --local flag for non-interactive local PostgreSQL setup.
-func runLocalInit(cwd string) error {
+func runLocalInit(cwd string, agents []AgentConfig) error {
 	// Check if already initialized (same check as interactive mode)
 	if config.Exists(cwd) {
 		fmt.Println("agentdx is already initialized in this directory.")
@@ -209,7 +249,7 @@ func runLocalInit(cwd string) error {
 	}
 
 	// Generate coding agent configurations
-	if err := GenerateAgentConfigs(cwd); err != nil {
+	if err := GenerateAgentConfigsFor(cwd, agents); err != nil {
 		fmt.Printf("Warning: could not generate agent configs: %v\n", err)
 	}
 
@@ -217,7 +257,51 @@ func runLocalInit(cwd string) error {
 	fmt.Println("  1. Start the indexing daemon: agentdx watch")
 	fmt.Println("  2. Search your code: agentdx search \"your query\"")
 
-	fmt.Println("\nCoding agent configurations generated for: Claude Code, Cursor, Windsurf, Codex CLI, GitHub Copilot, Gemini")
+	fmt.Printf("\nCoding agent configurations generated for: %s\n", agentNames(agents))
+
+	return nil
+}
+
+// runLiteInit handles the --lite flag: a container-free setup backed by SQLite FTS5.
+func runLiteInit(cwd string, agents []AgentConfig) error {
+	if config.Exists(cwd) {
+		fmt.Println("agentdx is already initialized in this directory.")
+		fmt.Printf("Configuration: %s\n", config.GetConfigPath(cwd))
+		return nil
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Index.Store.Backend = store.BackendSQLite
+
+	if err := cfg.Save(cwd); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Printf("Created configuration at %s\n", config.GetConfigPath(cwd))
+
+	// Add .agentdx/ to .gitignore
+	gitignorePath := cwd + "/.gitignore"
+	if _, err := os.Stat(gitignorePath); err == nil {
+		if err := indexer.AddToGitignore(cwd, ".agentdx/"); err != nil {
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		} else {
+			fmt.Println("Added .agentdx/ to .gitignore")
+		}
+	}
+
+	// Generate coding agent configurations
+	if err := GenerateAgentConfigsFor(cwd, agents); err != nil {
+		fmt.Printf("Warning: could not generate agent configs: %v\n", err)
+	}
+
+	fmt.Println("\nagentdx initialized successfully!")
+	fmt.Printf("  Index: %s (SQLite FTS5)\n", cfg.GetSQLiteIndexPath(cwd))
+
+	fmt.Println("\nNext steps:")
+	fmt.Println("  1. Start the indexing daemon: agentdx watch")
+	fmt.Println("  2. Search your code: agentdx search \"your query\"")
+
+	fmt.Printf("\nCoding agent configurations generated for: %s\n", agentNames(agents))
 
 	return nil
 }
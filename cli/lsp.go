@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/lsp"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Start agentdx as a language server over stdio",
+	Long: `Start agentdx as a minimal LSP (Language Server Protocol) server.
+
+The server communicates via stdio and supports:
+
+  - workspace/symbol: Find symbols in the symbol index
+  - textDocument/references: Find callers of the symbol under the cursor
+  - agentdx/search: Full-text search (params: {"query": "...", "limit": 10})
+
+The symbol index is built by 'agentdx watch'.`,
+	RunE: runLSP,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return fmt.Errorf("failed to find project root: %w", err)
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load symbol index: %w", err)
+	}
+
+	// Search is optional: symbol requests still work without the database
+	var searcher store.SearchStore
+	ftsStore, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: search unavailable: %v\n", err)
+	} else {
+		defer ftsStore.Close()
+		searcher = ftsStore
+	}
+
+	server := lsp.NewServer(projectRoot, symbolStore, searcher, cfg.Index.Search)
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
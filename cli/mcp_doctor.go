@@ -0,0 +1,435 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check statuses.
+const (
+	doctorPass  = "pass"
+	doctorEmpty = "empty"
+	doctorFail  = "fail"
+	doctorSkip  = "skip"
+)
+
+// doctorPayloadLimit is the number of payload bytes shown without --verbose.
+const doctorPayloadLimit = 400
+
+var (
+	doctorQuery   string
+	doctorSymbol  string
+	doctorFile    string
+	doctorTimeout time.Duration
+	doctorVerbose bool
+	doctorJSON    bool
+)
+
+var mcpDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Self-test the MCP tools against the live index",
+	Long: `Start 'agentdx serve' the way an agent does, list its tools and call each
+one with sample arguments taken from the index, then print pass/fail with
+the raw payloads.
+
+Use it when an agent's MCP tools fail or return nothing: a tool that fails
+here fails for the agent too, and an empty payload points at the index
+(run 'agentdx watch') rather than at the agent configuration.
+
+Sample arguments default to the first indexed file and traced symbol, and
+can be set with --query, --symbol and --file. agentdx_note_add is skipped
+because it writes to the index.
+
+Examples:
+  agentdx mcp doctor
+  agentdx mcp doctor --symbol HandleLogin --verbose
+  agentdx mcp doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: runMCPDoctor,
+}
+
+func init() {
+	mcpDoctorCmd.Flags().StringVar(&doctorQuery, "query", "", "Query for agentdx_search (default: the sample file name)")
+	mcpDoctorCmd.Flags().StringVar(&doctorSymbol, "symbol", "", "Symbol for the trace tools (default: the first traced symbol)")
+	mcpDoctorCmd.Flags().StringVar(&doctorFile, "file", "", "File for agentdx_read_chunk and agentdx_notes (default: the first indexed file)")
+	mcpDoctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 30*time.Second, "Timeout for starting the server and for each tool call")
+	mcpDoctorCmd.Flags().BoolVar(&doctorVerbose, "verbose", false, "Print full payloads instead of truncating them")
+	mcpDoctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output in JSON format")
+
+	mcpCmd.AddCommand(mcpDoctorCmd)
+}
+
+// DoctorCheck is the outcome of calling one MCP tool.
+type DoctorCheck struct {
+	Tool       string         `json:"tool"`
+	Status     string         `json:"status"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Payload    string         `json:"payload,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+// DoctorJSON is the JSON output of 'agentdx mcp doctor'.
+type DoctorJSON struct {
+	Server string        `json:"server"`
+	Tools  int           `json:"tools"`
+	Checks []DoctorCheck `json:"checks"`
+	Stderr string        `json:"stderr,omitempty"`
+}
+
+// doctorSamples are the arguments used to exercise the tools.
+type doctorSamples struct {
+	Query  string
+	Symbol string
+	File   string
+}
+
+func runMCPDoctor(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	samples, err := loadDoctorSamples(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate agentdx executable: %w", err)
+	}
+	// The server must start in the project root, as it does for agents
+	// configured with the project scope
+	inDir := transport.WithCommandFunc(func(ctx context.Context, command string, env, args []string) (*exec.Cmd, error) {
+		cmd := exec.CommandContext(ctx, command, args...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Dir = projectRoot
+		return cmd, nil
+	})
+	client, err := mcpclient.NewStdioMCPClientWithOptions(exe, nil, []string{"serve"}, inDir)
+	if err != nil {
+		return fmt.Errorf("failed to start MCP server: %w", err)
+	}
+	defer client.Close()
+
+	stderr := &lockedBuffer{}
+	if r, ok := mcpclient.GetStderr(client); ok {
+		go func() { _, _ = io.Copy(stderr, r) }()
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "agentdx-doctor", Version: version}
+	info, err := client.Initialize(initCtx, initReq)
+	if err != nil {
+		return doctorStartError("failed to initialize MCP session", err, stderr)
+	}
+	tools, err := client.ListTools(initCtx, mcp.ListToolsRequest{})
+	if err != nil {
+		return doctorStartError("tools/list failed", err, stderr)
+	}
+
+	checks := make([]DoctorCheck, 0, len(tools.Tools))
+	for _, tool := range tools.Tools {
+		checks = append(checks, callDoctorTool(ctx, client, tool, samples))
+	}
+
+	server := info.ServerInfo.Name + " " + info.ServerInfo.Version
+	if doctorJSON {
+		out := DoctorJSON{Server: server, Tools: len(tools.Tools), Checks: checks}
+		if countDoctorStatus(checks, doctorFail) > 0 {
+			out.Stderr = stderr.String()
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(server, checks, stderr.String())
+	}
+
+	if failed := countDoctorStatus(checks, doctorFail); failed > 0 {
+		return fmt.Errorf("%d of %d MCP tools failed", failed, len(checks))
+	}
+	return nil
+}
+
+// loadDoctorSamples fills the sample arguments not set by flags from the
+// chunk and symbol indexes.
+func loadDoctorSamples(ctx context.Context, cfg *config.Config, projectRoot string) (doctorSamples, error) {
+	samples := doctorSamples{Query: doctorQuery, Symbol: doctorSymbol, File: doctorFile}
+
+	if samples.File == "" {
+		st, err := openStore(ctx, cfg, projectRoot)
+		if err != nil {
+			return samples, fmt.Errorf("failed to open index store: %w", err)
+		}
+		files, err := st.ListFilesWithStats(ctx)
+		st.Close()
+		if err != nil {
+			return samples, fmt.Errorf("failed to list indexed files: %w", err)
+		}
+		samples.File = firstIndexedFile(files, cfg.Index.Trace.Languages())
+	}
+
+	if samples.Symbol == "" {
+		symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open symbol index: %v\n", err)
+		} else {
+			if symbols, err := symbolStore.FindSymbols(ctx, "", 1); err == nil && len(symbols) > 0 {
+				samples.Symbol = symbols[0].Name
+			}
+			symbolStore.Close()
+		}
+	}
+
+	// The sample file is in the root project's index, where the symbol may
+	// not be when it comes from a workspace
+	if samples.Query == "" && samples.File != "" {
+		samples.Query = strings.TrimSuffix(filepath.Base(samples.File), filepath.Ext(samples.File))
+	}
+	if samples.Query == "" {
+		samples.Query = samples.Symbol
+	}
+	return samples, nil
+}
+
+// firstIndexedFile returns the first file in path order, preferring source
+// files of the traced languages over documentation and configuration.
+func firstIndexedFile(files []store.FileStats, languages []string) string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		if slices.Contains(languages, strings.ToLower(filepath.Ext(path))) {
+			return path
+		}
+	}
+	if len(paths) > 0 {
+		return paths[0]
+	}
+	return ""
+}
+
+// doctorToolArgs returns the arguments to call tool with, or why it is
+// skipped.
+func doctorToolArgs(tool mcp.Tool, samples doctorSamples) (map[string]any, string) {
+	var args map[string]any
+	switch tool.Name {
+	case "agentdx_search":
+		args = map[string]any{"query": samples.Query, "limit": 3}
+	case "agentdx_files":
+		args = map[string]any{"pattern": "*", "limit": 5}
+	case "agentdx_trace_callers", "agentdx_trace_callees", "agentdx_trace_implementations":
+		args = map[string]any{"symbol": samples.Symbol}
+	case "agentdx_trace_graph":
+		args = map[string]any{"symbol": samples.Symbol, "depth": 1}
+	case "agentdx_read_chunk":
+		args = map[string]any{"file": samples.File, "start_line": 1, "end_line": 5}
+	case "agentdx_notes":
+		args = map[string]any{"file": samples.File}
+	case "agentdx_note_add":
+		return nil, "writes to the index"
+	default:
+		args = map[string]any{}
+	}
+
+	for _, name := range tool.InputSchema.Required {
+		if v, ok := args[name]; !ok || v == "" {
+			return nil, fmt.Sprintf("no sample value for required argument %q", name)
+		}
+	}
+	return args, ""
+}
+
+// callDoctorTool calls tool with sample arguments and classifies the result.
+func callDoctorTool(ctx context.Context, client *mcpclient.Client, tool mcp.Tool, samples doctorSamples) DoctorCheck {
+	check := DoctorCheck{Tool: tool.Name}
+	args, skip := doctorToolArgs(tool, samples)
+	if skip != "" {
+		check.Status = doctorSkip
+		check.Error = skip
+		return check
+	}
+	check.Arguments = args
+
+	callCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool.Name
+	req.Params.Arguments = args
+
+	start := time.Now()
+	result, err := client.CallTool(callCtx, req)
+	check.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		check.Status = doctorFail
+		check.Error = err.Error()
+		return check
+	}
+
+	check.Payload = doctorPayload(result)
+	switch {
+	case result.IsError && missingIndex(check.Payload):
+		// The tool works; the index it reads has not been built yet
+		check.Status = doctorEmpty
+		check.Error = check.Payload
+	case result.IsError:
+		check.Status = doctorFail
+		check.Error = check.Payload
+	case isEmptyPayload(check.Payload) && tool.Name != "agentdx_notes":
+		// A file without notes is the common case, not a symptom
+		check.Status = doctorEmpty
+	default:
+		check.Status = doctorPass
+	}
+	return check
+}
+
+// doctorPayload joins the content of a tool result as the agent sees it.
+func doctorPayload(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			parts = append(parts, c.Text)
+		default:
+			if data, err := json.Marshal(c); err == nil {
+				parts = append(parts, string(data))
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// missingIndex reports whether an error payload blames a missing search or
+// symbol index.
+func missingIndex(payload string) bool {
+	var p errcode.Payload
+	if json.Unmarshal([]byte(payload), &p) != nil {
+		return false
+	}
+	return p.Code == errcode.NoIndex || p.Code == errcode.NoSymbolIndex
+}
+
+// isEmptyPayload reports whether a payload carries no results.
+func isEmptyPayload(payload string) bool {
+	switch strings.TrimSpace(payload) {
+	case "", "[]", "{}", "null":
+		return true
+	}
+	return false
+}
+
+func countDoctorStatus(checks []DoctorCheck, status string) int {
+	n := 0
+	for _, c := range checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func printDoctorReport(server string, checks []DoctorCheck, stderr string) {
+	fmt.Printf("MCP server: %s (%d tools)\n\n", server, len(checks))
+	for _, c := range checks {
+		fmt.Printf("%-5s %s", strings.ToUpper(c.Status), c.Tool)
+		if c.Status != doctorSkip {
+			fmt.Printf(" (%dms)", c.DurationMs)
+		}
+		fmt.Println()
+		if c.Arguments != nil {
+			args, _ := json.Marshal(c.Arguments)
+			fmt.Printf("      args: %s\n", args)
+		}
+		switch {
+		case c.Status == doctorSkip:
+			fmt.Printf("      skipped: %s\n", c.Error)
+		case c.Error != "" && c.Error != c.Payload:
+			fmt.Printf("      error: %s\n", c.Error)
+		}
+		if c.Payload != "" {
+			fmt.Println(indentPayload(truncatePayload(c.Payload, doctorVerbose)))
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d empty, %d failed, %d skipped\n",
+		countDoctorStatus(checks, doctorPass), countDoctorStatus(checks, doctorEmpty),
+		countDoctorStatus(checks, doctorFail), countDoctorStatus(checks, doctorSkip))
+	if countDoctorStatus(checks, doctorEmpty) > 0 {
+		fmt.Println("Empty results usually mean the index is missing or stale; run 'agentdx watch' and check 'agentdx status'.")
+	}
+	if countDoctorStatus(checks, doctorFail) > 0 && strings.TrimSpace(stderr) != "" {
+		fmt.Printf("\nServer stderr:\n%s\n", indentPayload(strings.TrimSpace(stderr)))
+	}
+}
+
+// truncatePayload shortens payload to doctorPayloadLimit bytes unless full.
+func truncatePayload(payload string, full bool) string {
+	if full || len(payload) <= doctorPayloadLimit {
+		return payload
+	}
+	return fmt.Sprintf("%s... (%d more bytes, use --verbose)", payload[:doctorPayloadLimit], len(payload)-doctorPayloadLimit)
+}
+
+func indentPayload(payload string) string {
+	return "      " + strings.ReplaceAll(payload, "\n", "\n      ")
+}
+
+// doctorStartError reports a server that could not be reached, with what
+// it wrote to stderr.
+func doctorStartError(msg string, err error, stderr *lockedBuffer) error {
+	if out := strings.TrimSpace(stderr.String()); out != "" {
+		return fmt.Errorf("%s: %w\nserver stderr:\n%s", msg, err, out)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// lockedBuffer collects the server's stderr while it runs.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorToolArgs(t *testing.T) {
+	samples := doctorSamples{Query: "login", Symbol: "HandleLogin", File: "api/login.go"}
+
+	search := mcp.NewTool("agentdx_search", mcp.WithString("query", mcp.Required()))
+	args, skip := doctorToolArgs(search, samples)
+	assert.Empty(t, skip)
+	assert.Equal(t, "login", args["query"])
+
+	callers := mcp.NewTool("agentdx_trace_callers", mcp.WithString("symbol", mcp.Required()))
+	args, skip = doctorToolArgs(callers, samples)
+	assert.Empty(t, skip)
+	assert.Equal(t, "HandleLogin", args["symbol"])
+
+	_, skip = doctorToolArgs(callers, doctorSamples{})
+	assert.Contains(t, skip, `"symbol"`, "a required argument without a sample skips the tool")
+
+	_, skip = doctorToolArgs(mcp.NewTool("agentdx_note_add"), samples)
+	assert.Equal(t, "writes to the index", skip)
+
+	args, skip = doctorToolArgs(mcp.NewTool("agentdx_future"), samples)
+	assert.Empty(t, skip)
+	assert.Empty(t, args, "unknown tools without required arguments are called with none")
+}
+
+func TestFirstIndexedFile(t *testing.T) {
+	files := []store.FileStats{{Path: "README.md"}, {Path: "src/main.go"}, {Path: "src/app.go"}}
+	assert.Equal(t, "src/app.go", firstIndexedFile(files, []string{".go"}))
+	assert.Equal(t, "README.md", firstIndexedFile(files, []string{".py"}))
+	assert.Empty(t, firstIndexedFile(nil, []string{".go"}))
+}
+
+func TestDoctorPayload(t *testing.T) {
+	for _, p := range []string{"", " [] ", "{}", "null\n"} {
+		assert.True(t, isEmptyPayload(p), "%q", p)
+	}
+	assert.False(t, isEmptyPayload(`[{"path":"a.go"}]`))
+	assert.True(t, missingIndex(`{"error":"index is empty","code":"E_NO_INDEX"}`))
+	assert.False(t, missingIndex(`{"error":"boom","code":"E_BACKEND_DOWN"}`))
+	assert.False(t, missingIndex("failed"))
+
+	long := strings.Repeat("x", doctorPayloadLimit+10)
+	assert.Contains(t, truncatePayload(long, false), "(10 more bytes, use --verbose)")
+	assert.Equal(t, long, truncatePayload(long, true))
+	assert.Equal(t, "short", truncatePayload("short", false))
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doveaia/agentdx/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpSchemaFormat string
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp <subcommand>",
+	Short: "Work with the MCP tools",
+}
+
+var mcpSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print tool definitions for the MCP tools",
+	Long: `Print ready-to-use tool definitions for the agentdx MCP tools, generated
+from the tools served by 'agentdx serve'.
+
+Formats:
+  openai-tools     OpenAI function calling ("tools" of chat completions)
+  anthropic-tools  Anthropic Messages API ("tools" with input_schema)
+  json-schema      One JSON Schema document per tool
+
+Examples:
+  agentdx mcp schema --format openai-tools > tools.json
+  agentdx mcp schema --format anthropic-tools | jq '.[].name'`,
+	Args: cobra.NoArgs,
+	RunE: runMCPSchema,
+}
+
+func init() {
+	mcpSchemaCmd.Flags().StringVar(&mcpSchemaFormat, "format", mcp.SchemaJSONSchema,
+		"Output format: "+strings.Join(mcp.SchemaFormats, ", "))
+
+	mcpCmd.AddCommand(mcpSchemaCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCPSchema(_ *cobra.Command, _ []string) error {
+	// The tools are registered without opening the index, so this works
+	// outside a project
+	srv, err := mcp.NewServer("")
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+	defs, err := srv.ToolSchemas(mcpSchemaFormat)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(defs)
+}
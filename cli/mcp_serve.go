@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/internal/netutil"
 	"github.com/doveaia/agentdx/mcp"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +27,7 @@ The server communicates via stdio and exposes the following tools:
   - agentdx_trace_callers: Find all functions that call a symbol
   - agentdx_trace_callees: Find all functions called by a symbol
   - agentdx_trace_graph: Build a call graph around a symbol
+  - agentdx_trace_implementations: Find the Go types implementing an interface
   - agentdx_index_status: Check index health and statistics
 
 Configuration for Claude Code:
@@ -34,11 +41,40 @@ Configuration for Cursor (.cursor/mcp.json):
         "args": ["serve"]
       }
     }
-  }`,
+  }
+
+With --http, the tools are served over HTTP instead of stdio, for clients
+that cannot spawn a subprocess (web-based agents, remote IDEs):
+
+  POST /mcp                   MCP streamable HTTP transport
+  GET  /api/v1/tools          List REST endpoints
+  GET  /api/v1/search?query=  Call a tool; arguments come from the query
+                              string or a JSON body (POST)
+  POST /v1/retrieval          Ranked passages for RAG frameworks:
+                              {"query": "...", "top_k": 5} returns
+                              {"query", "results": [{"id", "text", "score",
+                              "metadata": {"source", ...}}]}
+
+Endpoints are named after the tools without the "agentdx_" prefix, e.g.
+/api/v1/files, /api/v1/trace_callers. Clients authenticate with
+"Authorization: Bearer <token>", the token set with --token or
+AGENTDX_HTTP_TOKEN. Without one, a random token is generated and printed on
+loopback addresses; other addresses require it to be set. POST requests
+must be sent as application/json, and on loopback only requests for
+localhost or a loopback address are served.`,
 	RunE: runMCPServe,
 }
 
+var (
+	serveHTTP  bool
+	serveAddr  string
+	serveToken string
+)
+
 func init() {
+	mcpServeCmd.Flags().BoolVar(&serveHTTP, "http", false, "Serve over HTTP (MCP and JSON REST API) instead of stdio")
+	mcpServeCmd.Flags().StringVar(&serveAddr, "addr", mcp.DefaultHTTPAddr, "Address to listen on with --http")
+	mcpServeCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token required from HTTP clients (default: $"+mcp.HTTPTokenEnv+")")
 	rootCmd.AddCommand(mcpServeCmd)
 }
 
@@ -55,5 +91,25 @@ func runMCPServe(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
-	return server.Serve()
+	if !serveHTTP {
+		return server.Serve()
+	}
+
+	token := serveToken
+	if token == "" {
+		token = os.Getenv(mcp.HTTPTokenEnv)
+	}
+	if token == "" {
+		// Off loopback, ListenAndServe asks for an explicit token
+		if host, _, err := net.SplitHostPort(serveAddr); err == nil && netutil.IsLoopback(host) {
+			if token, err = mcp.NewHTTPToken(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "agentdx HTTP API token (set --token or %s to choose one): %s\n", mcp.HTTPTokenEnv, token)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "agentdx HTTP API listening on http://%s\n", serveAddr)
+	return server.ListenAndServe(ctx, serveAddr, token)
 }
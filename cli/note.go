@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var noteJSON bool
+
+var noteCmd = &cobra.Command{
+	Use:   "note <subcommand>",
+	Short: "Attach notes to code regions",
+	Long: `Notes are durable breadcrumbs attached to a line range of a file.
+They are stored in the index backend, survive re-indexing, and are shown
+inline in search results (CLI and MCP).
+
+Examples:
+  agentdx note add cli/root.go:10-25 "This is the real entrypoint, ignore legacy/"
+  agentdx note list
+  agentdx note list cli/root.go --json
+  agentdx note rm 3`,
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add <file>:<line-range> <text>",
+	Short: "Attach a note to a line range (file:10-25 or file:10)",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runNoteAdd,
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list [file]",
+	Short: "List notes, optionally for a single file",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runNoteList,
+}
+
+var noteRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Delete a note",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNoteRm,
+}
+
+func init() {
+	noteListCmd.Flags().BoolVar(&noteJSON, "json", false, "Output notes in JSON format")
+
+	noteCmd.AddCommand(noteAddCmd)
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteRmCmd)
+
+	rootCmd.AddCommand(noteCmd)
+}
+
+// parseNoteRegion parses "<file>:<start>-<end>" or "<file>:<line>".
+func parseNoteRegion(region string) (string, int, int, error) {
+	idx := strings.LastIndex(region, ":")
+	if idx <= 0 {
+		return "", 0, 0, errcode.New(errcode.InvalidArgs, "invalid region %q: expected <file>:<start>-<end>", region)
+	}
+	file, lines := region[:idx], region[idx+1:]
+
+	startStr, endStr, isRange := strings.Cut(lines, "-")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return "", 0, 0, errcode.New(errcode.InvalidArgs, "invalid start line in %q", region)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(endStr); err != nil {
+			return "", 0, 0, errcode.New(errcode.InvalidArgs, "invalid end line in %q", region)
+		}
+	}
+	return file, start, end, nil
+}
+
+// relativeToRoot returns path relative to the project root with forward
+// slashes, the form file paths are indexed under.
+func relativeToRoot(projectRoot, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(projectRoot, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", errcode.New(errcode.InvalidArgs, "%s is outside the project", path)
+		}
+		path = rel
+	}
+	return filepath.ToSlash(filepath.Clean(path)), nil
+}
+
+// openNoteStore opens the index store for the current project.
+func openNoteStore(ctx context.Context) (store.SearchStore, string, error) {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open index store: %w", err)
+	}
+	return st, projectRoot, nil
+}
+
+func runNoteAdd(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	file, start, end, err := parseNoteRegion(args[0])
+	if err != nil {
+		return err
+	}
+
+	st, projectRoot, err := openNoteStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	file, err = relativeToRoot(projectRoot, file)
+	if err != nil {
+		return err
+	}
+
+	note := store.Note{FilePath: file, StartLine: start, EndLine: end, Text: args[1]}
+	if err := note.Validate(); err != nil {
+		return err
+	}
+
+	note, err = st.AddNote(ctx, note)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added note #%d to %s:%d-%d\n", note.ID, note.FilePath, note.StartLine, note.EndLine)
+	return nil
+}
+
+func runNoteList(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	st, projectRoot, err := openNoteStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	var file string
+	if len(args) == 1 {
+		if file, err = relativeToRoot(projectRoot, args[0]); err != nil {
+			return err
+		}
+	}
+
+	notes, err := st.ListNotes(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	if noteJSON {
+		if notes == nil {
+			notes = []store.Note{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(notes)
+	}
+
+	if len(notes) == 0 {
+		fmt.Println("No notes found.")
+		return nil
+	}
+	for _, n := range notes {
+		fmt.Printf("#%d  %s:%d-%d  %s\n", n.ID, n.FilePath, n.StartLine, n.EndLine, n.Text)
+	}
+	return nil
+}
+
+func runNoteRm(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil {
+		return errcode.New(errcode.InvalidArgs, "invalid note id %q", args[0])
+	}
+
+	st, _, err := openNoteStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if err := st.DeleteNote(ctx, id); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted note #%d\n", id)
+	return nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNoteRegion(t *testing.T) {
+	file, start, end, err := parseNoteRegion("cli/root.go:10-25")
+	require.NoError(t, err)
+	assert.Equal(t, "cli/root.go", file)
+	assert.Equal(t, 10, start)
+	assert.Equal(t, 25, end)
+
+	file, start, end, err = parseNoteRegion(`C:\src\main.go:7`)
+	require.NoError(t, err)
+	assert.Equal(t, `C:\src\main.go`, file)
+	assert.Equal(t, 7, start)
+	assert.Equal(t, 7, end)
+
+	for _, bad := range []string{"main.go", ":10", "main.go:a", "main.go:1-b"} {
+		_, _, _, err := parseNoteRegion(bad)
+		assert.Error(t, err, "region %q", bad)
+	}
+}
+
+func TestRelativeToRoot(t *testing.T) {
+	root := t.TempDir()
+
+	rel, err := relativeToRoot(root, filepath.Join(root, "cli", "root.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "cli/root.go", rel)
+
+	rel, err = relativeToRoot(root, "./cli/../cli/root.go")
+	require.NoError(t, err)
+	assert.Equal(t, "cli/root.go", rel)
+
+	_, err = relativeToRoot(root, filepath.Dir(root))
+	assert.Error(t, err)
+}
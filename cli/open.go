@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+// lastSearch is the result set cached by 'agentdx search' for 'agentdx open'.
+type lastSearch struct {
+	Query   string               `json:"query"`
+	Results []lastSearchLocation `json:"results"`
+}
+
+type lastSearchLocation struct {
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+var openCmd = &cobra.Command{
+	Use:   "open <result-ref>",
+	Short: "Open a search result in your editor",
+	Long: `Open a file from the last search at the matching line.
+
+The result reference is either the result number printed by the last
+'agentdx search' (e.g. 'agentdx open 3') or a path:line location.
+
+The editor is taken from $VISUAL or $EDITOR. When neither is set, VS Code
+('code -g') is used if available.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+}
+
+func runOpen(_ *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	path, line, err := resolveResultRef(projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		if _, err := exec.LookPath("code"); err != nil {
+			return fmt.Errorf("no editor configured: set $EDITOR or install the 'code' command")
+		}
+		editor = "code"
+	}
+
+	argv := editorCommand(editor, path, line)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+	return nil
+}
+
+// resolveResultRef turns a result number or path:line into a location.
+func resolveResultRef(projectRoot, ref string) (string, int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		last, err := loadLastSearch(projectRoot)
+		if err != nil {
+			return "", 0, err
+		}
+		if n < 1 || n > len(last.Results) {
+			return "", 0, errcode.New(errcode.InvalidArgs, "result %d out of range: last search for %q returned %d results", n, last.Query, len(last.Results))
+		}
+		r := last.Results[n-1]
+		if indexer.IsNotebook(r.FilePath) {
+			// Result lines are lines of the notebook's text, not of its JSON
+			return r.FilePath, 1, nil
+		}
+		return r.FilePath, r.StartLine, nil
+	}
+
+	if i := strings.LastIndex(ref, ":"); i > 0 {
+		if line, err := strconv.Atoi(ref[i+1:]); err == nil && line > 0 {
+			return ref[:i], line, nil
+		}
+	}
+	return ref, 1, nil
+}
+
+// editorCommand builds the command line that opens path at line with the
+// given editor. Editor settings may include arguments (e.g. "code --wait").
+func editorCommand(editor, path string, line int) []string {
+	argv := strings.Fields(editor)
+	switch strings.TrimSuffix(filepath.Base(argv[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		return append(argv, "-g", fmt.Sprintf("%s:%d", path, line))
+	case "subl", "zed", "hx", "helix", "mate":
+		return append(argv, fmt.Sprintf("%s:%d", path, line))
+	case "idea", "goland", "pycharm", "webstorm", "phpstorm", "rubymine", "clion":
+		return append(argv, "--line", strconv.Itoa(line), path)
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and most terminal editors
+		return append(argv, fmt.Sprintf("+%d", line), path)
+	}
+}
+
+// saveLastSearch caches search results for 'agentdx open'.
+func saveLastSearch(projectRoot, query string, results []store.SearchResult) error {
+	last := lastSearch{Query: query, Results: make([]lastSearchLocation, len(results))}
+	for i, r := range results {
+		last.Results[i] = lastSearchLocation{
+			FilePath:  r.Chunk.FilePath,
+			StartLine: r.Chunk.StartLine,
+			EndLine:   r.Chunk.EndLine,
+		}
+	}
+
+	data, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last search: %w", err)
+	}
+	if err := os.WriteFile(config.GetLastSearchPath(projectRoot), data, 0600); err != nil {
+		return fmt.Errorf("failed to write last search: %w", err)
+	}
+	return nil
+}
+
+// loadLastSearch reads the results cached by the last search.
+func loadLastSearch(projectRoot string) (*lastSearch, error) {
+	data, err := os.ReadFile(config.GetLastSearchPath(projectRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errcode.New(errcode.NotFound, "no previous search results: run 'agentdx search' first")
+		}
+		return nil, fmt.Errorf("failed to read last search: %w", err)
+	}
+
+	var last lastSearch
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("failed to parse last search: %w", err)
+	}
+	return &last, nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"code", []string{"code", "-g", "/p/a.go:12"}},
+		{"/usr/local/bin/code --wait", []string{"/usr/local/bin/code", "--wait", "-g", "/p/a.go:12"}},
+		{"nvim", []string{"nvim", "+12", "/p/a.go"}},
+		{"emacsclient -t", []string{"emacsclient", "-t", "+12", "/p/a.go"}},
+		{"subl", []string{"subl", "/p/a.go:12"}},
+		{"goland", []string{"goland", "--line", "12", "/p/a.go"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, editorCommand(tt.editor, "/p/a.go", 12), "editor %q", tt.editor)
+	}
+}
+
+func TestResolveResultRef(t *testing.T) {
+	projectRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(config.GetConfigDir(projectRoot), 0755))
+
+	// Numbers need a cached search
+	_, _, err := resolveResultRef(projectRoot, "1")
+	assert.Error(t, err)
+
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "cli/search.go", StartLine: 10, EndLine: 20}},
+		{Chunk: store.Chunk{FilePath: "cli/open.go", StartLine: 42, EndLine: 50}},
+	}
+	require.NoError(t, saveLastSearch(projectRoot, "open", results))
+
+	path, line, err := resolveResultRef(projectRoot, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "cli/open.go", path)
+	assert.Equal(t, 42, line)
+
+	_, _, err = resolveResultRef(projectRoot, "3")
+	assert.Error(t, err)
+
+	path, line, err = resolveResultRef(projectRoot, "store/store.go:7")
+	require.NoError(t, err)
+	assert.Equal(t, "store/store.go", path)
+	assert.Equal(t, 7, line)
+
+	path, line, err = resolveResultRef(projectRoot, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md", path)
+	assert.Equal(t, 1, line)
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// RegisterCommand adds subcommands to the agentdx root command, so that
+// programs embedding agentdx (or optional features built in with build
+// tags) can extend the CLI without changing this package. Call it from an
+// init function or before Execute. A command whose name or alias is already
+// taken is rejected, and no command is added.
+func RegisterCommand(cmds ...*cobra.Command) error {
+	// cobra adds help and completion when the CLI runs
+	taken := map[string]string{"help": "help", "completion": "completion"}
+	for _, c := range rootCmd.Commands() {
+		for _, name := range append([]string{c.Name()}, c.Aliases...) {
+			taken[name] = c.Name()
+		}
+	}
+	for _, c := range cmds {
+		for _, name := range append([]string{c.Name()}, c.Aliases...) {
+			if owner, ok := taken[name]; ok {
+				return fmt.Errorf("cannot register command %q: %q is already used by the %s command", c.Name(), name, owner)
+			}
+			taken[name] = c.Name()
+		}
+	}
+
+	rootCmd.AddCommand(cmds...)
+	return nil
+}
+
+// MustRegisterCommand is like RegisterCommand but panics on conflicts, for
+// use in init functions.
+func MustRegisterCommand(cmds ...*cobra.Command) {
+	if err := RegisterCommand(cmds...); err != nil {
+		panic(err)
+	}
+}
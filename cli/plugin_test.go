@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterCommand(t *testing.T) {
+	corp := &cobra.Command{Use: "corp", Short: "Company extensions"}
+	corp.AddCommand(&cobra.Command{Use: "sync", Run: func(*cobra.Command, []string) {}})
+	t.Cleanup(func() { rootCmd.RemoveCommand(corp) })
+
+	require.NoError(t, RegisterCommand(corp))
+	found, _, err := rootCmd.Find([]string{"corp", "sync"})
+	require.NoError(t, err)
+	assert.Equal(t, "sync", found.Name())
+
+	err = RegisterCommand(&cobra.Command{Use: "search"})
+	assert.ErrorContains(t, err, `"search" is already used`)
+
+	// A conflicting alias rejects every command of the call
+	other := &cobra.Command{Use: "other"}
+	err = RegisterCommand(other, &cobra.Command{Use: "x", Aliases: []string{"corp"}})
+	assert.Error(t, err)
+	assert.NotContains(t, rootCmd.Commands(), other)
+
+	// Commands of one call must not clash with each other either
+	assert.Error(t, RegisterCommand(&cobra.Command{Use: "dup"}, &cobra.Command{Use: "dup"}))
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var profileJSON bool
+
+var profileCmd = &cobra.Command{
+	Use:   "profile <subcommand>",
+	Short: "Share ranking profiles through the index backend",
+	Long: `Ranking profiles hold the search ranking settings (boost rules,
+tie-breaking, tokenizer and per-file cap) under a name in the index backend.
+With a shared PostgreSQL database every teammate can apply the same tuned
+ranking by setting index.search.profile in .agentdx/config.yaml.
+
+Examples:
+  agentdx profile export backend-team   # Publish this project's ranking settings
+  agentdx profile list
+  agentdx profile import backend-team   # Copy a profile into the local config`,
+}
+
+var profileExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Save the project's ranking settings as a named profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileExport,
+}
+
+var profileImportCmd = &cobra.Command{
+	Use:   "import <name>",
+	Short: "Copy a profile's ranking settings into the local config",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileImport,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List ranking profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runProfileList,
+}
+
+func init() {
+	profileListCmd.Flags().BoolVar(&profileJSON, "json", false, "Output profiles in JSON format")
+
+	profileCmd.AddCommand(profileExportCmd)
+	profileCmd.AddCommand(profileImportCmd)
+	profileCmd.AddCommand(profileListCmd)
+
+	rootCmd.AddCommand(profileCmd)
+}
+
+// openProfileStore opens the index store without applying the selected
+// profile, so profiles can be managed even when the selection is invalid.
+func openProfileStore(ctx context.Context) (*config.Config, string, store.SearchStore, error) {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := store.Open(ctx, store.OptionsFromConfig(cfg, projectRoot))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to open index store: %w", err)
+	}
+	return cfg, projectRoot, st, nil
+}
+
+func runProfileExport(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, _, st, err := openProfileStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	data, err := cfg.Index.Search.RankingProfile().Marshal()
+	if err != nil {
+		return err
+	}
+	if err := st.SaveProfile(ctx, args[0], data); err != nil {
+		return err
+	}
+	fmt.Printf("Exported ranking profile %q\n", args[0])
+	return nil
+}
+
+func runProfileImport(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, projectRoot, st, err := openProfileStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	p, err := st.GetProfile(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	profile, err := config.ParseRankingProfile(p.Data)
+	if err != nil {
+		return err
+	}
+
+	tokenizerChanged := profile.CJKBigrams != cfg.Index.Search.CJKBigrams
+	cfg.Index.Search.ApplyProfile(*profile)
+	if err := cfg.Save(projectRoot); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported ranking profile %q into %s\n", args[0], config.GetConfigPath(projectRoot))
+	if tokenizerChanged {
+		fmt.Println("The tokenizer setting changed; re-index with 'agentdx watch' to apply it.")
+	}
+	return nil
+}
+
+func runProfileList(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	cfg, _, st, err := openProfileStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	profiles, err := st.ListProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	if profileJSON {
+		if profiles == nil {
+			profiles = []store.Profile{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(profiles)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println("No ranking profiles found.")
+		return nil
+	}
+	for _, p := range profiles {
+		marker := " "
+		if p.Name == cfg.Index.Search.Profile {
+			marker = "*"
+		}
+		fmt.Printf("%s %s  (updated %s)\n", marker, p.Name, p.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
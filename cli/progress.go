@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/doveaia/agentdx/dashboard"
+	"github.com/doveaia/agentdx/indexer"
+)
+
+// Formats of the initial scan progress of 'agentdx watch --progress'.
+const (
+	progressFormatBar  = "bar"  // carriage-return progress bar
+	progressFormatJSON = "json" // newline-delimited JSON events
+	progressFormatNone = "none"
+)
+
+// scanProgress reports the progress of the initial scan to the terminal or
+// as NDJSON, and to the dashboard.
+type scanProgress struct {
+	format    string
+	out       io.Writer
+	dashboard *dashboard.Server
+}
+
+// newScanProgress returns the progress sink for format. Daemons print no
+// progress bar; the dashboard, when running, gets every event.
+func newScanProgress(format string, daemon bool, dash *dashboard.Server) *scanProgress {
+	if daemon && format == progressFormatBar {
+		format = progressFormatNone
+	}
+	return &scanProgress{format: format, out: os.Stdout, dashboard: dash}
+}
+
+// emit reports a progress event.
+func (p *scanProgress) emit(event indexer.ProgressEvent) {
+	if p.dashboard != nil {
+		p.dashboard.BroadcastProgress(event)
+	}
+	switch p.format {
+	case progressFormatJSON:
+		data, err := json.Marshal(event)
+		if err == nil {
+			fmt.Fprintln(p.out, string(data))
+		}
+	case progressFormatBar:
+		if event.Phase == indexer.PhaseIndex {
+			printProgress(event)
+		}
+	}
+}
+
+// clear erases the progress bar once a workspace is indexed.
+func (p *scanProgress) clear() {
+	if p.format == progressFormatBar {
+		fmt.Fprint(p.out, "\r"+strings.Repeat(" ", 110)+"\r")
+	}
+}
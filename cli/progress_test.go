@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanProgress_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	p := newScanProgress(progressFormatJSON, true, nil)
+	p.out = &buf
+
+	p.emit(indexer.ProgressEvent{Phase: indexer.PhaseIndex, Workspace: "api", File: "a.go", Current: 1, Total: 2})
+	p.emit(indexer.ProgressEvent{Phase: indexer.PhaseDone})
+	p.clear()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var event indexer.ProgressEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, indexer.ProgressEvent{Phase: "index", Workspace: "api", File: "a.go", Current: 1, Total: 2}, event)
+	assert.JSONEq(t, `{"phase":"done","current":0,"total":0}`, lines[1])
+}
+
+func TestScanProgress_DaemonPrintsNoBar(t *testing.T) {
+	var buf bytes.Buffer
+	p := newScanProgress(progressFormatBar, true, nil)
+	p.out = &buf
+
+	p.emit(indexer.ProgressEvent{Phase: indexer.PhaseIndex, File: "a.go", Current: 1, Total: 1})
+	p.clear()
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectsPruneDryRun bool
+	projectsPruneJSON   bool
+
+	projectsSetIDStrategy string
+	projectsSetIDFrom     string
+	projectsSetIDDryRun   bool
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects <subcommand>",
+	Short: "Manage project IDs and the projects of a shared PostgreSQL namespace",
+}
+
+var projectsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the schemas of projects that no longer exist",
+	Long: `Delete the index schemas of projects in index.store.postgres.namespace whose
+directory no longer exists on this machine, such as removed clones and
+worktrees.
+
+Each project of a namespace is indexed in its own schema of the shared
+database. Run prune on the machine that owns the namespace: only projects
+identified by their absolute path (project.id_strategy path) are checked.
+
+Examples:
+  agentdx projects prune --dry-run
+  agentdx projects prune --json`,
+	Args: cobra.NoArgs,
+	RunE: runProjectsPrune,
+}
+
+var projectsSetIDCmd = &cobra.Command{
+	Use:   "set-id",
+	Short: "Change the project ID strategy and re-key the index",
+	Long: `Change project.id_strategy and move the rows indexed under the current
+project ID, and under the IDs of its workspaces, to the new ID.
+
+Strategies:
+  path  the absolute path of the project (default); changes when it moves
+  uuid  a random UUID saved as project.id; commit the config to share it
+  git   the URL of the origin remote, e.g. github.com/org/repo
+
+Stop 'agentdx watch' and the session daemon before re-keying. Use --from
+when the index was built under another ID, e.g. the old path of a moved
+repository.
+
+Examples:
+  agentdx projects set-id --strategy uuid
+  agentdx projects set-id --strategy git --dry-run
+  agentdx projects set-id --strategy path --from /old/path/to/repo`,
+	Args: cobra.NoArgs,
+	RunE: runProjectsSetID,
+}
+
+func init() {
+	projectsSetIDCmd.Flags().StringVar(&projectsSetIDStrategy, "strategy", "", "Project ID strategy: "+strings.Join(config.ProjectIDStrategies, ", "))
+	projectsSetIDCmd.Flags().StringVar(&projectsSetIDFrom, "from", "", "Project ID the index was built under (default: the current ID)")
+	projectsSetIDCmd.Flags().BoolVar(&projectsSetIDDryRun, "dry-run", false, "Show the ID changes without applying them")
+	_ = projectsSetIDCmd.MarkFlagRequired("strategy")
+	projectsCmd.AddCommand(projectsSetIDCmd)
+
+	projectsPruneCmd.Flags().BoolVar(&projectsPruneDryRun, "dry-run", false, "List the projects that would be deleted without deleting them")
+	projectsPruneCmd.Flags().BoolVar(&projectsPruneJSON, "json", false, "Output in JSON format")
+
+	projectsCmd.AddCommand(projectsPruneCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
+
+// ProjectsPruneJSON is the JSON output of 'agentdx projects prune'.
+type ProjectsPruneJSON struct {
+	Namespace string                   `json:"namespace"`
+	DryRun    bool                     `json:"dry_run"`
+	Pruned    []store.NamespaceProject `json:"pruned"`
+	Kept      int                      `json:"kept"`
+}
+
+func runProjectsPrune(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	pg := cfg.Index.Store.Postgres
+	if cfg.Index.Store.Backend == store.BackendSQLite || pg.Namespace == "" || pg.DSN == "" {
+		return errcode.New(errcode.Config, "projects prune requires the postgres backend with index.store.postgres.dsn and namespace set")
+	}
+
+	projects, err := store.ListNamespaceProjects(ctx, pg.ConnString(), pg.Namespace)
+	if err != nil {
+		return err
+	}
+	pruned := missingProjects(projects, func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	if !projectsPruneDryRun {
+		for _, p := range pruned {
+			if err := store.DropNamespaceProject(ctx, pg.ConnString(), pg.Namespace, p.Schema); err != nil {
+				return err
+			}
+		}
+	}
+
+	if projectsPruneJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ProjectsPruneJSON{
+			Namespace: pg.Namespace,
+			DryRun:    projectsPruneDryRun,
+			Pruned:    append([]store.NamespaceProject{}, pruned...),
+			Kept:      len(projects) - len(pruned),
+		})
+	}
+
+	verb := "Deleted"
+	if projectsPruneDryRun {
+		verb = "Would delete"
+	}
+	for _, p := range pruned {
+		fmt.Printf("%s %s (%s, last used %s)\n", verb, p.Schema, p.ProjectID, p.UsedAt.Format("2006-01-02"))
+	}
+	fmt.Printf("%d of %d projects in namespace %s pruned\n", len(pruned), len(projects), pg.Namespace)
+	return nil
+}
+
+// missingProjects returns the projects whose directory does not exist.
+// Projects with a UUID or git remote ID are kept.
+func missingProjects(projects []store.NamespaceProject, exists func(path string) bool) []store.NamespaceProject {
+	var missing []store.NamespaceProject
+	for _, p := range projects {
+		if filepath.IsAbs(p.ProjectID) && !exists(p.ProjectID) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// projectRekey is a project ID change of the root project or a workspace.
+type projectRekey struct {
+	Name     string
+	From, To string
+}
+
+func runProjectsSetID(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if !slices.Contains(config.ProjectIDStrategies, projectsSetIDStrategy) {
+		return errcode.New(errcode.InvalidArgs, "unknown strategy %q: use one of %s", projectsSetIDStrategy, strings.Join(config.ProjectIDStrategies, ", "))
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	oldID := projectsSetIDFrom
+	if oldID == "" {
+		oldID = cfg.ProjectID(projectRoot)
+	}
+
+	cfg.Project.IDStrategy = projectsSetIDStrategy
+	switch projectsSetIDStrategy {
+	case config.ProjectIDUUID:
+		if cfg.Project.ID == "" && projectsSetIDDryRun {
+			cfg.Project.ID = "<new UUID>"
+		} else if cfg.Project.ID == "" {
+			if cfg.Project.ID, err = config.NewProjectUUID(); err != nil {
+				return err
+			}
+		}
+	case config.ProjectIDGit:
+		// ProjectID falls back to the path when the remote cannot be read
+		if _, err := config.GitRemoteID(projectRoot); err != nil {
+			return err
+		}
+	}
+	newID := cfg.ProjectID(projectRoot)
+
+	rekeys := []projectRekey{{Name: "(root)", From: oldID, To: newID}}
+	for _, ws := range cfg.Workspaces {
+		rekeys = append(rekeys, projectRekey{Name: ws.Name, From: ws.ProjectID(oldID), To: ws.ProjectID(newID)})
+	}
+
+	for _, r := range rekeys {
+		fmt.Printf("%-12s %s -> %s\n", r.Name, r.From, r.To)
+	}
+	if projectsSetIDDryRun {
+		fmt.Println("Dry run: nothing changed")
+		return nil
+	}
+
+	if oldID != newID {
+		for _, r := range rekeys {
+			moved, err := rekeyProject(ctx, cfg, projectRoot, r)
+			if err != nil {
+				return fmt.Errorf("failed to re-key %s: %w", r.Name, err)
+			}
+			fmt.Printf("%-12s %d rows moved\n", r.Name, moved)
+		}
+	}
+
+	if err := cfg.Save(projectRoot); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	fmt.Printf("Project ID strategy set to %s (ID %s)\n", projectsSetIDStrategy, newID)
+	return nil
+}
+
+// rekeyProject moves the index of one project ID to another.
+func rekeyProject(ctx context.Context, cfg *config.Config, projectRoot string, r projectRekey) (int64, error) {
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	opts.ProjectID = r.From
+	st, err := store.Open(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer st.Close()
+	return st.RekeyProject(ctx, r.To)
+}
@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingProjects(t *testing.T) {
+	projects := []store.NamespaceProject{
+		{Schema: "alice_1", ProjectID: "/src/app"},
+		{Schema: "alice_2", ProjectID: "/src/removed"},
+		{Schema: "alice_3", ProjectID: "/src/app/services/api"},
+		{Schema: "alice_4", ProjectID: "github.com/org/app"},
+	}
+	exists := map[string]bool{"/src/app": true, "/src/app/services/api": true}
+
+	missing := missingProjects(projects, func(path string) bool { return exists[path] })
+	assert.Equal(t, []store.NamespaceProject{projects[1]}, missing)
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reindexDueToConfig bool
+	reindexJSON        bool
+	reindexProfile     string
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the index without interrupting searches",
+	Long: `Rebuild the index of the project and its workspaces from the files on disk.
+
+Each index is rebuilt beside the live one, which keeps answering searches,
+and then replaces it in a single transaction. Notes, query metrics and
+files soft-deleted within the retention period are kept.
+
+The settings that decide how files are chunked (index.chunking,
+index.search.cjk_bigrams and index.search.identifiers) are recorded with
+the index; search and status warn when they change. With --due-to-config,
+only indexes built with other settings are rebuilt.
+
+Restart 'agentdx watch' afterwards so that it chunks changed files with the
+new settings.`,
+	Args: cobra.NoArgs,
+	RunE: runReindex,
+}
+
+func init() {
+	reindexCmd.Flags().BoolVar(&reindexDueToConfig, "due-to-config", false, "Only rebuild indexes built with other chunking settings")
+	reindexCmd.Flags().BoolVar(&reindexJSON, "json", false, "Output in JSON format")
+	reindexCmd.Flags().StringVar(&reindexProfile, "profile", "", "Only index the paths of this indexing profile (see profiles in config; default: $"+config.IndexProfileEnv+")")
+
+	rootCmd.AddCommand(reindexCmd)
+}
+
+// ReindexJSON is the JSON output of 'agentdx reindex' for one index.
+type ReindexJSON struct {
+	Workspace string `json:"workspace,omitempty"`
+	Rebuilt   bool   `json:"rebuilt"`
+	Files     int    `json:"files"`
+	Chunks    int    `json:"chunks"`
+}
+
+func runReindex(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner, err := profileScanner(cfg, indexer.NewScanner(projectRoot, ignoreMatcher), reindexProfile)
+	if err != nil {
+		return err
+	}
+	chunker, err := newFileChunker(cfg)
+	if err != nil {
+		return err
+	}
+
+	progressFormat := progressFormatBar
+	if reindexJSON {
+		progressFormat = progressFormatNone
+	}
+	progress := newScanProgress(progressFormat, false, nil)
+
+	// The root project first, then one index per workspace
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	projectIDs := []string{opts.ProjectID}
+	names := []string{""}
+	for _, ws := range cfg.Workspaces {
+		projectIDs = append(projectIDs, ws.ProjectID(opts.ProjectID))
+		names = append(names, ws.Name)
+	}
+
+	var reports []ReindexJSON
+	for i, name := range names {
+		wsOpts := opts
+		wsOpts.ProjectID = projectIDs[i]
+		report, err := reindexProject(ctx, cfg, projectRoot, wsOpts, chunker, workspaceScanner(cfg, scanner, name), progress)
+		if err != nil {
+			if name != "" {
+				return fmt.Errorf("workspace %s: %w", name, err)
+			}
+			return err
+		}
+		report.Workspace = name
+		reports = append(reports, report)
+	}
+
+	if reindexJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	}
+	for _, r := range reports {
+		label := "Project"
+		if r.Workspace != "" {
+			label = "Workspace " + r.Workspace
+		}
+		if !r.Rebuilt {
+			fmt.Printf("%s: index is up to date with the chunking settings\n", label)
+			continue
+		}
+		fmt.Printf("%s: rebuilt %d files (%d chunks)\n", label, r.Files, r.Chunks)
+	}
+	return nil
+}
+
+// reindexProject rebuilds the index of one project ID under its shadow
+// project ID and swaps it in. With --due-to-config, an index whose recorded
+// fingerprint matches the configuration is left alone.
+func reindexProject(ctx context.Context, cfg *config.Config, projectRoot string, opts store.Options, chunker indexer.FileChunker, scanner *indexer.Scanner, progress *scanProgress) (ReindexJSON, error) {
+	fingerprint := cfg.IndexFingerprint()
+
+	live, err := store.Open(ctx, opts)
+	if err != nil {
+		return ReindexJSON{}, fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer live.Close()
+
+	if reindexDueToConfig {
+		indexed, err := live.IndexFingerprint(ctx)
+		if err != nil {
+			return ReindexJSON{}, err
+		}
+		// Indexes without a fingerprint predate it; rebuild them to be sure
+		if indexed == fingerprint {
+			return ReindexJSON{}, nil
+		}
+	}
+
+	// Start over if an earlier rebuild was interrupted
+	if err := live.DiscardShadow(ctx); err != nil {
+		return ReindexJSON{}, err
+	}
+
+	shadowOpts := opts
+	shadowOpts.ProjectID = store.ShadowProjectID(opts.ProjectID)
+	shadow, err := store.Open(ctx, shadowOpts)
+	if err != nil {
+		return ReindexJSON{}, fmt.Errorf("failed to open shadow index store: %w", err)
+	}
+	idx := newIndexer(cfg, projectRoot, store.NewShadowWriter(shadow), chunker, scanner)
+	stats, err := idx.IndexAllWithProgress(ctx, func(info indexer.ProgressInfo) {
+		progress.emit(indexer.ProgressEvent{
+			Phase:        indexer.PhaseIndex,
+			File:         info.CurrentFile,
+			Current:      info.Current,
+			Total:        info.Total,
+			Chunks:       info.Chunks,
+			ChunksPerSec: info.ChunksPerSec,
+		})
+	})
+	progress.clear()
+	if err == nil {
+		err = shadow.SetIndexFingerprint(ctx, fingerprint)
+	}
+	shadow.Close()
+	if err != nil {
+		if discardErr := live.DiscardShadow(ctx); discardErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", discardErr)
+		}
+		return ReindexJSON{}, fmt.Errorf("failed to rebuild index: %w", err)
+	}
+
+	files, err := live.SwapShadow(ctx)
+	if err != nil {
+		return ReindexJSON{}, err
+	}
+	return ReindexJSON{Rebuilt: true, Files: files, Chunks: stats.ChunksCreated}, nil
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"fmt"
 
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +25,22 @@ project to serve as reliable context for developers and AI agents.`,
 	}
 )
 
+// pathStyle is the --path-style flag shared by all commands.
+var pathStyle string
+
 func SetVersion(v string) {
 	version = v
 }
 
+// Execute runs the command line. Errors carry an errcode code, reported by
+// ReportError; commands run with --json also print them as a JSON payload.
 func Execute() error {
-	return rootCmd.Execute()
+	classifyUsageErrors(rootCmd)
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return nil
+	}
+	return reportJSONError(cmd, usageError(err))
 }
 
 // GetRootCmd returns the root command for documentation generation
@@ -37,6 +49,11 @@ func GetRootCmd() *cobra.Command {
 }
 
 func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return errcode.Wrap(errcode.InvalidArgs, err)
+	})
+	rootCmd.PersistentFlags().StringVar(&pathStyle, "path-style", search.PathStyleRepo, "File paths in output: repo (relative to the project root), absolute or cwd (relative to the current directory)")
+
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(watchCmd)
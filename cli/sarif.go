@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// SARIF 2.1.0 output, for code scanning UIs. Locations are relative to the
+// project root, recorded as the SRCROOT base.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifSrcRoot = "SRCROOT"
+)
+
+// Rules of the SARIF results, one per kind of search or trace result.
+var sarifRules = []sarifRule{
+	{ID: "search-result", ShortDescription: sarifMessage{Text: "Code matching a search query"}},
+	{ID: "trace-caller", ShortDescription: sarifMessage{Text: "Call site of a traced symbol"}},
+	{ID: "trace-callee", ShortDescription: sarifMessage{Text: "Call made by a traced symbol"}},
+	{ID: "trace-call", ShortDescription: sarifMessage{Text: "Call in the graph around a traced symbol"}},
+	{ID: "trace-implementation", ShortDescription: sarifMessage{Text: "Implementation of a traced interface"}},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+	Properties         map[string]any              `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Properties       map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int           `json:"startLine"`
+	EndLine   int           `json:"endLine,omitempty"`
+	Snippet   *sarifMessage `json:"snippet,omitempty"`
+}
+
+// newSARIFRun returns a run of the agentdx driver for the project at
+// projectRoot.
+func newSARIFRun(projectRoot string) sarifRun {
+	root := filepath.ToSlash(projectRoot)
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+	if !strings.HasPrefix(root, "/") {
+		root = "/" + root // Windows drive letters
+	}
+	return sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "agentdx",
+			Version:        version,
+			InformationURI: "https://github.com/doveaia/agentdx",
+			Rules:          sarifRules,
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLoc{
+			sarifSrcRoot: {URI: (&url.URL{Scheme: "file", Path: root}).String()},
+		},
+		Results: []sarifResult{},
+	}
+}
+
+// sarifLocationOf returns the location of lines start to end of a file
+// relative to the project root. end and snippet are optional.
+func sarifLocationOf(path string, start, end int, snippet string) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLoc{
+			URI:       (&url.URL{Path: filepath.ToSlash(path)}).String(),
+			URIBaseID: sarifSrcRoot,
+		},
+	}}
+	if start > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: start}
+		if end > start {
+			loc.PhysicalLocation.Region.EndLine = end
+		}
+		if snippet != "" {
+			loc.PhysicalLocation.Region.Snippet = &sarifMessage{Text: snippet}
+		}
+	}
+	return loc
+}
+
+// writeSARIF writes a SARIF log with the single run.
+func writeSARIF(w io.Writer, run sarifRun) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// writeSearchSARIF writes search results as SARIF notes, one per result,
+// with the matching code as the region snippet. Result paths must be
+// relative to projectRoot.
+func writeSearchSARIF(w io.Writer, projectRoot, query string, results []store.SearchResult) error {
+	run := newSARIFRun(projectRoot)
+	run.Properties = map[string]any{"query": query}
+	for _, r := range results {
+		text := fmt.Sprintf("Match for %q (score %.4f)", query, r.Score)
+		if r.Confidence != "" {
+			text = fmt.Sprintf("Match for %q (score %.4f, %s confidence)", query, r.Score, r.Confidence)
+		}
+		props := map[string]any{"score": r.Score}
+		if r.Confidence != "" {
+			props["confidence"] = r.Confidence
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     "search-result",
+			Level:      "note",
+			Message:    sarifMessage{Text: text},
+			Locations:  []sarifLocation{sarifLocationOf(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine, chunkCode(r.Chunk.Content))},
+			Properties: props,
+		})
+	}
+	return writeSARIF(w, run)
+}
+
+// writeTraceSARIF writes a trace result as SARIF notes: one per caller,
+// callee, call graph edge or implementation, located at the call site or
+// the implementing declaration. The traced symbol is a related location.
+// Result paths must be relative to projectRoot.
+func writeTraceSARIF(w io.Writer, projectRoot string, result trace.TraceResult) error {
+	run := newSARIFRun(projectRoot)
+	run.Properties = map[string]any{"query": result.Query}
+
+	related := func(sym trace.Symbol, what string) []sarifLocation {
+		if sym.File == "" {
+			return nil
+		}
+		loc := sarifLocationOf(sym.File, sym.Line, 0, "")
+		loc.ID = 1
+		loc.Message = &sarifMessage{Text: what + " " + sym.Name}
+		return []sarifLocation{loc}
+	}
+	callers := func(target trace.Symbol, infos []trace.CallerInfo, unresolved bool) {
+		for _, c := range infos {
+			text := fmt.Sprintf("%s calls %s", c.Symbol.Name, result.Query)
+			if unresolved {
+				text += " (definition not resolved)"
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:           "trace-caller",
+				Level:            "note",
+				Message:          sarifMessage{Text: text},
+				Locations:        []sarifLocation{sarifLocationOf(c.CallSite.File, c.CallSite.Line, 0, c.CallSite.Context)},
+				RelatedLocations: related(target, "Definition of"),
+			})
+		}
+	}
+
+	var symbol trace.Symbol
+	if result.Symbol != nil {
+		symbol = *result.Symbol
+	}
+	callers(symbol, result.Callers, false)
+	for _, d := range result.Definitions {
+		callers(d.Symbol, d.Callers, false)
+	}
+	callers(trace.Symbol{}, result.Unresolved, true)
+	for _, c := range result.Callees {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:           "trace-callee",
+			Level:            "note",
+			Message:          sarifMessage{Text: fmt.Sprintf("%s calls %s", result.Query, c.Symbol.Name)},
+			Locations:        []sarifLocation{sarifLocationOf(c.CallSite.File, c.CallSite.Line, 0, c.CallSite.Context)},
+			RelatedLocations: related(c.Symbol, "Definition of"),
+		})
+	}
+	if result.Graph != nil {
+		for _, e := range result.Graph.Edges {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    "trace-call",
+				Level:     "note",
+				Message:   sarifMessage{Text: fmt.Sprintf("%s calls %s", e.Caller, e.Callee)},
+				Locations: []sarifLocation{sarifLocationOf(e.File, e.Line, 0, "")},
+			})
+		}
+	}
+	for _, impl := range result.Implementations {
+		text := fmt.Sprintf("%s implements %s", impl.Type.Name, impl.Interface.Name)
+		loc := sarifLocationOf(impl.Type.File, impl.Type.Line, 0, "")
+		if impl.Method != nil {
+			text = fmt.Sprintf("%s.%s implements %s.%s", impl.Type.Name, impl.Method.Name, impl.Interface.Name, impl.Method.Name)
+			loc = sarifLocationOf(impl.Method.File, impl.Method.Line, 0, "")
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:           "trace-implementation",
+			Level:            "note",
+			Message:          sarifMessage{Text: text},
+			Locations:        []sarifLocation{loc},
+			RelatedLocations: related(impl.Interface, "Interface"),
+		})
+	}
+	return writeSARIF(w, run)
+}
@@ -6,45 +6,152 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
 	"github.com/doveaia/agentdx/search"
 	"github.com/doveaia/agentdx/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	searchLimit   int
-	searchJSON    bool
-	searchCompact bool
+	searchLimit     int
+	searchJSON      bool
+	searchCompact   bool
+	searchWorkspace string
+	searchFormat    string
+	searchDeleted   bool
+	searchInclude   []string
+	searchExclude   []string
+	searchLangs     []string
+	searchTypes     []string
+	searchSymbols   []string
+	searchPackages  []string
+	searchContext   int
+	searchGroupBy   string
+	searchPerGroup  int
+	searchQueries   []string
+	searchMinScore  float64
+	searchRefresh   bool
+	searchBlame     bool
+	searchAt        string
 )
 
+// plainText renders text as-is
+func plainText(s string) string { return s }
+
+// markMatch renders a term matching the query in bold yellow when stdout
+// is a terminal and NO_COLOR is not set
+func markMatch(s string) string {
+	if os.Getenv("NO_COLOR") != "" || !term.IsTerminal(os.Stdout.Fd()) {
+		return s
+	}
+	return "\x1b[1;33m" + s + "\x1b[0m"
+}
+
 // SearchResultJSON is a lightweight struct for JSON output (excludes vector, hash, updated_at)
 type SearchResultJSON struct {
-	FilePath  string  `json:"file_path"`
+	FilePath  string  `json:"file_path"` // in the --path-style style
+	Path      string  `json:"path"`      // relative to the project root
+	AbsPath   string  `json:"abs_path"`
 	StartLine int     `json:"start_line"`
 	EndLine   int     `json:"end_line"`
 	Score     float32 `json:"score"`
-	Content   string  `json:"content"`
+	// Confidence is high when the result stands out from the other matches
+	Confidence string `json:"confidence,omitempty"`
+	// Strategy is how the query was matched: prefix, identifier, phrase or
+	// natural
+	Strategy string `json:"strategy,omitempty"`
+	// Symbol is the symbol the result is about, of kind SymbolKind, and
+	// Package the package or module of its file
+	Symbol     string           `json:"symbol,omitempty"`
+	SymbolKind string           `json:"symbol_kind,omitempty"`
+	Package    string           `json:"package,omitempty"`
+	Type       string           `json:"type,omitempty"` // code, doc, config or summary
+	Content    string           `json:"content"`
+	Context    *search.Context  `json:"context,omitempty"` // surrounding lines (--context)
+	Notes      []SearchNoteJSON `json:"notes,omitempty"`
+	// Highlights are the spans matching the query, to quote exact lines
+	Highlights []store.Highlight `json:"highlights,omitempty"`
+	// Blame is the last commit of the matched lines (--blame)
+	Blame *store.Blame `json:"blame,omitempty"`
+	// Cells are the notebook cells of a result from a Jupyter notebook
+	Cells     []int      `json:"cells,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Stale     bool       `json:"stale,omitempty"` // file changed on disk since it was indexed
 }
 
 // SearchResultCompactJSON is a minimal struct for compact JSON output (no content field)
 type SearchResultCompactJSON struct {
 	FilePath  string  `json:"file_path"`
+	Path      string  `json:"path"`
+	AbsPath   string  `json:"abs_path"`
 	StartLine int     `json:"start_line"`
 	EndLine   int     `json:"end_line"`
 	Score     float32 `json:"score"`
+	// Confidence is high when the result stands out from the other matches
+	Confidence string `json:"confidence,omitempty"`
+	// Strategy is how the query was matched: prefix, identifier, phrase or
+	// natural
+	Strategy string `json:"strategy,omitempty"`
+	// Symbol is the symbol the result is about, of kind SymbolKind, and
+	// Package the package or module of its file
+	Symbol     string           `json:"symbol,omitempty"`
+	SymbolKind string           `json:"symbol_kind,omitempty"`
+	Package    string           `json:"package,omitempty"`
+	Notes      []SearchNoteJSON `json:"notes,omitempty"`
+	// Highlights are the spans matching the query, to quote exact lines
+	Highlights []store.Highlight `json:"highlights,omitempty"`
+	// Blame is the last commit of the matched lines (--blame)
+	Blame *store.Blame `json:"blame,omitempty"`
+	// Cells are the notebook cells of a result from a Jupyter notebook
+	Cells     []int      `json:"cells,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Stale     bool       `json:"stale,omitempty"` // file changed on disk since it was indexed
+}
+
+// SearchNoteJSON is a note attached to a search result
+type SearchNoteJSON struct {
+	ID        int64  `json:"id"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Text      string `json:"text"`
+}
+
+// toSearchNotesJSON converts notes for JSON output
+func toSearchNotesJSON(notes []store.Note) []SearchNoteJSON {
+	if len(notes) == 0 {
+		return nil
+	}
+	out := make([]SearchNoteJSON, len(notes))
+	for i, n := range notes {
+		out[i] = SearchNoteJSON{ID: n.ID, StartLine: n.StartLine, EndLine: n.EndLine, Text: n.Text}
+	}
+	return out
 }
 
 var searchCmd = &cobra.Command{
-	Use:   "search <query>",
+	Use:   "search [query]",
 	Short: "Search codebase with full text search",
 	Long: `Search your codebase using full text search queries.
 
 The search will:
 - Query the documents_fts table with your search terms
-- Return the most relevant results with file path, line numbers, and score`,
-	Args: cobra.ExactArgs(1),
+- Return the most relevant results with file path, line numbers, and score
+
+--queries runs several queries over one index connection instead of one
+process per query. Results are grouped by query, and code already returned
+for an earlier query is not repeated:
+
+  agentdx search --queries "user,auth,login" --json
+
+--at searches a snapshot of the index taken with 'agentdx index snapshot'
+at a commit, to look up code as it was before it changed:
+
+  agentdx search "token refresh" --at v1.4.0`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSearch,
 }
 
@@ -52,15 +159,70 @@ func init() {
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "Maximum number of results to return")
 	searchCmd.Flags().BoolVarP(&searchJSON, "json", "j", false, "Output results in JSON format (for AI agents)")
 	searchCmd.Flags().BoolVarP(&searchCompact, "compact", "c", false, "Output minimal JSON without content (requires --json)")
+	searchCmd.Flags().StringVar(&searchFormat, "format", formatText, "Output format: text, md (Markdown with code blocks), csv or sarif")
+	searchCmd.Flags().StringVarP(&searchWorkspace, "workspace", "w", "", "Search only the named workspace (see workspaces in config)")
+	searchCmd.Flags().BoolVar(&searchDeleted, "deleted", false, "Search code from deleted files kept within the retention period")
+	searchCmd.Flags().StringSliceVar(&searchInclude, "include", nil, "Only search files matching these glob patterns (e.g. 'internal/**', '*.go')")
+	searchCmd.Flags().StringSliceVar(&searchExclude, "exclude", nil, "Skip files matching these glob patterns (e.g. '*_test.go', 'vendor/')")
+	searchCmd.Flags().StringSliceVar(&searchLangs, "lang", nil, "Only search files of these languages or extensions (e.g. go, ts, vue)")
+	searchCmd.Flags().StringSliceVar(&searchTypes, "type", nil, "Only search chunks of these types: code, doc (Markdown, text), config or summary (agentdx summarize)")
+	searchCmd.Flags().StringSliceVar(&searchSymbols, "symbol", nil, "Only search chunks about these symbols (e.g. ParseConfig), as shown in JSON output")
+	searchCmd.Flags().StringSliceVar(&searchPackages, "package", nil, "Only search files of these packages or modules, as --group-by package names them")
+	searchCmd.Flags().IntVarP(&searchContext, "context", "C", 0, "Include N lines before and after each result, read from disk")
+	searchCmd.Flags().StringVar(&searchGroupBy, "group-by", "", "Group results by package (Go package, npm workspace or Python module); --limit counts groups")
+	searchCmd.Flags().IntVar(&searchPerGroup, "per-group", 3, "Maximum number of results per group (with --group-by)")
+	searchCmd.Flags().StringSliceVar(&searchQueries, "queries", nil, "Run several comma-separated queries in one search; --limit applies to each")
+	searchCmd.Flags().BoolVar(&searchRefresh, "auto-refresh", false, "Index the files changed or deleted since indexing before searching")
+	searchCmd.Flags().BoolVar(&searchBlame, "blame", false, "Show the author, date and subject of the last commit of each result's matched lines (git blame)")
+	searchCmd.Flags().StringVar(&searchAt, "at", "", "Search the snapshot of the index taken at this commit (see 'agentdx index snapshot')")
+	searchCmd.Flags().Float64Var(&searchMinScore, "min-score", 0, "Drop results scoring below this score (scores depend on the backend; see --json output)")
 }
 
-func runSearch(cmd *cobra.Command, args []string) error {
-	query := args[0]
+func runSearch(cmd *cobra.Command, args []string) (err error) {
 	ctx := context.Background()
 
+	// The query argument, if any, runs first in a batch search
+	queries := search.BatchQueries(append(append([]string{}, args...), searchQueries...))
+	if len(queries) == 0 {
+		return errcode.New(errcode.InvalidArgs, "a query or --queries is required")
+	}
+	query := queries[0]
+	batch := len(searchQueries) > 0
+
 	// Validate flag combination
 	if searchCompact && !searchJSON {
-		return fmt.Errorf("--compact flag requires --json flag")
+		return errcode.New(errcode.InvalidArgs, "--compact flag requires --json flag")
+	}
+	if searchContext < 0 {
+		return errcode.New(errcode.InvalidArgs, "--context must not be negative")
+	}
+	if searchRefresh && searchDeleted {
+		return errcode.New(errcode.InvalidArgs, "--auto-refresh cannot be used with --deleted")
+	}
+	if searchMinScore < 0 {
+		return errcode.New(errcode.InvalidArgs, "--min-score must not be negative")
+	}
+	if searchAt != "" && (searchRefresh || searchDeleted || searchBlame) {
+		return errcode.New(errcode.InvalidArgs, "--at cannot be used with --auto-refresh, --deleted or --blame")
+	}
+	if searchContext > 0 && searchCompact {
+		return errcode.New(errcode.InvalidArgs, "--context cannot be used with --compact")
+	}
+	if err := validateFormat(searchFormat, searchJSON, formatMarkdown, formatCSV, formatSARIF); err != nil {
+		return err
+	}
+	if err := validateGroupBy(); err != nil {
+		return err
+	}
+	if batch && searchGroupBy != "" {
+		return errcode.New(errcode.InvalidArgs, "--queries cannot be combined with --group-by")
+	}
+	if batch && searchFormat != formatText {
+		return errcode.New(errcode.InvalidArgs, "--queries cannot be combined with --format %s", searchFormat)
+	}
+	types, err := store.ParseChunkTypes(searchTypes)
+	if err != nil {
+		return err
 	}
 
 	// Find project root
@@ -69,45 +231,137 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+
 	// Load configuration
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize PostgreSQL FTS store
-	ftsStore, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, projectRoot)
+	// Open index store, or the snapshot taken at --at
+	var ftsStore store.SearchStore
+	if searchAt != "" {
+		ftsStore, err = openSnapshotStore(ctx, cfg, projectRoot, searchWorkspace, searchAt)
+	} else {
+		ftsStore, err = openWorkspaceStore(ctx, cfg, projectRoot, searchWorkspace)
+	}
 	if err != nil {
-		if searchJSON {
-			return outputSearchError(err)
-		}
-		return fmt.Errorf("failed to connect to postgres: %w", err)
+		return fmt.Errorf("failed to open index store: %w", err)
 	}
 	defer ftsStore.Close()
 
-	// Search using FTS
-	results, err := ftsStore.SearchFTS(ctx, query, searchLimit*2)
-	if err != nil {
-		if searchJSON {
-			return outputSearchError(err)
+	// Bring the index up to date with the files on disk first
+	if searchRefresh {
+		if err := refreshIndex(ctx, cfg, projectRoot, searchWorkspace, ftsStore); err != nil {
+			return err
 		}
+	}
+
+	// Search using FTS; path filters are applied by the index query
+	filter := store.SearchFilter{
+		Include:  searchInclude,
+		Exclude:  searchExclude,
+		Langs:    searchLangs,
+		Types:    types,
+		Symbols:  searchSymbols,
+		Packages: searchPackages,
+		Deleted:  searchDeleted,
+	}
+	if batch {
+		return runBatchSearch(ctx, cfg, projectRoot, paths, ftsStore, queries, filter)
+	}
+	start := time.Now()
+	resp, err := search.Run(ctx, ftsStore, cfg.Index.Search, searchRequest(cfg, projectRoot, query, filter))
+	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
 	}
+	printSearchWarnings(resp.Warnings)
+	results, groups, confidence := resp.Results, resp.Groups, resp.Confidence
+	recordQuery(ctx, cfg, projectRoot, store.QueryKindSearch, query, time.Since(start), len(results))
 
-	// Apply structural boosting
-	results = search.ApplyBoost(results, cfg.Index.Search.Boost)
+	// Tell an empty index from a query without matches; the latter exits
+	// with E_NO_RESULTS once the (empty) results are printed
+	if len(results) == 0 {
+		if err := search.CheckIndexed(ctx, ftsStore); err != nil {
+			return err
+		}
+		defer func() {
+			if err == nil {
+				err = errcode.ErrNoResults
+			}
+		}()
+	}
 
-	// Trim to requested limit
-	if len(results) > searchLimit {
-		results = results[:searchLimit]
+	// Warn when the index lags behind the files on disk; a snapshot is not
+	// meant to follow them
+	var staleness *search.Staleness
+	if !searchDeleted && searchAt == "" {
+		staleness, err = search.CheckProjectStaleness(ctx, cfg, projectRoot, searchWorkspace, ftsStore)
+		if err != nil && !searchJSON {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if staleness != nil && (searchJSON || searchFormat != formatText) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", staleness.Warning())
+		}
+	}
+
+	// Warn when the index was built with other chunking settings
+	mismatch, err := search.CheckConfigFingerprint(ctx, cfg, ftsStore)
+	if err != nil && !searchJSON {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if mismatch != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", mismatch.Warning())
+	}
+
+	// Cache results so 'agentdx open <n>' can jump to them; the lines of
+	// snapshot results may have moved since
+	if !searchDeleted && searchAt == "" {
+		if err := saveLastSearch(projectRoot, query, results); err != nil && !searchJSON {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Read the lines around each result
+	contexts, err := search.AddContext(ctx, ftsStore, projectRoot, results, searchContext)
+	if err != nil {
+		return err
+	}
+
+	if groups != nil {
+		if searchJSON {
+			return outputSearchGroupsJSON(groups, paths, staleness, searchCompact)
+		}
+		return outputSearchGroups(query, groups, paths, staleness, confidence)
 	}
 
 	// JSON output mode
 	if searchJSON {
 		if searchCompact {
-			return outputSearchCompactJSON(results)
+			return outputSearchCompactJSON(results, paths, staleness)
 		}
-		return outputSearchJSON(results)
+		return outputSearchJSON(results, contexts, paths, staleness)
+	}
+
+	// SARIF locations are always relative to the project root
+	if searchFormat == formatSARIF {
+		return writeSearchSARIF(os.Stdout, projectRoot, query, results)
+	}
+
+	results = displaySearchResults(results, paths)
+	switch searchFormat {
+	case formatMarkdown:
+		return writeSearchMarkdown(os.Stdout, query, results, search.ConfidenceNote(confidence))
+	case formatCSV:
+		return writeSearchCSV(os.Stdout, results)
+	}
+
+	if staleness != nil {
+		fmt.Printf("Warning: %s\n\n", staleness.Warning())
 	}
 
 	if len(results) == 0 {
@@ -119,73 +373,201 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Found %d results for: %q\n\n", len(results), query)
 
 	for i, result := range results {
-		fmt.Printf("─── Result %d (score: %.4f) ───\n", i+1, result.Score)
-		fmt.Printf("File: %s:%d-%d\n", result.Chunk.FilePath, result.Chunk.StartLine, result.Chunk.EndLine)
-		fmt.Println()
-
-		// Display content with line numbers
-		lines := strings.Split(result.Chunk.Content, "\n")
-		// Skip the "File: xxx" prefix line if present
-		startIdx := 0
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
-			startIdx = 2 // Skip "File: xxx" and empty line
-		}
+		printSearchResult(i+1, result, contexts[i])
+	}
+	if note := search.ConfidenceNote(confidence); note != "" {
+		fmt.Printf("Note: %s\n\n", note)
+	}
 
-		lineNum := result.Chunk.StartLine
-		for j := startIdx; j < len(lines) && j < startIdx+15; j++ {
-			fmt.Printf("%4d │ %s\n", lineNum, lines[j])
-			lineNum++
-		}
-		if len(lines)-startIdx > 15 {
-			fmt.Printf("     │ ... (%d more lines)\n", len(lines)-startIdx-15)
-		}
-		fmt.Println()
+	if !searchDeleted && searchAt == "" {
+		fmt.Println("Open a result with: agentdx open <number>")
 	}
+	return nil
+}
 
+// refreshIndex indexes the files of the root project or the named workspace
+// that changed on disk since they were indexed, and removes those deleted
+// since, as 'agentdx search --auto-refresh' does before searching. The
+// summary goes to stderr so that JSON output stays parseable.
+func refreshIndex(ctx context.Context, cfg *config.Config, projectRoot, workspace string, st store.SearchStore) error {
+	scanner, err := search.ProjectScanner(cfg, projectRoot, workspace)
+	if err != nil {
+		return err
+	}
+	staleness, err := search.CheckStaleness(ctx, st, scanner)
+	if err != nil {
+		return err
+	}
+	diff := staleness.Diff()
+	if len(diff.Changed)+len(diff.Deleted) == 0 {
+		return nil
+	}
+
+	chunker, err := newFileChunker(cfg)
+	if err != nil {
+		return err
+	}
+	stats, err := newIndexer(cfg, projectRoot, st, chunker, scanner).IndexDiff(ctx, diff, true)
+	if err != nil {
+		return fmt.Errorf("failed to refresh index: %w", err)
+	}
+	if stats.FilesIndexed+stats.FilesRemoved > 0 {
+		fmt.Fprintf(os.Stderr, "Refreshed index: %d files indexed, %d removed (took %s)\n",
+			stats.FilesIndexed, stats.FilesRemoved, stats.Duration.Round(time.Millisecond))
+	}
 	return nil
 }
 
-// outputSearchJSON outputs results in JSON format for AI agents
-func outputSearchJSON(results []store.SearchResult) error {
+// printSearchResult prints result number n with up to 15 lines of its
+// content and the context lines c, if any.
+func printSearchResult(n int, result store.SearchResult, c *search.Context) {
+	fmt.Printf("─── Result %d (score: %.4f) ───\n", n, result.Score)
+	fmt.Printf("File: %s:%d-%d\n", result.Chunk.FilePath, result.Chunk.StartLine, result.Chunk.EndLine)
+	if cells := search.FormatCells(result.Cells); cells != "" {
+		fmt.Printf("Notebook: %s\n", cells)
+	}
+	if result.Chunk.DeletedAt != nil {
+		fmt.Printf("Deleted: %s\n", result.Chunk.DeletedAt.Format("2006-01-02 15:04"))
+	}
+	if result.Blame != nil {
+		fmt.Printf("Blame: %s\n", result.Blame.Summary())
+	}
+	for _, note := range result.Notes {
+		fmt.Printf("Note #%d (lines %d-%d): %s\n", note.ID, note.StartLine, note.EndLine, note.Text)
+	}
+	fmt.Println()
+
+	if c != nil {
+		printContextLines(c.StartLine, c.Before)
+	}
+
+	// Display content with line numbers
+	lines := strings.Split(result.Chunk.Content, "\n")
+	// Skip the "File: xxx" prefix line if present
+	startIdx := 0
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
+		startIdx = 2 // Skip "File: xxx" and empty line
+	}
+
+	lineNum := result.Chunk.StartLine
+	for j := startIdx; j < len(lines) && j < startIdx+15; j++ {
+		fmt.Printf("%4d │ %s\n", lineNum, search.MarkLine(lines[j], lineNum, result.Highlights, plainText, markMatch))
+		lineNum++
+	}
+	if len(lines)-startIdx > 15 {
+		fmt.Printf("     │ ... (%d more lines)\n", len(lines)-startIdx-15)
+	}
+	if c != nil {
+		printContextLines(result.Chunk.EndLine+1, c.After)
+	}
+	fmt.Println()
+}
+
+// printContextLines prints context lines starting at line first, marked
+// with a dotted bar to set them apart from the result.
+func printContextLines(first int, text string) {
+	if text == "" {
+		return
+	}
+	for j, line := range strings.Split(text, "\n") {
+		fmt.Printf("%4d ┆ %s\n", first+j, line)
+	}
+}
+
+// outputSearchJSON outputs results in JSON format for AI agents. contexts
+// are aligned with results.
+func outputSearchJSON(results []store.SearchResult, contexts []*search.Context, paths *search.Paths, staleness *search.Staleness) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(searchResultsJSON(results, contexts, paths, staleness))
+}
+
+// searchResultsJSON converts results to their JSON form. contexts are
+// aligned with results.
+func searchResultsJSON(results []store.SearchResult, contexts []*search.Context, paths *search.Paths, staleness *search.Staleness) []SearchResultJSON {
 	jsonResults := make([]SearchResultJSON, len(results))
 	for i, r := range results {
 		jsonResults[i] = SearchResultJSON{
-			FilePath:  r.Chunk.FilePath,
-			StartLine: r.Chunk.StartLine,
-			EndLine:   r.Chunk.EndLine,
-			Score:     r.Score,
-			Content:   r.Chunk.Content,
+			FilePath:   paths.Display(r.Chunk.FilePath),
+			Path:       r.Chunk.FilePath,
+			AbsPath:    paths.Abs(r.Chunk.FilePath),
+			StartLine:  r.Chunk.StartLine,
+			EndLine:    r.Chunk.EndLine,
+			Score:      r.Score,
+			Confidence: r.Confidence,
+			Strategy:   string(r.Strategy),
+			Symbol:     r.Chunk.Symbol,
+			SymbolKind: r.Chunk.SymbolKind,
+			Package:    r.Chunk.Package,
+			Type:       r.Chunk.Type,
+			Content:    r.Chunk.Content,
+			Context:    contexts[i],
+			Notes:      toSearchNotesJSON(r.Notes),
+			Highlights: r.Highlights,
+			Blame:      r.Blame,
+			Cells:      r.Cells,
+			DeletedAt:  r.Chunk.DeletedAt,
+			Stale:      staleness != nil && staleness.IsStale(r.Chunk.FilePath),
 		}
 	}
+	return jsonResults
+}
 
+// outputSearchCompactJSON outputs results in minimal JSON format (without content)
+func outputSearchCompactJSON(results []store.SearchResult, paths *search.Paths, staleness *search.Staleness) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(jsonResults)
+	return encoder.Encode(searchResultsCompactJSON(results, paths, staleness))
 }
 
-// outputSearchCompactJSON outputs results in minimal JSON format (without content)
-func outputSearchCompactJSON(results []store.SearchResult) error {
+// searchResultsCompactJSON converts results to their compact JSON form.
+func searchResultsCompactJSON(results []store.SearchResult, paths *search.Paths, staleness *search.Staleness) []SearchResultCompactJSON {
 	jsonResults := make([]SearchResultCompactJSON, len(results))
 	for i, r := range results {
 		jsonResults[i] = SearchResultCompactJSON{
-			FilePath:  r.Chunk.FilePath,
-			StartLine: r.Chunk.StartLine,
-			EndLine:   r.Chunk.EndLine,
-			Score:     r.Score,
+			FilePath:   paths.Display(r.Chunk.FilePath),
+			Path:       r.Chunk.FilePath,
+			AbsPath:    paths.Abs(r.Chunk.FilePath),
+			StartLine:  r.Chunk.StartLine,
+			EndLine:    r.Chunk.EndLine,
+			Score:      r.Score,
+			Confidence: r.Confidence,
+			Strategy:   string(r.Strategy),
+			Symbol:     r.Chunk.Symbol,
+			SymbolKind: r.Chunk.SymbolKind,
+			Package:    r.Chunk.Package,
+			Notes:      toSearchNotesJSON(r.Notes),
+			Highlights: r.Highlights,
+			Blame:      r.Blame,
+			Cells:      r.Cells,
+			DeletedAt:  r.Chunk.DeletedAt,
+			Stale:      staleness != nil && staleness.IsStale(r.Chunk.FilePath),
 		}
 	}
+	return jsonResults
+}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(jsonResults)
+// searchRequest returns the search of query the flags ask for.
+func searchRequest(cfg *config.Config, projectRoot, query string, filter store.SearchFilter) search.Request {
+	req := search.Request{
+		Query:       query,
+		Filter:      filter,
+		Limit:       searchLimit,
+		MinScore:    float32(searchMinScore),
+		Blame:       searchBlame || (cfg.Index.Search.Blame && searchAt == ""),
+		ProjectRoot: projectRoot,
+	}
+	if searchGroupBy != "" {
+		req.PerGroup = searchPerGroup
+	}
+	return req
 }
 
-// outputSearchError outputs an error in JSON format
-func outputSearchError(err error) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	_ = encoder.Encode(map[string]string{"error": err.Error()})
-	return nil
+// printSearchWarnings prints the failures a search did without.
+func printSearchWarnings(warnings []error) {
+	for _, err := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 }
 
 // SearchJSON returns results in JSON format for AI agents
@@ -197,28 +579,23 @@ func SearchJSON(projectRoot string, query string, limit int) ([]store.SearchResu
 		return nil, err
 	}
 
-	// Initialize PostgreSQL FTS store
-	ftsStore, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, projectRoot)
+	// Open index store
+	ftsStore, err := openStore(ctx, cfg, projectRoot)
 	if err != nil {
 		return nil, err
 	}
 	defer ftsStore.Close()
 
-	// Search using FTS
-	results, err := ftsStore.SearchFTS(ctx, query, limit*2)
+	resp, err := search.Run(ctx, ftsStore, cfg.Index.Search, search.Request{
+		Query:       query,
+		Limit:       limit,
+		ProjectRoot: projectRoot,
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Apply structural boosting
-	results = search.ApplyBoost(results, cfg.Index.Search.Boost)
-
-	// Trim to requested limit
-	if len(results) > limit {
-		results = results[:limit]
-	}
-
-	return results, nil
+	printSearchWarnings(resp.Warnings)
+	return resp.Results, nil
 }
 
 func init() {
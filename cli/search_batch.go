@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+)
+
+// SearchBatchJSON holds the results of one query in --queries JSON output
+type SearchBatchJSON struct {
+	Query string `json:"query"`
+	// Confidence is high, low or none for the query's own results, before
+	// those returned for an earlier query were dropped
+	Confidence string `json:"confidence"`
+	Results    any    `json:"results"`
+}
+
+// runBatchSearch runs each query of a --queries search on ftsStore and prints
+// the results grouped by query. Results overlapping those of an earlier query
+// are dropped, and --limit applies to each query.
+func runBatchSearch(ctx context.Context, cfg *config.Config, projectRoot string, paths *search.Paths, ftsStore store.SearchStore, queries []string, filter store.SearchFilter) (err error) {
+	batches := make([]search.Batch, len(queries))
+	for i, query := range queries {
+		start := time.Now()
+		resp, err := search.Run(ctx, ftsStore, cfg.Index.Search, searchRequest(cfg, projectRoot, query, filter))
+		if err != nil {
+			return fmt.Errorf("search failed for %q: %w", query, err)
+		}
+		printSearchWarnings(resp.Warnings)
+		recordQuery(ctx, cfg, projectRoot, store.QueryKindSearch, query, time.Since(start), len(resp.Results))
+		batches[i] = search.Batch{Query: query, Results: resp.Results, Confidence: resp.Confidence}
+	}
+	search.DedupeBatches(batches)
+
+	// Exit with E_NO_RESULTS when no query matched, as a single search does
+	if len(search.FlattenBatches(batches)) == 0 {
+		if err := search.CheckIndexed(ctx, ftsStore); err != nil {
+			return err
+		}
+		defer func() {
+			if err == nil {
+				err = errcode.ErrNoResults
+			}
+		}()
+	}
+
+	// Warn when the index lags behind the files on disk
+	var staleness *search.Staleness
+	if !searchDeleted && searchAt == "" {
+		var err error
+		staleness, err = search.CheckProjectStaleness(ctx, cfg, projectRoot, searchWorkspace, ftsStore)
+		if err != nil && !searchJSON {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if staleness != nil && searchJSON {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", staleness.Warning())
+		}
+	}
+
+	// Warn when the index was built with other chunking settings
+	mismatch, err := search.CheckConfigFingerprint(ctx, cfg, ftsStore)
+	if err != nil && !searchJSON {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if mismatch != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", mismatch.Warning())
+	}
+
+	// Cache results so 'agentdx open <n>' can jump to them, numbered across queries
+	if !searchDeleted && searchAt == "" {
+		if err := saveLastSearch(projectRoot, strings.Join(queries, ", "), search.FlattenBatches(batches)); err != nil && !searchJSON {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Read the lines around each result
+	contexts := make([][]*search.Context, len(batches))
+	for i, b := range batches {
+		if contexts[i], err = search.AddContext(ctx, ftsStore, projectRoot, b.Results, searchContext); err != nil {
+			return err
+		}
+	}
+
+	if searchJSON {
+		return outputSearchBatchesJSON(batches, contexts, paths, staleness, searchCompact)
+	}
+	return outputSearchBatches(batches, contexts, paths, staleness)
+}
+
+// outputSearchBatches prints the results of each query under a header,
+// numbered across queries for 'agentdx open <number>'.
+func outputSearchBatches(batches []search.Batch, contexts [][]*search.Context, paths *search.Paths, staleness *search.Staleness) error {
+	if staleness != nil {
+		fmt.Printf("Warning: %s\n\n", staleness.Warning())
+	}
+	total := len(search.FlattenBatches(batches))
+	if total == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d results for %d queries\n\n", total, len(batches))
+	n := 0
+	for i, b := range batches {
+		fmt.Printf("═══ %q · %d results ═══\n\n", b.Query, len(b.Results))
+		if note := search.ConfidenceNote(b.Confidence); note != "" && len(b.Results) > 0 {
+			fmt.Printf("Note: %s\n\n", note)
+		}
+		if len(b.Results) == 0 {
+			fmt.Println("No new results.")
+			fmt.Println()
+		}
+		for j, result := range displaySearchResults(b.Results, paths) {
+			n++
+			printSearchResult(n, result, contexts[i][j])
+		}
+	}
+
+	if !searchDeleted && searchAt == "" {
+		fmt.Println("Open a result with: agentdx open <number>")
+	}
+	return nil
+}
+
+// outputSearchBatchesJSON outputs the results of each query in JSON format
+func outputSearchBatchesJSON(batches []search.Batch, contexts [][]*search.Context, paths *search.Paths, staleness *search.Staleness, compact bool) error {
+	jsonBatches := make([]SearchBatchJSON, len(batches))
+	for i, b := range batches {
+		jsonBatches[i].Query = b.Query
+		jsonBatches[i].Confidence = b.Confidence
+		if compact {
+			jsonBatches[i].Results = searchResultsCompactJSON(b.Results, paths, staleness)
+		} else {
+			jsonBatches[i].Results = searchResultsJSON(b.Results, contexts[i], paths, staleness)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonBatches)
+}
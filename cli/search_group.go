@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+)
+
+// SearchGroupJSON is a package of results in --group-by JSON output
+type SearchGroupJSON struct {
+	Package string  `json:"package"`
+	Kind    string  `json:"kind"` // go, npm, python or dir
+	Dir     string  `json:"dir"`
+	Score   float32 `json:"score"` // score of the best result
+	Hits    int     `json:"hits"`  // results found in the package, before --per-group
+	Results any     `json:"results"`
+}
+
+// validateGroupBy checks the --group-by and --per-group flags.
+func validateGroupBy() error {
+	if searchGroupBy == "" {
+		return nil
+	}
+	if searchGroupBy != search.GroupPackage {
+		return errcode.New(errcode.InvalidArgs, "unknown --group-by %q (expected %s)", searchGroupBy, search.GroupPackage)
+	}
+	if searchPerGroup < 1 {
+		return errcode.New(errcode.InvalidArgs, "--per-group must be at least 1")
+	}
+	if searchFormat != formatText {
+		return errcode.New(errcode.InvalidArgs, "--group-by cannot be combined with --format %s", searchFormat)
+	}
+	if searchContext > 0 {
+		return errcode.New(errcode.InvalidArgs, "--group-by cannot be combined with --context")
+	}
+	return nil
+}
+
+// outputSearchGroups prints grouped results: one header per package and a
+// line per result, numbered for 'agentdx open <number>'.
+func outputSearchGroups(query string, groups []search.Group, paths *search.Paths, staleness *search.Staleness, confidence string) error {
+	if staleness != nil {
+		fmt.Printf("Warning: %s\n\n", staleness.Warning())
+	}
+	if len(groups) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d packages for: %q\n\n", len(groups), query)
+	n := 0
+	for _, g := range groups {
+		fmt.Printf("─── %s (%s, %s) · %d of %d results · best %.4f ───\n", g.Name, g.Kind, paths.Display(g.Dir), len(g.Results), g.Hits, g.Score)
+		for _, r := range g.Results {
+			n++
+			fmt.Printf("%3d. %s:%d-%d (score: %.4f)\n", n, paths.Display(r.Chunk.FilePath), r.Chunk.StartLine, r.Chunk.EndLine, r.Score)
+			if line := firstCodeLine(r.Chunk.Content); line != "" {
+				fmt.Printf("     │ %s\n", line)
+			}
+			if cells := search.FormatCells(r.Cells); cells != "" {
+				fmt.Printf("     Notebook: %s\n", cells)
+			}
+			if r.Blame != nil {
+				fmt.Printf("     Blame: %s\n", r.Blame.Summary())
+			}
+			for _, note := range r.Notes {
+				fmt.Printf("     Note #%d (lines %d-%d): %s\n", note.ID, note.StartLine, note.EndLine, note.Text)
+			}
+		}
+		fmt.Println()
+	}
+	if note := search.ConfidenceNote(confidence); note != "" {
+		fmt.Printf("Note: %s\n\n", note)
+	}
+
+	if !searchDeleted && searchAt == "" {
+		fmt.Println("Open a result with: agentdx open <number>")
+	}
+	return nil
+}
+
+// outputSearchGroupsJSON outputs grouped results in JSON format
+func outputSearchGroupsJSON(groups []search.Group, paths *search.Paths, staleness *search.Staleness, compact bool) error {
+	jsonGroups := make([]SearchGroupJSON, len(groups))
+	for i, g := range groups {
+		jsonGroups[i] = SearchGroupJSON{
+			Package: g.Name,
+			Kind:    g.Kind,
+			Dir:     g.Dir,
+			Score:   g.Score,
+			Hits:    g.Hits,
+		}
+		if compact {
+			results := make([]SearchResultCompactJSON, len(g.Results))
+			for j, r := range g.Results {
+				results[j] = SearchResultCompactJSON{
+					FilePath:   paths.Display(r.Chunk.FilePath),
+					Path:       r.Chunk.FilePath,
+					AbsPath:    paths.Abs(r.Chunk.FilePath),
+					StartLine:  r.Chunk.StartLine,
+					EndLine:    r.Chunk.EndLine,
+					Score:      r.Score,
+					Confidence: r.Confidence,
+					Strategy:   string(r.Strategy),
+					Symbol:     r.Chunk.Symbol,
+					SymbolKind: r.Chunk.SymbolKind,
+					Package:    r.Chunk.Package,
+					Notes:      toSearchNotesJSON(r.Notes),
+					Highlights: r.Highlights,
+					Blame:      r.Blame,
+					Cells:      r.Cells,
+					DeletedAt:  r.Chunk.DeletedAt,
+					Stale:      staleness != nil && staleness.IsStale(r.Chunk.FilePath),
+				}
+			}
+			jsonGroups[i].Results = results
+			continue
+		}
+		results := make([]SearchResultJSON, len(g.Results))
+		for j, r := range g.Results {
+			results[j] = SearchResultJSON{
+				FilePath:   paths.Display(r.Chunk.FilePath),
+				Path:       r.Chunk.FilePath,
+				AbsPath:    paths.Abs(r.Chunk.FilePath),
+				StartLine:  r.Chunk.StartLine,
+				EndLine:    r.Chunk.EndLine,
+				Score:      r.Score,
+				Confidence: r.Confidence,
+				Strategy:   string(r.Strategy),
+				Symbol:     r.Chunk.Symbol,
+				SymbolKind: r.Chunk.SymbolKind,
+				Package:    r.Chunk.Package,
+				Type:       r.Chunk.Type,
+				Content:    r.Chunk.Content,
+				Notes:      toSearchNotesJSON(r.Notes),
+				Highlights: r.Highlights,
+				Blame:      r.Blame,
+				Cells:      r.Cells,
+				DeletedAt:  r.Chunk.DeletedAt,
+				Stale:      staleness != nil && staleness.IsStale(r.Chunk.FilePath),
+			}
+		}
+		jsonGroups[i].Results = results
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonGroups)
+}
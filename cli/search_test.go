@@ -186,7 +186,7 @@ func TestSearchResultJSONStruct(t *testing.T) {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
 
-	expectedFields := []string{"file_path", "start_line", "end_line", "score", "content"}
+	expectedFields := []string{"file_path", "path", "abs_path", "start_line", "end_line", "score", "content"}
 	for _, field := range expectedFields {
 		if _, exists := decoded[field]; !exists {
 			t.Errorf("expected field '%s' to be present", field)
@@ -213,7 +213,7 @@ func TestSearchResultCompactJSONStruct(t *testing.T) {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
 
-	expectedFields := []string{"file_path", "start_line", "end_line", "score"}
+	expectedFields := []string{"file_path", "path", "abs_path", "start_line", "end_line", "score"}
 	for _, field := range expectedFields {
 		if _, exists := decoded[field]; !exists {
 			t.Errorf("expected field '%s' to be present", field)
@@ -3,22 +3,28 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/encrypt"
+	"github.com/doveaia/agentdx/errcode"
 	"github.com/doveaia/agentdx/localsetup"
 	"github.com/doveaia/agentdx/session"
+	"github.com/doveaia/agentdx/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	quietMode     bool
-	forceStop     bool
-	jsonOutput    bool
-	sessionPgName string
-	sessionPgPort int
+	quietMode      bool
+	forceStop      bool
+	jsonOutput     bool
+	sessionPgName  string
+	sessionPgPort  int
+	sessionProfile string
+	sessionLogTail int
 )
 
 var sessionCmd = &cobra.Command{
@@ -35,7 +41,7 @@ Session State:
   - Log file: .agentdx/session.log
 
 The daemon starts automatically when hooks are installed. For manual control,
-use the start/stop/status subcommands.`,
+use the start/stop/status subcommands, and read the log with 'agentdx session log'.`,
 }
 
 var sessionStartCmd = &cobra.Command{
@@ -63,7 +69,8 @@ Container Options:
 var sessionStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the watch daemon",
-	Long:  `Stop the agentdx watch daemon gracefully. Uses SIGTERM and waits up to 5 seconds. Use --force to send SIGKILL immediately.`,
+	Long: `Stop the agentdx watch daemon gracefully. Uses SIGTERM and waits up to 5 seconds. Use --force to send SIGKILL immediately.
+On Windows, the daemon is stopped with taskkill.`,
 	Example: `  # Stop gracefully
   agentdx session stop
 
@@ -78,7 +85,13 @@ var sessionStopCmd = &cobra.Command{
 var sessionStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show daemon status",
-	Long:  `Display the current status of the watch daemon, including whether it's running, its PID, uptime, and log file location.`,
+	Long: `Display the current status of the watch daemon, including whether it's running, its PID, uptime, and log file location.
+
+A running daemon is asked for its health: the last file event it processed,
+the number of events waiting, when the symbol index was last persisted and
+its heartbeat. A daemon whose heartbeat stopped for over a minute, or that
+does not answer, is reported as wedged; 'agentdx session start' (run by the
+session hooks) restarts it.`,
 	Example: `  # Human-readable status
   agentdx session status
 
@@ -87,11 +100,28 @@ var sessionStatusCmd = &cobra.Command{
 	RunE: runSessionStatus,
 }
 
+var sessionLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the end of the session log",
+	Long: `Print the last lines of the session log, .agentdx/session.log.
+
+When encryption is enabled (encryption.enabled in config), the lines of the
+log are encrypted and this command decrypts them with the configured key.`,
+	Example: `  # Last 50 lines
+  agentdx session log
+
+  # Last 200 lines
+  agentdx session log -n 200`,
+	Args: cobra.NoArgs,
+	RunE: runSessionLog,
+}
+
 func init() {
 	// session start flags
 	sessionStartCmd.Flags().BoolVarP(&quietMode, "quiet", "q", false, "Suppress output")
 	sessionStartCmd.Flags().StringVarP(&sessionPgName, "pg-name", "n", "", "PostgreSQL container name (default: agentdx-postgres)")
 	sessionStartCmd.Flags().IntVarP(&sessionPgPort, "pg-port", "p", 0, "PostgreSQL host port (default: 55432)")
+	sessionStartCmd.Flags().StringVar(&sessionProfile, "profile", "", "Only index the paths of this indexing profile (see profiles in config; default: $"+config.IndexProfileEnv+")")
 
 	// session stop flags
 	sessionStopCmd.Flags().BoolVarP(&quietMode, "quiet", "q", false, "Suppress output")
@@ -100,10 +130,14 @@ func init() {
 	// session status flags
 	sessionStatusCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 
+	// session log flags
+	sessionLogCmd.Flags().IntVarP(&sessionLogTail, "lines", "n", 50, "Number of lines to show")
+
 	// Register subcommands
 	sessionCmd.AddCommand(sessionStartCmd)
 	sessionCmd.AddCommand(sessionStopCmd)
 	sessionCmd.AddCommand(sessionStatusCmd)
+	sessionCmd.AddCommand(sessionLogCmd)
 }
 
 // buildSessionContainerOptions builds container options from flags and config.
@@ -119,6 +153,7 @@ func buildSessionContainerOptions(cfg *config.Config, flagName string, flagPort
 	if cfg.Index.Store.Postgres.Port != 0 {
 		opts.Port = cfg.Index.Store.Postgres.Port
 	}
+	opts.SSLMode = cfg.Index.Store.Postgres.SSLMode
 
 	// Apply flag values (highest priority)
 	if flagName != "" {
@@ -132,8 +167,12 @@ func buildSessionContainerOptions(cfg *config.Config, flagName string, flagPort
 }
 
 func runSessionStart(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	return startSession(sessionPgName, sessionPgPort, sessionProfile)
+}
 
+// startSession starts the watch daemon in the background, with the container
+// name, port and indexing profile flags of 'session start' or 'watch --daemon'.
+func startSession(pgName string, pgPort int, profile string) error {
 	// Find project root
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
@@ -143,31 +182,67 @@ func runSessionStart(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Load configuration
-	cfg, err := config.Load(projectRoot)
+	pid, wasRunning, err := startProjectSession(context.Background(), projectRoot, pgName, pgPort, profile)
 	if err != nil {
 		if !quietMode {
-			fmt.Fprintf(os.Stderr, "Error: failed to load configuration: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		}
 		return err
 	}
 
-	// Build container options: flags > config > defaults
-	opts := buildSessionContainerOptions(cfg, sessionPgName, sessionPgPort)
+	// Print status message unless quiet
+	if !quietMode {
+		if wasRunning {
+			fmt.Printf("Session daemon already running (PID: %d)\n", pid)
+		} else {
+			fmt.Printf("Session daemon started (PID: %d)\n", pid)
+		}
+	}
 
-	// Ensure PostgreSQL is running BEFORE starting daemon
-	_, err = localsetup.EnsurePostgresRunning(ctx, projectRoot, opts)
+	return nil
+}
+
+// startProjectSession starts the watch daemon of the project at projectRoot
+// unless it is running, restarting a wedged one, and records the project in
+// the registry of 'agentdx sessions'. It returns the daemon's PID and
+// whether it was already running.
+func startProjectSession(ctx context.Context, projectRoot, pgName string, pgPort int, profile string) (int, bool, error) {
+	// Load configuration
+	cfg, err := config.Load(projectRoot)
 	if err != nil {
-		if !quietMode {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 0, false, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Fail before forking when the indexing profile is unknown
+	if name := config.SelectedIndexProfile(profile); name != "" {
+		if _, err := cfg.IndexProfile(name); err != nil {
+			return 0, false, errcode.Wrap(errcode.InvalidArgs, err)
+		}
+	}
+
+	// Fail before forking when the encryption key is missing, since the
+	// daemon could not log why it stopped
+	cipher, err := encrypt.NewSource(cfg.Encryption).Cipher()
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Build container options: flags > config > defaults
+	opts := buildSessionContainerOptions(cfg, pgName, pgPort)
+
+	// Ensure PostgreSQL is running BEFORE starting daemon (SQLite needs no container)
+	if cfg.Index.Store.Backend != store.BackendSQLite {
+		if _, err := localsetup.EnsurePostgresRunning(ctx, projectRoot, opts); err != nil {
+			return 0, false, err
 		}
-		return err
 	}
 
 	// Create daemon manager with container options
 	dm := session.NewDaemonManagerWithOptions(projectRoot, session.DaemonOptions{
-		PgName: opts.Name,
-		PgPort: opts.Port,
+		PgName:  opts.Name,
+		PgPort:  opts.Port,
+		Profile: profile,
+		Cipher:  cipher,
 	})
 
 	// Check if already running
@@ -176,26 +251,33 @@ func runSessionStart(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Warning: failed to check daemon status: %v\n", err)
 	}
 
+	// Restart a daemon whose event loop stopped beating, so that the session
+	// hook heals it
+	if wasRunning {
+		status, _ := dm.Status()
+		if wedged, reason := session.Wedged(ctx, projectRoot, status.PID, time.Now()); wedged {
+			if !quietMode {
+				fmt.Fprintf(os.Stderr, "Session daemon (PID: %d) is not responding (%s), restarting\n", status.PID, reason)
+			}
+			if err := dm.Stop(ctx, true); err != nil {
+				return 0, false, fmt.Errorf("failed to stop daemon: %w", err)
+			}
+			wasRunning = false
+		}
+	}
+
 	// Start the daemon
 	if err := dm.Start(ctx); err != nil {
-		if !quietMode {
-			fmt.Fprintf(os.Stderr, "Error: failed to start daemon: %v\n", err)
-		}
-		return err
+		return 0, false, fmt.Errorf("failed to start daemon: %w", err)
 	}
 
-	// Print status message unless quiet
-	if !quietMode {
-		if wasRunning {
-			status, _ := dm.Status()
-			fmt.Printf("Session daemon already running (PID: %d)\n", status.PID)
-		} else {
-			status, _ := dm.Status()
-			fmt.Printf("Session daemon started (PID: %d)\n", status.PID)
-		}
+	// The registry is best effort; the daemon runs either way
+	if err := session.Register(projectRoot, !wasRunning); err != nil && !quietMode {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register project: %v\n", err)
 	}
 
-	return nil
+	status, _ := dm.Status()
+	return status.PID, wasRunning, nil
 }
 
 func runSessionStop(cmd *cobra.Command, args []string) error {
@@ -210,8 +292,9 @@ func runSessionStop(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Create daemon manager
-	dm := session.NewDaemonManager(projectRoot)
+	// Create daemon manager; stop messages are logged in the clear when the
+	// encryption key is missing, since they hold no code
+	dm := session.NewDaemonManagerWithOptions(projectRoot, session.DaemonOptions{Cipher: sessionCipher(projectRoot)})
 
 	// Check if running before stopping
 	wasRunning, err := dm.IsRunning()
@@ -253,15 +336,57 @@ func runSessionStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get daemon status: %w", err)
 	}
 
+	var health *session.Health
+	var healthErr error
+	if status.Running {
+		health, healthErr = session.QueryHealth(context.Background(), projectRoot)
+	}
+
 	// Output based on format flag
 	if jsonOutput {
-		return outputStatusJSON(status)
+		return outputStatusJSON(status, health, healthErr)
 	}
 
-	return outputStatusHuman(status)
+	return outputStatusHuman(status, health, healthErr)
 }
 
-func outputStatusHuman(status session.DaemonStatus) error {
+func runSessionLog(cmd *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cipher, err := encrypt.NewSource(cfg.Encryption).Cipher()
+	if err != nil {
+		return err
+	}
+
+	dm := session.NewDaemonManagerWithOptions(projectRoot, session.DaemonOptions{Cipher: cipher})
+	lines, err := dm.TailLog(max(sessionLogTail, 0))
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// sessionCipher returns the cipher of the session log of the project, or
+// nil when encryption is disabled or its key cannot be read.
+func sessionCipher(projectRoot string) *encrypt.Cipher {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil
+	}
+	cipher, _ := encrypt.NewSource(cfg.Encryption).Cipher()
+	return cipher
+}
+
+func outputStatusHuman(status session.DaemonStatus, health *session.Health, healthErr error) error {
 	if status.Running {
 		relativePath := relativeLogPath(status.LogFile)
 		fmt.Printf("agentdx session daemon: running\n")
@@ -271,6 +396,23 @@ func outputStatusHuman(status session.DaemonStatus) error {
 			fmt.Printf("Uptime: %s\n", formatUptime(uptime))
 		}
 		fmt.Printf("Log: %s\n", relativePath)
+		switch {
+		case health != nil:
+			state := "ok"
+			if health.Stale(time.Now()) {
+				state = "wedged (no heartbeat for " + formatUptime(time.Since(health.Heartbeat)) + ")"
+			}
+			fmt.Printf("Health: %s, %s\n", state, health.Phase)
+			if !health.LastEvent.IsZero() {
+				fmt.Printf("Last event: %s (%s ago)\n", health.LastEventPath, formatUptime(time.Since(health.LastEvent)))
+			}
+			fmt.Printf("Queue depth: %d\n", health.QueueDepth)
+			if !health.LastPersist.IsZero() {
+				fmt.Printf("Last persist: %s ago\n", formatUptime(time.Since(health.LastPersist)))
+			}
+		case !errors.Is(healthErr, session.ErrNoHealthEndpoint):
+			fmt.Printf("Health: wedged (%v)\n", healthErr)
+		}
 		return nil
 	}
 
@@ -278,7 +420,7 @@ func outputStatusHuman(status session.DaemonStatus) error {
 	return nil
 }
 
-func outputStatusJSON(status session.DaemonStatus) error {
+func outputStatusJSON(status session.DaemonStatus, health *session.Health, healthErr error) error {
 	// Create a simplified JSON output
 	output := map[string]any{
 		"running": status.Running,
@@ -289,6 +431,14 @@ func outputStatusJSON(status session.DaemonStatus) error {
 		if !status.StartTime.IsZero() {
 			output["start_time"] = status.StartTime.Format(time.RFC3339)
 		}
+		switch {
+		case health != nil:
+			output["health"] = health
+			output["wedged"] = health.Stale(time.Now())
+		case !errors.Is(healthErr, session.ErrNoHealthEndpoint):
+			output["health_error"] = healthErr.Error()
+			output["wedged"] = true
+		}
 	}
 
 	encoder := json.NewEncoder(os.Stdout)
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/session"
+	"github.com/spf13/cobra"
+)
+
+// Session states reported by 'agentdx sessions'.
+const (
+	sessionRunning = "running"
+	sessionStopped = "stopped"
+	sessionWedged  = "wedged"
+	sessionMissing = "missing" // The project directory or its config is gone
+)
+
+var (
+	sessionsJSON        bool
+	sessionsForce       bool
+	sessionsInterval    time.Duration
+	sessionsPgName      string
+	sessionsPgPort      int
+	sessionsKeepRunning bool
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage the session daemons of all projects",
+	Long: `Show and control the watch daemons of every project on this machine.
+
+A project is registered in ~/.agentdx/projects.json the first time its
+session daemon starts, through 'agentdx session start', 'agentdx watch
+--daemon' or the session hooks. Without a subcommand, the status of each
+registered project's daemon is listed.
+
+States:
+  running  the daemon is up and healthy
+  wedged   the daemon runs but its heartbeat stopped or it does not answer
+  stopped  no daemon runs
+  missing  the project directory or its .agentdx/config.yaml is gone`,
+	Example: `  # List the daemons of all projects
+  agentdx sessions
+
+  # Start or stop every daemon
+  agentdx sessions start-all
+  agentdx sessions stop-all
+
+  # Keep every daemon running from a single process
+  agentdx sessions supervise`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsList,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the daemons of all registered projects",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsList,
+}
+
+var sessionsStartAllCmd = &cobra.Command{
+	Use:   "start-all",
+	Short: "Start the daemons of all registered projects",
+	Long: `Start the watch daemon of every registered project that is not running,
+and restart wedged ones. PostgreSQL containers are started as needed, with
+the settings of each project's config.`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsStartAll,
+}
+
+var sessionsStopAllCmd = &cobra.Command{
+	Use:   "stop-all",
+	Short: "Stop the daemons of all registered projects",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsStopAll,
+}
+
+var sessionsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove projects that no longer exist from the registry",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsPrune,
+}
+
+var sessionsSuperviseCmd = &cobra.Command{
+	Use:   "supervise",
+	Short: "Keep the daemons of all registered projects running",
+	Long: `Run in the foreground and keep the watch daemon of every registered project
+running: at each interval the registry is read again, and daemons that
+stopped or are wedged are (re)started. Projects registered while the
+supervisor runs are picked up at the next check.
+
+On SIGINT or SIGTERM the supervisor stops the daemons it supervises, unless
+--keep-running is set. Run it from a login item, a systemd user unit or a
+terminal multiplexer to have one process own all daemons.`,
+	Example: `  # Check every 30 seconds (default)
+  agentdx sessions supervise
+
+  # Check every 5 minutes and leave the daemons running on exit
+  agentdx sessions supervise --interval 5m --keep-running`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsSupervise,
+}
+
+func init() {
+	sessionsCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output in JSON format")
+	sessionsListCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output in JSON format")
+
+	sessionsStartAllCmd.Flags().StringVarP(&sessionsPgName, "pg-name", "n", "", "PostgreSQL container name (default: from each project's config)")
+	sessionsStartAllCmd.Flags().IntVarP(&sessionsPgPort, "pg-port", "p", 0, "PostgreSQL host port (default: from each project's config)")
+
+	sessionsStopAllCmd.Flags().BoolVarP(&sessionsForce, "force", "f", false, "Force kill with SIGKILL")
+
+	sessionsSuperviseCmd.Flags().DurationVar(&sessionsInterval, "interval", 30*time.Second, "Time between checks of the daemons")
+	sessionsSuperviseCmd.Flags().BoolVar(&sessionsKeepRunning, "keep-running", false, "Leave the daemons running when the supervisor exits")
+	sessionsSuperviseCmd.Flags().StringVarP(&sessionsPgName, "pg-name", "n", "", "PostgreSQL container name (default: from each project's config)")
+	sessionsSuperviseCmd.Flags().IntVarP(&sessionsPgPort, "pg-port", "p", 0, "PostgreSQL host port (default: from each project's config)")
+
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsStartAllCmd)
+	sessionsCmd.AddCommand(sessionsStopAllCmd)
+	sessionsCmd.AddCommand(sessionsPruneCmd)
+	sessionsCmd.AddCommand(sessionsSuperviseCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+// projectSession is the daemon state of a registered project.
+type projectSession struct {
+	Root        string    `json:"root"`
+	State       string    `json:"state"`
+	PID         int       `json:"pid,omitempty"`
+	StartTime   time.Time `json:"start_time,omitzero"`
+	Reason      string    `json:"reason,omitempty"`
+	LastStarted time.Time `json:"last_started,omitzero"`
+}
+
+// projectSessionState returns the daemon state of the project at root.
+func projectSessionState(ctx context.Context, p session.RegisteredProject) projectSession {
+	ps := projectSession{Root: p.Root, State: sessionStopped, LastStarted: p.LastStarted}
+	if !config.Exists(p.Root) {
+		ps.State = sessionMissing
+		return ps
+	}
+	status, err := session.NewDaemonManager(p.Root).Status()
+	if err != nil || !status.Running {
+		return ps
+	}
+	ps.State, ps.PID, ps.StartTime = sessionRunning, status.PID, status.StartTime
+	if wedged, reason := session.Wedged(ctx, p.Root, status.PID, time.Now()); wedged {
+		ps.State, ps.Reason = sessionWedged, reason
+	}
+	return ps
+}
+
+// projectSessions returns the daemon state of every registered project.
+func projectSessions(ctx context.Context) ([]projectSession, error) {
+	projects, err := session.LoadRegistry()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]projectSession, 0, len(projects))
+	for _, p := range projects {
+		sessions = append(sessions, projectSessionState(ctx, p))
+	}
+	return sessions, nil
+}
+
+func runSessionsList(_ *cobra.Command, _ []string) error {
+	sessions, err := projectSessions(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if sessionsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sessions)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No projects registered; a project is added when its session daemon starts")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSTATUS\tPID\tUPTIME")
+	for _, s := range sessions {
+		pid, uptime := "-", "-"
+		if s.PID != 0 {
+			pid = fmt.Sprint(s.PID)
+		}
+		if !s.StartTime.IsZero() {
+			uptime = formatUptime(time.Since(s.StartTime))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Root, s.State, pid, uptime)
+	}
+	return w.Flush()
+}
+
+func runSessionsStartAll(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	sessions, err := projectSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, s := range sessions {
+		if s.State == sessionMissing {
+			fmt.Printf("%s: skipped, project missing\n", s.Root)
+			continue
+		}
+		pid, wasRunning, err := startProjectSession(ctx, s.Root, sessionsPgName, sessionsPgPort, "")
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("%s: failed: %v\n", s.Root, err)
+		case wasRunning:
+			fmt.Printf("%s: already running (PID: %d)\n", s.Root, pid)
+		default:
+			fmt.Printf("%s: started (PID: %d)\n", s.Root, pid)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to start %d of %d session daemons", failed, len(sessions))
+	}
+	return nil
+}
+
+func runSessionsStopAll(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	projects, err := session.LoadRegistry()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, p := range projects {
+		if !config.Exists(p.Root) {
+			continue
+		}
+		dm := session.NewDaemonManager(p.Root)
+		if running, _ := dm.IsRunning(); !running {
+			continue
+		}
+		if err := dm.Stop(ctx, sessionsForce); err != nil {
+			failed++
+			fmt.Printf("%s: failed: %v\n", p.Root, err)
+			continue
+		}
+		fmt.Printf("%s: stopped\n", p.Root)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to stop %d session daemons", failed)
+	}
+	return nil
+}
+
+func runSessionsPrune(_ *cobra.Command, _ []string) error {
+	projects, err := session.LoadRegistry()
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, p := range projects {
+		if !config.Exists(p.Root) {
+			missing = append(missing, p.Root)
+		}
+	}
+	removed, err := session.Unregister(missing...)
+	if err != nil {
+		return err
+	}
+	for _, root := range missing {
+		fmt.Printf("Removed %s\n", root)
+	}
+	fmt.Printf("%d projects removed from the registry\n", removed)
+	return nil
+}
+
+func runSessionsSupervise(_ *cobra.Command, _ []string) error {
+	if sessionsInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logf := func(format string, args ...any) {
+		fmt.Printf("%s "+format+"\n", append([]any{time.Now().Format(time.DateTime)}, args...)...)
+	}
+	logf("Supervising the session daemons of registered projects every %s", sessionsInterval)
+
+	// Roots of the projects whose daemons the supervisor checked, to stop
+	// them on exit
+	supervised := make(map[string]bool)
+	ticker := time.NewTicker(sessionsInterval)
+	defer ticker.Stop()
+	for {
+		sessions, err := projectSessions(ctx)
+		if err != nil {
+			logf("Warning: %v", err)
+		}
+		for _, s := range sessions {
+			if s.State == sessionMissing {
+				continue
+			}
+			supervised[s.Root] = true
+			if s.State == sessionRunning {
+				continue
+			}
+			pid, _, err := startProjectSession(ctx, s.Root, sessionsPgName, sessionsPgPort, "")
+			if err != nil {
+				logf("%s: failed to start: %v", s.Root, err)
+				continue
+			}
+			if s.State == sessionWedged {
+				logf("%s: restarted wedged daemon (%s), PID %d", s.Root, s.Reason, pid)
+			} else {
+				logf("%s: started, PID %d", s.Root, pid)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if !sessionsKeepRunning {
+				stopCtx := context.Background()
+				for root := range supervised {
+					if err := session.NewDaemonManager(root).Stop(stopCtx, false); err != nil {
+						logf("%s: failed to stop: %v", root, err)
+					}
+				}
+			}
+			logf("Supervisor stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	project := t.TempDir()
+	require.NoError(t, config.DefaultConfig().Save(project))
+	require.NoError(t, session.Register(project, false))
+	require.NoError(t, session.Register(t.TempDir(), false))
+
+	sessions, err := projectSessions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	states := map[string]string{}
+	for _, s := range sessions {
+		states[s.Root] = s.State
+	}
+	assert.Equal(t, sessionStopped, states[project])
+	for root, state := range states {
+		if root != project {
+			assert.Equal(t, sessionMissing, state)
+		}
+	}
+}
@@ -52,7 +52,7 @@ var agentdxPreToolUseHooks = []ToolHook{
 		Hooks: []HookAction{
 			{
 				Type:    "command",
-				Command: "echo '⚠️ AGENTDX FALLBACK: Grep tool requested. Use agentdx search instead unless agentdx failed.'",
+				Command: "agentdx hook report-fallback 2>/dev/null; echo '⚠️ AGENTDX FALLBACK: Grep tool requested. Use agentdx search instead unless agentdx failed.'",
 			},
 		},
 	},
@@ -61,7 +61,7 @@ var agentdxPreToolUseHooks = []ToolHook{
 		Hooks: []HookAction{
 			{
 				Type:    "command",
-				Command: "echo '⚠️ AGENTDX FALLBACK: Glob tool requested. Use agentdx files instead unless agentdx failed.'",
+				Command: "agentdx hook report-fallback 2>/dev/null; echo '⚠️ AGENTDX FALLBACK: Glob tool requested. Use agentdx files instead unless agentdx failed.'",
 			},
 		},
 	},
@@ -269,3 +269,11 @@ func createDefaultSettings() *ClaudeSettings {
 		},
 	}
 }
+
+// isAgentdxHookCommand reports whether a hook command was installed by
+// setup or init
+func isAgentdxHookCommand(command string) bool {
+	return contains(command, ".claude/hooks/agentdx/") ||
+		contains(command, "AGENTDX FALLBACK") ||
+		contains(command, "echo 'agentdx:")
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince time.Duration
+	statsLimit int
+	statsJSON  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show query latency and hit-rate metrics",
+	Long: `Show the latency, result counts and follow-up rate of recent search and
+trace queries, and the queries that returned nothing, so boosts and synonyms
+can be tuned where they help.
+
+Queries are only recorded when metrics are enabled in .agentdx/config.yaml:
+
+  metrics:
+    enabled: true
+    store_queries: true  # keep query text; by default only a hash is stored
+
+A query is followed up when an MCP agent reads one of its result files
+(agentdx_read_chunk or a file resource) within 10 minutes.
+
+Examples:
+  agentdx stats
+  agentdx stats --since 24h --json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().DurationVar(&statsSince, "since", 7*24*time.Hour, "Only include queries recorded within this duration")
+	statsCmd.Flags().IntVar(&statsLimit, "limit", 20, "Maximum number of queries returning nothing to list")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output in JSON format")
+
+	statsFallbacksCmd.Flags().DurationVar(&statsSince, "since", 7*24*time.Hour, "Only include fallbacks recorded within this duration")
+	statsFallbacksCmd.Flags().IntVar(&statsLimit, "limit", 20, "Maximum number of queries to list")
+	statsFallbacksCmd.Flags().BoolVar(&statsJSON, "json", false, "Output in JSON format")
+
+	statsCmd.AddCommand(statsFallbacksCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsFallbacksCmd = &cobra.Command{
+	Use:   "fallbacks",
+	Short: "Show the searches agents ran without agentdx",
+	Long: `Show the fallback incidents recorded by 'agentdx hook report-fallback':
+agents searching with Grep or Glob instead of agentdx, and agentdx searches
+that found nothing or failed. The queries agents fall back on most often
+show where search needs tuning, such as synonyms, boosts or summaries.
+
+The hooks installed by 'agentdx setup' record fallbacks in
+.agentdx/fallbacks.log.
+
+Examples:
+  agentdx stats fallbacks
+  agentdx stats fallbacks --since 24h --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsFallbacks,
+}
+
+// FallbackStatsJSON is the JSON output of 'agentdx stats fallbacks'.
+type FallbackStatsJSON struct {
+	Since   time.Time              `json:"since"`
+	Total   int                    `json:"total"`
+	Tools   []search.FallbackStats `json:"tools"`
+	Queries []search.FallbackQuery `json:"queries"`
+}
+
+// StatsJSON is the JSON output of 'agentdx stats'.
+type StatsJSON struct {
+	Since   time.Time           `json:"since"`
+	Kinds   []search.QueryStats `json:"kinds"`
+	Empties []search.EmptyQuery `json:"empty_queries"`
+}
+
+func runStats(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+
+	since := time.Now().Add(-statsSince)
+	records, err := st.ListQueries(ctx, since)
+	if err != nil {
+		return err
+	}
+	kinds, empties := search.SummarizeQueries(records)
+	if statsLimit >= 0 && len(empties) > statsLimit {
+		empties = empties[:statsLimit]
+	}
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(StatsJSON{Since: since, Kinds: kinds, Empties: empties})
+	}
+
+	if !cfg.Metrics.Enabled {
+		fmt.Println("Query metrics are disabled; set metrics.enabled: true in .agentdx/config.yaml to record queries.")
+	}
+	if len(records) == 0 {
+		fmt.Printf("No queries recorded since %s.\n", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	fmt.Printf("Queries since %s\n\n", since.Format("2006-01-02 15:04"))
+	fmt.Printf("%-8s %8s %14s %14s %8s %8s\n", "KIND", "QUERIES", "NO RESULTS", "FOLLOWED UP", "AVG MS", "P95 MS")
+	for _, k := range kinds {
+		fmt.Printf("%-8s %8d %14s %14s %8d %8d\n", k.Kind, k.Queries,
+			percentOf(k.ZeroResults, k.Queries), percentOf(k.FollowedUp, k.Queries),
+			k.AvgLatencyMs, k.P95LatencyMs)
+	}
+
+	if len(empties) == 0 {
+		return nil
+	}
+	fmt.Println("\nQueries returning nothing (most frequent first):")
+	for _, e := range empties {
+		query := e.Query
+		if query == "" {
+			query = "hash " + e.QueryHash
+		} else {
+			query = fmt.Sprintf("%q", query)
+		}
+		fmt.Printf("  %3dx  %-6s  %s  (last %s)\n", e.Count, e.Kind, query, e.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	if !cfg.Metrics.StoreQueries {
+		fmt.Println("\nSet metrics.store_queries: true to record query text instead of hashes.")
+	}
+	return nil
+}
+
+func runStatsFallbacks(_ *cobra.Command, _ []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().Add(-statsSince)
+	records, err := search.ReadFallbacks(config.GetFallbackLogPath(projectRoot), since)
+	if err != nil {
+		return err
+	}
+	tools, queries := search.SummarizeFallbacks(records)
+	if statsLimit >= 0 && len(queries) > statsLimit {
+		queries = queries[:statsLimit]
+	}
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(FallbackStatsJSON{Since: since, Total: len(records), Tools: tools, Queries: queries})
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("No fallbacks recorded since %s.\n", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	fmt.Printf("Fallbacks since %s: %d\n\n", since.Format("2006-01-02 15:04"), len(records))
+	fmt.Printf("%-20s %8s\n", "TOOL", "COUNT")
+	for _, t := range tools {
+		fmt.Printf("%-20s %8d\n", t.Tool, t.Count)
+	}
+
+	if len(queries) == 0 {
+		return nil
+	}
+	fmt.Println("\nQueries agents fell back on (most frequent first):")
+	for _, q := range queries {
+		reason := ""
+		if q.Reason != "" {
+			reason = ", " + q.Reason
+		}
+		fmt.Printf("  %3dx  %-16s  %q  (last %s%s)\n", q.Count, q.Tool, q.Query, q.LastSeen.Local().Format("2006-01-02 15:04"), reason)
+	}
+	return nil
+}
+
+// percentOf formats n and its share of total, e.g. "3 (12%)".
+func percentOf(n, total int) string {
+	return fmt.Sprintf("%d (%d%%)", n, n*100/total)
+}
+
+// recordQuery logs a CLI query when metrics are enabled. Workspace queries
+// are recorded with the root project so that 'agentdx stats' covers them.
+func recordQuery(ctx context.Context, cfg *config.Config, projectRoot, kind, query string, latency time.Duration, results int) {
+	if !cfg.Metrics.Enabled {
+		return
+	}
+	st, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record query: %v\n", err)
+		return
+	}
+	defer st.Close()
+	search.RecordQuery(ctx, cfg.Metrics, st, kind, "cli", query, latency, results)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/doveaia/agentdx/config"
 	"github.com/doveaia/agentdx/hooks"
+	"github.com/doveaia/agentdx/search"
 	"github.com/doveaia/agentdx/store"
 	"github.com/spf13/cobra"
 )
@@ -37,7 +38,7 @@ const (
 )
 
 type model struct {
-	st             *store.PostgresFTSStore
+	st             store.SearchStore
 	cfg            *config.Config
 	state          viewState
 	stats          *store.IndexStats
@@ -54,6 +55,7 @@ type model struct {
 	backendHealthy bool
 	hooksStatus    []hookStatus
 	detectedAgent  string
+	configMismatch *search.ConfigMismatch
 }
 
 // hookStatus represents the installation status of hooks for an agent
@@ -207,8 +209,15 @@ func (m model) viewStats() string {
 		sb.WriteString(fmt.Sprintf("%s\n", m.stats.LastUpdated.Format("2006-01-02 15:04:05")))
 	}
 
+	if m.configMismatch != nil {
+		sb.WriteString(normalStyle.Render("Chunking:         "))
+		sb.WriteString(statusErrStyle.Render("● Settings changed since indexing"))
+		sb.WriteString("\n")
+		sb.WriteString(dimStyle.Render("                  → Run: agentdx reindex --due-to-config\n"))
+	}
+
 	sb.WriteString(normalStyle.Render("Search:           "))
-	sb.WriteString("PostgreSQL FTS\n")
+	sb.WriteString(store.SearchName(m.backendType) + "\n")
 
 	// Show backend status for all backends
 	if m.backendType != "" {
@@ -381,10 +390,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Initialize PostgreSQL FTS store
-	st, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, projectRoot)
+	// Open index store
+	st, err := openStore(ctx, cfg, projectRoot)
 	if err != nil {
-		return fmt.Errorf("failed to connect to postgres: %w", err)
+		return fmt.Errorf("failed to open index store: %w", err)
 	}
 	defer st.Close()
 
@@ -415,6 +424,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		backendHealthy = status.Healthy
 	}
 
+	// Flag an index built with other chunking settings
+	configMismatch, err := search.CheckConfigFingerprint(ctx, cfg, st)
+	if err != nil {
+		return err
+	}
+
 	// Get hooks status and detected agent
 	cwd, _ := os.Getwd()
 	hooksStatus := getProjectHooksStatus(cwd)
@@ -433,6 +448,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		backendHealthy: backendHealthy,
 		hooksStatus:    hooksStatus,
 		detectedAgent:  detectedAgent,
+		configMismatch: configMismatch,
 	}
 
 	// Run TUI
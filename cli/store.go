@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+)
+
+// openStore opens the index store configured for the project.
+func openStore(ctx context.Context, cfg *config.Config, projectRoot string) (store.SearchStore, error) {
+	return openProfiledStore(ctx, cfg, store.OptionsFromConfig(cfg, projectRoot))
+}
+
+// openWorkspaceStore opens the index store scoped to a workspace's project ID.
+// An empty workspace name opens the root project.
+func openWorkspaceStore(ctx context.Context, cfg *config.Config, projectRoot, workspace string) (store.SearchStore, error) {
+	opts := store.OptionsFromConfig(cfg, projectRoot)
+	if workspace != "" {
+		ws, err := cfg.Workspace(workspace)
+		if err != nil {
+			return nil, err
+		}
+		opts.ProjectID = ws.ProjectID(opts.ProjectID)
+	}
+	return openProfiledStore(ctx, cfg, opts)
+}
+
+// openProfiledStore opens a store and applies the shared ranking profile
+// selected by index.search.profile to cfg.
+func openProfiledStore(ctx context.Context, cfg *config.Config, opts store.Options) (store.SearchStore, error) {
+	return search.OpenStore(ctx, &cfg.Index.Search, opts)
+}
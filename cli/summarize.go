@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/summary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	summarizeForce  bool
+	summarizeDryRun bool
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [dir...]",
+	Short: "Index a short summary of each directory",
+	Long: `Write a short summary of each directory with indexed files and index it
+as a chunk of type summary, under the directory's path with a trailing
+slash (billing/). Broad queries such as "where is billing handled" then
+match the directory as a whole; search --type summary searches summaries
+only.
+
+Summaries are assembled from the first paragraph of the directory's README
+and the doc comments of its files. With index.summaries.endpoint set to an
+OpenAI-compatible chat completions API (such as a local Ollama server), an
+LLM writes them instead; its API key, if any, is read from
+AGENTDX_SUMMARY_API_KEY.
+
+Directories whose files have not changed since their summary was written are
+skipped unless --force is given, and the summaries of directories without
+indexed files are removed. Summaries are not updated by 'agentdx watch' and
+are dropped by 'agentdx reindex': run 'agentdx summarize' again afterwards.`,
+	Example: `  agentdx summarize
+  agentdx summarize billing internal/api --force
+  agentdx summarize --dry-run
+  agentdx search "where is billing handled" --type summary`,
+	RunE: runSummarize,
+}
+
+func init() {
+	summarizeCmd.Flags().BoolVarP(&summarizeForce, "force", "f", false, "Summarize unchanged directories again")
+	summarizeCmd.Flags().BoolVar(&summarizeDryRun, "dry-run", false, "Print the summaries without indexing them")
+
+	rootCmd.AddCommand(summarizeCmd)
+}
+
+func runSummarize(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	st, err := openStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open index store: %w", err)
+	}
+	defer st.Close()
+
+	files, err := st.ListFilesWithStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	if len(files) == 0 {
+		return search.ErrNoIndex
+	}
+
+	only := make([]string, len(args))
+	for i, arg := range args {
+		if only[i], err = relativeToRoot(projectRoot, arg); err != nil {
+			return err
+		}
+	}
+	groups := summary.Group(files)
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		if len(only) == 0 || underAny(dir, only) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	summarizer := summary.New(cfg.Index.Summaries)
+	var written, unchanged, failed int
+	for _, dir := range dirs {
+		d := summary.ReadDir(projectRoot, dir, groups[dir])
+		hash := summarizer.Hash(d)
+		if !summarizeForce && !summarizeDryRun {
+			doc, err := st.GetDocument(ctx, store.SummaryPath(dir))
+			if err != nil {
+				return err
+			}
+			if doc != nil && doc.Hash == hash {
+				unchanged++
+				continue
+			}
+		}
+
+		text, err := summarizer.Summarize(ctx, d)
+		if err != nil {
+			// Keep the summary of the directory without the LLM
+			fmt.Printf("Warning: failed to summarize %s: %v\n", store.SummaryPath(dir), err)
+			text, hash = d.Describe(), ""
+			failed++
+		}
+		chunk, doc := summary.Chunk(d, text, hash, time.Now())
+		if summarizeDryRun {
+			fmt.Printf("%s\n%s\n", chunk.Content, strings.Repeat("-", 40))
+			continue
+		}
+		if err := saveSummary(ctx, st, chunk, doc); err != nil {
+			return err
+		}
+		written++
+		fmt.Printf("Summarized %s\n", chunk.FilePath)
+	}
+	if summarizeDryRun {
+		return nil
+	}
+
+	// Drop the summaries of directories without indexed files
+	removed := 0
+	if len(only) == 0 {
+		paths, err := st.ListSummaries(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			if _, ok := groups[path.Clean(strings.TrimSuffix(p, "/"))]; ok {
+				continue
+			}
+			if err := removeSummary(ctx, st, p); err != nil {
+				return err
+			}
+			removed++
+		}
+	}
+
+	fmt.Printf("Summaries: %d written, %d unchanged, %d removed\n", written, unchanged, removed)
+	if failed > 0 {
+		fmt.Printf("%d summaries were written without the LLM; run 'agentdx summarize' again to retry them\n", failed)
+	}
+	return nil
+}
+
+// underAny reports whether dir is one of dirs or under one of them.
+func underAny(dir string, dirs []string) bool {
+	for _, d := range dirs {
+		if d == "." || dir == d || strings.HasPrefix(dir, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// saveSummary replaces the summary chunk of a directory.
+func saveSummary(ctx context.Context, st store.SearchStore, chunk store.Chunk, doc store.Document) error {
+	if err := st.DeleteByFile(ctx, doc.Path); err != nil {
+		return err
+	}
+	if err := st.SaveChunks(ctx, []store.Chunk{chunk}); err != nil {
+		return err
+	}
+	return st.SaveDocument(ctx, doc)
+}
+
+// removeSummary removes the summary chunk of a directory.
+func removeSummary(ctx context.Context, st store.SearchStore, path string) error {
+	if err := st.DeleteByFile(ctx, path); err != nil {
+		return err
+	}
+	return st.DeleteDocument(ctx, path)
+}
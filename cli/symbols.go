@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	symbolsKind  string
+	symbolsFile  string
+	symbolsLimit int
+	symbolsJSON  bool
+)
+
+var symbolsCmd = &cobra.Command{
+	Use:   "symbols <subcommand>",
+	Short: "List and look up symbols of the call graph index",
+	Long: `List and look up the symbol definitions extracted for 'agentdx trace', to
+resolve ambiguous names before tracing them.
+
+--kind selects function (func), method, class, interface, type, variable
+(var) or constant (const) symbols. --file takes a path, a directory, a Go
+style tree such as pkg/... or a glob as in 'agentdx files'.
+
+Examples:
+  agentdx symbols list --kind func --file internal/api/...
+  agentdx symbols find HandleRequest --json`,
+}
+
+var symbolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List symbols, by file and line",
+	Args:  cobra.NoArgs,
+	RunE:  runSymbolsList,
+}
+
+var symbolsFindCmd = &cobra.Command{
+	Use:   "find <name>",
+	Short: "Find the definitions of a symbol",
+	Long: `Find the definitions of a symbol. Symbols named exactly <name> are
+returned; when there are none, symbols whose name contains it, ignoring case.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSymbolsFind,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{symbolsListCmd, symbolsFindCmd} {
+		cmd.Flags().StringVar(&symbolsKind, "kind", "", "Only symbols of this kind")
+		cmd.Flags().StringVar(&symbolsFile, "file", "", "Only symbols in files matching this path, dir/... tree or glob")
+		cmd.Flags().IntVarP(&symbolsLimit, "limit", "n", 100, "Maximum number of symbols (0 for no limit)")
+		cmd.Flags().BoolVar(&symbolsJSON, "json", false, "Output results in JSON format")
+	}
+
+	symbolsCmd.AddCommand(symbolsListCmd)
+	symbolsCmd.AddCommand(symbolsFindCmd)
+	rootCmd.AddCommand(symbolsCmd)
+}
+
+func runSymbolsList(_ *cobra.Command, _ []string) error {
+	return runSymbols(func(ctx context.Context, st trace.SymbolStore) ([]trace.Symbol, error) {
+		return st.FindSymbols(ctx, "", 0)
+	})
+}
+
+func runSymbolsFind(_ *cobra.Command, args []string) error {
+	name := args[0]
+	return runSymbols(func(ctx context.Context, st trace.SymbolStore) ([]trace.Symbol, error) {
+		symbols, err := st.LookupSymbol(ctx, name)
+		if err != nil || len(symbols) > 0 {
+			return symbols, err
+		}
+		return st.FindSymbols(ctx, name, 0)
+	})
+}
+
+// runSymbols loads the symbol index, filters the symbols returned by lookup
+// with the --kind and --file flags and prints them.
+func runSymbols(lookup func(ctx context.Context, st trace.SymbolStore) ([]trace.Symbol, error)) error {
+	ctx := context.Background()
+
+	var kind trace.SymbolKind
+	if symbolsKind != "" {
+		var err error
+		if kind, err = trace.ParseKind(symbolsKind); err != nil {
+			return err
+		}
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load symbol index: %w", err)
+	}
+	defer symbolStore.Close()
+
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		return errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")
+	}
+
+	symbols, err := lookup(ctx, symbolStore)
+	if err != nil {
+		return fmt.Errorf("failed to lookup symbols: %w", err)
+	}
+	symbols, err = filterSymbols(symbols, kind, symbolsFile)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].File != symbols[j].File {
+			return symbols[i].File < symbols[j].File
+		}
+		return symbols[i].Line < symbols[j].Line
+	})
+	total := len(symbols)
+	if symbolsLimit > 0 && total > symbolsLimit {
+		symbols = symbols[:symbolsLimit]
+	}
+	if total == 0 {
+		warnSymbolDrift(ctx, cfg, projectRoot, symbolStore)
+	}
+
+	for i := range symbols {
+		symbols[i].File = paths.Display(symbols[i].File)
+	}
+	if symbolsJSON {
+		if symbols == nil {
+			symbols = []trace.Symbol{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(symbols); err != nil {
+			return err
+		}
+		if total == 0 {
+			return errcode.ErrNoResults
+		}
+		return nil
+	}
+
+	if total == 0 {
+		fmt.Println("No symbols found.")
+		return errcode.ErrNoResults
+	}
+	if total > len(symbols) {
+		fmt.Printf("Found %d symbols (showing %d, use --limit to see more):\n\n", total, len(symbols))
+	} else {
+		fmt.Printf("Found %d symbols:\n\n", total)
+	}
+	for _, sym := range symbols {
+		name := sym.Name
+		if sym.Receiver != "" {
+			name = sym.Receiver + "." + sym.Name
+		}
+		fmt.Printf("%-10s %s  %s:%d\n", sym.Kind, name, sym.File, sym.Line)
+		if sym.Signature != "" {
+			fmt.Printf("           %s\n", truncate(sym.Signature, 100))
+		}
+	}
+	return nil
+}
+
+// filterSymbols keeps the symbols of kind, when set, whose file matches
+// pattern, when set.
+func filterSymbols(symbols []trace.Symbol, kind trace.SymbolKind, pattern string) ([]trace.Symbol, error) {
+	var kept []trace.Symbol
+	for _, sym := range symbols {
+		if kind != "" && sym.Kind != kind {
+			continue
+		}
+		if pattern != "" {
+			ok, err := matchSymbolFile(pattern, sym.File)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		kept = append(kept, sym)
+	}
+	return kept, nil
+}
+
+// matchSymbolFile reports whether file matches pattern: a Go style tree
+// (dir/...), a glob, or else a file or directory path.
+func matchSymbolFile(pattern, file string) (bool, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if dir, ok := strings.CutSuffix(pattern, "..."); ok {
+		dir = strings.TrimSuffix(dir, "/")
+		return dir == "" || dir == "." || strings.HasPrefix(file, dir+"/"), nil
+	}
+	if strings.ContainsAny(pattern, "*?[{") {
+		ok, err := doublestar.Match(normalizeGlobPattern(pattern), file)
+		if err != nil {
+			return false, fmt.Errorf("invalid glob pattern: %w", err)
+		}
+		return ok, nil
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+	return file == pattern || strings.HasPrefix(file, pattern+"/"), nil
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSymbolFile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"pkg/...", "pkg/api/server.go", true},
+		{"pkg/...", "pkg/main.go", true},
+		{"pkg/...", "pkgs/main.go", false},
+		{"./...", "main.go", true},
+		{"pkg", "pkg/api/server.go", true},
+		{"pkg/", "pkg/api/server.go", true},
+		{"pkg/api/server.go", "pkg/api/server.go", true},
+		{"pkg/api/server.go", "pkg/api/server_test.go", false},
+		{"**/*_test.go", "pkg/api/server_test.go", true},
+		{"*.go", "pkg/api/server.go", true},
+		{"pkg/*.go", "pkg/api/server.go", false},
+	}
+	for _, tt := range tests {
+		got, err := matchSymbolFile(tt.pattern, tt.file)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s matching %s", tt.pattern, tt.file)
+	}
+}
+
+func TestFilterSymbols(t *testing.T) {
+	symbols := []trace.Symbol{
+		{Name: "HandleRequest", Kind: trace.KindFunction, File: "pkg/api/server.go"},
+		{Name: "Server", Kind: trace.KindType, File: "pkg/api/server.go"},
+		{Name: "HandleRequest", Kind: trace.KindMethod, File: "internal/proxy/proxy.go"},
+	}
+
+	kind, err := trace.ParseKind("func")
+	require.NoError(t, err)
+	got, err := filterSymbols(symbols, kind, "")
+	require.NoError(t, err)
+	assert.Equal(t, symbols[:1], got)
+
+	got, err = filterSymbols(symbols, "", "internal/...")
+	require.NoError(t, err)
+	assert.Equal(t, symbols[2:], got)
+
+	got, err = filterSymbols(symbols, trace.KindMethod, "pkg/...")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = trace.ParseKind("struct")
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateFS holds the default instruction, skill, subagent and hook
+// templates.
+//
+//go:embed templates/agents/* templates/fulltext_*.md templates/agentdx-fallback.sh
+var templateFS embed.FS
+
+// templateOverrideDir is the directory, relative to the project root, whose
+// files replace the default templates of the same name, e.g.
+// .agentdx/templates/agents/CLAUDE.md or
+// .agentdx/templates/fulltext_instructions.md.
+const templateOverrideDir = ".agentdx/templates"
+
+// readTemplate returns the template name, a path such as "agents/CLAUDE.md",
+// from the override directory of the project at root when it holds it, and
+// the default template otherwise. An empty root reads the default.
+func readTemplate(root, name string) (string, error) {
+	if root != "" {
+		data, err := os.ReadFile(filepath.Join(root, templateOverrideDir, filepath.FromSlash(name)))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template override %s: %w", name, err)
+		}
+	}
+	data, err := templateFS.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("template not found: %w", err)
+	}
+	return string(data), nil
+}
+
+// Markers around the sections agentdx writes into files shared with the
+// user's own instructions. The begin marker names the template of the
+// section, so that 'agentdx setup --refresh' can regenerate it and
+// removal can strip it even once the template changed.
+const (
+	sectionBeginPrefix = "<!-- agentdx:begin "
+	sectionBeginSuffix = " -->"
+	sectionEnd         = "<!-- agentdx:end -->"
+)
+
+// markSection returns content of the template name between section markers.
+func markSection(name, content string) string {
+	return sectionBeginPrefix + name + sectionBeginSuffix + "\n" + strings.Trim(content, "\n") + "\n" + sectionEnd + "\n"
+}
+
+// nextSection finds the first marked section of content at or after offset
+// from. It returns the bounds of the section, its line break included, and
+// the name of its template; ok is false when there is none.
+func nextSection(content string, from int) (start, end int, name string, ok bool) {
+	i := strings.Index(content[from:], sectionBeginPrefix)
+	if i < 0 {
+		return 0, 0, "", false
+	}
+	start = from + i
+	line := content[start:]
+	if nl := strings.IndexByte(line, '\n'); nl >= 0 {
+		line = line[:nl]
+	}
+	if !strings.HasSuffix(line, sectionBeginSuffix) {
+		return 0, 0, "", false
+	}
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, sectionBeginPrefix), sectionBeginSuffix))
+	j := strings.Index(content[start:], sectionEnd)
+	if j < 0 {
+		// An unterminated section is left to the user
+		return 0, 0, "", false
+	}
+	end = start + j + len(sectionEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, name, true
+}
+
+// hasMarkedSection reports whether content holds a section written by agentdx
+// between markers.
+func hasMarkedSection(content string) bool {
+	_, _, _, ok := nextSection(content, 0)
+	return ok
+}
+
+// refreshSections replaces the agentdx sections of content with the current
+// version of their templates, read with the overrides of the project at root:
+// the marked sections or, in files without any, unmarked copies of the
+// default templates legacy, written before sections were marked, which get
+// markers. Edits outside the sections are kept. It returns the new content
+// and the number of sections replaced.
+func refreshSections(root, content string, legacy []string) (string, int, error) {
+	var b strings.Builder
+	refreshed := 0
+	pos := 0
+	for {
+		start, end, name, ok := nextSection(content, pos)
+		if !ok {
+			break
+		}
+		template, err := readTemplate(root, name)
+		if err != nil {
+			return content, 0, err
+		}
+		b.WriteString(content[pos:start])
+		b.WriteString(markSection(name, template))
+		pos = end
+		refreshed++
+	}
+	b.WriteString(content[pos:])
+	content = b.String()
+
+	if refreshed > 0 {
+		// Files with marked sections were written after legacy ones
+		return content, refreshed, nil
+	}
+	for _, name := range legacy {
+		old, err := templateFS.ReadFile("templates/" + name)
+		if err != nil {
+			return content, 0, fmt.Errorf("template not found: %w", err)
+		}
+		section := strings.Trim(string(old), "\n")
+		if section == "" || !strings.Contains(content, section) {
+			continue
+		}
+		template, err := readTemplate(root, name)
+		if err != nil {
+			return content, 0, err
+		}
+		content = strings.Replace(content, section, strings.TrimSuffix(markSection(name, template), "\n"), 1)
+		refreshed++
+	}
+	return content, refreshed, nil
+}
+
+// stripMarkedSections removes the marked sections of content along with the
+// blank line that separated each from the user's content.
+func stripMarkedSections(content string) string {
+	for {
+		start, end, _, ok := nextSection(content, 0)
+		if !ok {
+			return content
+		}
+		content = stripSection(content, content[start:end])
+	}
+}
@@ -1,6 +1,7 @@
 #!/bin/bash
 # PostToolUse hook for Bash tool
-# Detects when agentdx returns empty results and instructs Claude to spawn Explore agent
+# Detects when agentdx returns empty results and instructs Claude to spawn Explore agent,
+# or to fall back to Grep when agentdx fails with an error code
 
 # Read the hook input from stdin
 INPUT=$(cat)
@@ -13,10 +14,28 @@ OUTPUT=$(echo "$INPUT" | jq -r '.tool_output.stdout // empty')
 
 # Check if this was an agentdx search command
 if echo "$COMMAND" | grep -qE '^agentdx (search|files)'; then
+  # Extract the search query from the command
+  QUERY=$(echo "$COMMAND" | sed -E 's/agentdx (search|files) "?([^"]*)"?.*/\2/')
+  TOOL=$(echo "$COMMAND" | awk '{print "agentdx " $2}')
+
+  # agentdx could not search: no index yet, backend down or not a project
+  CODE=$(echo "$OUTPUT" | jq -r '.code? // empty' 2>/dev/null)
+  case "$CODE" in
+    E_NO_INDEX|E_BACKEND_DOWN|E_NO_PROJECT|E_CONFIG)
+      agentdx hook report-fallback --tool "$TOOL" --query "$QUERY" --reason "$CODE" </dev/null >/dev/null 2>&1
+      cat << EOF
+{
+  "decision": "block",
+  "reason": "agentdx is unavailable ($CODE). Do not retry it; use Grep and Glob for this search instead."
+}
+EOF
+      exit 0
+      ;;
+  esac
+
   # Check if output is empty array [] (with optional whitespace)
   if echo "$OUTPUT" | grep -qE '^\s*\[\s*\]\s*$'; then
-    # Extract the search query from the command
-    QUERY=$(echo "$COMMAND" | sed -E 's/agentdx (search|files) "?([^"]*)"?.*/\2/')
+    agentdx hook report-fallback --tool "$TOOL" --query "$QUERY" --reason empty </dev/null >/dev/null 2>&1
 
     # Return JSON instructing Claude to spawn Explore agent
     cat << EOF
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplateOverride(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, templateOverrideDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTemplate_Override(t *testing.T) {
+	root := t.TempDir()
+	embedded := defaultTemplate(t, "agents/CLAUDE.md")
+
+	got, err := readTemplate(root, "agents/CLAUDE.md")
+	if err != nil || got != embedded {
+		t.Fatalf("expected the default template without override, got %q, %v", got, err)
+	}
+
+	writeTemplateOverride(t, root, "agents/CLAUDE.md", "Use agentdx search with our query syntax.\n")
+	got, err = readTemplate(root, "agents/CLAUDE.md")
+	if err != nil || got != "Use agentdx search with our query syntax.\n" {
+		t.Errorf("expected the override, got %q, %v", got, err)
+	}
+
+	if _, err := readTemplate(root, "agents/missing.md"); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
+
+func TestRefreshSections(t *testing.T) {
+	root := t.TempDir()
+	user := "# Project\n\nOur own notes.\n"
+	content := user + "\n" + markSection(setupInstructionsTemplate, "Old instructions") + "\nMore notes.\n"
+
+	writeTemplateOverride(t, root, setupInstructionsTemplate, "New instructions\n")
+	got, n, err := refreshSections(root, content, nil)
+	if err != nil || n != 1 {
+		t.Fatalf("refreshSections = %d, %v, want 1 section", n, err)
+	}
+	want := user + "\n" + markSection(setupInstructionsTemplate, "New instructions") + "\nMore notes.\n"
+	if got != want {
+		t.Errorf("expected the section to be replaced and the notes kept, got:\n%s", got)
+	}
+
+	// Unmarked instructions of older versions get markers
+	legacy := user + "\n" + defaultTemplate(t, setupInstructionsTemplate)
+	got, n, err = refreshSections(root, legacy, []string{setupInstructionsTemplate})
+	if err != nil || n != 1 {
+		t.Fatalf("refreshSections = %d, %v, want 1 legacy section", n, err)
+	}
+	if !strings.HasPrefix(got, user) || !strings.Contains(got, markSection(setupInstructionsTemplate, "New instructions")) {
+		t.Errorf("expected the legacy instructions to be replaced, got:\n%s", got)
+	}
+
+	// Edited instructions without markers are left alone
+	edited := user + "\n## agentdx - PostgreSQL Full-Text Search\n\nOur edits.\n"
+	if got, n, err := refreshSections(root, edited, []string{setupInstructionsTemplate}); err != nil || n != 0 || got != edited {
+		t.Errorf("expected edited instructions to be kept, got %d, %v:\n%s", n, err, got)
+	}
+}
+
+func TestStripMarkedSections(t *testing.T) {
+	user := "# Notes\n\nOur own instructions.\n"
+	section := markSection("agents/AGENTS.md", "Use agentdx.")
+	for name, content := range map[string]string{
+		"appended":  user + "\n" + section,
+		"prepended": section + "\n" + user,
+	} {
+		if got := stripMarkedSections(content); got != user {
+			t.Errorf("%s: expected the user's content, got %q", name, got)
+		}
+	}
+}
+
+func TestGenerateAgentConfigsFor_Overrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTemplateOverride(t, tmpDir, "agents/GEMINI.md", "Search with agentdx, our way.\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "GEMINI.md"), []byte("# Gemini\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, _ := ResolveAgentConfigs([]string{"gemini"})
+	if err := GenerateAgentConfigsFor(tmpDir, agents); err != nil {
+		t.Fatalf("failed to generate agent configs: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "GEMINI.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := markSection("agents/GEMINI.md", "Search with agentdx, our way.") + "\n# Gemini\n"
+	if string(data) != want {
+		t.Errorf("expected the marked override before the user's content, got:\n%s", data)
+	}
+
+	// A refresh picks up the edited override and keeps the user's edits
+	writeTemplateOverride(t, tmpDir, "agents/GEMINI.md", "Search with agentdx, version 2.\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "GEMINI.md"), append(data, "More notes.\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := refreshInstructions(tmpDir, tmpDir, "GEMINI.md"); err != nil || !ok {
+		t.Fatalf("refreshInstructions = %v, %v", ok, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "GEMINI.md"))
+	want = markSection("agents/GEMINI.md", "Search with agentdx, version 2.") + "\n# Gemini\nMore notes.\n"
+	if string(data) != want {
+		t.Errorf("expected the refreshed section and the user's notes, got:\n%s", data)
+	}
+}
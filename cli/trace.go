@@ -6,16 +6,28 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/session"
+	"github.com/doveaia/agentdx/store"
 	"github.com/doveaia/agentdx/trace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	traceMode  string
-	traceDepth int
-	traceJSON  bool
+	traceMode       string
+	traceDepth      int
+	traceJSON       bool
+	traceFormat     string
+	traceExhaustive bool
+	traceRebuild    bool
+	traceLevels     int
+	traceRecursive  bool
+	traceFlat       bool
 )
 
 var traceCmd = &cobra.Command{
@@ -25,11 +37,23 @@ var traceCmd = &cobra.Command{
 - callers: functions that call the specified symbol
 - callees: functions that the specified symbol calls
 - graph: full call graph visualization
+- implementations: Go types implementing an interface or interface method
+
+The symbol index is updated by 'agentdx watch' alongside the search index.
+When trace warns that it is out of date, --rebuild extracts the symbols of
+every file again without re-chunking. Stop a running watch session first
+unless trace.store is postgres.
 
 Examples:
   agentdx trace callers "Login"
   agentdx trace callees "HandleRequest" --mode precise
-  agentdx trace graph "ProcessOrder" --depth 3 --json`,
+  agentdx trace callees "HandleRequest" --recursive --flat
+  agentdx trace graph "ProcessOrder" --depth 3 --json
+  agentdx trace implementations "SymbolStore"
+  agentdx trace callers "Login" --format sarif > callers.sarif
+  agentdx trace --rebuild`,
+	Args: cobra.NoArgs,
+	RunE: runTraceRebuild,
 }
 
 var traceCallersCmd = &cobra.Command{
@@ -37,10 +61,22 @@ var traceCallersCmd = &cobra.Command{
 	Short: "Find all functions that call the specified symbol",
 	Long: `Find all functions that call the specified symbol.
 
+With --exhaustive, callers are grouped per definition when the name is
+defined several times (e.g. same-named functions in different packages).
+Call sites that cannot be attributed to a single definition are listed as
+unresolved. Precise mode resolves calls through imports.
+
+--depth N follows callers N levels up: the callers of the callers, and so
+on. --recursive follows them until every path ends. Each symbol is followed
+once; a call closing a cycle is marked as such and not followed. --flat
+lists each symbol once, at its shallowest level, instead of every call.
+
 Examples:
   agentdx trace callers "Login"
   agentdx trace callers "HandleRequest" --json
-  agentdx trace callers "ProcessOrder" --mode precise`,
+  agentdx trace callers "ProcessOrder" --mode precise
+  agentdx trace callers "New" --exhaustive --json
+  agentdx trace callers "Charge" --depth 3 --flat --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTraceCallers,
 }
@@ -50,9 +86,16 @@ var traceCalleesCmd = &cobra.Command{
 	Short: "Find all functions called by the specified symbol",
 	Long: `Find all functions called by the specified symbol.
 
+--depth N follows callees N levels down: the callees of the callees, and so
+on. --recursive follows them until every path ends. Each symbol is followed
+once; a call closing a cycle is marked as such and not followed, and
+functions defined outside the project are not followed. --flat lists each
+symbol once, at its shallowest level, instead of every call.
+
 Examples:
   agentdx trace callees "Login"
-  agentdx trace callees "HandleRequest" --json`,
+  agentdx trace callees "HandleRequest" --json
+  agentdx trace callees "HandleRequest" --recursive --flat --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTraceCallees,
 }
@@ -69,33 +112,164 @@ Examples:
 	RunE: runTraceGraph,
 }
 
+var traceImplementationsCmd = &cobra.Command{
+	Use:   "implementations <interface|method>",
+	Short: "Find the Go types implementing an interface or interface method",
+	Long: `Find the Go types implementing an interface, or the methods implementing
+an interface method. Callers of an interface method reach these methods
+through dynamic dispatch, which 'trace callers' cannot follow.
+
+A type implements an interface when it has a method of every name the
+interface declares; signatures are not compared. Methods of interfaces
+embedded from another file or package are not resolved.
+
+Examples:
+  agentdx trace implementations "SymbolStore"
+  agentdx trace implementations "LookupSymbol"
+  agentdx trace implementations "SymbolStore.Close" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTraceImplementations,
+}
+
 func init() {
 	// Add flags to all trace subcommands
-	for _, cmd := range []*cobra.Command{traceCallersCmd, traceCalleesCmd, traceGraphCmd} {
+	for _, cmd := range []*cobra.Command{traceCallersCmd, traceCalleesCmd, traceGraphCmd, traceImplementationsCmd} {
 		cmd.Flags().StringVarP(&traceMode, "mode", "m", "fast", "Extraction mode: fast (regex) or precise (tree-sitter)")
 		cmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+		cmd.Flags().StringVar(&traceFormat, "format", formatText, "Output format: text, md (Markdown with code blocks) or sarif")
 	}
 	traceGraphCmd.Flags().IntVarP(&traceDepth, "depth", "d", 2, "Maximum depth for graph traversal")
+	traceCallersCmd.Flags().BoolVar(&traceExhaustive, "exhaustive", false, "Group callers per definition of the symbol")
+	for _, cmd := range []*cobra.Command{traceCallersCmd, traceCalleesCmd} {
+		cmd.Flags().IntVarP(&traceLevels, "depth", "d", 1, "Levels of calls to follow")
+		cmd.Flags().BoolVarP(&traceRecursive, "recursive", "r", false, "Follow calls until every path ends or cycles")
+		cmd.Flags().BoolVar(&traceFlat, "flat", false, "List each symbol once, at its shallowest level")
+	}
+	traceCmd.Flags().BoolVar(&traceRebuild, "rebuild", false, "Rebuild the symbol index from the files on disk")
 
 	traceCmd.AddCommand(traceCallersCmd)
 	traceCmd.AddCommand(traceCalleesCmd)
 	traceCmd.AddCommand(traceGraphCmd)
+	traceCmd.AddCommand(traceImplementationsCmd)
 
 	rootCmd.AddCommand(traceCmd)
 }
 
+func runTraceRebuild(cmd *cobra.Command, _ []string) error {
+	if !traceRebuild {
+		return cmd.Help()
+	}
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// A running watch holds its own copy of a local symbol index and would
+	// overwrite the rebuilt one
+	if cfg.Index.Trace.Store != store.SymbolBackendPostgres {
+		if running, _ := session.NewDaemonManager(projectRoot).IsRunning(); running {
+			return fmt.Errorf("agentdx watch is running and would overwrite the rebuilt symbol index; stop it with 'agentdx session stop' first")
+		}
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+
+	extractor, err := newSymbolExtractor(cfg.Index.Trace.Mode)
+	if err != nil {
+		return err
+	}
+
+	symbolStore, err := store.OpenSymbolStore(ctx, cfg.Index.Trace.Store, cfg.GetSymbolStorePath(projectRoot), store.OptionsFromConfig(cfg, projectRoot))
+	if err != nil {
+		return fmt.Errorf("failed to open symbol store: %w", err)
+	}
+	defer symbolStore.Close()
+	// An unreadable index is replaced anyway
+	_ = symbolStore.Load(ctx)
+
+	start := time.Now()
+	stats, err := trace.RebuildIndex(ctx, symbolStore, extractor, tracedSources(scanner, cfg.Index.Trace.Languages()), 0)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild symbol index: %w", err)
+	}
+	if err := symbolStore.Persist(ctx); err != nil {
+		return fmt.Errorf("failed to persist symbol index: %w", err)
+	}
+	fmt.Printf("Rebuilt symbol index: %d files extracted (%d symbols), %d failed (took %s)\n",
+		stats.Extracted, stats.Symbols, stats.Failed, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// warnSymbolDrift prints a warning to stderr when the symbol index lags the
+// search index of the project and its workspaces. The check is best effort.
+func warnSymbolDrift(ctx context.Context, cfg *config.Config, projectRoot string, symbolStore trace.SymbolStore) {
+	workspaces := []string{""}
+	for _, ws := range cfg.Workspaces {
+		workspaces = append(workspaces, ws.Name)
+	}
+	var files []store.FileStats
+	for _, workspace := range workspaces {
+		st, err := openWorkspaceStore(ctx, cfg, projectRoot, workspace)
+		if err != nil {
+			return
+		}
+		stats, err := st.ListFilesWithStats(ctx)
+		st.Close()
+		if err != nil {
+			return
+		}
+		files = append(files, stats...)
+	}
+
+	drift, err := search.CheckSymbolDrift(ctx, files, symbolStore, cfg.Index.Trace.Languages())
+	if err == nil && drift.Drifted() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", drift.Warning())
+	}
+}
+
 func runTraceCallers(cmd *cobra.Command, args []string) error {
 	symbolName := args[0]
 	ctx := context.Background()
+	if err := validateFormat(traceFormat, traceJSON, formatMarkdown, formatSARIF); err != nil {
+		return err
+	}
+	depth, err := traceLevelsFlag()
+	if err != nil {
+		return err
+	}
+	if depth != 1 && traceExhaustive {
+		return errcode.New(errcode.InvalidArgs, "--exhaustive cannot be combined with --depth or --recursive")
+	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
 
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	start := time.Now()
+
 	// Initialize symbol store
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
 		return fmt.Errorf("failed to load symbol index: %w", err)
 	}
 	defer symbolStore.Close()
@@ -103,72 +277,67 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return fmt.Errorf("symbol index is empty. Run 'agentdx watch' first to build the index")
+		return errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")
 	}
 
-	// Lookup symbol
-	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
+	traced, err := trace.Callers(ctx, symbolStore, symbolName, traceExhaustive)
 	if err != nil {
-		return fmt.Errorf("failed to lookup symbol: %w", err)
+		return err
 	}
-
-	if len(symbols) == 0 {
-		if traceJSON {
-			return outputJSON(trace.TraceResult{Query: symbolName, Mode: traceMode})
-		}
-		fmt.Printf("No symbol found: %s\n", symbolName)
-		return nil
+	if err := trace.ExpandCallers(ctx, symbolStore, traced, depth); err != nil {
+		return err
 	}
-
-	// Find callers
-	refs, err := symbolStore.LookupCallers(ctx, symbolName)
-	if err != nil {
-		return fmt.Errorf("failed to lookup callers: %w", err)
+	if traceFlat {
+		traced.Flatten()
 	}
+	result := *traced
+	result.Mode = traceMode
 
-	result := trace.TraceResult{
-		Query:  symbolName,
-		Mode:   traceMode,
-		Symbol: &symbols[0],
+	recordQuery(ctx, cfg, projectRoot, store.QueryKindTrace, symbolName, time.Since(start), result.Size())
+	warnSymbolDrift(ctx, cfg, projectRoot, symbolStore)
+	if result.Symbol == nil {
+		return symbolNotFound(projectRoot, result, paths)
 	}
 
-	// Convert refs to CallerInfo
-	for _, ref := range refs {
-		callerSyms, _ := symbolStore.LookupSymbol(ctx, ref.CallerName)
-		var callerSym trace.Symbol
-		if len(callerSyms) > 0 {
-			callerSym = callerSyms[0]
-		} else {
-			callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
-		}
-		result.Callers = append(result.Callers, trace.CallerInfo{
-			Symbol: callerSym,
-			CallSite: trace.CallSite{
-				File:    ref.File,
-				Line:    ref.Line,
-				Context: ref.Context,
-			},
-		})
+	if done, err := outputTraceFormat(projectRoot, &result, paths); done {
+		return err
 	}
 
-	if traceJSON {
-		return outputJSON(result)
+	if traceExhaustive {
+		return displayDefinitionsResult(result)
 	}
-
 	return displayCallersResult(result)
 }
 
 func runTraceCallees(cmd *cobra.Command, args []string) error {
 	symbolName := args[0]
 	ctx := context.Background()
+	if err := validateFormat(traceFormat, traceJSON, formatMarkdown, formatSARIF); err != nil {
+		return err
+	}
+	depth, err := traceLevelsFlag()
+	if err != nil {
+		return err
+	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
 
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	start := time.Now()
+
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
 		return fmt.Errorf("failed to load symbol index: %w", err)
 	}
 	defer symbolStore.Close()
@@ -176,71 +345,123 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return fmt.Errorf("symbol index is empty. Run 'agentdx watch' first to build the index")
+		return errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")
 	}
 
-	// Lookup symbol
-	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
+	traced, err := trace.Callees(ctx, symbolStore, symbolName)
 	if err != nil {
-		return fmt.Errorf("failed to lookup symbol: %w", err)
+		return err
+	}
+	if err := trace.ExpandCallees(ctx, symbolStore, traced, depth); err != nil {
+		return err
 	}
+	if traceFlat {
+		traced.Flatten()
+	}
+	result := *traced
+	result.Mode = traceMode
 
-	if len(symbols) == 0 {
-		if traceJSON {
-			return outputJSON(trace.TraceResult{Query: symbolName, Mode: traceMode})
-		}
-		fmt.Printf("No symbol found: %s\n", symbolName)
-		return nil
+	recordQuery(ctx, cfg, projectRoot, store.QueryKindTrace, symbolName, time.Since(start), result.Size())
+	warnSymbolDrift(ctx, cfg, projectRoot, symbolStore)
+	if result.Symbol == nil {
+		return symbolNotFound(projectRoot, result, paths)
 	}
 
-	// Find callees
-	refs, err := symbolStore.LookupCallees(ctx, symbolName, symbols[0].File)
+	if done, err := outputTraceFormat(projectRoot, &result, paths); done {
+		return err
+	}
+
+	return displayCalleesResult(result)
+}
+
+// traceLevelsFlag returns the levels of calls that trace callers and callees
+// follow, 0 for no limit.
+func traceLevelsFlag() (int, error) {
+	if traceRecursive {
+		return 0, nil
+	}
+	if traceLevels < 1 {
+		return 0, errcode.New(errcode.InvalidArgs, "--depth must be at least 1, got %d", traceLevels)
+	}
+	return traceLevels, nil
+}
+
+func runTraceGraph(cmd *cobra.Command, args []string) error {
+	symbolName := args[0]
+	ctx := context.Background()
+	if err := validateFormat(traceFormat, traceJSON, formatMarkdown, formatSARIF); err != nil {
+		return err
+	}
+
+	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
-		return fmt.Errorf("failed to lookup callees: %w", err)
+		return err
 	}
 
-	result := trace.TraceResult{
-		Query:  symbolName,
-		Mode:   traceMode,
-		Symbol: &symbols[0],
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
 	}
 
-	for _, ref := range refs {
-		calleeSyms, _ := symbolStore.LookupSymbol(ctx, ref.SymbolName)
-		var calleeSym trace.Symbol
-		if len(calleeSyms) > 0 {
-			calleeSym = calleeSyms[0]
-		} else {
-			calleeSym = trace.Symbol{Name: ref.SymbolName}
-		}
-		result.Callees = append(result.Callees, trace.CalleeInfo{
-			Symbol: calleeSym,
-			CallSite: trace.CallSite{
-				File:    ref.File,
-				Line:    ref.Line,
-				Context: ref.Context,
-			},
-		})
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	start := time.Now()
 
-	if traceJSON {
-		return outputJSON(result)
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load symbol index: %w", err)
 	}
+	defer symbolStore.Close()
 
-	return displayCalleesResult(result)
+	// Check if index exists
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		return errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")
+	}
+
+	traced, err := trace.Graph(ctx, symbolStore, symbolName, traceDepth)
+	if err != nil {
+		return err
+	}
+	result := *traced
+	result.Mode = traceMode
+
+	recordQuery(ctx, cfg, projectRoot, store.QueryKindTrace, symbolName, time.Since(start), result.Size())
+	warnSymbolDrift(ctx, cfg, projectRoot, symbolStore)
+	if done, err := outputTraceFormat(projectRoot, &result, paths); done {
+		return err
+	}
+
+	return displayGraphResult(result)
 }
 
-func runTraceGraph(cmd *cobra.Command, args []string) error {
-	symbolName := args[0]
+func runTraceImplementations(cmd *cobra.Command, args []string) error {
+	query := args[0]
 	ctx := context.Background()
+	if err := validateFormat(traceFormat, traceJSON, formatMarkdown, formatSARIF); err != nil {
+		return err
+	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
 
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
+	paths, err := outputPaths(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	start := time.Now()
+
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, cfg, projectRoot)
+	if err != nil {
 		return fmt.Errorf("failed to load symbol index: %w", err)
 	}
 	defer symbolStore.Close()
@@ -248,25 +469,100 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return fmt.Errorf("symbol index is empty. Run 'agentdx watch' first to build the index")
+		return errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")
 	}
 
-	graph, err := symbolStore.GetCallGraph(ctx, symbolName, traceDepth)
+	traced, err := trace.Implementations(ctx, symbolStore, query)
 	if err != nil {
-		return fmt.Errorf("failed to build call graph: %w", err)
+		return err
 	}
+	result := *traced
+	result.Mode = traceMode
 
-	result := trace.TraceResult{
-		Query: symbolName,
-		Mode:  traceMode,
-		Graph: graph,
+	recordQuery(ctx, cfg, projectRoot, store.QueryKindTrace, query, time.Since(start), result.Size())
+	warnSymbolDrift(ctx, cfg, projectRoot, symbolStore)
+	if result.Symbol == nil {
+		return symbolNotFound(projectRoot, result, paths)
 	}
 
-	if traceJSON {
-		return outputJSON(result)
+	if done, err := outputTraceFormat(projectRoot, &result, paths); done {
+		return err
 	}
 
-	return displayGraphResult(result)
+	return displayImplementationsResult(result)
+}
+
+// outputTraceFormat prints result in the --json or --format format, with
+// paths in the --path-style style except for SARIF, and reports whether it
+// did. Text output is left to each subcommand.
+func outputTraceFormat(projectRoot string, result *trace.TraceResult, paths *search.Paths) (bool, error) {
+	if traceFormat == formatSARIF {
+		return true, writeTraceSARIF(os.Stdout, projectRoot, *result)
+	}
+	displayTracePaths(result, paths)
+	switch {
+	case traceJSON:
+		return true, outputJSON(*result)
+	case traceFormat == formatMarkdown:
+		return true, writeTraceMarkdown(os.Stdout, *result)
+	}
+	return false, nil
+}
+
+// symbolNotFound prints the empty result of tracing an unknown symbol and
+// returns an E_NOT_FOUND error, already reported by that output.
+func symbolNotFound(projectRoot string, result trace.TraceResult, paths *search.Paths) error {
+	if traceJSON || traceFormat == formatSARIF {
+		if _, err := outputTraceFormat(projectRoot, &result, paths); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("No symbol found: %s\n", result.Query)
+	}
+	return reportedError{errcode.New(errcode.NotFound, "symbol not found: %s", result.Query)}
+}
+
+// displayTracePaths rewrites the file paths of result in the --path-style
+// style.
+func displayTracePaths(result *trace.TraceResult, paths *search.Paths) {
+	if result.Symbol != nil {
+		result.Symbol.File = paths.Display(result.Symbol.File)
+	}
+	displayCallerPaths(result.Callers, paths)
+	for i := range result.Definitions {
+		def := &result.Definitions[i]
+		def.Symbol.File = paths.Display(def.Symbol.File)
+		displayCallerPaths(def.Callers, paths)
+	}
+	displayCallerPaths(result.Unresolved, paths)
+	for i := range result.Callees {
+		result.Callees[i].Symbol.File = paths.Display(result.Callees[i].Symbol.File)
+		result.Callees[i].CallSite.File = paths.Display(result.Callees[i].CallSite.File)
+	}
+	for i := range result.Implementations {
+		impl := &result.Implementations[i]
+		impl.Interface.File = paths.Display(impl.Interface.File)
+		impl.Type.File = paths.Display(impl.Type.File)
+		if impl.Method != nil {
+			impl.Method.File = paths.Display(impl.Method.File)
+		}
+	}
+	if result.Graph != nil {
+		for name, sym := range result.Graph.Nodes {
+			sym.File = paths.Display(sym.File)
+			result.Graph.Nodes[name] = sym
+		}
+		for i := range result.Graph.Edges {
+			result.Graph.Edges[i].File = paths.Display(result.Graph.Edges[i].File)
+		}
+	}
+}
+
+func displayCallerPaths(callers []trace.CallerInfo, paths *search.Paths) {
+	for i := range callers {
+		callers[i].Symbol.File = paths.Display(callers[i].Symbol.File)
+		callers[i].CallSite.File = paths.Display(callers[i].CallSite.File)
+	}
 }
 
 func outputJSON(result trace.TraceResult) error {
@@ -286,8 +582,35 @@ func displayCallersResult(result trace.TraceResult) error {
 		return nil
 	}
 
-	for i, caller := range result.Callers {
-		fmt.Printf("\n%d. %s\n", i+1, caller.Symbol.Name)
+	printCallers(result.Callers)
+	return nil
+}
+
+func displayDefinitionsResult(result trace.TraceResult) error {
+	fmt.Printf("Symbol: %s (%d definitions)\n", result.Query, len(result.Definitions))
+
+	for _, def := range result.Definitions {
+		name := def.Symbol.Name
+		if def.Symbol.Receiver != "" {
+			name = "(" + def.Symbol.Receiver + ")." + name
+		}
+		fmt.Printf("\n%s (%s) @ %s:%d\n", name, def.Symbol.Kind, def.Symbol.File, def.Symbol.Line)
+		fmt.Printf("Callers (%d):\n", len(def.Callers))
+		fmt.Println(strings.Repeat("-", 60))
+		printCallers(def.Callers)
+	}
+
+	if len(result.Unresolved) > 0 {
+		fmt.Printf("\nUnresolved callers (%d), matching more than one definition:\n", len(result.Unresolved))
+		fmt.Println(strings.Repeat("-", 60))
+		printCallers(result.Unresolved)
+	}
+	return nil
+}
+
+func printCallers(callers []trace.CallerInfo) {
+	for i, caller := range callers {
+		fmt.Printf("\n%d. %s%s\n", i+1, caller.Symbol.Name, traceLevel(caller.Depth, caller.Via, caller.Cycle))
 		if caller.Symbol.File != "" {
 			fmt.Printf("   Defined: %s:%d\n", caller.Symbol.File, caller.Symbol.Line)
 		}
@@ -296,8 +619,6 @@ func displayCallersResult(result trace.TraceResult) error {
 			fmt.Printf("   Context: %s\n", truncate(caller.CallSite.Context, 80))
 		}
 	}
-
-	return nil
 }
 
 func displayCalleesResult(result trace.TraceResult) error {
@@ -312,7 +633,7 @@ func displayCalleesResult(result trace.TraceResult) error {
 	}
 
 	for i, callee := range result.Callees {
-		fmt.Printf("\n%d. %s\n", i+1, callee.Symbol.Name)
+		fmt.Printf("\n%d. %s%s\n", i+1, callee.Symbol.Name, traceLevel(callee.Depth, callee.Via, callee.Cycle))
 		if callee.Symbol.File != "" {
 			fmt.Printf("   Defined: %s:%d\n", callee.Symbol.File, callee.Symbol.Line)
 		}
@@ -322,6 +643,31 @@ func displayCalleesResult(result trace.TraceResult) error {
 	return nil
 }
 
+func displayImplementationsResult(result trace.TraceResult) error {
+	fmt.Printf("Symbol: %s (%s)\n", result.Query, result.Symbol.Kind)
+	fmt.Printf("File: %s:%d\n", result.Symbol.File, result.Symbol.Line)
+	fmt.Printf("\nImplementations (%d):\n", len(result.Implementations))
+	fmt.Println(strings.Repeat("-", 60))
+
+	if len(result.Implementations) == 0 {
+		fmt.Println("No implementations found.")
+		return nil
+	}
+
+	for i, impl := range result.Implementations {
+		if impl.Method != nil {
+			fmt.Printf("\n%d. %s.%s implements %s.%s\n", i+1, impl.Type.Name, impl.Method.Name, impl.Interface.Name, impl.Method.Name)
+			fmt.Printf("   Defined: %s:%d\n", impl.Method.File, impl.Method.Line)
+		} else {
+			fmt.Printf("\n%d. %s implements %s\n", i+1, impl.Type.Name, impl.Interface.Name)
+			fmt.Printf("   Defined: %s:%d\n", impl.Type.File, impl.Type.Line)
+		}
+		fmt.Printf("   Interface: %s:%d\n", impl.Interface.File, impl.Interface.Line)
+	}
+
+	return nil
+}
+
 func displayGraphResult(result trace.TraceResult) error {
 	fmt.Printf("Call Graph for: %s (depth: %d)\n", result.Query, result.Graph.Depth)
 	fmt.Println(strings.Repeat("=", 60))
@@ -339,6 +685,25 @@ func displayGraphResult(result trace.TraceResult) error {
 	return nil
 }
 
+// traceLevel describes where a caller or callee of a recursive trace was
+// found; it is empty for direct calls.
+func traceLevel(depth int, via string, cycle bool) string {
+	var parts []string
+	if depth > 1 {
+		parts = append(parts, fmt.Sprintf("depth %d", depth))
+	}
+	if via != "" {
+		parts = append(parts, "via "+via)
+	}
+	if cycle {
+		parts = append(parts, "cycle")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
 func truncate(s string, maxLen int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	if len(s) <= maxLen {
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/localsetup"
+	"github.com/doveaia/agentdx/session"
+	"github.com/spf13/cobra"
+)
+
+// claudeSettingsBackupPath is the copy of .claude/settings.json that setup
+// keeps before adding the agentdx hooks to it
+const claudeSettingsBackupPath = ".claude/settings.backup.json"
+
+// claudeSkillPath is where the agentdx skill of the Claude Code plugin is
+// copied into a project
+const claudeSkillPath = ".claude/skills/agentdx"
+
+var (
+	uninstallDocker bool
+	uninstallPurge  bool
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove agentdx from the project",
+	Long: `Reverse 'agentdx init' and 'agentdx setup' in the current project:
+
+- Stop the session daemon and forget the project in 'agentdx sessions'
+- Restore .claude/settings.json from the backup setup made of it, losing
+  later changes to it; without a backup only the agentdx hooks are removed
+- Remove the configuration of every coding agent, as 'agentdx setup
+  --remove' does: files agentdx owns and its hook scripts are deleted, and
+  its instructions are stripped from shared files such as CLAUDE.md and
+  .cursorrules
+- Delete .claude/skills/agentdx
+
+The index and configuration in .agentdx are kept unless --purge is given.
+--docker also removes the PostgreSQL container and its volume. The container
+is shared by every project on this machine: their indexes are deleted too.`,
+	Example: `  # Remove the agent configuration, keep the index
+  agentdx uninstall
+
+  # Also delete .agentdx and the local PostgreSQL container
+  agentdx uninstall --purge --docker`,
+	Args: cobra.NoArgs,
+	RunE: runUninstall,
+}
+
+func init() {
+	uninstallCmd.Flags().BoolVar(&uninstallDocker, "docker", false, "Also remove the PostgreSQL container and its volume (shared by all projects)")
+	uninstallCmd.Flags().BoolVar(&uninstallPurge, "purge", false, "Also delete the .agentdx directory (index and configuration)")
+	rootCmd.AddCommand(uninstallCmd)
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	// Agent files may be left in a project whose configuration is gone
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		projectRoot = cwd
+	}
+	var cfg *config.Config
+	if config.Exists(projectRoot) {
+		if cfg, err = config.Load(projectRoot); err != nil {
+			fmt.Printf("Warning: failed to load configuration: %v\n", err)
+		}
+	}
+
+	stopProjectSession(context.Background(), projectRoot)
+
+	if err := uninstallAgents(cwd); err != nil {
+		return err
+	}
+
+	if uninstallDocker {
+		removeDockerContainer(cfg)
+	}
+
+	if uninstallPurge {
+		dir := filepath.Join(projectRoot, ".agentdx")
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		fmt.Printf("\n[remove] %s\n", dir)
+	}
+
+	fmt.Println("\nagentdx was removed from this project. Remove the binary to uninstall it from the machine.")
+	return nil
+}
+
+// stopProjectSession stops the session daemon of the project and removes
+// the project from the registry of 'agentdx sessions'.
+func stopProjectSession(ctx context.Context, projectRoot string) {
+	dm := session.NewDaemonManager(projectRoot)
+	if running, _ := dm.IsRunning(); running {
+		if err := dm.Stop(ctx, false); err != nil {
+			fmt.Printf("[warn] failed to stop the session daemon: %v\n", err)
+		} else {
+			fmt.Println("[stop] session daemon")
+		}
+	}
+	if n, err := session.Unregister(projectRoot); err != nil {
+		fmt.Printf("[warn] failed to update the sessions registry: %v\n", err)
+	} else if n > 0 {
+		fmt.Println("[remove] project from 'agentdx sessions'")
+	}
+}
+
+// uninstallAgents removes the configuration of every coding agent in cwd,
+// restoring .claude/settings.json from its backup first so that settings
+// agentdx replaced come back.
+func uninstallAgents(cwd string) error {
+	backup := filepath.Join(cwd, claudeSettingsBackupPath)
+	if _, err := os.Stat(backup); err == nil {
+		if err := os.Rename(backup, filepath.Join(cwd, claudeSettingsPath)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", claudeSettingsPath, err)
+		}
+		fmt.Printf("[restore] %s (from %s)\n", claudeSettingsPath, filepath.Base(claudeSettingsBackupPath))
+	}
+
+	skill := filepath.Join(cwd, claudeSkillPath)
+	if _, err := os.Stat(skill); err == nil {
+		if err := os.RemoveAll(skill); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", claudeSkillPath, err)
+		}
+		fmt.Printf("[remove] %s/\n", claudeSkillPath)
+		_ = os.Remove(filepath.Dir(skill))
+	}
+
+	// Setup also appends its instructions to .claude/settings.md, which no
+	// agent owns; CLAUDE.md stands in as the template of shared files
+	if _, err := removeAgentFile(cwd, AgentFile{TemplateName: "CLAUDE.md", DestPath: ".claude/settings.md", Shared: true}); err != nil {
+		fmt.Printf("[warn] .claude/settings.md: %v\n", err)
+	}
+
+	return RemoveAgentConfigs(cwd, SupportedAgentConfigs())
+}
+
+// removeDockerContainer removes the PostgreSQL container of the
+// configuration, or the default one, with its volume.
+func removeDockerContainer(cfg *config.Config) {
+	opts := localsetup.DefaultContainerOptions()
+	if cfg != nil && cfg.Index.Store.Postgres.ContainerName != "" {
+		opts.Name = cfg.Index.Store.Postgres.ContainerName
+	}
+	if !localsetup.IsDockerAvailable() {
+		fmt.Printf("\n[warn] Docker is not running; remove the %s container and %s volume manually\n", opts.Name, opts.VolumeName())
+		return
+	}
+	if err := localsetup.RemoveContainer(opts.Name); err != nil {
+		fmt.Printf("\n[warn] %v\n", err)
+		return
+	}
+	fmt.Printf("\n[remove] container %s\n", opts.Name)
+	if err := localsetup.RemoveVolume(opts.VolumeName()); err != nil {
+		fmt.Printf("[warn] %v\n", err)
+		return
+	}
+	fmt.Printf("[remove] volume %s\n", opts.VolumeName())
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUninstallAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	userSettings := `{"permissions": {"allow": ["Bash(ls)"]}}`
+	userNotes := "# Settings\n\nOur own notes.\n"
+	if err := os.MkdirAll(filepath.Join(tmpDir, claudeSkillPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		claudeSettingsPath:            userSettings,
+		".claude/settings.md":         userNotes + "\n" + defaultTemplate(t, setupInstructionsTemplate),
+		claudeSkillPath + "/SKILL.md": "agentdx skill",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Setup backs up the user's settings before adding its hooks
+	agents, _ := ResolveAgentConfigs([]string{"cursor"})
+	if err := GenerateAgentConfigsFor(tmpDir, agents); err != nil {
+		t.Fatalf("failed to generate agent configs: %v", err)
+	}
+	if err := createSettings(tmpDir); err != nil {
+		t.Fatalf("failed to create settings: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, claudeSettingsBackupPath)); err != nil {
+		t.Fatalf("expected a settings backup: %v", err)
+	}
+
+	if err := uninstallAgents(tmpDir); err != nil {
+		t.Fatalf("uninstallAgents failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, claudeSettingsPath))
+	if err != nil || string(data) != userSettings {
+		t.Errorf("expected the settings backup to be restored, got %q, %v", data, err)
+	}
+	data, err = os.ReadFile(filepath.Join(tmpDir, ".claude/settings.md"))
+	if err != nil || string(data) != userNotes {
+		t.Errorf("expected the agentdx instructions to be stripped, got %q, %v", data, err)
+	}
+	for _, path := range []string{claudeSettingsBackupPath, claudeSkillPath, ".claude/skills", ".cursorrules", ".cursor"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err == nil {
+			t.Errorf("%s left behind", path)
+		}
+	}
+	if _, err := os.Stat(claudeDir); err != nil {
+		t.Errorf("expected .claude to be kept for the user's files: %v", err)
+	}
+}
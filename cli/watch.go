@@ -2,19 +2,26 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/doveaia/agentdx/config"
 	"github.com/doveaia/agentdx/dashboard"
+	"github.com/doveaia/agentdx/encrypt"
+	"github.com/doveaia/agentdx/errcode"
 	"github.com/doveaia/agentdx/indexer"
 	"github.com/doveaia/agentdx/localsetup"
+	"github.com/doveaia/agentdx/rpc"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/session"
 	"github.com/doveaia/agentdx/store"
 	"github.com/doveaia/agentdx/trace"
 	"github.com/doveaia/agentdx/watcher"
@@ -38,20 +45,37 @@ Container Options:
   --pg-name, -n    Custom container name (default: agentdx-postgres)
   --pg-port, -p    Custom host port (default: 55432)
 
+Daemon Mode:
+  --daemon runs the watcher in the background like 'agentdx session start',
+  writing structured logs to .agentdx/session.log (rotated per session.log in
+  the configuration). Send SIGHUP to reload the configuration: retention,
+  garbage collection, trace mode, dashboard, gRPC and log settings apply
+  immediately; other changes require a restart.
+
+Indexing Profiles:
+  --profile indexes only the path globs of a profile from the configuration
+  (or $AGENTDX_PROFILE), so that searches cover the slice of a monorepo you
+  work on. Files outside the profile are removed from the index; watch
+  without a profile to index the whole project again.
+
 The PostgreSQL container persists after agentdx exits to preserve your index.`,
 	RunE: runWatch,
 }
 
 var (
-	daemonMode bool
-	pgName     string
-	pgPort     int
+	daemonMode    bool
+	pgName        string
+	pgPort        int
+	watchProgress string
+	watchProfile  string
 )
 
 func init() {
-	watchCmd.Flags().BoolVar(&daemonMode, "daemon", false, "Run in daemon mode (for session management)")
+	watchCmd.Flags().BoolVar(&daemonMode, "daemon", false, "Run in the background, logging to .agentdx/session.log")
 	watchCmd.Flags().StringVarP(&pgName, "pg-name", "n", "", "PostgreSQL container name (default: agentdx-postgres)")
 	watchCmd.Flags().IntVarP(&pgPort, "pg-port", "p", 0, "PostgreSQL host port (default: 55432)")
+	watchCmd.Flags().StringVar(&watchProgress, "progress", progressFormatBar, "Initial scan progress: bar, json (NDJSON events on stdout, other output on stderr) or none")
+	watchCmd.Flags().StringVar(&watchProfile, "profile", "", "Only index the paths of this indexing profile (see profiles in config; default: $"+config.IndexProfileEnv+")")
 }
 
 // buildContainerOptions builds container options from flags and config.
@@ -67,6 +91,7 @@ func buildContainerOptions(cfg *config.Config, flagName string, flagPort int) lo
 	if cfg.Index.Store.Postgres.Port != 0 {
 		opts.Port = cfg.Index.Store.Postgres.Port
 	}
+	opts.SSLMode = cfg.Index.Store.Postgres.SSLMode
 
 	// Apply flag values (highest priority)
 	if flagName != "" {
@@ -85,7 +110,19 @@ func runWatch(cmd *cobra.Command, args []string) error {
 
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	if watchProgress != progressFormatBar && watchProgress != progressFormatJSON && watchProgress != progressFormatNone {
+		return errcode.New(errcode.InvalidArgs, "invalid --progress %q: use %s, %s or %s", watchProgress, progressFormatBar, progressFormatJSON, progressFormatNone)
+	}
+	// NDJSON progress owns stdout, so messages are logged like in daemon mode
+	verbose := !daemonMode && watchProgress != progressFormatJSON
+
+	// Run by hand, --daemon detaches: the daemon manager starts this command
+	// again in the background
+	if daemonMode && !session.IsDaemonProcess() {
+		return startSession(pgName, pgPort, watchProfile)
+	}
 
 	// Find project root
 	projectRoot, err := config.FindProjectRoot()
@@ -99,26 +136,62 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Build container options: flags > config > defaults
-	opts := buildContainerOptions(cfg, pgName, pgPort)
+	// The daemon logs structured records to the rotating session log
+	var logFile *session.RotatingFile
+	if daemonMode {
+		cipher, err := encrypt.NewSource(cfg.Encryption).Cipher()
+		if err != nil {
+			return err
+		}
+		logFile, err = session.OpenRotatingFile(session.LogFilePath(projectRoot), sessionLogOptions(cfg))
+		if err != nil {
+			return err
+		}
+		defer logFile.Close()
+		logFile.SetCipher(cipher)
+		session.RedirectLog(logFile, cfg.Session.Log.Format)
+	}
 
-	// Ensure PostgreSQL is running
-	dsn, err := localsetup.EnsurePostgresRunning(ctx, projectRoot, opts)
-	if err != nil {
-		return err
+	// The daemon serves its health to 'agentdx session status' and to
+	// 'agentdx session start', which restarts it when wedged
+	health := session.NewHealthMonitor(projectRoot)
+	if daemonMode {
+		if err := health.Start(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		defer health.Close()
+	}
+
+	storeOpts := store.OptionsFromConfig(cfg, projectRoot)
+	if storeOpts.Backend != store.BackendSQLite && storeOpts.PostgresNamespace != "" {
+		// A namespaced index lives in a shared database, not a local container
+		if storeOpts.PostgresDSN == "" {
+			return errcode.New(errcode.Config, "index.store.postgres.namespace requires index.store.postgres.dsn")
+		}
+	} else if storeOpts.Backend != store.BackendSQLite {
+		// Build container options: flags > config > defaults
+		opts := buildContainerOptions(cfg, pgName, pgPort)
+
+		// Ensure PostgreSQL is running
+		dsn, err := localsetup.EnsurePostgresRunning(ctx, projectRoot, opts)
+		if err != nil {
+			return err
+		}
+		storeOpts.PostgresDSN = dsn
 	}
 
-	if !daemonMode {
+	if verbose {
 		fmt.Printf("Starting agentdx watch in %s\n", projectRoot)
-		fmt.Printf("Backend: PostgreSQL FTS\n")
+		fmt.Printf("Backend: %s\n", store.SearchName(storeOpts.Backend))
 	}
 
-	// Initialize PostgreSQL FTS store with the DSN from EnsurePostgresRunning
-	st, err := store.NewPostgresFTSStore(ctx, dsn, projectRoot)
+	// Open index store (Postgres uses the DSN from EnsurePostgresRunning)
+	st, err := openProfiledStore(ctx, cfg, storeOpts)
 	if err != nil {
-		return fmt.Errorf("failed to connect to postgres: %w", err)
+		return fmt.Errorf("failed to open index store: %w", err)
 	}
 	defer st.Close()
+	storeOpts.CJKBigrams = cfg.Index.Search.CJKBigrams
 
 	// Initialize ignore matcher
 	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
@@ -126,104 +199,130 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
 	}
 
-	// Initialize scanner
-	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	// Initialize scanner, restricted to the selected indexing profile
+	scanner, err := profileScanner(cfg, indexer.NewScanner(projectRoot, ignoreMatcher), watchProfile)
+	if err != nil {
+		return err
+	}
+	if profile := config.SelectedIndexProfile(watchProfile); profile != "" {
+		if verbose {
+			fmt.Printf("Profile: %s\n", profile)
+		} else {
+			log.Printf("Indexing profile %s", profile)
+		}
+	}
 
-	// Initialize chunker
-	chunker := indexer.NewChunker(cfg.Index.Chunking.Size, cfg.Index.Chunking.Overlap)
+	// Initialize chunker (size-based or AST-aware, per config)
+	chunker, err := newFileChunker(cfg)
+	if err != nil {
+		return err
+	}
 
-	// Initialize indexer
-	idx := indexer.NewIndexer(projectRoot, st, chunker, scanner)
+	// Initialize indexers: one for the root project and one per workspace,
+	// each writing under its own project ID
+	indexes, err := openWorkspaceIndexes(ctx, cfg, projectRoot, storeOpts, st, chunker, scanner)
+	if err != nil {
+		return err
+	}
+	defer closeWorkspaceIndexes(indexes)
 
 	// Initialize symbol store and extractor
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
+	symbolStore, err := store.OpenSymbolStore(ctx, cfg.Index.Trace.Store, cfg.GetSymbolStorePath(projectRoot), storeOpts)
+	if err != nil {
+		return fmt.Errorf("failed to open symbol store: %w", err)
+	}
 	if err := symbolStore.Load(ctx); err != nil {
 		log.Printf("Warning: failed to load symbol index: %v", err)
 	}
 	defer symbolStore.Close()
 
-	extractor, err := trace.NewRegexExtractor()
+	extractor, err := newSymbolExtractor(cfg.Index.Trace.Mode)
 	if err != nil {
-		return fmt.Errorf("failed to create symbol extractor: %w", err)
+		return err
 	}
 
-	// Use default trace languages if not configured
-	tracedLanguages := cfg.Index.Trace.EnabledLanguages
-	if len(tracedLanguages) == 0 {
-		tracedLanguages = []string{".go", ".js", ".ts", ".jsx", ".tsx", ".py", ".php", ".java"}
-	}
+	tracedLanguages := cfg.Index.Trace.Languages()
+
+	// Start dashboard if enabled; it shows the progress of the initial scan
+	dashboardServer := startDashboard(ctx, cfg, projectRoot, st, symbolStore, verbose)
+
+	progress := newScanProgress(watchProgress, daemonMode, dashboardServer)
 
 	// Initial scan with progress
-	if !daemonMode {
+	if verbose {
 		fmt.Println("\nPerforming initial scan...")
 	}
-	stats, err := idx.IndexAllWithProgress(ctx, func(info indexer.ProgressInfo) {
-		if !daemonMode {
-			printProgress(info.Current, info.Total, info.CurrentFile)
+	stats := &indexer.IndexStats{}
+	for _, wi := range indexes {
+		wsStats, err := wi.indexer.IndexAllWithProgress(ctx, func(info indexer.ProgressInfo) {
+			progress.emit(indexer.ProgressEvent{
+				Phase:        indexer.PhaseIndex,
+				Workspace:    wi.name,
+				File:         info.CurrentFile,
+				Current:      info.Current,
+				Total:        info.Total,
+				Chunks:       info.Chunks,
+				ChunksPerSec: info.ChunksPerSec,
+			})
+		})
+		progress.clear()
+		if err != nil {
+			return fmt.Errorf("initial indexing failed: %w", err)
 		}
-	})
-	if !daemonMode {
-		// Clear progress line
-		fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
-	}
-	if err != nil {
-		return fmt.Errorf("initial indexing failed: %w", err)
+		if wi.name != "" && verbose {
+			fmt.Printf("Workspace %s: %d files indexed, %d chunks created\n", wi.name, wsStats.FilesIndexed, wsStats.ChunksCreated)
+		}
+		stats.FilesIndexed += wsStats.FilesIndexed
+		stats.ChunksCreated += wsStats.ChunksCreated
+		stats.FilesRemoved += wsStats.FilesRemoved
+		stats.FilesSkipped += wsStats.FilesSkipped
+		stats.Duration += wsStats.Duration
 	}
 
-	if !daemonMode {
+	if verbose {
 		fmt.Printf("Initial scan complete: %d files indexed, %d chunks created, %d files removed, %d skipped (took %s)\n",
 			stats.FilesIndexed, stats.ChunksCreated, stats.FilesRemoved, stats.FilesSkipped, stats.Duration.Round(time.Millisecond))
 	} else {
 		log.Printf("Initial scan complete: %d files indexed, %d chunks created", stats.FilesIndexed, stats.ChunksCreated)
 	}
 
-	// Index symbols for traced languages
-	if !daemonMode {
-		fmt.Println("Building symbol index...")
+	// Drop files deleted longer ago than the retention period
+	purgeDeleted(ctx, cfg, indexes)
+
+	// Flag indexes built with other chunking settings
+	checkIndexFingerprints(ctx, cfg, indexes)
+
+	// Update symbol index for traced languages, skipping unchanged files
+	if verbose {
+		fmt.Println("Updating symbol index...")
 	}
-	symbolCount := 0
-	files, _, _ := scanner.Scan()
-	for _, file := range files {
-		ext := strings.ToLower(filepath.Ext(file.Path))
-		if !isTracedLanguage(ext, tracedLanguages) {
-			continue
-		}
-		symbols, refs, err := extractor.ExtractAll(ctx, file.Path, file.Content)
-		if err != nil {
-			log.Printf("Warning: failed to extract symbols from %s: %v", file.Path, err)
-			continue
-		}
-		if err := symbolStore.SaveFile(ctx, file.Path, symbols, refs); err != nil {
-			log.Printf("Warning: failed to save symbols for %s: %v", file.Path, err)
-		}
-		symbolCount += len(symbols)
+	progress.emit(indexer.ProgressEvent{Phase: indexer.PhaseSymbols})
+	symbolStats, err := updateSymbols(ctx, scanner, extractor, symbolStore, tracedLanguages)
+	if err != nil {
+		log.Printf("Warning: symbol index update interrupted: %v", err)
+	}
+	if symbolStats.Failed > 0 {
+		log.Printf("Warning: failed to extract symbols from %d files", symbolStats.Failed)
 	}
 	if err := symbolStore.Persist(ctx); err != nil {
 		log.Printf("Warning: failed to persist symbol index: %v", err)
+	} else {
+		health.Persisted()
 	}
-	if !daemonMode {
-		fmt.Printf("Symbol index built: %d symbols extracted\n", symbolCount)
+	if verbose {
+		fmt.Printf("Symbol index updated: %d files extracted (%d symbols), %d unchanged, %d removed\n",
+			symbolStats.Extracted, symbolStats.Symbols, symbolStats.Skipped, symbolStats.Removed)
 	} else {
-		log.Printf("Symbol index built: %d symbols extracted", symbolCount)
+		log.Printf("Symbol index updated: %d files extracted (%d symbols), %d unchanged, %d removed",
+			symbolStats.Extracted, symbolStats.Symbols, symbolStats.Skipped, symbolStats.Removed)
 	}
+	progress.emit(indexer.ProgressEvent{Phase: indexer.PhaseDone})
 
-	// Start dashboard if enabled
-	var dashboardServer *dashboard.Server
-	if cfg.Dashboard.Enabled {
-		dashboardServer = dashboard.NewServer(cfg, projectRoot, st, symbolStore)
-		if err := dashboardServer.Start(ctx); err != nil {
-			log.Printf("Warning: failed to start dashboard: %v", err)
-		} else {
-			if !daemonMode {
-				fmt.Printf("Dashboard started at %s\n", dashboardServer.URL())
-			} else {
-				log.Printf("Dashboard started at %s", dashboardServer.URL())
-			}
-		}
-	}
+	// Start gRPC API if enabled
+	grpcServer := startGRPC(ctx, cfg, projectRoot, st, symbolStore, verbose)
 
 	// Initialize watcher
-	w, err := watcher.NewWatcher(projectRoot, ignoreMatcher, cfg.Index.Watch.DebounceMs)
+	w, err := watcher.NewWatcher(projectRoot, ignoreMatcher, cfg.Index.Watch.DebounceMs, cfg.Index.Watch.BulkThreshold, watcher.Backend(cfg.Index.Watch.Backend))
 	if err != nil {
 		return fmt.Errorf("failed to initialize watcher: %w", err)
 	}
@@ -233,67 +332,307 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to start watcher: %w", err)
 	}
 
-	if !daemonMode {
-		fmt.Println("\nWatching for changes... (Press Ctrl+C to stop)")
+	if verbose {
+		fmt.Printf("\nWatching for changes with %s... (Press Ctrl+C to stop)\n", w.Backend())
 	} else {
-		log.Println("Watching for changes...")
+		log.Printf("Watching for changes with %s...", w.Backend())
 	}
 
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+
+	// The heartbeat stops when the event loop hangs; symbols changed since
+	// the last persist are saved every minute
+	heartbeat := time.NewTicker(session.HeartbeatInterval)
+	defer heartbeat.Stop()
+	persistTicker := time.NewTicker(time.Minute)
+	defer persistTicker.Stop()
+	symbolsChanged := false
+	health.SetPhase(session.PhaseWatching)
+
+	// A nil channel never fires, which disables periodic garbage collection
+	var gcTicker *time.Ticker
+	var gcTick <-chan time.Time
+	if hours := cfg.Index.Watch.GCIntervalHours; hours > 0 {
+		gcTicker = time.NewTicker(time.Duration(hours) * time.Hour)
+		gcTick = gcTicker.C
+	}
+	defer func() {
+		if gcTicker != nil {
+			gcTicker.Stop()
+		}
+	}()
+
 	// Event loop
 	for {
 		select {
-		case <-sigChan:
-			if !daemonMode {
+		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				next, err := reloadWatchConfig(projectRoot, cfg)
+				if err != nil {
+					log.Printf("Warning: failed to reload configuration: %v", err)
+					continue
+				}
+				if next.Index.Trace.Mode != cfg.Index.Trace.Mode {
+					if ex, err := newSymbolExtractor(next.Index.Trace.Mode); err != nil {
+						log.Printf("Warning: %v", err)
+						next.Index.Trace.Mode = cfg.Index.Trace.Mode
+					} else {
+						extractor = ex
+					}
+				}
+				if next.Index.Watch.GCIntervalHours != cfg.Index.Watch.GCIntervalHours {
+					if gcTicker != nil {
+						gcTicker.Stop()
+					}
+					gcTicker, gcTick = nil, nil
+					if hours := next.Index.Watch.GCIntervalHours; hours > 0 {
+						gcTicker = time.NewTicker(time.Duration(hours) * time.Hour)
+						gcTick = gcTicker.C
+					}
+				}
+				if logFile != nil {
+					logFile.SetOptions(sessionLogOptions(next))
+					session.RedirectLog(logFile, next.Session.Log.Format)
+				}
+				// Restart the servers so they serve the new settings
+				stopServers(ctx, dashboardServer, grpcServer)
+				cfg = next
+				dashboardServer = startDashboard(ctx, cfg, projectRoot, st, symbolStore, verbose)
+				grpcServer = startGRPC(ctx, cfg, projectRoot, st, symbolStore, verbose)
+				log.Println("Configuration reloaded")
+				continue
+			}
+
+			if verbose {
 				fmt.Println("\nShutting down...")
 			} else {
 				log.Println("Shutting down...")
 			}
-			// Stop dashboard
-			if dashboardServer != nil {
-				if err := dashboardServer.Stop(ctx); err != nil {
-					log.Printf("Warning: failed to stop dashboard: %v", err)
-				}
-			}
+			stopServers(ctx, dashboardServer, grpcServer)
 			if err := symbolStore.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist symbol index on shutdown: %v", err)
 			}
 			return nil
 
 		case event := <-w.Events():
-			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, event)
+			if event.Type == watcher.EventIgnoreChange {
+				resyncIgnored(ctx, indexes, scanner, extractor, symbolStore, tracedLanguages, event)
+				health.EventProcessed(event.Path)
+				continue
+			}
+			if event.Type == watcher.EventBulkChange {
+				// The rescan sends no heartbeats; scanning daemons are not
+				// reported as wedged
+				health.SetPhase(session.PhaseScanning)
+				resyncBulk(ctx, indexes, scanner, extractor, symbolStore, tracedLanguages, event)
+				health.SetPhase(session.PhaseWatching)
+				symbolsChanged = true
+				continue
+			}
+			// Files outside the profile are not indexed; deleting them is harmless
+			if (event.Type == watcher.EventCreate || event.Type == watcher.EventModify) && !scanner.Includes(event.Path) {
+				continue
+			}
+			idx := indexerFor(cfg, indexes, event.Path)
+			activity, ok := handleFileEvent(ctx, projectRoot, idx, scanner, extractor, symbolStore, tracedLanguages, event)
+			if ok && dashboardServer != nil {
+				dashboardServer.BroadcastActivity(activity)
+			}
+			health.EventProcessed(event.Path)
+			symbolsChanged = true
+
+		case <-heartbeat.C:
+			health.Beat(w.QueueDepth())
+
+		case <-persistTicker.C:
+			// A bulk change in progress ends with a rescan; persist after it
+			if !symbolsChanged || w.Settling() {
+				continue
+			}
+			if err := symbolStore.Persist(ctx); err != nil {
+				log.Printf("Warning: failed to persist symbol index: %v", err)
+				continue
+			}
+			symbolsChanged = false
+			health.Persisted()
+
+		case <-purgeTicker.C:
+			purgeDeleted(ctx, cfg, indexes)
+
+		case <-gcTick:
+			stats, err := collectGarbage(ctx, st)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			} else if stats.OrphanChunks > 0 || stats.OrphanIndexRows > 0 {
+				log.Printf("Garbage collection removed %d orphaned chunks and %d index rows, reclaimed %s",
+					stats.OrphanChunks, stats.OrphanIndexRows, formatBytes(stats.Reclaimed()))
+			}
 		}
 	}
 }
 
-func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore *trace.GOBSymbolStore, enabledLanguages []string, event watcher.FileEvent) {
+// startDashboard starts the dashboard if enabled. It returns nil when the
+// dashboard is disabled or fails to start.
+func startDashboard(ctx context.Context, cfg *config.Config, projectRoot string, st store.SearchStore, symbolStore trace.SymbolStore, verbose bool) *dashboard.Server {
+	if !cfg.Dashboard.Enabled {
+		return nil
+	}
+	server := dashboard.NewServer(cfg, projectRoot, st, symbolStore)
+	if err := server.Start(ctx); err != nil {
+		log.Printf("Warning: failed to start dashboard: %v", err)
+		return nil
+	}
+	if verbose {
+		fmt.Printf("Dashboard started at %s\n", server.URL())
+	} else {
+		log.Printf("Dashboard started at %s", server.URL())
+	}
+	return server
+}
+
+// startGRPC starts the gRPC API if enabled. It returns nil when the API is
+// disabled or fails to start.
+func startGRPC(ctx context.Context, cfg *config.Config, projectRoot string, st store.SearchStore, symbolStore trace.SymbolStore, verbose bool) *rpc.Server {
+	if !cfg.GRPC.Enabled {
+		return nil
+	}
+	server := rpc.NewServer(cfg, projectRoot, st, symbolStore)
+	if err := server.Start(ctx); err != nil {
+		log.Printf("Warning: failed to start gRPC API: %v", err)
+		return nil
+	}
+	if verbose {
+		fmt.Printf("gRPC API listening on %s\n", server.Addr())
+	} else {
+		log.Printf("gRPC API listening on %s", server.Addr())
+	}
+	return server
+}
+
+// stopServers stops the dashboard and the gRPC API, either of which may be nil.
+func stopServers(ctx context.Context, dashboardServer *dashboard.Server, grpcServer *rpc.Server) {
+	if dashboardServer != nil {
+		if err := dashboardServer.Stop(ctx); err != nil {
+			log.Printf("Warning: failed to stop dashboard: %v", err)
+		}
+	}
+	if grpcServer != nil {
+		stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := grpcServer.Stop(stopCtx); err != nil {
+			log.Printf("Warning: failed to stop gRPC API: %v", err)
+		}
+		cancel()
+	}
+}
+
+// reloadWatchConfig loads the configuration again for a running watch. Settings
+// the watch cannot change while running keep their current values, with a
+// warning when they were changed.
+func reloadWatchConfig(projectRoot string, cur *config.Config) (*config.Config, error) {
+	next, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range keepRestartSettings(cur, next) {
+		log.Printf("Warning: %s changed; restart agentdx watch to apply it", name)
+	}
+	return next, nil
+}
+
+// keepRestartSettings copies the settings that only apply when watch starts
+// from cur to next, and returns the names of those that differ.
+func keepRestartSettings(cur, next *config.Config) []string {
+	var changed []string
+	keep := func(name string, curVal, nextVal any, restore func()) {
+		if !reflect.DeepEqual(curVal, nextVal) {
+			changed = append(changed, name)
+			restore()
+		}
+	}
+	keep("index.store", cur.Index.Store, next.Index.Store, func() { next.Index.Store = cur.Index.Store })
+	keep("index.chunking", cur.Index.Chunking, next.Index.Chunking, func() { next.Index.Chunking = cur.Index.Chunking })
+	keep("index.ignore", cur.Index.Ignore, next.Index.Ignore, func() { next.Index.Ignore = cur.Index.Ignore })
+	keep("index.watch.debounce_ms", cur.Index.Watch.DebounceMs, next.Index.Watch.DebounceMs, func() { next.Index.Watch.DebounceMs = cur.Index.Watch.DebounceMs })
+	keep("index.watch.bulk_threshold", cur.Index.Watch.BulkThreshold, next.Index.Watch.BulkThreshold, func() { next.Index.Watch.BulkThreshold = cur.Index.Watch.BulkThreshold })
+	keep("index.trace.store", cur.Index.Trace.Store, next.Index.Trace.Store, func() { next.Index.Trace.Store = cur.Index.Trace.Store })
+	keep("index.trace.enabled_languages", cur.Index.Trace.EnabledLanguages, next.Index.Trace.EnabledLanguages, func() { next.Index.Trace.EnabledLanguages = cur.Index.Trace.EnabledLanguages })
+	keep("project", cur.Project, next.Project, func() { next.Project = cur.Project })
+	keep("workspaces", cur.Workspaces, next.Workspaces, func() { next.Workspaces = cur.Workspaces })
+	keep("profiles", cur.Profiles, next.Profiles, func() { next.Profiles = cur.Profiles })
+	return changed
+}
+
+// sessionLogOptions returns the rotation limits of the session log.
+func sessionLogOptions(cfg *config.Config) session.RotateOptions {
+	l := cfg.Session.Log
+	return session.RotateOptions{
+		MaxSize:    int64(l.MaxSizeMB) << 20,
+		MaxAge:     time.Duration(l.MaxAgeHours) * time.Hour,
+		MaxBackups: l.MaxBackups,
+	}
+}
+
+// handleFileEvent indexes or removes the file of event and returns what was
+// done, for the dashboard's activity feed. ok is false when the file was
+// skipped (binary, too large, etc.).
+func handleFileEvent(ctx context.Context, projectRoot string, idx *indexer.Indexer, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore trace.SymbolStore, enabledLanguages []string, event watcher.FileEvent) (activity dashboard.Activity, ok bool) {
 	log.Printf("[%s] %s", event.Type, event.Path)
 
+	start := time.Now()
+	activity = dashboard.Activity{Time: start, File: event.Path}
+	defer func() {
+		activity.DurationMs = time.Since(start).Milliseconds()
+	}()
+	fail := func(format string, err error) (dashboard.Activity, bool) {
+		log.Printf(format, event.Path, err)
+		activity.Action = dashboard.ActivityFailed
+		activity.Error = err.Error()
+		return activity, true
+	}
+
 	switch event.Type {
 	case watcher.EventCreate, watcher.EventModify:
 		fileInfo, err := scanner.ScanFile(event.Path)
 		if err != nil {
-			log.Printf("Failed to scan %s: %v", event.Path, err)
-			return
+			return fail("Failed to scan %s: %v", err)
 		}
 		if fileInfo == nil {
-			return // File was skipped (binary, too large, etc.)
+			return activity, false // File was skipped (binary, too large, etc.)
 		}
 
 		chunks, err := idx.IndexFile(ctx, *fileInfo)
 		if err != nil {
-			log.Printf("Failed to index %s: %v", event.Path, err)
-			return
+			return fail("Failed to index %s: %v", err)
 		}
 		log.Printf("Indexed %s (%d chunks)", event.Path, chunks)
+		activity.Action = dashboard.ActivityIndexed
+		activity.Chunks = chunks
 
 		// Extract symbols if language is supported
 		ext := strings.ToLower(filepath.Ext(event.Path))
 		if isTracedLanguage(ext, enabledLanguages) {
+			// A renamed file keeps its symbols
+			source := trace.SourceFile{Path: fileInfo.Path, Hash: fileInfo.Hash}
+			if old := trace.RenameSource(symbolStore, source, func(path string) bool {
+				_, err := os.Stat(filepath.Join(projectRoot, path))
+				return !os.IsNotExist(err)
+			}); old != "" {
+				if err := symbolStore.RenameFile(ctx, old, fileInfo.Path); err != nil {
+					log.Printf("Failed to move symbols from %s to %s: %v", old, event.Path, err)
+				} else {
+					log.Printf("Moved symbols from %s to %s", old, event.Path)
+					return activity, true
+				}
+			}
+
 			symbols, refs, err := extractor.ExtractAll(ctx, fileInfo.Path, fileInfo.Content)
 			if err != nil {
 				log.Printf("Failed to extract symbols from %s: %v", event.Path, err)
-			} else if err := symbolStore.SaveFile(ctx, fileInfo.Path, symbols, refs); err != nil {
+				activity.Error = "failed to extract symbols: " + err.Error()
+			} else if err := symbolStore.SaveFileWithHash(ctx, fileInfo.Path, fileInfo.Hash, symbols, refs); err != nil {
 				log.Printf("Failed to save symbols for %s: %v", event.Path, err)
+				activity.Error = "failed to save symbols: " + err.Error()
 			} else {
 				log.Printf("Extracted %d symbols from %s", len(symbols), event.Path)
 			}
@@ -301,15 +640,224 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 
 	case watcher.EventDelete, watcher.EventRename:
 		if err := idx.RemoveFile(ctx, event.Path); err != nil {
-			log.Printf("Failed to remove %s from index: %v", event.Path, err)
-			return
+			return fail("Failed to remove %s from index: %v", err)
 		}
+		activity.Action = dashboard.ActivityRemoved
 		// Also remove from symbol index
 		if err := symbolStore.DeleteFile(ctx, event.Path); err != nil {
 			log.Printf("Failed to remove symbols for %s: %v", event.Path, err)
+			activity.Error = "failed to remove symbols: " + err.Error()
 		}
 		log.Printf("Removed %s from index", event.Path)
+
+	default:
+		return activity, false
+	}
+	return activity, true
+}
+
+// resyncIgnored brings the indexes in line with changed ignore files: newly
+// ignored files are removed and files that are no longer ignored are indexed.
+// The watcher has already reloaded the ignore matcher.
+func resyncIgnored(ctx context.Context, indexes []workspaceIndex, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore trace.SymbolStore, enabledLanguages []string, event watcher.FileEvent) {
+	log.Printf("[%s] %s", event.Type, event.Path)
+
+	indexed, removed, symbolStats := rescan(ctx, indexes, scanner, extractor, symbolStore, enabledLanguages, event.Path+" changed")
+	if err := symbolStore.Persist(ctx); err != nil {
+		log.Printf("Failed to persist symbol index: %v", err)
+	}
+	log.Printf("Ignore rules reloaded: %d files indexed, %d removed, %d symbol files removed", indexed, removed, symbolStats.Removed)
+}
+
+// resyncBulk updates the index after a bulk change, such as a branch switch,
+// with a single rescan instead of one update per changed file. The symbol
+// index is left to the next persist tick.
+func resyncBulk(ctx context.Context, indexes []workspaceIndex, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore trace.SymbolStore, enabledLanguages []string, event watcher.FileEvent) {
+	log.Printf("[%s] %d files changed, rescanning", event.Type, event.Count)
+
+	start := time.Now()
+	indexed, removed, symbolStats := rescan(ctx, indexes, scanner, extractor, symbolStore, enabledLanguages, "a bulk change")
+	log.Printf("Bulk change rescanned: %d files indexed, %d removed, %d symbol files extracted, %d removed (took %s)",
+		indexed, removed, symbolStats.Extracted, symbolStats.Removed, time.Since(start).Round(time.Millisecond))
+}
+
+// rescan indexes the files of every workspace whose content differs from the
+// index, drops the files that are gone and updates the symbol index. Failures
+// are logged as happening after cause.
+func rescan(ctx context.Context, indexes []workspaceIndex, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore trace.SymbolStore, enabledLanguages []string, cause string) (indexed, removed int, symbolStats trace.UpdateStats) {
+	for _, wi := range indexes {
+		stats, err := wi.indexer.IndexAll(ctx)
+		if err != nil {
+			log.Printf("Failed to update index after %s: %v", cause, err)
+			continue
+		}
+		indexed += stats.FilesIndexed
+		removed += stats.FilesRemoved
+	}
+
+	symbolStats, err := updateSymbols(ctx, scanner, extractor, symbolStore, enabledLanguages)
+	if err != nil {
+		log.Printf("Failed to update symbol index after %s: %v", cause, err)
+	}
+	return indexed, removed, symbolStats
+}
+
+// newSymbolExtractor returns the extractor for a trace mode, falling back
+// to fast extraction when tree-sitter is unavailable.
+func newSymbolExtractor(mode string) (trace.SymbolExtractor, error) {
+	extractor, err := trace.NewExtractor(mode)
+	if errors.Is(err, trace.ErrTreeSitterUnavailable) {
+		log.Printf("Warning: trace mode %q unavailable: %v; falling back to fast (regex) extraction", mode, err)
+		extractor, err = trace.NewExtractor(trace.ModeFast)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create symbol extractor: %w", err)
+	}
+	return extractor, nil
+}
+
+// updateSymbols extracts symbols from the traced files the scanner finds,
+// skipping unchanged files and dropping files that are no longer found.
+func updateSymbols(ctx context.Context, scanner *indexer.Scanner, extractor trace.SymbolExtractor, symbolStore trace.SymbolStore, enabledLanguages []string) (trace.UpdateStats, error) {
+	return trace.UpdateIndex(ctx, symbolStore, extractor, tracedSources(scanner, enabledLanguages), 0)
+}
+
+// tracedSources returns the files the scanner finds in the traced languages.
+func tracedSources(scanner *indexer.Scanner, enabledLanguages []string) []trace.SourceFile {
+	files, _, _ := scanner.Scan()
+	var sources []trace.SourceFile
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Path))
+		if !isTracedLanguage(ext, enabledLanguages) {
+			continue
+		}
+		sources = append(sources, trace.SourceFile{Path: file.Path, Content: file.Content, Hash: file.Hash})
 	}
+	return sources
+}
+
+// workspaceIndex indexes the files of the root project (name "") or of one
+// workspace into that project's store.
+type workspaceIndex struct {
+	name    string
+	store   store.SearchStore
+	indexer *indexer.Indexer
+}
+
+// openWorkspaceIndexes returns the root project's index followed by one index
+// per configured workspace. The root index uses rootStore and skips files that
+// belong to a workspace. Paths stay relative to the project root everywhere.
+func openWorkspaceIndexes(ctx context.Context, cfg *config.Config, projectRoot string, opts store.Options, rootStore store.SearchStore, chunker indexer.FileChunker, scanner *indexer.Scanner) ([]workspaceIndex, error) {
+	indexes := []workspaceIndex{{
+		store:   rootStore,
+		indexer: newIndexer(cfg, projectRoot, rootStore, chunker, workspaceScanner(cfg, scanner, "")),
+	}}
+
+	for _, ws := range cfg.Workspaces {
+		wsOpts := opts
+		wsOpts.ProjectID = ws.ProjectID(opts.ProjectID)
+		st, err := store.Open(ctx, wsOpts)
+		if err != nil {
+			closeWorkspaceIndexes(indexes)
+			return nil, fmt.Errorf("failed to open index store for workspace %s: %w", ws.Name, err)
+		}
+
+		indexes = append(indexes, workspaceIndex{
+			name:    ws.Name,
+			store:   st,
+			indexer: newIndexer(cfg, projectRoot, st, chunker, workspaceScanner(cfg, scanner, ws.Name)),
+		})
+	}
+	return indexes, nil
+}
+
+// workspaceScanner restricts scanner to the files of the named workspace, or
+// of the root project when the name is empty.
+func workspaceScanner(cfg *config.Config, scanner *indexer.Scanner, name string) *indexer.Scanner {
+	return scanner.Filter(func(relPath string) bool {
+		w := cfg.WorkspaceFor(relPath)
+		if w == nil {
+			return name == ""
+		}
+		return w.Name == name
+	})
+}
+
+// profileScanner restricts scanner to the paths of the indexing profile
+// selected by flag or by AGENTDX_PROFILE. Without a profile, scanner is
+// returned as is.
+func profileScanner(cfg *config.Config, scanner *indexer.Scanner, flag string) (*indexer.Scanner, error) {
+	name := config.SelectedIndexProfile(flag)
+	if name == "" {
+		return scanner, nil
+	}
+	patterns, err := cfg.IndexProfile(name)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgs, err)
+	}
+	return scanner.Include(patterns), nil
+}
+
+// closeWorkspaceIndexes closes the workspace stores. The root store is owned
+// by the caller.
+func closeWorkspaceIndexes(indexes []workspaceIndex) {
+	for _, wi := range indexes {
+		if wi.name != "" {
+			wi.store.Close()
+		}
+	}
+}
+
+// purgeDeleted permanently removes files that were deleted longer ago than
+// the retention period.
+func purgeDeleted(ctx context.Context, cfg *config.Config, indexes []workspaceIndex) {
+	cutoff, ok := cfg.Index.Retention.DeletedCutoff(time.Now())
+	if !ok {
+		return
+	}
+	for _, wi := range indexes {
+		purged, err := wi.store.PurgeDeleted(ctx, cutoff)
+		if err != nil {
+			log.Printf("Warning: failed to purge deleted files: %v", err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("Purged %d chunks of files deleted before %s", purged, cutoff.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+// checkIndexFingerprints records the chunking settings with indexes built
+// before fingerprints existed, and warns about indexes built with other
+// settings, whose unchanged files keep their old chunks.
+func checkIndexFingerprints(ctx context.Context, cfg *config.Config, indexes []workspaceIndex) {
+	fingerprint := cfg.IndexFingerprint()
+	for _, wi := range indexes {
+		indexed, err := wi.store.IndexFingerprint(ctx)
+		switch {
+		case err != nil:
+			log.Printf("Warning: %v", err)
+		case indexed == "":
+			if err := wi.store.SetIndexFingerprint(ctx, fingerprint); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		case indexed != fingerprint:
+			mismatch := search.ConfigMismatch{Indexed: indexed, Current: fingerprint}
+			log.Printf("Warning: %s", mismatch.Warning())
+		}
+	}
+}
+
+// indexerFor returns the indexer responsible for a project-relative path.
+func indexerFor(cfg *config.Config, indexes []workspaceIndex, relPath string) *indexer.Indexer {
+	if ws := cfg.WorkspaceFor(relPath); ws != nil {
+		for _, wi := range indexes {
+			if wi.name == ws.Name {
+				return wi.indexer
+			}
+		}
+	}
+	return indexes[0].indexer
 }
 
 // isTracedLanguage checks if a file extension is in the enabled languages list.
@@ -323,7 +871,8 @@ func isTracedLanguage(ext string, enabledLanguages []string) bool {
 }
 
 // printProgress displays a progress bar for indexing
-func printProgress(current, total int, filePath string) {
+func printProgress(event indexer.ProgressEvent) {
+	current, total := event.Current, event.Total
 	if total == 0 {
 		return
 	}
@@ -336,13 +885,19 @@ func printProgress(current, total int, filePath string) {
 	filled := int(float64(barWidth) * float64(current) / float64(total))
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 
+	// Throughput, once chunks are stored
+	rate := ""
+	if event.ChunksPerSec > 0 {
+		rate = fmt.Sprintf(" %.0f chunks/s", event.ChunksPerSec)
+	}
+
 	// Truncate file path if too long
 	maxPathLen := 35
-	displayPath := filePath
-	if len(filePath) > maxPathLen {
-		displayPath = "..." + filePath[len(filePath)-maxPathLen+3:]
+	displayPath := event.File
+	if len(displayPath) > maxPathLen {
+		displayPath = "..." + displayPath[len(displayPath)-maxPathLen+3:]
 	}
 
 	// Print with carriage return to overwrite previous line
-	fmt.Printf("\rIndexing [%s] %3.0f%% (%d/%d) %s", bar, percent, current, total, displayPath)
+	fmt.Printf("\rIndexing [%s] %3.0f%% (%d/%d)%s %s", bar, percent, current, total, rate, displayPath)
 }
@@ -158,3 +158,25 @@ func TestBuildSessionContainerOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestKeepRestartSettings(t *testing.T) {
+	cur := config.DefaultConfig()
+	next := config.DefaultConfig()
+	next.Index.Chunking.Size = 1024
+	next.Index.Retention.DeletedDays = 30
+	next.Dashboard.Port = 8080
+	next.Workspaces = []config.WorkspaceConfig{{Name: "api", Path: "services/api"}}
+
+	changed := keepRestartSettings(cur, next)
+
+	want := []string{"index.chunking", "workspaces"}
+	if len(changed) != len(want) || changed[0] != want[0] || changed[1] != want[1] {
+		t.Errorf("keepRestartSettings() = %v, want %v", changed, want)
+	}
+	if next.Index.Chunking.Size != 512 || next.Workspaces != nil {
+		t.Error("settings applied at startup should keep their current values")
+	}
+	if next.Index.Retention.DeletedDays != 30 || next.Dashboard.Port != 8080 {
+		t.Error("settings applied on reload should take their new values")
+	}
+}
@@ -1,17 +1,31 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/doveaia/agentdx/errcode"
 )
 
 const (
 	ConfigDir           = ".agentdx"
 	ConfigFileName      = "config.yaml"
 	SymbolIndexFileName = "symbols.gob"
+	SymbolBoltFileName  = "symbols.db"
+	SQLiteIndexFileName = "index.db"
+	LastSearchFileName  = "last-search.json"
+	FallbackLogFileName = "fallbacks.log"
 )
 
 // Config holds the agentdx configuration.
@@ -20,22 +34,127 @@ type Config struct {
 	Mode      string          `yaml:"mode"` // "local" or "remote" - local uses embedded PostgreSQL, remote uses configured backend
 	Index     IndexSection    `yaml:"index"`
 	Dashboard DashboardConfig `yaml:"dashboard"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	Metrics   MetricsConfig   `yaml:"metrics,omitempty"`
+	Project   ProjectConfig   `yaml:"project,omitempty"`
+	Session   SessionConfig   `yaml:"session,omitempty"`
+	MCP       MCPConfig       `yaml:"mcp,omitempty"`
+
+	// Encryption seals the symbol index and session log at rest
+	Encryption EncryptionConfig `yaml:"encryption,omitempty"`
+
+	// Workspaces splits a monorepo into sub-projects indexed under their own project IDs
+	Workspaces []WorkspaceConfig `yaml:"workspaces,omitempty"`
+
+	// Profiles name slices of a monorepo (path globs) that watch --profile indexes alone
+	Profiles map[string][]string `yaml:"profiles,omitempty"`
+
+	gitProjectID string // origin remote ID resolved by Load for the git strategy
 }
 
 // DashboardConfig holds web dashboard settings.
 type DashboardConfig struct {
+	Enabled bool                `yaml:"enabled"`
+	Port    int                 `yaml:"port"`
+	Host    string              `yaml:"host"`
+	Auth    DashboardAuthConfig `yaml:"auth,omitempty"`
+}
+
+// DashboardAuthConfig holds the dashboard credentials.
+type DashboardAuthConfig struct {
+	Token string `yaml:"token,omitempty"` // Required as a bearer token or basic auth password; AGENTDX_DASHBOARD_TOKEN overrides it
+}
+
+// DashboardTokenEnv is the environment variable holding the dashboard token.
+const DashboardTokenEnv = "AGENTDX_DASHBOARD_TOKEN"
+
+// AuthToken returns the token clients must present, preferring the
+// environment over the config file.
+func (d DashboardConfig) AuthToken() string {
+	if token := os.Getenv(DashboardTokenEnv); token != "" {
+		return token
+	}
+	return d.Auth.Token
+}
+
+// MetricsConfig enables the local query metrics reviewed with 'agentdx stats'.
+type MetricsConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	StoreQueries bool `yaml:"store_queries,omitempty"` // Keep query text, not only its hash
+}
+
+// Defaults of the MCP server limits.
+const (
+	DefaultMCPTimeoutMs     = 30000
+	DefaultMCPMaxConcurrent = 8
+)
+
+// MCPConfig bounds the tool calls of the MCP server, so that an agent firing
+// dozens of calls at once cannot exhaust the backend's connections, and a
+// call stuck on the backend does not hang the agent.
+type MCPConfig struct {
+	TimeoutMs      int            `yaml:"timeout_ms,omitempty"`       // Time allowed for a tool call (default 30000); negative disables it
+	ToolTimeoutsMs map[string]int `yaml:"tool_timeouts_ms,omitempty"` // Per-tool overrides of timeout_ms, keyed by tool name (agentdx_search)
+	MaxConcurrent  int            `yaml:"max_concurrent,omitempty"`   // Tool calls run at once (default 8), others wait their turn; negative disables the limit
+}
+
+// Timeout returns the time allowed for a call of tool, or 0 for no limit.
+func (m MCPConfig) Timeout(tool string) time.Duration {
+	ms, ok := m.ToolTimeoutsMs[tool]
+	if !ok || ms == 0 {
+		ms = m.TimeoutMs
+	}
+	switch {
+	case ms < 0:
+		return 0
+	case ms == 0:
+		ms = DefaultMCPTimeoutMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Concurrency returns how many tool calls may run at once, or 0 for no
+// limit.
+func (m MCPConfig) Concurrency() int {
+	switch {
+	case m.MaxConcurrent < 0:
+		return 0
+	case m.MaxConcurrent == 0:
+		return DefaultMCPMaxConcurrent
+	}
+	return m.MaxConcurrent
+}
+
+// GRPCConfig holds gRPC API settings.
+type GRPCConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	Port    int    `yaml:"port"`
 	Host    string `yaml:"host"`
+	Token   string `yaml:"token,omitempty"` // Bearer token required from clients; AGENTDX_GRPC_TOKEN overrides it
 }
+
+// GRPCTokenEnv is the environment variable holding the gRPC API token.
+const GRPCTokenEnv = "AGENTDX_GRPC_TOKEN"
+
+// AuthToken returns the token clients must present, preferring the
+// environment over the config file.
+func (g GRPCConfig) AuthToken() string {
+	if token := os.Getenv(GRPCTokenEnv); token != "" {
+		return token
+	}
+	return g.Token
+}
+
 type IndexSection struct {
-	Store    StoreConfig    `yaml:"store"`
-	Chunking ChunkingConfig `yaml:"chunking"`
-	Watch    WatchConfig    `yaml:"watch"`
-	Search   SearchConfig   `yaml:"search"`
-	Trace    TraceConfig    `yaml:"trace"`
-	Update   UpdateConfig   `yaml:"update"`
-	Ignore   []string       `yaml:"ignore"`
+	Store     StoreConfig     `yaml:"store"`
+	Chunking  ChunkingConfig  `yaml:"chunking"`
+	Watch     WatchConfig     `yaml:"watch"`
+	Retention RetentionConfig `yaml:"retention"`
+	Search    SearchConfig    `yaml:"search"`
+	Trace     TraceConfig     `yaml:"trace"`
+	Update    UpdateConfig    `yaml:"update"`
+	Summaries SummaryConfig   `yaml:"summaries,omitempty"`
+	Ignore    []string        `yaml:"ignore"`
 }
 
 // UpdateConfig holds auto-update settings
@@ -44,7 +163,81 @@ type UpdateConfig struct {
 }
 
 type SearchConfig struct {
-	Boost BoostConfig `yaml:"boost"`
+	Boost              BoostConfig      `yaml:"boost"`
+	PreferSourceOnTies bool             `yaml:"prefer_source_on_ties"` // Rank source files above tests when scores tie
+	CJKBigrams         bool             `yaml:"cjk_bigrams"`           // Tokenize Chinese/Japanese/Korean text as bigrams (requires re-index)
+	Identifiers        IdentifierConfig `yaml:"identifiers"`
+	MaxPerFile         int              `yaml:"max_per_file"` // Maximum results per file; negative disables the cap
+	Expansion          ExpansionConfig  `yaml:"expansion"`
+	Profile            string           `yaml:"profile,omitempty"`   // Shared ranking profile that replaces the settings above
+	StaleAfterSeconds  int              `yaml:"stale_after_seconds"` // Warn when the index lags files on disk by more than this; negative disables the check
+	Budget             BudgetConfig     `yaml:"budget,omitempty"`
+	Blame              bool             `yaml:"blame"` // Annotate results with the last commit of their matched lines (git blame)
+	Rerank             RerankConfig     `yaml:"rerank,omitempty"`
+}
+
+// Defaults of the reranker settings, applied when reranking is enabled. The
+// endpoint has no default: servers of the API listen on different ports.
+const (
+	DefaultRerankModel      = "bge-reranker-v2-m3"
+	DefaultRerankCandidates = 50
+	DefaultRerankTimeoutMs  = 2000
+)
+
+// RerankConfig sets up an optional second ranking stage: the top full-text
+// candidates of a query are scored by a local cross-encoder model served
+// over the /v1/rerank API, and reordered by its scores. When the reranker
+// fails or times out, the full-text order is kept.
+type RerankConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Endpoint   string `yaml:"endpoint,omitempty"`   // URL of the rerank API, required when enabled
+	Model      string `yaml:"model,omitempty"`      // Reranker model name
+	Candidates int    `yaml:"candidates,omitempty"` // Full-text results to rerank
+	TimeoutMs  int    `yaml:"timeout_ms,omitempty"` // Time allowed for a rerank call
+}
+
+// Defaults of the summary settings, applied when an LLM endpoint is set.
+const (
+	DefaultSummaryModel     = "qwen2.5-coder:7b"
+	DefaultSummaryTimeoutMs = 60000
+)
+
+// SummaryAPIKeyEnv is the environment variable holding the API key sent to
+// the summary LLM endpoint as a bearer token.
+const SummaryAPIKeyEnv = "AGENTDX_SUMMARY_API_KEY"
+
+// SummaryConfig sets up 'agentdx summarize', which indexes a short summary
+// of each directory. Without an endpoint, summaries are assembled from the
+// directory's README, doc comments and file names; with one, they are
+// written by an LLM served over the OpenAI-compatible chat completions API.
+type SummaryConfig struct {
+	Endpoint  string `yaml:"endpoint,omitempty"`   // URL of the chat completions API
+	Model     string `yaml:"model,omitempty"`      // LLM model name
+	TimeoutMs int    `yaml:"timeout_ms,omitempty"` // Time allowed for a summary
+}
+
+// BudgetConfig limits the searches of each MCP session, so that a runaway
+// agent loop cannot hammer the backend or flood its own context. Zero
+// disables a limit.
+type BudgetConfig struct {
+	SearchesPerMinute int     `yaml:"searches_per_minute,omitempty"`
+	MaxContentBytes   int64   `yaml:"max_content_bytes,omitempty"` // Total result content returned to a session
+	WarnAt            float64 `yaml:"warn_at,omitempty"`           // Fraction of a limit at which agents are warned (default 0.8)
+}
+
+// IdentifierConfig controls the indexing of camelCase and snake_case
+// identifiers as sub-tokens, so that "getUser" matches get_user and "user"
+// matches getUser. Changing it requires a re-index.
+type IdentifierConfig struct {
+	Split     bool            `yaml:"split"`               // Split identifiers in every language
+	Languages map[string]bool `yaml:"languages,omitempty"` // Per-language overrides, keyed like --lang (go, markdown) or by extension
+}
+
+// ExpansionConfig controls query expansion with synonyms and identifier
+// variants (camelCase, snake_case).
+type ExpansionConfig struct {
+	Enabled  bool       `yaml:"enabled"`
+	Synonyms [][]string `yaml:"synonyms,omitempty"` // Extra synonym sets, merged with the built-in ones
 }
 
 type BoostConfig struct {
@@ -53,36 +246,215 @@ type BoostConfig struct {
 	Bonuses   []BoostRule `yaml:"bonuses"`
 }
 
+// BoostRule multiplies the score of results matching every condition it
+// sets: a file path substring, a chunk kind and a file age.
 type BoostRule struct {
-	Pattern string  `yaml:"pattern"`
-	Factor  float32 `yaml:"factor"`
+	Pattern        string  `yaml:"pattern,omitempty"`
+	Kind           string  `yaml:"kind,omitempty"`            // Dominant chunk content: function, type, comment or code
+	ModifiedWithin string  `yaml:"modified_within,omitempty"` // Files modified within this age, e.g. 30d or 12h
+	Factor         float32 `yaml:"factor"`
+}
+
+// Chunk kinds boost rules can match.
+const (
+	KindFunction = "function"
+	KindType     = "type"
+	KindComment  = "comment"
+	KindCode     = "code"
+)
+
+// Age returns the ModifiedWithin duration, or 0 when it is unset. Days
+// (30d) and weeks (2w) are accepted along with Go durations (12h).
+func (r BoostRule) Age() (time.Duration, error) {
+	if r.ModifiedWithin == "" {
+		return 0, nil
+	}
+	age, err := ParseAge(r.ModifiedWithin)
+	if err != nil {
+		return 0, fmt.Errorf("invalid modified_within %q: use a duration such as 30d or 12h", r.ModifiedWithin)
+	}
+	return age, nil
+}
+
+// ParseAge parses a positive age in days (30d), weeks (2w) or as a Go
+// duration (12h).
+func ParseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty age: use a duration such as 30d or 12h")
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if unit == 0 {
+		age, err := time.ParseDuration(s)
+		if err != nil || age <= 0 {
+			return 0, fmt.Errorf("invalid age %q: use a duration such as 30d or 12h", s)
+		}
+		return age, nil
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid age %q: use a duration such as 30d or 12h", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Validate checks the rule's kind and age.
+func (r BoostRule) Validate() error {
+	switch r.Kind {
+	case "", KindFunction, KindType, KindComment, KindCode:
+	default:
+		return fmt.Errorf("invalid kind %q: use function, type, comment or code", r.Kind)
+	}
+	_, err := r.Age()
+	return err
 }
 
 type StoreConfig struct {
+	Backend  string         `yaml:"backend"` // postgres or sqlite
 	Postgres PostgresConfig `yaml:"postgres,omitempty"`
+	SQLite   SQLiteConfig   `yaml:"sqlite,omitempty"`
 }
 
 type PostgresConfig struct {
 	DSN           string `yaml:"dsn"`
 	ContainerName string `yaml:"container_name,omitempty"` // optional, default: agentdx-postgres
 	Port          int    `yaml:"port,omitempty"`           // optional, default: 55432
+	// Namespace gives each project its own schema in a database shared by
+	// several machines, e.g. the developer's name. Empty uses the public schema.
+	Namespace string `yaml:"namespace,omitempty"`
+	// SSLMode sets the sslmode of the DSN and of the local container's DSN:
+	// require encrypts connections, verify-ca and verify-full also check
+	// the server certificate. Empty keeps the DSN's own sslmode.
+	SSLMode string `yaml:"sslmode,omitempty"`
+}
+
+// ConnString returns the DSN with the configured sslmode, which replaces
+// any sslmode in the DSN. It accepts URLs and keyword/value DSNs.
+func (p PostgresConfig) ConnString() string {
+	if p.SSLMode == "" || p.DSN == "" {
+		return p.DSN
+	}
+	if u, err := url.Parse(p.DSN); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("sslmode", p.SSLMode)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	// The last setting of a keyword/value DSN wins
+	return p.DSN + " sslmode=" + p.SSLMode
+}
+
+// namespacePattern matches namespaces usable in a PostgreSQL schema name.
+var namespacePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type SQLiteConfig struct {
+	Path string `yaml:"path,omitempty"` // optional, default: .agentdx/index.db
 }
 
 type ChunkingConfig struct {
-	Size    int `yaml:"size"`
-	Overlap int `yaml:"overlap"`
+	Size     int         `yaml:"size"`
+	Overlap  int         `yaml:"overlap"`
+	Strategy string      `yaml:"strategy"` // size or ast
+	Strip    StripConfig `yaml:"strip,omitempty"`
+}
+
+// StripConfig removes boilerplate from the top of files before they are
+// chunked, such as license headers and generated-code banners. Line numbers
+// of the chunks still match the files.
+type StripConfig struct {
+	Headers      bool     `yaml:"headers,omitempty"`       // Strip leading comment blocks matching a license or generated-code pattern
+	Patterns     []string `yaml:"patterns,omitempty"`      // More regular expressions; a leading comment block matching one is stripped
+	CommentLines int      `yaml:"comment_lines,omitempty"` // Strip the first N lines of files when they are all comments
+	MaxLines     int      `yaml:"max_lines,omitempty"`     // Lines searched for a header from the top of files (default 50)
+}
+
+// Enabled reports whether any boilerplate is stripped.
+func (s StripConfig) Enabled() bool {
+	return s.Headers || len(s.Patterns) > 0 || s.CommentLines > 0
 }
 
 type WatchConfig struct {
-	DebounceMs int `yaml:"debounce_ms"`
+	DebounceMs      int `yaml:"debounce_ms"`
+	GCIntervalHours int `yaml:"gc_interval_hours"` // Hours between garbage collection passes; negative disables them
+	BulkThreshold   int `yaml:"bulk_threshold"`    // Files changed within a debounce window from which the project is rescanned at once; negative disables it
+	// Backend is how file changes are watched: fsnotify, watchman (which
+	// uses FSEvents on macOS and needs no inotify watch per directory), or
+	// auto, the default, which uses watchman when it is installed.
+	Backend string `yaml:"backend,omitempty"`
+}
+
+// Backends of the watcher.
+const (
+	WatchBackendAuto     = "auto"
+	WatchBackendFSNotify = "fsnotify"
+	WatchBackendWatchman = "watchman"
+)
+
+// EncryptionConfig encrypts the GOB symbol index (index.trace.store: gob)
+// and the session log with AES-256-GCM. The key is 32 bytes in base64 or
+// hex, read from AGENTDX_ENCRYPTION_KEY or from the OS keychain (macOS
+// Keychain or the Secret Service through secret-tool). The SQLite index and
+// the bolt symbol index would keep source in the clear, so Validate rejects
+// them with encryption; PostgreSQL relies on the database's own encryption.
+type EncryptionConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Key             string `yaml:"key,omitempty"`              // env (default) or keychain
+	KeychainService string `yaml:"keychain_service,omitempty"` // Keychain item service, default agentdx
+	KeychainAccount string `yaml:"keychain_account,omitempty"` // Keychain item account, default encryption-key
+}
+
+// Sources of the encryption key.
+const (
+	EncryptionKeyEnv      = "AGENTDX_ENCRYPTION_KEY"
+	EncryptionKeyKeychain = "keychain"
+
+	DefaultKeychainService = "agentdx"
+	DefaultKeychainAccount = "encryption-key"
+)
+
+// SessionConfig holds settings of the session daemon (agentdx watch --daemon).
+type SessionConfig struct {
+	Log SessionLogConfig `yaml:"log,omitempty"`
+}
+
+// SessionLogConfig controls the daemon log, .agentdx/session.log. Rotated
+// logs are kept as session.log.1 (newest) to session.log.<max_backups>.
+type SessionLogConfig struct {
+	Format      string `yaml:"format,omitempty"`        // json or text (key=value pairs)
+	MaxSizeMB   int    `yaml:"max_size_mb,omitempty"`   // Rotate when the log grows past this size; negative disables
+	MaxAgeHours int    `yaml:"max_age_hours,omitempty"` // Rotate after writing to the log this long; negative disables
+	MaxBackups  int    `yaml:"max_backups,omitempty"`   // Rotated logs to keep; negative keeps none
+}
+
+// RetentionConfig controls how long removed files stay soft-deleted in the
+// index (and searchable with search --deleted) before they are purged.
+type RetentionConfig struct {
+	DeletedDays int `yaml:"deleted_days"` // Days to keep removed files; negative keeps them forever
+}
+
+// DeletedCutoff returns the time before which soft-deleted rows are purged,
+// or false when purging is disabled.
+func (r RetentionConfig) DeletedCutoff(now time.Time) (time.Time, bool) {
+	if r.DeletedDays < 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -r.DeletedDays), true
 }
 
 type TraceConfig struct {
 	Mode             string   `yaml:"mode"`              // fast or precise
+	Store            string   `yaml:"store,omitempty"`   // Symbol index storage: gob (default), bolt (large codebases) or postgres (shared)
 	EnabledLanguages []string `yaml:"enabled_languages"` // File extensions to index
 	ExcludePatterns  []string `yaml:"exclude_patterns"`  // Patterns to exclude
 }
 
+// Languages returns the file extensions symbols are extracted from.
+func (t TraceConfig) Languages() []string {
+	if len(t.EnabledLanguages) == 0 {
+		return []string{".go", ".js", ".ts", ".jsx", ".tsx", ".py", ".php", ".java"}
+	}
+	return t.EnabledLanguages
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Version: 1,
@@ -92,16 +464,40 @@ func DefaultConfig() *Config {
 			Port:    7780,
 			Host:    "127.0.0.1",
 		},
+		GRPC: GRPCConfig{
+			Port: 7781,
+			Host: "127.0.0.1",
+		},
+		Session: SessionConfig{
+			Log: SessionLogConfig{
+				Format:      "json",
+				MaxSizeMB:   10,
+				MaxAgeHours: 24,
+				MaxBackups:  3,
+			},
+		},
 		Index: IndexSection{
-			Store: StoreConfig{},
+			Store: StoreConfig{
+				Backend: "postgres",
+			},
 			Chunking: ChunkingConfig{
-				Size:    512,
-				Overlap: 50,
+				Size:     512,
+				Overlap:  50,
+				Strategy: "size",
 			},
 			Watch: WatchConfig{
-				DebounceMs: 500,
+				DebounceMs:      500,
+				GCIntervalHours: 24,
+				BulkThreshold:   100,
+				Backend:         WatchBackendAuto,
+			},
+			Retention: RetentionConfig{
+				DeletedDays: 7,
 			},
 			Search: SearchConfig{
+				MaxPerFile:        3,
+				StaleAfterSeconds: 60,
+				Identifiers:       IdentifierConfig{Split: true},
 				Boost: BoostConfig{
 					Enabled: true,
 					Penalties: []BoostRule{
@@ -187,29 +583,156 @@ func GetSymbolIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), SymbolIndexFileName)
 }
 
+// GetLastSearchPath returns the file caching the results of the last search.
+func GetLastSearchPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), LastSearchFileName)
+}
+
+// GetFallbackLogPath returns the log of the searches agents ran without
+// agentdx, written by 'agentdx hook report-fallback'.
+func GetFallbackLogPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), FallbackLogFileName)
+}
+
+// GetSymbolStorePath returns the symbol index file of the trace.store backend.
+func (c *Config) GetSymbolStorePath(projectRoot string) string {
+	if c.Index.Trace.Store == "bolt" {
+		return filepath.Join(GetConfigDir(projectRoot), SymbolBoltFileName)
+	}
+	return GetSymbolIndexPath(projectRoot)
+}
+
+// GetSQLiteIndexPath returns the SQLite index file, honoring index.store.sqlite.path.
+// Relative paths are resolved against the project root.
+func (c *Config) GetSQLiteIndexPath(projectRoot string) string {
+	path := c.Index.Store.SQLite.Path
+	if path == "" {
+		return filepath.Join(GetConfigDir(projectRoot), SQLiteIndexFileName)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+	return path
+}
+
+// Load reads and validates the configuration of the project. Its errors
+// carry the errcode.Config code.
 func Load(projectRoot string) (*Config, error) {
-	configPath := GetConfigPath(projectRoot)
+	cfg, err := load(projectRoot)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, err)
+	}
+	return cfg, nil
+}
 
-	data, err := os.ReadFile(configPath)
+func load(projectRoot string) (*Config, error) {
+	data, err := os.ReadFile(GetConfigPath(projectRoot))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	return parse(data, projectRoot, false)
+}
 
+// parse decodes and validates a configuration file. With strict, keys
+// that are not part of the configuration are errors.
+func parse(data []byte, projectRoot string, strict bool) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(strict)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	// Apply defaults for missing values (backward compatibility)
 	cfg.applyDefaults()
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveProjectID(projectRoot); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// Validate checks the settings of a configuration with its defaults
+// applied. The project ID strategy is checked when the configuration is
+// loaded, since the git strategy reads the repository.
+func (c *Config) Validate() error {
+	if err := c.validateWorkspaces(); err != nil {
+		return err
+	}
+	if err := c.validateIndexProfiles(); err != nil {
+		return err
+	}
+	for _, k := range keyChoices {
+		value, err := c.Get(k.key)
+		if err != nil {
+			return err
+		}
+		if s := value.(string); s != "" && !slices.Contains(k.choices, s) {
+			return fmt.Errorf("%s %q: use %s", k.key, s, joinChoices(k.choices))
+		}
+	}
+	if ns := c.Index.Store.Postgres.Namespace; ns != "" && !namespacePattern.MatchString(ns) {
+		return fmt.Errorf("index.store.postgres.namespace %q: use up to 40 lowercase letters, digits and underscores, starting with a letter", ns)
+	}
+	for _, rule := range append(append([]BoostRule{}, c.Index.Search.Boost.Penalties...), c.Index.Search.Boost.Bonuses...) {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("index.search.boost: %w", err)
+		}
+	}
+	if b := c.Index.Search.Budget; b.SearchesPerMinute < 0 || b.MaxContentBytes < 0 || b.WarnAt < 0 || b.WarnAt > 1 {
+		return fmt.Errorf("index.search.budget: limits must not be negative and warn_at must be between 0 and 1")
+	}
+	if st := c.Index.Chunking.Strip; st.CommentLines < 0 || st.MaxLines < 0 {
+		return fmt.Errorf("index.chunking.strip: comment_lines and max_lines must not be negative")
+	}
+	for _, p := range c.Index.Chunking.Strip.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("index.chunking.strip.patterns: invalid pattern %q: %v", p, err)
+		}
+	}
+	if sum := c.Index.Summaries; sum.Endpoint != "" {
+		if u, err := url.Parse(sum.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("index.summaries.endpoint %q: use an http or https URL", sum.Endpoint)
+		}
+	}
+	if c.Index.Summaries.TimeoutMs < 0 {
+		return fmt.Errorf("index.summaries.timeout_ms must not be negative")
+	}
+	if c.Encryption.Enabled {
+		// These files hold source that encryption does not cover
+		if c.Index.Store.Backend == "sqlite" {
+			return fmt.Errorf("encryption.enabled: index.store.backend sqlite stores source unencrypted in the SQLite index file; use postgres with encryption at rest, or disable encryption")
+		}
+		if c.Index.Trace.Store == "bolt" {
+			return fmt.Errorf("encryption.enabled: index.trace.store bolt stores symbols unencrypted in .agentdx/symbols.db; use gob or postgres, or disable encryption")
+		}
+	}
+	if r := c.Index.Search.Rerank; r.Enabled {
+		if r.Endpoint == "" {
+			return fmt.Errorf("index.search.rerank.endpoint must be set when reranking is enabled, e.g. http://localhost:8080/v1/rerank for llama-server --reranking")
+		}
+		if u, err := url.Parse(r.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("index.search.rerank.endpoint %q: use an http or https URL", r.Endpoint)
+		}
+		if r.Candidates < 0 || r.TimeoutMs < 0 {
+			return fmt.Errorf("index.search.rerank: candidates and timeout_ms must not be negative")
+		}
+	}
+	return nil
+}
+
 // applyDefaults fills in missing configuration values with sensible defaults.
 func (c *Config) applyDefaults() {
 	defaults := DefaultConfig()
 
+	// Store defaults
+	if c.Index.Store.Backend == "" {
+		c.Index.Store.Backend = defaults.Index.Store.Backend
+	}
+
 	// Chunking defaults
 	if c.Index.Chunking.Size == 0 {
 		c.Index.Chunking.Size = defaults.Index.Chunking.Size
@@ -217,11 +740,79 @@ func (c *Config) applyDefaults() {
 	if c.Index.Chunking.Overlap == 0 {
 		c.Index.Chunking.Overlap = defaults.Index.Chunking.Overlap
 	}
+	if c.Index.Chunking.Strategy == "" {
+		c.Index.Chunking.Strategy = defaults.Index.Chunking.Strategy
+	}
+
+	// Retention defaults
+	if c.Index.Retention.DeletedDays == 0 {
+		c.Index.Retention.DeletedDays = defaults.Index.Retention.DeletedDays
+	}
+
+	// Search defaults
+	if c.Index.Search.MaxPerFile == 0 {
+		c.Index.Search.MaxPerFile = defaults.Index.Search.MaxPerFile
+	}
+	if c.Index.Search.StaleAfterSeconds == 0 {
+		c.Index.Search.StaleAfterSeconds = defaults.Index.Search.StaleAfterSeconds
+	}
+	if r := &c.Index.Search.Rerank; r.Enabled {
+		if r.Model == "" {
+			r.Model = DefaultRerankModel
+		}
+		if r.Candidates == 0 {
+			r.Candidates = DefaultRerankCandidates
+		}
+		if r.TimeoutMs == 0 {
+			r.TimeoutMs = DefaultRerankTimeoutMs
+		}
+	}
+
+	if sum := &c.Index.Summaries; sum.Endpoint != "" {
+		if sum.Model == "" {
+			sum.Model = DefaultSummaryModel
+		}
+		if sum.TimeoutMs == 0 {
+			sum.TimeoutMs = DefaultSummaryTimeoutMs
+		}
+	}
 
 	// Watch defaults
 	if c.Index.Watch.DebounceMs == 0 {
 		c.Index.Watch.DebounceMs = defaults.Index.Watch.DebounceMs
 	}
+	if c.Index.Watch.GCIntervalHours == 0 {
+		c.Index.Watch.GCIntervalHours = defaults.Index.Watch.GCIntervalHours
+	}
+	if c.Index.Watch.BulkThreshold == 0 {
+		c.Index.Watch.BulkThreshold = defaults.Index.Watch.BulkThreshold
+	}
+	if c.Index.Watch.Backend == "" {
+		c.Index.Watch.Backend = defaults.Index.Watch.Backend
+	}
+
+	if e := &c.Encryption; e.Enabled {
+		if e.KeychainService == "" {
+			e.KeychainService = DefaultKeychainService
+		}
+		if e.KeychainAccount == "" {
+			e.KeychainAccount = DefaultKeychainAccount
+		}
+	}
+
+	// Session log defaults
+	if c.Session.Log.Format == "" {
+		c.Session.Log.Format = defaults.Session.Log.Format
+	}
+	if c.Session.Log.MaxSizeMB == 0 {
+		c.Session.Log.MaxSizeMB = defaults.Session.Log.MaxSizeMB
+	}
+	if c.Session.Log.MaxAgeHours == 0 {
+		c.Session.Log.MaxAgeHours = defaults.Session.Log.MaxAgeHours
+	}
+	if c.Session.Log.MaxBackups == 0 {
+		c.Session.Log.MaxBackups = defaults.Session.Log.MaxBackups
+	}
 
 	// Dashboard defaults - if Port is 0, assume dashboard was never configured
 	// and apply all defaults including Enabled=true
@@ -232,6 +823,14 @@ func (c *Config) applyDefaults() {
 	if c.Dashboard.Host == "" {
 		c.Dashboard.Host = defaults.Dashboard.Host
 	}
+
+	// gRPC defaults
+	if c.GRPC.Port == 0 {
+		c.GRPC.Port = defaults.GRPC.Port
+	}
+	if c.GRPC.Host == "" {
+		c.GRPC.Host = defaults.GRPC.Host
+	}
 }
 
 func (c *Config) Save(projectRoot string) error {
@@ -265,8 +864,11 @@ func FindProjectRoot() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get current directory: %w", err)
 	}
+	return FindProjectRootFrom(cwd)
+}
 
-	dir := cwd
+// FindProjectRootFrom searches for the project root from dir upward.
+func FindProjectRootFrom(dir string) (string, error) {
 	for {
 		if Exists(dir) {
 			return dir, nil
@@ -279,5 +881,5 @@ func FindProjectRoot() (string, error) {
 		dir = parent
 	}
 
-	return "", fmt.Errorf("no agentdx project found (run 'agentdx init' first)")
+	return "", errcode.New(errcode.NoProject, "no agentdx project found (run 'agentdx init' first)")
 }
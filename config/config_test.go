@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -71,6 +73,228 @@ func TestConfigSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestLoad_ValidatesPostgresNamespace(t *testing.T) {
+	for _, tt := range []struct {
+		namespace string
+		valid     bool
+	}{
+		{"", true},
+		{"alice", true},
+		{"team_ci2", true},
+		{"Alice", false},
+		{"2fast", false},
+		{"bob; DROP", false},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Store.Postgres.Namespace = tt.namespace
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(tmpDir); (err == nil) != tt.valid {
+			t.Errorf("namespace %q: expected valid=%v, got %v", tt.namespace, tt.valid, err)
+		}
+	}
+}
+
+func TestLoad_ValidatesSearchBudget(t *testing.T) {
+	for _, tt := range []struct {
+		budget BudgetConfig
+		valid  bool
+	}{
+		{BudgetConfig{}, true},
+		{BudgetConfig{SearchesPerMinute: 30, MaxContentBytes: 1 << 20, WarnAt: 0.5}, true},
+		{BudgetConfig{SearchesPerMinute: -1}, false},
+		{BudgetConfig{MaxContentBytes: -1}, false},
+		{BudgetConfig{WarnAt: 1.5}, false},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Search.Budget = tt.budget
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(tmpDir); (err == nil) != tt.valid {
+			t.Errorf("budget %+v: expected valid=%v, got %v", tt.budget, tt.valid, err)
+		}
+	}
+}
+
+func TestLoad_Rerank(t *testing.T) {
+	for _, tt := range []struct {
+		rerank RerankConfig
+		valid  bool
+	}{
+		{RerankConfig{}, true},
+		{RerankConfig{Enabled: true}, false},
+		{RerankConfig{Enabled: true, Endpoint: "http://localhost:8080/v1/rerank"}, true},
+		{RerankConfig{Enabled: true, Endpoint: "localhost:11434"}, false},
+		{RerankConfig{Enabled: true, Candidates: -1}, false},
+		{RerankConfig{Endpoint: "not checked while disabled"}, true},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Search.Rerank = tt.rerank
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(tmpDir)
+		if (err == nil) != tt.valid {
+			t.Errorf("rerank %+v: expected valid=%v, got %v", tt.rerank, tt.valid, err)
+		}
+		if err == nil && tt.rerank.Enabled && tt.rerank.Model == "" {
+			want := RerankConfig{Enabled: true, Endpoint: tt.rerank.Endpoint, Model: DefaultRerankModel, Candidates: DefaultRerankCandidates, TimeoutMs: DefaultRerankTimeoutMs}
+			if loaded.Index.Search.Rerank != want {
+				t.Errorf("expected the rerank defaults, got %+v", loaded.Index.Search.Rerank)
+			}
+		}
+	}
+}
+
+func TestLoad_Strip(t *testing.T) {
+	for _, tt := range []struct {
+		strip StripConfig
+		valid bool
+	}{
+		{StripConfig{}, true},
+		{StripConfig{Headers: true, Patterns: []string{`(?i)proprietary`}}, true},
+		{StripConfig{Patterns: []string{`(unclosed`}}, false},
+		{StripConfig{CommentLines: -1}, false},
+		{StripConfig{Headers: true, MaxLines: -1}, false},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Chunking.Strip = tt.strip
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(tmpDir); (err == nil) != tt.valid {
+			t.Errorf("strip %+v: expected valid=%v, got %v", tt.strip, tt.valid, err)
+		}
+	}
+}
+
+func TestLoad_Summaries(t *testing.T) {
+	for _, tt := range []struct {
+		summaries SummaryConfig
+		valid     bool
+	}{
+		{SummaryConfig{}, true},
+		{SummaryConfig{Endpoint: "http://localhost:11434/v1/chat/completions"}, true},
+		{SummaryConfig{Endpoint: "localhost:11434"}, false},
+		{SummaryConfig{TimeoutMs: -1}, false},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Summaries = tt.summaries
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(tmpDir)
+		if (err == nil) != tt.valid {
+			t.Errorf("summaries %+v: expected valid=%v, got %v", tt.summaries, tt.valid, err)
+		}
+		if err == nil && tt.summaries.Endpoint != "" && (loaded.Index.Summaries.Model != DefaultSummaryModel || loaded.Index.Summaries.TimeoutMs != DefaultSummaryTimeoutMs) {
+			t.Errorf("expected the summary defaults, got %+v", loaded.Index.Summaries)
+		}
+	}
+}
+
+func TestPostgresConfig_ConnString(t *testing.T) {
+	for _, tt := range []struct {
+		pg   PostgresConfig
+		want string
+	}{
+		{PostgresConfig{DSN: "postgres://db.example.com/agentdx?sslmode=disable"}, "postgres://db.example.com/agentdx?sslmode=disable"},
+		{PostgresConfig{DSN: "postgres://db.example.com/agentdx?sslmode=disable", SSLMode: "require"}, "postgres://db.example.com/agentdx?sslmode=require"},
+		{PostgresConfig{DSN: "host=db.example.com dbname=agentdx", SSLMode: "verify-full"}, "host=db.example.com dbname=agentdx sslmode=verify-full"},
+		{PostgresConfig{SSLMode: "require"}, ""},
+	} {
+		if got := tt.pg.ConnString(); got != tt.want {
+			t.Errorf("ConnString(%+v) = %s, want %s", tt.pg, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_Encryption(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Encryption = EncryptionConfig{Enabled: true, Key: "keychain"}
+	if err := cfg.Save(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := loaded.Encryption; e.KeychainService != DefaultKeychainService || e.KeychainAccount != DefaultKeychainAccount {
+		t.Errorf("expected the keychain defaults, got %+v", e)
+	}
+
+	cfg.Encryption.Key = "vault"
+	if err := cfg.Save(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(tmpDir); err == nil {
+		t.Error("expected an unknown key source to be rejected")
+	}
+
+	// Stores that keep source in the clear are refused
+	cfg.Encryption.Key = ""
+	cfg.Index.Store.Backend = "sqlite"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "index.store.backend sqlite") {
+		t.Errorf("expected the SQLite index to be rejected with encryption, got %v", err)
+	}
+	cfg.Index.Store.Backend = "postgres"
+	cfg.Index.Trace.Store = "bolt"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "index.trace.store bolt") {
+		t.Errorf("expected the bolt symbol index to be rejected with encryption, got %v", err)
+	}
+}
+
+func TestBoostRule_Age(t *testing.T) {
+	for _, tt := range []struct {
+		within string
+		want   time.Duration
+		valid  bool
+	}{
+		{"", 0, true},
+		{"30d", 30 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"12h", 12 * time.Hour, true},
+		{"0d", 0, false},
+		{"xd", 0, false},
+		{"soon", 0, false},
+	} {
+		got, err := BoostRule{ModifiedWithin: tt.within}.Age()
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("Age(%q) = %v, %v; want %v, valid=%v", tt.within, got, err, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestLoad_ValidatesBoostRules(t *testing.T) {
+	for _, tt := range []struct {
+		rule  BoostRule
+		valid bool
+	}{
+		{BoostRule{Kind: KindComment, Factor: 0.5}, true},
+		{BoostRule{Pattern: "/api/", ModifiedWithin: "30d", Factor: 1.2}, true},
+		{BoostRule{Kind: "method", Factor: 0.5}, false},
+		{BoostRule{ModifiedWithin: "recently", Factor: 1.2}, false},
+	} {
+		tmpDir := t.TempDir()
+		cfg := DefaultConfig()
+		cfg.Index.Search.Boost.Bonuses = append(cfg.Index.Search.Boost.Bonuses, tt.rule)
+		if err := cfg.Save(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(tmpDir); (err == nil) != tt.valid {
+			t.Errorf("rule %+v: expected valid=%v, got %v", tt.rule, tt.valid, err)
+		}
+	}
+}
+
 func TestConfigExists(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -170,6 +394,56 @@ index:
 			if loaded.Index.Watch.DebounceMs != defaults.Index.Watch.DebounceMs {
 				t.Errorf("expected debounce %d, got %d", defaults.Index.Watch.DebounceMs, loaded.Index.Watch.DebounceMs)
 			}
+
+			if loaded.Index.Search.MaxPerFile != defaults.Index.Search.MaxPerFile {
+				t.Errorf("expected max per file %d, got %d", defaults.Index.Search.MaxPerFile, loaded.Index.Search.MaxPerFile)
+			}
 		})
 	}
 }
+
+func TestIndexFingerprint(t *testing.T) {
+	base := DefaultConfig().IndexFingerprint()
+	if base != DefaultConfig().IndexFingerprint() {
+		t.Error("expected the fingerprint to be stable")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Index.Search.MaxPerFile = 7
+	cfg.Index.Watch.DebounceMs = 1
+	cfg.Index.Chunking.Strip.MaxLines = 10 // unused while stripping is disabled
+	if cfg.IndexFingerprint() != base {
+		t.Error("expected settings unrelated to chunking to keep the fingerprint")
+	}
+
+	for name, change := range map[string]func(*Config){
+		"size":        func(c *Config) { c.Index.Chunking.Size++ },
+		"overlap":     func(c *Config) { c.Index.Chunking.Overlap++ },
+		"strategy":    func(c *Config) { c.Index.Chunking.Strategy = "ast" },
+		"cjk_bigrams": func(c *Config) { c.Index.Search.CJKBigrams = !c.Index.Search.CJKBigrams },
+		"identifiers": func(c *Config) { c.Index.Search.Identifiers.Languages = map[string]bool{"markdown": false} },
+		"strip":       func(c *Config) { c.Index.Chunking.Strip.Headers = true },
+	} {
+		cfg := DefaultConfig()
+		change(cfg)
+		if cfg.IndexFingerprint() == base {
+			t.Errorf("expected changing %s to change the fingerprint", name)
+		}
+	}
+}
+
+func TestMCPConfig_Timeout(t *testing.T) {
+	m := MCPConfig{TimeoutMs: 5000, ToolTimeoutsMs: map[string]int{"agentdx_search": 1000, "agentdx_files": -1}}
+	for tool, want := range map[string]time.Duration{
+		"agentdx_search":      time.Second,
+		"agentdx_files":       0,
+		"agentdx_trace_graph": 5 * time.Second,
+	} {
+		if got := m.Timeout(tool); got != want {
+			t.Errorf("Timeout(%s) = %s, want %s", tool, got, want)
+		}
+	}
+	if got := (MCPConfig{}).Timeout("agentdx_search"); got != DefaultMCPTimeoutMs*time.Millisecond {
+		t.Errorf("default timeout = %s", got)
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// IndexFingerprint identifies the settings that decide how files are split
+// into chunks and tokenized. It is stored with the index, so that an index
+// built with other settings can be detected and rebuilt.
+func (c *Config) IndexFingerprint() string {
+	chunking := c.Index.Chunking
+	key := fmt.Sprintf("chunking.size=%d\nchunking.overlap=%d\nchunking.strategy=%s\nsearch.cjk_bigrams=%t\n",
+		chunking.Size, chunking.Overlap, chunking.Strategy, c.Index.Search.CJKBigrams)
+	// Identifier splitting is only part of the key when enabled, keeping the
+	// fingerprint of indexes built before it existed
+	if ids := c.Index.Search.Identifiers; ids.Split || len(ids.Languages) > 0 {
+		key += fmt.Sprintf("search.identifiers.split=%t\n", ids.Split)
+		for _, lang := range slices.Sorted(maps.Keys(ids.Languages)) {
+			key += fmt.Sprintf("search.identifiers.languages.%s=%t\n", lang, ids.Languages[lang])
+		}
+	}
+	// So is header stripping
+	if strip := chunking.Strip; strip.Enabled() {
+		key += fmt.Sprintf("chunking.strip.headers=%t\nchunking.strip.comment_lines=%d\nchunking.strip.max_lines=%d\n",
+			strip.Headers, strip.CommentLines, strip.MaxLines)
+		for _, p := range strip.Patterns {
+			key += fmt.Sprintf("chunking.strip.pattern=%s\n", p)
+		}
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
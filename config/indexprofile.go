@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IndexProfileEnv selects the indexing profile when no --profile flag is
+// given, e.g. in the environment of the session hooks.
+const IndexProfileEnv = "AGENTDX_PROFILE"
+
+// SelectedIndexProfile returns the name of the indexing profile selected by
+// flag, or by AGENTDX_PROFILE when flag is empty. An empty name selects the
+// whole project.
+func SelectedIndexProfile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv(IndexProfileEnv)
+}
+
+// IndexProfile returns the path globs of the named indexing profile.
+func (c *Config) IndexProfile(name string) ([]string, error) {
+	patterns, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown indexing profile %q: no profiles are configured", name)
+		}
+		return nil, fmt.Errorf("unknown indexing profile %q (configured: %s)", name, strings.Join(names, ", "))
+	}
+	return patterns, nil
+}
+
+// validateIndexProfiles rejects empty profiles and invalid globs.
+func (c *Config) validateIndexProfiles() error {
+	for name, patterns := range c.Profiles {
+		if len(patterns) == 0 {
+			return fmt.Errorf("profiles.%s: list at least one path glob", name)
+		}
+		for _, p := range patterns {
+			if p == "" || strings.HasPrefix(p, "/") || !doublestar.ValidatePattern(p) {
+				return fmt.Errorf("profiles.%s: invalid path glob %q", name, p)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndexProfile(t *testing.T) {
+	cfg := &Config{Profiles: map[string][]string{
+		"backend":  {"services/**", "pkg/**"},
+		"frontend": {"web/**"},
+	}}
+
+	patterns, err := cfg.IndexProfile("backend")
+	if err != nil || len(patterns) != 2 {
+		t.Fatalf("IndexProfile(backend) = %v, %v", patterns, err)
+	}
+	_, err = cfg.IndexProfile("mobile")
+	if err == nil || !strings.Contains(err.Error(), "backend, frontend") {
+		t.Errorf("expected the configured profiles to be listed, got %v", err)
+	}
+	if _, err := (&Config{}).IndexProfile("backend"); err == nil {
+		t.Error("expected an error without profiles")
+	}
+}
+
+func TestValidateIndexProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles map[string][]string
+		wantErr  bool
+	}{
+		{"valid", map[string][]string{"backend": {"services/**", "pkg"}}, false},
+		{"empty profile", map[string][]string{"backend": {}}, true},
+		{"absolute glob", map[string][]string{"backend": {"/services/**"}}, true},
+		{"invalid glob", map[string][]string{"backend": {"services/[a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Profiles: tt.profiles}).validateIndexProfiles()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateIndexProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectedIndexProfile(t *testing.T) {
+	t.Setenv(IndexProfileEnv, "frontend")
+	if got := SelectedIndexProfile("backend"); got != "backend" {
+		t.Errorf("expected the flag to win, got %q", got)
+	}
+	if got := SelectedIndexProfile(""); got != "frontend" {
+		t.Errorf("expected the environment profile, got %q", got)
+	}
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// keyChoices lists the settings that take one of a fixed set of values.
+// Empty values fall back to a default.
+var keyChoices = []struct {
+	key     string
+	choices []string
+}{
+	{"index.store.backend", []string{"postgres", "sqlite"}},
+	{"index.chunking.strategy", []string{"size", "ast"}},
+	{"index.trace.mode", []string{"fast", "precise"}},
+	{"index.trace.store", []string{"gob", "bolt", "postgres"}},
+	{"index.watch.backend", []string{"auto", "fsnotify", "watchman"}},
+	{"session.log.format", []string{"json", "text"}},
+	{"encryption.key", []string{"env", "keychain"}},
+	{"index.store.postgres.sslmode", []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}},
+}
+
+// joinChoices returns choices as "a, b or c".
+func joinChoices(choices []string) string {
+	if len(choices) == 1 {
+		return choices[0]
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
+
+// Get returns the setting at a dotted key such as index.search.max_per_file.
+// Keys below a map, like profiles.frontend, return nil when they are not
+// set.
+func (c *Config) Get(key string) (any, error) {
+	v := reflect.ValueOf(c).Elem()
+	segments := strings.Split(key, ".")
+	for i, seg := range segments {
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := yamlField(v.Type(), seg)
+			if !ok {
+				return nil, unknownKey(v.Type(), segments[:i+1])
+			}
+			v = v.FieldByIndex(field.Index)
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(seg))
+			if !v.IsValid() {
+				return nil, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown config key %q: %s is not a section", key, strings.Join(segments[:i], "."))
+		}
+	}
+	return v.Interface(), nil
+}
+
+// keyType returns the type of the setting at a dotted key.
+func keyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	segments := strings.Split(key, ".")
+	for i, seg := range segments {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := yamlField(t, seg)
+			if !ok {
+				return nil, unknownKey(t, segments[:i+1])
+			}
+			t = field.Type
+		case reflect.Map:
+			if seg == "" {
+				return nil, fmt.Errorf("unknown config key %q: empty name", key)
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("unknown config key %q: %s is not a section", key, strings.Join(segments[:i], "."))
+		}
+	}
+	return t, nil
+}
+
+// yamlField returns the field of struct type t stored under the YAML key
+// name.
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); tag == name && tag != "-" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// unknownKey returns the error for the unknown last segment of a key,
+// listing the keys of its section.
+func unknownKey(section reflect.Type, segments []string) error {
+	var names []string
+	for i := 0; i < section.NumField(); i++ {
+		f := section.Field(i)
+		if tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); f.IsExported() && tag != "" && tag != "-" {
+			names = append(names, tag)
+		}
+	}
+	where := "the configuration"
+	if len(segments) > 1 {
+		where = strings.Join(segments[:len(segments)-1], ".")
+	}
+	return fmt.Errorf("unknown config key %q: %s has %s", strings.Join(segments, "."), where, strings.Join(names, ", "))
+}
+
+// parseValue converts a command line value to the type of a setting.
+// Lists of strings are comma-separated or YAML flow sequences; other lists,
+// maps and sections are YAML.
+func parseValue(t reflect.Type, s string) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", s)
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return reflect.ValueOf(f).Convert(t).Interface(), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(s), "[") {
+			items := []string{}
+			for _, item := range strings.Split(s, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return items, nil
+		}
+	}
+
+	v := reflect.New(t)
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	dec.KnownFields(true)
+	if err := dec.Decode(v.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", t, err)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// SetKey sets the setting at a dotted key of the project configuration to
+// value, converted to the setting's type, and returns the configuration
+// it saved. The file keeps its comments and other settings. Nothing is
+// written when the key or value is invalid (errcode.InvalidArgs) or the
+// resulting configuration is not (errcode.Config).
+func SetKey(projectRoot, key, value string) (*Config, error) {
+	t, err := keyType(key)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.InvalidArgs, err)
+	}
+	v, err := parseValue(t, value)
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidArgs, "%s: %v", key, err)
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	return editConfig(projectRoot, func(doc *yaml.Node) (bool, error) {
+		section := doc
+		segments := strings.Split(key, ".")
+		for i, seg := range segments[:len(segments)-1] {
+			next := mappingValue(section, seg)
+			if next == nil {
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: seg}, next)
+			} else if next.Kind != yaml.MappingNode {
+				return false, errcode.New(errcode.InvalidArgs, "%s is not a section in the config file", strings.Join(segments[:i+1], "."))
+			}
+			section = next
+		}
+		last := segments[len(segments)-1]
+		if cur := mappingValue(section, last); cur != nil {
+			// Keep the comments attached to the value
+			node.HeadComment, node.LineComment, node.FootComment = cur.HeadComment, cur.LineComment, cur.FootComment
+			*cur = node
+			return true, nil
+		}
+		section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, &node)
+		return true, nil
+	})
+}
+
+// UnsetKey removes the setting at a dotted key from the project
+// configuration, so that its default applies, and returns the
+// configuration it saved. It reports false when the key was not set.
+func UnsetKey(projectRoot, key string) (*Config, bool, error) {
+	if _, err := keyType(key); err != nil {
+		return nil, false, errcode.Wrap(errcode.InvalidArgs, err)
+	}
+	removed := false
+	cfg, err := editConfig(projectRoot, func(doc *yaml.Node) (bool, error) {
+		section := doc
+		segments := strings.Split(key, ".")
+		for _, seg := range segments[:len(segments)-1] {
+			if section = mappingValue(section, seg); section == nil || section.Kind != yaml.MappingNode {
+				return false, nil
+			}
+		}
+		last := segments[len(segments)-1]
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			if section.Content[i].Value == last {
+				section.Content = slices.Delete(section.Content, i, i+2)
+				removed = true
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return cfg, removed, err
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// editConfig applies edit to the top-level mapping of the project's config
+// file, validates the result like Load and writes it back atomically. edit
+// reports whether it changed the mapping; if not, the file is left as is.
+func editConfig(projectRoot string, edit func(doc *yaml.Node) (bool, error)) (*Config, error) {
+	path := GetConfigPath(projectRoot)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, fmt.Errorf("failed to read config file: %w", err))
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errcode.Wrap(errcode.Config, fmt.Errorf("failed to parse config file: %w", err))
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errcode.New(errcode.Config, "config file %s is not a YAML mapping", path)
+	}
+	changed, err := edit(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return Load(projectRoot)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	cfg, err := parse(out, projectRoot, false)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return cfg, nil
+}
+
+// ValidateFile loads the project configuration like Load, and also
+// rejects keys that are not part of the configuration, such as misspelled
+// settings Load ignores. Its errors carry the errcode.Config code.
+func ValidateFile(projectRoot string) (*Config, error) {
+	data, err := os.ReadFile(GetConfigPath(projectRoot))
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, fmt.Errorf("failed to read config file: %w", err))
+	}
+	cfg, err := parse(data, projectRoot, true)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.Config, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	if err := DefaultConfig().Save(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(GetConfigPath(tmpDir), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return tmpDir
+}
+
+func TestConfigGet(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string][]string{"frontend": {"web/**"}}
+
+	for _, tt := range []struct {
+		key  string
+		want any
+	}{
+		{"index.search.max_per_file", 3},
+		{"index.trace.mode", "fast"},
+		{"index.search.boost.enabled", true},
+		{"profiles.frontend", []string{"web/**"}},
+		{"profiles.backend", nil},
+	} {
+		got, err := cfg.Get(tt.key)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", tt.key, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Get(%q) = %#v, want %#v", tt.key, got, tt.want)
+		}
+	}
+
+	_, err := cfg.Get("index.serch")
+	if err == nil || !strings.Contains(err.Error(), "index has") || !strings.Contains(err.Error(), "search") {
+		t.Errorf("expected an error listing the index keys, got %v", err)
+	}
+	if _, err := cfg.Get("index.search.max_per_file.x"); err == nil {
+		t.Error("expected an error for a key below a setting")
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	tmpDir := writeConfigFile(t, `# project settings
+version: 1
+index:
+  search:
+    max_per_file: 3 # keep results diverse
+`)
+
+	cfg, err := SetKey(tmpDir, "index.search.max_per_file", "5")
+	if err != nil {
+		t.Fatalf("SetKey failed: %v", err)
+	}
+	if cfg.Index.Search.MaxPerFile != 5 {
+		t.Errorf("expected max_per_file 5, got %d", cfg.Index.Search.MaxPerFile)
+	}
+	if _, err := SetKey(tmpDir, "index.ignore", "vendor, dist"); err != nil {
+		t.Fatalf("SetKey failed: %v", err)
+	}
+	if _, err := SetKey(tmpDir, "profiles.frontend", "[web/**, ui/**]"); err != nil {
+		t.Fatalf("SetKey failed: %v", err)
+	}
+
+	data, err := os.ReadFile(GetConfigPath(tmpDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# project settings", "max_per_file: 5 # keep results diverse"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q to be kept in:\n%s", want, data)
+		}
+	}
+	loaded, err := Load(tmpDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Index.Ignore, []string{"vendor", "dist"}) {
+		t.Errorf("unexpected ignore list %v", loaded.Index.Ignore)
+	}
+	if !reflect.DeepEqual(loaded.Profiles["frontend"], []string{"web/**", "ui/**"}) {
+		t.Errorf("unexpected profile %v", loaded.Profiles["frontend"])
+	}
+}
+
+func TestSetKey_Invalid(t *testing.T) {
+	const content = "version: 1\nindex:\n  trace:\n    mode: fast\n"
+	tmpDir := writeConfigFile(t, content)
+
+	for _, tt := range []struct {
+		key, value string
+		code       errcode.Code
+	}{
+		{"index.serch.max_per_file", "5", errcode.InvalidArgs},
+		{"index.search.max_per_file", "five", errcode.InvalidArgs},
+		{"index.trace.mode", "turbo", errcode.Config},
+	} {
+		_, err := SetKey(tmpDir, tt.key, tt.value)
+		if err == nil {
+			t.Errorf("SetKey(%q, %q): expected an error", tt.key, tt.value)
+			continue
+		}
+		if got := errcode.Of(err); got != tt.code {
+			t.Errorf("SetKey(%q, %q): expected code %s, got %s (%v)", tt.key, tt.value, tt.code, got, err)
+		}
+	}
+
+	data, err := os.ReadFile(GetConfigPath(tmpDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("config file changed after invalid sets:\n%s", data)
+	}
+}
+
+func TestUnsetKey(t *testing.T) {
+	tmpDir := writeConfigFile(t, "version: 1\nindex:\n  search:\n    max_per_file: 7\n")
+
+	cfg, removed, err := UnsetKey(tmpDir, "index.search.max_per_file")
+	if err != nil {
+		t.Fatalf("UnsetKey failed: %v", err)
+	}
+	if !removed {
+		t.Error("expected the key to be removed")
+	}
+	if cfg.Index.Search.MaxPerFile != DefaultConfig().Index.Search.MaxPerFile {
+		t.Errorf("expected the default max_per_file, got %d", cfg.Index.Search.MaxPerFile)
+	}
+
+	before, _ := os.ReadFile(GetConfigPath(tmpDir))
+	if _, removed, err := UnsetKey(tmpDir, "index.search.max_per_file"); err != nil || removed {
+		t.Errorf("expected nothing to remove, got removed=%v err=%v", removed, err)
+	}
+	after, _ := os.ReadFile(GetConfigPath(tmpDir))
+	if string(before) != string(after) {
+		t.Error("config file rewritten although nothing was removed")
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	tmpDir := writeConfigFile(t, "version: 1\nindex:\n  search:\n    max_per_file: 3\n")
+	if _, err := ValidateFile(tmpDir); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+
+	// Load ignores unknown keys, ValidateFile reports them
+	tmpDir = writeConfigFile(t, "version: 1\nindex:\n  serch:\n    max_per_file: 3\n")
+	if _, err := Load(tmpDir); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	_, err := ValidateFile(tmpDir)
+	if err == nil || !strings.Contains(err.Error(), "serch") {
+		t.Errorf("expected an error naming the unknown key, got %v", err)
+	}
+	if got := errcode.Of(err); got != errcode.Config {
+		t.Errorf("expected code %s, got %s", errcode.Config, got)
+	}
+}
+
+func TestLoad_ValidatesChoices(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		want    string
+	}{
+		{"index:\n  store:\n    backend: mysql\n", "index.store.backend"},
+		{"index:\n  chunking:\n    strategy: lines\n", "index.chunking.strategy"},
+		{"index:\n  trace:\n    store: redis\n", "index.trace.store"},
+	} {
+		tmpDir := writeConfigFile(t, "version: 1\n"+tt.content)
+		_, err := Load(tmpDir)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected an error for %s, got %v", tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RankingProfile is the shareable part of the search settings. Profiles are
+// stored in the index backend and selected with index.search.profile.
+type RankingProfile struct {
+	Boost              BoostConfig     `yaml:"boost"`
+	PreferSourceOnTies bool            `yaml:"prefer_source_on_ties"`
+	CJKBigrams         bool            `yaml:"cjk_bigrams"`
+	MaxPerFile         int             `yaml:"max_per_file"`
+	Expansion          ExpansionConfig `yaml:"expansion"`
+}
+
+// RankingProfile returns the ranking settings of c.
+func (c SearchConfig) RankingProfile() RankingProfile {
+	return RankingProfile{
+		Boost:              c.Boost,
+		PreferSourceOnTies: c.PreferSourceOnTies,
+		CJKBigrams:         c.CJKBigrams,
+		MaxPerFile:         c.MaxPerFile,
+		Expansion:          c.Expansion,
+	}
+}
+
+// ApplyProfile replaces the ranking settings of c with those of p.
+func (c *SearchConfig) ApplyProfile(p RankingProfile) {
+	c.Boost = p.Boost
+	c.PreferSourceOnTies = p.PreferSourceOnTies
+	c.CJKBigrams = p.CJKBigrams
+	c.MaxPerFile = p.MaxPerFile
+	c.Expansion = p.Expansion
+	if c.MaxPerFile == 0 {
+		c.MaxPerFile = DefaultConfig().Index.Search.MaxPerFile
+	}
+}
+
+// Marshal encodes the profile as YAML.
+func (p RankingProfile) Marshal() (string, error) {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ranking profile: %w", err)
+	}
+	return string(data), nil
+}
+
+// ParseRankingProfile decodes a YAML-encoded profile.
+func ParseRankingProfile(data string) (*RankingProfile, error) {
+	var p RankingProfile
+	if err := yaml.Unmarshal([]byte(data), &p); err != nil {
+		return nil, fmt.Errorf("failed to parse ranking profile: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import "testing"
+
+func TestRankingProfile_RoundTrip(t *testing.T) {
+	search := DefaultConfig().Index.Search
+	search.PreferSourceOnTies = true
+	search.CJKBigrams = true
+	search.MaxPerFile = 5
+	search.Expansion = ExpansionConfig{Enabled: true, Synonyms: [][]string{{"tenant", "org"}}}
+
+	data, err := search.RankingProfile().Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	profile, err := ParseRankingProfile(data)
+	if err != nil {
+		t.Fatalf("ParseRankingProfile failed: %v", err)
+	}
+
+	var applied SearchConfig
+	applied.Profile = "team"
+	applied.ApplyProfile(*profile)
+	if !applied.PreferSourceOnTies || !applied.CJKBigrams || applied.MaxPerFile != 5 {
+		t.Errorf("unexpected applied settings: %+v", applied)
+	}
+	if !applied.Expansion.Enabled || len(applied.Expansion.Synonyms) != 1 {
+		t.Errorf("expected expansion settings to be applied, got %+v", applied.Expansion)
+	}
+	if len(applied.Boost.Penalties) != len(search.Boost.Penalties) {
+		t.Errorf("expected %d penalties, got %d", len(search.Boost.Penalties), len(applied.Boost.Penalties))
+	}
+	if applied.Profile != "team" {
+		t.Errorf("expected profile selection to be kept, got %q", applied.Profile)
+	}
+}
+
+func TestRankingProfile_DefaultMaxPerFile(t *testing.T) {
+	profile, err := ParseRankingProfile("prefer_source_on_ties: true\n")
+	if err != nil {
+		t.Fatalf("ParseRankingProfile failed: %v", err)
+	}
+
+	var applied SearchConfig
+	applied.ApplyProfile(*profile)
+	if applied.MaxPerFile != DefaultConfig().Index.Search.MaxPerFile {
+		t.Errorf("expected default max per file, got %d", applied.MaxPerFile)
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Project ID strategies.
+const (
+	// ProjectIDPath identifies a project by its absolute path (default)
+	ProjectIDPath = "path"
+	// ProjectIDUUID identifies a project by the UUID stored in project.id
+	ProjectIDUUID = "uuid"
+	// ProjectIDGit identifies a project by the URL of its origin remote
+	ProjectIDGit = "git"
+)
+
+// ProjectIDStrategies lists the supported project ID strategies.
+var ProjectIDStrategies = []string{ProjectIDPath, ProjectIDUUID, ProjectIDGit}
+
+// ProjectConfig sets how the project is identified in the index. Rows of
+// every project sharing a database are keyed by this ID.
+type ProjectConfig struct {
+	IDStrategy string `yaml:"id_strategy,omitempty"` // path (default), uuid or git
+	ID         string `yaml:"id,omitempty"`          // Project UUID for the uuid strategy
+}
+
+// ProjectID returns the ID the project at projectRoot is indexed under.
+// The path strategy keeps the absolute path, which changes when the
+// repository moves; uuid and git IDs are the same in every clone.
+func (c *Config) ProjectID(projectRoot string) string {
+	switch c.Project.IDStrategy {
+	case ProjectIDUUID:
+		return c.Project.ID
+	case ProjectIDGit:
+		if c.gitProjectID != "" {
+			return c.gitProjectID
+		}
+		// Load resolves the remote; only configs built in code get here
+		if id, err := GitRemoteID(projectRoot); err == nil {
+			return id
+		}
+	}
+	return projectRoot
+}
+
+// resolveProjectID validates the project ID strategy and resolves the git
+// remote once for the git strategy.
+func (c *Config) resolveProjectID(projectRoot string) error {
+	switch c.Project.IDStrategy {
+	case "", ProjectIDPath:
+		return nil
+	case ProjectIDUUID:
+		if c.Project.ID == "" {
+			return fmt.Errorf("project.id is required with id_strategy uuid; run 'agentdx projects set-id --strategy uuid'")
+		}
+		return nil
+	case ProjectIDGit:
+		id, err := GitRemoteID(projectRoot)
+		if err != nil {
+			return fmt.Errorf("project.id_strategy git: %w", err)
+		}
+		c.gitProjectID = id
+		return nil
+	default:
+		return fmt.Errorf("project.id_strategy %q: use one of %s", c.Project.IDStrategy, strings.Join(ProjectIDStrategies, ", "))
+	}
+}
+
+// GitRemoteID returns the project ID derived from the origin remote of the
+// git repository at projectRoot, e.g. github.com/org/repo.
+func GitRemoteID(projectRoot string) (string, error) {
+	out, err := exec.Command("git", "-C", projectRoot, "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the origin remote of %s (is it a git repository with an origin remote?): %w", projectRoot, err)
+	}
+	id := NormalizeRemoteURL(strings.TrimSpace(string(out)))
+	if id == "" {
+		return "", fmt.Errorf("%s has no origin remote", projectRoot)
+	}
+	return id, nil
+}
+
+// NormalizeRemoteURL turns the HTTPS, SSH and scp-like URLs of a remote
+// into host/path, without credentials, port or .git suffix, so that every
+// clone of a repository gets the same ID.
+func NormalizeRemoteURL(remote string) string {
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(remote, ":"); at > 0 && !strings.Contains(remote[:at], "/") {
+		// scp-like syntax: [user@]host:path
+		host, path = remote[:at], remote[at+1:]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		// A local path remote
+		return filepath.ToSlash(strings.TrimSuffix(remote, ".git"))
+	}
+
+	path = strings.Trim(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if host == "" || path == "" {
+		return ""
+	}
+	return strings.ToLower(host) + "/" + path
+}
+
+// NewProjectUUID returns a random (version 4) UUID for project.id.
+func NewProjectUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate project ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
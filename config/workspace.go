@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceConfig declares a sub-project of a monorepo. Files under Path are
+// indexed with the workspace's own project ID instead of the root's.
+type WorkspaceConfig struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"` // relative to the project root
+}
+
+// Workspace returns the workspace with the given name.
+func (c *Config) Workspace(name string) (*WorkspaceConfig, error) {
+	for i := range c.Workspaces {
+		if c.Workspaces[i].Name == name {
+			return &c.Workspaces[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown workspace: %s", name)
+}
+
+// WorkspaceFor returns the workspace containing relPath, or nil if the file
+// belongs to the root project. Nested workspaces resolve to the deepest one.
+func (c *Config) WorkspaceFor(relPath string) *WorkspaceConfig {
+	relPath = filepath.ToSlash(relPath)
+
+	var best *WorkspaceConfig
+	for i := range c.Workspaces {
+		ws := &c.Workspaces[i]
+		prefix := ws.slashPath()
+		if relPath != prefix && !strings.HasPrefix(relPath, prefix+"/") {
+			continue
+		}
+		if best == nil || len(prefix) > len(best.slashPath()) {
+			best = ws
+		}
+	}
+	return best
+}
+
+// ProjectID returns the project ID the workspace is indexed under: its path
+// within the project appended to the project's ID (see Config.ProjectID).
+// With the path strategy this is the workspace's absolute path, the same
+// way a standalone project is identified.
+func (w *WorkspaceConfig) ProjectID(projectID string) string {
+	if filepath.IsAbs(projectID) {
+		return filepath.Join(projectID, filepath.FromSlash(w.slashPath()))
+	}
+	return projectID + "/" + w.slashPath()
+}
+
+func (w *WorkspaceConfig) slashPath() string {
+	return strings.TrimSuffix(filepath.ToSlash(filepath.Clean(w.Path)), "/")
+}
+
+// validateWorkspaces rejects unnamed, duplicate or out-of-tree workspaces.
+func (c *Config) validateWorkspaces() error {
+	seen := make(map[string]bool)
+	for _, ws := range c.Workspaces {
+		if ws.Name == "" {
+			return fmt.Errorf("workspace with path %q has no name", ws.Path)
+		}
+		if seen[ws.Name] {
+			return fmt.Errorf("duplicate workspace name: %s", ws.Name)
+		}
+		seen[ws.Name] = true
+
+		p := ws.slashPath()
+		if ws.Path == "" || p == "." || filepath.IsAbs(ws.Path) || p == ".." || strings.HasPrefix(p, "../") {
+			return fmt.Errorf("workspace %s: path must be a sub-directory of the project root, got %q", ws.Name, ws.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceFor(t *testing.T) {
+	cfg := &Config{Workspaces: []WorkspaceConfig{
+		{Name: "api", Path: "services/api"},
+		{Name: "api-v2", Path: "services/api/v2/"},
+		{Name: "web", Path: "./apps/web"},
+	}}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"services/api/main.go", "api"},
+		{"services/api/v2/handler.go", "api-v2"},
+		{"services/apiextra/main.go", ""},
+		{"apps/web/index.ts", "web"},
+		{"README.md", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if ws := cfg.WorkspaceFor(tt.path); ws != nil {
+			got = ws.Name
+		}
+		if got != tt.want {
+			t.Errorf("WorkspaceFor(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceLookup(t *testing.T) {
+	cfg := &Config{Workspaces: []WorkspaceConfig{{Name: "api", Path: "services/api/"}}}
+
+	ws, err := cfg.Workspace("api")
+	if err != nil {
+		t.Fatalf("Workspace failed: %v", err)
+	}
+	want := filepath.Join("/repo", "services", "api")
+	if got := ws.ProjectID("/repo"); got != want {
+		t.Errorf("expected project ID %s, got %s", want, got)
+	}
+	if got := ws.ProjectID("github.com/org/repo"); got != "github.com/org/repo/services/api" {
+		t.Errorf("expected a slash-joined ID for a remote project ID, got %s", got)
+	}
+
+	if _, err := cfg.Workspace("missing"); err == nil {
+		t.Error("expected error for unknown workspace")
+	}
+}
+
+func TestValidateWorkspaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		ws      []WorkspaceConfig
+		wantErr bool
+	}{
+		{"valid", []WorkspaceConfig{{Name: "a", Path: "pkg/a"}, {Name: "b", Path: "pkg/b"}}, false},
+		{"missing name", []WorkspaceConfig{{Path: "pkg/a"}}, true},
+		{"duplicate name", []WorkspaceConfig{{Name: "a", Path: "pkg/a"}, {Name: "a", Path: "pkg/b"}}, true},
+		{"empty path", []WorkspaceConfig{{Name: "a"}}, true},
+		{"root path", []WorkspaceConfig{{Name: "a", Path: "."}}, true},
+		{"outside root", []WorkspaceConfig{{Name: "a", Path: "../other"}}, true},
+		{"absolute path", []WorkspaceConfig{{Name: "a", Path: "/srv/a"}}, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Workspaces: tt.ws}
+		if err := cfg.validateWorkspaces(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateWorkspaces() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidWorkspaces(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(GetConfigDir(dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("version: 1\nworkspaces:\n  - name: api\n    path: ../api\n")
+	if err := os.WriteFile(GetConfigPath(dir), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); err == nil {
+		t.Error("expected Load to reject a workspace outside the project root")
+	}
+}
@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxActivity is the number of recent file events kept for pages opened
+// after them.
+const maxActivity = 100
+
+// Actions of activity events.
+const (
+	ActivityIndexed = "indexed"
+	ActivityRemoved = "removed"
+	ActivityFailed  = "failed"
+)
+
+// Activity is a file event processed by the watch daemon, shown in the live
+// activity feed of the dashboard. Error is also set on indexed files whose
+// symbols could not be extracted.
+type Activity struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	File       string    `json:"file"`
+	Chunks     int       `json:"chunks,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// activityLog keeps the most recent activity.
+type activityLog struct {
+	mu     sync.Mutex
+	events []Activity // oldest first
+}
+
+func (l *activityLog) add(a Activity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, a)
+	if len(l.events) > maxActivity {
+		l.events = slices.Delete(l.events, 0, len(l.events)-maxActivity)
+	}
+}
+
+// recent returns the activity kept, newest first.
+func (l *activityLog) recent() []Activity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := slices.Clone(l.events)
+	slices.Reverse(events)
+	return events
+}
+
+// BroadcastActivity records a file event of the watch daemon and sends it
+// to the connected clients as an "activity" event.
+func (s *Server) BroadcastActivity(a Activity) {
+	s.activity.add(a)
+	s.sseHub.Broadcast("activity", a)
+}
+
+// handleAPIActivity handles GET /api/activity, the recent activity, newest
+// first.
+func (s *Server) handleAPIActivity(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.activity.recent())
+}
@@ -3,6 +3,8 @@ package dashboard
 import (
 	"context"
 	"encoding/json"
+	"html"
+	"html/template"
 	"net/http"
 	"sort"
 	"strconv"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
 	"github.com/doveaia/agentdx/trace"
 	"github.com/go-chi/chi/v5"
 )
@@ -30,13 +33,42 @@ type StatusResponse struct {
 	BackendOK    bool   `json:"backend_ok,omitempty"`
 }
 
+// SearchResponse is the API response for a search.
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+	// Warnings are failures the search did without, such as the reranker
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // SearchResult represents a search result.
 type SearchResult struct {
-	FilePath  string  `json:"file_path"`
-	StartLine int     `json:"start_line"`
-	EndLine   int     `json:"end_line"`
-	Score     float32 `json:"score"`
-	Content   string  `json:"content"`
+	FilePath   string            `json:"file_path"`
+	StartLine  int               `json:"start_line"`
+	EndLine    int               `json:"end_line"`
+	Score      float32           `json:"score"`
+	Content    string            `json:"content"`
+	Highlights []store.Highlight `json:"highlights,omitempty"`
+	Blame      *store.Blame      `json:"blame,omitempty"` // last commit of the matched lines
+}
+
+// HighlightedContent returns the content as HTML with the terms matching
+// the query wrapped in <mark>.
+func (r SearchResult) HighlightedContent() template.HTML {
+	lines := strings.Split(r.Content, "\n")
+	// Content lines are numbered from StartLine after the "File:" header
+	first := 0
+	if strings.HasPrefix(r.Content, "File: ") && strings.Contains(r.Content, "\n\n") {
+		first = 2
+	}
+	mark := func(s string) string { return "<mark>" + html.EscapeString(s) + "</mark>" }
+	for i, line := range lines {
+		if i < first {
+			lines[i] = html.EscapeString(line)
+			continue
+		}
+		lines[i] = search.MarkLine(line, r.StartLine+i-first, r.Highlights, html.EscapeString, mark)
+	}
+	return template.HTML(strings.Join(lines, "\n"))
 }
 
 // FileResult represents a file in the index.
@@ -81,13 +113,13 @@ func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	results, err := s.performSearch(ctx, query, limit)
+	resp, err := s.performSearch(ctx, query, limit, s.blameRequested(r))
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, results)
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // handleAPIFiles handles GET /api/files
@@ -160,7 +192,7 @@ func (s *Server) handleAPIProjects(w http.ResponseWriter, r *http.Request) {
 // getStatus returns the current index status.
 func (s *Server) getStatus(ctx context.Context) *StatusResponse {
 	status := &StatusResponse{
-		Search: "PostgreSQL FTS",
+		Search: store.SearchName(s.config.Index.Store.Backend),
 	}
 
 	// Get store stats
@@ -192,39 +224,49 @@ func (s *Server) getStatus(ctx context.Context) *StatusResponse {
 	return status
 }
 
-// performSearch performs a search query.
-func (s *Server) performSearch(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+// blameRequested reports whether the blame parameter of r, or
+// index.search.blame without one, asks for the last commit of each result.
+func (s *Server) blameRequested(r *http.Request) bool {
+	if v := r.URL.Query().Get("blame"); v != "" {
+		blame, err := strconv.ParseBool(v)
+		return err == nil && blame
+	}
+	return s.config.Index.Search.Blame
+}
+
+// performSearch performs a search query, annotating results with the last
+// commit of their matched lines when blame is set.
+func (s *Server) performSearch(ctx context.Context, query string, limit int, blame bool) (*SearchResponse, error) {
 	if s.store == nil {
-		return nil, nil
+		return &SearchResponse{}, nil
 	}
 
-	// Search using FTS
-	results, err := s.store.SearchFTS(ctx, query, limit*2)
+	resp, err := search.Run(ctx, s.store, s.config.Index.Search, search.Request{
+		Query:       query,
+		Limit:       limit,
+		Blame:       blame,
+		ProjectRoot: s.projectRoot,
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Apply structural boosting
-	results = search.ApplyBoost(results, s.config.Index.Search.Boost)
-
-	// Trim to requested limit
-	if len(results) > limit {
-		results = results[:limit]
-	}
+	results := resp.Results
 
 	// Convert to lightweight results
 	searchResults := make([]SearchResult, len(results))
 	for i, r := range results {
 		searchResults[i] = SearchResult{
-			FilePath:  r.Chunk.FilePath,
-			StartLine: r.Chunk.StartLine,
-			EndLine:   r.Chunk.EndLine,
-			Score:     r.Score,
-			Content:   r.Chunk.Content,
+			FilePath:   r.Chunk.FilePath,
+			StartLine:  r.Chunk.StartLine,
+			EndLine:    r.Chunk.EndLine,
+			Score:      r.Score,
+			Content:    r.Chunk.Content,
+			Highlights: r.Highlights,
+			Blame:      r.Blame,
 		}
 	}
 
-	return searchResults, nil
+	return &SearchResponse{Results: searchResults, Warnings: resp.WarningTexts()}, nil
 }
 
 // listFiles lists files matching a pattern.
@@ -0,0 +1,37 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requireAuth rejects requests that present neither the bearer token nor
+// basic auth credentials with the token as password. Browsers are asked for
+// the latter; the user name is ignored. An empty token disables
+// authentication.
+func requireAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if token == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r, token) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="agentdx", charset="UTF-8"`)
+				http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorized reports whether r carries token as a bearer token or as the
+// basic auth password.
+func authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, got, ok = r.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -15,14 +15,17 @@ type PageData struct {
 // IndexData holds data for the index page.
 type IndexData struct {
 	PageData
-	Status *StatusResponse
+	Status   *StatusResponse
+	Activity []Activity
 }
 
 // SearchPageData holds data for the search page.
 type SearchPageData struct {
 	PageData
-	Query   string
-	Results []SearchResult
+	Query    string
+	Blame    bool // show the last commit of each result
+	Results  []SearchResult
+	Warnings []string // failures the search did without
 }
 
 // FilesPageData holds data for the files page.
@@ -81,7 +84,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 			CurrentPage: "index",
 			ProjectRoot: s.projectRoot,
 		},
-		Status: status,
+		Status:   status,
+		Activity: s.activity.recent(),
 	}
 
 	s.renderTemplate(w, "index.html", data)
@@ -98,14 +102,15 @@ func (s *Server) handleSearchPage(w http.ResponseWriter, r *http.Request) {
 			ProjectRoot: s.projectRoot,
 		},
 		Query: query,
+		Blame: s.blameRequested(r),
 	}
 
 	// If query provided, perform search
 	if query != "" {
 		ctx := r.Context()
-		results, err := s.performSearch(ctx, query, 20)
+		resp, err := s.performSearch(ctx, query, 20, data.Blame)
 		if err == nil {
-			data.Results = results
+			data.Results, data.Warnings = resp.Results, resp.Warnings
 		}
 	}
 
@@ -248,6 +253,13 @@ func getMCPTools() []MCPTool {
 				{Name: "depth", Type: "number", Required: false, Description: "Max depth (default: 2)"},
 			},
 		},
+		{
+			Name:        "agentdx_trace_implementations",
+			Description: "Find the Go types implementing an interface or interface method.",
+			Parameters: []MCPParameter{
+				{Name: "symbol", Type: "string", Required: true, Description: "Interface, interface method or Interface.Method"},
+			},
+		},
 		{
 			Name:        "agentdx_index_status",
 			Description: "Check the health and status of the agentdx index.",
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/internal/netutil"
 	"github.com/doveaia/agentdx/store"
 	"github.com/doveaia/agentdx/trace"
 	"github.com/go-chi/chi/v5"
@@ -25,23 +26,26 @@ var templatesFS embed.FS
 type Server struct {
 	config      *config.Config
 	projectRoot string
-	store       *store.PostgresFTSStore
-	symbolStore *trace.GOBSymbolStore
+	store       store.SearchStore
+	symbolStore trace.SymbolStore
 	httpServer  *http.Server
 	router      *chi.Mux
 	sseHub      *SSEHub
+	activity    activityLog
+	token       string
 	mu          sync.RWMutex
 	running     bool
 }
 
 // NewServer creates a new dashboard server.
-func NewServer(cfg *config.Config, projectRoot string, st *store.PostgresFTSStore, symbolStore *trace.GOBSymbolStore) *Server {
+func NewServer(cfg *config.Config, projectRoot string, st store.SearchStore, symbolStore trace.SymbolStore) *Server {
 	s := &Server{
 		config:      cfg,
 		projectRoot: projectRoot,
 		store:       st,
 		symbolStore: symbolStore,
 		sseHub:      NewSSEHub(),
+		token:       cfg.Dashboard.AuthToken(),
 	}
 
 	s.router = s.setupRouter()
@@ -55,6 +59,7 @@ func (s *Server) setupRouter() *chi.Mux {
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(requireAuth(s.token))
 	r.Use(middleware.Compress(5))
 
 	// Page routes
@@ -72,6 +77,7 @@ func (s *Server) setupRouter() *chi.Mux {
 		r.Get("/status", s.handleAPIStatus)
 		r.Get("/trace/{mode}/{symbol}", s.handleAPITrace)
 		r.Get("/projects", s.handleAPIProjects)
+		r.Get("/activity", s.handleAPIActivity)
 	})
 
 	// SSE route
@@ -91,6 +97,11 @@ func (s *Server) Start(ctx context.Context) error {
 		return nil
 	}
 
+	if s.token == "" && !netutil.IsLoopback(s.config.Dashboard.Host) {
+		s.mu.Unlock()
+		return fmt.Errorf("a token is required to serve the dashboard on %s (set dashboard.auth.token or %s)", s.config.Dashboard.Host, config.DashboardTokenEnv)
+	}
+
 	addr := fmt.Sprintf("%s:%d", s.config.Dashboard.Host, s.config.Dashboard.Port)
 
 	// Check if port is available
@@ -303,6 +314,8 @@ button:hover, .btn:hover { background: var(--accent-hover); }
 
 .search-form { display: flex; gap: 0.5rem; margin-bottom: 1rem; }
 .search-form input { flex: 1; }
+.search-form label { display: flex; align-items: center; gap: 0.25rem; color: var(--text-secondary); font-size: 0.875rem; white-space: nowrap; }
+.search-form label input { flex: none; }
 
 .result-item {
   background: var(--bg-tertiary);
@@ -321,6 +334,7 @@ button:hover, .btn:hover { background: var(--accent-hover); }
 .result-path { color: var(--accent); font-weight: 500; }
 .result-score { color: var(--text-secondary); font-size: 0.875rem; }
 .result-lines { color: var(--text-secondary); font-size: 0.875rem; }
+.result-blame { color: var(--text-secondary); font-size: 0.875rem; margin-top: 0.25rem; }
 
 pre, code {
   font-family: 'SF Mono', Monaco, 'Consolas', monospace;
@@ -338,6 +352,12 @@ pre {
 
 code { padding: 0.125rem 0.25rem; }
 
+mark {
+  background: transparent;
+  color: var(--warning);
+  font-weight: 600;
+}
+
 .status-badge {
   display: inline-block;
   padding: 0.25rem 0.5rem;
@@ -349,6 +369,14 @@ code { padding: 0.125rem 0.25rem; }
 .status-healthy { background: var(--success); }
 .status-unhealthy { background: var(--error); }
 
+.activity-feed { list-style: none; max-height: 24rem; overflow-y: auto; font-size: 0.875rem; }
+.activity-feed li { display: flex; gap: 0.75rem; padding: 0.375rem 0; border-bottom: 1px solid var(--border); }
+.activity-time, .activity-meta { color: var(--text-secondary); white-space: nowrap; }
+.activity-action { width: 4.5rem; font-weight: 600; }
+.activity-file { flex: 1; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.activity-failed .activity-action, .activity-failed .activity-error { color: var(--error); }
+.activity-warning .activity-error { color: var(--warning); }
+
 .table { width: 100%; border-collapse: collapse; }
 .table th, .table td {
   padding: 0.75rem;
@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+func TestServer_RequiresTokenOffLoopback(t *testing.T) {
+	t.Setenv(config.DashboardTokenEnv, "")
+	cfg := config.DefaultConfig()
+	cfg.Dashboard.Host = "0.0.0.0"
+	if err := NewServer(cfg, "/project", nil, nil).Start(context.Background()); err == nil {
+		t.Error("expected Start to refuse a public address without a token")
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	t.Setenv(config.DashboardTokenEnv, "")
+	cfg := config.DefaultConfig()
+	cfg.Dashboard.Auth.Token = "secret"
+	s := NewServer(cfg, "/project", nil, nil)
+
+	tests := []struct {
+		name string
+		auth func(r *http.Request)
+		want int
+	}{
+		{"no credentials", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusOK},
+		{"wrong bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"basic auth", func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, http.StatusOK},
+		{"wrong basic auth", func(r *http.Request) { r.SetBasicAuth("secret", "nope") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/static/style.css", nil)
+			tt.auth(req)
+			rec := httptest.NewRecorder()
+			s.router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestServer_Activity(t *testing.T) {
+	t.Setenv(config.DashboardTokenEnv, "")
+	s := NewServer(config.DefaultConfig(), "/project", nil, nil)
+	for i := range maxActivity + 5 {
+		s.BroadcastActivity(Activity{Time: time.Now(), Action: ActivityIndexed, File: fmt.Sprintf("file%d.go", i), Chunks: 1})
+	}
+	s.BroadcastActivity(Activity{Time: time.Now(), Action: ActivityFailed, File: "broken.go", Error: "permission denied"})
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/activity", nil))
+	var got []Activity
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode activity: %v", err)
+	}
+	if len(got) != maxActivity {
+		t.Fatalf("expected the %d most recent events, got %d", maxActivity, len(got))
+	}
+	if got[0].File != "broken.go" || got[0].Error != "permission denied" || got[1].File != fmt.Sprintf("file%d.go", maxActivity+4) {
+		t.Errorf("expected the newest events first, got %+v", got[:2])
+	}
+}
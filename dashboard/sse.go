@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+
+	"github.com/doveaia/agentdx/indexer"
 )
 
 // SSEClient represents a connected SSE client.
@@ -95,6 +97,12 @@ func (h *SSEHub) Broadcast(event string, data interface{}) {
 	}
 }
 
+// BroadcastProgress sends a progress event of the initial scan to the
+// connected clients as a "progress" event.
+func (s *Server) BroadcastProgress(event indexer.ProgressEvent) {
+	s.sseHub.Broadcast("progress", event)
+}
+
 // formatSSE formats a message for SSE.
 func formatSSE(event string, data []byte) []byte {
 	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, string(data)))
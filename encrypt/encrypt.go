@@ -0,0 +1,202 @@
+// Package encrypt seals the files agentdx writes with source code in them,
+// the GOB symbol index and the session log, with AES-256-GCM so that they
+// can be kept on disks where snippets must not be stored in the clear.
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// KeySize is the size of encryption keys (AES-256).
+const KeySize = 32
+
+// fileMagic starts sealed files, so that files written before encryption
+// was enabled can still be read.
+var fileMagic = []byte("agentdx-enc\x00v1\x00")
+
+// linePrefix starts sealed log lines.
+const linePrefix = "agentdx-enc:v1:"
+
+// Cipher seals and opens data with an AES-256-GCM key. Each sealed message
+// has its own random nonce.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a key written as base64 (e.g. openssl rand -base64 32)
+// or hex.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes in base64 or hex", KeySize)
+}
+
+// seal encrypts data, returning the nonce followed by the ciphertext.
+func (c *Cipher) seal(data []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(data)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("failed to read random nonce: %v", err))
+	}
+	return c.aead.Seal(nonce, nonce, data, nil)
+}
+
+// open decrypts the output of seal.
+func (c *Cipher) open(data []byte) ([]byte, error) {
+	if len(data) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("failed to decrypt: data is truncated")
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// SealFile returns the content of an encrypted file holding data.
+func (c *Cipher) SealFile(data []byte) []byte {
+	return append(bytes.Clone(fileMagic), c.seal(data)...)
+}
+
+// OpenFile returns the data of a file written by SealFile.
+func (c *Cipher) OpenFile(data []byte) ([]byte, error) {
+	if !IsSealedFile(data) {
+		return nil, fmt.Errorf("failed to decrypt: file is not encrypted")
+	}
+	return c.open(data[len(fileMagic):])
+}
+
+// IsSealedFile reports whether data is the content of an encrypted file.
+func IsSealedFile(data []byte) bool {
+	return bytes.HasPrefix(data, fileMagic)
+}
+
+// SealLine returns line, without its line break, as a line of text that
+// can be appended to a log.
+func (c *Cipher) SealLine(line []byte) string {
+	return linePrefix + base64.StdEncoding.EncodeToString(c.seal(line))
+}
+
+// OpenLine returns the text of a line written by SealLine. Lines that are
+// not sealed are returned as they are.
+func (c *Cipher) OpenLine(line string) (string, error) {
+	if !IsSealedLine(line) {
+		return line, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, linePrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	plain, err := c.open(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// IsSealedLine reports whether line was written by SealLine.
+func IsSealedLine(line string) bool {
+	return strings.HasPrefix(line, linePrefix)
+}
+
+// Source resolves the key of the encryption settings the first time it is
+// needed, so that commands that never touch encrypted files do not require
+// it. A nil Source means encryption is disabled.
+type Source struct {
+	cfg    config.EncryptionConfig
+	once   sync.Once
+	cipher *Cipher
+	err    error
+}
+
+// NewSource returns the key source of the encryption settings, or nil when
+// encryption is disabled.
+func NewSource(cfg config.EncryptionConfig) *Source {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Source{cfg: cfg}
+}
+
+// Cipher returns the cipher of the configured key. It returns nil without
+// an error when encryption is disabled.
+func (s *Source) Cipher() (*Cipher, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.once.Do(func() {
+		var encoded string
+		encoded, s.err = lookupKey(s.cfg)
+		if s.err != nil {
+			return
+		}
+		var key []byte
+		if key, s.err = ParseKey(encoded); s.err != nil {
+			s.err = errcode.Wrap(errcode.Config, s.err)
+			return
+		}
+		s.cipher, s.err = NewCipher(key)
+	})
+	return s.cipher, s.err
+}
+
+// lookupKey reads the encoded key from the environment or the OS keychain.
+func lookupKey(cfg config.EncryptionConfig) (string, error) {
+	if cfg.Key != config.EncryptionKeyKeychain {
+		key := os.Getenv(config.EncryptionKeyEnv)
+		if key == "" {
+			return "", errcode.New(errcode.Config, "encryption is enabled but %s is not set", config.EncryptionKeyEnv)
+		}
+		return key, nil
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", cfg.KeychainService, "-a", cfg.KeychainAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", cfg.KeychainService, "account", cfg.KeychainAccount)
+	default:
+		return "", errcode.New(errcode.Config, "encryption.key: keychain is not supported on %s, use env", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		return "", errcode.New(errcode.Config, "failed to read the encryption key from the keychain (service %s, account %s)", cfg.KeychainService, cfg.KeychainAccount)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,87 @@
+package encrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+var testKey = bytes.Repeat([]byte{7}, KeySize)
+
+func TestParseKey(t *testing.T) {
+	for _, encoded := range []string{base64.StdEncoding.EncodeToString(testKey), hex.EncodeToString(testKey) + "\n"} {
+		key, err := ParseKey(encoded)
+		if err != nil || !bytes.Equal(key, testKey) {
+			t.Errorf("ParseKey(%q) = %x, %v", encoded, key, err)
+		}
+	}
+	if _, err := ParseKey(base64.StdEncoding.EncodeToString(testKey[:16])); err == nil {
+		t.Error("expected a 16-byte key to be rejected")
+	}
+}
+
+func TestCipher_File(t *testing.T) {
+	c, err := NewCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("func Charge(card Card) error")
+	sealed := c.SealFile(data)
+	if !IsSealedFile(sealed) || bytes.Contains(sealed, []byte("Charge")) {
+		t.Fatalf("expected sealed data, got %q", sealed)
+	}
+	if bytes.Equal(sealed, c.SealFile(data)) {
+		t.Error("expected a new nonce for each file")
+	}
+	if plain, err := c.OpenFile(sealed); err != nil || !bytes.Equal(plain, data) {
+		t.Errorf("OpenFile() = %q, %v", plain, err)
+	}
+
+	other, _ := NewCipher(bytes.Repeat([]byte{8}, KeySize))
+	if _, err := other.OpenFile(sealed); err == nil {
+		t.Error("expected the wrong key to fail")
+	}
+	if _, err := c.OpenFile(data); err == nil {
+		t.Error("expected a file in the clear to be rejected")
+	}
+}
+
+func TestCipher_Line(t *testing.T) {
+	c, _ := NewCipher(testKey)
+	line := c.SealLine([]byte(`{"msg":"Indexed billing/charge.go"}`))
+	if !IsSealedLine(line) || strings.Contains(line, "billing") || strings.Contains(line, "\n") {
+		t.Fatalf("expected a sealed line, got %q", line)
+	}
+	if got, err := c.OpenLine(line); err != nil || got != `{"msg":"Indexed billing/charge.go"}` {
+		t.Errorf("OpenLine() = %q, %v", got, err)
+	}
+	// Lines written before encryption was enabled are kept
+	if got, err := c.OpenLine("[2026-10-17T10:00:00Z] Daemon started"); err != nil || got != "[2026-10-17T10:00:00Z] Daemon started" {
+		t.Errorf("OpenLine() = %q, %v", got, err)
+	}
+}
+
+func TestSource(t *testing.T) {
+	if c, err := NewSource(config.EncryptionConfig{}).Cipher(); c != nil || err != nil {
+		t.Errorf("expected no cipher when encryption is disabled, got %v, %v", c, err)
+	}
+
+	t.Setenv(config.EncryptionKeyEnv, "")
+	if _, err := NewSource(config.EncryptionConfig{Enabled: true}).Cipher(); err == nil {
+		t.Error("expected a missing key to fail")
+	}
+
+	t.Setenv(config.EncryptionKeyEnv, base64.StdEncoding.EncodeToString(testKey))
+	c, err := NewSource(config.EncryptionConfig{Enabled: true}).Cipher()
+	if err != nil {
+		t.Fatalf("Cipher() failed: %v", err)
+	}
+	want, _ := NewCipher(testKey)
+	if got, err := want.OpenLine(c.SealLine([]byte("x"))); err != nil || got != "x" {
+		t.Errorf("expected the key of the environment, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package errcode classifies agentdx failures so agents and hooks can tell
+// an empty index from an unreachable backend or a search without results.
+//
+// Commands print the code in JSON error payloads ({"error": ..., "code": ...})
+// and exit with the status of the code; MCP tools return the same payload as
+// the text of their error results.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Code identifies a class of failure.
+type Code string
+
+// Error codes, stable across releases.
+const (
+	Internal       Code = "E_INTERNAL"        // Unexpected failure
+	InvalidArgs    Code = "E_INVALID_ARGS"    // Bad flags, arguments or tool parameters
+	NoResults      Code = "E_NO_RESULTS"      // The query matched nothing
+	NoProject      Code = "E_NO_PROJECT"      // No .agentdx directory was found
+	Config         Code = "E_CONFIG"          // The configuration could not be loaded
+	BackendDown    Code = "E_BACKEND_DOWN"    // The index store is unreachable
+	NoIndex        Code = "E_NO_INDEX"        // Nothing has been indexed yet
+	NoSymbolIndex  Code = "E_NO_SYMBOL_INDEX" // The call graph has not been indexed yet
+	NotFound       Code = "E_NOT_FOUND"       // The symbol, file or note does not exist
+	BudgetExceeded Code = "E_BUDGET_EXCEEDED" // The session ran out of search budget
+	Unauthorized   Code = "E_UNAUTHORIZED"    // The HTTP API token is missing or wrong
+	Timeout        Code = "E_TIMEOUT"         // The call ran past its time limit
+)
+
+// exitCodes maps codes to process exit statuses.
+var exitCodes = map[Code]int{
+	Internal:       1,
+	InvalidArgs:    2,
+	NoResults:      3,
+	NoProject:      4,
+	Config:         5,
+	BackendDown:    6,
+	NoIndex:        7,
+	NoSymbolIndex:  8,
+	NotFound:       9,
+	BudgetExceeded: 10,
+	Unauthorized:   11,
+	Timeout:        12,
+}
+
+// ErrNoResults is returned by commands whose query matched nothing. They have
+// already printed their (empty) output, so it is not reported as an error.
+var ErrNoResults = New(NoResults, "no results found")
+
+// Error is an error with a code.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error with code and a formatted message.
+func New(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap attaches code to err, keeping its message. It returns nil for a nil
+// err and keeps the code of an already classified error.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the code of err: the outermost code attached to it, BackendDown
+// for network failures, or Internal.
+func Of(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		return BackendDown
+	}
+	return Internal
+}
+
+// ExitCode returns the process exit status for err: 0 for nil, otherwise the
+// status of its code.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[Of(err)]
+}
+
+// Payload is the machine-readable form of an error.
+type Payload struct {
+	Error string `json:"error"`
+	Code  Code   `json:"code"`
+}
+
+// PayloadOf returns the payload describing err.
+func PayloadOf(err error) Payload {
+	return Payload{Error: err.Error(), Code: Of(err)}
+}
@@ -0,0 +1,73 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"plain", errors.New("boom"), Internal},
+		{"coded", New(NoIndex, "index is empty"), NoIndex},
+		{"wrapped", fmt.Errorf("failed to search: %w", New(InvalidArgs, "bad type")), InvalidArgs},
+		{"network", fmt.Errorf("failed to connect: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), BackendDown},
+		{"outermost", Wrap(Config, fmt.Errorf("parse: %w", New(InvalidArgs, "bad"))), InvalidArgs},
+		{"sentinel", fmt.Errorf("%w: query", ErrNoResults), NoResults},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(Config, nil) != nil {
+		t.Error("Wrap(nil) should be nil")
+	}
+	base := errors.New("open config.yaml: no such file")
+	err := Wrap(Config, base)
+	if err.Error() != base.Error() {
+		t.Errorf("Wrap() changed the message to %q", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("Wrap() should keep the wrapped error")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(nil); got != 0 {
+		t.Errorf("ExitCode(nil) = %d, want 0", got)
+	}
+	if got := ExitCode(errors.New("boom")); got != 1 {
+		t.Errorf("ExitCode(internal) = %d, want 1", got)
+	}
+
+	// Every code exits with its own status
+	seen := make(map[int]Code)
+	for code := range exitCodes {
+		status := ExitCode(New(code, "failure"))
+		if status <= 0 {
+			t.Errorf("%s exits with %d", code, status)
+		}
+		if other, ok := seen[status]; ok {
+			t.Errorf("%s and %s share exit status %d", code, other, status)
+		}
+		seen[status] = code
+	}
+}
+
+func TestPayloadOf(t *testing.T) {
+	p := PayloadOf(New(BackendDown, "postgres is not running"))
+	if p.Error != "postgres is not running" || p.Code != BackendDown {
+		t.Errorf("PayloadOf() = %+v", p)
+	}
+}
@@ -0,0 +1,176 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// Chunking strategies selectable via index.chunking.strategy.
+const (
+	StrategySize = "size" // Fixed-size overlapping chunks
+	StrategyAST  = "ast"  // Split on top-level declaration boundaries
+)
+
+// FileChunker splits file content into indexable chunks.
+type FileChunker interface {
+	Chunk(filePath string, content string) []ChunkInfo
+	ChunkWithContext(filePath string, content string) []ChunkInfo
+}
+
+// NewFileChunker returns the chunker for the configured strategy.
+// Unknown strategies fall back to size-based chunking. Markdown files are
+// split on headings and notebooks on cells with either strategy.
+func NewFileChunker(strategy string, chunkSize, overlap int) FileChunker {
+	base := NewChunker(chunkSize, overlap)
+	if strategy == StrategyAST {
+		return NewNotebookChunker(base, NewMarkdownChunker(base, NewASTChunker(base)))
+	}
+	return NewNotebookChunker(base, NewMarkdownChunker(base, base))
+}
+
+// ASTChunker splits source files on declaration boundaries so that functions
+// and types are kept whole whenever they fit in a chunk. Files in languages
+// without a parser, or that fail to parse, are chunked by the fallback chunker.
+type ASTChunker struct {
+	fallback *Chunker
+}
+
+// NewASTChunker creates an AST-aware chunker using fallback for unsupported files
+// and for declarations larger than the configured chunk size.
+func NewASTChunker(fallback *Chunker) *ASTChunker {
+	return &ASTChunker{fallback: fallback}
+}
+
+// Chunk splits content into chunks aligned with top-level declarations.
+func (c *ASTChunker) Chunk(filePath string, content string) []ChunkInfo {
+	if len(content) == 0 {
+		return nil
+	}
+
+	var boundaries []int
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".go":
+		boundaries = goDeclBoundaries(filePath, content)
+	}
+	if len(boundaries) == 0 {
+		return c.fallback.Chunk(filePath, content)
+	}
+
+	return packSegments(c.fallback, filePath, content, boundaries)
+}
+
+// ChunkWithContext adds the file path header to each chunk.
+func (c *ASTChunker) ChunkWithContext(filePath string, content string) []ChunkInfo {
+	chunks := c.Chunk(filePath, content)
+	for i := range chunks {
+		chunks[i].Content = chunkHeader(filePath) + chunks[i].Content
+	}
+	return chunks
+}
+
+// packSegments greedily merges consecutive segments, such as declarations or
+// sections, into chunks of at most chunkSize tokens. A single segment that
+// exceeds the limit is split with the fallback chunker.
+func packSegments(fallback *Chunker, filePath, content string, boundaries []int) []ChunkInfo {
+	maxChars := fallback.chunkSize * CharsPerToken
+	lineStarts := buildLineStarts(content)
+
+	var chunks []ChunkInfo
+	emit := func(start, end int) {
+		text := content[start:end]
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		chunks = append(chunks, ChunkInfo{
+			FilePath:  filePath,
+			StartLine: getLineNumber(lineStarts, start),
+			EndLine:   getLineNumber(lineStarts, end-1),
+			Content:   text,
+		})
+	}
+
+	segStart := 0
+	packStart := 0
+	for i := 0; i <= len(boundaries); i++ {
+		segEnd := len(content)
+		if i < len(boundaries) {
+			segEnd = boundaries[i]
+		}
+		if segEnd <= segStart {
+			continue
+		}
+
+		if segEnd-segStart > maxChars {
+			// Oversized declaration: flush what we have, then split it
+			emit(packStart, segStart)
+			offset := getLineNumber(lineStarts, segStart) - 1
+			for _, sub := range fallback.Chunk(filePath, content[segStart:segEnd]) {
+				sub.StartLine += offset
+				sub.EndLine += offset
+				chunks = append(chunks, sub)
+			}
+			packStart = segEnd
+		} else if segEnd-packStart > maxChars {
+			emit(packStart, segStart)
+			packStart = segStart
+		}
+		segStart = segEnd
+	}
+	emit(packStart, len(content))
+
+	// Assign stable IDs and hashes in file order
+	for i := range chunks {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%s", filePath, chunks[i].StartLine, chunks[i].EndLine, chunks[i].Content)))
+		chunks[i].ID = fmt.Sprintf("%s_%d", filePath, i)
+		chunks[i].Hash = hex.EncodeToString(hash[:8])
+	}
+
+	return chunks
+}
+
+// goDeclBoundaries returns the byte offsets at which each top-level Go
+// declaration (including its doc comment) begins, aligned to line starts.
+// Returns nil if the file cannot be parsed.
+func goDeclBoundaries(filePath, content string) []int {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	var boundaries []int
+	for _, decl := range file.Decls {
+		pos := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			pos = doc.Pos()
+		}
+		offset := fset.Position(pos).Offset
+		// Align to the start of the line
+		if nl := strings.LastIndex(content[:offset], "\n"); nl >= 0 {
+			offset = nl + 1
+		} else {
+			offset = 0
+		}
+		if offset > 0 && (len(boundaries) == 0 || offset > boundaries[len(boundaries)-1]) {
+			boundaries = append(boundaries, offset)
+		}
+	}
+	return boundaries
+}
+
+// declDoc returns the doc comment attached to a top-level declaration.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
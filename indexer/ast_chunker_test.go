@@ -0,0 +1,132 @@
+package indexer
+
+import (
+	"strings"
+	"testing"
+)
+
+const astTestSource = `package sample
+
+import "fmt"
+
+// Greet prints a greeting.
+func Greet(name string) {
+	fmt.Println("hello", name)
+}
+
+// Farewell prints a farewell.
+func Farewell(name string) {
+	fmt.Println("bye", name)
+}
+`
+
+func TestNewFileChunker_Strategy(t *testing.T) {
+	next := func(strategy string) FileChunker {
+		nb, ok := NewFileChunker(strategy, 512, 50).(*NotebookChunker)
+		if !ok {
+			t.Fatalf("expected NotebookChunker for %s strategy", strategy)
+		}
+		m, ok := nb.next.(*MarkdownChunker)
+		if !ok {
+			t.Fatalf("expected MarkdownChunker for %s strategy", strategy)
+		}
+		return m.next
+	}
+	if _, ok := next(StrategyAST).(*ASTChunker); !ok {
+		t.Error("expected ASTChunker for ast strategy")
+	}
+	if _, ok := next(StrategySize).(*Chunker); !ok {
+		t.Error("expected Chunker for size strategy")
+	}
+	if _, ok := next("unknown").(*Chunker); !ok {
+		t.Error("expected Chunker for unknown strategy")
+	}
+}
+
+func TestASTChunker_KeepsFunctionsWhole(t *testing.T) {
+	// Each function is ~80 chars; limit chunks to ~100 chars so every
+	// declaration ends up in its own chunk.
+	chunker := NewASTChunker(NewChunker(25, 0))
+	chunks := chunker.Chunk("sample.go", astTestSource)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected at least 2 chunks, got %d", len(chunks))
+	}
+
+	for _, name := range []string{"func Greet", "func Farewell"} {
+		found := false
+		for _, c := range chunks {
+			if strings.Contains(c.Content, name) {
+				if !strings.Contains(c.Content, "fmt.Println") {
+					t.Errorf("%s split from its body", name)
+				}
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no chunk contains %s", name)
+		}
+	}
+
+	// Doc comments stay attached to their declaration
+	for _, c := range chunks {
+		if strings.Contains(c.Content, "func Farewell") && !strings.Contains(c.Content, "// Farewell prints") {
+			t.Error("doc comment not attached to Farewell")
+		}
+	}
+}
+
+func TestASTChunker_LineNumbers(t *testing.T) {
+	chunker := NewASTChunker(NewChunker(25, 0))
+	chunks := chunker.Chunk("sample.go", astTestSource)
+	lines := strings.Split(astTestSource, "\n")
+
+	for _, c := range chunks {
+		first := strings.SplitN(c.Content, "\n", 2)[0]
+		if lines[c.StartLine-1] != first {
+			t.Errorf("chunk start line %d = %q, content starts with %q", c.StartLine, lines[c.StartLine-1], first)
+		}
+		if c.ID == "" || c.Hash == "" {
+			t.Error("chunk missing ID or hash")
+		}
+	}
+}
+
+func TestASTChunker_MergesSmallDeclarations(t *testing.T) {
+	chunker := NewASTChunker(NewChunker(512, 50))
+	chunks := chunker.Chunk("sample.go", astTestSource)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected small file to fit in 1 chunk, got %d", len(chunks))
+	}
+	if chunks[0].StartLine != 1 {
+		t.Errorf("expected start line 1, got %d", chunks[0].StartLine)
+	}
+}
+
+func TestASTChunker_SplitsOversizedDeclaration(t *testing.T) {
+	body := strings.Repeat("\tfmt.Println(\"line of code\")\n", 100)
+	content := "package big\n\nimport \"fmt\"\n\nfunc Big() {\n" + body + "}\n"
+
+	chunker := NewASTChunker(NewChunker(50, 5))
+	chunks := chunker.Chunk("big.go", content)
+
+	if len(chunks) < 3 {
+		t.Fatalf("expected oversized function to be split, got %d chunks", len(chunks))
+	}
+	last := chunks[len(chunks)-1]
+	if last.EndLine != strings.Count(content, "\n") {
+		t.Errorf("expected last chunk to end at line %d, got %d", strings.Count(content, "\n"), last.EndLine)
+	}
+}
+
+func TestASTChunker_FallbackForUnsupportedOrInvalid(t *testing.T) {
+	chunker := NewASTChunker(NewChunker(512, 50))
+
+	if chunks := chunker.Chunk("script.py", "def foo():\n    pass\n"); len(chunks) != 1 {
+		t.Errorf("expected fallback chunk for python, got %d", len(chunks))
+	}
+	if chunks := chunker.Chunk("broken.go", "package x\nfunc {{{"); len(chunks) != 1 {
+		t.Errorf("expected fallback chunk for invalid Go, got %d", len(chunks))
+	}
+}
@@ -0,0 +1,141 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Diff lists the files of a project changed since a git revision, relative
+// to the project root.
+type Diff struct {
+	Changed []string // Added, modified or type-changed files
+	Deleted []string // Deleted files
+}
+
+// GitDiff returns the files under root that differ between ref and the
+// working tree, as reported by git diff. Renames are reported as a deletion
+// and an addition.
+func GitDiff(root, ref string) (*Diff, error) {
+	cmd := exec.Command("git", "-C", root, "diff", "--name-status", "-z", "--no-renames", "--relative", ref, "--")
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git diff %s: %s", ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git diff %s: %w", ref, err)
+	}
+	return parseNameStatus(out), nil
+}
+
+// GitCommit returns the full SHA of the commit ref names in the repository
+// at root, such as a branch, a tag or an abbreviated SHA.
+func GitCommit(root, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", root, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git rev-parse %s: %s", ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git rev-parse %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseNameStatus parses the NUL-separated status and path pairs written by
+// git diff --name-status -z.
+func parseNameStatus(out []byte) *Diff {
+	diff := &Diff{}
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := string(fields[i]), filepath.FromSlash(string(fields[i+1]))
+		if strings.HasPrefix(status, "D") {
+			diff.Deleted = append(diff.Deleted, path)
+		} else {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	return diff
+}
+
+// IndexDiff indexes the changed files of diff that the scanner accepts and
+// whose content differs from the index. With prune, the deleted files of
+// diff are removed from the index; otherwise they are kept.
+func (idx *Indexer) IndexDiff(ctx context.Context, diff *Diff, prune bool) (*IndexStats, error) {
+	start := time.Now()
+	stats := &IndexStats{}
+
+	for _, path := range diff.Changed {
+		if !idx.scanner.Accepts(path) {
+			continue
+		}
+		file, err := idx.scanner.ScanFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", path, err)
+		}
+		if file == nil {
+			stats.FilesSkipped++
+			continue
+		}
+		needed, err := idx.NeedsReindex(ctx, file.Path, file.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document %s: %w", path, err)
+		}
+		if !needed {
+			// Touched without changes: record the new time so that the
+			// file no longer looks stale
+			if err := idx.touchDocument(ctx, *file); err != nil {
+				return nil, fmt.Errorf("failed to update document %s: %w", path, err)
+			}
+			continue
+		}
+		chunks, err := idx.IndexFile(ctx, *file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index %s: %w", path, err)
+		}
+		stats.FilesIndexed++
+		stats.ChunksCreated += chunks
+	}
+
+	// After indexing, so that renamed files reuse the chunks of their old path
+	if prune {
+		for _, path := range diff.Deleted {
+			doc, err := idx.store.GetDocument(ctx, path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get document %s: %w", path, err)
+			}
+			if doc == nil {
+				continue
+			}
+			if err := idx.RemoveFile(ctx, path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			stats.FilesRemoved++
+		}
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// touchDocument records the modification time of file for its indexed
+// document, whose content is unchanged.
+func (idx *Indexer) touchDocument(ctx context.Context, file FileInfo) error {
+	doc, err := idx.store.GetDocument(ctx, file.Path)
+	if err != nil || doc == nil {
+		return err
+	}
+	modTime := time.Unix(file.ModTime, 0)
+	if doc.ModTime.Equal(modTime) {
+		return nil
+	}
+	doc.ModTime = modTime
+	return idx.store.SaveDocument(ctx, *doc)
+}
@@ -0,0 +1,163 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	out := []byte("M\x00a.go\x00A\x00dir/b.go\x00D\x00old.go\x00T\x00link.go\x00")
+	diff := parseNameStatus(out)
+	wantChanged := []string{"a.go", filepath.FromSlash("dir/b.go"), "link.go"}
+	if !reflect.DeepEqual(diff.Changed, wantChanged) {
+		t.Errorf("Changed = %v, want %v", diff.Changed, wantChanged)
+	}
+	if !reflect.DeepEqual(diff.Deleted, []string{"old.go"}) {
+		t.Errorf("Deleted = %v, want [old.go]", diff.Deleted)
+	}
+	if diff := parseNameStatus(nil); len(diff.Changed)+len(diff.Deleted) != 0 {
+		t.Errorf("expected an empty diff, got %+v", diff)
+	}
+}
+
+func TestIndexer_IndexDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write(".gitignore", "ignored.go\n")
+	write("kept.go", "package main\n\nfunc Kept() {}\n")
+	write("edited.go", "package main\n\nfunc Before() {}\n")
+	write("gone.go", "package main\n\nfunc Gone() {}\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+	ignore, err := NewIgnoreMatcher(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndexer(root, st, NewChunker(0, 0), NewScanner(root, ignore))
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	write("edited.go", "package main\n\nfunc After() {}\n")
+	write("added.go", "package main\n\nfunc Added() {}\n")
+	write("ignored.go", "package main\n\nfunc Ignored() {}\n")
+	if err := os.Remove(filepath.Join(root, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-f", ".")
+	git("commit", "-q", "-m", "change")
+
+	diff, err := GitDiff(root, "HEAD~1")
+	if err != nil {
+		t.Fatalf("GitDiff failed: %v", err)
+	}
+	if len(diff.Changed) != 3 || !reflect.DeepEqual(diff.Deleted, []string{"gone.go"}) {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+
+	stats, err := idx.IndexDiff(ctx, diff, false)
+	if err != nil {
+		t.Fatalf("IndexDiff failed: %v", err)
+	}
+	if stats.FilesIndexed != 2 || stats.FilesRemoved != 0 {
+		t.Errorf("expected 2 files indexed and none removed, got %+v", stats)
+	}
+	docs, err := st.ListDocuments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 4 {
+		t.Errorf("expected gone.go to stay indexed without prune, got %v", docs)
+	}
+
+	stats, err = idx.IndexDiff(ctx, diff, true)
+	if err != nil {
+		t.Fatalf("IndexDiff failed: %v", err)
+	}
+	if stats.FilesIndexed != 0 || stats.FilesRemoved != 1 {
+		t.Errorf("expected only gone.go removed, got %+v", stats)
+	}
+	doc, err := st.GetDocument(ctx, "gone.go")
+	if err != nil || doc != nil {
+		t.Errorf("expected gone.go to be pruned, got %+v (%v)", doc, err)
+	}
+
+	// A touched file is not indexed again, but its new time is recorded
+	touched := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(root, "kept.go"), touched, touched); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = idx.IndexDiff(ctx, &Diff{Changed: []string{"kept.go"}}, false)
+	if err != nil {
+		t.Fatalf("IndexDiff failed: %v", err)
+	}
+	doc, err = st.GetDocument(ctx, "kept.go")
+	if err != nil || doc == nil || stats.FilesIndexed != 0 || !doc.ModTime.Equal(touched) {
+		t.Errorf("expected only the time of kept.go to change, got %+v %+v (%v)", stats, doc, err)
+	}
+
+	if _, err := GitDiff(root, "no-such-ref"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
+
+func TestGitCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "base"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	head, err := GitCommit(root, "HEAD")
+	if err != nil || len(head) != 40 {
+		t.Fatalf("GitCommit(HEAD) = %q, %v; want a full SHA", head, err)
+	}
+	for _, ref := range []string{"v1", head[:7]} {
+		if got, err := GitCommit(root, ref); err != nil || got != head {
+			t.Errorf("GitCommit(%s) = %q, %v; want %s", ref, got, err, head)
+		}
+	}
+	if _, err := GitCommit(root, "missing"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
@@ -145,12 +145,17 @@ func (c *Chunker) ChunkWithContext(filePath string, content string) []ChunkInfo
 
 	// Add file path context to each chunk
 	for i := range chunks {
-		chunks[i].Content = fmt.Sprintf("File: %s\n\n%s", filePath, chunks[i].Content)
+		chunks[i].Content = chunkHeader(filePath) + chunks[i].Content
 	}
 
 	return chunks
 }
 
+// chunkHeader is the file path context prepended to each chunk's content.
+func chunkHeader(filePath string) string {
+	return "File: " + filePath + "\n\n"
+}
+
 // EstimateTokens provides a rough token count (simple word-based estimation)
 func EstimateTokens(text string) int {
 	words := strings.Fields(text)
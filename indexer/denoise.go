@@ -0,0 +1,243 @@
+package indexer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultHeaderMaxLines is how many lines from the top of a file are
+// searched for a boilerplate header.
+const DefaultHeaderMaxLines = 50
+
+// DefaultHeaderPatterns match license headers and generated-code banners.
+var DefaultHeaderPatterns = []string{
+	`(?i)\bcopyright\b`,
+	`(?i)SPDX-License-Identifier`,
+	`(?i)licensed under`,
+	`(?i)permission is hereby granted`,
+	`(?i)all rights reserved`,
+	`Code generated .* DO NOT EDIT`,
+	`@generated`,
+	`(?i)auto-?generated`,
+}
+
+// HeaderFilter finds boilerplate at the top of files, such as license
+// headers and generated-code banners, whose terms would otherwise dominate
+// the term frequencies of every file carrying them.
+type HeaderFilter struct {
+	patterns     []*regexp.Regexp
+	commentLines int
+	maxLines     int
+}
+
+// NewHeaderFilter returns a filter stripping the leading comment blocks
+// that match one of patterns, and the first commentLines lines of a file
+// when they are all comments. Only the first maxLines lines are searched
+// (DefaultHeaderMaxLines when maxLines is not positive).
+func NewHeaderFilter(patterns []string, commentLines, maxLines int) (*HeaderFilter, error) {
+	f := &HeaderFilter{commentLines: max(commentLines, 0), maxLines: maxLines}
+	if f.maxLines <= 0 {
+		f.maxLines = DefaultHeaderMaxLines
+	}
+	f.maxLines = max(f.maxLines, f.commentLines)
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid header pattern %q: %w", p, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	return f, nil
+}
+
+// Strip returns content without its boilerplate header, up to the last
+// stripped comment block and the blank lines after it, and the raw offset
+// of what remains: the number of lines removed, which chunk line numbers
+// must be shifted by to match the file. Content is returned whole when it
+// has no header or holds nothing else.
+func (f *HeaderFilter) Strip(content string) (string, int) {
+	lines := strings.SplitAfterN(content, "\n", f.maxLines+1)
+	truncated := len(lines) > f.maxLines
+	if truncated {
+		lines = lines[:f.maxLines]
+	}
+
+	// Offset (in lines) past the last stripped block
+	strip := 0
+	if f.commentLines > 0 && len(lines) >= f.commentLines && allComments(lines[:f.commentLines]) {
+		strip = f.commentLines
+	}
+	if len(f.patterns) > 0 {
+		for _, block := range leadingCommentBlocks(lines) {
+			// A block running past the searched lines is not stripped
+			if truncated && block.end == len(lines) {
+				break
+			}
+			if block.end > strip && f.matches(strings.Join(lines[block.start:block.end], "")) {
+				strip = block.end
+			}
+		}
+	}
+	if strip == 0 {
+		return content, 0
+	}
+
+	// Blank lines after the header go with it
+	for strip < len(lines) && strings.TrimSpace(lines[strip]) == "" {
+		strip++
+	}
+	offset := 0
+	for _, line := range lines[:strip] {
+		offset += len(line)
+	}
+	body := content[offset:]
+	if strings.TrimSpace(body) == "" {
+		return content, 0
+	}
+	return body, strip
+}
+
+// matches reports whether text matches one of the filter's patterns.
+func (f *HeaderFilter) matches(text string) bool {
+	for _, re := range f.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineBlock is the half-open range [start, end) of lines of a comment block.
+type lineBlock struct {
+	start, end int
+}
+
+// leadingCommentBlocks returns the comment blocks at the top of lines,
+// separated by blank lines, up to the first line of code.
+func leadingCommentBlocks(lines []string) []lineBlock {
+	var blocks []lineBlock
+	var closer string // end marker of the block comment being read
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		isComment := false
+		switch {
+		case closer != "":
+			isComment = true
+			if strings.Contains(trimmed, closer) {
+				closer = ""
+			}
+		case trimmed == "":
+		default:
+			isComment, closer = commentLine(trimmed)
+		}
+
+		if isComment {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			blocks = append(blocks, lineBlock{start, i})
+			start = -1
+		}
+		if trimmed != "" {
+			return blocks
+		}
+	}
+	if start >= 0 {
+		blocks = append(blocks, lineBlock{start, len(lines)})
+	}
+	return blocks
+}
+
+// allComments reports whether lines are all comments or blank.
+func allComments(lines []string) bool {
+	blocks := leadingCommentBlocks(lines)
+	if len(blocks) == 0 {
+		return false
+	}
+	for _, line := range lines[blocks[len(blocks)-1].end:] {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// blockComments are the start and end markers of block comments.
+var blockComments = []struct{ open, close string }{
+	{"/*", "*/"},
+	{"<!--", "-->"},
+	{"{-", "-}"},
+	{"(*", "*)"},
+}
+
+// commentLine reports whether the trimmed line is a comment and, when it
+// opens a block comment left open on the line, the marker closing it.
+func commentLine(trimmed string) (bool, string) {
+	for _, b := range blockComments {
+		if strings.HasPrefix(trimmed, b.open) {
+			if strings.Contains(trimmed[len(b.open):], b.close) {
+				return true, ""
+			}
+			return true, b.close
+		}
+	}
+	switch {
+	case strings.HasPrefix(trimmed, "//"), strings.HasPrefix(trimmed, ";;"):
+		return true, ""
+	case trimmed == "#", trimmed == "--", strings.HasPrefix(trimmed, "-- "):
+		return true, ""
+	case strings.HasPrefix(trimmed, "#"):
+		// "# text", "#!" and "##"; not C preprocessor directives
+		next := trimmed[1]
+		return next == ' ' || next == '\t' || next == '!' || next == '#', ""
+	}
+	return false, ""
+}
+
+// StrippingChunker chunks files without their boilerplate header. Chunk
+// line numbers are shifted by the raw offset of the stripped content, so
+// they still point at the lines of the file.
+type StrippingChunker struct {
+	filter *HeaderFilter
+	next   FileChunker
+}
+
+// NewStrippingChunker returns a chunker stripping headers with filter before
+// chunking with next.
+func NewStrippingChunker(next FileChunker, filter *HeaderFilter) *StrippingChunker {
+	return &StrippingChunker{filter: filter, next: next}
+}
+
+// Chunk strips the header of content and chunks the rest.
+func (c *StrippingChunker) Chunk(filePath string, content string) []ChunkInfo {
+	if IsNotebook(filePath) {
+		// The first line of a notebook is the marker of its first cell
+		return c.next.Chunk(filePath, content)
+	}
+	body, rawOffset := c.filter.Strip(content)
+	return shiftLines(c.next.Chunk(filePath, body), rawOffset)
+}
+
+// ChunkWithContext strips the header of content and chunks the rest with
+// the file path header.
+func (c *StrippingChunker) ChunkWithContext(filePath string, content string) []ChunkInfo {
+	if IsNotebook(filePath) {
+		return c.next.ChunkWithContext(filePath, content)
+	}
+	body, rawOffset := c.filter.Strip(content)
+	return shiftLines(c.next.ChunkWithContext(filePath, body), rawOffset)
+}
+
+// shiftLines moves chunks down by offset lines.
+func shiftLines(chunks []ChunkInfo, offset int) []ChunkInfo {
+	for i := range chunks {
+		chunks[i].StartLine += offset
+		chunks[i].EndLine += offset
+	}
+	return chunks
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeaderFilter_Strip(t *testing.T) {
+	f, err := NewHeaderFilter(DefaultHeaderPatterns, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		body    string
+		offset  int
+	}{
+		{
+			name:    "license and package doc",
+			content: "// Copyright 2024 Example Inc.\n// SPDX-License-Identifier: MIT\n\n// Package auth logs users in.\npackage auth\n",
+			body:    "// Package auth logs users in.\npackage auth\n",
+			offset:  3,
+		},
+		{
+			name:    "block comment",
+			content: "/*\n * Licensed under the Apache License, Version 2.0\n */\n\nclass Login {}\n",
+			body:    "class Login {}\n",
+			offset:  4,
+		},
+		{
+			name:    "generated banner",
+			content: "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: auth.proto\n\npackage auth\n",
+			body:    "package auth\n",
+			offset:  3,
+		},
+		{
+			name:    "shell script",
+			content: "#!/bin/sh\n# Copyright (c) 2024 Example\n\necho hi\n",
+			body:    "echo hi\n",
+			offset:  3,
+		},
+		{
+			name:    "comment without boilerplate",
+			content: "// Login authenticates a user.\nfunc Login() {}\n",
+			body:    "// Login authenticates a user.\nfunc Login() {}\n",
+		},
+		{
+			name:    "header after code",
+			content: "#include <stdio.h>\n/* Copyright 2024 */\nint main() {}\n",
+			body:    "#include <stdio.h>\n/* Copyright 2024 */\nint main() {}\n",
+		},
+		{
+			name:    "nothing but a header",
+			content: "# Copyright 2024 Example\n",
+			body:    "# Copyright 2024 Example\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, offset := f.Strip(tt.content)
+			if body != tt.body || offset != tt.offset {
+				t.Errorf("Strip() = %q, %d; want %q, %d", body, offset, tt.body, tt.offset)
+			}
+		})
+	}
+}
+
+func TestHeaderFilter_CommentLines(t *testing.T) {
+	f, err := NewHeaderFilter(nil, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, offset := f.Strip("// Project banner\n// v1\nfunc Login() {}\n"); body != "func Login() {}\n" || offset != 2 {
+		t.Errorf("expected the two comment lines stripped, got %q, %d", body, offset)
+	}
+	if body, offset := f.Strip("// Login logs in.\nfunc Login() {}\n"); offset != 0 || !strings.HasPrefix(body, "// Login") {
+		t.Errorf("expected no stripping when the first lines are not all comments, got %q, %d", body, offset)
+	}
+
+	if _, err := NewHeaderFilter([]string{"("}, 0, 0); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestStrippingChunker_KeepsFileLines(t *testing.T) {
+	content := "// Copyright 2024 Example Inc.\n// All rights reserved.\n\npackage auth\n\nfunc Login() {}\n"
+	f, err := NewHeaderFilter(DefaultHeaderPatterns, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewStrippingChunker(NewFileChunker(StrategySize, 512, 0), f)
+
+	chunks := c.ChunkWithContext("auth.go", content)
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %+v", chunks)
+	}
+	if strings.Contains(chunks[0].Content, "Copyright") || !strings.HasPrefix(chunks[0].Content, chunkHeader("auth.go")+"package auth") {
+		t.Errorf("expected the header stripped, got %q", chunks[0].Content)
+	}
+	if chunks[0].StartLine != 4 || chunks[0].EndLine != 6 {
+		t.Errorf("expected lines 4-6 of the file, got %d-%d", chunks[0].StartLine, chunks[0].EndLine)
+	}
+}
@@ -0,0 +1,155 @@
+package indexer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// commentStyle describes the comment syntax of a language.
+type commentStyle struct {
+	line      []string // line comment prefixes
+	block     bool     // /* ... */ block comments
+	docstring bool     // Python docstrings after def and class lines
+}
+
+var (
+	cStyle     = commentStyle{line: []string{"//"}, block: true}
+	hashStyle  = commentStyle{line: []string{"#"}}
+	dashStyle  = commentStyle{line: []string{"--"}, block: true}
+	phpStyle   = commentStyle{line: []string{"//", "#"}, block: true}
+	pythonDocs = commentStyle{line: []string{"#"}, docstring: true}
+)
+
+// commentStyles maps extensions to the comment syntax of their language.
+// Files of other types have no doc comments.
+var commentStyles = map[string]commentStyle{
+	".go": cStyle, ".js": cStyle, ".ts": cStyle, ".jsx": cStyle, ".tsx": cStyle,
+	".java": cStyle, ".c": cStyle, ".cpp": cStyle, ".cc": cStyle, ".h": cStyle,
+	".hpp": cStyle, ".cs": cStyle, ".rs": cStyle, ".swift": cStyle, ".kt": cStyle,
+	".scala": cStyle, ".vue": cStyle, ".svelte": cStyle, ".css": cStyle,
+	".scss": cStyle, ".less": cStyle,
+	".php": phpStyle,
+	".py":  pythonDocs,
+	".rb":  hashStyle, ".sh": hashStyle, ".bash": hashStyle, ".zsh": hashStyle,
+	".sql": dashStyle,
+}
+
+// DocComments returns the leading doc comments in content, one per line:
+// comment blocks directly followed by code and, for Python, docstrings
+// directly following a def or class line. Comment markers are stripped.
+// For Markdown files, it returns the headings.
+func DocComments(filePath, content string) string {
+	if isMarkdown(filePath) {
+		return markdownHeadings(content)
+	}
+	style, ok := commentStyles[strings.ToLower(filepath.Ext(filePath))]
+	if !ok {
+		return ""
+	}
+
+	var docs, block []string
+	inBlock := false
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case inBlock:
+			text, closed := strings.CutSuffix(line, "*/")
+			if !closed {
+				if before, _, found := strings.Cut(line, "*/"); found {
+					text, closed = before, true
+				}
+			}
+			block = appendComment(block, strings.TrimPrefix(strings.TrimSpace(text), "*"))
+			inBlock = !closed
+		case style.block && strings.HasPrefix(line, "/*"):
+			text := strings.TrimLeft(strings.TrimPrefix(line, "/*"), "*!")
+			if before, _, found := strings.Cut(text, "*/"); found {
+				block = appendComment(block, before)
+			} else {
+				block = appendComment(block, text)
+				inBlock = true
+			}
+		case line == "":
+			block = nil // Only comments attached to code are doc comments
+		case isLineComment(line, style):
+			block = appendComment(block, stripLineComment(line, style))
+		default:
+			if len(block) > 0 {
+				docs = append(docs, strings.Join(block, " "))
+			}
+			block = nil
+			if style.docstring && isPythonDef(line) {
+				if doc, end := pythonDocstring(lines, i+1); end > i {
+					docs = appendComment(docs, doc)
+					i = end
+				}
+			}
+		}
+	}
+	return strings.Join(docs, "\n")
+}
+
+func isLineComment(line string, style commentStyle) bool {
+	for _, prefix := range style.line {
+		if strings.HasPrefix(line, prefix) {
+			return prefix != "#" || !strings.HasPrefix(line, "#!") // Skip shebangs
+		}
+	}
+	return false
+}
+
+func stripLineComment(line string, style commentStyle) string {
+	for _, prefix := range style.line {
+		if text, ok := strings.CutPrefix(line, prefix); ok {
+			// Doc comment markers such as /// and //!
+			return strings.TrimLeft(text, prefix[:1]+"!")
+		}
+	}
+	return line
+}
+
+func appendComment(block []string, text string) []string {
+	if text = strings.TrimSpace(text); text != "" {
+		block = append(block, text)
+	}
+	return block
+}
+
+func isPythonDef(line string) bool {
+	line = strings.TrimPrefix(line, "async ")
+	return (strings.HasPrefix(line, "def ") || strings.HasPrefix(line, "class ")) && strings.HasSuffix(line, ":")
+}
+
+// pythonDocstring returns the docstring starting at lines[start] and the
+// index of its last line, or -1 when there is none.
+func pythonDocstring(lines []string, start int) (string, int) {
+	if start >= len(lines) {
+		return "", -1
+	}
+	first := strings.TrimSpace(lines[start])
+	first = strings.TrimLeft(first, "rRuU")
+	var quote string
+	switch {
+	case strings.HasPrefix(first, `"""`):
+		quote = `"""`
+	case strings.HasPrefix(first, `'''`):
+		quote = `'''`
+	default:
+		return "", -1
+	}
+
+	var parts []string
+	text := strings.TrimPrefix(first, quote)
+	for i := start; i < len(lines); i++ {
+		if i > start {
+			text = strings.TrimSpace(lines[i])
+		}
+		if before, _, found := strings.Cut(text, quote); found {
+			parts = appendComment(parts, before)
+			return strings.Join(parts, " "), i
+		}
+		parts = appendComment(parts, text)
+	}
+	return "", -1
+}
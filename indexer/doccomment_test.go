@@ -0,0 +1,60 @@
+package indexer
+
+import "testing"
+
+func TestDocComments(t *testing.T) {
+	tests := []struct {
+		name, path, content, want string
+	}{
+		{
+			name:    "go line comments",
+			path:    "pool.go",
+			content: "File: pool.go\n\n// Acquire waits for a free\n// connection.\nfunc Acquire() {}\n\n// unattached\n\nvar x = 1 // trailing\n",
+			want:    "Acquire waits for a free connection.",
+		},
+		{
+			name:    "block comments",
+			path:    "api.ts",
+			content: "/**\n * Fetches the user profile.\n * @param id user id\n */\nexport function fetchUser(id) {}\n/* Inline block */\nconst y = 2\n",
+			want:    "Fetches the user profile. @param id user id\nInline block",
+		},
+		{
+			name:    "rust doc comments",
+			path:    "lib.rs",
+			content: "/// Parses a config file.\npub fn parse() {}\n",
+			want:    "Parses a config file.",
+		},
+		{
+			name:    "python docstrings",
+			path:    "auth.py",
+			content: "#!/usr/bin/env python\n# Session helpers\nimport os\n\ndef login(user):\n    \"\"\"Authenticate a user\n    against the directory.\n    \"\"\"\n    pass\n\nclass Token:\n    '''Signed token.'''\n",
+			want:    "Session helpers\nAuthenticate a user against the directory.\nSigned token.",
+		},
+		{
+			name:    "sql",
+			path:    "schema.sql",
+			content: "-- Users of the app\nCREATE TABLE users (id INT);\n",
+			want:    "Users of the app",
+		},
+		{
+			name:    "unknown language",
+			path:    "notes.txt",
+			content: "# Title\ntext\n",
+			want:    "",
+		},
+		{
+			name:    "markdown headings",
+			path:    "README.md",
+			content: "# Title\ntext\n```sh\n# not a heading\n```\n## Usage ##\n",
+			want:    "Title\nUsage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DocComments(tt.path, tt.content); got != tt.want {
+				t.Errorf("DocComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package indexer
+
+import (
+	"bytes"
+	"slices"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encodings of source files that are not UTF-8, as recorded in
+// store.Document.Encoding. Such files are transcoded to UTF-8 before they
+// are chunked; line breaks are kept, so line numbers match the file on
+// disk.
+const (
+	EncodingUTF16LE     = "UTF-16LE"
+	EncodingUTF16BE     = "UTF-16BE"
+	EncodingShiftJIS    = "Shift_JIS"
+	EncodingLatin1      = "ISO-8859-1"
+	EncodingWindows1252 = "windows-1252"
+)
+
+var decoders = map[string]encoding.Encoding{
+	EncodingUTF16LE:     unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM),
+	EncodingUTF16BE:     unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM),
+	EncodingShiftJIS:    japanese.ShiftJIS,
+	EncodingLatin1:      charmap.ISO8859_1,
+	EncodingWindows1252: charmap.Windows1252,
+}
+
+// DecodeText returns the content of a source file as UTF-8 and the
+// encoding it was detected in, empty for UTF-8. ok is false for binary
+// content.
+//
+// UTF-16 is recognized by its byte order mark. Other content that is not
+// valid UTF-8 is Shift_JIS when it decodes without errors and contains
+// kana, and Latin-1 (windows-1252 when it uses the C1 range) otherwise.
+func DecodeText(data []byte) (content, enc string, ok bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		enc = EncodingUTF16LE
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		enc = EncodingUTF16BE
+	case utf8.Valid(data):
+		if containsNull(data) {
+			return "", "", false
+		}
+		return string(data), "", true
+	case containsNull(data) || hasControlBytes(data):
+		return "", "", false
+	case isShiftJIS(data):
+		enc = EncodingShiftJIS
+	case slices.ContainsFunc(data, func(b byte) bool { return b >= 0x80 && b <= 0x9F }):
+		enc = EncodingWindows1252
+	default:
+		enc = EncodingLatin1
+	}
+
+	decoded, err := decoders[enc].NewDecoder().Bytes(data)
+	if err != nil || containsNull(decoded) {
+		return "", "", false
+	}
+	return string(decoded), enc, true
+}
+
+// hasControlBytes reports whether more than 1% of data are control bytes
+// other than whitespace, which text in a legacy encoding does not have.
+func hasControlBytes(data []byte) bool {
+	n := 0
+	for _, b := range data {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != '\v' && b != 0x1A && b != 0x1B {
+			n++
+		}
+	}
+	return n*100 > len(data)
+}
+
+// isShiftJIS reports whether data decodes as Shift_JIS without invalid
+// sequences and has full-width kana. Latin-1 text can form valid Shift_JIS
+// byte pairs, but those decode to kanji, never to kana.
+func isShiftJIS(data []byte) bool {
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+		return false
+	}
+	return bytes.ContainsFunc(decoded, func(r rune) bool {
+		return r >= 0x3041 && r <= 0x30FA // Hiragana and Katakana
+	})
+}
@@ -0,0 +1,64 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		enc     string
+		notText bool
+	}{
+		{"utf-8", []byte("// café\n"), "// café\n", "", false},
+		{"latin-1", []byte("// d\xe9but, caf\xe9\n"), "// début, café\n", EncodingLatin1, false},
+		{"windows-1252", []byte("// \x93quoted\x94 \x80\n"), "// “quoted” €\n", EncodingWindows1252, false},
+		{"shift_jis", []byte("// \x90\xdd\x92\xe8\x82\xf0\x93\xc7\x82\xdd\x8d\x9e\x82\xde\n"), "// 設定を読み込む\n", EncodingShiftJIS, false},
+		{"utf-16le", []byte("\xff\xfeh\x00i\x00\n\x00"), "hi\n", EncodingUTF16LE, false},
+		{"utf-16be", []byte("\xfe\xff\x00h\x00i\x00\n"), "hi\n", EncodingUTF16BE, false},
+		{"null bytes", []byte("ab\x00cd"), "", "", true},
+		{"binary", []byte("\x01\x02\x03\xe9\x04\x05"), "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, enc, ok := DecodeText(tt.data)
+			if ok == tt.notText {
+				t.Fatalf("DecodeText ok = %v, want %v", ok, !tt.notText)
+			}
+			if got != tt.want || enc != tt.enc {
+				t.Errorf("DecodeText = %q (%q), want %q (%q)", got, enc, tt.want, tt.enc)
+			}
+		})
+	}
+}
+
+func TestScanner_ScanFileTranscodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte("package main\n\n// Gr\xfc\xdfe\nfunc main() {}\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	file, err := NewScanner(tmpDir, ignoreMatcher).ScanFile("main.go")
+	if err != nil || file == nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if file.Encoding != EncodingLatin1 {
+		t.Errorf("expected encoding %s, got %q", EncodingLatin1, file.Encoding)
+	}
+	if file.Content != "package main\n\n// Grüße\nfunc main() {}\n" {
+		t.Errorf("unexpected content %q", file.Content)
+	}
+	// The hash is of the file on disk, as HashFile computes it
+	if hash, _ := HashFile(filepath.Join(tmpDir, "main.go")); file.Hash != hash {
+		t.Errorf("hash %s does not match the file's %s", file.Hash, hash)
+	}
+}
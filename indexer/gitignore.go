@@ -2,23 +2,57 @@ package indexer
 
 import (
 	"bufio"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
-	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
-// nestedMatcher holds a gitignore matcher and its base directory
-type nestedMatcher struct {
-	matcher *ignore.GitIgnore
-	baseDir string // relative path from project root (empty for root .gitignore)
+// IgnoreFileNames are the per-directory ignore files, in increasing order of
+// precedence. .agentdxignore uses the .gitignore syntax and excludes files
+// from the index only.
+var IgnoreFileNames = []string{".gitignore", ".agentdxignore"}
+
+// IsIgnoreFile reports whether path names an ignore file.
+func IsIgnoreFile(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range IgnoreFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ignorePattern is one line of an ignore file.
+type ignorePattern struct {
+	glob    string // doublestar pattern relative to the ignore file's directory
+	negate  bool   // "!pattern" re-includes matching paths
+	dirOnly bool   // "pattern/" matches directories only
+}
+
+// ignoreFile holds the patterns of an ignore file and its base directory.
+type ignoreFile struct {
+	baseDir  string // slash-separated path from the project root ("" for the root)
+	patterns []ignorePattern
 }
 
+// IgnoreMatcher decides which paths are excluded from indexing using the
+// .gitignore semantics: ignore files apply to their directory and below,
+// deeper files take precedence over shallower ones, the last matching pattern
+// wins, negated patterns re-include paths, and nothing below an ignored
+// directory can be re-included. Directory names from the config are always
+// ignored.
 type IgnoreMatcher struct {
-	projectRoot    string
-	nestedMatchers []nestedMatcher
-	extraDirs      []string
+	projectRoot string
+	extraDirs   []string
+
+	mu    sync.RWMutex
+	files []ignoreFile // parents before children
 }
 
 func NewIgnoreMatcher(projectRoot string, extraIgnore []string) (*IgnoreMatcher, error) {
@@ -26,106 +60,169 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string) (*IgnoreMatcher,
 		projectRoot: projectRoot,
 		extraDirs:   extraIgnore,
 	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
 
-	// Walk the project to find all .gitignore files
-	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip inaccessible paths
-		}
-
-		// Skip directories that should be ignored by default
-		if info.IsDir() {
-			base := filepath.Base(path)
-			for _, dir := range extraIgnore {
-				if base == dir {
-					return filepath.SkipDir
-				}
-			}
-			return nil
-		}
-
-		// Only process .gitignore files
-		if filepath.Base(path) != ".gitignore" {
-			return nil
-		}
+// Reload reads the ignore files of the project again.
+func (m *IgnoreMatcher) Reload() error {
+	// Config patterns act as a root-level ignore file with the lowest precedence
+	var files []ignoreFile
+	if len(m.extraDirs) > 0 {
+		files = append(files, ignoreFile{patterns: parseIgnoreLines(m.extraDirs)})
+	}
 
-		gi, err := ignore.CompileIgnoreFile(path)
-		if err != nil {
-			return nil // Skip invalid .gitignore files
+	err := filepath.WalkDir(m.projectRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil // Skip inaccessible paths; files are read per directory
 		}
 
-		// Get relative base directory
-		relPath, err := filepath.Rel(projectRoot, filepath.Dir(path))
+		relPath, err := filepath.Rel(m.projectRoot, p)
 		if err != nil {
 			return nil
 		}
+		relPath = filepath.ToSlash(relPath)
 		if relPath == "." {
 			relPath = ""
+		} else if ignored(files, m.extraDirs, relPath, true) {
+			// Git does not read ignore files in ignored directories either
+			return filepath.SkipDir
 		}
 
-		m.nestedMatchers = append(m.nestedMatchers, nestedMatcher{
-			matcher: gi,
-			baseDir: relPath,
-		})
-
+		for _, name := range IgnoreFileNames {
+			patterns, err := readIgnoreFile(filepath.Join(p, name))
+			if err != nil || len(patterns) == 0 {
+				continue // Skip missing or unreadable ignore files
+			}
+			files = append(files, ignoreFile{baseDir: relPath, patterns: patterns})
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.files = files
+	m.mu.Unlock()
+	return nil
+}
 
+// ShouldIgnore reports whether the file or directory at path, relative to
+// the project root, is ignored. Paths that do not exist are matched both as
+// a file and as a directory.
+func (m *IgnoreMatcher) ShouldIgnore(path string) bool {
+	info, err := os.Stat(filepath.Join(m.projectRoot, path))
 	if err != nil {
-		return nil, err
+		return m.ShouldIgnoreEntry(path, false) || m.ShouldIgnoreEntry(path, true)
 	}
+	return m.ShouldIgnoreEntry(path, info.IsDir())
+}
 
-	// Add extra ignore patterns as a root-level matcher
-	if len(extraIgnore) > 0 {
-		gi := ignore.CompileIgnoreLines(extraIgnore...)
-		m.nestedMatchers = append(m.nestedMatchers, nestedMatcher{
-			matcher: gi,
-			baseDir: "",
-		})
+// ShouldIgnoreEntry is ShouldIgnore for a path whose type is known, as when
+// walking the tree.
+func (m *IgnoreMatcher) ShouldIgnoreEntry(path string, isDir bool) bool {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	if path == "" || path == "." {
+		return false
 	}
 
-	return m, nil
-}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-func (m *IgnoreMatcher) ShouldIgnore(path string) bool {
-	// Normalize path separators for cross-platform compatibility
-	normalizedPath := filepath.ToSlash(path)
+	// A path inside an ignored directory is ignored
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && ignored(m.files, m.extraDirs, path[:i], true) {
+			return true
+		}
+	}
+	return ignored(m.files, m.extraDirs, path, isDir)
+}
 
-	// Check extra directories first (exact match for efficiency)
-	base := filepath.Base(path)
-	for _, dir := range m.extraDirs {
+// ignored applies the ignore files to path without looking at its parents.
+func ignored(files []ignoreFile, extraDirs []string, relPath string, isDir bool) bool {
+	base := path.Base(relPath)
+	for _, dir := range extraDirs {
 		if base == dir {
 			return true
 		}
 	}
 
-	// Check nested gitignore patterns
-	for _, nm := range m.nestedMatchers {
-		// Determine the relative path from this matcher's base directory
-		var relPath string
-		if nm.baseDir == "" {
-			// Root-level matcher applies to all paths
-			relPath = normalizedPath
-		} else {
-			// Nested matcher only applies to paths within its directory
-			normalizedBase := filepath.ToSlash(nm.baseDir)
-			if !strings.HasPrefix(normalizedPath, normalizedBase+"/") && normalizedPath != normalizedBase {
-				continue // This matcher doesn't apply to this path
+	result := false
+	for _, f := range files {
+		rel := relPath
+		if f.baseDir != "" {
+			if !strings.HasPrefix(relPath, f.baseDir+"/") {
+				continue // This file doesn't apply to this path
+			}
+			rel = relPath[len(f.baseDir)+1:]
+		}
+		for _, p := range f.patterns {
+			if p.dirOnly && !isDir {
+				continue
+			}
+			if ok, _ := doublestar.Match(p.glob, rel); ok {
+				result = !p.negate
 			}
-			// Get path relative to the matcher's base directory
-			relPath = strings.TrimPrefix(normalizedPath, normalizedBase+"/")
+		}
+	}
+	return result
+}
+
+// readIgnoreFile parses the ignore file at path.
+func readIgnoreFile(path string) ([]ignorePattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseIgnoreLines(strings.Split(string(data), "\n")), nil
+}
+
+// parseIgnoreLines parses lines in the .gitignore syntax.
+func parseIgnoreLines(lines []string) []ignorePattern {
+	var patterns []ignorePattern
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		// Trailing spaces are ignored unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
 
-		if nm.matcher.MatchesPath(relPath) {
-			return true
+		var p ignorePattern
+		switch {
+		case strings.HasPrefix(line, "!"):
+			p.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, "\\#"), strings.HasPrefix(line, "\\!"):
+			line = line[1:]
 		}
-		// Also check with trailing slash to match directory patterns like "build/"
-		if nm.matcher.MatchesPath(relPath + "/") {
-			return true
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
 		}
-	}
 
-	return false
+		// A pattern with a slash is relative to the ignore file's directory;
+		// one without matches at any depth
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		if !doublestar.ValidatePattern(line) {
+			continue // Skip invalid patterns like git does
+		}
+		p.glob = line
+		patterns = append(patterns, p)
+	}
+	return patterns
 }
 
 // AddToGitignore appends a pattern to .gitignore if not already present
@@ -402,3 +402,119 @@ func TestScanner_RespectsNestedGitignore(t *testing.T) {
 		t.Errorf("expected %s, got %s", expectedPath, files[0].Path)
 	}
 }
+
+func TestIgnoreMatcher_NegationAndPrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		".gitignore": `*.log
+!keep.log
+/generated/
+logs/
+docs/*.md
+!docs/README.md
+\#literal.go
+`,
+		"src/.gitignore": `!debug.log
+local.go
+`,
+		"src/.agentdxignore": `fixtures/
+!local.go
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "src", "generated"), 0755); err != nil {
+		t.Fatalf("failed to create src/generated: %v", err)
+	}
+
+	matcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+		desc     string
+	}{
+		{"app.log", true, "wildcard pattern"},
+		{"keep.log", false, "negated pattern re-includes"},
+		{"sub/keep.log", false, "negated pattern applies at any depth"},
+		{"src/debug.log", false, "nested negation overrides root pattern"},
+		{"debug.log", true, "nested negation does not apply in root"},
+		{"generated/code.go", true, "anchored directory pattern"},
+		{"src/generated/code.go", false, "anchored pattern does not match deeper"},
+		{"logs/keep.log", true, "file in ignored directory cannot be re-included"},
+		{"docs/guide.md", true, "pattern with slash is relative to its file"},
+		{"docs/README.md", false, "negated pattern with slash"},
+		{"docs/sub/guide.md", false, "single star does not cross directories"},
+		{"#literal.go", true, "escaped hash"},
+		{"local.go", false, "nested pattern does not apply in root"},
+		{"src/local.go", false, ".agentdxignore overrides .gitignore in the same directory"},
+		{"src/fixtures/data.json", true, ".agentdxignore pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			result := matcher.ShouldIgnore(tt.path)
+			if result != tt.expected {
+				t.Errorf("ShouldIgnore(%q) = %v, expected %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_DirectoryOnlyPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("out/\n"), 0644); err != nil {
+		t.Fatalf("failed to create .gitignore: %v", err)
+	}
+
+	matcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	if !matcher.ShouldIgnoreEntry("out", true) {
+		t.Error("expected directory out to be ignored")
+	}
+	if matcher.ShouldIgnoreEntry("out", false) {
+		t.Error("expected file out not to be ignored by a directory pattern")
+	}
+}
+
+func TestIgnoreMatcher_Reload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gitignorePath := filepath.Join(tmpDir, ".gitignore")
+	if err := os.WriteFile(gitignorePath, []byte("*.tmp\n"), 0644); err != nil {
+		t.Fatalf("failed to create .gitignore: %v", err)
+	}
+
+	matcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	if !matcher.ShouldIgnore("a.tmp") || matcher.ShouldIgnore("a.go") {
+		t.Fatal("unexpected matches before reload")
+	}
+
+	if err := os.WriteFile(gitignorePath, []byte("*.go\n"), 0644); err != nil {
+		t.Fatalf("failed to update .gitignore: %v", err)
+	}
+	if err := matcher.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if matcher.ShouldIgnore("a.tmp") || !matcher.ShouldIgnore("a.go") {
+		t.Error("expected reload to apply the updated patterns")
+	}
+}
@@ -7,13 +7,18 @@ import (
 	"time"
 
 	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
 )
 
 type Indexer struct {
 	root    string
 	store   store.CodeStore
-	chunker *Chunker
+	chunker FileChunker
 	scanner *Scanner
+
+	// Chunk metadata sources; see SetMetadata
+	symbols   trace.SymbolExtractor
+	packageOf func(path string) string
 }
 
 type IndexStats struct {
@@ -26,18 +31,44 @@ type IndexStats struct {
 
 // ProgressInfo contains progress information for indexing
 type ProgressInfo struct {
-	Current     int    // Current file number (1-indexed)
-	Total       int    // Total number of files
-	CurrentFile string // Path of current file being processed
+	Current      int     // Current file number (1-indexed)
+	Total        int     // Total number of files
+	CurrentFile  string  // Path of current file being processed
+	Chunks       int     // Chunks stored so far
+	ChunksPerSec float64 // Chunks stored per second since the scan started
 }
 
 // ProgressCallback is called for each file during indexing
 type ProgressCallback func(info ProgressInfo)
 
+// Phases of the initial scan reported in progress events.
+const (
+	PhaseIndex   = "index"   // indexing files
+	PhaseSymbols = "symbols" // updating the symbol index
+	PhaseDone    = "done"    // initial scan complete
+)
+
+// ProgressEvent is a progress update of the initial scan, written as NDJSON
+// by 'agentdx watch --progress json' and sent over the dashboard's SSE
+// stream.
+type ProgressEvent struct {
+	Phase        string  `json:"phase"`
+	Workspace    string  `json:"workspace,omitempty"`
+	File         string  `json:"file,omitempty"`
+	Current      int     `json:"current"`
+	Total        int     `json:"total"`
+	Chunks       int     `json:"chunks,omitempty"`
+	ChunksPerSec float64 `json:"chunks_per_sec,omitempty"`
+}
+
+// bulkChunks is the number of chunks of new files stored at once on the
+// first index of a project.
+const bulkChunks = 5000
+
 func NewIndexer(
 	root string,
 	st store.CodeStore,
-	chunker *Chunker,
+	chunker FileChunker,
 	scanner *Scanner,
 ) *Indexer {
 	return &Indexer{
@@ -78,17 +109,48 @@ func (idx *Indexer) IndexAllWithProgress(ctx context.Context, onProgress Progres
 
 	total := len(files)
 
+	// Every file of an empty index is new: store them in bulk rather than
+	// file by file
+	var bulk *bulkBatch
+	if len(existingDocs) == 0 {
+		if _, ok := idx.store.(store.BulkSaver); ok {
+			bulk = &bulkBatch{}
+		}
+	}
+
 	// Index new/modified files
 	for i, file := range files {
 		// Report progress
 		if onProgress != nil {
+			var rate float64
+			if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+				rate = float64(stats.ChunksCreated) / elapsed
+			}
 			onProgress(ProgressInfo{
-				Current:     i + 1,
-				Total:       total,
-				CurrentFile: file.Path,
+				Current:      i + 1,
+				Total:        total,
+				CurrentFile:  file.Path,
+				Chunks:       stats.ChunksCreated,
+				ChunksPerSec: rate,
 			})
 		}
 
+		if bulk != nil {
+			chunks, doc := idx.fileChunks(ctx, file)
+			stats.FilesIndexed++
+			if len(chunks) == 0 {
+				continue
+			}
+			bulk.chunks = append(bulk.chunks, chunks...)
+			bulk.docs = append(bulk.docs, doc)
+			if len(bulk.chunks) >= bulkChunks {
+				if err := idx.flushBulk(ctx, bulk, stats); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
 		// Check if file needs reindexing
 		doc, err := idx.store.GetDocument(ctx, file.Path)
 		if err != nil {
@@ -113,6 +175,12 @@ func (idx *Indexer) IndexAllWithProgress(ctx context.Context, onProgress Progres
 		delete(existingMap, file.Path)
 	}
 
+	if bulk != nil {
+		if err := idx.flushBulk(ctx, bulk, stats); err != nil {
+			return nil, err
+		}
+	}
+
 	// Remove deleted files
 	for path := range existingMap {
 		if err := idx.RemoveFile(ctx, path); err != nil {
@@ -128,15 +196,60 @@ func (idx *Indexer) IndexAllWithProgress(ctx context.Context, onProgress Progres
 
 // IndexFile indexes a single file
 func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
+	// Reuse the chunks of a renamed file
+	if chunks, ok := idx.relinkRenamed(ctx, file); ok {
+		return chunks, nil
+	}
+
 	// Remove existing chunks for this file
 	if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
 		return 0, fmt.Errorf("failed to delete existing chunks: %w", err)
 	}
 
-	// Chunk the file
+	chunks, doc := idx.fileChunks(ctx, file)
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	// Save chunks
+	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
+		return 0, fmt.Errorf("failed to save chunks: %w", err)
+	}
+
+	// Save document metadata
+	if err := idx.store.SaveDocument(ctx, doc); err != nil {
+		return 0, fmt.Errorf("failed to save document: %w", err)
+	}
+
+	return len(chunks), nil
+}
+
+// bulkBatch holds the chunks and documents of new files until they are
+// stored at once.
+type bulkBatch struct {
+	chunks []store.Chunk
+	docs   []store.Document
+}
+
+// flushBulk stores the files of batch and empties it.
+func (idx *Indexer) flushBulk(ctx context.Context, batch *bulkBatch, stats *IndexStats) error {
+	if len(batch.docs) == 0 {
+		return nil
+	}
+	if err := store.SaveBulk(ctx, idx.store, batch.chunks, batch.docs); err != nil {
+		return fmt.Errorf("failed to save %d files: %w", len(batch.docs), err)
+	}
+	stats.ChunksCreated += len(batch.chunks)
+	batch.chunks, batch.docs = batch.chunks[:0], batch.docs[:0]
+	return nil
+}
+
+// fileChunks chunks a file and returns its chunks and document, or no
+// chunks when the file has no content to index.
+func (idx *Indexer) fileChunks(ctx context.Context, file FileInfo) ([]store.Chunk, store.Document) {
 	chunkInfos := idx.chunker.ChunkWithContext(file.Path, file.Content)
 	if len(chunkInfos) == 0 {
-		return 0, nil
+		return nil, store.Document{}
 	}
 
 	// Create store chunks (no embeddings for FTS)
@@ -151,30 +264,22 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 			StartLine: info.StartLine,
 			EndLine:   info.EndLine,
 			Content:   info.Content,
+			Doc:       DocComments(info.FilePath, info.Content),
+			Type:      store.ChunkTypeOf(info.FilePath),
 			Hash:      info.Hash,
 			UpdatedAt: now,
 		}
 		chunkIDs[i] = info.ID
 	}
+	idx.annotateChunks(ctx, file, chunks)
 
-	// Save chunks
-	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
-		return 0, fmt.Errorf("failed to save chunks: %w", err)
-	}
-
-	// Save document metadata
-	doc := store.Document{
+	return chunks, store.Document{
 		Path:     file.Path,
 		Hash:     file.Hash,
 		ModTime:  time.Unix(file.ModTime, 0),
 		ChunkIDs: chunkIDs,
+		Encoding: file.Encoding,
 	}
-
-	if err := idx.store.SaveDocument(ctx, doc); err != nil {
-		return 0, fmt.Errorf("failed to save document: %w", err)
-	}
-
-	return len(chunks), nil
 }
 
 // RemoveFile removes a file from the index
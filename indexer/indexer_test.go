@@ -0,0 +1,65 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestIndexer_FirstIndexInBulk(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	for i := range 20 {
+		content := fmt.Sprintf("package main\n\nfunc Bulk%d() {}\n", i)
+		if err := os.WriteFile(filepath.Join(root, fmt.Sprintf("f%d.go", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignore, err := NewIgnoreMatcher(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndexer(root, st, NewChunker(0, 0), NewScanner(root, ignore))
+
+	var last ProgressInfo
+	stats, err := idx.IndexAllWithProgress(ctx, func(info ProgressInfo) { last = info })
+	if err != nil {
+		t.Fatalf("IndexAllWithProgress failed: %v", err)
+	}
+	if stats.FilesIndexed != 20 || stats.ChunksCreated != 20 {
+		t.Errorf("expected 20 files and chunks indexed, got %+v", stats)
+	}
+	if last.Current != 20 || last.Total != 20 {
+		t.Errorf("expected progress up to 20/20, got %+v", last)
+	}
+	paths, err := st.ListDocuments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 20 {
+		t.Errorf("expected 20 documents, got %d", len(paths))
+	}
+	results, err := st.SearchFTS(ctx, "Bulk7", 5)
+	if err != nil || len(results) == 0 || results[0].Chunk.FilePath != "f7.go" {
+		t.Errorf("expected f7.go to be searchable, got %+v (%v)", results, err)
+	}
+
+	// The next scan finds every file indexed
+	stats, err = idx.IndexAll(ctx)
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if stats.FilesIndexed != 0 || stats.FilesRemoved != 0 {
+		t.Errorf("expected nothing to index again, got %+v", stats)
+	}
+}
@@ -0,0 +1,106 @@
+package indexer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownExtensions = map[string]bool{".md": true, ".mdx": true, ".markdown": true}
+	markdownHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t#]*$`)
+)
+
+// isMarkdown reports whether filePath is a Markdown file.
+func isMarkdown(filePath string) bool {
+	return markdownExtensions[strings.ToLower(filepath.Ext(filePath))]
+}
+
+// MarkdownChunker splits Markdown files on headings so that sections are kept
+// whole whenever they fit in a chunk. Other files are chunked by next.
+type MarkdownChunker struct {
+	fallback *Chunker
+	next     FileChunker
+}
+
+// NewMarkdownChunker creates a heading-aware chunker using fallback for
+// sections larger than the configured chunk size and next for other files.
+func NewMarkdownChunker(fallback *Chunker, next FileChunker) *MarkdownChunker {
+	return &MarkdownChunker{fallback: fallback, next: next}
+}
+
+// Chunk splits Markdown content into chunks aligned with headings.
+func (c *MarkdownChunker) Chunk(filePath string, content string) []ChunkInfo {
+	if !isMarkdown(filePath) {
+		return c.next.Chunk(filePath, content)
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	boundaries := markdownHeadingBoundaries(content)
+	if len(boundaries) == 0 {
+		return c.fallback.Chunk(filePath, content)
+	}
+	return packSegments(c.fallback, filePath, content, boundaries)
+}
+
+// ChunkWithContext adds the file path header to each chunk.
+func (c *MarkdownChunker) ChunkWithContext(filePath string, content string) []ChunkInfo {
+	if !isMarkdown(filePath) {
+		return c.next.ChunkWithContext(filePath, content)
+	}
+	chunks := c.Chunk(filePath, content)
+	for i := range chunks {
+		chunks[i].Content = chunkHeader(filePath) + chunks[i].Content
+	}
+	return chunks
+}
+
+// markdownHeadingBoundaries returns the byte offsets of the lines starting
+// headings, except the first line.
+func markdownHeadingBoundaries(content string) []int {
+	var boundaries []int
+	eachMarkdownHeading(content, func(offset int, _ string) {
+		if offset > 0 {
+			boundaries = append(boundaries, offset)
+		}
+	})
+	return boundaries
+}
+
+// markdownHeadings returns the text of the headings in content, one per line.
+func markdownHeadings(content string) string {
+	var headings []string
+	eachMarkdownHeading(content, func(_ int, text string) {
+		if text != "" {
+			headings = append(headings, text)
+		}
+	})
+	return strings.Join(headings, "\n")
+}
+
+// eachMarkdownHeading calls fn with the byte offset and text of each ATX
+// heading (# Title) in content, skipping fenced code blocks.
+func eachMarkdownHeading(content string, fn func(offset int, text string)) {
+	var fence string
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if m := markdownHeading.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+				fn(start, m[2])
+			}
+		}
+	}
+}
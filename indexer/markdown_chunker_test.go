@@ -0,0 +1,58 @@
+package indexer
+
+import (
+	"strings"
+	"testing"
+)
+
+const markdownSource = `# Guide
+
+Intro text.
+
+## Install
+
+` + "```sh\n# not a heading\nmake install\n```" + `
+
+## Usage
+
+Run the tool.
+`
+
+func TestMarkdownChunker_SplitsOnHeadings(t *testing.T) {
+	base := NewChunker(16, 0) // 64 characters: one section per chunk
+	c := NewMarkdownChunker(base, base)
+
+	chunks := c.Chunk("docs/guide.md", markdownSource)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	for i, prefix := range []string{"# Guide", "## Install", "## Usage"} {
+		if !strings.HasPrefix(chunks[i].Content, prefix) {
+			t.Errorf("chunk %d starts with %q, want %q", i, chunks[i].Content, prefix)
+		}
+	}
+	if chunks[1].StartLine != 5 || chunks[2].StartLine != 12 {
+		t.Errorf("unexpected start lines %d and %d", chunks[1].StartLine, chunks[2].StartLine)
+	}
+	if !strings.Contains(chunks[1].Content, "# not a heading") {
+		t.Error("expected a fenced code block to stay in its section")
+	}
+
+	// Small sections are packed together
+	if chunks := NewMarkdownChunker(NewChunker(512, 0), base).Chunk("docs/guide.md", markdownSource); len(chunks) != 1 {
+		t.Errorf("expected 1 chunk, got %d", len(chunks))
+	}
+}
+
+func TestMarkdownChunker_OtherFiles(t *testing.T) {
+	base := NewChunker(512, 50)
+	c := NewMarkdownChunker(base, NewASTChunker(base))
+
+	chunks := c.Chunk("greet.go", astTestSource)
+	if len(chunks) == 0 || strings.HasPrefix(chunks[0].Content, "File: ") {
+		t.Fatalf("expected Go chunks from the next chunker, got %+v", chunks)
+	}
+	if got := c.ChunkWithContext("greet.go", astTestSource); !strings.HasPrefix(got[0].Content, chunkHeader("greet.go")) {
+		t.Error("expected the file header from ChunkWithContext")
+	}
+}
@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// SetMetadata makes the indexer record the dominant symbol of each chunk,
+// found by symbols, and the package of its file, named by packageOf. Either
+// may be nil.
+func (idx *Indexer) SetMetadata(symbols trace.SymbolExtractor, packageOf func(path string) string) {
+	idx.symbols = symbols
+	idx.packageOf = packageOf
+}
+
+// annotateChunks sets the symbol and package of the chunks of file.
+func (idx *Indexer) annotateChunks(ctx context.Context, file FileInfo, chunks []store.Chunk) {
+	var pkg string
+	if idx.packageOf != nil {
+		pkg = idx.packageOf(file.Path)
+	}
+	var symbols []trace.Symbol
+	if idx.symbols != nil {
+		// Files the extractor cannot parse simply get no symbols
+		symbols, _ = idx.symbols.ExtractSymbols(ctx, file.Path, file.Content)
+	}
+	for i := range chunks {
+		chunks[i].Package = pkg
+		if sym, ok := dominantSymbol(symbols, chunks[i].StartLine, chunks[i].EndLine); ok {
+			chunks[i].Symbol = sym.Name
+			chunks[i].SymbolKind = string(sym.Kind)
+		}
+	}
+}
+
+// dominantSymbol returns the symbol a chunk spanning lines start to end is
+// about: among the symbols defined in the chunk, the one covering most of
+// its lines, the first on ties; otherwise the innermost symbol enclosing
+// the chunk. Symbols without an end line, as the regex extractor finds
+// them, are taken to run to the next symbol.
+func dominantSymbol(symbols []trace.Symbol, start, end int) (trace.Symbol, bool) {
+	var best trace.Symbol
+	bestLines := -1
+	var enclosing trace.Symbol
+	enclosingSpan := -1
+	for _, sym := range symbols {
+		symEnd := sym.EndLine
+		if symEnd < sym.Line {
+			symEnd = nextSymbolLine(symbols, sym.Line, end) - 1
+		}
+		switch {
+		case sym.Line >= start && sym.Line <= end:
+			lines := min(symEnd, end) - sym.Line
+			if lines > bestLines || (lines == bestLines && sym.Line < best.Line) {
+				best, bestLines = sym, lines
+			}
+		case sym.Line < start && symEnd >= start:
+			if span := symEnd - sym.Line; enclosingSpan < 0 || span < enclosingSpan {
+				enclosing, enclosingSpan = sym, span
+			}
+		}
+	}
+	if bestLines >= 0 {
+		return best, true
+	}
+	return enclosing, enclosingSpan >= 0
+}
+
+// nextSymbolLine returns the line of the first symbol defined after line, or
+// the line after end when there is none.
+func nextSymbolLine(symbols []trace.Symbol, line, end int) int {
+	next := end + 1
+	for _, sym := range symbols {
+		if sym.Line > line && sym.Line < next {
+			next = sym.Line
+		}
+	}
+	return next
+}
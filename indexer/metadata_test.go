@@ -0,0 +1,37 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/trace"
+)
+
+func TestDominantSymbol(t *testing.T) {
+	symbols := []trace.Symbol{
+		{Name: "Server", Kind: trace.KindType, Line: 3, EndLine: 40},
+		{Name: "Start", Kind: trace.KindMethod, Line: 10, EndLine: 12},
+		{Name: "handle", Kind: trace.KindMethod, Line: 14, EndLine: 30},
+		{Name: "helper", Kind: trace.KindFunction, Line: 50},
+		{Name: "other", Kind: trace.KindFunction, Line: 60},
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+	}{
+		{"most lines defined in chunk", 9, 32, "handle"},
+		{"innermost enclosing symbol", 20, 25, "handle"},
+		{"enclosing type", 32, 38, "Server"},
+		{"symbol without end line runs to next", 52, 55, "helper"},
+		{"no symbol", 41, 45, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sym, ok := dominantSymbol(symbols, tt.start, tt.end)
+			if ok != (tt.want != "") || sym.Name != tt.want {
+				t.Errorf("dominantSymbol(%d, %d) = %q, %v, want %q", tt.start, tt.end, sym.Name, ok, tt.want)
+			}
+		})
+	}
+}
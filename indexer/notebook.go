@@ -0,0 +1,158 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxNotebookSize is the size up to which notebooks are indexed. It is
+// larger than maxFileSize since most of a notebook is usually the outputs
+// of its cells, which are not indexed.
+const maxNotebookSize = 20 * 1024 * 1024 // 20 MB
+
+// notebookMarker matches the line starting a cell in the text of a
+// notebook, as written by NotebookText.
+var notebookMarker = regexp.MustCompile(`^# %% \[(\w+)\] cell (\d+)$`)
+
+// IsNotebook reports whether filePath is a Jupyter notebook.
+func IsNotebook(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".ipynb")
+}
+
+// notebook is the part of a Jupyter notebook (nbformat 4) that is indexed.
+type notebook struct {
+	Cells []struct {
+		Type   string          `json:"cell_type"`
+		Source json.RawMessage `json:"source"`
+	} `json:"cells"`
+}
+
+// NotebookText returns the text of a Jupyter notebook: its code, Markdown
+// and raw cells in order, each after a line "# %% [type] cell N" numbering
+// cells from 1, the percent format editors and jupytext read as cells.
+// Outputs and metadata are left out. Line numbers of chunks and search
+// results are lines of this text; NotebookCells maps them back to cells.
+// ok is false when data is not an nbformat 4 notebook.
+func NotebookText(data []byte) (text string, ok bool) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil || nb.Cells == nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	for i, cell := range nb.Cells {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %%%% [%s] cell %d\n", cell.Type, i+1)
+		source := cellSource(cell.Source)
+		if source != "" {
+			b.WriteString(strings.TrimRight(source, "\n") + "\n")
+		}
+	}
+	return b.String(), true
+}
+
+// cellSource returns the source of a cell, stored as a string or as a list
+// of lines.
+func cellSource(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var lines []string
+	if json.Unmarshal(raw, &lines) == nil {
+		return strings.Join(lines, "")
+	}
+	return ""
+}
+
+// DecodeFile returns the content of the file at relPath as UTF-8 text, like
+// DecodeText, with notebooks turned into their NotebookText. Notebooks that
+// fail to parse are decoded as they are.
+func DecodeFile(relPath string, data []byte) (content, enc string, ok bool) {
+	if IsNotebook(relPath) {
+		if text, ok := NotebookText(data); ok {
+			return text, "", true
+		}
+	}
+	return DecodeText(data)
+}
+
+// NotebookCells returns the numbers of the notebook cells that lines start
+// to end of its text span, or nil when lines has no cell markers there.
+func NotebookCells(lines []string, start, end int) []int {
+	var cells []int
+	for i, line := range lines {
+		n := i + 1
+		if n > end {
+			break
+		}
+		m := notebookMarker.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		cell, _ := strconv.Atoi(m[2])
+		if n <= start {
+			// Last cell starting before the range
+			cells = []int{cell}
+		} else {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// notebookCellBoundaries returns the byte offsets of the lines starting
+// cells in the text of a notebook, except the first line.
+func notebookCellBoundaries(content string) []int {
+	var boundaries []int
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if offset > 0 && notebookMarker.MatchString(strings.TrimSuffix(line, "\n")) {
+			boundaries = append(boundaries, offset)
+		}
+		offset += len(line)
+	}
+	return boundaries
+}
+
+// NotebookChunker splits the text of notebooks on cells so that cells are
+// kept whole whenever they fit in a chunk. Other files are chunked by next.
+type NotebookChunker struct {
+	fallback *Chunker
+	next     FileChunker
+}
+
+// NewNotebookChunker creates a cell-aware chunker using fallback for cells
+// larger than the configured chunk size and next for other files.
+func NewNotebookChunker(fallback *Chunker, next FileChunker) *NotebookChunker {
+	return &NotebookChunker{fallback: fallback, next: next}
+}
+
+// Chunk splits the text of a notebook into chunks aligned with cells.
+func (c *NotebookChunker) Chunk(filePath string, content string) []ChunkInfo {
+	if !IsNotebook(filePath) {
+		return c.next.Chunk(filePath, content)
+	}
+	if len(content) == 0 {
+		return nil
+	}
+	return packSegments(c.fallback, filePath, content, notebookCellBoundaries(content))
+}
+
+// ChunkWithContext adds the file path header to each chunk.
+func (c *NotebookChunker) ChunkWithContext(filePath string, content string) []ChunkInfo {
+	if !IsNotebook(filePath) {
+		return c.next.ChunkWithContext(filePath, content)
+	}
+	chunks := c.Chunk(filePath, content)
+	for i := range chunks {
+		chunks[i].Content = chunkHeader(filePath) + chunks[i].Content
+	}
+	return chunks
+}
@@ -0,0 +1,104 @@
+package indexer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Churn model\n", "\n", "Loads the customers."]},
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "outputs": [{"output_type": "stream", "text": ["loaded\n"]}],
+   "source": ["import pandas as pd\n", "customers = pd.read_csv(\"customers.csv\")"]},
+  {"cell_type": "code", "execution_count": null, "metadata": {}, "outputs": [], "source": []},
+  {"cell_type": "code", "execution_count": 2, "metadata": {}, "outputs": [], "source": "def churn_rate(df):\n    return df.churned.mean()\n"}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestNotebookText(t *testing.T) {
+	text, ok := NotebookText([]byte(testNotebook))
+	if !ok {
+		t.Fatal("expected the notebook to parse")
+	}
+	want := `# %% [markdown] cell 1
+# Churn model
+
+Loads the customers.
+
+# %% [code] cell 2
+import pandas as pd
+customers = pd.read_csv("customers.csv")
+
+# %% [code] cell 3
+
+# %% [code] cell 4
+def churn_rate(df):
+    return df.churned.mean()
+`
+	if text != want {
+		t.Errorf("unexpected notebook text:\n%s", text)
+	}
+	if strings.Contains(text, "loaded") {
+		t.Error("expected outputs to be left out")
+	}
+
+	if _, ok := NotebookText([]byte(`{"not": "a notebook"}`)); ok {
+		t.Error("expected JSON without cells to be rejected")
+	}
+	// Notebooks that fail to parse are indexed as they are
+	if content, _, ok := DecodeFile("broken.ipynb", []byte("{")); !ok || content != "{" {
+		t.Errorf("expected the raw content of a broken notebook, got %q", content)
+	}
+}
+
+func TestNotebookCells(t *testing.T) {
+	text, _ := NotebookText([]byte(testNotebook))
+	lines := strings.Split(text, "\n")
+
+	tests := []struct {
+		start, end int
+		want       []int
+	}{
+		{1, 4, []int{1}},
+		{7, 8, []int{2}}, // inside cell 2
+		{8, 13, []int{2, 3, 4}},
+		{14, 15, []int{4}},
+	}
+	for _, tt := range tests {
+		if got := NotebookCells(lines, tt.start, tt.end); !slices.Equal(got, tt.want) {
+			t.Errorf("NotebookCells(%d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+	if got := NotebookCells([]string{"package main"}, 1, 1); got != nil {
+		t.Errorf("expected no cells outside notebooks, got %v", got)
+	}
+}
+
+func TestNotebookChunker(t *testing.T) {
+	text, _ := NotebookText([]byte(testNotebook))
+	// Small enough to split every cell into its own chunk
+	chunker := NewFileChunker(StrategyAST, 20, 0)
+	chunks := chunker.ChunkWithContext("churn.ipynb", text)
+	if len(chunks) < 3 {
+		t.Fatalf("expected chunks aligned with cells, got %d", len(chunks))
+	}
+	lines := strings.Split(text, "\n")
+	for _, c := range chunks {
+		first := lines[c.StartLine-1]
+		if !notebookMarker.MatchString(first) {
+			t.Errorf("chunk %d-%d does not start at a cell: %q", c.StartLine, c.EndLine, first)
+		}
+		if !strings.HasPrefix(c.Content, "File: churn.ipynb\n\n") {
+			t.Errorf("expected the file header, got %q", c.Content)
+		}
+	}
+
+	// Other files are left to the next chunker
+	if got := chunker.Chunk("main.go", "package main\n"); len(got) != 1 || got[0].StartLine != 1 {
+		t.Errorf("unexpected chunks of a Go file: %+v", got)
+	}
+}
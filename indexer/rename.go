@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// relinkRenamed detects that file was renamed from a path that is indexed
+// with the same content but no longer exists, and moves that path's chunks
+// to file instead of re-chunking it. It reports whether file was relinked.
+func (idx *Indexer) relinkRenamed(ctx context.Context, file FileInfo) (int, bool) {
+	rs, ok := idx.store.(store.RenameStore)
+	if !ok {
+		return 0, false
+	}
+	if doc, err := idx.store.GetDocument(ctx, file.Path); err != nil || doc != nil {
+		return 0, false // Not a new file
+	}
+	docs, err := rs.DocumentsByHash(ctx, file.Hash)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, doc := range docs {
+		if !idx.renamedFrom(doc, file.Path) {
+			continue
+		}
+		relink := func(c store.Chunk) store.Chunk {
+			c.ID = relinkPath(c.ID, doc.Path, file.Path)
+			if header := chunkHeader(doc.Path); strings.HasPrefix(c.Content, header) {
+				c.Content = chunkHeader(file.Path) + strings.TrimPrefix(c.Content, header)
+			}
+			if idx.packageOf != nil {
+				c.Package = idx.packageOf(file.Path)
+			}
+			return c
+		}
+		if err := rs.RenameFile(ctx, doc.Path, file.Path, relink); err != nil {
+			log.Printf("Failed to relink %s to %s, re-indexing: %v", doc.Path, file.Path, err)
+			return 0, false
+		}
+
+		renamed, err := idx.store.GetDocument(ctx, file.Path)
+		if err != nil || renamed == nil {
+			return 0, false
+		}
+		renamed.ModTime = time.Unix(file.ModTime, 0)
+		if err := idx.store.SaveDocument(ctx, *renamed); err != nil {
+			return 0, false
+		}
+		log.Printf("Relinked %s to %s (%d chunks)", doc.Path, file.Path, len(renamed.ChunkIDs))
+		return len(renamed.ChunkIDs), true
+	}
+	return 0, false
+}
+
+// renamedFrom reports whether doc, which has the same content as newPath,
+// is the file newPath was renamed from. The old path must be gone from disk
+// and keep its extension, since chunking depends on the language.
+func (idx *Indexer) renamedFrom(doc store.Document, newPath string) bool {
+	if doc.Path == newPath || len(doc.ChunkIDs) == 0 {
+		return false
+	}
+	if !strings.EqualFold(filepath.Ext(doc.Path), filepath.Ext(newPath)) {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(idx.root, doc.Path))
+	return os.IsNotExist(err)
+}
+
+// relinkPath rewrites a chunk ID derived from oldPath to derive from newPath.
+func relinkPath(id, oldPath, newPath string) string {
+	if suffix, ok := strings.CutPrefix(id, oldPath+"_"); ok {
+		return newPath + "_" + suffix
+	}
+	return id
+}
@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestIndexer_RelinksRenamedFile(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	if err := os.WriteFile(filepath.Join(root, "old.go"), []byte("package main\n\nfunc RelinkMe() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ignore, err := NewIgnoreMatcher(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := NewScanner(root, ignore)
+	idx := NewIndexer(root, st, NewChunker(0, 0), scanner)
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(root, "old.go"), filepath.Join(root, "pkg", "new.go")); err != nil {
+		t.Fatal(err)
+	}
+	file, err := scanner.ScanFile("pkg/new.go")
+	if err != nil || file == nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if _, ok := idx.relinkRenamed(ctx, *file); !ok {
+		t.Fatal("expected pkg/new.go to be relinked")
+	}
+
+	results, err := st.SearchFTS(ctx, "RelinkMe", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	chunk := results[0].Chunk
+	if chunk.FilePath != "pkg/new.go" || !strings.HasPrefix(chunk.ID, "pkg/new.go_") || !strings.HasPrefix(chunk.Content, chunkHeader("pkg/new.go")) {
+		t.Errorf("expected chunk relinked to pkg/new.go, got %+v", chunk)
+	}
+	if doc, _ := st.GetDocument(ctx, "old.go"); doc != nil {
+		t.Errorf("expected old.go to be gone, got %+v", doc)
+	}
+
+	// Files with the same content that still exist are not renames
+	if err := os.WriteFile(filepath.Join(root, "copy.go"), []byte("package main\n\nfunc RelinkMe() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, _ = scanner.ScanFile("copy.go")
+	if _, ok := idx.relinkRenamed(ctx, *file); ok {
+		t.Error("expected a copy not to be relinked")
+	}
+}
@@ -8,7 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 const (
@@ -93,19 +94,24 @@ var SupportedExtensions = map[string]bool{
 	".proto":  true,
 	".tf":     true,
 	".hcl":    true,
+	".ipynb":  true, // Code and Markdown cells; see NotebookText
 }
 
 type FileInfo struct {
 	Path    string
 	Size    int64
 	ModTime int64
-	Hash    string
-	Content string
+	Hash    string // Of the file on disk, before transcoding
+	Content string // UTF-8
+	// Encoding the file was transcoded from, empty for UTF-8
+	Encoding string
 }
 
 type Scanner struct {
-	root   string
-	ignore *IgnoreMatcher
+	root    string
+	ignore  *IgnoreMatcher
+	keep    func(relPath string) bool
+	include []string // Path globs of an indexing profile; nil includes everything
 }
 
 func NewScanner(root string, ignore *IgnoreMatcher) *Scanner {
@@ -115,8 +121,120 @@ func NewScanner(root string, ignore *IgnoreMatcher) *Scanner {
 	}
 }
 
+// Filter returns a scanner whose Scan only yields files for which keep
+// returns true. ScanFile is not affected.
+func (s *Scanner) Filter(keep func(relPath string) bool) *Scanner {
+	return &Scanner{
+		root:    s.root,
+		ignore:  s.ignore,
+		keep:    keep,
+		include: s.include,
+	}
+}
+
+// Include returns a scanner whose Scan only yields files matching one of
+// the path globs of an indexing profile, without walking directories that
+// cannot contain matches. A glob naming a directory includes its files.
+// ScanFile is not affected.
+func (s *Scanner) Include(patterns []string) *Scanner {
+	return &Scanner{
+		root:    s.root,
+		ignore:  s.ignore,
+		keep:    s.keep,
+		include: patterns,
+	}
+}
+
+// Includes reports whether relPath matches the globs set by Include.
+func (s *Scanner) Includes(relPath string) bool {
+	if s.include == nil {
+		return true
+	}
+	relPath = filepath.ToSlash(relPath)
+	for _, p := range s.include {
+		p = strings.TrimSuffix(p, "/")
+		if ok, _ := doublestar.Match(p, relPath); ok {
+			return true
+		}
+		if ok, _ := doublestar.Match(p+"/**", relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// includesDir reports whether the directory relDir may contain files
+// matching the globs set by Include: it lies inside or above the static
+// base directory of one of them.
+func (s *Scanner) includesDir(relDir string) bool {
+	if s.include == nil {
+		return true
+	}
+	relDir = filepath.ToSlash(relDir)
+	for _, p := range s.include {
+		p = strings.TrimSuffix(p, "/")
+		base, _ := doublestar.SplitPattern(p)
+		// A glob without meta characters names a file or directory itself
+		if !strings.ContainsAny(p, "*?[{\\") {
+			base = p
+		}
+		if base == "." || relDir == base || strings.HasPrefix(relDir, base+"/") || strings.HasPrefix(base, relDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 	var files []FileInfo
+
+	skipped, err := s.walk(func(relPath, path string, info fs.FileInfo) {
+		// Read file content
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+
+		// Skip binary files
+		text, encoding, ok := DecodeFile(relPath, content)
+		if !ok {
+			return
+		}
+
+		// Calculate hash
+		hash := sha256.Sum256(content)
+
+		files = append(files, FileInfo{
+			Path:     relPath,
+			Size:     info.Size(),
+			ModTime:  info.ModTime().Unix(),
+			Hash:     hex.EncodeToString(hash[:]),
+			Content:  text,
+			Encoding: encoding,
+		})
+	})
+
+	return files, skipped, err
+}
+
+// Stat lists the files Scan would consider with their size and modification
+// time, without reading them. Binary files are not detected.
+func (s *Scanner) Stat() ([]FileInfo, error) {
+	var files []FileInfo
+	_, err := s.walk(func(relPath, _ string, info fs.FileInfo) {
+		files = append(files, FileInfo{
+			Path:    relPath,
+			Size:    info.Size(),
+			ModTime: info.ModTime().Unix(),
+		})
+	})
+	return files, err
+}
+
+// walk calls visit for every file that passes the ignore rules, extension,
+// filter, minified and size checks, and returns the files skipped by the
+// last two.
+func (s *Scanner) walk(visit func(relPath, path string, info fs.FileInfo)) ([]string, error) {
 	var skipped []string
 
 	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
@@ -130,7 +248,7 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 		}
 
 		// Skip ignored paths
-		if s.ignore.ShouldIgnore(relPath) {
+		if s.ignore.ShouldIgnoreEntry(relPath, d.IsDir()) {
 			if d.IsDir() {
 				return filepath.SkipDir
 			}
@@ -138,6 +256,9 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 		}
 
 		if d.IsDir() {
+			if relPath != "." && !s.includesDir(relPath) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -147,6 +268,13 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 			return nil
 		}
 
+		if s.keep != nil && !s.keep(relPath) {
+			return nil
+		}
+		if !s.Includes(relPath) {
+			return nil
+		}
+
 		// Skip minified files
 		if isMinifiedFile(relPath) {
 			skipped = append(skipped, relPath+" (minified)")
@@ -159,37 +287,29 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 		}
 
 		// Skip large files
-		if info.Size() > maxFileSize {
+		if info.Size() > maxSize(relPath) {
 			skipped = append(skipped, relPath+" (too large)")
 			return nil
 		}
 
-		// Read file content
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return nil
-		}
-
-		// Skip binary files
-		if !utf8.Valid(content) || containsNull(content) {
-			return nil
-		}
-
-		// Calculate hash
-		hash := sha256.Sum256(content)
-
-		files = append(files, FileInfo{
-			Path:    relPath,
-			Size:    info.Size(),
-			ModTime: info.ModTime().Unix(),
-			Hash:    hex.EncodeToString(hash[:]),
-			Content: string(content),
-		})
-
+		visit(relPath, path, info)
 		return nil
 	})
 
-	return files, skipped, err
+	return skipped, err
+}
+
+// Accepts reports whether Scan considers the file at relPath: it is not
+// ignored, has a supported extension and passes the filter. Size, minified
+// and binary checks are left to ScanFile.
+func (s *Scanner) Accepts(relPath string) bool {
+	if s.ignore.ShouldIgnore(relPath) {
+		return false
+	}
+	if !SupportedExtensions[strings.ToLower(filepath.Ext(relPath))] {
+		return false
+	}
+	return s.Includes(relPath) && (s.keep == nil || s.keep(relPath))
 }
 
 func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
@@ -205,7 +325,7 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	if info.Size() > maxFileSize {
+	if info.Size() > maxSize(relPath) {
 		return nil, nil // Skip large files
 	}
 
@@ -214,21 +334,31 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	if !utf8.Valid(content) || containsNull(content) {
+	text, encoding, ok := DecodeFile(relPath, content)
+	if !ok {
 		return nil, nil // Skip binary files
 	}
 
 	hash := sha256.Sum256(content)
 
 	return &FileInfo{
-		Path:    relPath,
-		Size:    info.Size(),
-		ModTime: info.ModTime().Unix(),
-		Hash:    hex.EncodeToString(hash[:]),
-		Content: string(content),
+		Path:     relPath,
+		Size:     info.Size(),
+		ModTime:  info.ModTime().Unix(),
+		Hash:     hex.EncodeToString(hash[:]),
+		Content:  text,
+		Encoding: encoding,
 	}, nil
 }
 
+// maxSize returns the size past which the file at relPath is not indexed.
+func maxSize(relPath string) int64 {
+	if IsNotebook(relPath) {
+		return maxNotebookSize
+	}
+	return maxFileSize
+}
+
 func containsNull(data []byte) bool {
 	for _, b := range data {
 		if b == 0 {
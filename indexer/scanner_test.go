@@ -3,6 +3,8 @@ package indexer
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -125,6 +127,78 @@ func TestScanner_IgnoreUnsupportedExtensions(t *testing.T) {
 	}
 }
 
+func TestScanner_Filter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"main.go", filepath.Join("pkg", "api", "api.go")} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	scanner := NewScanner(tmpDir, ignoreMatcher).Filter(func(relPath string) bool {
+		return strings.HasPrefix(filepath.ToSlash(relPath), "pkg/")
+	})
+	files, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	if len(files) != 1 || filepath.ToSlash(files[0].Path) != "pkg/api/api.go" {
+		t.Errorf("expected only pkg/api/api.go, got %+v", files)
+	}
+}
+
+func TestScanner_Include(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"main.go", "services/auth/auth.go", "services/auth/web/app.ts", "pkg/util.go", "web/app.ts"} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{})
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	scanner := NewScanner(tmpDir, ignoreMatcher).Include([]string{"services/**/*.go", "pkg"})
+	files, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f.Path))
+	}
+	sort.Strings(got)
+	want := []string{"pkg/util.go", "services/auth/auth.go"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if scanner.includesDir("web") || !scanner.includesDir("services") || !scanner.includesDir(filepath.Join("services", "auth")) {
+		t.Error("expected only directories that may hold matches to be walked")
+	}
+	if scanner.Accepts("main.go") || !scanner.Accepts(filepath.Join("pkg", "util.go")) {
+		t.Error("expected Accepts to apply the profile")
+	}
+}
+
 func TestScanner_ScanFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
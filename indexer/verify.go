@@ -0,0 +1,139 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Kinds of discrepancies between the index and the files on disk.
+const (
+	IssueMissingChunks = "missing_chunks" // document references chunks that are not indexed
+	IssueHashMismatch  = "hash_mismatch"  // file changed on disk since it was indexed
+	IssueNotIndexed    = "not_indexed"    // file on disk has no document
+	IssueNotOnDisk     = "not_on_disk"    // indexed file no longer exists or is ignored
+)
+
+// Issue is a discrepancy found by Verify.
+type Issue struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// VerifyReport is the result of Verify.
+type VerifyReport struct {
+	Documents int     `json:"documents"` // indexed files checked
+	Files     int     `json:"files"`     // files on disk checked
+	Issues    []Issue `json:"issues"`
+}
+
+// Verify cross-checks the documents of the index with their chunks and with
+// the files the scanner finds on disk. It reads every file, like IndexAll,
+// but changes nothing.
+func (idx *Indexer) Verify(ctx context.Context) (*VerifyReport, error) {
+	files, _, err := idx.scanner.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	paths, err := idx.store.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	report := &VerifyReport{Documents: len(paths), Files: len(files), Issues: []Issue{}}
+	onDisk := make(map[string]FileInfo, len(files))
+	for _, f := range files {
+		onDisk[f.Path] = f
+	}
+
+	indexed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		indexed[path] = true
+		doc, err := idx.store.GetDocument(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document %s: %w", path, err)
+		}
+		if doc == nil {
+			continue // Removed concurrently
+		}
+
+		chunks, err := idx.store.GetChunksForFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chunks of %s: %w", path, err)
+		}
+		present := make(map[string]bool, len(chunks))
+		for _, c := range chunks {
+			present[c.ID] = true
+		}
+		missing := 0
+		for _, id := range doc.ChunkIDs {
+			if !present[id] {
+				missing++
+			}
+		}
+		// A file gone from disk is removed whatever state its chunks are in
+		file, ok := onDisk[path]
+		switch {
+		case !ok:
+			report.Issues = append(report.Issues, Issue{Path: path, Kind: IssueNotOnDisk})
+		case missing > 0:
+			report.Issues = append(report.Issues, Issue{
+				Path:   path,
+				Kind:   IssueMissingChunks,
+				Detail: fmt.Sprintf("%d of %d chunks missing", missing, len(doc.ChunkIDs)),
+			})
+		case file.Hash != doc.Hash:
+			report.Issues = append(report.Issues, Issue{Path: path, Kind: IssueHashMismatch})
+		}
+	}
+
+	for _, f := range files {
+		// Files without chunks, such as empty files, are never indexed
+		if !indexed[f.Path] && len(idx.chunker.ChunkWithContext(f.Path, f.Content)) > 0 {
+			report.Issues = append(report.Issues, Issue{Path: f.Path, Kind: IssueNotIndexed})
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Path < report.Issues[j].Path
+	})
+	return report, nil
+}
+
+// Fix repairs issues found by Verify: files on disk are indexed again and
+// files gone from disk are removed. It returns the number of issues fixed
+// and the first error.
+func (idx *Indexer) Fix(ctx context.Context, issues []Issue) (int, error) {
+	fixed := 0
+	var firstErr error
+	for _, issue := range issues {
+		var err error
+		if issue.Kind == IssueNotOnDisk {
+			err = idx.RemoveFile(ctx, issue.Path)
+		} else {
+			err = idx.reindexPath(ctx, issue.Path)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to fix %s: %w", issue.Path, err)
+			}
+			continue
+		}
+		fixed++
+	}
+	return fixed, firstErr
+}
+
+// reindexPath reads the file at path and indexes it, even if unchanged.
+func (idx *Indexer) reindexPath(ctx context.Context, path string) error {
+	file, err := idx.scanner.ScanFile(path)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return idx.RemoveFile(ctx, path)
+	}
+	_, err = idx.IndexFile(ctx, *file)
+	return err
+}
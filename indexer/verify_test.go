@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestIndexer_VerifyAndFix(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ok.go", "package main\n\nfunc Healthy() {}\n")
+	write("lost.go", "package main\n\nfunc LostChunks() {}\n")
+	write("edited.go", "package main\n\nfunc Before() {}\n")
+	write("gone.go", "package main\n\nfunc Gone() {}\n")
+	write("empty.go", "")
+
+	ignore, err := NewIgnoreMatcher(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndexer(root, st, NewChunker(0, 0), NewScanner(root, ignore))
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	// Simulate a crash between saving chunks and documents, edits made while
+	// no watcher ran, and files added or removed meanwhile
+	if err := st.DeleteByFile(ctx, "lost.go"); err != nil {
+		t.Fatal(err)
+	}
+	write("edited.go", "package main\n\nfunc After() {}\n")
+	if err := os.Remove(filepath.Join(root, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+	write("new.go", "package main\n\nfunc Added() {}\n")
+
+	report, err := idx.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	want := map[string]string{
+		"edited.go": IssueHashMismatch,
+		"gone.go":   IssueNotOnDisk,
+		"lost.go":   IssueMissingChunks,
+		"new.go":    IssueNotIndexed,
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if want[issue.Path] != issue.Kind {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+
+	fixed, err := idx.Fix(ctx, report.Issues)
+	if err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	if fixed != len(want) {
+		t.Errorf("expected %d issues fixed, got %d", len(want), fixed)
+	}
+	report, err = idx.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("expected no issues after fixing, got %+v", report.Issues)
+	}
+	if results, _ := st.SearchFTS(ctx, "LostChunks", 10); len(results) != 1 {
+		t.Errorf("expected lost.go to be indexed again, got %+v", results)
+	}
+}
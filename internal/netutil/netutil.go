@@ -0,0 +1,14 @@
+// Package netutil holds the network helpers shared by the agentdx servers.
+package netutil
+
+import "net"
+
+// IsLoopback reports whether host, a name or an IP address without a port,
+// only accepts local connections.
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -3,6 +3,9 @@ package localsetup
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -15,6 +18,7 @@ const (
 	defaultPostgresHost     = "localhost"
 	defaultPostgresPort     = 55432
 	defaultPostgresDB       = "postgres"
+	defaultSSLMode          = "disable"
 
 	// Retry constants
 	initialBackoff = 500 * time.Millisecond
@@ -68,8 +72,7 @@ func CreateDatabase(dsn, dbName string) error {
 	}
 
 	// Create the database (can't use parameterized query for CREATE DATABASE)
-	// dbName is derived from ToSlug() which only allows alphanumeric and underscore
-	_, err = conn.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s", dbName))
+	_, err = conn.Exec(ctx, "CREATE DATABASE "+pgx.Identifier{dbName}.Sanitize())
 	if err != nil {
 		return fmt.Errorf("failed to create database %s: %w", dbName, err)
 	}
@@ -77,34 +80,46 @@ func CreateDatabase(dsn, dbName string) error {
 	return nil
 }
 
+// buildDSN returns a connection URL for the default agentdx credentials.
+// Components are escaped so a database name cannot alter other DSN parts.
+// An empty sslMode disables TLS, which the local container does not serve
+// unless it is configured with a certificate; require enforces it.
+func buildDSN(host string, port int, dbName, sslMode string) string {
+	if sslMode == "" {
+		sslMode = defaultSSLMode
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(defaultPostgresUser, defaultPostgresPassword),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + dbName,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return u.String()
+}
+
 // PostgresDSN returns a DSN for connecting to the postgres default database.
 func PostgresDSN() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword,
-		defaultPostgresHost, defaultPostgresPort,
-		defaultPostgresDB)
+	return buildDSN(defaultPostgresHost, defaultPostgresPort, defaultPostgresDB, "")
 }
 
 // ProjectDSN returns a DSN for connecting to the project-specific database.
 func ProjectDSN(dbName string) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword,
-		defaultPostgresHost, defaultPostgresPort,
-		dbName)
+	return buildDSN(defaultPostgresHost, defaultPostgresPort, dbName, "")
 }
 
 // PostgresDSNWithPort returns a DSN for connecting to postgres with a custom port.
 func PostgresDSNWithPort(port int) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword,
-		defaultPostgresHost, port,
-		defaultPostgresDB)
+	return buildDSN(defaultPostgresHost, port, defaultPostgresDB, "")
 }
 
 // ProjectDSNWithPort returns a DSN for the project database with a custom port.
 func ProjectDSNWithPort(dbName string, port int) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword,
-		defaultPostgresHost, port,
-		dbName)
+	return buildDSN(defaultPostgresHost, port, dbName, "")
+}
+
+// ContainerDSN returns a DSN for a database of the container of opts, with
+// its sslmode.
+func ContainerDSN(dbName string, opts ContainerOptions) string {
+	return buildDSN(defaultPostgresHost, opts.Port, dbName, opts.SSLMode)
 }
@@ -157,3 +157,19 @@ func RemoveContainer(name string) error {
 		}
 	}
 }
+
+// RemoveVolume removes a Docker volume and the data in it.
+// If the volume doesn't exist, no error is returned.
+func RemoveVolume(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerCommandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "volume", "rm", name).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "no such volume") {
+			return nil
+		}
+		return fmt.Errorf("failed to remove volume: %s: %w", string(output), err)
+	}
+	return nil
+}
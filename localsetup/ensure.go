@@ -64,8 +64,7 @@ func EnsurePostgresRunning(ctx context.Context, projectRoot string, opts Contain
 	}
 
 	// Wait for PostgreSQL to be ready
-	dsn := fmt.Sprintf("postgres://%s:%s@localhost:%d/postgres?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword, opts.Port)
+	dsn := ContainerDSN(defaultPostgresDB, opts)
 
 	if err := WaitForPostgres(dsn, 30*time.Second); err != nil {
 		return "", fmt.Errorf("PostgreSQL not ready after 30s. Check container logs: docker logs %s", opts.Name)
@@ -80,8 +79,7 @@ func EnsurePostgresRunning(ctx context.Context, projectRoot string, opts Contain
 		return "", fmt.Errorf("failed to create database: %w", err)
 	}
 
-	return fmt.Sprintf("postgres://%s:%s@localhost:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword, opts.Port, dbName), nil
+	return ContainerDSN(dbName, opts), nil
 }
 
 // isPortInUse checks if a port is already in use.
@@ -4,6 +4,9 @@ package localsetup
 type ContainerOptions struct {
 	Name string // Container name (default: "agentdx-postgres")
 	Port int    // Host port (default: 55432)
+	// SSLMode is the sslmode of the DSNs built for the container (default:
+	// disable). Use require when the server is configured for TLS.
+	SSLMode string
 }
 
 // DefaultContainerOptions returns the default container configuration.
@@ -28,5 +31,8 @@ func (o ContainerOptions) Merge(other ContainerOptions) ContainerOptions {
 	if other.Port != 0 {
 		result.Port = other.Port
 	}
+	if other.SSLMode != "" {
+		result.SSLMode = other.SSLMode
+	}
 	return result
 }
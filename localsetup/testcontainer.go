@@ -66,8 +66,7 @@ func NewTestContainer(t testing.TB) *TestContainer {
 	t.Cleanup(tc.cleanup)
 
 	// Wait for PostgreSQL to be ready
-	dsn := fmt.Sprintf("postgres://%s:%s@localhost:%d/postgres?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword, port)
+	dsn := PostgresDSNWithPort(port)
 
 	if err := WaitForPostgres(dsn, 30*time.Second); err != nil {
 		tc.cleanup()
@@ -90,8 +89,7 @@ func (tc *TestContainer) CreateDatabase(dbName string) string {
 	if err := CreateDatabase(tc.DSN, dbName); err != nil {
 		tc.t.Fatalf("failed to create test database: %v", err)
 	}
-	return fmt.Sprintf("postgres://%s:%s@localhost:%d/%s?sslmode=disable",
-		defaultPostgresUser, defaultPostgresPassword, tc.Port, dbName)
+	return ProjectDSNWithPort(dbName, tc.Port)
 }
 
 // generateRandomName creates a unique container name for testing.
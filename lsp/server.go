@@ -0,0 +1,395 @@
+// Package lsp provides a minimal Language Server Protocol server for agentdx.
+// It lets editors and LSP-speaking agents query the symbol index and the
+// full-text search index over stdio.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// maxWorkspaceSymbols caps the number of workspace/symbol results.
+const maxWorkspaceSymbols = 200
+
+// LSP SymbolKind values.
+const (
+	symbolKindClass     = 5
+	symbolKindMethod    = 6
+	symbolKindInterface = 11
+	symbolKindFunction  = 12
+	symbolKindVariable  = 13
+	symbolKindConstant  = 14
+	symbolKindStruct    = 23
+)
+
+// SymbolSource is the subset of the symbol store used by the server.
+type SymbolSource interface {
+	LookupSymbol(ctx context.Context, name string) ([]trace.Symbol, error)
+	LookupCallers(ctx context.Context, symbolName string) ([]trace.Reference, error)
+	FindSymbols(ctx context.Context, query string, limit int) ([]trace.Symbol, error)
+}
+
+// Server answers LSP requests from the agentdx indexes.
+type Server struct {
+	projectRoot string
+	symbols     SymbolSource
+	searcher    store.SearchStore
+	searchCfg   config.SearchConfig
+}
+
+// NewServer creates a language server. searcher may be nil, in which case
+// agentdx/search requests fail with an error.
+func NewServer(projectRoot string, symbols SymbolSource, searcher store.SearchStore, searchCfg config.SearchConfig) *Server {
+	return &Server{
+		projectRoot: projectRoot,
+		symbols:     symbols,
+		searcher:    searcher,
+		searchCfg:   searchCfg,
+	}
+}
+
+// Serve processes requests from r and writes responses to w until the
+// client sends exit or closes the stream.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(w, nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(ctx, req)
+		if req.ID == nil {
+			continue // notification
+		}
+		if err := s.reply(w, req.ID, result, rpcErr); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+}
+
+func (s *Server) reply(w io.Writer, id *json.RawMessage, result interface{}, rpcErr *responseError) error {
+	if rpcErr != nil {
+		return writeMessage(w, errorResponse{JSONRPC: "2.0", ID: id, Error: rpcErr})
+	}
+	return writeMessage(w, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// handle dispatches a request to its handler.
+func (s *Server) handle(ctx context.Context, req request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return s.handleInitialize(), nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "workspace/symbol":
+		var params workspaceSymbolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.handleWorkspaceSymbol(ctx, params)
+	case "textDocument/references":
+		var params referenceParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.handleReferences(ctx, params)
+	case "agentdx/search":
+		var params searchParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.handleSearch(ctx, params)
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+func (s *Server) handleInitialize() interface{} {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			// Columns are counted in UTF-16 code units, the LSP default
+			"positionEncoding":        "utf-16",
+			"workspaceSymbolProvider": true,
+			"referencesProvider":      true,
+		},
+		"serverInfo": map[string]string{
+			"name":    "agentdx",
+			"version": "1.0.0",
+		},
+	}
+}
+
+// handleWorkspaceSymbol maps workspace/symbol to the symbol store.
+func (s *Server) handleWorkspaceSymbol(ctx context.Context, params workspaceSymbolParams) (interface{}, *responseError) {
+	symbols, err := s.symbols.FindSymbols(ctx, params.Query, maxWorkspaceSymbols)
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("failed to find symbols: %v", err)}
+	}
+
+	result := make([]SymbolInformation, 0, len(symbols))
+	for _, sym := range symbols {
+		endLine := sym.EndLine
+		if endLine < sym.Line {
+			endLine = sym.Line
+		}
+		result = append(result, SymbolInformation{
+			Name: sym.Name,
+			Kind: symbolKind(sym.Kind),
+			Location: Location{
+				URI:   s.fileURI(sym.File),
+				Range: Range{Start: Position{Line: sym.Line - 1}, End: Position{Line: endLine - 1}},
+			},
+			ContainerName: sym.Receiver,
+		})
+	}
+	return result, nil
+}
+
+// handleReferences maps textDocument/references to LookupCallers for the
+// identifier under the cursor.
+func (s *Server) handleReferences(ctx context.Context, params referenceParams) (interface{}, *responseError) {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("failed to read document: %v", err)}
+	}
+
+	text, _, _ := indexer.DecodeText(content)
+	name := identifierAt(text, params.Position)
+	result := []Location{}
+	if name == "" {
+		return result, nil
+	}
+	files := fileLines{}
+
+	if params.Context.IncludeDeclaration {
+		defs, err := s.symbols.LookupSymbol(ctx, name)
+		if err != nil {
+			return nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("failed to lookup symbol: %v", err)}
+		}
+		for _, def := range defs {
+			result = append(result, s.lineLocation(files, def.File, def.Line, 0, name))
+		}
+	}
+
+	refs, err := s.symbols.LookupCallers(ctx, name)
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("failed to lookup callers: %v", err)}
+	}
+	for _, ref := range refs {
+		result = append(result, s.lineLocation(files, ref.File, ref.Line, ref.Column, name))
+	}
+	return result, nil
+}
+
+// handleSearch maps the custom agentdx/search request to FTS search.
+func (s *Server) handleSearch(ctx context.Context, params searchParams) (interface{}, *responseError) {
+	if s.searcher == nil {
+		return nil, &responseError{Code: codeInternalError, Message: "search index is not available"}
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, &responseError{Code: codeInvalidParams, Message: "query is required"}
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	resp, err := search.Run(ctx, s.searcher, s.searchCfg, search.Request{
+		Query:       params.Query,
+		Filter:      store.SearchFilter{Include: params.Include, Exclude: params.Exclude, Langs: params.Langs},
+		Limit:       limit,
+		MinScore:    params.MinScore,
+		ProjectRoot: s.projectRoot,
+	})
+	if err != nil {
+		return nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("search failed: %v", err)}
+	}
+	// Stdout carries the protocol; the client shows stderr in its log
+	for _, warning := range resp.Warnings {
+		log.Printf("Warning: %v", warning)
+	}
+
+	out := make([]SearchResult, len(resp.Results))
+	for i, r := range resp.Results {
+		out[i] = SearchResult{
+			Location: Location{
+				URI: s.fileURI(r.Chunk.FilePath),
+				Range: Range{
+					Start: Position{Line: r.Chunk.StartLine - 1},
+					End:   Position{Line: r.Chunk.EndLine - 1},
+				},
+			},
+			FilePath:   r.Chunk.FilePath,
+			Score:      r.Score,
+			Confidence: r.Confidence,
+			Content:    r.Chunk.Content,
+			Notes:      r.Notes,
+			Highlights: r.Highlights,
+		}
+	}
+	return out, nil
+}
+
+// filePath resolves an index path (relative to the project root) to a path
+// on disk.
+func (s *Server) filePath(file string) string {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.projectRoot, file)
+	}
+	return file
+}
+
+// fileURI resolves an index path (relative to the project root) to a URI.
+func (s *Server) fileURI(file string) string {
+	return pathToURI(s.filePath(file))
+}
+
+// lineLocation builds a location spanning name at the given 1-based line,
+// starting at the byte offset column. The offset is converted to UTF-16 code
+// units from the line in files; a line that cannot be read keeps it as is.
+func (s *Server) lineLocation(files fileLines, file string, line, column int, name string) Location {
+	if line > 0 {
+		line--
+	}
+	start := column
+	if text, ok := files.line(s.filePath(file), line); ok && column <= len(text) {
+		start = utf16Len(text[:column])
+	}
+	return Location{
+		URI: s.fileURI(file),
+		Range: Range{
+			Start: Position{Line: line, Character: start},
+			End:   Position{Line: line, Character: start + utf16Len(name)},
+		},
+	}
+}
+
+// fileLines caches the lines of the files that locations point into.
+type fileLines map[string][]string
+
+// line returns the 0-based line n of the file at path.
+func (f fileLines) line(path string, n int) (string, bool) {
+	lines, ok := f[path]
+	if !ok {
+		// Unreadable files are cached as empty
+		if content, err := os.ReadFile(path); err == nil {
+			text, _, _ := indexer.DecodeText(content)
+			lines = strings.Split(text, "\n")
+		}
+		f[path] = lines
+	}
+	if n < 0 || n >= len(lines) {
+		return "", false
+	}
+	return lines[n], true
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// runeIndex returns the index in line of the rune at the UTF-16 offset
+// units, or -1 when line is shorter.
+func runeIndex(line []rune, units int) int {
+	if units < 0 {
+		return -1
+	}
+	for i, r := range line {
+		if units <= 0 {
+			return i
+		}
+		units -= utf16.RuneLen(r)
+	}
+	if units > 0 {
+		return -1
+	}
+	return len(line)
+}
+
+// identifierAt returns the identifier that contains the given position,
+// whose character is a UTF-16 offset.
+func identifierAt(content string, pos Position) string {
+	lines := strings.Split(content, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return ""
+	}
+	line := []rune(lines[pos.Line])
+	col := runeIndex(line, pos.Character)
+	if col < 0 {
+		return ""
+	}
+
+	isIdent := func(r rune) bool { return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	start, end := col, col
+	for start > 0 && isIdent(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdent(line[end]) {
+		end++
+	}
+	return string(line[start:end])
+}
+
+// symbolKind maps a trace symbol kind to an LSP SymbolKind.
+func symbolKind(kind trace.SymbolKind) int {
+	switch kind {
+	case trace.KindMethod:
+		return symbolKindMethod
+	case trace.KindClass:
+		return symbolKindClass
+	case trace.KindInterface:
+		return symbolKindInterface
+	case trace.KindType:
+		return symbolKindStruct
+	case trace.KindVariable:
+		return symbolKindVariable
+	case trace.KindConstant:
+		return symbolKindConstant
+	default:
+		return symbolKindFunction
+	}
+}
@@ -0,0 +1,287 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+type fakeSymbols struct {
+	symbols []trace.Symbol
+	refs    map[string][]trace.Reference
+}
+
+func (f *fakeSymbols) LookupSymbol(ctx context.Context, name string) ([]trace.Symbol, error) {
+	var out []trace.Symbol
+	for _, s := range f.symbols {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSymbols) LookupCallers(ctx context.Context, name string) ([]trace.Reference, error) {
+	return f.refs[name], nil
+}
+
+func (f *fakeSymbols) FindSymbols(ctx context.Context, query string, limit int) ([]trace.Symbol, error) {
+	var out []trace.Symbol
+	for _, s := range f.symbols {
+		if strings.Contains(strings.ToLower(s.Name), strings.ToLower(query)) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// fakeSearcher serves results and notes; other store methods are not used
+// by searches of Go files.
+type fakeSearcher struct {
+	store.SearchStore
+	results []store.SearchResult
+	notes   []store.Note
+	filter  store.SearchFilter
+}
+
+func (f *fakeSearcher) SearchFiltered(ctx context.Context, query string, limit int, filter store.SearchFilter) ([]store.SearchResult, error) {
+	f.filter = filter
+	return append([]store.SearchResult(nil), f.results...), nil
+}
+
+func (f *fakeSearcher) ListNotes(ctx context.Context, filePath string) ([]store.Note, error) {
+	var notes []store.Note
+	for _, n := range f.notes {
+		if n.FilePath == filePath {
+			notes = append(notes, n)
+		}
+	}
+	return notes, nil
+}
+
+// exchange sends the given requests to a server and returns its responses.
+func exchange(t *testing.T, s *Server, msgs ...string) []map[string]json.RawMessage {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, m := range msgs {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), &in, &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var responses []map[string]json.RawMessage
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", body, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServer_Initialize(t *testing.T) {
+	s := NewServer("/project", &fakeSymbols{}, nil, config.SearchConfig{})
+	resps := exchange(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+	)
+
+	if len(resps) != 2 {
+		t.Fatalf("expected 2 responses (notifications and post-exit ignored), got %d", len(resps))
+	}
+	if !strings.Contains(string(resps[0]["result"]), `"workspaceSymbolProvider":true`) {
+		t.Errorf("missing workspace symbol capability: %s", resps[0]["result"])
+	}
+	if string(resps[1]["result"]) != "null" {
+		t.Errorf("expected null shutdown result, got %s", resps[1]["result"])
+	}
+}
+
+func TestServer_WorkspaceSymbol(t *testing.T) {
+	symbols := &fakeSymbols{symbols: []trace.Symbol{
+		{Name: "HandleLogin", Kind: trace.KindFunction, File: "auth/login.go", Line: 10, EndLine: 20},
+		{Name: "Server", Kind: trace.KindClass, File: "server.go", Line: 3},
+	}}
+	s := NewServer("/project", symbols, nil, config.SearchConfig{})
+	resps := exchange(t, s, `{"jsonrpc":"2.0","id":1,"method":"workspace/symbol","params":{"query":"login"}}`)
+
+	var result []SymbolInformation
+	if err := json.Unmarshal(resps[0]["result"], &result); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 symbol, got %d", len(result))
+	}
+	got := result[0]
+	if got.Name != "HandleLogin" || got.Kind != symbolKindFunction {
+		t.Errorf("unexpected symbol: %+v", got)
+	}
+	if got.Location.URI != "file:///project/auth/login.go" {
+		t.Errorf("unexpected URI: %s", got.Location.URI)
+	}
+	if got.Location.Range.Start.Line != 9 || got.Location.Range.End.Line != 19 {
+		t.Errorf("unexpected range: %+v", got.Location.Range)
+	}
+}
+
+func TestServer_References(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {\n\tDoWork()\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := &fakeSymbols{
+		symbols: []trace.Symbol{{Name: "DoWork", File: "work.go", Line: 5}},
+		refs: map[string][]trace.Reference{
+			"DoWork": {{SymbolName: "DoWork", File: "main.go", Line: 4, Column: 1}},
+		},
+	}
+	s := NewServer(dir, symbols, nil, config.SearchConfig{})
+
+	params := fmt.Sprintf(`{"textDocument":{"uri":%q},"position":{"line":3,"character":3},"context":{"includeDeclaration":true}}`, pathToURI(file))
+	resps := exchange(t, s, `{"jsonrpc":"2.0","id":1,"method":"textDocument/references","params":`+params+`}`)
+
+	var result []Location
+	if err := json.Unmarshal(resps[0]["result"], &result); err != nil {
+		t.Fatalf("invalid result: %v (%s)", err, resps[0]["error"])
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected declaration and 1 reference, got %d", len(result))
+	}
+	if result[1].Range.Start.Line != 3 || result[1].Range.End.Character != 1+len("DoWork") {
+		t.Errorf("unexpected reference range: %+v", result[1].Range)
+	}
+}
+
+func TestServer_ReferencesUTF16(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	// "名前" is 6 bytes but 2 UTF-16 code units, "😀" 4 bytes but 2 units
+	line := `	s := "名前😀"; DoWork()`
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {\n"+line+"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := &fakeSymbols{
+		refs: map[string][]trace.Reference{
+			"DoWork": {{SymbolName: "DoWork", File: "main.go", Line: 4, Column: strings.Index(line, "DoWork")}},
+		},
+	}
+	s := NewServer(dir, symbols, nil, config.SearchConfig{})
+
+	// The client counts the D of DoWork at UTF-16 offset 14
+	params := fmt.Sprintf(`{"textDocument":{"uri":%q},"position":{"line":3,"character":14}}`, pathToURI(file))
+	resps := exchange(t, s, `{"jsonrpc":"2.0","id":1,"method":"textDocument/references","params":`+params+`}`)
+
+	var result []Location
+	if err := json.Unmarshal(resps[0]["result"], &result); err != nil {
+		t.Fatalf("invalid result: %v (%s)", err, resps[0]["error"])
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 reference, got %d", len(result))
+	}
+	if got := result[0].Range; got.Start.Character != 14 || got.End.Character != 20 {
+		t.Errorf("expected UTF-16 range 14-20, got %+v", got)
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	searcher := &fakeSearcher{
+		results: []store.SearchResult{
+			{Chunk: store.Chunk{FilePath: "a.go", StartLine: 1, EndLine: 5, Content: "x := 1"}, Score: 2},
+			{Chunk: store.Chunk{FilePath: "b.go", StartLine: 3, EndLine: 9, Content: "b"}, Score: 1},
+		},
+		notes: []store.Note{{FilePath: "a.go", StartLine: 2, EndLine: 2, Text: "entrypoint"}},
+	}
+	s := NewServer("/project", &fakeSymbols{}, searcher, config.SearchConfig{})
+	resps := exchange(t, s, `{"jsonrpc":"2.0","id":1,"method":"agentdx/search","params":{"query":"x","limit":1,"include":["*.go"]}}`)
+
+	var result []SearchResult
+	if err := json.Unmarshal(resps[0]["result"], &result); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if len(result) != 1 || result[0].FilePath != "a.go" {
+		t.Fatalf("unexpected results: %+v", result)
+	}
+	if len(result[0].Notes) != 1 || len(result[0].Highlights) != 1 || result[0].Confidence == "" {
+		t.Errorf("expected the note, highlight and confidence of the result, got %+v", result[0])
+	}
+	if len(searcher.filter.Include) != 1 {
+		t.Errorf("expected the include filter to reach the store, got %+v", searcher.filter)
+	}
+
+	// Weak matches are dropped
+	resps = exchange(t, s, `{"jsonrpc":"2.0","id":1,"method":"agentdx/search","params":{"query":"x","min_score":5}}`)
+	if err := json.Unmarshal(resps[0]["result"], &result); err != nil || len(result) != 0 {
+		t.Errorf("expected no results above the minimum score, got %+v (%v)", result, err)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := NewServer("/project", &fakeSymbols{}, nil, config.SearchConfig{})
+	resps := exchange(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"agentdx/search","params":{"query":"x"}}`,
+	)
+
+	for i, want := range []int{codeMethodNotFound, codeInternalError} {
+		var rpcErr responseError
+		if err := json.Unmarshal(resps[i]["error"], &rpcErr); err != nil {
+			t.Fatalf("response %d: expected error, got %v", i, resps[i])
+		}
+		if rpcErr.Code != want {
+			t.Errorf("response %d: expected code %d, got %d", i, want, rpcErr.Code)
+		}
+		if _, ok := resps[i]["result"]; ok {
+			t.Errorf("response %d: error response must not contain result", i)
+		}
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	content := "x := foo.BarBaz(y)\n"
+	tests := []struct {
+		char int
+		want string
+	}{
+		{0, "x"},
+		{9, "BarBaz"},
+		{15, "BarBaz"},
+		{2, ""},
+		{19, ""},
+	}
+	for _, tt := range tests {
+		if got := identifierAt(content, Position{Character: tt.char}); got != tt.want {
+			t.Errorf("identifierAt(%d) = %q, want %q", tt.char, got, tt.want)
+		}
+	}
+
+	// Characters are UTF-16 offsets: "😀" takes 2
+	if got := identifierAt("😀😀 ab 名前\n", Position{Character: 6}); got != "ab" {
+		t.Errorf("identifierAt after surrogate pairs = %q, want %q", got, "ab")
+	}
+}
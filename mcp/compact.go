@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Arguments every tool accepts to shrink its response.
+const (
+	argCompact   = "compact"
+	argMaxTokens = "max_tokens"
+)
+
+const (
+	// bytesPerToken converts max_tokens to a response size. Code and JSON
+	// average 3 to 4 bytes per token; counting 3 errs toward staying within
+	// the budget.
+	bytesPerToken = 3
+
+	// minCutBytes is the length max_tokens cuts long strings such as result
+	// content down to before it drops results.
+	minCutBytes = 200
+
+	// cutMarker ends a string cut to fit max_tokens.
+	cutMarker = "\n… (truncated)"
+)
+
+// readOnlyTool annotates a tool that only reads the local index, so clients
+// may call it in parallel and retry it.
+func readOnlyTool(title string) mcp.ToolOption {
+	return mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		Title:           title,
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	})
+}
+
+// addTool registers a tool whose responses honor the compact and
+// max_tokens arguments, and whose calls are bounded by the limits of the
+// mcp configuration.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	handler = s.limit(tool.Name, handler)
+	s.mcpServer.AddTool(withShapeArgs(tool), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil {
+			return result, err
+		}
+		return shapeRequestResult(result, request), nil
+	})
+}
+
+// withShapeArgs adds the compact and max_tokens arguments to a tool.
+func withShapeArgs(tool mcp.Tool) mcp.Tool {
+	mcp.WithBoolean(argCompact,
+		mcp.Description("Return minified JSON without fields that repeat others, such as abs_path (default: false)"),
+	)(&tool)
+	mcp.WithNumber(argMaxTokens,
+		mcp.Description("Approximate token budget of the response. Long content is cut first, then trailing results are dropped, with a note saying what was left out (default: 0 = unlimited)"),
+	)(&tool)
+	return tool
+}
+
+// shapeRequestResult shapes result as the compact and max_tokens arguments
+// of request ask. Errors are returned as is.
+func shapeRequestResult(result *mcp.CallToolResult, request mcp.CallToolRequest) *mcp.CallToolResult {
+	if result == nil || result.IsError {
+		return result
+	}
+	return shapeResult(result, request.GetBool(argCompact, false), request.GetInt(argMaxTokens, 0))
+}
+
+// shapeResult returns result with its JSON payload, the first text block,
+// minified when compact is set and shrunk to about maxTokens tokens when
+// it is positive. Later blocks, notes and warnings, are kept. result may
+// be shared with other callers, so it is not modified.
+func shapeResult(result *mcp.CallToolResult, compact bool, maxTokens int) *mcp.CallToolResult {
+	if (!compact && maxTokens <= 0) || len(result.Content) == 0 {
+		return result
+	}
+	first, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+	dec := json.NewDecoder(strings.NewReader(first.Text))
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		return result // Not JSON
+	}
+
+	encode := func(v any) []byte {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if !compact {
+			enc.SetIndent("", "  ")
+		}
+		_ = enc.Encode(v)
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+
+	if compact {
+		payload = dropRedundant(payload)
+	}
+	var note string
+	if maxTokens > 0 {
+		// The other blocks count toward the budget too
+		budget := maxTokens * bytesPerToken
+		for _, c := range result.Content[1:] {
+			if tc, ok := c.(mcp.TextContent); ok {
+				budget -= len(tc.Text)
+			}
+		}
+		var cut, dropped int
+		payload, cut, dropped = fitJSON(payload, budget, encode)
+		if cut > 0 || dropped > 0 {
+			note = fmt.Sprintf("Note: response shortened to fit max_tokens=%d: %d values cut, %d items dropped; narrow the request or raise max_tokens to see more", maxTokens, cut, dropped)
+		}
+	}
+
+	shaped := *result
+	shaped.Content = append([]mcp.Content{mcp.NewTextContent(string(encode(payload)))}, result.Content[1:]...)
+	if note != "" {
+		shaped.Content = append(shaped.Content, mcp.NewTextContent(note))
+	}
+	return &shaped
+}
+
+// dropRedundant removes abs_path, which the project root and path give,
+// and file_path where it repeats path.
+func dropRedundant(v any) any {
+	root := []any{v}
+	walkJSON(root, func(node any, _ func(any)) {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return
+		}
+		delete(obj, "abs_path")
+		if obj["file_path"] != nil && obj["file_path"] == obj["path"] {
+			delete(obj, "file_path")
+		}
+	})
+	return root[0]
+}
+
+// fitJSON shrinks v until encode returns at most budget bytes: it cuts the
+// longest strings down to minCutBytes, then drops the last item of the
+// largest array, keeping at least one item per array. It returns the
+// shrunk value and how many strings it cut and items it dropped.
+func fitJSON(v any, budget int, encode func(any) []byte) (any, int, int) {
+	root := []any{v}
+	cut, dropped := 0, 0
+	for {
+		excess := len(encode(root[0])) - budget
+		if excess <= 0 {
+			break
+		}
+
+		// Cut the longest string that is still above the minimum
+		var longest string
+		var setLongest func(any)
+		walkJSON(root, func(node any, set func(any)) {
+			if s, ok := node.(string); ok && len(s) > minCutBytes+len(cutMarker) && len(s) > len(longest) {
+				longest, setLongest = s, set
+			}
+		})
+		if setLongest != nil {
+			if !strings.HasSuffix(longest, cutMarker) {
+				cut++
+			}
+			text := strings.TrimSuffix(longest, cutMarker)
+			setLongest(cutString(text, max(minCutBytes, len(text)-excess-len(cutMarker))) + cutMarker)
+			continue
+		}
+
+		// Drop the last item of the largest array
+		var largest []any
+		var largestSize int
+		var setLargest func(any)
+		walkJSON(root, func(node any, set func(any)) {
+			if a, ok := node.([]any); ok && len(a) > 1 {
+				if size := len(encode(a)); size > largestSize {
+					largest, largestSize, setLargest = a, size, set
+				}
+			}
+		})
+		if setLargest == nil {
+			break // Nothing left to shrink
+		}
+		setLargest(largest[:len(largest)-1])
+		dropped++
+	}
+	return root[0], cut, dropped
+}
+
+// cutString returns the first n bytes of s at most, ending at a line break
+// when one is in the second half and never inside a UTF-8 sequence.
+func cutString(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '\n'); i > n/2 {
+		s = s[:i]
+	}
+	return s
+}
+
+// walkJSON calls visit for every value below the decoded JSON container
+// root, with a function replacing the value in its parent. Object keys are
+// visited in sorted order so that the walk is deterministic.
+func walkJSON(root any, visit func(node any, set func(any))) {
+	switch c := root.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			visit(c[k], func(v any) { c[k] = v })
+			walkJSON(c[k], visit)
+		}
+	case []any:
+		for i := range c {
+			visit(c[i], func(v any) { c[i] = v })
+			walkJSON(c[i], visit)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestShapeResult_Compact(t *testing.T) {
+	payload := `[
+  {
+    "file_path": "a.go",
+    "path": "a.go",
+    "abs_path": "/src/a.go",
+    "start_line": 1
+  }
+]`
+	result := mcp.NewToolResultText(payload)
+	shaped := shapeResult(result, true, 0)
+
+	if got := toolResultTexts(shaped)[0]; got != `[{"path":"a.go","start_line":1}]` {
+		t.Errorf("unexpected compact payload %s", got)
+	}
+	if toolResultTexts(result)[0] != payload {
+		t.Error("shapeResult modified the original result")
+	}
+	if shapeResult(result, false, 0) != result {
+		t.Error("expected the result as is without compact or max_tokens")
+	}
+}
+
+func TestShapeResult_MaxTokens(t *testing.T) {
+	type item struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	var items []item
+	for _, path := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		items = append(items, item{Path: path, Content: strings.Repeat("line of code\n", 100)})
+	}
+	data, _ := json.Marshal(items)
+	result := mcp.NewToolResultText(string(data))
+	result.Content = append(result.Content, mcp.NewTextContent("Warning: stale"))
+
+	// Cutting content is enough
+	shaped := shapeResult(result, true, 400)
+	texts := toolResultTexts(shaped)
+	var got []item
+	if err := json.Unmarshal([]byte(texts[0]), &got); err != nil {
+		t.Fatalf("shaped payload is not JSON: %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(got))
+	}
+	for _, it := range got {
+		text, ok := strings.CutSuffix(it.Content, cutMarker)
+		if !ok || len(it.Content) >= len(items[0].Content) {
+			t.Errorf("expected %s content to be cut, got %d bytes", it.Path, len(it.Content))
+		}
+		if !strings.HasSuffix(text, "line of code") {
+			t.Errorf("expected %s content cut at a line break, got %q", it.Path, text)
+		}
+	}
+	if len(texts) != 3 || texts[1] != "Warning: stale" || !strings.Contains(texts[2], "4 values cut, 0 items dropped") {
+		t.Errorf("unexpected blocks %q", texts[1:])
+	}
+
+	// Then trailing items are dropped, keeping one
+	texts = toolResultTexts(shapeResult(result, true, 50))
+	got = nil
+	if err := json.Unmarshal([]byte(texts[0]), &got); err != nil {
+		t.Fatalf("shaped payload is not JSON: %v", err)
+	}
+	if len(got) != 1 || got[0].Path != "a.go" {
+		t.Errorf("expected only a.go to be kept, got %+v", got)
+	}
+	if !strings.Contains(texts[len(texts)-1], "3 items dropped") {
+		t.Errorf("unexpected note %q", texts[len(texts)-1])
+	}
+
+	// Responses within the budget are kept
+	if texts := toolResultTexts(shapeResult(result, true, 100000)); strings.Contains(texts[0], "truncated") || len(texts) != 2 {
+		t.Errorf("unexpected blocks %q", texts)
+	}
+}
+
+func TestCutString(t *testing.T) {
+	if got := cutString("héllo", 2); got != "h" {
+		t.Errorf("cutString split a UTF-8 sequence: %q", got)
+	}
+	if got := cutString("first line\nsecond line", 15); got != "first line" {
+		t.Errorf("expected a cut at the line break, got %q", got)
+	}
+}
+
+func TestTools_Annotations(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range s.Tools() {
+		readOnly := tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+		if readOnly != (tool.Name != "agentdx_note_add") {
+			t.Errorf("%s: unexpected readOnlyHint %v", tool.Name, readOnly)
+		}
+		if tool.Annotations.OpenWorldHint == nil || *tool.Annotations.OpenWorldHint {
+			t.Errorf("%s: expected openWorldHint false", tool.Name)
+		}
+		if tool.Annotations.DestructiveHint == nil || *tool.Annotations.DestructiveHint {
+			t.Errorf("%s: expected destructiveHint false", tool.Name)
+		}
+		for _, arg := range []string{argCompact, argMaxTokens} {
+			if _, ok := tool.InputSchema.Properties[arg]; !ok {
+				t.Errorf("%s: missing %s argument", tool.Name, arg)
+			}
+		}
+	}
+}
+
+func TestHandleSearch_MaxTokens(t *testing.T) {
+	s := newSearchServer(t, map[string]string{
+		"a.go": "// user\n" + strings.Repeat("func A() {}\n", 200),
+		"b.go": "// user\n" + strings.Repeat("func B() {}\n", 200),
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "agentdx_search"
+	request.Params.Arguments = map[string]any{"query": "user", "compact": true, "max_tokens": 300}
+	result, err := s.handleSearch(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearch failed: %v %v", err, toolResultTexts(result))
+	}
+	texts := toolResultTexts(result)
+	var results []SearchResult
+	if err := json.Unmarshal([]byte(texts[0]), &results); err != nil {
+		t.Fatalf("result is not a list of results: %v", err)
+	}
+	if len(results) == 0 || results[0].AbsPath != "" || !strings.HasSuffix(results[0].Content, cutMarker) {
+		t.Errorf("expected compact results with cut content, got %+v", results)
+	}
+	if !strings.Contains(texts[len(texts)-1], "max_tokens=300") {
+		t.Errorf("expected a note about max_tokens, got %q", texts[len(texts)-1])
+	}
+}
@@ -0,0 +1,18 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolError returns a tool error result whose text is the JSON payload
+// {"error": ..., "code": ...} of err, so agents can branch on the code.
+func toolError(err error) *mcp.CallToolResult {
+	data, merr := json.Marshal(errcode.PayloadOf(err))
+	if merr != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	return mcp.NewToolResultError(string(data))
+}
@@ -0,0 +1,297 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/internal/netutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultHTTPAddr is the address served by 'agentdx serve --http'.
+const DefaultHTTPAddr = "127.0.0.1:7782"
+
+// HTTPTokenEnv is the environment variable holding the HTTP API token.
+const HTTPTokenEnv = "AGENTDX_HTTP_TOKEN"
+
+// restPrefix is the path prefix of the REST API. Each tool is served at
+// restPrefix + its name without the "agentdx_" prefix, e.g. /api/v1/search.
+const restPrefix = "/api/v1/"
+
+// maxBodyBytes bounds the JSON body of REST and retrieval requests.
+const maxBodyBytes = 1 << 20
+
+// postOnlyTools modify the index and are not served for GET requests.
+var postOnlyTools = map[string]bool{
+	"agentdx_note_add": true,
+}
+
+// HTTPHandler serves the MCP tools over HTTP: the MCP streamable HTTP
+// transport at /mcp, a JSON REST API under /api/v1/ and a retrieval endpoint
+// for RAG frameworks at /v1/retrieval. Clients must send token as a bearer
+// token, and POST requests must be JSON; with an empty token, every request
+// is rejected.
+func (s *Server) HTTPHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s.subscriptionHandler(server.NewStreamableHTTPServer(s.mcpServer)))
+	mux.HandleFunc(restPrefix, s.handleREST)
+	mux.HandleFunc(retrievalPath, s.handleRetrieval)
+	return requireToken(token, requireJSON(mux))
+}
+
+// NewHTTPToken returns a random token for the HTTP API, for servers started
+// without one.
+func NewHTTPToken() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// ListenAndServe serves the HTTP API on addr until ctx is done. A token is
+// required (see NewHTTPToken). On a loopback address, only requests for a
+// loopback host name are served, so that a web page cannot reach the API
+// through DNS rebinding.
+func (s *Server) ListenAndServe(ctx context.Context, addr, token string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if token == "" {
+		return fmt.Errorf("a token is required to serve HTTP on %s (use --token or %s)", addr, HTTPTokenEnv)
+	}
+	handler := s.HTTPHandler(token)
+	if netutil.IsLoopback(host) {
+		handler = requireLoopbackHost(handler)
+	}
+
+	defer s.Close()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	go s.watchResources(ctx)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve HTTP on %s: %w", addr, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop HTTP server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("HTTP server error: %v", err)
+	}
+	return nil
+}
+
+// handleREST calls a tool with arguments taken from the query string or,
+// for POST requests, a JSON object body, and writes the tool's JSON result.
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, restPrefix)
+	if name == "tools" {
+		s.writeToolList(w)
+		return
+	}
+
+	tool := s.mcpServer.GetTool("agentdx_" + name)
+	if tool == nil {
+		writeHTTPError(w, http.StatusNotFound, errcode.NotFound, fmt.Sprintf("unknown endpoint: %s", r.URL.Path))
+		return
+	}
+
+	args := make(map[string]any)
+	switch r.Method {
+	case http.MethodGet:
+		if postOnlyTools[tool.Tool.Name] {
+			w.Header().Set("Allow", http.MethodPost)
+			writeHTTPError(w, http.StatusMethodNotAllowed, errcode.InvalidArgs, "use POST for this endpoint")
+			return
+		}
+	case http.MethodPost:
+		if r.ContentLength != 0 && !decodeJSONBody(w, r, &args) {
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeHTTPError(w, http.StatusMethodNotAllowed, errcode.InvalidArgs, "method not allowed")
+		return
+	}
+	for key, values := range r.URL.Query() {
+		if _, ok := args[key]; !ok && len(values) > 0 {
+			args[key] = values[0]
+		}
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = tool.Tool.Name
+	req.Params.Arguments = args
+	result, err := tool.Handler(r.Context(), req)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, errcode.Of(err), err.Error())
+		return
+	}
+
+	texts := toolResultTexts(result)
+	if result.IsError {
+		writeToolError(w, texts)
+		return
+	}
+	if len(texts) == 0 {
+		texts = []string{"null"}
+	}
+	// The first block is the JSON result; later ones are warnings
+	for _, warning := range texts[1:] {
+		w.Header().Add("X-Agentdx-Warning", warning)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintln(w, texts[0])
+}
+
+// writeToolList writes the REST endpoints and the tools behind them.
+func (s *Server) writeToolList(w http.ResponseWriter) {
+	type endpoint struct {
+		Path        string `json:"path"`
+		Tool        string `json:"tool"`
+		Description string `json:"description"`
+	}
+	var endpoints []endpoint
+	for _, tool := range s.Tools() {
+		endpoints = append(endpoints, endpoint{
+			Path:        restPrefix + strings.TrimPrefix(tool.Name, "agentdx_"),
+			Tool:        tool.Name,
+			Description: tool.Description,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(endpoints)
+}
+
+// toolResultTexts returns the text content blocks of a tool result.
+func toolResultTexts(result *mcp.CallToolResult) []string {
+	var texts []string
+	for _, c := range result.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	return texts
+}
+
+// decodeJSONBody decodes the JSON body of r, of at most maxBodyBytes, into
+// v. On failure it writes the error, 413 for a body over the limit, and
+// returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeHTTPError(w, http.StatusRequestEntityTooLarge, errcode.InvalidArgs, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	}
+	writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, fmt.Sprintf("invalid JSON body: %v", err))
+	return false
+}
+
+// writeHTTPError writes a JSON error response with the error code.
+func writeHTTPError(w http.ResponseWriter, status int, code errcode.Code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errcode.Payload{Error: msg, Code: code})
+}
+
+// writeToolError writes the error payload of a failed tool call, with the
+// HTTP status of its code.
+func writeToolError(w http.ResponseWriter, texts []string) {
+	var p errcode.Payload
+	if len(texts) == 0 || json.Unmarshal([]byte(texts[0]), &p) != nil || p.Code == "" {
+		p = errcode.Payload{Error: strings.Join(texts, "\n"), Code: errcode.Internal}
+	}
+	status := http.StatusBadRequest
+	switch p.Code {
+	case errcode.Internal:
+		status = http.StatusInternalServerError
+	case errcode.BackendDown:
+		status = http.StatusServiceUnavailable
+	case errcode.NotFound:
+		status = http.StatusNotFound
+	case errcode.BudgetExceeded:
+		status = http.StatusTooManyRequests
+	case errcode.Timeout:
+		status = http.StatusGatewayTimeout
+	}
+	writeHTTPError(w, status, p.Code, p.Error)
+}
+
+// requireToken rejects requests without the bearer token. An empty token
+// rejects every request.
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeHTTPError(w, http.StatusUnauthorized, errcode.Unauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireJSON rejects POST requests whose Content-Type is not
+// application/json. Browsers send other types cross-site without a CORS
+// preflight, so a web page could otherwise post to the API.
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeHTTPError(w, http.StatusUnsupportedMediaType, errcode.InvalidArgs, "POST requests must have Content-Type application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireLoopbackHost rejects requests whose Host header is not a loopback
+// name or address: a page of another site resolving its name to 127.0.0.1
+// (DNS rebinding) still sends its own host name.
+func requireLoopbackHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if !netutil.IsLoopback(strings.Trim(host, "[]")) {
+			writeHTTPError(w, http.StatusForbidden, errcode.Unauthorized, fmt.Sprintf("host %q is not served; use localhost or 127.0.0.1", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHTTPServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.HTTPHandler(token))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func doRequest(t *testing.T, method, url, token, body string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestHTTPHandler_REST(t *testing.T) {
+	ts := newTestHTTPServer(t, "secret")
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/tools", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var endpoints []struct {
+		Path string `json:"path"`
+		Tool string `json:"tool"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	found := false
+	for _, e := range endpoints {
+		if e.Path == "/api/v1/search" && e.Tool == "agentdx_search" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected /api/v1/search in tool list, got %+v", endpoints)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"unknown endpoint", http.MethodGet, "/api/v1/nope", "", http.StatusNotFound},
+		{"tool error", http.MethodGet, "/api/v1/search", "", http.StatusBadRequest},
+		{"write over GET", http.MethodGet, "/api/v1/note_add?file=a.go", "", http.StatusMethodNotAllowed},
+		{"invalid body", http.MethodPost, "/api/v1/search", "{", http.StatusBadRequest},
+		{"body too large", http.MethodPost, "/api/v1/search", `{"query": "` + strings.Repeat("a", maxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge},
+		{"unsupported method", http.MethodDelete, "/api/v1/search", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := doRequest(t, tt.method, ts.URL+tt.path, "secret", tt.body)
+			if code != tt.want {
+				t.Errorf("expected status %d, got %d (%v)", tt.want, code, body)
+			}
+			if body["error"] == nil {
+				t.Errorf("expected an error message, got %v", body)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_Token(t *testing.T) {
+	ts := newTestHTTPServer(t, "secret")
+
+	if code, _ := doRequest(t, http.MethodGet, ts.URL+"/api/v1/tools", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code, _ := doRequest(t, http.MethodGet, ts.URL+"/api/v1/tools", "wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with a wrong token, got %d", code)
+	}
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	code, body := doRequest(t, http.MethodPost, ts.URL+"/mcp", "secret", initialize)
+	if code != http.StatusOK || body["result"] == nil {
+		t.Errorf("expected MCP initialize to succeed, got %d %v", code, body)
+	}
+
+	// Without a token, nothing is served
+	noToken := newTestHTTPServer(t, "")
+	if code, _ := doRequest(t, http.MethodGet, noToken.URL+"/api/v1/tools", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 from a handler without token, got %d", code)
+	}
+}
+
+func TestHTTPHandler_RequiresJSON(t *testing.T) {
+	ts := newTestHTTPServer(t, "secret")
+
+	// A cross-site form or fetch can post text/plain without a preflight
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/note_add?file=a.go&line=1&text=x", strings.NewReader(`{"file": "a.go"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for a text/plain POST, got %d", resp.StatusCode)
+	}
+}
+
+func TestRequireLoopbackHost(t *testing.T) {
+	handler := requireLoopbackHost(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for host, want := range map[string]int{
+		"localhost:7782":      http.StatusOK,
+		"127.0.0.1:7782":      http.StatusOK,
+		"[::1]:7782":          http.StatusOK,
+		"localhost":           http.StatusOK,
+		"attacker.example":    http.StatusForbidden,
+		"attacker.example:80": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tools", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("host %q: expected status %d, got %d", host, want, rec.Code)
+		}
+	}
+}
+
+func TestListenAndServe_RequiresToken(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"0.0.0.0:0", "127.0.0.1:0"} {
+		err = s.ListenAndServe(t.Context(), addr, "")
+		if err == nil || !strings.Contains(err.Error(), "token is required") {
+			t.Errorf("%s: expected a token error, got %v", addr, err)
+		}
+	}
+}
+
+func TestHTTPHandler_Retrieval(t *testing.T) {
+	s := newSearchServer(t, map[string]string{
+		"auth.go":   "// Login authenticates a user\nfunc Login(u User) error",
+		"user.go":   "type User struct {\n\tName string\n}",
+		"README.md": "# Login\n\nHow to login",
+	})
+	ts := httptest.NewServer(s.HTTPHandler("secret"))
+	t.Cleanup(ts.Close)
+
+	if code, _ := doRequest(t, http.MethodPost, ts.URL+"/v1/retrieval", "", `{"query": "login"}`); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	// A server without a token does not serve retrieval either
+	noToken := httptest.NewServer(s.HTTPHandler(""))
+	t.Cleanup(noToken.Close)
+	for _, token := range []string{"", "secret"} {
+		if code, _ := doRequest(t, http.MethodPost, noToken.URL+"/v1/retrieval", token, `{"query": "login"}`); code != http.StatusUnauthorized {
+			t.Errorf("expected 401 from a handler without token, got %d", code)
+		}
+	}
+
+	code, body := doRequest(t, http.MethodPost, ts.URL+"/v1/retrieval", "secret", `{"query": "login", "top_k": 5, "filter": {"type": ["code"]}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", code, body)
+	}
+	var single RetrievalResult
+	remarshal(t, body, &single)
+	if single.Query != "login" || len(single.Results) != 1 {
+		t.Fatalf("expected the code passage only, got %+v", single)
+	}
+	p := single.Results[0]
+	if p.ID != "auth.go:1-2" || p.Metadata.Source != "auth.go" || !strings.Contains(p.Text, "func Login") || p.Score <= 0 {
+		t.Errorf("unexpected passage %+v", p)
+	}
+
+	code, body = doRequest(t, http.MethodPost, ts.URL+"/v1/retrieval", "secret", `{"queries": [{"query": "user", "top_k": 1}, {"query": "login"}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", code, body)
+	}
+	var batch struct {
+		Results []RetrievalResult `json:"results"`
+	}
+	remarshal(t, body, &batch)
+	if len(batch.Results) != 2 || batch.Results[0].Query != "user" || len(batch.Results[0].Results) != 1 || len(batch.Results[1].Results) != 2 {
+		t.Errorf("unexpected batch %+v", batch)
+	}
+
+	code, body = doRequest(t, http.MethodGet, ts.URL+"/v1/retrieval?query=user&include=user.go", "secret", "")
+	remarshal(t, body, &single)
+	if code != http.StatusOK || len(single.Results) != 1 || single.Results[0].Metadata.Source != "user.go" {
+		t.Errorf("expected user.go over GET, got %d %+v", code, single)
+	}
+
+	for _, body := range []string{`{}`, `{"query": "user", "top_k": -1}`, `{`} {
+		if code, resp := doRequest(t, http.MethodPost, ts.URL+"/v1/retrieval", "secret", body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %v", body, code, resp)
+		}
+	}
+	large := `{"query": "` + strings.Repeat("a", maxBodyBytes) + `"}`
+	if code, resp := doRequest(t, http.MethodPost, ts.URL+"/v1/retrieval", "secret", large); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a body over the limit, got %d %v", code, resp)
+	}
+}
+
+// remarshal decodes a JSON object decoded as a map into v.
+func remarshal(t *testing.T, m map[string]any, v any) {
+	t.Helper()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// callLimiter caps the tool calls running at once, so that an agent firing
+// many calls in parallel queues them instead of opening a connection each.
+type callLimiter struct {
+	mu    sync.Mutex
+	slots chan struct{} // a token per running call; nil for no limit
+	size  int
+}
+
+// acquire waits for a slot among n, or 0 for no limit, until ctx is done.
+// It returns the function releasing the slot.
+func (l *callLimiter) acquire(ctx context.Context, n int) (func(), error) {
+	l.mu.Lock()
+	if n != l.size {
+		// Calls holding a slot of the previous size release it there
+		l.size = n
+		l.slots = nil
+		if n > 0 {
+			l.slots = make(chan struct{}, n)
+		}
+	}
+	slots := l.slots
+	l.mu.Unlock()
+
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limit wraps the handler of tool name with the limits of the mcp section
+// of the configuration: the call waits for one of max_concurrent slots and
+// fails with errcode.Timeout once it ran, waiting included, past its
+// timeout. A call canceled by the client returns its context's error.
+func (s *Server) limit(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limits, err := s.stores.mcpConfig(s.projectRoot)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+		}
+		timeout := limits.Timeout(name)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		release, err := s.calls.acquire(ctx, limits.Concurrency())
+		if err == nil {
+			defer release()
+			var result *mcp.CallToolResult
+			result, err = handler(ctx, request)
+			// A result completed as the deadline passed is still good
+			if ctx.Err() == nil || (err == nil && result != nil && !result.IsError) {
+				return result, err
+			}
+			err = ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return toolError(errcode.New(errcode.Timeout, "%s did not finish within %s; narrow the call or raise mcp.timeout_ms", name, timeout)), nil
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// setMCPConfig saves the mcp section of the configuration of s.
+func setMCPConfig(t *testing.T, s *Server, mcpCfg config.MCPConfig) {
+	t.Helper()
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MCP = mcpCfg
+	if err := cfg.Save(s.projectRoot); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimit_Concurrency(t *testing.T) {
+	s := newSearchServer(t, nil)
+	setMCPConfig(t, s, config.MCPConfig{MaxConcurrent: 2})
+
+	var running, peak atomic.Int32
+	handler := s.limit("agentdx_test", func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := handler(context.Background(), mcp.CallToolRequest{}); err != nil || result.IsError {
+				t.Errorf("call failed: %v %v", err, toolResultTexts(result))
+			}
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 calls at once, got %d", got)
+	}
+}
+
+func TestLimit_Timeout(t *testing.T) {
+	s := newSearchServer(t, nil)
+	setMCPConfig(t, s, config.MCPConfig{ToolTimeoutsMs: map[string]int{"agentdx_slow": 20}})
+
+	slow := func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return toolError(ctx.Err()), nil
+	}
+	result, err := s.limit("agentdx_slow", slow)(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !result.IsError || !strings.Contains(toolResultTexts(result)[0], "E_TIMEOUT") {
+		t.Errorf("expected a timeout error, got %v %v", err, toolResultTexts(result))
+	}
+
+	// A call canceled by the client returns the cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.limit("agentdx_other", slow)(ctx, mcp.CallToolRequest{}); err != context.Canceled {
+		t.Errorf("expected the cancellation, got %v", err)
+	}
+}
+
+func TestLimit_TimeoutCancelsSearch(t *testing.T) {
+	s := newSearchServer(t, map[string]string{"auth.go": "func Login() error", "login.go": "func LoginPage() string"})
+
+	// A reranker that never answers keeps the search running until it is
+	// canceled
+	reached, left := make(chan struct{}, 8), make(chan struct{}, 8)
+	reranker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached <- struct{}{}
+		// The server notices the client left once the body is read
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		left <- struct{}{}
+	}))
+	defer reranker.Close()
+
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MCP = config.MCPConfig{ToolTimeoutsMs: map[string]int{"agentdx_search": 200}, MaxConcurrent: 1}
+	cfg.Index.Search.Rerank = config.RerankConfig{Enabled: true, Endpoint: reranker.URL, TimeoutMs: 60000}
+	if err := cfg.Save(s.projectRoot); err != nil {
+		t.Fatal(err)
+	}
+	handler := s.limit("agentdx_search", s.handleSearch)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"query": "Login"}
+	waitLeft := func() {
+		t.Helper()
+		select {
+		case <-reached:
+		default:
+			return // Timed out before reranking
+		}
+		select {
+		case <-left:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the search to stop reranking")
+		}
+	}
+
+	start := time.Now()
+	result, err := handler(context.Background(), request)
+	if err != nil || !result.IsError || !strings.Contains(toolResultTexts(result)[0], "E_TIMEOUT") {
+		t.Fatalf("expected a timeout error, got %v %v", err, toolResultTexts(result))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the search to stop at its timeout, took %s", elapsed)
+	}
+	waitLeft()
+
+	// A client canceling the call cancels the search, which releases its
+	// slot for the next call
+	cfg.MCP = config.MCPConfig{MaxConcurrent: 1}
+	if err := cfg.Save(s.projectRoot); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-reached
+			reached <- struct{}{} // For waitLeft
+			cancel()
+		}()
+		if _, err := handler(ctx, request); err != context.Canceled {
+			t.Fatalf("expected the cancellation, got %v", err)
+		}
+		waitLeft()
+	}
+}
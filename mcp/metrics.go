@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// followUpWindow is how long after a search reading one of its result
+	// files counts as following it up.
+	followUpWindow = 10 * time.Minute
+	// maxPendingQueries bounds the searches remembered per session.
+	maxPendingQueries = 20
+)
+
+// followUps remembers each session's recent searches and the files they
+// returned, so that later reads of those files can be credited to them.
+type followUps struct {
+	mu       sync.Mutex
+	sessions map[string][]pendingQuery
+	now      func() time.Time
+}
+
+type pendingQuery struct {
+	id    int64
+	files map[string]bool
+	at    time.Time
+}
+
+func newFollowUps() *followUps {
+	return &followUps{sessions: make(map[string][]pendingQuery), now: time.Now}
+}
+
+// add remembers the search id of session that returned files.
+func (f *followUps) add(session string, id int64, files []string) {
+	if id == 0 || len(files) == 0 {
+		return
+	}
+	q := pendingQuery{id: id, files: make(map[string]bool, len(files)), at: f.now()}
+	for _, file := range files {
+		q.files[file] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending := append(f.sessions[session], q)
+	if len(pending) > maxPendingQueries {
+		pending = pending[len(pending)-maxPendingQueries:]
+	}
+	f.sessions[session] = pending
+}
+
+// read returns the recent searches of session that returned file. Each
+// search is returned once.
+func (f *followUps) read(session, file string) []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cutoff := f.now().Add(-followUpWindow)
+	var ids []int64
+	var kept []pendingQuery
+	for _, q := range f.sessions[session] {
+		switch {
+		case q.at.Before(cutoff):
+		case q.files[file]:
+			ids = append(ids, q.id)
+		default:
+			kept = append(kept, q)
+		}
+	}
+	if len(kept) == 0 {
+		delete(f.sessions, session)
+	} else {
+		f.sessions[session] = kept
+	}
+	return ids
+}
+
+// forget drops the searches of a session that ended.
+func (f *followUps) forget(session string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, session)
+}
+
+// recordQuery logs an MCP search or trace query when metrics are enabled,
+// remembering the files it returned for follow-up tracking.
+func (s *Server) recordQuery(ctx context.Context, cfg *config.Config, kind, query string, latency time.Duration, results int, files []string) {
+	if !cfg.Metrics.Enabled {
+		return
+	}
+	st, err := s.openStore(ctx, cfg)
+	if err != nil {
+		log.Printf("Warning: failed to record query: %v", err)
+		return
+	}
+	defer st.Close()
+
+	id := search.RecordQuery(ctx, cfg.Metrics, st, kind, "mcp", query, latency, results)
+	s.followUps.add(sessionID(ctx), id, files)
+}
+
+// followUp credits the session's recent searches that returned file, which
+// the agent just read.
+func (s *Server) followUp(ctx context.Context, cfg *config.Config, file string) {
+	if !cfg.Metrics.Enabled {
+		return
+	}
+	ids := s.followUps.read(sessionID(ctx), file)
+	if len(ids) == 0 {
+		return
+	}
+	st, err := s.openStore(ctx, cfg)
+	if err != nil {
+		log.Printf("Warning: failed to record follow-up: %v", err)
+		return
+	}
+	defer st.Close()
+	for _, id := range ids {
+		if err := st.MarkFollowedUp(ctx, id); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// resultFiles returns the file of each result of an agentdx_search result.
+func resultFiles(result *mcp.CallToolResult) []string {
+	texts := toolResultTexts(result)
+	if len(texts) == 0 {
+		return nil
+	}
+	// Results are a list, or a list of packages with group_by
+	var results []struct {
+		SearchResult
+		Results []SearchResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(texts[0]), &results); err != nil {
+		return nil
+	}
+	var files []string
+	for _, r := range results {
+		if r.Results == nil {
+			files = append(files, r.Path)
+		}
+		for _, grouped := range r.Results {
+			files = append(files, grouped.Path)
+		}
+	}
+	return files
+}
+
+// resultBatches returns the batches of an agentdx_search result with queries.
+func resultBatches(result *mcp.CallToolResult) []SearchBatch {
+	texts := toolResultTexts(result)
+	if len(texts) == 0 {
+		return nil
+	}
+	var batches []SearchBatch
+	if err := json.Unmarshal([]byte(texts[0]), &batches); err != nil {
+		return nil
+	}
+	return batches
+}
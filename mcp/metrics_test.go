@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFollowUps(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := newFollowUps()
+	f.now = func() time.Time { return now }
+
+	f.add("s1", 1, []string{"a.go", "b.go"})
+	f.add("s1", 2, []string{"b.go"})
+	f.add("s1", 0, []string{"a.go"}) // not recorded
+	f.add("s2", 3, []string{"a.go"})
+
+	if ids := f.read("s1", "b.go"); !slices.Equal(ids, []int64{1, 2}) {
+		t.Errorf("read b.go = %v, want [1 2]", ids)
+	}
+	// Each search is credited once
+	if ids := f.read("s1", "a.go"); len(ids) != 0 {
+		t.Errorf("read a.go again = %v, want none", ids)
+	}
+
+	// Searches expire after the follow-up window
+	now = now.Add(followUpWindow + time.Second)
+	if ids := f.read("s2", "a.go"); len(ids) != 0 {
+		t.Errorf("read after window = %v, want none", ids)
+	}
+	if len(f.sessions) != 0 {
+		t.Errorf("sessions = %v, want none", f.sessions)
+	}
+}
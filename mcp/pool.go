@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// storePool holds the stores of the server, opened on the first call that
+// needs them and shared by later calls, instead of a store, and with
+// PostgreSQL a connection pool, per call. A store is opened again when the
+// configuration it was opened with changes. Stores are opened, and ranking
+// profiles loaded, outside mu, so that a slow database only holds up the
+// calls using it.
+type storePool struct {
+	mu      sync.Mutex
+	stores  map[string]*pooledStore // by workspace, "" for the root project
+	symbols *pooledSymbols
+	closed  bool
+
+	// The mcp section of the configuration, read again when the file changes
+	cfgMu   sync.Mutex
+	cfgFile os.FileInfo
+	mcpCfg  config.MCPConfig
+}
+
+// pooledStore is a shared index store and the settings it was opened with.
+type pooledStore struct {
+	st   store.SearchStore
+	key  string      // connection settings, see storeKey
+	file os.FileInfo // the SQLite database, to notice it was replaced
+	// Query settings after the ranking profile was applied
+	cjk       bool
+	expansion config.ExpansionConfig
+
+	refs  int  // calls using the store
+	stale bool // replaced; closed once the last call is done
+
+	// The ranking profile named by the settings, loaded by the first call
+	// sharing the store. Changes to the profile are used once the store is
+	// opened again.
+	profileMu     sync.Mutex
+	profile       *config.RankingProfile
+	profileLoaded bool
+}
+
+// pooledSymbols is a shared PostgreSQL symbol store.
+type pooledSymbols struct {
+	st    trace.SymbolStore
+	key   string
+	refs  int
+	stale bool
+}
+
+func newStorePool() *storePool {
+	return &storePool{stores: make(map[string]*pooledStore)}
+}
+
+// storeKey identifies the connection settings of opts and the query settings
+// of cfg a store was opened with.
+func storeKey(cfg *config.SearchConfig, opts store.Options, encryption config.EncryptionConfig) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%v|%v|%v|%s|%v",
+		opts.Backend, opts.PostgresDSN, opts.PostgresNamespace, opts.SQLitePath, opts.ProjectID,
+		cfg.CJKBigrams, cfg.Identifiers, cfg.Expansion, cfg.Profile, encryption)
+}
+
+// sqliteFile returns the file of a SQLite store, or nil for other backends.
+func sqliteFile(opts store.Options) os.FileInfo {
+	if opts.Backend != store.BackendSQLite {
+		return nil
+	}
+	info, _ := os.Stat(opts.SQLitePath)
+	return info
+}
+
+// search returns the shared store of workspace for cfg and opts, opening it
+// when needed, and applies the ranking profile to cfg as search.OpenStore
+// does. Closing the returned store releases it.
+func (p *storePool) search(ctx context.Context, cfg *config.Config, workspace string, opts store.Options) (store.SearchStore, error) {
+	key := storeKey(&cfg.Index.Search, opts, cfg.Encryption)
+	file := sqliteFile(opts)
+
+	p.mu.Lock()
+	e := p.stores[workspace]
+	// A deleted or recreated SQLite database is a new index
+	if e != nil && (e.key != key || (e.file != nil && (file == nil || !os.SameFile(file, e.file)))) {
+		p.retireStore(workspace, e)
+		e = nil
+	}
+	if e != nil {
+		e.refs++
+	}
+	p.mu.Unlock()
+
+	if e != nil {
+		profile, err := e.rankingProfile(ctx, cfg.Index.Search.Profile)
+		if err != nil {
+			p.releaseStore(e)
+			return nil, err
+		}
+		if profile != nil {
+			cfg.Index.Search.ApplyProfile(*profile)
+		}
+		if cfg.Index.Search.CJKBigrams == e.cjk && reflect.DeepEqual(cfg.Index.Search.Expansion, e.expansion) {
+			return &sharedStore{SearchStore: e.st, release: func() { p.releaseStore(e) }}, nil
+		}
+		// The profile changed the tokenizer or query expansion since the
+		// store was opened; open it with the reloaded settings
+		p.mu.Lock()
+		if p.stores[workspace] == e {
+			p.retireStore(workspace, e)
+		}
+		p.mu.Unlock()
+		p.releaseStore(e)
+	}
+
+	st, err := search.OpenStore(ctx, &cfg.Index.Search, opts)
+	if err != nil {
+		return nil, err
+	}
+	e = &pooledStore{
+		st:        st,
+		key:       key,
+		file:      sqliteFile(opts),
+		cjk:       cfg.Index.Search.CJKBigrams,
+		expansion: cfg.Index.Search.Expansion,
+		refs:      1,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		e.stale = true
+	} else {
+		// A concurrent call may have opened the store too; the last one wins
+		if old := p.stores[workspace]; old != nil {
+			p.retireStore(workspace, old)
+		}
+		p.stores[workspace] = e
+	}
+	return &sharedStore{SearchStore: st, release: func() { p.releaseStore(e) }}, nil
+}
+
+// rankingProfile returns the ranking profile name of the store, loading it
+// on the first call. A failed load is tried again by the next call.
+func (e *pooledStore) rankingProfile(ctx context.Context, name string) (*config.RankingProfile, error) {
+	e.profileMu.Lock()
+	defer e.profileMu.Unlock()
+	if !e.profileLoaded {
+		profile, err := search.RankingProfile(ctx, e.st, name)
+		if err != nil {
+			return nil, err
+		}
+		e.profile = profile
+		e.profileLoaded = true
+	}
+	return e.profile, nil
+}
+
+// retireStore removes e from the pool, closing it unless calls still use it.
+func (p *storePool) retireStore(workspace string, e *pooledStore) {
+	delete(p.stores, workspace)
+	e.stale = true
+	if e.refs == 0 {
+		e.st.Close()
+	}
+}
+
+func (p *storePool) releaseStore(e *pooledStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.refs--
+	if e.stale && e.refs == 0 {
+		e.st.Close()
+	}
+}
+
+// symbolStore returns the shared PostgreSQL symbol store for opts, opening
+// and loading it when needed. Closing the returned store releases it.
+func (p *storePool) symbolStore(ctx context.Context, opts store.Options) (trace.SymbolStore, error) {
+	key := fmt.Sprintf("%s|%s|%s", opts.PostgresDSN, opts.PostgresNamespace, opts.ProjectID)
+
+	p.mu.Lock()
+	if e := p.symbols; e != nil {
+		if e.key == key {
+			e.refs++
+			p.mu.Unlock()
+			return &sharedSymbols{SymbolStore: e.st, release: func() { p.releaseSymbols(e) }}, nil
+		}
+		p.retireSymbols(e)
+	}
+	p.mu.Unlock()
+
+	st, err := store.OpenSymbolStore(ctx, store.SymbolBackendPostgres, "", opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.Load(ctx); err != nil {
+		st.Close()
+		return nil, err
+	}
+	e := &pooledSymbols{st: st, key: key, refs: 1}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		e.stale = true
+	} else {
+		if old := p.symbols; old != nil {
+			p.retireSymbols(old)
+		}
+		p.symbols = e
+	}
+	return &sharedSymbols{SymbolStore: st, release: func() { p.releaseSymbols(e) }}, nil
+}
+
+// retireSymbols removes e from the pool, closing it unless calls still use
+// it.
+func (p *storePool) retireSymbols(e *pooledSymbols) {
+	p.symbols = nil
+	e.stale = true
+	if e.refs == 0 {
+		e.st.Close()
+	}
+}
+
+func (p *storePool) releaseSymbols(e *pooledSymbols) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.refs--
+	if e.stale && e.refs == 0 {
+		e.st.Close()
+	}
+}
+
+// close closes the stores of the pool. Calls still using one keep it open
+// until they are done.
+func (p *storePool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for workspace, e := range p.stores {
+		p.retireStore(workspace, e)
+	}
+	if e := p.symbols; e != nil {
+		p.retireSymbols(e)
+	}
+}
+
+// mcpConfig returns the mcp section of the configuration of the project at
+// projectRoot, read again only when the configuration file changed.
+func (p *storePool) mcpConfig(projectRoot string) (config.MCPConfig, error) {
+	p.cfgMu.Lock()
+	defer p.cfgMu.Unlock()
+
+	info, err := os.Stat(config.GetConfigPath(projectRoot))
+	if err == nil && p.cfgFile != nil && os.SameFile(info, p.cfgFile) &&
+		info.ModTime().Equal(p.cfgFile.ModTime()) && info.Size() == p.cfgFile.Size() {
+		return p.mcpCfg, nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		p.cfgFile = nil
+		return config.MCPConfig{}, err
+	}
+	p.cfgFile = info
+	p.mcpCfg = cfg.MCP
+	return p.mcpCfg, nil
+}
+
+// sharedStore is a pooled index store handed to a call; Close releases it
+// instead of closing it.
+type sharedStore struct {
+	store.SearchStore
+	once    sync.Once
+	release func()
+}
+
+func (s *sharedStore) Close() error {
+	s.once.Do(s.release)
+	return nil
+}
+
+// sharedSymbols is a pooled symbol store handed to a call; Close releases
+// it instead of closing it.
+type sharedSymbols struct {
+	trace.SymbolStore
+	once    sync.Once
+	release func()
+}
+
+func (s *sharedSymbols) Close() error {
+	s.once.Do(s.release)
+	return nil
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestStorePool_SharesStores(t *testing.T) {
+	ctx := context.Background()
+	s := newSearchServer(t, map[string]string{"auth.go": "func Login() error"})
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open := func() store.SearchStore {
+		t.Helper()
+		st, err := s.openStore(ctx, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.(*sharedStore).SearchStore
+	}
+	first := open()
+	second := open()
+	if first != second {
+		t.Error("expected calls to share the store")
+	}
+
+	// Closing a shared store leaves it open for later calls
+	st, _ := s.openStore(ctx, cfg)
+	st.Close()
+	if results, err := open().SearchFTS(ctx, "Login", 10); err != nil || len(results) != 1 {
+		t.Errorf("expected the shared store to still search, got %d results (%v)", len(results), err)
+	}
+
+	// A recreated SQLite database is opened again
+	if err := os.Rename(cfg.GetSQLiteIndexPath(s.projectRoot), cfg.GetSQLiteIndexPath(s.projectRoot)+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if open() == first {
+		t.Error("expected a new store for the recreated database")
+	}
+
+	// Another backend configuration opens another store
+	cfg.Index.Search.CJKBigrams = !cfg.Index.Search.CJKBigrams
+	if open() == first {
+		t.Error("expected a new store for changed settings")
+	}
+}
+
+func TestStorePool_MCPConfig(t *testing.T) {
+	s := newSearchServer(t, nil)
+	setMCPConfig(t, s, config.MCPConfig{MaxConcurrent: 2})
+	limits, err := s.stores.mcpConfig(s.projectRoot)
+	if err != nil || limits.MaxConcurrent != 2 {
+		t.Fatalf("expected max_concurrent 2, got %d (%v)", limits.MaxConcurrent, err)
+	}
+
+	// A changed configuration file is read again
+	setMCPConfig(t, s, config.MCPConfig{MaxConcurrent: 12})
+	limits, err = s.stores.mcpConfig(s.projectRoot)
+	if err != nil || limits.MaxConcurrent != 12 {
+		t.Errorf("expected max_concurrent 12, got %d (%v)", limits.MaxConcurrent, err)
+	}
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// workflowPrompt is an MCP prompt walking an agent through the recommended
+// agentdx workflow: search, then trace, then read.
+type workflowPrompt struct {
+	name        string
+	description string
+	arguments   []mcp.PromptArgument
+	render      func(args map[string]string) string
+}
+
+var workflowPrompts = []workflowPrompt{
+	{
+		name:        "explore-feature",
+		description: "Explore how a feature works: find its code with agentdx_search, connect it with the trace tools and read the key regions.",
+		arguments: []mcp.PromptArgument{
+			{Name: "feature", Description: "The feature to explore, e.g. 'password reset' or 'invoice rendering'", Required: true},
+			{Name: "workspace", Description: "Limit searches to a monorepo workspace"},
+		},
+		render: func(args map[string]string) string {
+			feature := args["feature"]
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Explore how %s works in this codebase using the agentdx tools, in this order:\n\n", feature)
+			fmt.Fprintf(&sb, "1. Search: call agentdx_search with a few short queries naming %s: its domain terms and likely type and function names, not a full sentence. Set group_by to \"package\" to see which packages are involved.", feature)
+			if ws := args["workspace"]; ws != "" {
+				fmt.Fprintf(&sb, " Pass workspace %q.", ws)
+			}
+			sb.WriteString("\n")
+			sb.WriteString("2. Trace: for the central functions found, call agentdx_trace_graph with depth 2 to see how they connect, and agentdx_trace_callers to find the entry points.\n")
+			sb.WriteString("3. Read: expand the most relevant results with agentdx_read_chunk instead of reading whole files, and check agentdx_notes for breadcrumbs left on them.\n\n")
+			fmt.Fprintf(&sb, "Then summarize the entry points, the main components and their responsibilities, the data flow between them, and the files a change to %s would touch, citing file paths and line numbers. Record anything future searches should know with agentdx_note_add.", feature)
+			return sb.String()
+		},
+	},
+	{
+		name:        "impact-analysis",
+		description: "Assess what a change to a function or type affects: every caller, indirect caller and covering test, found with agentdx_search and the trace tools.",
+		arguments: []mcp.PromptArgument{
+			{Name: "symbol", Description: "The function, method or type to change", Required: true},
+			{Name: "change", Description: "The planned change, e.g. 'add a context parameter'"},
+		},
+		render: func(args map[string]string) string {
+			symbol := args["symbol"]
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Assess the impact of changing %s", symbol)
+			if change := args["change"]; change != "" {
+				fmt.Fprintf(&sb, " (%s)", change)
+			}
+			sb.WriteString(" using the agentdx tools, in this order:\n\n")
+			fmt.Fprintf(&sb, "1. Search: call agentdx_search for %s to find its definition and the places that mention it, including tests, configuration and documentation.\n", symbol)
+			fmt.Fprintf(&sb, "2. Trace: call agentdx_trace_callers for %s with exhaustive set to true to list every caller, then agentdx_trace_graph with depth 3 to follow indirect callers, and agentdx_trace_callees to see what it depends on. If it is a Go interface or interface method, call agentdx_trace_implementations for it as well, since callers through the interface reach every implementation.\n", symbol)
+			sb.WriteString("3. Read: read the definition and each call site with agentdx_read_chunk.\n\n")
+			sb.WriteString("Then report the direct and indirect callers grouped by package, the tests that cover them, the public APIs or entry points affected and the risks of the change, citing file paths and line numbers.")
+			return sb.String()
+		},
+	},
+	{
+		name:        "find-implementation",
+		description: "Find where a behavior is implemented, confirm it is the live code path with the trace tools and read it.",
+		arguments: []mcp.PromptArgument{
+			{Name: "behavior", Description: "What the code does, e.g. 'retries failed webhook deliveries'", Required: true},
+			{Name: "lang", Description: "Limit searches to languages, e.g. 'go' or 'ts,tsx'"},
+		},
+		render: func(args map[string]string) string {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Find where this behavior is implemented: %s\n\n", args["behavior"])
+			sb.WriteString("1. Search: call agentdx_search with the distinctive terms of the behavior, such as identifiers, error messages, log strings and configuration keys, rather than a full sentence. Try synonyms if the first queries miss.")
+			if lang := args["lang"]; lang != "" {
+				fmt.Fprintf(&sb, " Pass lang %q.", lang)
+			}
+			sb.WriteString("\n")
+			sb.WriteString("2. Trace: once a candidate is found, confirm with agentdx_trace_callers and agentdx_trace_callees that it is on a live code path rather than dead code or a test helper.\n")
+			sb.WriteString("3. Read: read the implementation with agentdx_read_chunk.\n\n")
+			sb.WriteString("Answer with the file path and line range of the implementation and a short explanation of how it works. If there are several implementations, say which one is used and why.")
+			return sb.String()
+		},
+	},
+}
+
+// registerPrompts registers the workflow prompts with the MCP server.
+func (s *Server) registerPrompts() {
+	for _, p := range workflowPrompts {
+		prompt := mcp.NewPrompt(p.name, mcp.WithPromptDescription(p.description))
+		prompt.Arguments = p.arguments
+		s.mcpServer.AddPrompt(prompt, p.handle)
+	}
+}
+
+// handle renders the prompt for the arguments of a prompts/get request.
+func (p workflowPrompt) handle(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := request.Params.Arguments
+	for _, arg := range p.arguments {
+		if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+			return nil, fmt.Errorf("%s parameter is required", arg.Name)
+		}
+	}
+	return mcp.NewGetPromptResult(p.description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.render(args))),
+	}), nil
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPrompts(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(msg string) string {
+		t.Helper()
+		data, err := json.Marshal(s.mcpServer.HandleMessage(context.Background(), []byte(msg)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+	for _, name := range []string{"explore-feature", "impact-analysis", "find-implementation"} {
+		if !strings.Contains(list, `"name":"`+name+`"`) {
+			t.Errorf("prompts/list misses %s: %s", name, list)
+		}
+	}
+
+	got := call(`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"impact-analysis","arguments":{"symbol":"ChargeCustomer","change":"add a context parameter"}}}`)
+	for _, want := range []string{"ChargeCustomer (add a context parameter)", "agentdx_search", "agentdx_trace_callers", "agentdx_read_chunk", `"role":"user"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompts/get misses %q: %s", want, got)
+		}
+	}
+
+	got = call(`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"explore-feature","arguments":{}}}`)
+	if !strings.Contains(got, `"error"`) || !strings.Contains(got, "feature parameter is required") {
+		t.Errorf("expected a missing argument error, got %s", got)
+	}
+}
@@ -0,0 +1,375 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResourceURIPrefix prefixes the URIs of the indexed files served as MCP
+// resources, e.g. mcp://agentdx/services/api/login.go.
+const ResourceURIPrefix = "mcp://agentdx/"
+
+// resourcePollInterval is how often the index is checked for files added,
+// removed or reindexed by 'agentdx watch'.
+const resourcePollInterval = 5 * time.Second
+
+// Subscription methods, which mcp-go does not implement.
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// stdioSessionID is the ID mcp-go gives the single stdio session.
+const stdioSessionID = "stdio"
+
+// resourceState tracks the indexed files and the sessions subscribed to them.
+type resourceState struct {
+	mu    sync.Mutex
+	files map[string]time.Time       // indexed path -> index mod time; nil before the first sync
+	subs  map[string]map[string]bool // resource URI -> subscribed session IDs
+}
+
+// ResourceURI returns the resource URI of a file indexed at path.
+func ResourceURI(path string) string {
+	return ResourceURIPrefix + path
+}
+
+// resourcePath returns the indexed path of a resource URI.
+func resourcePath(uri string) (string, error) {
+	p, ok := strings.CutPrefix(uri, ResourceURIPrefix)
+	if !ok || p == "" {
+		return "", fmt.Errorf("invalid resource URI %q: expected %s<path>", uri, ResourceURIPrefix)
+	}
+	if clean := path.Clean(p); clean != p || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+		return "", fmt.Errorf("invalid resource URI %q: path must be relative to the project root", uri)
+	}
+	return p, nil
+}
+
+// registerResources registers the template resolving resource URIs to
+// indexed files. The files themselves are listed by syncResources.
+func (s *Server) registerResources() {
+	template := mcp.NewResourceTemplate(ResourceURIPrefix+"{+path}", "Indexed file",
+		mcp.WithTemplateDescription("A file of the agentdx index, read from disk, or reassembled from the index when it changed since it was indexed."),
+		mcp.WithTemplateMIMEType("text/plain"),
+	)
+	s.mcpServer.AddResourceTemplate(template, s.handleReadResource)
+}
+
+// handleReadResource returns the content of an indexed file.
+func (s *Server) handleReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	file, err := resourcePath(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	s.followUp(ctx, cfg, file)
+
+	// Files inside a workspace are indexed in the workspace's store
+	var workspace string
+	if ws := cfg.WorkspaceFor(file); ws != nil {
+		workspace = ws.Name
+	}
+	st, err := s.openWorkspaceStore(ctx, cfg, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+	defer st.Close()
+
+	lines, _, err := search.IndexedLines(ctx, st, s.projectRoot, file)
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		return nil, fmt.Errorf("file not indexed: %s", file)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "text/plain",
+		Text:     strings.Join(lines, "\n") + "\n",
+	}}, nil
+}
+
+// indexedFiles returns the files indexed in the root project and its
+// workspaces with their index mod times.
+func (s *Server) indexedFiles(ctx context.Context) (map[string]time.Time, error) {
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	stats, err := s.listIndexedFiles(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]time.Time, len(stats))
+	for _, f := range stats {
+		files[f.Path] = f.ModTime
+	}
+	return files, nil
+}
+
+// listIndexedFiles lists the files indexed in the root project and its
+// workspaces.
+func (s *Server) listIndexedFiles(ctx context.Context, cfg *config.Config) ([]store.FileStats, error) {
+	workspaces := []string{""}
+	for _, ws := range cfg.Workspaces {
+		workspaces = append(workspaces, ws.Name)
+	}
+
+	var files []store.FileStats
+	for _, workspace := range workspaces {
+		st, err := s.openWorkspaceStore(ctx, cfg, workspace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize store: %w", err)
+		}
+		stats, err := st.ListFilesWithStats(ctx)
+		st.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		files = append(files, stats...)
+	}
+	return files, nil
+}
+
+// syncResources lists the indexed files as resources and notifies the
+// sessions subscribed to files that were reindexed or removed since the
+// previous sync.
+func (s *Server) syncResources(ctx context.Context) error {
+	files, err := s.indexedFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	type update struct{ session, uri string }
+	var updates []update
+	s.resources.mu.Lock()
+	old := s.resources.files
+	s.resources.files = files
+	if old != nil {
+		for uri, sessions := range s.resources.subs {
+			p, _ := resourcePath(uri)
+			if old[p].Equal(files[p]) {
+				continue
+			}
+			for session := range sessions {
+				updates = append(updates, update{session, uri})
+			}
+		}
+	}
+	s.resources.mu.Unlock()
+
+	if old == nil || !samePaths(old, files) {
+		// Replacing the resources notifies clients that the list changed
+		paths := make([]string, 0, len(files))
+		for p := range files {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		resources := make([]server.ServerResource, len(paths))
+		for i, p := range paths {
+			resources[i] = server.ServerResource{
+				Resource: mcp.NewResource(ResourceURI(p), p, mcp.WithMIMEType("text/plain")),
+				Handler:  s.handleReadResource,
+			}
+		}
+		s.mcpServer.SetResources(resources...)
+	}
+
+	for _, u := range updates {
+		// The session may have disconnected since it subscribed
+		_ = s.mcpServer.SendNotificationToSpecificClient(u.session, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": u.uri})
+	}
+	return nil
+}
+
+// samePaths reports whether a and b hold the same paths.
+func samePaths(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p := range a {
+		if _, ok := b[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// watchResources keeps the resources in sync with the index until ctx is
+// done. A failing sync is logged once until it succeeds again, since the
+// index may not exist before 'agentdx watch' first runs.
+func (s *Server) watchResources(ctx context.Context) {
+	ticker := time.NewTicker(resourcePollInterval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		if err := s.syncResources(ctx); err != nil {
+			if err.Error() != lastErr && ctx.Err() == nil {
+				log.Printf("Failed to sync MCP resources: %v", err)
+			}
+			lastErr = err.Error()
+		} else {
+			lastErr = ""
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleSubscription answers a resources/subscribe or resources/unsubscribe
+// request from a session. It returns nil for any other message, which is
+// left to mcp-go.
+func (s *Server) handleSubscription(sessionID string, message []byte) []byte {
+	var req struct {
+		ID     *mcp.RequestId `json:"id"`
+		Method string         `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil || req.ID == nil {
+		return nil
+	}
+	if req.Method != methodSubscribe && req.Method != methodUnsubscribe {
+		return nil
+	}
+
+	var resp any = mcp.NewJSONRPCResultResponse(*req.ID, mcp.EmptyResult{})
+	if _, err := resourcePath(req.Params.URI); err != nil {
+		resp = mcp.NewJSONRPCError(*req.ID, mcp.INVALID_PARAMS, err.Error(), nil)
+	} else if sessionID == "" {
+		resp = mcp.NewJSONRPCError(*req.ID, mcp.INVALID_REQUEST, "subscriptions require a session", nil)
+	} else {
+		s.subscribe(sessionID, req.Params.URI, req.Method == methodSubscribe)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// subscribe adds or removes the subscription of a session to a resource.
+func (s *Server) subscribe(sessionID, uri string, on bool) {
+	s.resources.mu.Lock()
+	defer s.resources.mu.Unlock()
+
+	if s.resources.subs == nil {
+		s.resources.subs = make(map[string]map[string]bool)
+	}
+	sessions := s.resources.subs[uri]
+	if on {
+		if sessions == nil {
+			sessions = make(map[string]bool)
+			s.resources.subs[uri] = sessions
+		}
+		sessions[sessionID] = true
+		return
+	}
+	delete(sessions, sessionID)
+	if len(sessions) == 0 {
+		delete(s.resources.subs, uri)
+	}
+}
+
+// unsubscribeSession removes all subscriptions of a session.
+func (s *Server) unsubscribeSession(sessionID string) {
+	s.resources.mu.Lock()
+	defer s.resources.mu.Unlock()
+
+	for uri, sessions := range s.resources.subs {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(s.resources.subs, uri)
+		}
+	}
+}
+
+// subscriptionReader returns the messages read from in, minus the
+// subscription requests, which are answered on out.
+func (s *Server) subscriptionReader(in io.Reader, out io.Writer, sessionID string) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if resp := s.handleSubscription(sessionID, line); resp != nil {
+					_, _ = out.Write(append(resp, '\n'))
+				} else if _, err := pw.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// subscriptionHandler answers the subscription requests posted to the MCP
+// streamable HTTP transport and passes other requests to next.
+func (s *Server) subscriptionHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, fmt.Sprintf("failed to read request: %v", err))
+			return
+		}
+		if resp := s.handleSubscription(r.Header.Get(server.HeaderKeySessionID), body); resp != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(resp)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// syncWriter serializes writes, so that responses written by the stdio
+// transport and subscription responses do not interleave.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResourcePath(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    string
+		wantErr bool
+	}{
+		{uri: "mcp://agentdx/services/api/login.go", want: "services/api/login.go"},
+		{uri: ResourceURI("main.go"), want: "main.go"},
+		{uri: "mcp://agentdx/", wantErr: true},
+		{uri: "mcp://agentdx/../secret", wantErr: true},
+		{uri: "mcp://agentdx//etc/passwd", wantErr: true},
+		{uri: "mcp://agentdx/a/./b.go", wantErr: true},
+		{uri: "file:///etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resourcePath(tt.uri)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resourcePath(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resourcePath(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestHandleSubscription(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := ResourceURI("main.go")
+
+	resp := s.handleSubscription("s1", []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`))
+	if string(resp) != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Fatalf("subscribe response = %s", resp)
+	}
+	if !s.resources.subs[uri]["s1"] {
+		t.Fatalf("session s1 not subscribed to %s", uri)
+	}
+
+	resp = s.handleSubscription("s1", []byte(`{"jsonrpc":"2.0","id":"x","method":"resources/subscribe","params":{"uri":"file:///etc/passwd"}}`))
+	if !strings.Contains(string(resp), `"error"`) || !strings.Contains(string(resp), `"id":"x"`) {
+		t.Errorf("invalid URI response = %s", resp)
+	}
+	resp = s.handleSubscription("", []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`))
+	if !strings.Contains(string(resp), "require a session") {
+		t.Errorf("sessionless response = %s", resp)
+	}
+
+	// Other messages are left to mcp-go
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"` + uri + `"}}`,
+		`not json`,
+	} {
+		if resp := s.handleSubscription("s1", []byte(msg)); resp != nil {
+			t.Errorf("handleSubscription(%s) = %s, want nil", msg, resp)
+		}
+	}
+
+	s.handleSubscription("s1", []byte(`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"`+uri+`"}}`))
+	if len(s.resources.subs) != 0 {
+		t.Errorf("subscriptions after unsubscribe = %v", s.resources.subs)
+	}
+}
+
+func TestUnsubscribeSession(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.subscribe("s1", ResourceURI("a.go"), true)
+	s.subscribe("s2", ResourceURI("a.go"), true)
+	s.subscribe("s1", ResourceURI("b.go"), true)
+
+	s.unsubscribeSession("s1")
+	if len(s.resources.subs) != 1 || !s.resources.subs[ResourceURI("a.go")]["s2"] {
+		t.Errorf("subscriptions = %v, want only s2 on a.go", s.resources.subs)
+	}
+}
+
+func TestHTTPHandler_Subscribe(t *testing.T) {
+	ts := newTestHTTPServer(t, "secret")
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp",
+		strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"mcp://agentdx/main.go"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Mcp-Session-Id", "session-1")
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		ID     int            `json:"id"`
+		Result map[string]any `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || decoded.ID != 7 || decoded.Result == nil {
+		t.Errorf("status %d, response %+v", resp.StatusCode, decoded)
+	}
+}
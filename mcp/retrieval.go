@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// retrievalPath is the retrieval endpoint for RAG frameworks. Like the rest
+// of the HTTP API, it is only served to clients with the token.
+const retrievalPath = "/v1/retrieval"
+
+// RetrievalRequest is the body of a /v1/retrieval call: a query, or several
+// in Queries, each with the number of passages to return and filters.
+type RetrievalRequest struct {
+	Query   string           `json:"query,omitempty"`
+	TopK    int              `json:"top_k,omitempty"`
+	Filter  *RetrievalFilter `json:"filter,omitempty"`
+	Queries []RetrievalQuery `json:"queries,omitempty"`
+}
+
+// RetrievalQuery is one query of a batch retrieval.
+type RetrievalQuery struct {
+	Query  string           `json:"query"`
+	TopK   int              `json:"top_k,omitempty"`
+	Filter *RetrievalFilter `json:"filter,omitempty"`
+}
+
+// RetrievalFilter restricts the passages of a query, like the agentdx_search
+// parameters of the same names.
+type RetrievalFilter struct {
+	Include   []string `json:"include,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+	Lang      []string `json:"lang,omitempty"`
+	Type      []string `json:"type,omitempty"`
+	Workspace string   `json:"workspace,omitempty"`
+}
+
+// Passage is a retrieved chunk. Metadata holds its location, with the path
+// as source, the key document loaders use.
+type Passage struct {
+	ID       string          `json:"id"`
+	Text     string          `json:"text"`
+	Score    float32         `json:"score"`
+	Metadata PassageMetadata `json:"metadata"`
+}
+
+// PassageMetadata locates a passage in the project.
+type PassageMetadata struct {
+	Source     string `json:"source"`
+	AbsPath    string `json:"abs_path"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Type       string `json:"type,omitempty"`
+	Confidence string `json:"confidence,omitempty"`
+}
+
+// RetrievalResult holds the passages of one query.
+type RetrievalResult struct {
+	Query   string    `json:"query"`
+	Results []Passage `json:"results"`
+}
+
+// handleRetrieval answers a retrieval request with the ranked passages of
+// each query. A single query, from the JSON body or the query string of a
+// GET request (query, top_k, include, exclude, lang, type, workspace), gets
+// a RetrievalResult; queries get {"results": [RetrievalResult, ...]}.
+func (s *Server) handleRetrieval(w http.ResponseWriter, r *http.Request) {
+	var req RetrievalRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		if topK := q.Get("top_k"); topK != "" {
+			n, err := strconv.Atoi(topK)
+			if err != nil {
+				writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, fmt.Sprintf("invalid top_k %q", topK))
+				return
+			}
+			req.TopK = n
+		}
+		req.Filter = &RetrievalFilter{
+			Include:   splitList(q.Get("include")),
+			Exclude:   splitList(q.Get("exclude")),
+			Lang:      splitList(q.Get("lang")),
+			Type:      splitList(q.Get("type")),
+			Workspace: q.Get("workspace"),
+		}
+	case http.MethodPost:
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeHTTPError(w, http.StatusMethodNotAllowed, errcode.InvalidArgs, "method not allowed")
+		return
+	}
+
+	queries := req.Queries
+	if len(queries) == 0 {
+		if strings.TrimSpace(req.Query) == "" {
+			writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, "query or queries is required")
+			return
+		}
+		queries = []RetrievalQuery{{Query: req.Query, TopK: req.TopK, Filter: req.Filter}}
+	}
+
+	results := make([]RetrievalResult, len(queries))
+	for i, q := range queries {
+		if strings.TrimSpace(q.Query) == "" {
+			writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, fmt.Sprintf("query %d is empty", i+1))
+			return
+		}
+		if q.TopK < 0 {
+			writeHTTPError(w, http.StatusBadRequest, errcode.InvalidArgs, "top_k must not be negative")
+			return
+		}
+		passages, warnings, errTexts, err := s.retrieve(r, q)
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, errcode.Of(err), err.Error())
+			return
+		}
+		if errTexts != nil {
+			writeToolError(w, errTexts)
+			return
+		}
+		for _, warning := range warnings {
+			w.Header().Add("X-Agentdx-Warning", warning)
+		}
+		results[i] = RetrievalResult{Query: q.Query, Results: passages}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(req.Queries) == 0 {
+		_ = json.NewEncoder(w).Encode(results[0])
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string][]RetrievalResult{"results": results})
+}
+
+// retrieve runs q through the agentdx_search tool and returns its passages
+// and warnings, or the texts of the tool's error result.
+func (s *Server) retrieve(r *http.Request, q RetrievalQuery) ([]Passage, []string, []string, error) {
+	args := map[string]any{"query": q.Query}
+	if q.TopK > 0 {
+		args["limit"] = q.TopK
+	}
+	if f := q.Filter; f != nil {
+		for name, values := range map[string][]string{"include": f.Include, "exclude": f.Exclude, "lang": f.Lang, "type": f.Type} {
+			if len(values) > 0 {
+				args[name] = strings.Join(values, ",")
+			}
+		}
+		if f.Workspace != "" {
+			args["workspace"] = f.Workspace
+		}
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "agentdx_search"
+	req.Params.Arguments = args
+	result, err := s.limit(req.Params.Name, s.handleSearch)(r.Context(), req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	texts := toolResultTexts(result)
+	if result.IsError {
+		return nil, nil, texts, nil
+	}
+	if len(texts) == 0 {
+		return []Passage{}, nil, nil, nil
+	}
+
+	var found []SearchResult
+	if err := json.Unmarshal([]byte(texts[0]), &found); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+	passages := make([]Passage, len(found))
+	for i, f := range found {
+		passages[i] = Passage{
+			ID:    fmt.Sprintf("%s:%d-%d", f.Path, f.StartLine, f.EndLine),
+			Text:  f.Content,
+			Score: f.Score,
+			Metadata: PassageMetadata{
+				Source:     f.Path,
+				AbsPath:    f.AbsPath,
+				StartLine:  f.StartLine,
+				EndLine:    f.EndLine,
+				Type:       f.Type,
+				Confidence: f.Confidence,
+			},
+		}
+	}
+	// The first block is the JSON result; later ones are notes and warnings
+	return passages, texts[1:], nil, nil
+}
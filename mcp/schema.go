@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tool definition formats for 'agentdx mcp schema'.
+const (
+	SchemaOpenAI     = "openai-tools"    // OpenAI function calling tools
+	SchemaAnthropic  = "anthropic-tools" // Anthropic Messages API tools
+	SchemaJSONSchema = "json-schema"     // one JSON Schema document per tool
+)
+
+// SchemaFormats lists the supported tool definition formats.
+var SchemaFormats = []string{SchemaOpenAI, SchemaAnthropic, SchemaJSONSchema}
+
+// OpenAITool is a tool definition for the OpenAI chat completions API.
+type OpenAITool struct {
+	Type     string         `json:"type"` // always "function"
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction describes a function an OpenAI model may call.
+type OpenAIFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+// AnthropicTool is a tool definition for the Anthropic Messages API.
+type AnthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// ToolSchemas returns the definitions of the registered tools in format,
+// ready to be encoded as JSON.
+func (s *Server) ToolSchemas(format string) (any, error) {
+	tools := s.Tools()
+	switch format {
+	case SchemaOpenAI:
+		defs := make([]OpenAITool, len(tools))
+		for i, tool := range tools {
+			defs[i] = OpenAITool{
+				Type: "function",
+				Function: OpenAIFunction{
+					Name:        tool.Name,
+					Description: tool.Description,
+					Parameters:  toolParameters(tool.InputSchema.Properties, tool.InputSchema.Required),
+				},
+			}
+		}
+		return defs, nil
+	case SchemaAnthropic:
+		defs := make([]AnthropicTool, len(tools))
+		for i, tool := range tools {
+			defs[i] = AnthropicTool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				InputSchema: toolParameters(tool.InputSchema.Properties, tool.InputSchema.Required),
+			}
+		}
+		return defs, nil
+	case SchemaJSONSchema:
+		defs := make([]map[string]any, len(tools))
+		for i, tool := range tools {
+			schema := toolParameters(tool.InputSchema.Properties, tool.InputSchema.Required)
+			schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+			schema["title"] = tool.Name
+			schema["description"] = tool.Description
+			defs[i] = schema
+		}
+		return defs, nil
+	}
+	return nil, fmt.Errorf("unknown schema format %q: use %s", format, strings.Join(SchemaFormats, ", "))
+}
+
+// toolParameters returns the JSON Schema object of a tool's parameters.
+// Tools without parameters get an empty properties object, which function
+// calling APIs require.
+func toolParameters(properties map[string]any, required []string) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToolSchemas(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := s.Tools()
+
+	for _, tc := range []struct {
+		format string
+		schema func(def map[string]any) map[string]any
+	}{
+		{SchemaOpenAI, func(def map[string]any) map[string]any {
+			if def["type"] != "function" {
+				t.Errorf("expected function type, got %v", def["type"])
+			}
+			return def["function"].(map[string]any)["parameters"].(map[string]any)
+		}},
+		{SchemaAnthropic, func(def map[string]any) map[string]any {
+			return def["input_schema"].(map[string]any)
+		}},
+		{SchemaJSONSchema, func(def map[string]any) map[string]any {
+			if def["title"] == "" || def["$schema"] == nil {
+				t.Errorf("expected a titled JSON Schema document, got %v", def)
+			}
+			return def
+		}},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			v, err := s.ToolSchemas(tc.format)
+			if err != nil {
+				t.Fatalf("ToolSchemas failed: %v", err)
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var defs []map[string]any
+			if err := json.Unmarshal(data, &defs); err != nil {
+				t.Fatal(err)
+			}
+			if len(defs) != len(tools) {
+				t.Fatalf("expected %d tools, got %d", len(tools), len(defs))
+			}
+			for _, def := range defs {
+				schema := tc.schema(def)
+				if schema["type"] != "object" || schema["properties"] == nil {
+					t.Errorf("expected an object schema with properties, got %v", schema)
+				}
+			}
+		})
+	}
+
+	if _, err := s.ToolSchemas("yaml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
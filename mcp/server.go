@@ -6,11 +6,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
 	"github.com/doveaia/agentdx/search"
 	"github.com/doveaia/agentdx/store"
 	"github.com/doveaia/agentdx/trace"
@@ -22,15 +30,65 @@ import (
 type Server struct {
 	mcpServer   *server.MCPServer
 	projectRoot string
+	searches    *search.Flight[*mcp.CallToolResult] // dedupes identical concurrent searches
+	budget      *search.Budget                      // per-session search limits
+	followUps   *followUps                          // recent searches credited when their results are read
+	resources   resourceState
+	stores      *storePool  // index stores shared by tool calls
+	calls       callLimiter // caps the tool calls running at once
 }
 
 // SearchResult is a lightweight struct for MCP output.
 type SearchResult struct {
 	FilePath  string  `json:"file_path"`
+	Path      string  `json:"path"`
+	AbsPath   string  `json:"abs_path"`
 	StartLine int     `json:"start_line"`
 	EndLine   int     `json:"end_line"`
 	Score     float32 `json:"score"`
-	Content   string  `json:"content"`
+	// Confidence is high when the result stands out from the other matches
+	Confidence string `json:"confidence,omitempty"`
+	// Strategy is how the query was matched: prefix, identifier, phrase or
+	// natural
+	Strategy string `json:"strategy,omitempty"`
+	// Symbol is the symbol the result is about, of kind SymbolKind, and
+	// Package the package or module of its file
+	Symbol     string          `json:"symbol,omitempty"`
+	SymbolKind string          `json:"symbol_kind,omitempty"`
+	Package    string          `json:"package,omitempty"`
+	Type       string          `json:"type,omitempty"` // code, doc, config or summary
+	Content    string          `json:"content"`
+	Context    *search.Context `json:"context,omitempty"` // surrounding lines
+	Notes      []store.Note    `json:"notes,omitempty"`
+	// Highlights are the spans matching the query, to quote exact lines
+	Highlights []store.Highlight `json:"highlights,omitempty"`
+	// Blame is the last commit of the matched lines (blame)
+	Blame *store.Blame `json:"blame,omitempty"`
+	// Cells are the notebook cells of a result from a Jupyter notebook
+	Cells     []int      `json:"cells,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Stale     bool       `json:"stale,omitempty"` // file changed on disk since it was indexed
+}
+
+// SearchGroup is a package of results returned by agentdx_search with
+// group_by package.
+type SearchGroup struct {
+	Package string         `json:"package"`
+	Kind    string         `json:"kind"` // go, npm, python or dir
+	Dir     string         `json:"dir"`
+	Score   float32        `json:"score"` // score of the best result
+	Hits    int            `json:"hits"`  // results found in the package, before per_group
+	Results []SearchResult `json:"results"`
+}
+
+// SearchBatch holds the results of one query of an agentdx_search call with
+// queries.
+type SearchBatch struct {
+	Query string `json:"query"`
+	// Confidence is high, low or none for the query's own results, before
+	// those returned for an earlier query were dropped
+	Confidence string         `json:"confidence"`
+	Results    []SearchResult `json:"results"`
 }
 
 // IndexStatus represents the current state of the index.
@@ -45,71 +103,194 @@ type IndexStatus struct {
 	BackendHost  string `json:"backend_host,omitempty"`
 	BackendName  string `json:"backend_name,omitempty"`
 	BackendOK    bool   `json:"backend_ok,omitempty"`
+
+	SymbolsLastBuilt string             `json:"symbols_last_built,omitempty"`
+	SymbolDrift      *SymbolDriftStatus `json:"symbol_drift,omitempty"` // set when the symbol index lags the search index
 }
 
+// SymbolDriftStatus summarizes how the symbol index differs from the search
+// index.
+type SymbolDriftStatus struct {
+	Outdated int      `json:"outdated"`
+	Missing  int      `json:"missing"`
+	Removed  int      `json:"removed"`
+	Examples []string `json:"examples,omitempty"` // a few of the files
+	Warning  string   `json:"warning"`
+}
+
+// maxDriftExamples is how many drifted files index_status lists.
+const maxDriftExamples = 10
+
 // FileResult is the output struct for the files tool.
 type FileResult struct {
 	Path    string `json:"path"`
+	AbsPath string `json:"abs_path"`
 	ModTime string `json:"mod_time,omitempty"`
+	Chunks  int    `json:"chunks"`
+}
+
+// ReadChunkResult is the output struct for the read_chunk tool.
+type ReadChunkResult struct {
+	search.Slice
+	AbsPath string `json:"abs_path"`
+	Source  string `json:"source"`          // "index" or "disk"
+	Stale   bool   `json:"stale,omitempty"` // file changed on disk since it was indexed
 }
 
 // NewServer creates a new MCP server for agentdx.
 func NewServer(projectRoot string) (*Server, error) {
 	s := &Server{
 		projectRoot: projectRoot,
+		searches:    search.NewFlight[*mcp.CallToolResult]("search"),
+		budget:      search.NewBudget(),
+		followUps:   newFollowUps(),
+		stores:      newStorePool(),
 	}
 
+	// Drop the resource subscriptions, search budget and follow-ups of closed sessions
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.unsubscribeSession(session.SessionID())
+		s.budget.Forget(session.SessionID())
+		s.followUps.forget(session.SessionID())
+	})
+
 	// Create MCP server
 	s.mcpServer = server.NewMCPServer(
 		"agentdx",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
 	)
 
-	// Register tools
+	// Register tools, resources and prompts
 	s.registerTools()
+	s.registerResources()
+	s.registerPrompts()
 
 	return s, nil
 }
 
+// Tools returns the registered tools sorted by name.
+func (s *Server) Tools() []mcp.Tool {
+	var tools []mcp.Tool
+	for _, tool := range s.mcpServer.ListTools() {
+		tools = append(tools, tool.Tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// absPath returns the absolute path of a file indexed relative to the
+// project root, so agents running from a subdirectory can open it.
+func (s *Server) absPath(path string) string {
+	return filepath.Join(s.projectRoot, filepath.FromSlash(path))
+}
+
+// splitList splits a comma-separated tool argument, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // registerTools registers all agentdx tools with the MCP server.
 func (s *Server) registerTools() {
 	// agentdx_search tool
 	searchTool := mcp.NewTool("agentdx_search",
 		mcp.WithDescription("Semantic code search. Search your codebase using natural language queries. Returns the most relevant code chunks with file paths, line numbers, and similarity scores."),
+		readOnlyTool("Search code"),
 		mcp.WithString("query",
-			mcp.Required(),
-			mcp.Description("Natural language search query (e.g., 'user authentication flow', 'error handling middleware')"),
+			mcp.Description("Natural language search query (e.g., 'user authentication flow', 'error handling middleware'). Required unless queries is set"),
+		),
+		mcp.WithArray("queries",
+			mcp.Description("Several keyword queries to run in one call instead of parallel calls (e.g., ['user', 'auth', 'login']). Results are grouped by query, and code already returned for an earlier query is not repeated; limit applies to each query"),
+			mcp.WithStringItems(),
 		),
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of results to return (default: 10)"),
 		),
+		mcp.WithString("workspace",
+			mcp.Description("Restrict the search to a workspace declared in the config (default: root project)"),
+		),
+		mcp.WithBoolean("deleted",
+			mcp.Description("Search code from recently deleted files instead of live files (default: false)"),
+		),
+		mcp.WithString("include",
+			mcp.Description("Comma-separated glob patterns; only matching files are searched (e.g., 'internal/**,*.go')"),
+		),
+		mcp.WithString("exclude",
+			mcp.Description("Comma-separated glob patterns of files to skip (e.g., '*_test.go,vendor/')"),
+		),
+		mcp.WithString("lang",
+			mcp.Description("Comma-separated languages or extensions to search (e.g., 'go', 'ts,vue')"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Comma-separated chunk types to search: code, doc (Markdown, text), config or summary (directory summaries). Use 'doc' to search documentation deliberately"),
+		),
+		mcp.WithString("symbol",
+			mcp.Description("Comma-separated symbol names; only chunks about these symbols are searched (e.g., 'ParseConfig'), as results report them"),
+		),
+		mcp.WithString("package",
+			mcp.Description("Comma-separated packages or modules; only their files are searched, as results and group_by 'package' name them"),
+		),
+		mcp.WithNumber("context",
+			mcp.Description("Lines of surrounding code to include before and after each result, read from disk (default: 0)"),
+		),
+		mcp.WithString("group_by",
+			mcp.Description("Set to 'package' to group results by Go package, npm workspace or Python module; limit then counts packages. Useful to find where a feature lives"),
+			mcp.Enum(search.GroupPackage),
+		),
+		mcp.WithNumber("per_group",
+			mcp.Description("Maximum number of results per package with group_by (default: 3)"),
+		),
+		mcp.WithNumber("min_score",
+			mcp.Description("Drop results scoring below this score (default: 0). Each result's confidence is high when it stands out from the other matches; when none does, verify with grep"),
+		),
+		mcp.WithBoolean("blame",
+			mcp.Description("Add the author, date and subject of the last commit of each result's matched lines, to see who to mention or how fresh the code is (default: index.search.blame)"),
+		),
 	)
-	s.mcpServer.AddTool(searchTool, s.handleSearch)
+	// Shaped by handleSearch, which charges the session budget for the shaped size
+	s.mcpServer.AddTool(withShapeArgs(searchTool), s.limit(searchTool.Name, s.handleSearch))
 
 	// agentdx_trace_callers tool
 	traceCallersTool := mcp.NewTool("agentdx_trace_callers",
 		mcp.WithDescription("Find all functions that call the specified symbol. Useful for understanding code dependencies before modifying a function."),
+		readOnlyTool("Find callers"),
 		mcp.WithString("symbol",
 			mcp.Required(),
 			mcp.Description("Name of the function/method to find callers for"),
 		),
+		mcp.WithBoolean("exhaustive",
+			mcp.Description("Group callers per definition when several functions share the name (returns definitions[] and unresolved[] instead of callers[])"),
+		),
 	)
-	s.mcpServer.AddTool(traceCallersTool, s.handleTraceCallers)
+	s.addTool(withTraceDepthArgs(traceCallersTool, "callers"), s.handleTraceCallers)
 
 	// agentdx_trace_callees tool
 	traceCalleesTool := mcp.NewTool("agentdx_trace_callees",
 		mcp.WithDescription("Find all functions called by the specified symbol. Useful for understanding what a function depends on."),
+		readOnlyTool("Find callees"),
 		mcp.WithString("symbol",
 			mcp.Required(),
 			mcp.Description("Name of the function/method to find callees for"),
 		),
 	)
-	s.mcpServer.AddTool(traceCalleesTool, s.handleTraceCallees)
+	s.addTool(withTraceDepthArgs(traceCalleesTool, "callees"), s.handleTraceCallees)
 
 	// agentdx_trace_graph tool
 	traceGraphTool := mcp.NewTool("agentdx_trace_graph",
 		mcp.WithDescription("Build a complete call graph around a symbol showing both callers and callees up to a specified depth."),
+		readOnlyTool("Build call graph"),
 		mcp.WithString("symbol",
 			mcp.Required(),
 			mcp.Description("Name of the function/method to build graph for"),
@@ -118,17 +299,30 @@ func (s *Server) registerTools() {
 			mcp.Description("Maximum depth for graph traversal (default: 2)"),
 		),
 	)
-	s.mcpServer.AddTool(traceGraphTool, s.handleTraceGraph)
+	s.addTool(traceGraphTool, s.handleTraceGraph)
+
+	// agentdx_trace_implementations tool
+	traceImplementationsTool := mcp.NewTool("agentdx_trace_implementations",
+		mcp.WithDescription("Find the Go types implementing an interface, or the methods implementing an interface method. Use it where agentdx_trace_callers stops at calls through an interface."),
+		readOnlyTool("Find implementations"),
+		mcp.WithString("symbol",
+			mcp.Required(),
+			mcp.Description("Interface name, interface method name, or Interface.Method"),
+		),
+	)
+	s.addTool(traceImplementationsTool, s.handleTraceImplementations)
 
 	// agentdx_index_status tool
 	indexStatusTool := mcp.NewTool("agentdx_index_status",
 		mcp.WithDescription("Check the health and status of the agentdx index. Returns statistics about indexed files, chunks, and configuration."),
+		readOnlyTool("Index status"),
 	)
-	s.mcpServer.AddTool(indexStatusTool, s.handleIndexStatus)
+	s.addTool(indexStatusTool, s.handleIndexStatus)
 
 	// agentdx_files tool
 	filesTool := mcp.NewTool("agentdx_files",
-		mcp.WithDescription("List indexed files matching a glob pattern. Patterns without path separators are matched recursively by default (e.g., '*.go' matches all Go files). Use explicit paths to limit scope (e.g., 'internal/**', 'cli/*.go')."),
+		mcp.WithDescription("List indexed files matching a glob pattern. Patterns without path separators are matched recursively by default (e.g., '*.go' matches all Go files). Use explicit paths to limit scope (e.g., 'internal/**', 'cli/*.go'). Use since to list only the files that changed lately, e.g. to see what was just edited."),
+		readOnlyTool("List indexed files"),
 		mcp.WithString("pattern",
 			mcp.Required(),
 			mcp.Description("Glob pattern to match files (e.g., '*.go', '**/*.test.ts', 'internal/**')"),
@@ -136,15 +330,80 @@ func (s *Server) registerTools() {
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of results to return (default: 0 = unlimited)"),
 		),
+		mcp.WithString("since",
+			mcp.Description("Only list files modified within an age (e.g., '2h', '7d') or changed since a git revision (e.g., 'main', 'HEAD~5')"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Order: 'path' (default), 'mtime' (newest first) or 'chunks' (most chunks first)"),
+			mcp.Enum(search.FileSorts...),
+		),
+	)
+	s.addTool(filesTool, s.handleFiles)
+
+	// agentdx_read_chunk tool
+	readChunkTool := mcp.NewTool("agentdx_read_chunk",
+		mcp.WithDescription("Read a line range of an indexed file directly from the index, e.g. to expand an agentdx_search result without a separate file read."),
+		readOnlyTool("Read lines"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("File path relative to the project root"),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Required(),
+			mcp.Description("First line to return (1-based)"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Required(),
+			mcp.Description("Last line to return (clamped to the end of the file)"),
+		),
+		mcp.WithBoolean("check_fresh",
+			mcp.Description("Compare the file on disk with the indexed version and read from disk if it changed (default: false)"),
+		),
+	)
+	s.addTool(readChunkTool, s.handleReadChunk)
+
+	// agentdx_notes tool
+	notesTool := mcp.NewTool("agentdx_notes",
+		mcp.WithDescription("List notes left on code regions by users or agents (e.g. 'this is the real entrypoint'). Notes are also included in agentdx_search results."),
+		readOnlyTool("List notes"),
+		mcp.WithString("file",
+			mcp.Description("Only list notes for this file path (default: all files)"),
+		),
+	)
+	s.addTool(notesTool, s.handleNotes)
+
+	// agentdx_note_add tool
+	noteAddTool := mcp.NewTool("agentdx_note_add",
+		mcp.WithDescription("Attach a durable note to a line range of a file. Use it to leave breadcrumbs that help future searches, e.g. which implementation is current."),
+		mcp.WithTitleAnnotation("Add note"),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("File path relative to the project root"),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Required(),
+			mcp.Description("First line of the region (1-based)"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line of the region (default: start_line)"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("Note text"),
+		),
 	)
-	s.mcpServer.AddTool(filesTool, s.handleFiles)
+	s.addTool(noteAddTool, s.handleNoteAdd)
 }
 
 // handleSearch handles the agentdx_search tool call.
 func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	query, err := request.RequireString("query")
-	if err != nil {
-		return mcp.NewToolResultError("query parameter is required"), nil
+	// The query, if any, runs first in a batch search
+	batch := request.GetStringSlice("queries", nil)
+	queries := search.BatchQueries(append([]string{request.GetString("query", "")}, batch...))
+	if len(queries) == 0 {
+		return toolError(errcode.New(errcode.InvalidArgs, "query or queries parameter is required")), nil
 	}
 
 	limit := request.GetInt("limit", 10)
@@ -152,90 +411,319 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 		limit = 10
 	}
 
+	// Refuse searches beyond the session's budget
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+	}
+	budget := cfg.Index.Search.Budget
+	session := sessionID(ctx)
+	if err := s.budget.Start(budget, session); err != nil {
+		return toolError(err), nil
+	}
+
+	// Identical concurrent searches run once; the arguments are the key
+	key, err := json.Marshal(request.GetArguments())
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "invalid arguments: %v", err)), nil
+	}
+	start := time.Now()
+	result, err := s.searches.Do(ctx, string(key), func(ctx context.Context) (*mcp.CallToolResult, error) {
+		return s.search(ctx, request, queries, len(batch) > 0, limit)
+	})
+	if err != nil || result.IsError {
+		return result, err
+	}
+	result = shapeRequestResult(result, request)
+	if len(batch) > 0 {
+		for _, b := range resultBatches(result) {
+			files := make([]string, len(b.Results))
+			for i, r := range b.Results {
+				files[i] = r.Path
+			}
+			s.recordQuery(ctx, cfg, store.QueryKindSearch, b.Query, time.Since(start), len(files), files)
+		}
+	} else {
+		files := resultFiles(result)
+		s.recordQuery(ctx, cfg, store.QueryKindSearch, queries[0], time.Since(start), len(files), files)
+	}
+
+	var size int64
+	for _, text := range toolResultTexts(result) {
+		size += int64(len(text))
+	}
+	warnings := s.budget.Spend(budget, session, size)
+	if len(warnings) == 0 {
+		return result, nil
+	}
+	// The result may be shared with other callers, so warn on a copy
+	warned := *result
+	warned.Content = append([]mcp.Content{}, result.Content...)
+	for _, warning := range warnings {
+		warned.Content = append(warned.Content, mcp.NewTextContent("Warning: "+warning))
+	}
+	return &warned, nil
+}
+
+// sessionID returns the ID of the MCP session making a call. Calls without
+// a session, such as REST API calls, share the empty ID.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// search runs the agentdx_search tool call. A batch search runs each of
+// queries and returns their results grouped by query.
+func (s *Server) search(ctx context.Context, request mcp.CallToolRequest, queries []string, batch bool, limit int) (*mcp.CallToolResult, error) {
 	// Load configuration
 	cfg, err := config.Load(s.projectRoot)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load configuration: %v", err)), nil
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
 	}
 
-	// Initialize PostgreSQL FTS store
-	ftsStore, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, s.projectRoot)
+	// Open index store, scoped to the workspace if one was given
+	ftsStore, err := s.openWorkspaceStore(ctx, cfg, request.GetString("workspace", ""))
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize store: %v", err)), nil
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
 	}
 	defer ftsStore.Close()
 
-	// Search using FTS
-	results, err := ftsStore.SearchFTS(ctx, query, limit*2)
+	// Search using FTS; path filters are applied by the index query
+	types, err := store.ParseChunkTypes(splitList(request.GetString("type", "")))
+	if err != nil {
+		return toolError(err), nil
+	}
+	filter := store.SearchFilter{
+		Include:  splitList(request.GetString("include", "")),
+		Exclude:  splitList(request.GetString("exclude", "")),
+		Langs:    splitList(request.GetString("lang", "")),
+		Types:    types,
+		Symbols:  splitList(request.GetString("symbol", "")),
+		Packages: splitList(request.GetString("package", "")),
+		Deleted:  request.GetBool("deleted", false),
+	}
+	groupBy := request.GetString("group_by", "")
+	if groupBy != "" && groupBy != search.GroupPackage {
+		return toolError(errcode.New(errcode.InvalidArgs, "unknown group_by %q (expected %s)", groupBy, search.GroupPackage)), nil
+	}
+	if batch && groupBy != "" {
+		return toolError(errcode.New(errcode.InvalidArgs, "queries cannot be combined with group_by")), nil
+	}
+	perGroup := request.GetInt("per_group", 3)
+	if perGroup <= 0 {
+		perGroup = 3
+	}
+	minScore := float32(request.GetFloat("min_score", 0))
+	if minScore < 0 {
+		return toolError(errcode.New(errcode.InvalidArgs, "min_score must not be negative")), nil
+	}
+	req := search.Request{
+		Filter:      filter,
+		Limit:       limit,
+		MinScore:    minScore,
+		Blame:       request.GetBool("blame", cfg.Index.Search.Blame),
+		ProjectRoot: s.projectRoot,
+	}
+	if batch {
+		return s.searchBatch(ctx, request, cfg, ftsStore, queries, req)
+	}
+	req.Query = queries[0]
+	if groupBy != "" {
+		req.PerGroup = perGroup
+	}
+	resp, err := search.Run(ctx, ftsStore, cfg.Index.Search, req)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+		return toolError(fmt.Errorf("search failed: %w", err)), nil
+	}
+	results, groups := resp.Results, resp.Groups
+	if len(results) == 0 {
+		if err := search.CheckIndexed(ctx, ftsStore); err != nil {
+			return toolError(err), nil
+		}
 	}
 
-	// Apply structural boosting
-	results = search.ApplyBoost(results, cfg.Index.Search.Boost)
+	// Flag results that may miss recent edits; the check is best effort
+	var staleness *search.Staleness
+	if !filter.Deleted {
+		staleness, _ = search.CheckProjectStaleness(ctx, cfg, s.projectRoot, request.GetString("workspace", ""), ftsStore)
+	}
+	mismatch, _ := search.CheckConfigFingerprint(ctx, cfg, ftsStore)
 
-	// Trim to requested limit
-	if len(results) > limit {
-		results = results[:limit]
+	// Read the lines around each result
+	contexts, err := search.AddContext(ctx, ftsStore, s.projectRoot, results, max(request.GetInt("context", 0), 0))
+	if err != nil {
+		return toolError(err), nil
 	}
 
 	// Convert to lightweight results
+	searchResults := s.searchResults(results, contexts, staleness)
+
+	// Return JSON result
+	var payload any = searchResults
+	if groups != nil {
+		searchGroups := make([]SearchGroup, len(groups))
+		n := 0
+		for i, g := range groups {
+			searchGroups[i] = SearchGroup{
+				Package: g.Name,
+				Kind:    g.Kind,
+				Dir:     g.Dir,
+				Score:   g.Score,
+				Hits:    g.Hits,
+				Results: searchResults[n : n+len(g.Results)],
+			}
+			n += len(g.Results)
+		}
+		payload = searchGroups
+	}
+	return searchToolResult(payload, search.ConfidenceNote(resp.Confidence), resp.WarningTexts(), staleness, mismatch)
+}
+
+// searchBatch runs req for each of queries over ftsStore and returns their
+// results grouped by query. Results overlapping those of an earlier query are
+// dropped.
+func (s *Server) searchBatch(ctx context.Context, request mcp.CallToolRequest, cfg *config.Config, ftsStore store.SearchStore, queries []string, req search.Request) (*mcp.CallToolResult, error) {
+	batches := make([]search.Batch, len(queries))
+	var warnings []string
+	for i, query := range queries {
+		req.Query = query
+		resp, err := search.Run(ctx, ftsStore, cfg.Index.Search, req)
+		if err != nil {
+			return toolError(fmt.Errorf("search failed for %q: %w", query, err)), nil
+		}
+		batches[i] = search.Batch{Query: query, Results: resp.Results, Confidence: resp.Confidence}
+		// A reranker that is down fails every query the same way
+		for _, warning := range resp.WarningTexts() {
+			if !slices.Contains(warnings, warning) {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	search.DedupeBatches(batches)
+	if len(search.FlattenBatches(batches)) == 0 {
+		if err := search.CheckIndexed(ctx, ftsStore); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	var staleness *search.Staleness
+	if !req.Filter.Deleted {
+		staleness, _ = search.CheckProjectStaleness(ctx, cfg, s.projectRoot, request.GetString("workspace", ""), ftsStore)
+	}
+	mismatch, _ := search.CheckConfigFingerprint(ctx, cfg, ftsStore)
+
+	payload := make([]SearchBatch, len(batches))
+	for i, b := range batches {
+		contexts, err := search.AddContext(ctx, ftsStore, s.projectRoot, b.Results, max(request.GetInt("context", 0), 0))
+		if err != nil {
+			return toolError(err), nil
+		}
+		payload[i] = SearchBatch{Query: b.Query, Confidence: b.Confidence, Results: s.searchResults(b.Results, contexts, staleness)}
+	}
+	return searchToolResult(payload, "", warnings, staleness, mismatch)
+}
+
+// searchResults converts results to lightweight results. contexts are
+// aligned with results.
+func (s *Server) searchResults(results []store.SearchResult, contexts []*search.Context, staleness *search.Staleness) []SearchResult {
 	searchResults := make([]SearchResult, len(results))
 	for i, r := range results {
 		searchResults[i] = SearchResult{
-			FilePath:  r.Chunk.FilePath,
-			StartLine: r.Chunk.StartLine,
-			EndLine:   r.Chunk.EndLine,
-			Score:     r.Score,
-			Content:   r.Chunk.Content,
+			FilePath:   r.Chunk.FilePath,
+			Path:       r.Chunk.FilePath,
+			AbsPath:    s.absPath(r.Chunk.FilePath),
+			StartLine:  r.Chunk.StartLine,
+			EndLine:    r.Chunk.EndLine,
+			Score:      r.Score,
+			Confidence: r.Confidence,
+			Strategy:   string(r.Strategy),
+			Symbol:     r.Chunk.Symbol,
+			SymbolKind: r.Chunk.SymbolKind,
+			Package:    r.Chunk.Package,
+			Type:       r.Chunk.Type,
+			Content:    r.Chunk.Content,
+			Context:    contexts[i],
+			Notes:      r.Notes,
+			Highlights: r.Highlights,
+			Blame:      r.Blame,
+			Cells:      r.Cells,
+			DeletedAt:  r.Chunk.DeletedAt,
+			Stale:      staleness != nil && staleness.IsStale(r.Chunk.FilePath),
 		}
 	}
+	return searchResults
+}
 
-	// Return JSON result
-	jsonBytes, err := json.MarshalIndent(searchResults, "", "  ")
+// searchToolResult returns payload as JSON, followed by the confidence note,
+// the failures the search did without, and the staleness and config mismatch
+// warnings, if any.
+func searchToolResult(payload any, note string, warnings []string, staleness *search.Staleness, mismatch *search.ConfigMismatch) (*mcp.CallToolResult, error) {
+	jsonBytes, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+		return toolError(fmt.Errorf("failed to marshal results: %w", err)), nil
 	}
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	result := mcp.NewToolResultText(string(jsonBytes))
+	if note != "" {
+		result.Content = append(result.Content, mcp.NewTextContent("Note: "+note))
+	}
+	for _, warning := range warnings {
+		result.Content = append(result.Content, mcp.NewTextContent("Warning: "+warning))
+	}
+	if staleness != nil {
+		// A separate content block keeps the results parseable as JSON
+		result.Content = append(result.Content, mcp.NewTextContent("Warning: "+staleness.Warning()))
+	}
+	if mismatch != nil {
+		result.Content = append(result.Content, mcp.NewTextContent("Warning: "+mismatch.Warning()))
+	}
+	return result, nil
 }
 
 // handleTraceCallers handles the agentdx_trace_callers tool call.
 func (s *Server) handleTraceCallers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	symbolName, err := request.RequireString("symbol")
 	if err != nil {
-		return mcp.NewToolResultError("symbol parameter is required"), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "symbol parameter is required")), nil
+	}
+	start := time.Now()
+	depth, err := traceDepth(request)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if depth != 1 && request.GetBool("exhaustive", false) {
+		return toolError(errcode.New(errcode.InvalidArgs, "exhaustive cannot be combined with depth or recursive")), nil
 	}
 
 	// Initialize symbol store
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
+	symbolStore, err := s.openSymbolStore(ctx)
+	if err != nil {
+		return toolError(errcode.New(errcode.NoSymbolIndex, "failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
 	}
 	defer symbolStore.Close()
 
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'agentdx watch' first to build the index"), nil
+		return toolError(errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")), nil
 	}
 
 	// Lookup symbol
 	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to lookup symbol: %v", err)), nil
+		return toolError(fmt.Errorf("failed to lookup symbol: %w", err)), nil
 	}
 
 	if len(symbols) == 0 {
-		result := trace.TraceResult{Query: symbolName, Mode: "fast"}
-		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
-		return mcp.NewToolResultText(string(jsonBytes)), nil
+		return s.traceResult(ctx, start, symbolStore, trace.TraceResult{Query: symbolName, Mode: "fast"})
 	}
 
 	// Find callers
 	refs, err := symbolStore.LookupCallers(ctx, symbolName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to lookup callers: %v", err)), nil
+		return toolError(fmt.Errorf("failed to lookup callers: %w", err)), nil
 	}
 
 	result := trace.TraceResult{
@@ -262,51 +750,58 @@ func (s *Server) handleTraceCallers(ctx context.Context, request mcp.CallToolReq
 			},
 		})
 	}
-
-	jsonBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	if request.GetBool("exhaustive", false) {
+		result.Definitions, result.Unresolved = trace.GroupCallers(symbols, refs, result.Callers)
+		result.Callers = nil
+	} else if err := trace.ExpandCallers(ctx, symbolStore, &result, depth); err != nil {
+		return toolError(err), nil
+	}
+	if request.GetBool("flat", false) {
+		result.Flatten()
 	}
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return s.traceResult(ctx, start, symbolStore, result)
 }
 
 // handleTraceCallees handles the agentdx_trace_callees tool call.
 func (s *Server) handleTraceCallees(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	symbolName, err := request.RequireString("symbol")
 	if err != nil {
-		return mcp.NewToolResultError("symbol parameter is required"), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "symbol parameter is required")), nil
+	}
+	start := time.Now()
+	depth, err := traceDepth(request)
+	if err != nil {
+		return toolError(err), nil
 	}
 
 	// Initialize symbol store
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
+	symbolStore, err := s.openSymbolStore(ctx)
+	if err != nil {
+		return toolError(errcode.New(errcode.NoSymbolIndex, "failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
 	}
 	defer symbolStore.Close()
 
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'agentdx watch' first to build the index"), nil
+		return toolError(errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")), nil
 	}
 
 	// Lookup symbol
 	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to lookup symbol: %v", err)), nil
+		return toolError(fmt.Errorf("failed to lookup symbol: %w", err)), nil
 	}
 
 	if len(symbols) == 0 {
-		result := trace.TraceResult{Query: symbolName, Mode: "fast"}
-		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
-		return mcp.NewToolResultText(string(jsonBytes)), nil
+		return s.traceResult(ctx, start, symbolStore, trace.TraceResult{Query: symbolName, Mode: "fast"})
 	}
 
 	// Find callees
 	refs, err := symbolStore.LookupCallees(ctx, symbolName, symbols[0].File)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to lookup callees: %v", err)), nil
+		return toolError(fmt.Errorf("failed to lookup callees: %w", err)), nil
 	}
 
 	result := trace.TraceResult{
@@ -332,21 +827,55 @@ func (s *Server) handleTraceCallees(ctx context.Context, request mcp.CallToolReq
 			},
 		})
 	}
+	if err := trace.ExpandCallees(ctx, symbolStore, &result, depth); err != nil {
+		return toolError(err), nil
+	}
+	if request.GetBool("flat", false) {
+		result.Flatten()
+	}
 
-	jsonBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+	return s.traceResult(ctx, start, symbolStore, result)
+}
+
+// withTraceDepthArgs adds the arguments of recursive traces to a callers or
+// callees tool.
+func withTraceDepthArgs(tool mcp.Tool, direction string) mcp.Tool {
+	for _, opt := range []mcp.ToolOption{
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("Levels of %s to follow: 2 adds the %s of the %s (default: 1). Each entry has its depth and, below the first level, the symbol it is reached via", direction, direction, direction)),
+		),
+		mcp.WithBoolean("recursive",
+			mcp.Description(fmt.Sprintf("Follow %s until every path ends or cycles, ignoring depth. Calls closing a cycle are marked cycle and not followed", direction)),
+		),
+		mcp.WithBoolean("flat",
+			mcp.Description(fmt.Sprintf("List each symbol once, at its shallowest depth, instead of every call: the set of %s reached", direction)),
+		),
+	} {
+		opt(&tool)
 	}
+	return tool
+}
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+// traceDepth returns the levels a callers or callees trace follows, 0 for
+// no limit.
+func traceDepth(request mcp.CallToolRequest) (int, error) {
+	if request.GetBool("recursive", false) {
+		return 0, nil
+	}
+	depth := request.GetInt("depth", 1)
+	if depth < 1 {
+		return 0, errcode.New(errcode.InvalidArgs, "depth must be at least 1, got %d", depth)
+	}
+	return depth, nil
 }
 
 // handleTraceGraph handles the agentdx_trace_graph tool call.
 func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	symbolName, err := request.RequireString("symbol")
 	if err != nil {
-		return mcp.NewToolResultError("symbol parameter is required"), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "symbol parameter is required")), nil
 	}
+	start := time.Now()
 
 	depth := request.GetInt("depth", 2)
 	if depth <= 0 {
@@ -354,21 +883,21 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 	}
 
 	// Initialize symbol store
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
+	symbolStore, err := s.openSymbolStore(ctx)
+	if err != nil {
+		return toolError(errcode.New(errcode.NoSymbolIndex, "failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
 	}
 	defer symbolStore.Close()
 
 	// Check if index exists
 	stats, err := symbolStore.GetStats(ctx)
 	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'agentdx watch' first to build the index"), nil
+		return toolError(errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")), nil
 	}
 
 	graph, err := symbolStore.GetCallGraph(ctx, symbolName, depth)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to build call graph: %v", err)), nil
+		return toolError(fmt.Errorf("failed to build call graph: %w", err)), nil
 	}
 
 	result := trace.TraceResult{
@@ -377,12 +906,58 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 		Graph: graph,
 	}
 
-	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	return s.traceResult(ctx, start, symbolStore, result)
+}
+
+// handleTraceImplementations handles the agentdx_trace_implementations tool call.
+func (s *Server) handleTraceImplementations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("symbol")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "symbol parameter is required")), nil
 	}
+	start := time.Now()
 
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	// Initialize symbol store
+	symbolStore, err := s.openSymbolStore(ctx)
+	if err != nil {
+		return toolError(errcode.New(errcode.NoSymbolIndex, "failed to load symbol index: %v. Run 'agentdx watch' first", err)), nil
+	}
+	defer symbolStore.Close()
+
+	// Check if index exists
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		return toolError(errcode.New(errcode.NoSymbolIndex, "symbol index is empty. Run 'agentdx watch' first to build the index")), nil
+	}
+
+	result, err := trace.Implementations(ctx, symbolStore, query)
+	if err != nil {
+		return toolError(err), nil
+	}
+	result.Mode = "fast"
+
+	return s.traceResult(ctx, start, symbolStore, *result)
+}
+
+// traceResult records a trace query and returns its result, with a warning
+// when the symbol index lags the search index.
+func (s *Server) traceResult(ctx context.Context, start time.Time, symbolStore trace.SymbolStore, result trace.TraceResult) (*mcp.CallToolResult, error) {
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+	}
+	s.recordQuery(ctx, cfg, store.QueryKindTrace, result.Query, time.Since(start), result.Size(), nil)
+
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolError(fmt.Errorf("failed to marshal results: %w", err)), nil
+	}
+	toolResult := mcp.NewToolResultText(string(jsonBytes))
+	// The drift check is best effort
+	if drift, err := s.symbolDrift(ctx, cfg, symbolStore); err == nil && drift.Drifted() {
+		toolResult.Content = append(toolResult.Content, mcp.NewTextContent("Warning: "+drift.Warning()))
+	}
+	return toolResult, nil
 }
 
 // handleIndexStatus handles the agentdx_index_status tool call.
@@ -390,28 +965,40 @@ func (s *Server) handleIndexStatus(ctx context.Context, _ mcp.CallToolRequest) (
 	// Load configuration
 	cfg, err := config.Load(s.projectRoot)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load configuration: %v", err)), nil
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
 	}
 
-	// Initialize PostgreSQL FTS store
-	st, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, s.projectRoot)
+	// Open index store
+	st, err := s.openStore(ctx, cfg)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize store: %v", err)), nil
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
 	}
 	defer st.Close()
 
 	// Get stats
 	stats, err := st.GetStats(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get stats: %v", err)), nil
+		return toolError(fmt.Errorf("failed to get stats: %w", err)), nil
 	}
 
-	// Check symbol index
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
+	// Check symbol index and how it compares with the search index
 	symbolsReady := false
-	if err := symbolStore.Load(ctx); err == nil {
+	var symbolsLastBuilt string
+	var symbolDrift *SymbolDriftStatus
+	if symbolStore, err := s.openSymbolStore(ctx); err == nil {
 		if symbolStats, err := symbolStore.GetStats(ctx); err == nil && symbolStats.TotalSymbols > 0 {
 			symbolsReady = true
+			symbolsLastBuilt = symbolStats.LastUpdated.Format("2006-01-02 15:04:05")
+		}
+		if drift, err := s.symbolDrift(ctx, cfg, symbolStore); err == nil && drift.Drifted() {
+			symbolDrift = &SymbolDriftStatus{
+				Outdated: len(drift.Outdated),
+				Missing:  len(drift.Missing),
+				Removed:  len(drift.Removed),
+				Warning:  drift.Warning(),
+			}
+			examples := slices.Concat(drift.Outdated, drift.Missing, drift.Removed)
+			symbolDrift.Examples = examples[:min(len(examples), maxDriftExamples)]
 		}
 		symbolStore.Close()
 	}
@@ -431,17 +1018,20 @@ func (s *Server) handleIndexStatus(ctx context.Context, _ mcp.CallToolRequest) (
 		TotalChunks:  stats.TotalChunks,
 		IndexSize:    formatBytes(stats.IndexSize),
 		LastUpdated:  stats.LastUpdated.Format("2006-01-02 15:04:05"),
-		Search:       "PostgreSQL FTS",
+		Search:       store.SearchName(backendType),
 		SymbolsReady: symbolsReady,
 		BackendType:  backendType,
 		BackendHost:  backendHost,
 		BackendName:  backendName,
 		BackendOK:    backendOK,
+
+		SymbolsLastBuilt: symbolsLastBuilt,
+		SymbolDrift:      symbolDrift,
 	}
 
 	jsonBytes, err := json.MarshalIndent(status, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal status: %v", err)), nil
+		return toolError(fmt.Errorf("failed to marshal status: %w", err)), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonBytes)), nil
@@ -451,40 +1041,48 @@ func (s *Server) handleIndexStatus(ctx context.Context, _ mcp.CallToolRequest) (
 func (s *Server) handleFiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pattern, err := request.RequireString("pattern")
 	if err != nil {
-		return mcp.NewToolResultError("pattern parameter is required"), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "pattern parameter is required")), nil
 	}
 
 	limit := request.GetInt("limit", 0)
+	since := request.GetString("since", "")
+	sortBy := request.GetString("sort", search.FileSortPath)
 
 	// Load configuration
 	cfg, err := config.Load(s.projectRoot)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load configuration: %v", err)), nil
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
 	}
 
-	// Initialize PostgreSQL FTS store
-	st, err := store.NewPostgresFTSStore(ctx, cfg.Index.Store.Postgres.DSN, s.projectRoot)
+	// Open index store
+	st, err := s.openStore(ctx, cfg)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to connect to postgres: %v", err)), nil
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
 	}
 	defer st.Close()
 
 	// Get all files with stats
 	allFiles, err := st.ListFilesWithStats(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list files: %v", err)), nil
+		return toolError(fmt.Errorf("failed to list files: %w", err)), nil
 	}
 
 	// Filter by glob pattern
 	matched, err := filterFilesByGlob(allFiles, pattern)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid glob pattern: %v", err)), nil
+		return toolError(errcode.New(errcode.InvalidArgs, "invalid glob pattern: %v", err)), nil
 	}
 
-	// Sort alphabetically by path
-	sort.Slice(matched, func(i, j int) bool {
-		return matched[i].Path < matched[j].Path
-	})
+	// Keep the files that changed lately
+	if since != "" {
+		if matched, err = search.FilesSince(matched, s.projectRoot, since, time.Now()); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	if err := search.SortFiles(matched, sortBy); err != nil {
+		return toolError(err), nil
+	}
 
 	// Apply limit if specified
 	if limit > 0 && len(matched) > limit {
@@ -496,18 +1094,174 @@ func (s *Server) handleFiles(ctx context.Context, request mcp.CallToolRequest) (
 	for i, f := range matched {
 		results[i] = FileResult{
 			Path:    f.Path,
+			AbsPath: s.absPath(f.Path),
 			ModTime: f.ModTime.Format("2006-01-02T15:04:05Z"),
+			Chunks:  f.ChunkCount,
 		}
 	}
 
 	jsonBytes, err := json.MarshalIndent(results, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal results: %v", err)), nil
+		return toolError(fmt.Errorf("failed to marshal results: %w", err)), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
+// handleReadChunk handles the agentdx_read_chunk tool call.
+func (s *Server) handleReadChunk(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	file, err := request.RequireString("file")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "file parameter is required")), nil
+	}
+	startLine, err := request.RequireInt("start_line")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "start_line parameter is required")), nil
+	}
+	endLine, err := request.RequireInt("end_line")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "end_line parameter is required")), nil
+	}
+	file = filepath.ToSlash(filepath.Clean(file))
+
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+	}
+	s.followUp(ctx, cfg, file)
+
+	// Files inside a workspace are indexed in the workspace's store
+	var workspace string
+	if ws := cfg.WorkspaceFor(file); ws != nil {
+		workspace = ws.Name
+	}
+	st, err := s.openWorkspaceStore(ctx, cfg, workspace)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
+	}
+	defer st.Close()
+
+	result := ReadChunkResult{AbsPath: s.absPath(file), Source: "index"}
+	if request.GetBool("check_fresh", false) {
+		doc, err := st.GetDocument(ctx, file)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get document: %w", err)), nil
+		}
+		if doc == nil {
+			return toolError(errcode.New(errcode.NotFound, "file not indexed: %s", file)), nil
+		}
+		path := filepath.Join(s.projectRoot, filepath.FromSlash(file))
+		if hash, err := indexer.HashFile(path); err != nil || hash != doc.Hash {
+			result.Stale = true
+		}
+		if result.Stale {
+			content, err := os.ReadFile(path)
+			if text, _, ok := indexer.DecodeFile(file, content); err == nil && ok {
+				slice, err := search.TextSlice(file, text, startLine, endLine)
+				if err != nil {
+					return toolError(err), nil
+				}
+				result.Slice = *slice
+				result.Source = "disk"
+			}
+		}
+	}
+
+	if result.Source == "index" {
+		chunks, err := st.GetChunksForFile(ctx, file)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get chunks: %w", err)), nil
+		}
+		slice, err := search.ChunkSlice(file, chunks, startLine, endLine)
+		if err != nil {
+			return toolError(err), nil
+		}
+		result.Slice = *slice
+	}
+
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolError(fmt.Errorf("failed to marshal result: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// handleNotes handles the agentdx_notes tool call.
+func (s *Server) handleNotes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+	}
+
+	st, err := s.openStore(ctx, cfg)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
+	}
+	defer st.Close()
+
+	notes, err := st.ListNotes(ctx, request.GetString("file", ""))
+	if err != nil {
+		return toolError(err), nil
+	}
+	if notes == nil {
+		notes = []store.Note{}
+	}
+
+	jsonBytes, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return toolError(fmt.Errorf("failed to marshal notes: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// handleNoteAdd handles the agentdx_note_add tool call.
+func (s *Server) handleNoteAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	file, err := request.RequireString("file")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "file parameter is required")), nil
+	}
+	startLine, err := request.RequireInt("start_line")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "start_line parameter is required")), nil
+	}
+	text, err := request.RequireString("text")
+	if err != nil {
+		return toolError(errcode.New(errcode.InvalidArgs, "text parameter is required")), nil
+	}
+
+	note := store.Note{
+		FilePath:  filepath.ToSlash(filepath.Clean(file)),
+		StartLine: startLine,
+		EndLine:   request.GetInt("end_line", startLine),
+		Text:      text,
+	}
+	if err := note.Validate(); err != nil {
+		return toolError(err), nil
+	}
+
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to load configuration: %w", err)), nil
+	}
+
+	st, err := s.openStore(ctx, cfg)
+	if err != nil {
+		return toolError(fmt.Errorf("failed to initialize store: %w", err)), nil
+	}
+	defer st.Close()
+
+	note, err = st.AddNote(ctx, note)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	jsonBytes, err := json.MarshalIndent(note, "", "  ")
+	if err != nil {
+		return toolError(fmt.Errorf("failed to marshal note: %w", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
 // normalizeGlobPattern makes patterns without path separators recursive by default.
 // "*.go" becomes "**/*.go" to match all Go files recursively.
 // Patterns with "/" or "**" are left unchanged.
@@ -551,7 +1305,64 @@ func formatBytes(b int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
+// openStore opens the index store configured for the project.
+func (s *Server) openStore(ctx context.Context, cfg *config.Config) (store.SearchStore, error) {
+	return s.openWorkspaceStore(ctx, cfg, "")
+}
+
+// openWorkspaceStore opens the index store scoped to a workspace's project ID.
+// An empty workspace name opens the root project.
+func (s *Server) openWorkspaceStore(ctx context.Context, cfg *config.Config, workspace string) (store.SearchStore, error) {
+	projectID := cfg.ProjectID(s.projectRoot)
+	if workspace != "" {
+		ws, err := cfg.Workspace(workspace)
+		if err != nil {
+			return nil, err
+		}
+		projectID = ws.ProjectID(projectID)
+	}
+	opts := store.OptionsFromConfig(cfg, s.projectRoot)
+	opts.ProjectID = projectID
+	return s.stores.search(ctx, cfg, workspace, opts)
+}
+
+// openSymbolStore opens and loads the symbol index configured by trace.store.
+// A PostgreSQL index is shared by calls; a local one is a file the watch
+// daemon rewrites, so it is read again by each call.
+func (s *Server) openSymbolStore(ctx context.Context) (trace.SymbolStore, error) {
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Index.Trace.Store == store.SymbolBackendPostgres {
+		return s.stores.symbolStore(ctx, store.OptionsFromConfig(cfg, s.projectRoot))
+	}
+	return store.OpenProjectSymbolStore(ctx, cfg, s.projectRoot)
+}
+
+// symbolDrift compares the symbol index with the search index of the root
+// project and its workspaces.
+func (s *Server) symbolDrift(ctx context.Context, cfg *config.Config, symbolStore trace.SymbolStore) (*search.SymbolDrift, error) {
+	files, err := s.listIndexedFiles(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return search.CheckSymbolDrift(ctx, files, symbolStore, cfg.Index.Trace.Languages())
+}
+
+// Close closes the stores shared by the tool calls.
+func (s *Server) Close() {
+	s.stores.close()
+}
+
 // Serve starts the MCP server using stdio transport.
 func (s *Server) Serve() error {
-	return server.ServeStdio(s.mcpServer)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	defer s.Close()
+	go s.watchResources(ctx)
+
+	out := &syncWriter{w: os.Stdout}
+	in := s.subscriptionReader(os.Stdin, out, stdioSessionID)
+	return server.NewStdioServer(s.mcpServer).Listen(ctx, in, out)
 }
@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newSearchServer returns a server for a project whose SQLite index holds
+// the given files, one chunk each.
+func newSearchServer(t *testing.T, files map[string]string) *Server {
+	t.Helper()
+	ctx := context.Background()
+	root := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.Index.Store.Backend = store.BackendSQLite
+	cfg.Index.Search.StaleAfterSeconds = -1
+	if err := cfg.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	st, err := store.NewSQLiteFTSStore(ctx, cfg.GetSQLiteIndexPath(root), cfg.ProjectID(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	for path, content := range files {
+		chunk := store.Chunk{ID: path + "_0", FilePath: path, StartLine: 1, EndLine: strings.Count(content, "\n") + 1, Content: content, Hash: path, UpdatedAt: time.Now()}
+		if err := st.SaveChunks(ctx, []store.Chunk{chunk}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SaveDocument(ctx, store.Document{Path: path, Hash: path, ModTime: time.Now(), ChunkIDs: []string{chunk.ID}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHandleSearch_Queries(t *testing.T) {
+	s := newSearchServer(t, map[string]string{
+		"user.go":  "type User struct {\n\tName string\n}",
+		"auth.go":  "// Login authenticates a user\nfunc Login(u User) error",
+		"login.go": "func LoginPage() string",
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "agentdx_search"
+	request.Params.Arguments = map[string]any{"queries": []any{"user", "login", "user"}}
+	result, err := s.handleSearch(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearch failed: %v %v", err, toolResultTexts(result))
+	}
+
+	var batches []SearchBatch
+	if err := json.Unmarshal([]byte(toolResultTexts(result)[0]), &batches); err != nil {
+		t.Fatalf("result is not a list of batches: %v", err)
+	}
+	if len(batches) != 2 || batches[0].Query != "user" || batches[1].Query != "login" {
+		t.Fatalf("unexpected batches %+v", batches)
+	}
+	seen := make(map[string]bool)
+	for _, b := range batches {
+		for _, r := range b.Results {
+			if seen[r.Path] {
+				t.Errorf("%s returned for more than one query", r.Path)
+			}
+			seen[r.Path] = true
+		}
+	}
+	if !seen["user.go"] || !seen["auth.go"] || !seen["login.go"] {
+		t.Errorf("expected all files in the results, got %v", seen)
+	}
+
+	request.Params.Arguments = map[string]any{"queries": []any{"user"}, "group_by": "package"}
+	if result, _ := s.handleSearch(context.Background(), request); !result.IsError {
+		t.Error("expected an error for queries with group_by")
+	}
+	request.Params.Arguments = map[string]any{}
+	if result, _ := s.handleSearch(context.Background(), request); !result.IsError {
+		t.Error("expected an error without query or queries")
+	}
+}
+
+func TestHandleSearch_Confidence(t *testing.T) {
+	s := newSearchServer(t, map[string]string{
+		"a.go": "func Retry() {}",
+		"b.go": "func Retry() {}",
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "agentdx_search"
+	request.Params.Arguments = map[string]any{"query": "retry"}
+	result, err := s.handleSearch(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearch failed: %v %v", err, toolResultTexts(result))
+	}
+	texts := toolResultTexts(result)
+	var results []SearchResult
+	if err := json.Unmarshal([]byte(texts[0]), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Confidence != "low" {
+		t.Fatalf("expected two equally scored low-confidence results, got %+v", results)
+	}
+	if len(texts) < 2 || !strings.HasPrefix(texts[1], "Note: low confidence") {
+		t.Errorf("expected a low confidence note, got %q", texts[1:])
+	}
+
+	request.Params.Arguments = map[string]any{"query": "retry", "min_score": 1e9}
+	result, err = s.handleSearch(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearch failed: %v %v", err, toolResultTexts(result))
+	}
+	texts = toolResultTexts(result)
+	if texts[0] != "[]" || len(texts) < 2 || !strings.HasPrefix(texts[1], "Note: no confident result") {
+		t.Errorf("expected no results and a note, got %q", texts)
+	}
+}
+
+func TestHandleSearch_Warnings(t *testing.T) {
+	s := newSearchServer(t, map[string]string{
+		"a.go": "func Retry() {}",
+		"b.go": "func Retry() {}",
+	})
+	reranker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer reranker.Close()
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Index.Search.Rerank = config.RerankConfig{Enabled: true, Endpoint: reranker.URL, TimeoutMs: 1000}
+	if err := cfg.Save(s.projectRoot); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing reranker is reported once, after the results, for single
+	// and batch searches
+	for _, args := range []map[string]any{{"query": "Retry"}, {"queries": []any{"Retry", "func"}}} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := s.handleSearch(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearch failed: %v %v", err, toolResultTexts(result))
+		}
+		warnings := 0
+		for _, text := range toolResultTexts(result)[1:] {
+			if strings.HasPrefix(text, "Warning: reranker failed") {
+				warnings++
+			}
+		}
+		if warnings != 1 {
+			t.Errorf("expected one reranker warning for %v, got %v", args, toolResultTexts(result))
+		}
+	}
+}
+
+func TestHandleSearch_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		args  map[string]any
+		want  errcode.Code
+	}{
+		{"missing query", map[string]string{"a.go": "package a"}, map[string]any{}, errcode.InvalidArgs},
+		{"invalid type", map[string]string{"a.go": "package a"}, map[string]any{"query": "a", "type": "binary"}, errcode.InvalidArgs},
+		{"negative min_score", map[string]string{"a.go": "package a"}, map[string]any{"query": "a", "min_score": -1}, errcode.InvalidArgs},
+		{"empty index", nil, map[string]any{"query": "user"}, errcode.NoIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSearchServer(t, tt.files)
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "agentdx_search"
+			request.Params.Arguments = tt.args
+			result, err := s.handleSearch(context.Background(), request)
+			if err != nil || !result.IsError {
+				t.Fatalf("expected a tool error, got %v %v", err, toolResultTexts(result))
+			}
+			var payload errcode.Payload
+			if err := json.Unmarshal([]byte(toolResultTexts(result)[0]), &payload); err != nil {
+				t.Fatalf("error is not a JSON payload: %v", err)
+			}
+			if payload.Code != tt.want || payload.Error == "" {
+				t.Errorf("payload = %+v, want code %s", payload, tt.want)
+			}
+		})
+	}
+
+	// A query without matches in a populated index is not an error
+	s := newSearchServer(t, map[string]string{"a.go": "package a"})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "agentdx_search"
+	request.Params.Arguments = map[string]any{"query": "nothingmatches"}
+	if result, err := s.handleSearch(context.Background(), request); err != nil || result.IsError {
+		t.Errorf("expected empty results, got %v %v", err, toolResultTexts(result))
+	}
+}
@@ -0,0 +1,314 @@
+// Package agentdx embeds agentdx search and call graph tracing in other Go
+// programs, such as developer portals and bots, without running the CLI.
+//
+// A Client queries the index of a project, which 'agentdx watch' builds and
+// keeps up to date:
+//
+//	client, err := agentdx.Open(ctx, "/path/to/project", agentdx.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	found, err := client.Search(ctx, "user login", agentdx.SearchOptions{Limit: 5})
+//
+// Errors carry the codes of the errcode package, e.g. errcode.BackendDown
+// when the index store is unreachable; read them with errcode.Of.
+package agentdx
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+const (
+	defaultSearchLimit = 10
+	defaultGraphDepth  = 2
+)
+
+// Trace directions.
+const (
+	TraceCallers = "callers"
+	TraceCallees = "callees"
+	TraceGraph   = "graph"
+)
+
+// ErrSymbolIndexEmpty is returned by Trace before the call graph is indexed.
+var ErrSymbolIndexEmpty = errcode.New(errcode.NoSymbolIndex, "symbol index is empty; run 'agentdx watch' to build it")
+
+// Types shared with the CLI and the MCP tools.
+type (
+	Highlight   = store.Highlight
+	Note        = store.Note
+	Symbol      = trace.Symbol
+	TraceResult = trace.TraceResult
+)
+
+// Client queries the index of one project. It is safe for concurrent use.
+type Client struct {
+	projectRoot string
+	workspace   string
+	cfg         *config.Config
+	store       store.SearchStore
+}
+
+// Options configures Open.
+type Options struct {
+	Workspace string // Query the index of this monorepo workspace instead of the root project
+}
+
+// SearchOptions configures Search. Zero values search all live files.
+type SearchOptions struct {
+	Limit   int      // Maximum number of results (default 10)
+	Include []string // Glob patterns; files must match at least one
+	Exclude []string // Glob patterns; files matching any are skipped
+	Langs   []string // Languages (go, typescript) or extensions (vue)
+	Types   []string // Chunk types: code, doc, config or summary
+	// Symbols and Packages restrict results to chunks about these symbols,
+	// or files of these packages or modules, as results report them
+	Symbols  []string
+	Packages []string
+	Deleted  bool // Search files deleted within the retention period instead
+}
+
+// SearchResults are the matches of a search.
+type SearchResults struct {
+	Results []SearchResult `json:"results"`
+	// Warnings report what the search did without, e.g. a failing reranker
+	// leaving the full-text order, or notes that failed to load
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SearchResult is a ranked search match.
+type SearchResult struct {
+	Path       string      `json:"path"` // relative to the project root
+	AbsPath    string      `json:"abs_path"`
+	StartLine  int         `json:"start_line"`
+	EndLine    int         `json:"end_line"`
+	Score      float32     `json:"score"`
+	Type       string      `json:"type,omitempty"`        // code, doc, config or summary
+	Symbol     string      `json:"symbol,omitempty"`      // symbol the match is about
+	SymbolKind string      `json:"symbol_kind,omitempty"` // function, method, type...
+	Package    string      `json:"package,omitempty"`     // package or module of the file
+	Content    string      `json:"content"`
+	Notes      []Note      `json:"notes,omitempty"`      // notes overlapping the match
+	Highlights []Highlight `json:"highlights,omitempty"` // spans matching the query
+	Cells      []int       `json:"cells,omitempty"`      // notebook cells of a match in a Jupyter notebook
+	DeletedAt  *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// TraceOptions configures Trace.
+type TraceOptions struct {
+	Direction  string // TraceCallers (default), TraceCallees or TraceGraph
+	Depth      int    // Levels of callers or callees followed (default 1), or call graph depth (default 2)
+	Recursive  bool   // Follow callers or callees until every path ends or cycles, ignoring Depth
+	Flat       bool   // List each caller or callee once, at its shallowest level
+	Exhaustive bool   // Group callers per definition of the symbol
+}
+
+// IndexStatus describes the index of the project.
+type IndexStatus struct {
+	Files       int       `json:"files"`
+	Chunks      int       `json:"chunks"`
+	IndexSize   int64     `json:"index_size"` // bytes
+	LastUpdated time.Time `json:"last_updated"`
+	Backend     string    `json:"backend"` // sqlite or postgres
+	Healthy     bool      `json:"healthy"`
+	Symbols     int       `json:"symbols"` // 0 until the call graph is indexed
+	// Warnings report an index that lags behind the files on disk or was
+	// built with other chunking settings
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Open opens the index of the project at projectRoot, configured by its
+// .agentdx/config.yaml. Close the client when done.
+func Open(ctx context.Context, projectRoot string, opts Options) (*Client, error) {
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project root: %w", err)
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	storeOpts := store.OptionsFromConfig(cfg, root)
+	if opts.Workspace != "" {
+		ws, err := cfg.Workspace(opts.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		storeOpts.ProjectID = ws.ProjectID(storeOpts.ProjectID)
+	}
+	st, err := search.OpenStore(ctx, &cfg.Index.Search, storeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index store: %w", err)
+	}
+
+	return &Client{projectRoot: root, workspace: opts.Workspace, cfg: cfg, store: st}, nil
+}
+
+// Close releases the index store.
+func (c *Client) Close() error {
+	return c.store.Close()
+}
+
+// Search returns the chunks best matching query, ranked like 'agentdx search'.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) (*SearchResults, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errcode.New(errcode.InvalidArgs, "query is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	types, err := store.ParseChunkTypes(opts.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.SearchFilter{
+		Include:  opts.Include,
+		Exclude:  opts.Exclude,
+		Langs:    opts.Langs,
+		Types:    types,
+		Symbols:  opts.Symbols,
+		Packages: opts.Packages,
+		Deleted:  opts.Deleted,
+	}
+	resp, err := search.Run(ctx, c.store, c.cfg.Index.Search, search.Request{
+		Query:       query,
+		Filter:      filter,
+		Limit:       limit,
+		ProjectRoot: c.projectRoot,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	out := make([]SearchResult, len(resp.Results))
+	for i, r := range resp.Results {
+		out[i] = SearchResult{
+			Path:       r.Chunk.FilePath,
+			AbsPath:    filepath.Join(c.projectRoot, filepath.FromSlash(r.Chunk.FilePath)),
+			StartLine:  r.Chunk.StartLine,
+			EndLine:    r.Chunk.EndLine,
+			Score:      r.Score,
+			Type:       r.Chunk.Type,
+			Symbol:     r.Chunk.Symbol,
+			SymbolKind: r.Chunk.SymbolKind,
+			Package:    r.Chunk.Package,
+			Content:    r.Chunk.Content,
+			Notes:      r.Notes,
+			Highlights: r.Highlights,
+			Cells:      r.Cells,
+			DeletedAt:  r.Chunk.DeletedAt,
+		}
+	}
+	return &SearchResults{Results: out, Warnings: resp.WarningTexts()}, nil
+}
+
+// Trace returns the callers, callees or call graph of a symbol, like
+// 'agentdx trace'. The result has no Symbol when the symbol is not defined,
+// except for call graphs.
+func (c *Client) Trace(ctx context.Context, symbol string, opts TraceOptions) (*TraceResult, error) {
+	if symbol == "" {
+		return nil, errcode.New(errcode.InvalidArgs, "symbol is required")
+	}
+
+	symbolStore, err := store.OpenProjectSymbolStore(ctx, c.cfg, c.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load symbol index: %w", err)
+	}
+	defer symbolStore.Close()
+
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		return nil, ErrSymbolIndexEmpty
+	}
+
+	levels := max(opts.Depth, 1)
+	if opts.Recursive {
+		levels = 0
+	}
+	var result *TraceResult
+	switch opts.Direction {
+	case "", TraceCallers:
+		if levels != 1 && opts.Exhaustive {
+			return nil, errcode.New(errcode.InvalidArgs, "exhaustive cannot be combined with depth or recursive")
+		}
+		if result, err = trace.Callers(ctx, symbolStore, symbol, opts.Exhaustive); err == nil {
+			err = trace.ExpandCallers(ctx, symbolStore, result, levels)
+		}
+	case TraceCallees:
+		if result, err = trace.Callees(ctx, symbolStore, symbol); err == nil {
+			err = trace.ExpandCallees(ctx, symbolStore, result, levels)
+		}
+	case TraceGraph:
+		depth := opts.Depth
+		if depth <= 0 {
+			depth = defaultGraphDepth
+		}
+		result, err = trace.Graph(ctx, symbolStore, symbol, depth)
+	default:
+		return nil, errcode.New(errcode.InvalidArgs, "unknown trace direction %q (use callers, callees or graph)", opts.Direction)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Flat {
+		result.Flatten()
+	}
+	result.Mode = c.cfg.Index.Trace.Mode
+	return result, nil
+}
+
+// IndexStatus returns statistics and the health of the index.
+func (c *Client) IndexStatus(ctx context.Context) (*IndexStatus, error) {
+	stats, err := c.store.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	status := &IndexStatus{
+		Files:       stats.TotalFiles,
+		Chunks:      stats.TotalChunks,
+		IndexSize:   stats.IndexSize,
+		LastUpdated: stats.LastUpdated,
+	}
+	if bs := c.store.BackendStatus(ctx); bs != nil {
+		status.Backend = bs.Type
+		status.Healthy = bs.Healthy
+	}
+
+	if symbolStore, err := store.OpenProjectSymbolStore(ctx, c.cfg, c.projectRoot); err == nil {
+		if symbolStats, err := symbolStore.GetStats(ctx); err == nil {
+			status.Symbols = symbolStats.TotalSymbols
+		}
+		symbolStore.Close()
+	}
+
+	staleness, err := search.CheckProjectStaleness(ctx, c.cfg, c.projectRoot, c.workspace, c.store)
+	if err != nil {
+		return nil, err
+	}
+	if staleness != nil {
+		status.Warnings = append(status.Warnings, staleness.Warning())
+	}
+	mismatch, err := search.CheckConfigFingerprint(ctx, c.cfg, c.store)
+	if err != nil {
+		return nil, err
+	}
+	if mismatch != nil {
+		status.Warnings = append(status.Warnings, mismatch.Warning())
+	}
+	return status, nil
+}
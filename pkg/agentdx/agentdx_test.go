@@ -0,0 +1,152 @@
+package agentdx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// newTestProject creates a project with a SQLite index holding two files.
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	root := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.Index.Store.Backend = store.BackendSQLite
+	cfg.Index.Search.StaleAfterSeconds = -1
+	if err := cfg.Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	st, err := store.NewSQLiteFTSStore(ctx, cfg.GetSQLiteIndexPath(root), cfg.ProjectID(root))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+	now := time.Now()
+	chunks := []store.Chunk{
+		{ID: "auth.go_0", FilePath: "auth/auth.go", StartLine: 1, EndLine: 3, Content: "func Login(user string) error {\n\treturn Connect()\n}", Hash: "a", UpdatedAt: now},
+		{ID: "guide.md_0", FilePath: "docs/guide.md", StartLine: 1, EndLine: 2, Content: "# Login\nHow login works.", Hash: "b", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, c := range chunks {
+		if err := st.SaveDocument(ctx, store.Document{Path: c.FilePath, Hash: c.Hash, ModTime: now, ChunkIDs: []string{c.ID}}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+	return root
+}
+
+func TestClient_Search(t *testing.T) {
+	ctx := context.Background()
+	root := newTestProject(t)
+
+	client, err := Open(ctx, root, Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer client.Close()
+
+	found, err := client.Search(ctx, "login", SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(found.Results) != 2 || len(found.Warnings) != 0 {
+		t.Fatalf("expected 2 results and no warnings, got %d and %v", len(found.Results), found.Warnings)
+	}
+
+	found, err = client.Search(ctx, "login", SearchOptions{Types: []string{"doc"}})
+	if err != nil || len(found.Results) != 1 || found.Results[0].Path != "docs/guide.md" {
+		t.Fatalf("expected the doc result, got %+v (%v)", found, err)
+	}
+	r := found.Results[0]
+	if r.AbsPath != filepath.Join(root, "docs", "guide.md") || r.Type != store.ChunkTypeDoc || len(r.Highlights) == 0 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	if _, err := client.Search(ctx, " ", SearchOptions{}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+	if _, err := client.Search(ctx, "login", SearchOptions{Types: []string{"tests"}}); err == nil {
+		t.Error("expected an error for an unknown chunk type")
+	}
+}
+
+func TestClient_IndexStatus(t *testing.T) {
+	ctx := context.Background()
+	client, err := Open(ctx, newTestProject(t), Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer client.Close()
+
+	status, err := client.IndexStatus(ctx)
+	if err != nil {
+		t.Fatalf("IndexStatus failed: %v", err)
+	}
+	if status.Files != 2 || status.Chunks != 2 || status.Backend != store.BackendSQLite || !status.Healthy || status.Symbols != 0 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestClient_Trace(t *testing.T) {
+	ctx := context.Background()
+	root := newTestProject(t)
+	client, err := Open(ctx, root, Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Trace(ctx, "Login", TraceOptions{}); !errors.Is(err, ErrSymbolIndexEmpty) {
+		t.Fatalf("expected ErrSymbolIndexEmpty, got %v", err)
+	}
+
+	cfg, err := config.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := trace.NewGOBSymbolStore(cfg.GetSymbolStorePath(root))
+	if err := symbols.SaveFile(ctx, "auth/auth.go",
+		[]trace.Symbol{
+			{Name: "Login", Kind: trace.KindFunction, File: "auth/auth.go", Line: 1, Language: "go"},
+			{Name: "Connect", Kind: trace.KindFunction, File: "db/db.go", Line: 1, Language: "go"},
+		},
+		[]trace.Reference{{SymbolName: "Connect", File: "auth/auth.go", Line: 2, CallerName: "Login", CallerFile: "auth/auth.go", CallerLine: 1}},
+	); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if err := symbols.Close(); err != nil {
+		t.Fatalf("failed to persist symbols: %v", err)
+	}
+	if _, err := os.Stat(cfg.GetSymbolStorePath(root)); err != nil {
+		t.Fatalf("expected a symbol index: %v", err)
+	}
+
+	result, err := client.Trace(ctx, "Connect", TraceOptions{})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+	if result.Symbol == nil || len(result.Callers) != 1 || result.Callers[0].Symbol.Name != "Login" {
+		t.Errorf("unexpected callers %+v", result)
+	}
+
+	result, err = client.Trace(ctx, "Login", TraceOptions{Direction: TraceCallees})
+	if err != nil || len(result.Callees) != 1 || result.Callees[0].Symbol.Name != "Connect" {
+		t.Errorf("unexpected callees %+v (%v)", result, err)
+	}
+
+	if _, err := client.Trace(ctx, "Login", TraceOptions{Direction: "sideways"}); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
@@ -0,0 +1,1256 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: agentdx/v1/agentdx.proto
+
+package agentdxpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Maximum number of results; defaults to 10.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Search code from deleted files kept within the retention period.
+	Deleted bool `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// Only search files matching one of these glob patterns.
+	Include []string `protobuf:"bytes,4,rep,name=include,proto3" json:"include,omitempty"`
+	// Skip files matching any of these glob patterns.
+	Exclude []string `protobuf:"bytes,5,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	// Only search files of these languages or extensions (go, ts, vue).
+	Lang          []string `protobuf:"bytes,6,rep,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *SearchRequest) GetInclude() []string {
+	if x != nil {
+		return x.Include
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLang() []string {
+	if x != nil {
+		return x.Lang
+	}
+	return nil
+}
+
+// SearchResponse is a single search result.
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path relative to the project root.
+	FilePath  string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	StartLine int32                  `protobuf:"varint,2,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`
+	EndLine   int32                  `protobuf:"varint,3,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	Score     float32                `protobuf:"fixed32,4,opt,name=score,proto3" json:"score,omitempty"`
+	Content   string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	Notes     []*Note                `protobuf:"bytes,6,rep,name=notes,proto3" json:"notes,omitempty"`
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// Absolute path on the server's machine.
+	AbsPath       string `protobuf:"bytes,8,opt,name=abs_path,json=absPath,proto3" json:"abs_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchResponse) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetNotes() []*Note {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetAbsPath() string {
+	if x != nil {
+		return x.AbsPath
+	}
+	return ""
+}
+
+type Note struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	StartLine     int32                  `protobuf:"varint,2,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`
+	EndLine       int32                  `protobuf:"varint,3,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	Text          string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Note) Reset() {
+	*x = Note{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Note) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Note) ProtoMessage() {}
+
+func (x *Note) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Note.ProtoReflect.Descriptor instead.
+func (*Note) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Note) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Note) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *Note) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *Note) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type FilesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Glob pattern; patterns without a slash match at any depth.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// Maximum number of files; 0 returns all matches.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilesRequest) Reset() {
+	*x = FilesRequest{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilesRequest) ProtoMessage() {}
+
+func (x *FilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilesRequest.ProtoReflect.Descriptor instead.
+func (*FilesRequest) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FilesRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *FilesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type FilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*File                `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilesResponse) Reset() {
+	*x = FilesResponse{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilesResponse) ProtoMessage() {}
+
+func (x *FilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilesResponse.ProtoReflect.Descriptor instead.
+func (*FilesResponse) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FilesResponse) GetFiles() []*File {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type File struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	ModTime       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"`
+	AbsPath       string                 `protobuf:"bytes,3,opt,name=abs_path,json=absPath,proto3" json:"abs_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *File) Reset() {
+	*x = File{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*File) ProtoMessage() {}
+
+func (x *File) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *File) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *File) GetModTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModTime
+	}
+	return nil
+}
+
+func (x *File) GetAbsPath() string {
+	if x != nil {
+		return x.AbsPath
+	}
+	return ""
+}
+
+type TraceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// callers, callees or graph.
+	Mode   string `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Symbol string `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Call graph depth; defaults to 2.
+	Depth         int32 `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraceRequest) Reset() {
+	*x = TraceRequest{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceRequest) ProtoMessage() {}
+
+func (x *TraceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceRequest.ProtoReflect.Descriptor instead.
+func (*TraceRequest) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TraceRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *TraceRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TraceRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type TraceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Symbol        *Symbol                `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Callers       []*Call                `protobuf:"bytes,4,rep,name=callers,proto3" json:"callers,omitempty"`
+	Callees       []*Call                `protobuf:"bytes,5,rep,name=callees,proto3" json:"callees,omitempty"`
+	Graph         *CallGraph             `protobuf:"bytes,6,opt,name=graph,proto3" json:"graph,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraceResponse) Reset() {
+	*x = TraceResponse{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceResponse) ProtoMessage() {}
+
+func (x *TraceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceResponse.ProtoReflect.Descriptor instead.
+func (*TraceResponse) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TraceResponse) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *TraceResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *TraceResponse) GetSymbol() *Symbol {
+	if x != nil {
+		return x.Symbol
+	}
+	return nil
+}
+
+func (x *TraceResponse) GetCallers() []*Call {
+	if x != nil {
+		return x.Callers
+	}
+	return nil
+}
+
+func (x *TraceResponse) GetCallees() []*Call {
+	if x != nil {
+		return x.Callees
+	}
+	return nil
+}
+
+func (x *TraceResponse) GetGraph() *CallGraph {
+	if x != nil {
+		return x.Graph
+	}
+	return nil
+}
+
+type Symbol struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	File          string                 `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,4,opt,name=line,proto3" json:"line,omitempty"`
+	EndLine       int32                  `protobuf:"varint,5,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	Signature     string                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	Receiver      string                 `protobuf:"bytes,7,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Package       string                 `protobuf:"bytes,8,opt,name=package,proto3" json:"package,omitempty"`
+	Exported      bool                   `protobuf:"varint,9,opt,name=exported,proto3" json:"exported,omitempty"`
+	Language      string                 `protobuf:"bytes,10,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Symbol) Reset() {
+	*x = Symbol{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Symbol) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Symbol) ProtoMessage() {}
+
+func (x *Symbol) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Symbol.ProtoReflect.Descriptor instead.
+func (*Symbol) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Symbol) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Symbol) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Symbol) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Symbol) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Symbol) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *Symbol) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Symbol) GetReceiver() string {
+	if x != nil {
+		return x.Receiver
+	}
+	return ""
+}
+
+func (x *Symbol) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *Symbol) GetExported() bool {
+	if x != nil {
+		return x.Exported
+	}
+	return false
+}
+
+func (x *Symbol) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+// Call is a caller or callee of the traced symbol and where the call happens.
+type Call struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        *Symbol                `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	File          string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Context       string                 `protobuf:"bytes,4,opt,name=context,proto3" json:"context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Call) Reset() {
+	*x = Call{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Call) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Call) ProtoMessage() {}
+
+func (x *Call) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Call.ProtoReflect.Descriptor instead.
+func (*Call) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Call) GetSymbol() *Symbol {
+	if x != nil {
+		return x.Symbol
+	}
+	return nil
+}
+
+func (x *Call) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Call) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Call) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+type CallGraph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          string                 `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Nodes         map[string]*Symbol     `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Edges         []*CallEdge            `protobuf:"bytes,3,rep,name=edges,proto3" json:"edges,omitempty"`
+	Depth         int32                  `protobuf:"varint,4,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallGraph) Reset() {
+	*x = CallGraph{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallGraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallGraph) ProtoMessage() {}
+
+func (x *CallGraph) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallGraph.ProtoReflect.Descriptor instead.
+func (*CallGraph) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CallGraph) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *CallGraph) GetNodes() map[string]*Symbol {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *CallGraph) GetEdges() []*CallEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *CallGraph) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type CallEdge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Caller        string                 `protobuf:"bytes,1,opt,name=caller,proto3" json:"caller,omitempty"`
+	Callee        string                 `protobuf:"bytes,2,opt,name=callee,proto3" json:"callee,omitempty"`
+	File          string                 `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,4,opt,name=line,proto3" json:"line,omitempty"`
+	CallType      string                 `protobuf:"bytes,5,opt,name=call_type,json=callType,proto3" json:"call_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallEdge) Reset() {
+	*x = CallEdge{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallEdge) ProtoMessage() {}
+
+func (x *CallEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallEdge.ProtoReflect.Descriptor instead.
+func (*CallEdge) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CallEdge) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *CallEdge) GetCallee() string {
+	if x != nil {
+		return x.Callee
+	}
+	return ""
+}
+
+func (x *CallEdge) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *CallEdge) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *CallEdge) GetCallType() string {
+	if x != nil {
+		return x.CallType
+	}
+	return ""
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{12}
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalFiles    int32                  `protobuf:"varint,1,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	TotalChunks   int32                  `protobuf:"varint,2,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
+	IndexSize     int64                  `protobuf:"varint,3,opt,name=index_size,json=indexSize,proto3" json:"index_size,omitempty"`
+	LastUpdated   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	Search        string                 `protobuf:"bytes,5,opt,name=search,proto3" json:"search,omitempty"`
+	SymbolsReady  bool                   `protobuf:"varint,6,opt,name=symbols_ready,json=symbolsReady,proto3" json:"symbols_ready,omitempty"`
+	BackendType   string                 `protobuf:"bytes,7,opt,name=backend_type,json=backendType,proto3" json:"backend_type,omitempty"`
+	BackendHost   string                 `protobuf:"bytes,8,opt,name=backend_host,json=backendHost,proto3" json:"backend_host,omitempty"`
+	BackendName   string                 `protobuf:"bytes,9,opt,name=backend_name,json=backendName,proto3" json:"backend_name,omitempty"`
+	BackendOk     bool                   `protobuf:"varint,10,opt,name=backend_ok,json=backendOk,proto3" json:"backend_ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentdx_v1_agentdx_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_agentdx_v1_agentdx_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StatusResponse) GetTotalFiles() int32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetTotalChunks() int32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetIndexSize() int64 {
+	if x != nil {
+		return x.IndexSize
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetSymbolsReady() bool {
+	if x != nil {
+		return x.SymbolsReady
+	}
+	return false
+}
+
+func (x *StatusResponse) GetBackendType() string {
+	if x != nil {
+		return x.BackendType
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetBackendHost() string {
+	if x != nil {
+		return x.BackendHost
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetBackendName() string {
+	if x != nil {
+		return x.BackendName
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetBackendOk() bool {
+	if x != nil {
+		return x.BackendOk
+	}
+	return false
+}
+
+var File_agentdx_v1_agentdx_proto protoreflect.FileDescriptor
+
+const file_agentdx_v1_agentdx_proto_rawDesc = "" +
+	"\n" +
+	"\x18agentdx/v1/agentdx.proto\x12\n" +
+	"agentdx.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9d\x01\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x12\x18\n" +
+	"\ainclude\x18\x04 \x03(\tR\ainclude\x12\x18\n" +
+	"\aexclude\x18\x05 \x03(\tR\aexclude\x12\x12\n" +
+	"\x04lang\x18\x06 \x03(\tR\x04lang\"\x95\x02\n" +
+	"\x0eSearchResponse\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x02 \x01(\x05R\tstartLine\x12\x19\n" +
+	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x02R\x05score\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12&\n" +
+	"\x05notes\x18\x06 \x03(\v2\x10.agentdx.v1.NoteR\x05notes\x129\n" +
+	"\n" +
+	"deleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x19\n" +
+	"\babs_path\x18\b \x01(\tR\aabsPath\"d\n" +
+	"\x04Note\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x02 \x01(\x05R\tstartLine\x12\x19\n" +
+	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x12\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\">\n" +
+	"\fFilesRequest\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"7\n" +
+	"\rFilesResponse\x12&\n" +
+	"\x05files\x18\x01 \x03(\v2\x10.agentdx.v1.FileR\x05files\"l\n" +
+	"\x04File\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x125\n" +
+	"\bmod_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\amodTime\x12\x19\n" +
+	"\babs_path\x18\x03 \x01(\tR\aabsPath\"P\n" +
+	"\fTraceRequest\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\"\xea\x01\n" +
+	"\rTraceResponse\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12*\n" +
+	"\x06symbol\x18\x03 \x01(\v2\x12.agentdx.v1.SymbolR\x06symbol\x12*\n" +
+	"\acallers\x18\x04 \x03(\v2\x10.agentdx.v1.CallR\acallers\x12*\n" +
+	"\acallees\x18\x05 \x03(\v2\x10.agentdx.v1.CallR\acallees\x12+\n" +
+	"\x05graph\x18\x06 \x01(\v2\x15.agentdx.v1.CallGraphR\x05graph\"\xff\x01\n" +
+	"\x06Symbol\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04file\x18\x03 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x04 \x01(\x05R\x04line\x12\x19\n" +
+	"\bend_line\x18\x05 \x01(\x05R\aendLine\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\tR\tsignature\x12\x1a\n" +
+	"\breceiver\x18\a \x01(\tR\breceiver\x12\x18\n" +
+	"\apackage\x18\b \x01(\tR\apackage\x12\x1a\n" +
+	"\bexported\x18\t \x01(\bR\bexported\x12\x1a\n" +
+	"\blanguage\x18\n" +
+	" \x01(\tR\blanguage\"t\n" +
+	"\x04Call\x12*\n" +
+	"\x06symbol\x18\x01 \x01(\v2\x12.agentdx.v1.SymbolR\x06symbol\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x03 \x01(\x05R\x04line\x12\x18\n" +
+	"\acontext\x18\x04 \x01(\tR\acontext\"\xe7\x01\n" +
+	"\tCallGraph\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x126\n" +
+	"\x05nodes\x18\x02 \x03(\v2 .agentdx.v1.CallGraph.NodesEntryR\x05nodes\x12*\n" +
+	"\x05edges\x18\x03 \x03(\v2\x14.agentdx.v1.CallEdgeR\x05edges\x12\x14\n" +
+	"\x05depth\x18\x04 \x01(\x05R\x05depth\x1aL\n" +
+	"\n" +
+	"NodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.agentdx.v1.SymbolR\x05value:\x028\x01\"\x7f\n" +
+	"\bCallEdge\x12\x16\n" +
+	"\x06caller\x18\x01 \x01(\tR\x06caller\x12\x16\n" +
+	"\x06callee\x18\x02 \x01(\tR\x06callee\x12\x12\n" +
+	"\x04file\x18\x03 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x04 \x01(\x05R\x04line\x12\x1b\n" +
+	"\tcall_type\x18\x05 \x01(\tR\bcallType\"\x0f\n" +
+	"\rStatusRequest\"\xf7\x02\n" +
+	"\x0eStatusResponse\x12\x1f\n" +
+	"\vtotal_files\x18\x01 \x01(\x05R\n" +
+	"totalFiles\x12!\n" +
+	"\ftotal_chunks\x18\x02 \x01(\x05R\vtotalChunks\x12\x1d\n" +
+	"\n" +
+	"index_size\x18\x03 \x01(\x03R\tindexSize\x12=\n" +
+	"\flast_updated\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12\x16\n" +
+	"\x06search\x18\x05 \x01(\tR\x06search\x12#\n" +
+	"\rsymbols_ready\x18\x06 \x01(\bR\fsymbolsReady\x12!\n" +
+	"\fbackend_type\x18\a \x01(\tR\vbackendType\x12!\n" +
+	"\fbackend_host\x18\b \x01(\tR\vbackendHost\x12!\n" +
+	"\fbackend_name\x18\t \x01(\tR\vbackendName\x12\x1d\n" +
+	"\n" +
+	"backend_ok\x18\n" +
+	" \x01(\bR\tbackendOk2\x90\x02\n" +
+	"\x0eAgentDXService\x12A\n" +
+	"\x06Search\x12\x19.agentdx.v1.SearchRequest\x1a\x1a.agentdx.v1.SearchResponse0\x01\x12<\n" +
+	"\x05Files\x12\x18.agentdx.v1.FilesRequest\x1a\x19.agentdx.v1.FilesResponse\x12<\n" +
+	"\x05Trace\x12\x18.agentdx.v1.TraceRequest\x1a\x19.agentdx.v1.TraceResponse\x12?\n" +
+	"\x06Status\x12\x19.agentdx.v1.StatusRequest\x1a\x1a.agentdx.v1.StatusResponseB4Z2github.com/doveaia/agentdx/rpc/agentdxpb;agentdxpbb\x06proto3"
+
+var (
+	file_agentdx_v1_agentdx_proto_rawDescOnce sync.Once
+	file_agentdx_v1_agentdx_proto_rawDescData []byte
+)
+
+func file_agentdx_v1_agentdx_proto_rawDescGZIP() []byte {
+	file_agentdx_v1_agentdx_proto_rawDescOnce.Do(func() {
+		file_agentdx_v1_agentdx_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agentdx_v1_agentdx_proto_rawDesc), len(file_agentdx_v1_agentdx_proto_rawDesc)))
+	})
+	return file_agentdx_v1_agentdx_proto_rawDescData
+}
+
+var file_agentdx_v1_agentdx_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_agentdx_v1_agentdx_proto_goTypes = []any{
+	(*SearchRequest)(nil),         // 0: agentdx.v1.SearchRequest
+	(*SearchResponse)(nil),        // 1: agentdx.v1.SearchResponse
+	(*Note)(nil),                  // 2: agentdx.v1.Note
+	(*FilesRequest)(nil),          // 3: agentdx.v1.FilesRequest
+	(*FilesResponse)(nil),         // 4: agentdx.v1.FilesResponse
+	(*File)(nil),                  // 5: agentdx.v1.File
+	(*TraceRequest)(nil),          // 6: agentdx.v1.TraceRequest
+	(*TraceResponse)(nil),         // 7: agentdx.v1.TraceResponse
+	(*Symbol)(nil),                // 8: agentdx.v1.Symbol
+	(*Call)(nil),                  // 9: agentdx.v1.Call
+	(*CallGraph)(nil),             // 10: agentdx.v1.CallGraph
+	(*CallEdge)(nil),              // 11: agentdx.v1.CallEdge
+	(*StatusRequest)(nil),         // 12: agentdx.v1.StatusRequest
+	(*StatusResponse)(nil),        // 13: agentdx.v1.StatusResponse
+	nil,                           // 14: agentdx.v1.CallGraph.NodesEntry
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+}
+var file_agentdx_v1_agentdx_proto_depIdxs = []int32{
+	2,  // 0: agentdx.v1.SearchResponse.notes:type_name -> agentdx.v1.Note
+	15, // 1: agentdx.v1.SearchResponse.deleted_at:type_name -> google.protobuf.Timestamp
+	5,  // 2: agentdx.v1.FilesResponse.files:type_name -> agentdx.v1.File
+	15, // 3: agentdx.v1.File.mod_time:type_name -> google.protobuf.Timestamp
+	8,  // 4: agentdx.v1.TraceResponse.symbol:type_name -> agentdx.v1.Symbol
+	9,  // 5: agentdx.v1.TraceResponse.callers:type_name -> agentdx.v1.Call
+	9,  // 6: agentdx.v1.TraceResponse.callees:type_name -> agentdx.v1.Call
+	10, // 7: agentdx.v1.TraceResponse.graph:type_name -> agentdx.v1.CallGraph
+	8,  // 8: agentdx.v1.Call.symbol:type_name -> agentdx.v1.Symbol
+	14, // 9: agentdx.v1.CallGraph.nodes:type_name -> agentdx.v1.CallGraph.NodesEntry
+	11, // 10: agentdx.v1.CallGraph.edges:type_name -> agentdx.v1.CallEdge
+	15, // 11: agentdx.v1.StatusResponse.last_updated:type_name -> google.protobuf.Timestamp
+	8,  // 12: agentdx.v1.CallGraph.NodesEntry.value:type_name -> agentdx.v1.Symbol
+	0,  // 13: agentdx.v1.AgentDXService.Search:input_type -> agentdx.v1.SearchRequest
+	3,  // 14: agentdx.v1.AgentDXService.Files:input_type -> agentdx.v1.FilesRequest
+	6,  // 15: agentdx.v1.AgentDXService.Trace:input_type -> agentdx.v1.TraceRequest
+	12, // 16: agentdx.v1.AgentDXService.Status:input_type -> agentdx.v1.StatusRequest
+	1,  // 17: agentdx.v1.AgentDXService.Search:output_type -> agentdx.v1.SearchResponse
+	4,  // 18: agentdx.v1.AgentDXService.Files:output_type -> agentdx.v1.FilesResponse
+	7,  // 19: agentdx.v1.AgentDXService.Trace:output_type -> agentdx.v1.TraceResponse
+	13, // 20: agentdx.v1.AgentDXService.Status:output_type -> agentdx.v1.StatusResponse
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_agentdx_v1_agentdx_proto_init() }
+func file_agentdx_v1_agentdx_proto_init() {
+	if File_agentdx_v1_agentdx_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agentdx_v1_agentdx_proto_rawDesc), len(file_agentdx_v1_agentdx_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agentdx_v1_agentdx_proto_goTypes,
+		DependencyIndexes: file_agentdx_v1_agentdx_proto_depIdxs,
+		MessageInfos:      file_agentdx_v1_agentdx_proto_msgTypes,
+	}.Build()
+	File_agentdx_v1_agentdx_proto = out.File
+	file_agentdx_v1_agentdx_proto_goTypes = nil
+	file_agentdx_v1_agentdx_proto_depIdxs = nil
+}
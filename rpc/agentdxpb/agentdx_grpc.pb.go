@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: agentdx/v1/agentdx.proto
+
+package agentdxpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AgentDXService_Search_FullMethodName = "/agentdx.v1.AgentDXService/Search"
+	AgentDXService_Files_FullMethodName  = "/agentdx.v1.AgentDXService/Files"
+	AgentDXService_Trace_FullMethodName  = "/agentdx.v1.AgentDXService/Trace"
+	AgentDXService_Status_FullMethodName = "/agentdx.v1.AgentDXService/Status"
+)
+
+// AgentDXServiceClient is the client API for AgentDXService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AgentDX serves the shared index to programmatic consumers. It mirrors the
+// JSON endpoints of the dashboard API.
+type AgentDXServiceClient interface {
+	// Search streams ranked full text search results, best match first.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResponse], error)
+	// Files lists indexed files matching a glob pattern.
+	Files(ctx context.Context, in *FilesRequest, opts ...grpc.CallOption) (*FilesResponse, error)
+	// Trace returns the callers, callees or call graph of a symbol.
+	Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error)
+	// Status reports index statistics and backend health.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type agentDXServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentDXServiceClient(cc grpc.ClientConnInterface) AgentDXServiceClient {
+	return &agentDXServiceClient{cc}
+}
+
+func (c *agentDXServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentDXService_ServiceDesc.Streams[0], AgentDXService_Search_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, SearchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentDXService_SearchClient = grpc.ServerStreamingClient[SearchResponse]
+
+func (c *agentDXServiceClient) Files(ctx context.Context, in *FilesRequest, opts ...grpc.CallOption) (*FilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FilesResponse)
+	err := c.cc.Invoke(ctx, AgentDXService_Files_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentDXServiceClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (*TraceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraceResponse)
+	err := c.cc.Invoke(ctx, AgentDXService_Trace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentDXServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, AgentDXService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentDXServiceServer is the server API for AgentDXService service.
+// All implementations must embed UnimplementedAgentDXServiceServer
+// for forward compatibility.
+//
+// AgentDX serves the shared index to programmatic consumers. It mirrors the
+// JSON endpoints of the dashboard API.
+type AgentDXServiceServer interface {
+	// Search streams ranked full text search results, best match first.
+	Search(*SearchRequest, grpc.ServerStreamingServer[SearchResponse]) error
+	// Files lists indexed files matching a glob pattern.
+	Files(context.Context, *FilesRequest) (*FilesResponse, error)
+	// Trace returns the callers, callees or call graph of a symbol.
+	Trace(context.Context, *TraceRequest) (*TraceResponse, error)
+	// Status reports index statistics and backend health.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	mustEmbedUnimplementedAgentDXServiceServer()
+}
+
+// UnimplementedAgentDXServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentDXServiceServer struct{}
+
+func (UnimplementedAgentDXServiceServer) Search(*SearchRequest, grpc.ServerStreamingServer[SearchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedAgentDXServiceServer) Files(context.Context, *FilesRequest) (*FilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Files not implemented")
+}
+func (UnimplementedAgentDXServiceServer) Trace(context.Context, *TraceRequest) (*TraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Trace not implemented")
+}
+func (UnimplementedAgentDXServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedAgentDXServiceServer) mustEmbedUnimplementedAgentDXServiceServer() {}
+func (UnimplementedAgentDXServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeAgentDXServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentDXServiceServer will
+// result in compilation errors.
+type UnsafeAgentDXServiceServer interface {
+	mustEmbedUnimplementedAgentDXServiceServer()
+}
+
+func RegisterAgentDXServiceServer(s grpc.ServiceRegistrar, srv AgentDXServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAgentDXServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AgentDXService_ServiceDesc, srv)
+}
+
+func _AgentDXService_Search_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentDXServiceServer).Search(m, &grpc.GenericServerStream[SearchRequest, SearchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentDXService_SearchServer = grpc.ServerStreamingServer[SearchResponse]
+
+func _AgentDXService_Files_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentDXServiceServer).Files(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentDXService_Files_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentDXServiceServer).Files(ctx, req.(*FilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentDXService_Trace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentDXServiceServer).Trace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentDXService_Trace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentDXServiceServer).Trace(ctx, req.(*TraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentDXService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentDXServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentDXService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentDXServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentDXService_ServiceDesc is the grpc.ServiceDesc for AgentDXService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentDXService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentdx.v1.AgentDXService",
+	HandlerType: (*AgentDXServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Files",
+			Handler:    _AgentDXService_Files_Handler,
+		},
+		{
+			MethodName: "Trace",
+			Handler:    _AgentDXService_Trace_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _AgentDXService_Status_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Search",
+			Handler:       _AgentDXService_Search_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "agentdx/v1/agentdx.proto",
+}
@@ -0,0 +1,111 @@
+// Package rpc serves the index over gRPC for programmatic consumers.
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/internal/netutil"
+	"github.com/doveaia/agentdx/rpc/agentdxpb"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"google.golang.org/grpc"
+)
+
+// Server is the gRPC API server.
+type Server struct {
+	agentdxpb.UnimplementedAgentDXServiceServer
+
+	config      *config.Config
+	projectRoot string
+	store       store.SearchStore
+	symbolStore trace.SymbolStore
+	searches    *search.Flight[*search.Response] // dedupes identical concurrent searches
+	grpcServer  *grpc.Server
+	listener    net.Listener
+	mu          sync.Mutex
+}
+
+// NewServer creates a new gRPC API server.
+func NewServer(cfg *config.Config, projectRoot string, st store.SearchStore, symbolStore trace.SymbolStore) *Server {
+	return &Server{
+		config:      cfg,
+		projectRoot: projectRoot,
+		store:       st,
+		symbolStore: symbolStore,
+		searches:    search.NewFlight[*search.Response]("gRPC search"),
+	}
+}
+
+// Start starts serving on the configured address.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.grpcServer != nil {
+		return nil
+	}
+
+	token := s.config.GRPC.AuthToken()
+	if token == "" && !netutil.IsLoopback(s.config.GRPC.Host) {
+		return fmt.Errorf("a token is required to serve gRPC on %s (set grpc.token or %s)", s.config.GRPC.Host, config.GRPCTokenEnv)
+	}
+
+	ln, err := net.Listen("tcp", s.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to bind to %s: %w", s.Addr(), err)
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAuth(token)),
+		grpc.StreamInterceptor(streamAuth(token)),
+	)
+	agentdxpb.RegisterAgentDXServiceServer(s.grpcServer, s)
+	s.listener = ln
+
+	go func(srv *grpc.Server) {
+		if err := srv.Serve(ln); err != nil {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}(s.grpcServer)
+
+	return nil
+}
+
+// Stop stops the server, waiting for in-flight calls until ctx is done.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.grpcServer == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+
+	s.grpcServer = nil
+	s.listener = nil
+	return nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return net.JoinHostPort(s.config.GRPC.Host, fmt.Sprint(s.config.GRPC.Port))
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/rpc/agentdxpb"
+	"github.com/doveaia/agentdx/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func startTestServer(t *testing.T, token string) *Server {
+	t.Helper()
+	ctx := context.Background()
+
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), "/project")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	now := time.Now()
+	chunks := []store.Chunk{
+		{ID: "auth.go_0", FilePath: "auth/auth.go", StartLine: 1, EndLine: 5, Content: "func Login(user string) error {}", Hash: "a", UpdatedAt: now},
+		{ID: "db.go_0", FilePath: "db/db.go", StartLine: 1, EndLine: 5, Content: "func Connect() error {}", Hash: "b", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, path := range []string{"auth/auth.go", "db/db.go"} {
+		if err := st.SaveDocument(ctx, store.Document{Path: path, Hash: path, ModTime: now}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Index.Store.Backend = store.BackendSQLite
+	cfg.GRPC.Port = 0
+	cfg.GRPC.Token = token
+
+	srv := NewServer(cfg, "/project", st, nil)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { srv.Stop(context.Background()) })
+	return srv
+}
+
+func dial(t *testing.T, srv *Server, opts ...grpc.DialOption) agentdxpb.AgentDXServiceClient {
+	t.Helper()
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(srv.Addr(), opts...)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return agentdxpb.NewAgentDXServiceClient(conn)
+}
+
+func TestServer_Search(t *testing.T) {
+	srv := startTestServer(t, "")
+	client := dial(t, srv)
+
+	stream, err := client.Search(context.Background(), &agentdxpb.SearchRequest{Query: "login"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	var results []*agentdxpb.SearchResponse
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != 1 || results[0].GetFilePath() != "auth/auth.go" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	// Errors of server streams surface on the first Recv
+	stream, err = client.Search(context.Background(), &agentdxpb.SearchRequest{})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err = stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for empty query, got %v", err)
+	}
+}
+
+func TestServer_SearchWarnings(t *testing.T) {
+	reranker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer reranker.Close()
+	srv := startTestServer(t, "")
+	srv.config.Index.Search.Rerank = config.RerankConfig{Enabled: true, Endpoint: reranker.URL, Candidates: 10, TimeoutMs: 1000}
+	client := dial(t, srv)
+
+	stream, err := client.Search(context.Background(), &agentdxpb.SearchRequest{Query: "error"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	n := 0
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		n++
+	}
+	warnings := stream.Trailer().Get(WarningTrailer)
+	if n != 2 || len(warnings) != 1 || !strings.Contains(warnings[0], "reranker failed") {
+		t.Errorf("expected 2 results and the reranker warning, got %d and %v", n, warnings)
+	}
+}
+
+func TestServer_FilesAndStatus(t *testing.T) {
+	srv := startTestServer(t, "")
+	client := dial(t, srv)
+	ctx := context.Background()
+
+	files, err := client.Files(ctx, &agentdxpb.FilesRequest{Pattern: "*.go", Limit: 1})
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+	if len(files.GetFiles()) != 1 || files.GetFiles()[0].GetPath() != "auth/auth.go" {
+		t.Errorf("unexpected files: %v", files.GetFiles())
+	}
+
+	st, err := client.Status(ctx, &agentdxpb.StatusRequest{})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if st.GetTotalFiles() != 2 || st.GetTotalChunks() != 2 {
+		t.Errorf("unexpected status: %v", st)
+	}
+
+	_, err = client.Trace(ctx, &agentdxpb.TraceRequest{Mode: "up", Symbol: "Login"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unknown mode, got %v", err)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := startTestServer(t, "secret")
+	ctx := context.Background()
+
+	_, err := dial(t, srv).Status(ctx, &agentdxpb.StatusRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without token, got %v", err)
+	}
+
+	_, err = dial(t, srv, grpc.WithPerRPCCredentials(TokenCredentials("wrong"))).Status(ctx, &agentdxpb.StatusRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated with a wrong token, got %v", err)
+	}
+
+	client := dial(t, srv, grpc.WithPerRPCCredentials(TokenCredentials("secret")))
+	if _, err := client.Status(ctx, &agentdxpb.StatusRequest{}); err != nil {
+		t.Errorf("expected Status to succeed with the token, got %v", err)
+	}
+	stream, err := client.Search(ctx, &agentdxpb.SearchRequest{Query: "connect"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("expected a streamed result with the token, got %v", err)
+	}
+}
+
+func TestServer_RequiresTokenOffLoopback(t *testing.T) {
+	t.Setenv(config.GRPCTokenEnv, "")
+	cfg := config.DefaultConfig()
+	cfg.GRPC.Host = "0.0.0.0"
+	if err := NewServer(cfg, "/project", nil, nil).Start(context.Background()); err == nil {
+		t.Error("expected Start to refuse a public address without a token")
+	}
+}
@@ -0,0 +1,305 @@
+package rpc
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/doveaia/agentdx/rpc/agentdxpb"
+	"github.com/doveaia/agentdx/search"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultSearchLimit = 10
+	defaultGraphDepth  = 2
+)
+
+// WarningTrailer is the trailer key holding the failures a search did
+// without, such as the reranker, one value each.
+const WarningTrailer = "agentdx-warning"
+
+// Search streams ranked search results.
+func (s *Server) Search(req *agentdxpb.SearchRequest, stream agentdxpb.AgentDXService_SearchServer) error {
+	if req.GetQuery() == "" {
+		return status.Error(codes.InvalidArgument, "query is required")
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	// Identical concurrent searches run once; the request is the key
+	key, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	resp, err := s.searches.Do(stream.Context(), string(key), func(ctx context.Context) (*search.Response, error) {
+		return s.search(ctx, req, limit)
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.FromContextError(err).Err()
+	}
+
+	if warnings := resp.WarningTexts(); len(warnings) > 0 {
+		stream.SetTrailer(metadata.MD{WarningTrailer: warnings})
+	}
+	for _, r := range resp.Results {
+		if err := stream.Send(s.toSearchResponse(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search runs a search request and ranks its results.
+func (s *Server) search(ctx context.Context, req *agentdxpb.SearchRequest, limit int) (*search.Response, error) {
+	filter := store.SearchFilter{
+		Include: req.GetInclude(),
+		Exclude: req.GetExclude(),
+		Langs:   req.GetLang(),
+		Deleted: req.GetDeleted(),
+	}
+	resp, err := search.Run(ctx, s.store, s.config.Index.Search, search.Request{
+		Query:       req.GetQuery(),
+		Filter:      filter,
+		Limit:       limit,
+		ProjectRoot: s.projectRoot,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "search failed: %v", err)
+	}
+	return resp, nil
+}
+
+// Files lists indexed files matching a glob pattern.
+func (s *Server) Files(ctx context.Context, req *agentdxpb.FilesRequest) (*agentdxpb.FilesResponse, error) {
+	if req.GetPattern() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pattern is required")
+	}
+
+	allFiles, err := s.store.ListFilesWithStats(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list files: %v", err)
+	}
+
+	pattern := normalizeGlobPattern(req.GetPattern())
+	var files []*agentdxpb.File
+	for _, f := range allFiles {
+		ok, err := doublestar.Match(pattern, f.Path)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid pattern: %v", err)
+		}
+		if ok {
+			files = append(files, &agentdxpb.File{Path: f.Path, AbsPath: s.absPath(f.Path), ModTime: timestamppb.New(f.ModTime)})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	if limit := int(req.GetLimit()); limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+
+	return &agentdxpb.FilesResponse{Files: files}, nil
+}
+
+// Trace returns the callers, callees or call graph of a symbol.
+func (s *Server) Trace(ctx context.Context, req *agentdxpb.TraceRequest) (*agentdxpb.TraceResponse, error) {
+	if req.GetSymbol() == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	mode := req.GetMode()
+	switch mode {
+	case "callers", "callees", "graph":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown trace mode %q (use callers, callees or graph)", mode)
+	}
+
+	resp := &agentdxpb.TraceResponse{Query: req.GetSymbol(), Mode: mode}
+	if s.symbolStore == nil {
+		return resp, nil
+	}
+
+	symbols, err := s.symbolStore.LookupSymbol(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to lookup symbol: %v", err)
+	}
+	if len(symbols) > 0 {
+		resp.Symbol = toSymbol(symbols[0])
+	}
+
+	switch mode {
+	case "callers":
+		refs, err := s.symbolStore.LookupCallers(ctx, req.GetSymbol())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to lookup callers: %v", err)
+		}
+		for _, ref := range refs {
+			caller := trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+			if syms, _ := s.symbolStore.LookupSymbol(ctx, ref.CallerName); len(syms) > 0 {
+				caller = syms[0]
+			}
+			resp.Callers = append(resp.Callers, toCall(caller, ref))
+		}
+
+	case "callees":
+		if len(symbols) == 0 {
+			break
+		}
+		refs, err := s.symbolStore.LookupCallees(ctx, req.GetSymbol(), symbols[0].File)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to lookup callees: %v", err)
+		}
+		for _, ref := range refs {
+			callee := trace.Symbol{Name: ref.SymbolName}
+			if syms, _ := s.symbolStore.LookupSymbol(ctx, ref.SymbolName); len(syms) > 0 {
+				callee = syms[0]
+			}
+			resp.Callees = append(resp.Callees, toCall(callee, ref))
+		}
+
+	case "graph":
+		depth := int(req.GetDepth())
+		if depth <= 0 {
+			depth = defaultGraphDepth
+		}
+		graph, err := s.symbolStore.GetCallGraph(ctx, req.GetSymbol(), depth)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to build call graph: %v", err)
+		}
+		resp.Graph = toCallGraph(graph)
+	}
+
+	return resp, nil
+}
+
+// Status reports index statistics and backend health.
+func (s *Server) Status(ctx context.Context, req *agentdxpb.StatusRequest) (*agentdxpb.StatusResponse, error) {
+	resp := &agentdxpb.StatusResponse{
+		Search: store.SearchName(s.config.Index.Store.Backend),
+	}
+
+	stats, err := s.store.GetStats(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get stats: %v", err)
+	}
+	resp.TotalFiles = int32(stats.TotalFiles)
+	resp.TotalChunks = int32(stats.TotalChunks)
+	resp.IndexSize = stats.IndexSize
+	if !stats.LastUpdated.IsZero() {
+		resp.LastUpdated = timestamppb.New(stats.LastUpdated)
+	}
+
+	if bs := s.store.BackendStatus(ctx); bs != nil {
+		resp.BackendType = bs.Type
+		resp.BackendHost = bs.Host
+		resp.BackendName = bs.Name
+		resp.BackendOk = bs.Healthy
+	}
+
+	if s.symbolStore != nil {
+		if symbolStats, err := s.symbolStore.GetStats(ctx); err == nil && symbolStats.TotalSymbols > 0 {
+			resp.SymbolsReady = true
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *Server) toSearchResponse(r store.SearchResult) *agentdxpb.SearchResponse {
+	resp := &agentdxpb.SearchResponse{
+		FilePath:  r.Chunk.FilePath,
+		AbsPath:   s.absPath(r.Chunk.FilePath),
+		StartLine: int32(r.Chunk.StartLine),
+		EndLine:   int32(r.Chunk.EndLine),
+		Score:     r.Score,
+		Content:   r.Chunk.Content,
+	}
+	for _, n := range r.Notes {
+		resp.Notes = append(resp.Notes, &agentdxpb.Note{
+			Id:        n.ID,
+			StartLine: int32(n.StartLine),
+			EndLine:   int32(n.EndLine),
+			Text:      n.Text,
+		})
+	}
+	if r.Chunk.DeletedAt != nil {
+		resp.DeletedAt = timestamppb.New(*r.Chunk.DeletedAt)
+	}
+	return resp
+}
+
+func toSymbol(sym trace.Symbol) *agentdxpb.Symbol {
+	return &agentdxpb.Symbol{
+		Name:      sym.Name,
+		Kind:      string(sym.Kind),
+		File:      sym.File,
+		Line:      int32(sym.Line),
+		EndLine:   int32(sym.EndLine),
+		Signature: sym.Signature,
+		Receiver:  sym.Receiver,
+		Package:   sym.Package,
+		Exported:  sym.Exported,
+		Language:  sym.Language,
+	}
+}
+
+func toCall(sym trace.Symbol, ref trace.Reference) *agentdxpb.Call {
+	return &agentdxpb.Call{
+		Symbol:  toSymbol(sym),
+		File:    ref.File,
+		Line:    int32(ref.Line),
+		Context: ref.Context,
+	}
+}
+
+func toCallGraph(graph *trace.CallGraph) *agentdxpb.CallGraph {
+	if graph == nil {
+		return nil
+	}
+	out := &agentdxpb.CallGraph{
+		Root:  graph.Root,
+		Nodes: make(map[string]*agentdxpb.Symbol, len(graph.Nodes)),
+		Depth: int32(graph.Depth),
+	}
+	for name, sym := range graph.Nodes {
+		out.Nodes[name] = toSymbol(sym)
+	}
+	for _, e := range graph.Edges {
+		out.Edges = append(out.Edges, &agentdxpb.CallEdge{
+			Caller:   e.Caller,
+			Callee:   e.Callee,
+			File:     e.File,
+			Line:     int32(e.Line),
+			CallType: e.CallType,
+		})
+	}
+	return out
+}
+
+// normalizeGlobPattern makes patterns without path separators recursive by default.
+func normalizeGlobPattern(pattern string) string {
+	if strings.Contains(pattern, "/") || strings.Contains(pattern, "**") {
+		return pattern
+	}
+	return "**/" + pattern
+}
+
+// absPath returns the absolute path of a file indexed relative to the
+// project root.
+func (s *Server) absPath(path string) string {
+	return filepath.Join(s.projectRoot, filepath.FromSlash(path))
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// Batch holds the results of one query of a batch search.
+type Batch struct {
+	Query      string
+	Results    []store.SearchResult
+	Confidence string // of the query's own results, before deduplication
+}
+
+// BatchQueries trims the queries of a batch search, dropping blank and
+// repeated ones while keeping their order.
+func BatchQueries(queries []string) []string {
+	seen := make(map[string]bool, len(queries))
+	var out []string
+	for _, q := range queries {
+		q = strings.TrimSpace(q)
+		if q == "" || seen[q] {
+			continue
+		}
+		seen[q] = true
+		out = append(out, q)
+	}
+	return out
+}
+
+// DedupeBatches drops results overlapping a result of an earlier query, so
+// each code region is returned once, under the first query that found it.
+func DedupeBatches(batches []Batch) {
+	seen := make(map[string][]store.Chunk)
+	for i, b := range batches {
+		kept := b.Results[:0:0]
+		for _, r := range b.Results {
+			if overlapsAny(seen[r.Chunk.FilePath], r.Chunk) {
+				continue
+			}
+			kept = append(kept, r)
+		}
+		for _, r := range kept {
+			seen[r.Chunk.FilePath] = append(seen[r.Chunk.FilePath], r.Chunk)
+		}
+		batches[i].Results = kept
+	}
+}
+
+// FlattenBatches returns the results of all batches, in order.
+func FlattenBatches(batches []Batch) []store.SearchResult {
+	var out []store.SearchResult
+	for _, b := range batches {
+		out = append(out, b.Results...)
+	}
+	return out
+}
+
+// overlapsAny reports whether c shares lines with any of chunks.
+func overlapsAny(chunks []store.Chunk, c store.Chunk) bool {
+	for _, other := range chunks {
+		if c.StartLine <= other.EndLine && other.StartLine <= c.EndLine {
+			return true
+		}
+	}
+	return false
+}
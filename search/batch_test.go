@@ -0,0 +1,43 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestBatchQueries(t *testing.T) {
+	got := BatchQueries([]string{" user", "auth", "", "user", "login "})
+	want := []string{"user", "auth", "login"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BatchQueries() = %v, want %v", got, want)
+	}
+}
+
+func TestDedupeBatches(t *testing.T) {
+	result := func(file string, start, end int) store.SearchResult {
+		return store.SearchResult{Chunk: store.Chunk{FilePath: file, StartLine: start, EndLine: end}}
+	}
+	batches := []Batch{
+		{Query: "user", Results: []store.SearchResult{result("user.go", 1, 20), result("auth.go", 10, 30)}},
+		{Query: "auth", Results: []store.SearchResult{result("auth.go", 25, 40), result("auth.go", 31, 50), result("user.go", 21, 30)}},
+		{Query: "login", Results: []store.SearchResult{result("user.go", 5, 8)}},
+	}
+
+	DedupeBatches(batches)
+
+	want := [][]store.SearchResult{
+		{result("user.go", 1, 20), result("auth.go", 10, 30)},
+		{result("auth.go", 31, 50), result("user.go", 21, 30)},
+		nil,
+	}
+	for i, b := range batches {
+		if len(b.Results) != len(want[i]) || (len(want[i]) > 0 && !reflect.DeepEqual(b.Results, want[i])) {
+			t.Errorf("batch %q = %v, want %v", b.Query, b.Results, want[i])
+		}
+	}
+	if got := FlattenBatches(batches); len(got) != 4 {
+		t.Errorf("FlattenBatches() returned %d results, want 4", len(got))
+	}
+}
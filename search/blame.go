@@ -0,0 +1,220 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+// uncommittedSHA is the commit git blame reports for lines changed in the
+// working tree.
+const uncommittedSHA = "0000000000000000000000000000000000000000"
+
+const (
+	// blameConcurrency is the number of git blame processes run at once.
+	blameConcurrency = 4
+	// blameTimeout bounds the time AddBlame spends blaming; results whose
+	// file was not blamed in time get no blame.
+	blameTimeout = 10 * time.Second
+)
+
+// AddBlame sets the last commit that changed the matched lines of each
+// result, read with git blame from the working tree of the repository at
+// projectRoot. Each file is blamed once for all its results. The matched
+// lines are those with highlights, or the whole chunk without. Results from
+// deleted or untracked files, or whose lines no longer exist, get no blame,
+// nor do results from notebooks, whose lines are not those of the file. It
+// fails when projectRoot is not in a git repository.
+//
+// Blame runs the git binary, which must be on PATH, like the rest of
+// agentdx's git support (git project IDs, index --changed-since). go-git is
+// not a dependency, and its blame does not see uncommitted changes in the
+// working tree.
+func AddBlame(ctx context.Context, projectRoot string, results []store.SearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("failed to blame results: git is not on PATH")
+	}
+	if err := exec.CommandContext(ctx, "git", "-C", projectRoot, "rev-parse", "--git-dir").Run(); err != nil {
+		return fmt.Errorf("failed to blame results: %s is not in a git repository", projectRoot)
+	}
+
+	byFile := make(map[string][]int)
+	var files []string
+	for i := range results {
+		results[i].Blame = nil
+		chunk := results[i].Chunk
+		if chunk.DeletedAt != nil || indexer.IsNotebook(chunk.FilePath) {
+			continue
+		}
+		if _, ok := byFile[chunk.FilePath]; !ok {
+			files = append(files, chunk.FilePath)
+		}
+		byFile[chunk.FilePath] = append(byFile[chunk.FilePath], i)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, blameTimeout)
+	defer cancel()
+	slots := make(chan struct{}, blameConcurrency)
+	var wg sync.WaitGroup
+	for _, path := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			lines, err := gitBlame(ctx, projectRoot, path)
+			if err != nil {
+				return // Untracked file, or out of time
+			}
+			// Each result of the file is only written by this goroutine
+			for _, i := range byFile[path] {
+				start, end := matchedLines(results[i])
+				results[i].Blame = newestBlame(lines, start, end)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// matchedLines returns the first and last line of result with a highlight,
+// or the line range of its chunk when it has none.
+func matchedLines(result store.SearchResult) (int, int) {
+	start, end := 0, 0
+	for _, h := range result.Highlights {
+		if h.Line < result.Chunk.StartLine || h.Line > result.Chunk.EndLine {
+			continue
+		}
+		if start == 0 || h.Line < start {
+			start = h.Line
+		}
+		end = max(end, h.Line)
+	}
+	if start == 0 {
+		return result.Chunk.StartLine, result.Chunk.EndLine
+	}
+	return start, end
+}
+
+// gitBlame returns the commit of each line of the file at path, relative to
+// projectRoot, by line number.
+func gitBlame(ctx context.Context, projectRoot, path string) (map[int]*store.Blame, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", projectRoot, "blame", "--porcelain", "--", filepath.FromSlash(path))
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git blame %s: %s", path, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git blame %s: %w", path, err)
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+// parseBlamePorcelain returns the commit of each line described by git
+// blame --porcelain, by line number in the blamed file. Lines of the same
+// commit share its Blame.
+func parseBlamePorcelain(out []byte) map[int]*store.Blame {
+	commits := make(map[string]*store.Blame)
+	lines := make(map[int]*store.Blame)
+	var current *store.Blame
+	line := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "\t") {
+			// The content of a line ends its entry
+			if current != nil && line > 0 {
+				lines[line] = current
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(text, " ")
+		// Entries start with the 40-character SHA of their commit, followed
+		// by the line number in the commit and in the file
+		if len(key) == len(uncommittedSHA) && isHex(key) {
+			if current = commits[key]; current == nil {
+				current = &store.Blame{Commit: key}
+				commits[key] = current
+			}
+			line = 0
+			if fields := strings.Fields(value); len(fields) >= 2 {
+				line, _ = strconv.Atoi(fields[1])
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Date = time.Unix(secs, 0).UTC()
+			}
+		case "summary":
+			current.Subject = value
+		}
+	}
+	return lines
+}
+
+// newestBlame returns the newest commit of lines start to end, with
+// Uncommitted set when some of them have changes that are not committed.
+// It returns nil when the file has none of the lines.
+func newestBlame(lines map[int]*store.Blame, start, end int) *store.Blame {
+	var newest *store.Blame
+	found, uncommitted := false, false
+	for line := start; line <= end; line++ {
+		commit := lines[line]
+		if commit == nil {
+			continue
+		}
+		found = true
+		if commit.Commit == uncommittedSHA {
+			uncommitted = true
+		} else if newest == nil || commit.Date.After(newest.Date) {
+			newest = commit
+		}
+	}
+	if !found {
+		return nil
+	}
+	blame := &store.Blame{Uncommitted: uncommitted}
+	if newest != nil {
+		blame.Commit, blame.Author, blame.Date, blame.Subject = newest.Commit, newest.Author, newest.Date, newest.Subject
+	}
+	return blame
+}
+
+// isHex reports whether s only has lowercase hexadecimal digits.
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestAddBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	root := t.TempDir()
+	git := func(author, date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=" + author, "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("alice", "2024-01-02T10:00:00Z", "init", "-q")
+	write("auth.go", "package auth\n\nfunc Login() {}\n\nfunc Logout() {}\n")
+	git("alice", "2024-01-02T10:00:00Z", "add", ".")
+	git("alice", "2024-01-02T10:00:00Z", "commit", "-q", "-m", "Add auth\n\nWith a body")
+	write("auth.go", "package auth\n\nfunc Login() {}\n\nfunc Logout(force bool) {}\n")
+	git("bob", "2025-03-04T10:00:00Z", "commit", "-q", "-am", "Force logout")
+	write("untracked.go", "package auth\n")
+
+	results := []store.SearchResult{
+		// Matched line 3 only: the first commit
+		{Chunk: store.Chunk{FilePath: "auth.go", StartLine: 1, EndLine: 5}, Highlights: []store.Highlight{{Line: 3, StartCol: 6, EndCol: 11}}},
+		// The whole chunk: the newest commit
+		{Chunk: store.Chunk{FilePath: "auth.go", StartLine: 1, EndLine: 5}},
+		{Chunk: store.Chunk{FilePath: "untracked.go", StartLine: 1, EndLine: 1}},
+		{Chunk: store.Chunk{FilePath: "auth.go", StartLine: 40, EndLine: 50}},
+	}
+	if err := AddBlame(ctx, root, results); err != nil {
+		t.Fatalf("AddBlame failed: %v", err)
+	}
+
+	if b := results[0].Blame; b == nil || b.Author != "alice" || b.Subject != "Add auth" || b.Date.Year() != 2024 {
+		t.Errorf("expected alice's commit for the matched line, got %+v", b)
+	}
+	if b := results[1].Blame; b == nil || b.Author != "bob" || b.Subject != "Force logout" || len(b.Commit) != 40 {
+		t.Errorf("expected bob's commit for the chunk, got %+v", b)
+	}
+	if results[2].Blame != nil || results[3].Blame != nil {
+		t.Errorf("expected no blame for untracked files or missing lines, got %+v and %+v", results[2].Blame, results[3].Blame)
+	}
+
+	// Uncommitted edits are reported
+	write("auth.go", "package auth\n\nfunc Login(user string) {}\n\nfunc Logout(force bool) {}\n")
+	if err := AddBlame(ctx, root, results[:2]); err != nil {
+		t.Fatalf("AddBlame failed: %v", err)
+	}
+	if b := results[0].Blame; b == nil || !b.Uncommitted || b.Commit != "" {
+		t.Errorf("expected only uncommitted changes for the edited line, got %+v", b)
+	}
+	if b := results[1].Blame; b == nil || !b.Uncommitted || b.Author != "bob" {
+		t.Errorf("expected bob's commit and uncommitted changes, got %+v", b)
+	}
+
+	if err := AddBlame(ctx, t.TempDir(), results); err == nil {
+		t.Error("expected an error outside a git repository")
+	}
+}
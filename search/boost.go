@@ -1,46 +1,55 @@
 package search
 
 import (
-	"sort"
 	"strings"
+	"time"
 
 	"github.com/doveaia/agentdx/config"
 	"github.com/doveaia/agentdx/store"
 )
 
-// ApplyBoost applies structural boosting to search results based on file path patterns.
-// Penalties reduce scores (factor < 1), bonuses increase scores (factor > 1).
+// ApplyBoost applies structural boosting to search results based on file path
+// patterns, chunk kinds and file recency. Penalties reduce scores
+// (factor < 1), bonuses increase scores (factor > 1).
 // Results are re-sorted by adjusted score after boosting.
 func ApplyBoost(results []store.SearchResult, boostCfg config.BoostConfig) []store.SearchResult {
 	if !boostCfg.Enabled || len(results) == 0 {
 		return results
 	}
 
+	now := time.Now()
 	for i := range results {
-		boost := computeBoostFactor(results[i].Chunk.FilePath, boostCfg)
+		boost := computeBoostFactor(results[i], boostCfg, now)
 		results[i].Score *= boost
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	SortResults(results, false)
 
 	return results
 }
 
-// computeBoostFactor calculates the combined boost factor for a file path.
+// computeBoostFactor calculates the combined boost factor for a result.
 // Multiple matching rules are multiplied together.
-func computeBoostFactor(filePath string, boostCfg config.BoostConfig) float32 {
+func computeBoostFactor(result store.SearchResult, boostCfg config.BoostConfig, now time.Time) float32 {
 	factor := float32(1.0)
 
+	// The kind is only derived when a rule needs it
+	var kind string
+	chunkKind := func() string {
+		if kind == "" {
+			kind = ChunkKind(result.Chunk.Content)
+		}
+		return kind
+	}
+
 	for _, rule := range boostCfg.Penalties {
-		if matchesPattern(filePath, rule.Pattern) {
+		if ruleMatches(rule, result, chunkKind, now) {
 			factor *= rule.Factor
 		}
 	}
 
 	for _, rule := range boostCfg.Bonuses {
-		if matchesPattern(filePath, rule.Pattern) {
+		if ruleMatches(rule, result, chunkKind, now) {
 			factor *= rule.Factor
 		}
 	}
@@ -48,6 +57,24 @@ func computeBoostFactor(filePath string, boostCfg config.BoostConfig) float32 {
 	return factor
 }
 
+// ruleMatches reports whether result meets every condition rule sets.
+// Recency rules never match results whose file mod time is unknown.
+func ruleMatches(rule config.BoostRule, result store.SearchResult, chunkKind func() string, now time.Time) bool {
+	if !matchesPattern(result.Chunk.FilePath, rule.Pattern) {
+		return false
+	}
+	if rule.Kind != "" && chunkKind() != rule.Kind {
+		return false
+	}
+	if rule.ModifiedWithin != "" {
+		age, err := rule.Age()
+		if err != nil || result.ModTime.IsZero() || now.Sub(result.ModTime) > age {
+			return false
+		}
+	}
+	return true
+}
+
 // matchesPattern checks if a file path contains the given pattern.
 // Patterns are simple substring matches (case-sensitive).
 func matchesPattern(filePath, pattern string) bool {
@@ -2,6 +2,7 @@ package search
 
 import (
 	"testing"
+	"time"
 
 	"github.com/doveaia/agentdx/config"
 	"github.com/doveaia/agentdx/store"
@@ -133,10 +134,42 @@ func TestComputeBoostFactor(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			factor := computeBoostFactor(tt.path, boostCfg)
+			factor := computeBoostFactor(store.SearchResult{Chunk: store.Chunk{FilePath: tt.path}}, boostCfg, time.Now())
 			if factor != tt.expected {
 				t.Errorf("computeBoostFactor(%s) = %f, want %f", tt.path, factor, tt.expected)
 			}
 		})
 	}
 }
+
+func TestApplyBoost_KindAndRecency(t *testing.T) {
+	now := time.Now()
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "auth/doc.go", Content: "// Login flow overview\n// and more\n"}, Score: 1.0, ModTime: now},
+		{Chunk: store.Chunk{FilePath: "auth/old.go", Content: "func Login() {}\n"}, Score: 0.9, ModTime: now.AddDate(-1, 0, 0)},
+		{Chunk: store.Chunk{FilePath: "auth/new.go", Content: "func Logout() {}\n"}, Score: 0.8, ModTime: now.Add(-time.Hour)},
+		{Chunk: store.Chunk{FilePath: "auth/unknown.go", Content: "func Check() {}\n"}, Score: 0.7},
+	}
+
+	boostCfg := config.BoostConfig{
+		Enabled:   true,
+		Penalties: []config.BoostRule{{Kind: config.KindComment, Factor: 0.5}},
+		Bonuses:   []config.BoostRule{{ModifiedWithin: "30d", Factor: 1.5}},
+	}
+	boosted := ApplyBoost(results, boostCfg)
+
+	want := map[string]float32{
+		"auth/new.go":     1.2,  // recent: 0.8 * 1.5
+		"auth/old.go":     0.9,  // too old for the bonus
+		"auth/doc.go":     0.75, // comment penalty, recency bonus: 1.0 * 0.5 * 1.5
+		"auth/unknown.go": 0.7,  // unknown mod time
+	}
+	for _, r := range boosted {
+		if diff := r.Score - want[r.Chunk.FilePath]; diff > 1e-6 || diff < -1e-6 {
+			t.Errorf("%s score = %f, want %f", r.Chunk.FilePath, r.Score, want[r.Chunk.FilePath])
+		}
+	}
+	if boosted[0].Chunk.FilePath != "auth/new.go" {
+		t.Errorf("expected the recent file first, got %s", boosted[0].Chunk.FilePath)
+	}
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// defaultWarnAt is the fraction of a budget limit at which agents are warned.
+const defaultWarnAt = 0.8
+
+// ErrBudgetExceeded is returned for searches beyond a session's budget.
+var ErrBudgetExceeded = errcode.New(errcode.BudgetExceeded, "search budget exceeded")
+
+// Budget enforces the search budget of each session: searches per minute
+// and total result content bytes. Sessions are warned as they near a limit
+// and refused once they reach it.
+type Budget struct {
+	mu       sync.Mutex
+	sessions map[string]*budgetUsage
+	now      func() time.Time
+}
+
+type budgetUsage struct {
+	searches []time.Time // searches started in the last minute
+	bytes    int64       // result content returned so far
+}
+
+// NewBudget returns an empty Budget.
+func NewBudget() *Budget {
+	return &Budget{sessions: make(map[string]*budgetUsage), now: time.Now}
+}
+
+// Start records a search by session. It fails with ErrBudgetExceeded when
+// the session is at a limit of cfg.
+func (b *Budget) Start(cfg config.BudgetConfig, session string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	u := b.usage(session)
+	if cfg.MaxContentBytes > 0 && u.bytes >= cfg.MaxContentBytes {
+		return fmt.Errorf("%w: %d of %d result bytes returned to this session (index.search.budget.max_content_bytes)",
+			ErrBudgetExceeded, u.bytes, cfg.MaxContentBytes)
+	}
+	if cfg.SearchesPerMinute > 0 && len(u.searches) >= cfg.SearchesPerMinute {
+		wait := u.searches[0].Add(time.Minute).Sub(b.now()).Round(time.Second)
+		return fmt.Errorf("%w: %d searches in the last minute (index.search.budget.searches_per_minute); retry in %s",
+			ErrBudgetExceeded, len(u.searches), wait)
+	}
+	u.searches = append(u.searches, b.now())
+	return nil
+}
+
+// Spend records n bytes of results returned to session and returns a
+// warning for each limit of cfg the session is close to.
+func (b *Budget) Spend(cfg config.BudgetConfig, session string, n int64) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	u := b.usage(session)
+	u.bytes += n
+
+	warnAt := cfg.WarnAt
+	if warnAt == 0 {
+		warnAt = defaultWarnAt
+	}
+	var warnings []string
+	if limit := cfg.SearchesPerMinute; limit > 0 && float64(len(u.searches)) >= warnAt*float64(limit) {
+		warnings = append(warnings, fmt.Sprintf("search budget: %d of %d searches in the last minute; slow down",
+			len(u.searches), limit))
+	}
+	if limit := cfg.MaxContentBytes; limit > 0 && float64(u.bytes) >= warnAt*float64(limit) {
+		warnings = append(warnings, fmt.Sprintf("search budget: %d of %d result bytes used this session; narrow your searches",
+			min(u.bytes, limit), limit))
+	}
+	return warnings
+}
+
+// Forget drops the usage of a session that ended.
+func (b *Budget) Forget(session string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.sessions, session)
+}
+
+// usage returns the usage of session, without searches older than a minute.
+// b.mu must be held.
+func (b *Budget) usage(session string) *budgetUsage {
+	u, ok := b.sessions[session]
+	if !ok {
+		u = &budgetUsage{}
+		b.sessions[session] = u
+	}
+	cutoff := b.now().Add(-time.Minute)
+	i := 0
+	for i < len(u.searches) && !u.searches[i].After(cutoff) {
+		i++
+	}
+	u.searches = u.searches[i:]
+	return u
+}
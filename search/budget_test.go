@@ -0,0 +1,79 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+func TestBudget_SearchesPerMinute(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBudget()
+	b.now = func() time.Time { return now }
+	cfg := config.BudgetConfig{SearchesPerMinute: 5}
+
+	for i := 1; i <= 5; i++ {
+		if err := b.Start(cfg, "s1"); err != nil {
+			t.Fatalf("search %d: %v", i, err)
+		}
+		warnings := b.Spend(cfg, "s1", 0)
+		// 4 of 5 reaches the default warning threshold of 80%
+		if wantWarning := i >= 4; (len(warnings) > 0) != wantWarning {
+			t.Errorf("search %d: warnings = %v, want warning %v", i, warnings, wantWarning)
+		}
+	}
+	if err := b.Start(cfg, "s1"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("sixth search: err = %v, want ErrBudgetExceeded", err)
+	}
+
+	// Other sessions have their own budget
+	if err := b.Start(cfg, "s2"); err != nil {
+		t.Errorf("other session: %v", err)
+	}
+
+	// Searches leave the window after a minute
+	now = now.Add(time.Minute + time.Second)
+	if err := b.Start(cfg, "s1"); err != nil {
+		t.Errorf("after a minute: %v", err)
+	}
+}
+
+func TestBudget_MaxContentBytes(t *testing.T) {
+	b := NewBudget()
+	cfg := config.BudgetConfig{MaxContentBytes: 1000, WarnAt: 0.5}
+
+	if err := b.Start(cfg, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := b.Spend(cfg, "s1", 400); len(warnings) != 0 {
+		t.Errorf("warnings at 400 bytes = %v, want none", warnings)
+	}
+	if err := b.Start(cfg, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := b.Spend(cfg, "s1", 700); len(warnings) != 1 {
+		t.Errorf("warnings at 1100 bytes = %v, want one", warnings)
+	}
+	if err := b.Start(cfg, "s1"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("err = %v, want ErrBudgetExceeded", err)
+	}
+
+	b.Forget("s1")
+	if err := b.Start(cfg, "s1"); err != nil {
+		t.Errorf("after Forget: %v", err)
+	}
+}
+
+func TestBudget_Unlimited(t *testing.T) {
+	b := NewBudget()
+	for i := 0; i < 100; i++ {
+		if err := b.Start(config.BudgetConfig{}, ""); err != nil {
+			t.Fatal(err)
+		}
+		if warnings := b.Spend(config.BudgetConfig{}, "", 1<<20); len(warnings) != 0 {
+			t.Fatalf("warnings = %v", warnings)
+		}
+	}
+}
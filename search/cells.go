@@ -0,0 +1,46 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+// AddCells sets the notebook cells that each result from a Jupyter
+// notebook spans, so that results can be found in the notebook: their line
+// numbers are lines of the notebook's text (see indexer.NotebookText), not
+// of its JSON. Results from other files get no cells.
+func AddCells(ctx context.Context, st store.CodeStore, projectRoot string, results []store.SearchResult) error {
+	files := make(map[string][]string)
+	for i, r := range results {
+		results[i].Cells = nil
+		if !indexer.IsNotebook(r.Chunk.FilePath) || r.Chunk.DeletedAt != nil {
+			continue
+		}
+		lines, ok := files[r.Chunk.FilePath]
+		if !ok {
+			var err error
+			if lines, _, err = IndexedLines(ctx, st, projectRoot, r.Chunk.FilePath); err != nil {
+				return err
+			}
+			files[r.Chunk.FilePath] = lines
+		}
+		results[i].Cells = indexer.NotebookCells(lines, r.Chunk.StartLine, r.Chunk.EndLine)
+	}
+	return nil
+}
+
+// FormatCells describes the notebook cells of a result: "cell 3" or
+// "cells 3-5". It is empty without cells.
+func FormatCells(cells []int) string {
+	switch len(cells) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("cell %d", cells[0])
+	default:
+		return fmt.Sprintf("cells %d-%d", cells[0], cells[len(cells)-1])
+	}
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestAddCells(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	path := filepath.Join(root, "model.ipynb")
+	nb := `{"cells": [{"cell_type": "markdown", "source": "# Model"}, {"cell_type": "code", "source": ["x = 1\n", "y = 2"]}], "nbformat": 4}`
+	if err := os.WriteFile(path, []byte(nb), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := indexer.HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "model.ipynb", Hash: hash, ModTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "model.ipynb", StartLine: 5, EndLine: 5}},
+		{Chunk: store.Chunk{FilePath: "model.ipynb", StartLine: 1, EndLine: 6}},
+		{Chunk: store.Chunk{FilePath: "main.go", StartLine: 1, EndLine: 3}},
+	}
+	if err := AddCells(ctx, st, root, results); err != nil {
+		t.Fatalf("AddCells failed: %v", err)
+	}
+	if !slices.Equal(results[0].Cells, []int{2}) || FormatCells(results[0].Cells) != "cell 2" {
+		t.Errorf("expected cell 2, got %v", results[0].Cells)
+	}
+	if FormatCells(results[1].Cells) != "cells 1-2" {
+		t.Errorf("expected cells 1-2, got %v", results[1].Cells)
+	}
+	if results[2].Cells != nil {
+		t.Errorf("expected no cells outside notebooks, got %v", results[2].Cells)
+	}
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"slices"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// Confidence levels of search results, telling agents when to fall back to
+// grep rather than trust weak matches.
+const (
+	ConfidenceHigh = "high" // the result stands out from the other matches
+	ConfidenceLow  = "low"  // the result scores like the other matches
+	ConfidenceNone = "none" // nothing matched
+)
+
+// confidenceRatio is how many times the median score of the other results
+// a result must score to stand out. Scores are compared rather than taken
+// as is because their scale depends on the backend and the corpus.
+const confidenceRatio = 1.5
+
+// MinScore drops the results scoring below min. A min of 0 keeps every
+// result.
+func MinScore(results []store.SearchResult, min float32) []store.SearchResult {
+	if min <= 0 {
+		return results
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if r.Score >= min {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// AddConfidence rates each result high when its score is at least
+// confidenceRatio times the median score of the other results, and low
+// otherwise; a lone result is high. It returns the confidence of the results
+// as a whole: high when any result is, none without results.
+func AddConfidence(results []store.SearchResult) string {
+	if len(results) == 0 {
+		return ConfidenceNone
+	}
+	overall := ConfidenceLow
+	others := make([]float32, 0, len(results)-1)
+	for i := range results {
+		others = others[:0]
+		for j, r := range results {
+			if j != i {
+				others = append(others, r.Score)
+			}
+		}
+		results[i].Confidence = ConfidenceLow
+		if score := results[i].Score; len(others) == 0 || score > 0 && score >= confidenceRatio*median(others) {
+			results[i].Confidence = ConfidenceHigh
+			overall = ConfidenceHigh
+		}
+	}
+	return overall
+}
+
+// ConfidenceNote explains a confidence other than high to the reader of the
+// results, or returns "" for high confidence.
+func ConfidenceNote(confidence string) string {
+	switch confidence {
+	case ConfidenceNone:
+		return "no confident result: nothing matched; fall back to grep or other terms"
+	case ConfidenceLow:
+		return "low confidence: no result stands out from the other matches; verify with grep or refine the query"
+	}
+	return ""
+}
+
+// median returns the median of scores, which it sorts.
+func median(scores []float32) float32 {
+	slices.Sort(scores)
+	n := len(scores)
+	if n%2 == 1 {
+		return scores[n/2]
+	}
+	return (scores[n/2-1] + scores[n/2]) / 2
+}
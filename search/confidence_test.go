@@ -0,0 +1,53 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func scoredResults(scores ...float32) []store.SearchResult {
+	results := make([]store.SearchResult, len(scores))
+	for i, score := range scores {
+		results[i] = store.SearchResult{Score: score}
+	}
+	return results
+}
+
+func TestAddConfidence(t *testing.T) {
+	tests := []struct {
+		name    string
+		scores  []float32
+		want    string
+		perItem []string
+	}{
+		{"no results", nil, ConfidenceNone, nil},
+		{"lone result", []float32{0.2}, ConfidenceHigh, []string{ConfidenceHigh}},
+		{"top stands out", []float32{9, 3, 2.5, 2}, ConfidenceHigh, []string{ConfidenceHigh, ConfidenceLow, ConfidenceLow, ConfidenceLow}},
+		{"flat scores", []float32{3, 2.9, 2.8, 2.7}, ConfidenceLow, []string{ConfidenceLow, ConfidenceLow, ConfidenceLow, ConfidenceLow}},
+		{"zero scores", []float32{0, 0}, ConfidenceLow, []string{ConfidenceLow, ConfidenceLow}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := scoredResults(tt.scores...)
+			if got := AddConfidence(results); got != tt.want {
+				t.Errorf("AddConfidence() = %q, want %q", got, tt.want)
+			}
+			for i, r := range results {
+				if r.Confidence != tt.perItem[i] {
+					t.Errorf("result %d: confidence %q, want %q", i, r.Confidence, tt.perItem[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMinScore(t *testing.T) {
+	results := MinScore(scoredResults(5, 2, 1), 2)
+	if len(results) != 2 || results[1].Score != 2 {
+		t.Errorf("expected the results scoring at least 2, got %+v", results)
+	}
+	if got := MinScore(scoredResults(5, 2, 1), 0); len(got) != 3 {
+		t.Errorf("expected a min of 0 to keep every result, got %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+// Context is the code around a search result.
+type Context struct {
+	StartLine int    `json:"start_line"` // first line of Before
+	EndLine   int    `json:"end_line"`   // last line of After
+	Before    string `json:"before"`
+	After     string `json:"after"`
+	Source    string `json:"source"` // "disk" or "index"
+}
+
+// AddContext returns up to n lines before and after each result, aligned
+// with results. Lines are read from the file on disk, or reassembled from
+// the indexed chunks when the file changed since it was indexed so that
+// they line up with the result. Results from deleted files get no context.
+func AddContext(ctx context.Context, st store.CodeStore, projectRoot string, results []store.SearchResult, n int) ([]*Context, error) {
+	contexts := make([]*Context, len(results))
+	if n <= 0 {
+		return contexts, nil
+	}
+
+	files := make(map[string]*fileLines)
+	for i, r := range results {
+		if r.Chunk.DeletedAt != nil {
+			continue
+		}
+		f, ok := files[r.Chunk.FilePath]
+		if !ok {
+			var err error
+			if f, err = loadFileLines(ctx, st, projectRoot, r.Chunk.FilePath); err != nil {
+				return nil, err
+			}
+			files[r.Chunk.FilePath] = f
+		}
+		if f != nil {
+			contexts[i] = f.around(r.Chunk.StartLine, r.Chunk.EndLine, n)
+		}
+	}
+	return contexts, nil
+}
+
+// fileLines are the lines of a file and where they were read from.
+type fileLines struct {
+	lines  []string
+	source string
+}
+
+// loadFileLines reads the lines of an indexed file, or returns nil when the
+// file is not indexed.
+func loadFileLines(ctx context.Context, st store.CodeStore, projectRoot, path string) (*fileLines, error) {
+	lines, source, err := IndexedLines(ctx, st, projectRoot, path)
+	if err != nil || lines == nil {
+		return nil, err
+	}
+	return &fileLines{lines: lines, source: source}, nil
+}
+
+// IndexedLines returns the lines of an indexed file and where they were
+// read from: "disk" when the file is unchanged since it was indexed,
+// otherwise "index", reassembled from its chunks. It returns nil lines when
+// the file is not indexed.
+func IndexedLines(ctx context.Context, st store.CodeStore, projectRoot, path string) ([]string, string, error) {
+	doc, err := st.GetDocument(ctx, path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get document: %w", err)
+	}
+	if doc == nil {
+		return nil, "", nil
+	}
+
+	abs := filepath.Join(projectRoot, filepath.FromSlash(path))
+	if hash, err := indexer.HashFile(abs); err == nil && hash == doc.Hash {
+		if content, err := os.ReadFile(abs); err == nil {
+			if text, _, ok := indexer.DecodeFile(path, content); ok {
+				return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), "disk", nil
+			}
+		}
+	}
+
+	chunks, err := st.GetChunksForFile(ctx, path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get chunks: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, "", nil
+	}
+	return chunkLines(chunks), "index", nil
+}
+
+// around returns up to n lines on each side of lines start..end, or nil
+// when the file has no such lines.
+func (f *fileLines) around(start, end, n int) *Context {
+	start = max(start, 1)
+	if start > len(f.lines) {
+		return nil
+	}
+	end = min(max(end, start), len(f.lines))
+	from := max(start-n, 1)
+	to := min(end+n, len(f.lines))
+
+	c := &Context{StartLine: from, EndLine: to, Source: f.source}
+	if from < start {
+		c.Before = strings.Join(f.lines[from-1:start-1], "\n")
+	}
+	if end < to {
+		c.After = strings.Join(f.lines[end:to], "\n")
+	}
+	return c
+}
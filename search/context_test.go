@@ -0,0 +1,65 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestAddContext(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	path := filepath.Join(root, "main.go")
+	content := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1)\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := indexer.HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := store.Chunk{ID: "main.go_0", FilePath: "main.go", StartLine: 1, EndLine: 7, Content: "File: main.go\n\n" + content, Hash: "c", UpdatedAt: time.Now()}
+	if err := st.SaveChunks(ctx, []store.Chunk{chunk}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "main.go", Hash: hash, ModTime: time.Now(), ChunkIDs: []string{chunk.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := store.SearchResult{Chunk: store.Chunk{FilePath: "main.go", StartLine: 5, EndLine: 5}}
+	contexts, err := AddContext(ctx, st, root, []store.SearchResult{result}, 2)
+	if err != nil {
+		t.Fatalf("AddContext failed: %v", err)
+	}
+	want := Context{StartLine: 3, EndLine: 7, Before: "import \"fmt\"\n", After: "\tfmt.Println(1)\n}", Source: "disk"}
+	if contexts[0] == nil || *contexts[0] != want {
+		t.Errorf("unexpected context from disk: %+v", contexts[0])
+	}
+
+	// After an edit, the lines come from the index to match the result
+	if err := os.WriteFile(path, []byte("package main\n\n// edited\n"+content[len("package main\n\n"):]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	contexts, err = AddContext(ctx, st, root, []store.SearchResult{result}, 2)
+	if err != nil {
+		t.Fatalf("AddContext failed: %v", err)
+	}
+	if contexts[0] == nil || *contexts[0] != (Context{StartLine: 3, EndLine: 7, Before: want.Before, After: want.After, Source: "index"}) {
+		t.Errorf("unexpected context from index: %+v", contexts[0])
+	}
+
+	if contexts, _ := AddContext(ctx, st, root, []store.SearchResult{result}, 0); contexts[0] != nil {
+		t.Errorf("expected no context for n = 0, got %+v", contexts[0])
+	}
+}
@@ -0,0 +1,116 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// MergeOverlapping merges results from the same file whose line ranges
+// overlap or touch into a single result spanning the union of the ranges.
+// The merged result keeps the highest score and the position of its
+// best-scoring member.
+func MergeOverlapping(results []store.SearchResult) []store.SearchResult {
+	byFile := make(map[string][]int)
+	for i, r := range results {
+		byFile[r.Chunk.FilePath] = append(byFile[r.Chunk.FilePath], i)
+	}
+
+	// best maps each result index to the index of its group's best member
+	best := make([]int, len(results))
+	for i := range best {
+		best[i] = i
+	}
+	merged := make(map[int]store.SearchResult)
+
+	for _, idxs := range byFile {
+		if len(idxs) < 2 {
+			continue
+		}
+		sort.SliceStable(idxs, func(a, b int) bool {
+			return results[idxs[a]].Chunk.StartLine < results[idxs[b]].Chunk.StartLine
+		})
+
+		group := idxs[:1]
+		end := results[idxs[0]].Chunk.EndLine
+		for _, i := range idxs[1:] {
+			if results[i].Chunk.StartLine <= end+1 {
+				group = append(group, i)
+				end = max(end, results[i].Chunk.EndLine)
+				continue
+			}
+			mergeGroup(results, group, best, merged)
+			group = []int{i}
+			end = results[i].Chunk.EndLine
+		}
+		mergeGroup(results, group, best, merged)
+	}
+
+	out := make([]store.SearchResult, 0, len(results))
+	for i, r := range results {
+		if best[i] != i {
+			continue
+		}
+		if m, ok := merged[i]; ok {
+			r = m
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// mergeGroup combines a run of overlapping results (sorted by start line)
+// into one, recorded under the index of the best-scoring member.
+func mergeGroup(results []store.SearchResult, group []int, best []int, merged map[int]store.SearchResult) {
+	if len(group) < 2 {
+		return
+	}
+
+	top := group[0]
+	for _, i := range group[1:] {
+		if results[i].Score > results[top].Score {
+			top = i
+		}
+	}
+
+	chunks := make([]store.Chunk, len(group))
+	start, end := results[group[0]].Chunk.StartLine, 0
+	for n, i := range group {
+		chunks[n] = results[i].Chunk
+		end = max(end, results[i].Chunk.EndLine)
+		best[i] = top
+	}
+
+	lines := chunkLines(chunks)
+	end = min(end, len(lines))
+	content := strings.Join(lines[start-1:end], "\n") + "\n"
+	if header, _, ok := strings.Cut(results[top].Chunk.Content, "\n\n"); ok && strings.HasPrefix(header, "File: ") {
+		content = header + "\n\n" + content
+	}
+
+	r := results[top]
+	r.Chunk.StartLine = start
+	r.Chunk.EndLine = end
+	r.Chunk.Content = content
+	merged[top] = r
+}
+
+// CapPerFile keeps at most maxPerFile results per file, preserving order.
+// A maxPerFile of zero or less disables the cap.
+func CapPerFile(results []store.SearchResult, maxPerFile int) []store.SearchResult {
+	if maxPerFile <= 0 {
+		return results
+	}
+
+	counts := make(map[string]int)
+	out := results[:0]
+	for _, r := range results {
+		if counts[r.Chunk.FilePath] >= maxPerFile {
+			continue
+		}
+		counts[r.Chunk.FilePath]++
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func chunkResult(path string, start, end int, content string, score float32) store.SearchResult {
+	return store.SearchResult{
+		Chunk: store.Chunk{FilePath: path, StartLine: start, EndLine: end, Content: "File: " + path + "\n\n" + content},
+		Score: score,
+	}
+}
+
+func TestMergeOverlapping(t *testing.T) {
+	results := []store.SearchResult{
+		chunkResult("a.go", 3, 5, "c\nd\ne\n", 0.9),
+		chunkResult("b.go", 1, 2, "x\ny\n", 0.8),
+		chunkResult("a.go", 1, 3, "a\nb\nc\n", 0.7),
+		chunkResult("a.go", 6, 6, "f\n", 0.6),
+		chunkResult("a.go", 20, 22, "t\nu\nv\n", 0.5),
+	}
+
+	merged := MergeOverlapping(results)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 results, got %d: %+v", len(merged), merged)
+	}
+
+	first := merged[0]
+	if first.Chunk.FilePath != "a.go" || first.Chunk.StartLine != 1 || first.Chunk.EndLine != 6 || first.Score != 0.9 {
+		t.Errorf("unexpected merged result: %+v", first)
+	}
+	if want := "File: a.go\n\na\nb\nc\nd\ne\nf\n"; first.Chunk.Content != want {
+		t.Errorf("merged content = %q, want %q", first.Chunk.Content, want)
+	}
+	if merged[1].Chunk.FilePath != "b.go" || merged[2].Chunk.StartLine != 20 {
+		t.Errorf("expected untouched results to keep their order, got %+v", merged[1:])
+	}
+}
+
+func TestCapPerFile(t *testing.T) {
+	results := []store.SearchResult{
+		result("a.go", 1, 0.9),
+		result("a.go", 50, 0.8),
+		result("b.go", 1, 0.7),
+		result("a.go", 90, 0.6),
+	}
+
+	capped := CapPerFile(results, 2)
+	if len(capped) != 3 || capped[2].Chunk.FilePath != "b.go" {
+		t.Errorf("unexpected capped results: %+v", capped)
+	}
+
+	if uncapped := CapPerFile(results[:3], -1); len(uncapped) != 3 {
+		t.Errorf("expected negative cap to disable capping, got %d results", len(uncapped))
+	}
+}
+
+func TestRank_CoversMoreFiles(t *testing.T) {
+	var results []store.SearchResult
+	for i := 0; i < 5; i++ {
+		results = append(results, chunkResult("big.go", i*100+1, i*100+10, "x\n", 1))
+	}
+	results = append(results, chunkResult("other.go", 1, 1, "y\n", 0.5))
+
+	ranked := Rank(results, config.SearchConfig{MaxPerFile: 2}, 3)
+	if len(ranked) != 3 || ranked[2].Chunk.FilePath != "other.go" {
+		t.Errorf("expected the cap to make room for other.go, got %+v", ranked)
+	}
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// SymbolDrift lists the differences between the symbol index and the chunk
+// index, which are both built from the files on disk but updated separately.
+type SymbolDrift struct {
+	Outdated  []string  `json:"outdated,omitempty"` // symbols extracted from other content than was chunked
+	Missing   []string  `json:"missing,omitempty"`  // chunked files without symbols
+	Removed   []string  `json:"removed,omitempty"`  // symbol files no longer chunked
+	LastBuilt time.Time `json:"last_built"`         // last update of the symbol index
+}
+
+// CheckSymbolDrift compares the content hashes recorded by symbols with the
+// chunked files of the given languages (file extensions).
+func CheckSymbolDrift(ctx context.Context, files []store.FileStats, symbols trace.SymbolStore, languages []string) (*SymbolDrift, error) {
+	stats, err := symbols.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol index stats: %w", err)
+	}
+	drift := &SymbolDrift{LastBuilt: stats.LastUpdated}
+
+	chunked := make(map[string]bool, len(files))
+	for _, f := range files {
+		if !slices.Contains(languages, strings.ToLower(filepath.Ext(f.Path))) {
+			continue
+		}
+		chunked[f.Path] = true
+		switch hash := symbols.FileHash(f.Path); {
+		case !symbols.IsFileIndexed(f.Path):
+			drift.Missing = append(drift.Missing, f.Path)
+		case f.Hash != "" && hash != "" && hash != f.Hash:
+			drift.Outdated = append(drift.Outdated, f.Path)
+		}
+	}
+	for _, path := range symbols.IndexedFiles() {
+		if !chunked[path] {
+			drift.Removed = append(drift.Removed, path)
+		}
+	}
+	slices.Sort(drift.Outdated)
+	slices.Sort(drift.Missing)
+	slices.Sort(drift.Removed)
+	return drift, nil
+}
+
+// Drifted reports whether the symbol index differs from the chunk index.
+func (d *SymbolDrift) Drifted() bool {
+	return len(d.Outdated)+len(d.Missing)+len(d.Removed) > 0
+}
+
+// Warning describes the drift for agents and users.
+func (d *SymbolDrift) Warning() string {
+	return fmt.Sprintf("symbol index is out of date with the search index: %d files changed, %d missing, %d removed since it was built (%s); trace results may be incomplete, run 'agentdx trace --rebuild'",
+		len(d.Outdated), len(d.Missing), len(d.Removed), d.LastBuilt.Format("2006-01-02 15:04:05"))
+}
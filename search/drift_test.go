@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+func TestCheckSymbolDrift(t *testing.T) {
+	ctx := context.Background()
+	symbols := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	for path, hash := range map[string]string{"same.go": "h1", "changed.go": "old", "gone.go": "h3"} {
+		if err := symbols.SaveFileWithHash(ctx, path, hash, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []store.FileStats{
+		{Path: "same.go", Hash: "h1"},
+		{Path: "changed.go", Hash: "new"},
+		{Path: "new.go", Hash: "h4"},
+		{Path: "README.md", Hash: "h5"}, // not traced
+	}
+	drift, err := CheckSymbolDrift(ctx, files, symbols, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !drift.Drifted() {
+		t.Fatal("expected drift")
+	}
+	if !slices.Equal(drift.Outdated, []string{"changed.go"}) ||
+		!slices.Equal(drift.Missing, []string{"new.go"}) ||
+		!slices.Equal(drift.Removed, []string{"gone.go"}) {
+		t.Errorf("drift = %+v", drift)
+	}
+
+	drift, err = CheckSymbolDrift(ctx, files[:1], symbols, []string{".go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.Removed) != 2 || len(drift.Outdated)+len(drift.Missing) != 0 {
+		t.Errorf("drift = %+v, want changed.go and gone.go removed", drift)
+	}
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// defaultSynonyms are the built-in sets of interchangeable code vocabulary.
+var defaultSynonyms = [][]string{
+	{"login", "signin", "auth", "authenticate"},
+	{"fetch", "get", "retrieve", "load"},
+	{"delete", "remove", "destroy"},
+	{"create", "add", "new", "insert"},
+	{"update", "modify", "edit"},
+	{"error", "err", "exception", "failure"},
+	{"config", "configuration", "settings", "options"},
+	{"init", "initialize", "setup"},
+	{"user", "account"},
+	{"db", "database"},
+}
+
+// Expander expands query terms with synonyms and identifier variants.
+type Expander struct {
+	synonyms map[string][]string
+}
+
+// NewExpander builds an expander from the built-in synonym sets and the
+// extra sets in cfg.
+func NewExpander(cfg config.ExpansionConfig) *Expander {
+	e := &Expander{synonyms: make(map[string][]string)}
+	for _, set := range append(append([][]string{}, defaultSynonyms...), cfg.Synonyms...) {
+		for _, word := range set {
+			key := strings.ToLower(word)
+			e.synonyms[key] = append(e.synonyms[key], set...)
+		}
+	}
+	return e
+}
+
+// ExpandFunc returns the store expansion hook for cfg, or nil when expansion
+// is disabled.
+func ExpandFunc(cfg config.ExpansionConfig) store.ExpandFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewExpander(cfg).Expand
+}
+
+// SplitFunc returns the store hook selecting the files whose identifiers are
+// indexed as sub-tokens, or nil when no language splits them.
+func SplitFunc(cfg config.IdentifierConfig) store.SplitFunc {
+	return store.NewSplitFunc(cfg.Split, cfg.Languages)
+}
+
+// Expand returns term followed by its synonyms and, for identifiers made of
+// several words, the snake_case and joined lowercase variants.
+func (e *Expander) Expand(term string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(word string) {
+		key := strings.ToLower(word)
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		out = append(out, word)
+	}
+
+	add(term)
+	for _, syn := range e.synonyms[strings.ToLower(term)] {
+		add(syn)
+	}
+	for _, variant := range store.IdentifierVariants(term) {
+		add(variant)
+	}
+	return out
+}
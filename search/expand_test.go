@@ -0,0 +1,38 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+func TestExpander_Expand(t *testing.T) {
+	e := NewExpander(config.ExpansionConfig{Synonyms: [][]string{{"tenant", "org", "workspace"}}})
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"login", []string{"login", "signin", "auth", "authenticate"}},
+		{"Login", []string{"Login", "signin", "auth", "authenticate"}},
+		{"tenant", []string{"tenant", "org", "workspace"}},
+		{"userName", []string{"userName", "user_name"}},
+		{"user_name", []string{"user_name", "username"}},
+		{"checkout", []string{"checkout"}},
+	}
+	for _, tt := range tests {
+		if got := e.Expand(tt.term); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expand(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestExpandFunc_Disabled(t *testing.T) {
+	if ExpandFunc(config.ExpansionConfig{}) != nil {
+		t.Error("expected no expansion hook when expansion is disabled")
+	}
+	if ExpandFunc(config.ExpansionConfig{Enabled: true}) == nil {
+		t.Error("expected an expansion hook when expansion is enabled")
+	}
+}
@@ -0,0 +1,215 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// Explanation is what the index holds about a line of a file: the chunks
+// covering it and the symbols defined there, with their callers.
+type Explanation struct {
+	File    string            `json:"file"`
+	Line    int               `json:"line"`
+	Query   string            `json:"query,omitempty"`
+	Chunks  []ExplainedChunk  `json:"chunks"`
+	Symbols []ExplainedSymbol `json:"symbols"`
+}
+
+// ExplainedChunk is a chunk covering the explained line.
+type ExplainedChunk struct {
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Type       string `json:"type,omitempty"`
+	Symbol     string `json:"symbol,omitempty"`
+	SymbolKind string `json:"symbol_kind,omitempty"`
+	Package    string `json:"package,omitempty"`
+	// Score of the chunk for the query, nil when no query was given or the
+	// chunk does not match it
+	Score   *float32 `json:"score,omitempty"`
+	Content string   `json:"content"`
+}
+
+// ExplainedSymbol is a symbol defined in the chunks covering the explained
+// line, or enclosing it, with the callers attributed to its definition.
+type ExplainedSymbol struct {
+	trace.Symbol
+	Callers []trace.CallerInfo `json:"callers"`
+	// TotalCallers counts the callers before they were cut to the limit
+	TotalCallers int `json:"total_callers"`
+}
+
+// explainSearchLimit bounds the matches of the query searched in the file.
+const explainSearchLimit = 200
+
+// Explain returns what the index holds about line of file, a path relative
+// to the project root: the chunks covering it, scored for query when it is
+// set, and the symbols the index symbols holds that are defined in those
+// chunks or enclose the line, each with up to maxCallers callers (0 for no
+// limit). symbols may be nil when there is no symbol index. It fails with errcode.NotFound when
+// the file is not indexed or no chunk covers the line.
+func Explain(ctx context.Context, st store.SearchStore, symbols trace.SymbolStore, file string, line int, query string, maxCallers int) (*Explanation, error) {
+	chunks, err := st.GetChunksForFile(ctx, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks for %s: %w", file, err)
+	}
+	if len(chunks) == 0 {
+		return nil, errcode.New(errcode.NotFound, "%s is not indexed", file)
+	}
+
+	exp := &Explanation{File: file, Line: line, Query: query, Chunks: []ExplainedChunk{}, Symbols: []ExplainedSymbol{}}
+	var covering []store.Chunk
+	for _, c := range chunks {
+		if c.StartLine <= line && line <= c.EndLine {
+			covering = append(covering, c)
+		}
+	}
+	if len(covering) == 0 {
+		return nil, errcode.New(errcode.NotFound, "no indexed chunk of %s covers line %d", file, line)
+	}
+	sort.Slice(covering, func(i, j int) bool { return covering[i].StartLine < covering[j].StartLine })
+
+	scores, err := fileScores(ctx, st, file, query)
+	if err != nil {
+		return nil, err
+	}
+	start, end := covering[0].StartLine, covering[0].EndLine
+	for _, c := range covering {
+		ec := ExplainedChunk{
+			StartLine:  c.StartLine,
+			EndLine:    c.EndLine,
+			Type:       c.Type,
+			Symbol:     c.Symbol,
+			SymbolKind: c.SymbolKind,
+			Package:    c.Package,
+			Content:    c.Content,
+		}
+		if score, ok := scores[c.ID]; ok {
+			ec.Score = &score
+		}
+		exp.Chunks = append(exp.Chunks, ec)
+		start, end = min(start, c.StartLine), max(end, c.EndLine)
+	}
+
+	if symbols == nil {
+		return exp, nil
+	}
+	all, err := symbols.FindSymbols(ctx, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+	var inFile []trace.Symbol
+	seen := make(map[trace.Symbol]bool)
+	for _, sym := range all {
+		// Extractors may report a definition twice
+		if sym.File == file && !seen[sym] {
+			seen[sym] = true
+			inFile = append(inFile, sym)
+		}
+	}
+	for _, sym := range symbolsAt(inFile, start, end, line) {
+		es, err := explainSymbol(ctx, symbols, sym, maxCallers)
+		if err != nil {
+			return nil, err
+		}
+		exp.Symbols = append(exp.Symbols, es)
+	}
+	return exp, nil
+}
+
+// fileScores searches query in file and returns the scores of the matching
+// chunks by ID, or nil when query is empty.
+func fileScores(ctx context.Context, st store.FilteredSearcher, file, query string) (map[string]float32, error) {
+	if query == "" {
+		return nil, nil
+	}
+	results, err := st.SearchFiltered(ctx, query, explainSearchLimit, store.SearchFilter{Include: []string{escapeGlob(file)}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", file, err)
+	}
+	scores := make(map[string]float32, len(results))
+	for _, r := range results {
+		// A pattern without a slash matches the file name at any depth
+		if r.Chunk.FilePath == file {
+			scores[r.Chunk.ID] = r.Score
+		}
+	}
+	return scores, nil
+}
+
+// escapeGlob escapes the glob metacharacters of path so that it matches
+// itself only.
+func escapeGlob(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[]{},\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// symbolsAt returns, ordered by line, the symbols of a file defined between
+// lines start and end, and the innermost symbol defined before start whose
+// body encloses line. Symbols without an end line, as the regex extractor
+// finds them, are taken to run to the next symbol.
+func symbolsAt(symbols []trace.Symbol, start, end, line int) []trace.Symbol {
+	sort.SliceStable(symbols, func(i, j int) bool { return symbols[i].Line < symbols[j].Line })
+
+	var at []trace.Symbol
+	enclosing := -1
+	for i, sym := range symbols {
+		if sym.Line >= start && sym.Line <= end {
+			at = append(at, sym)
+			continue
+		}
+		if sym.Line >= start {
+			continue
+		}
+		symEnd := sym.EndLine
+		if symEnd < sym.Line {
+			symEnd = line
+			for _, next := range symbols[i+1:] {
+				if next.Line > sym.Line {
+					symEnd = next.Line - 1
+					break
+				}
+			}
+		}
+		// Later definitions enclosing line are nested in earlier ones
+		if symEnd >= line {
+			enclosing = i
+		}
+	}
+	if enclosing >= 0 {
+		at = append([]trace.Symbol{symbols[enclosing]}, at...)
+	}
+	return at
+}
+
+// explainSymbol returns sym with up to maxCallers of the callers attributed
+// to its definition.
+func explainSymbol(ctx context.Context, st trace.SymbolStore, sym trace.Symbol, maxCallers int) (ExplainedSymbol, error) {
+	es := ExplainedSymbol{Symbol: sym, Callers: []trace.CallerInfo{}}
+	traced, err := trace.Callers(ctx, st, sym.Name, true)
+	if err != nil {
+		return es, err
+	}
+	for _, def := range traced.Definitions {
+		if def.Symbol.File == sym.File && def.Symbol.Line == sym.Line {
+			es.Callers = def.Callers
+			break
+		}
+	}
+	es.TotalCallers = len(es.Callers)
+	if maxCallers > 0 && len(es.Callers) > maxCallers {
+		es.Callers = es.Callers[:maxCallers]
+	}
+	return es, nil
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+	"github.com/doveaia/agentdx/trace"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	now := time.Now()
+	chunks := []store.Chunk{
+		{ID: "a1", FilePath: "auth/login.go", StartLine: 1, EndLine: 20, Content: "func Login() { validate session token }", Hash: "a1", UpdatedAt: now},
+		{ID: "a2", FilePath: "auth/login.go", StartLine: 21, EndLine: 40, Content: "func Logout() { clear cookie }", Hash: "a2", UpdatedAt: now},
+		{ID: "b1", FilePath: "web/login.go", StartLine: 1, EndLine: 20, Content: "func Handle() { session token }", Hash: "b1", UpdatedAt: now},
+		{ID: "c1", FilePath: "app/[id]/page.ts", StartLine: 1, EndLine: 20, Content: "export function Page() { session }", Hash: "c1", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	defs := []trace.Symbol{
+		{Name: "Login", Kind: trace.KindFunction, File: "auth/login.go", Line: 3, EndLine: 18},
+		{Name: "validate", Kind: trace.KindFunction, File: "auth/login.go", Line: 19},
+		{Name: "Logout", Kind: trace.KindFunction, File: "auth/login.go", Line: 25, EndLine: 35},
+	}
+	if err := symbols.SaveFile(ctx, "auth/login.go", defs, nil); err != nil {
+		t.Fatal(err)
+	}
+	calls := []trace.Reference{
+		{SymbolName: "Login", File: "web/login.go", Line: 5, CallerName: "Handle", CallerFile: "web/login.go", CallerLine: 1},
+		{SymbolName: "Login", File: "web/login.go", Line: 9, CallerName: "Handle", CallerFile: "web/login.go", CallerLine: 1},
+	}
+	if err := symbols.SaveFile(ctx, "web/login.go", []trace.Symbol{{Name: "Handle", Kind: trace.KindFunction, File: "web/login.go", Line: 1}}, calls); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := Explain(ctx, st, symbols, "auth/login.go", 10, "session", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Chunks) != 1 || exp.Chunks[0].StartLine != 1 || exp.Chunks[0].Score == nil {
+		t.Fatalf("expected the scored chunk covering line 10, got %+v", exp.Chunks)
+	}
+	if len(exp.Symbols) != 2 || exp.Symbols[0].Name != "Login" || exp.Symbols[1].Name != "validate" {
+		t.Fatalf("expected Login and validate, got %+v", exp.Symbols)
+	}
+	if login := exp.Symbols[0]; login.TotalCallers != 2 || len(login.Callers) != 1 || login.Callers[0].Symbol.Name != "Handle" {
+		t.Errorf("expected 1 of 2 callers of Login, got %+v", login)
+	}
+
+	// The chunk of Logout does not match the query, and validate, without
+	// an end line, runs to the next symbol only
+	exp, err = Explain(ctx, st, symbols, "auth/login.go", 30, "session", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Chunks) != 1 || exp.Chunks[0].Score != nil {
+		t.Errorf("expected an unscored chunk, got %+v", exp.Chunks)
+	}
+	if len(exp.Symbols) != 1 || exp.Symbols[0].Name != "Logout" {
+		t.Errorf("expected Logout, got %+v", exp.Symbols)
+	}
+
+	// Without a symbol index, only the chunks are explained
+	exp, err = Explain(ctx, st, nil, "auth/login.go", 30, "", 0)
+	if err != nil || len(exp.Chunks) != 1 || len(exp.Symbols) != 0 {
+		t.Errorf("expected the chunk without symbols, got %+v (%v)", exp, err)
+	}
+
+	// Glob characters in the path are matched literally
+	exp, err = Explain(ctx, st, nil, "app/[id]/page.ts", 1, "session", 0)
+	if err != nil || len(exp.Chunks) != 1 || exp.Chunks[0].Score == nil {
+		t.Errorf("expected a scored chunk, got %+v (%v)", exp, err)
+	}
+
+	for _, tt := range []struct {
+		file string
+		line int
+	}{{"auth/missing.go", 1}, {"auth/login.go", 99}} {
+		if _, err := Explain(ctx, st, symbols, tt.file, tt.line, "", 0); errcode.Of(err) != errcode.NotFound {
+			t.Errorf("Explain(%s:%d) error = %v, want %s", tt.file, tt.line, err, errcode.NotFound)
+		}
+	}
+}
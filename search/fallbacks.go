@@ -0,0 +1,153 @@
+package search
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFallbackLogBytes is the size past which the fallback log is rotated to
+// a single backup, <log>.1.
+const maxFallbackLogBytes = 1 << 20
+
+// Reasons of the fallbacks reported by the hook of agentdx commands.
+const (
+	FallbackEmpty = "empty" // agentdx found nothing
+)
+
+// FallbackRecord is an incident where an agent searched without agentdx, or
+// where agentdx gave it nothing to work with.
+type FallbackRecord struct {
+	Time    time.Time `json:"time"`
+	Tool    string    `json:"tool"`              // Grep, Glob, or the agentdx command that failed
+	Query   string    `json:"query,omitempty"`   // pattern or query of the search
+	Path    string    `json:"path,omitempty"`    // directory or files the search was limited to
+	Reason  string    `json:"reason,omitempty"`  // FallbackEmpty or the error code of agentdx
+	Session string    `json:"session,omitempty"` // agent session ID
+}
+
+// RecordFallback appends rec to the fallback log at path, one JSON object
+// per line, rotating the log first when it grew past maxFallbackLogBytes.
+func RecordFallback(path string, rec FallbackRecord) error {
+	if info, err := os.Stat(path); err == nil && info.Size() > maxFallbackLogBytes {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate fallback log: %w", err)
+		}
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode fallback: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open fallback log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write fallback log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadFallbacks returns the fallbacks recorded at path and in its backup
+// since the given time, oldest first. A missing log holds no fallbacks and
+// malformed lines are skipped.
+func ReadFallbacks(path string, since time.Time) ([]FallbackRecord, error) {
+	var records []FallbackRecord
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open fallback log: %w", err)
+		}
+		r := bufio.NewReader(f)
+		for {
+			line, err := r.ReadBytes('\n')
+			var rec FallbackRecord
+			if json.Unmarshal(line, &rec) == nil && !rec.Time.Before(since) {
+				records = append(records, rec)
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("failed to read fallback log: %w", err)
+			}
+		}
+		f.Close()
+	}
+	return records, nil
+}
+
+// FallbackStats counts the fallbacks of one tool.
+type FallbackStats struct {
+	Tool  string `json:"tool"`
+	Count int    `json:"count"`
+}
+
+// FallbackQuery is a query agents fell back on, with how often they did.
+type FallbackQuery struct {
+	Tool     string    `json:"tool"`
+	Query    string    `json:"query"`
+	Reason   string    `json:"reason,omitempty"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// SummarizeFallbacks counts fallbacks by tool and by query, both most
+// frequent first. Queries are grouped by tool and case-insensitive text;
+// fallbacks without a query are only counted by tool.
+func SummarizeFallbacks(records []FallbackRecord) ([]FallbackStats, []FallbackQuery) {
+	byTool := make(map[string]int)
+	byQuery := make(map[string]*FallbackQuery)
+	for _, r := range records {
+		byTool[r.Tool]++
+		if strings.TrimSpace(r.Query) == "" {
+			continue
+		}
+		key := r.Tool + "\x00" + strings.ToLower(strings.TrimSpace(r.Query))
+		q, ok := byQuery[key]
+		if !ok {
+			q = &FallbackQuery{Tool: r.Tool, Query: r.Query}
+			byQuery[key] = q
+		}
+		q.Count++
+		if !r.Time.Before(q.LastSeen) {
+			q.LastSeen = r.Time
+			q.Reason = r.Reason
+		}
+	}
+
+	tools := make([]FallbackStats, 0, len(byTool))
+	for tool, n := range byTool {
+		tools = append(tools, FallbackStats{Tool: tool, Count: n})
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Count != tools[j].Count {
+			return tools[i].Count > tools[j].Count
+		}
+		return tools[i].Tool < tools[j].Tool
+	})
+
+	queries := make([]FallbackQuery, 0, len(byQuery))
+	for _, q := range byQuery {
+		queries = append(queries, *q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Count != queries[j].Count {
+			return queries[i].Count > queries[j].Count
+		}
+		return queries[i].LastSeen.After(queries[j].LastSeen)
+	})
+	return tools, queries
+}
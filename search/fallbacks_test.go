@@ -0,0 +1,77 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordFallback_ReadFallbacks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallbacks.log")
+	if records, err := ReadFallbacks(path, time.Time{}); err != nil || len(records) != 0 {
+		t.Fatalf("expected no fallbacks without a log, got %v (%v)", records, err)
+	}
+
+	now := time.Now()
+	for _, rec := range []FallbackRecord{
+		{Time: now.Add(-48 * time.Hour), Tool: "Grep", Query: "old"},
+		{Time: now.Add(-time.Hour), Tool: "Grep", Query: "billing"},
+		{Time: now, Tool: "agentdx search", Query: "Billing", Reason: FallbackEmpty},
+	} {
+		if err := RecordFallback(path, rec); err != nil {
+			t.Fatalf("RecordFallback failed: %v", err)
+		}
+	}
+	// Malformed lines are skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("not json\n")
+	f.Close()
+
+	records, err := ReadFallbacks(path, now.Add(-24*time.Hour))
+	if err != nil || len(records) != 2 || records[0].Query != "billing" {
+		t.Fatalf("expected the 2 fallbacks of the last day, got %+v (%v)", records, err)
+	}
+
+	// A log past the size limit is rotated and still read
+	if err := os.WriteFile(path, []byte(strings.Repeat(" ", maxFallbackLogBytes+1)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordFallback(path, FallbackRecord{Time: now, Tool: "Glob", Query: "*.go"}); err != nil {
+		t.Fatalf("RecordFallback failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected the log to be rotated: %v", err)
+	}
+	records, err = ReadFallbacks(path, time.Time{})
+	if err != nil || len(records) != 1 || records[0].Tool != "Glob" {
+		t.Errorf("expected the fallback after rotation, got %+v (%v)", records, err)
+	}
+}
+
+func TestSummarizeFallbacks(t *testing.T) {
+	now := time.Now()
+	tools, queries := SummarizeFallbacks([]FallbackRecord{
+		{Time: now.Add(-time.Hour), Tool: "Grep", Query: "billing"},
+		{Time: now, Tool: "Grep", Query: "Billing "},
+		{Time: now, Tool: "Glob", Query: "*.go"},
+		{Time: now, Tool: "Grep"},
+		{Time: now, Tool: "agentdx search", Query: "billing", Reason: FallbackEmpty},
+	})
+
+	if len(tools) != 3 || tools[0] != (FallbackStats{Tool: "Grep", Count: 3}) {
+		t.Errorf("expected Grep first with 3 fallbacks, got %+v", tools)
+	}
+	if len(queries) != 3 || queries[0].Tool != "Grep" || queries[0].Count != 2 || !queries[0].LastSeen.Equal(now) {
+		t.Errorf("expected the Grep billing query first, twice, got %+v", queries)
+	}
+	for _, q := range queries {
+		if q.Tool == "agentdx search" && q.Reason != FallbackEmpty {
+			t.Errorf("expected the reason of the last fallback, got %+v", q)
+		}
+	}
+}
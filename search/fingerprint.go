@@ -0,0 +1,39 @@
+package search
+
+import (
+	"context"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// ConfigMismatch reports that an index was built with other chunking or
+// tokenizer settings than the current configuration, so its chunks no
+// longer match what indexing would produce.
+type ConfigMismatch struct {
+	Indexed string // fingerprint recorded with the index
+	Current string // fingerprint of the configuration
+}
+
+// CheckConfigFingerprint compares the fingerprint recorded with the index in
+// st with the configuration. It returns nil when they match or the index has
+// no fingerprint yet.
+func CheckConfigFingerprint(ctx context.Context, cfg *config.Config, st store.IndexFingerprinter) (*ConfigMismatch, error) {
+	indexed, err := st.IndexFingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := cfg.IndexFingerprint()
+	if indexed == "" || indexed == current {
+		return nil, nil
+	}
+	return &ConfigMismatch{Indexed: indexed, Current: current}, nil
+}
+
+// Warning describes the mismatch for display.
+func (m *ConfigMismatch) Warning() string {
+	if m.Indexed == store.StaleIndexFingerprint {
+		return "index predates doc comment indexing and was migrated without doc comments; run 'agentdx reindex --due-to-config' to rebuild it"
+	}
+	return "index was built with other chunking settings (index.chunking, index.search.cjk_bigrams, index.search.identifiers) than the configuration; run 'agentdx reindex --due-to-config' to rebuild it"
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Flight runs identical concurrent requests once and shares the result with
+// every caller (single-flight). Agents often fire the same query several
+// times in parallel; on a shared backend each duplicate would cost a full
+// search.
+type Flight[T any] struct {
+	name   string
+	mu     sync.Mutex
+	calls  map[string]*flightCall[T]
+	shared atomic.Int64
+}
+
+type flightCall[T any] struct {
+	done   chan struct{}
+	val    T
+	err    error
+	cancel context.CancelFunc
+	// Guarded by Flight.mu
+	refs   int // callers still waiting for the result
+	shared int // callers that joined the call after it started
+}
+
+// NewFlight returns a Flight for requests described by name in log messages.
+func NewFlight[T any](name string) *Flight[T] {
+	return &Flight[T]{name: name, calls: make(map[string]*flightCall[T])}
+}
+
+// Do returns the result of fn. When a call with the same key is in flight,
+// it waits for that call instead of running fn. fn runs on a context of its
+// own, holding the values of the ctx of the first caller, so that caller
+// giving up does not fail the others; it is canceled once every caller
+// waiting on it returned early because its ctx was done. The result is
+// shared and must not be modified.
+func (f *Flight[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	f.mu.Lock()
+	c, ok := f.calls[key]
+	if ok {
+		c.refs++
+		c.shared++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flightCall[T]{done: make(chan struct{}), cancel: cancel, refs: 1}
+		f.calls[key] = c
+		go f.run(callCtx, key, c, fn)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		c.refs--
+		if c.refs == 0 {
+			// Nobody waits for the result anymore; later calls run again
+			c.cancel()
+			if f.calls[key] == c {
+				delete(f.calls, key)
+			}
+		}
+		f.mu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// run runs fn for call c of key and hands its result to the callers.
+func (f *Flight[T]) run(ctx context.Context, key string, c *flightCall[T], fn func(ctx context.Context) (T, error)) {
+	c.val, c.err = fn(ctx)
+	c.cancel()
+
+	f.mu.Lock()
+	if f.calls[key] == c {
+		delete(f.calls, key)
+	}
+	shared := c.shared
+	f.mu.Unlock()
+	close(c.done)
+
+	if shared > 0 {
+		total := f.shared.Add(int64(shared))
+		log.Printf("Deduplicated %d identical concurrent %s requests (%d in total)", shared, f.name, total)
+	}
+}
+
+// Shared returns how many requests were served from another request's
+// result.
+func (f *Flight[T]) Shared() int64 {
+	return f.shared.Load()
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlight_SharesConcurrentCalls(t *testing.T) {
+	f := NewFlight[int]("test")
+	release := make(chan struct{})
+	var runs atomic.Int32
+
+	const callers = 4
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = f.Do(context.Background(), "query", func(context.Context) (int, error) {
+				runs.Add(1)
+				<-release
+				return 42, nil
+			})
+		}(i)
+	}
+
+	// Wait until every caller joined the in-flight call
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f.mu.Lock()
+		c := f.calls["query"]
+		joined := c != nil && c.shared == callers-1
+		f.mu.Unlock()
+		if joined {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("callers did not join the in-flight call")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Errorf("expected one run, got %d", runs.Load())
+	}
+	for i, r := range results {
+		if r != 42 {
+			t.Errorf("caller %d got %d, expected 42", i, r)
+		}
+	}
+	if f.Shared() != callers-1 {
+		t.Errorf("expected %d shared requests, got %d", callers-1, f.Shared())
+	}
+
+	// Later calls run again
+	if _, err := f.Do(context.Background(), "query", func(context.Context) (int, error) {
+		runs.Add(1)
+		return 0, errors.New("boom")
+	}); err == nil || runs.Load() != 2 {
+		t.Errorf("expected a second run returning its error, got err=%v runs=%d", err, runs.Load())
+	}
+}
+
+func TestFlight_WaiterCancellation(t *testing.T) {
+	f := NewFlight[int]("test")
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.Do(leaderCtx, "query", func(ctx context.Context) (int, error) {
+			close(started)
+			select {
+			case <-release:
+				return 1, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		})
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Do(ctx, "query", func(context.Context) (int, error) { return 2, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancelled waiter to return context.Canceled, got %v", err)
+	}
+
+	// Cancelling the leader does not cancel the call another caller waits on
+	waiter := make(chan int, 1)
+	go func() {
+		v, _ := f.Do(context.Background(), "query", func(context.Context) (int, error) { return 2, nil })
+		waiter <- v
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f.mu.Lock()
+		refs := f.calls["query"].refs
+		f.mu.Unlock()
+		if refs == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the waiter did not join the in-flight call")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancelLeader()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled leader to return context.Canceled, got %v", err)
+	}
+	close(release)
+	if v := <-waiter; v != 1 {
+		t.Errorf("expected the waiter to get the shared result, got %d", v)
+	}
+}
+
+func TestFlight_CancelsAbandonedCall(t *testing.T) {
+	f := NewFlight[int]("test")
+	canceled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := f.Do(ctx, "query", func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the call to be canceled once its only caller left")
+	}
+}
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/session"
+	"github.com/doveaia/agentdx/store"
+)
+
+// ErrNoIndex is returned for queries against a project nothing was indexed for.
+var ErrNoIndex = errcode.New(errcode.NoIndex, "index is empty; run 'agentdx watch' to build it")
+
+// CheckIndexed returns ErrNoIndex when st holds no files yet, telling an empty
+// index from a query without matches. Failing to read the statistics is not
+// an error.
+func CheckIndexed(ctx context.Context, st store.CodeStore) error {
+	stats, err := st.GetStats(ctx)
+	if err == nil && stats.TotalFiles == 0 {
+		return ErrNoIndex
+	}
+	return nil
+}
+
+// Staleness compares the files on disk with the index.
+type Staleness struct {
+	StaleFiles    int       // files added or modified since they were indexed
+	NewestOnDisk  time.Time // newest modification time on disk
+	NewestIndexed time.Time // newest modification time recorded in the index
+	DaemonStopped bool      // no watch daemon keeps the index up to date
+	stale         map[string]bool
+	gone          []string // indexed files no longer found on disk
+}
+
+// CheckStaleness lists the files scanner would index and counts those that
+// are missing from st or were modified after they were indexed. Only file
+// metadata is read.
+func CheckStaleness(ctx context.Context, st store.CodeStore, scanner *indexer.Scanner) (*Staleness, error) {
+	indexed, err := st.ListFilesWithStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	modTimes := make(map[string]time.Time, len(indexed))
+	s := &Staleness{stale: make(map[string]bool)}
+	for _, f := range indexed {
+		modTimes[f.Path] = f.ModTime
+		if f.ModTime.After(s.NewestIndexed) {
+			s.NewestIndexed = f.ModTime
+		}
+	}
+
+	files, err := scanner.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	found := make(map[string]bool, len(files))
+	for _, f := range files {
+		path := filepath.ToSlash(f.Path)
+		found[path] = true
+		// Empty files have no chunks to index
+		if f.Size == 0 {
+			continue
+		}
+		modTime := time.Unix(f.ModTime, 0)
+		if modTime.After(s.NewestOnDisk) {
+			s.NewestOnDisk = modTime
+		}
+		if indexedAt, ok := modTimes[path]; !ok || modTime.After(indexedAt) {
+			s.stale[path] = true
+		}
+	}
+	s.StaleFiles = len(s.stale)
+	for _, f := range indexed {
+		if !found[f.Path] {
+			s.gone = append(s.gone, f.Path)
+		}
+	}
+	return s, nil
+}
+
+// Diff returns the stale files as changed and the indexed files no longer
+// found on disk as deleted, to bring the index up to date with
+// Indexer.IndexDiff.
+func (s *Staleness) Diff() *indexer.Diff {
+	diff := &indexer.Diff{Deleted: s.gone}
+	for path := range s.stale {
+		diff.Changed = append(diff.Changed, filepath.FromSlash(path))
+	}
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// Lag is how much older the newest indexed file is than the newest file on
+// disk.
+func (s *Staleness) Lag() time.Duration {
+	if !s.NewestOnDisk.After(s.NewestIndexed) {
+		return 0
+	}
+	return s.NewestOnDisk.Sub(s.NewestIndexed)
+}
+
+// Exceeds reports whether files are stale and the index lags by more than
+// threshold.
+func (s *Staleness) Exceeds(threshold time.Duration) bool {
+	return s.StaleFiles > 0 && s.Lag() > threshold
+}
+
+// IsStale reports whether the file at path changed since it was indexed.
+func (s *Staleness) IsStale(path string) bool {
+	return s.stale[path]
+}
+
+// Warning describes the staleness for display.
+func (s *Staleness) Warning() string {
+	files := "files"
+	if s.StaleFiles == 1 {
+		files = "file"
+	}
+	if s.DaemonStopped {
+		return fmt.Sprintf("index is stale: %d %s changed on disk since indexing and no watch daemon is running; results may miss recent edits, run 'agentdx session start' or search with --auto-refresh",
+			s.StaleFiles, files)
+	}
+	return fmt.Sprintf("index is stale: %d %s changed on disk since indexing (newest edit %s newer than the index); results may miss recent edits, run 'agentdx watch' to update",
+		s.StaleFiles, files, s.Lag().Round(time.Second))
+}
+
+// CheckProjectStaleness runs CheckStaleness for the files of the root project
+// or the named workspace. It returns nil when the check is disabled with a
+// negative index.search.stale_after_seconds or the index is fresh. The
+// threshold gives a running watch daemon time to catch up; without one,
+// any stale file makes the index stale.
+func CheckProjectStaleness(ctx context.Context, cfg *config.Config, projectRoot, workspace string, st store.CodeStore) (*Staleness, error) {
+	threshold := cfg.Index.Search.StaleAfterSeconds
+	if threshold < 0 {
+		return nil, nil
+	}
+
+	scanner, err := ProjectScanner(cfg, projectRoot, workspace)
+	if err != nil {
+		return nil, err
+	}
+	s, err := CheckStaleness(ctx, st, scanner)
+	if err != nil {
+		return nil, err
+	}
+	running, _ := session.NewPIDFile(projectRoot).IsProcessRunning()
+	s.DaemonStopped = !running
+	if s.DaemonStopped && s.StaleFiles > 0 {
+		return s, nil
+	}
+	if !s.Exceeds(time.Duration(threshold) * time.Second) {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// ProjectScanner returns the scanner of the files indexed for the root
+// project or the named workspace, restricted to the indexing profile
+// selected by AGENTDX_PROFILE, if any.
+func ProjectScanner(cfg *config.Config, projectRoot, workspace string) (*indexer.Scanner, error) {
+	ignore, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Index.Ignore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignore).Filter(func(relPath string) bool {
+		ws := cfg.WorkspaceFor(relPath)
+		if ws == nil {
+			return workspace == ""
+		}
+		return ws.Name == workspace
+	})
+	if name := config.SelectedIndexProfile(""); name != "" {
+		patterns, err := cfg.IndexProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		scanner = scanner.Include(patterns)
+	}
+	return scanner, nil
+}
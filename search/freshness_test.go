@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/session"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestCheckProjectStaleness(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	indexedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"fresh.go", "edited.go"} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, indexedAt, indexedAt); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SaveDocument(ctx, store.Document{Path: name, Hash: name, ModTime: indexedAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	if s, err := CheckProjectStaleness(ctx, cfg, root, "", st); err != nil || s != nil {
+		t.Fatalf("expected a fresh index, got %+v (%v)", s, err)
+	}
+
+	// An edited and a new file, both newer than the index
+	if err := os.WriteFile(filepath.Join(root, "edited.go"), []byte("package main\n\nfunc f() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "empty.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := CheckProjectStaleness(ctx, cfg, root, "", st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || s.StaleFiles != 2 {
+		t.Fatalf("expected 2 stale files, got %+v", s)
+	}
+	if !s.IsStale("edited.go") || !s.IsStale("new.go") || s.IsStale("fresh.go") {
+		t.Errorf("unexpected stale files: %v", s.stale)
+	}
+	if s.Lag() < 59*time.Minute {
+		t.Errorf("expected a lag of about an hour, got %v", s.Lag())
+	}
+
+	if !s.DaemonStopped || !strings.Contains(s.Warning(), "no watch daemon is running") {
+		t.Errorf("expected the warning to mention the stopped daemon, got %q", s.Warning())
+	}
+
+	// Without a daemon to catch up, the threshold does not hide stale files
+	cfg.Index.Search.StaleAfterSeconds = int((2 * time.Hour).Seconds())
+	if s, _ := CheckProjectStaleness(ctx, cfg, root, "", st); s == nil {
+		t.Error("expected a warning below the threshold without a running daemon")
+	}
+
+	// The threshold hides small lags of a running daemon and a negative one
+	// disables the check
+	if err := session.NewPIDFile(root).Write(os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := CheckProjectStaleness(ctx, cfg, root, "", st); s != nil {
+		t.Errorf("expected no warning below the threshold, got %+v", s)
+	}
+	cfg.Index.Search.StaleAfterSeconds = -1
+	if s, _ := CheckProjectStaleness(ctx, cfg, root, "", st); s != nil {
+		t.Errorf("expected the check to be disabled, got %+v", s)
+	}
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// GroupPackage groups search results by package (--group-by package).
+const GroupPackage = "package"
+
+// Package kinds, by the manifest that defines the package.
+const (
+	PackageGo     = "go"     // Go package: a directory of a go.mod module
+	PackageNPM    = "npm"    // npm package or workspace: a package.json
+	PackagePython = "python" // Python package: __init__.py, pyproject.toml or setup.py
+	PackageDir    = "dir"    // No manifest: the file's directory
+)
+
+// Package identifies the package or module a file belongs to.
+type Package struct {
+	Name string `json:"name"` // e.g. github.com/org/repo/billing, @org/web, billing.invoices
+	Kind string `json:"kind"`
+	Dir  string `json:"dir"` // root directory of the package, relative to the project root
+}
+
+// Group is a package with its best search results.
+type Group struct {
+	Package
+	Score   float32              `json:"score"` // score of the best result
+	Hits    int                  `json:"hits"`  // results in the package before capping
+	Results []store.SearchResult `json:"-"`
+}
+
+// jsManifestExts are the extensions of files that belong to npm packages
+var jsManifestExts = map[string]bool{
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+	".ts": true, ".tsx": true, ".mts": true, ".cts": true,
+	".vue": true, ".svelte": true, ".astro": true,
+}
+
+// PackageResolver derives the package of files from their path and the
+// manifests found in their directories. Manifests are read once per
+// directory.
+type PackageResolver struct {
+	root     string
+	goMods   map[string]string // directory -> module path ("" when none)
+	npmNames map[string]string // directory -> package name ("" when none)
+	exists   map[string]bool   // relative path -> file exists
+}
+
+// NewPackageResolver creates a resolver for files of the project at root.
+func NewPackageResolver(root string) *PackageResolver {
+	return &PackageResolver{
+		root:     root,
+		goMods:   make(map[string]string),
+		npmNames: make(map[string]string),
+		exists:   make(map[string]bool),
+	}
+}
+
+// Resolve returns the package of a file, given relative to the project root.
+func (r *PackageResolver) Resolve(file string) Package {
+	file = filepath.ToSlash(file)
+	dir := path.Dir(file)
+	ext := strings.ToLower(path.Ext(file))
+
+	switch {
+	case ext == ".go":
+		for d := dir; ; d = path.Dir(d) {
+			if module := r.goModule(d); module != "" {
+				return Package{Name: joinImportPath(module, relDir(d, dir)), Kind: PackageGo, Dir: dir}
+			}
+			if d == "." {
+				break
+			}
+		}
+	case jsManifestExts[ext]:
+		for d := dir; ; d = path.Dir(d) {
+			if name, ok := r.npmPackage(d); ok {
+				return Package{Name: name, Kind: PackageNPM, Dir: d}
+			}
+			if d == "." {
+				break
+			}
+		}
+	case ext == ".py" || ext == ".pyi":
+		if pkg, ok := r.pythonPackage(dir); ok {
+			return pkg
+		}
+	}
+	return Package{Name: dir, Kind: PackageDir, Dir: dir}
+}
+
+// goModule returns the module path declared by the go.mod in dir, if any.
+func (r *PackageResolver) goModule(dir string) string {
+	if module, ok := r.goMods[dir]; ok {
+		return module
+	}
+	module := ""
+	if f, err := os.Open(filepath.Join(r.root, filepath.FromSlash(dir), "go.mod")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+				module = strings.Trim(strings.TrimSpace(rest), `"`)
+				break
+			}
+		}
+		f.Close()
+	}
+	r.goMods[dir] = module
+	return module
+}
+
+// npmPackage returns the name of the package.json in dir, falling back to
+// the directory for unnamed packages. It reports whether dir has one.
+func (r *PackageResolver) npmPackage(dir string) (string, bool) {
+	if name, ok := r.npmNames[dir]; ok {
+		return name, name != ""
+	}
+	name := ""
+	if data, err := os.ReadFile(filepath.Join(r.root, filepath.FromSlash(dir), "package.json")); err == nil {
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(data, &manifest)
+		name = manifest.Name
+		if name == "" {
+			name = dir
+		}
+	}
+	r.npmNames[dir] = name
+	return name, name != ""
+}
+
+// pythonPackage returns the dotted module of the Python package in dir: the
+// chain of directories with an __init__.py, or the path below the nearest
+// pyproject.toml or setup.py (without a src/ layout prefix).
+func (r *PackageResolver) pythonPackage(dir string) (Package, bool) {
+	if r.hasFile(dir, "__init__.py") {
+		top := dir
+		for top != "." && r.hasFile(path.Dir(top), "__init__.py") {
+			top = path.Dir(top)
+		}
+		module := relDir(path.Dir(top), dir)
+		return Package{Name: strings.ReplaceAll(module, "/", "."), Kind: PackagePython, Dir: dir}, true
+	}
+
+	for d := dir; ; d = path.Dir(d) {
+		if r.hasFile(d, "pyproject.toml") || r.hasFile(d, "setup.py") {
+			module := relDir(d, dir)
+			if module == "src" || strings.HasPrefix(module, "src/") {
+				module = strings.TrimPrefix(strings.TrimPrefix(module, "src"), "/")
+			}
+			if module == "" || module == "." {
+				// Modules at the top of the project are named after it
+				module = path.Base(d)
+				if d == "." {
+					module = filepath.Base(r.root)
+				}
+			}
+			return Package{Name: strings.ReplaceAll(module, "/", "."), Kind: PackagePython, Dir: dir}, true
+		}
+		if d == "." {
+			return Package{}, false
+		}
+	}
+}
+
+// hasFile reports whether dir contains the named file.
+func (r *PackageResolver) hasFile(dir, name string) bool {
+	p := path.Join(dir, name)
+	if ok, cached := r.exists[p]; cached {
+		return ok
+	}
+	_, err := os.Stat(filepath.Join(r.root, filepath.FromSlash(p)))
+	r.exists[p] = err == nil
+	return err == nil
+}
+
+// relDir returns dir relative to base, both relative to the project root.
+func relDir(base, dir string) string {
+	if base == "." {
+		return dir
+	}
+	if dir == base {
+		return "."
+	}
+	return strings.TrimPrefix(dir, base+"/")
+}
+
+// joinImportPath appends a package directory to a Go module path.
+func joinImportPath(module, dir string) string {
+	if dir == "." {
+		return module
+	}
+	return module + "/" + dir
+}
+
+// GroupByPackage clusters ranked results by package. Groups are ordered by
+// their best result and keep their perGroup best results; at most limit
+// groups are returned.
+func GroupByPackage(resolver *PackageResolver, results []store.SearchResult, limit, perGroup int) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, r := range results {
+		pkg := resolver.Resolve(r.Chunk.FilePath)
+		key := pkg.Kind + ":" + pkg.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Package: pkg, Score: r.Score})
+		}
+		g := &groups[i]
+		g.Hits++
+		g.Score = max(g.Score, r.Score)
+		g.Results = append(g.Results, r)
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].Score > groups[b].Score
+	})
+	if limit > 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+	for i := range groups {
+		if perGroup > 0 && len(groups[i].Results) > perGroup {
+			groups[i].Results = groups[i].Results[:perGroup]
+		}
+	}
+	return groups
+}
+
+// FlattenGroups returns the results of groups in display order. The groups
+// then share the returned slice, so updates to its results, such as
+// attached notes, show in the groups.
+func FlattenGroups(groups []Group) []store.SearchResult {
+	var results []store.SearchResult
+	for _, g := range groups {
+		results = append(results, g.Results...)
+	}
+	start := 0
+	for i := range groups {
+		n := len(groups[i].Results)
+		groups[i].Results = results[start : start+n : start+n]
+		start += n
+	}
+	return results
+}
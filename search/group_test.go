@@ -0,0 +1,97 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// writeFiles creates files with the given content under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPackageResolver(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                          "// comment\nmodule example.com/shop\n\ngo 1.22\n",
+		"tools/go.mod":                    "module \"example.com/shop/tools\"\n",
+		"web/package.json":                `{"name": "@shop/web"}`,
+		"apps/admin/package.json":         `{"private": true}`,
+		"py/pyproject.toml":               "[project]\n",
+		"py/src/payments/__init__.py":     "",
+		"py/src/payments/api/__init__.py": "",
+		"scripts/pyproject.toml":          "[project]\n",
+	})
+
+	tests := []struct {
+		file string
+		want Package
+	}{
+		{"main.go", Package{Name: "example.com/shop", Kind: PackageGo, Dir: "."}},
+		{"billing/charge.go", Package{Name: "example.com/shop/billing", Kind: PackageGo, Dir: "billing"}},
+		{"tools/lint/main.go", Package{Name: "example.com/shop/tools/lint", Kind: PackageGo, Dir: "tools/lint"}},
+		{"web/src/pages/billing.tsx", Package{Name: "@shop/web", Kind: PackageNPM, Dir: "web"}},
+		{"apps/admin/index.js", Package{Name: "apps/admin", Kind: PackageNPM, Dir: "apps/admin"}},
+		{"py/src/payments/api/views.py", Package{Name: "payments.api", Kind: PackagePython, Dir: "py/src/payments/api"}},
+		{"py/src/cli/main.py", Package{Name: "cli", Kind: PackagePython, Dir: "py/src/cli"}},
+		{"scripts/release.py", Package{Name: "scripts", Kind: PackagePython, Dir: "scripts"}},
+		{"docs/billing.md", Package{Name: "docs", Kind: PackageDir, Dir: "docs"}},
+		{"web/README.md", Package{Name: "web", Kind: PackageDir, Dir: "web"}},
+	}
+
+	resolver := NewPackageResolver(root)
+	for _, tt := range tests {
+		if got := resolver.Resolve(tt.file); got != tt.want {
+			t.Errorf("Resolve(%q) = %+v, want %+v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByPackage(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": "module example.com/shop\n"})
+
+	results := []store.SearchResult{
+		chunkResult("billing/charge.go", 1, 5, "func Charge()", 0.9),
+		chunkResult("docs/billing.md", 1, 5, "# Billing", 0.8),
+		chunkResult("billing/refund.go", 1, 5, "func Refund()", 0.7),
+		chunkResult("billing/invoice.go", 1, 5, "func Invoice()", 0.6),
+		chunkResult("api/handler.go", 1, 5, "func Handle()", 0.5),
+	}
+
+	groups := GroupByPackage(NewPackageResolver(root), results, 2, 2)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d: %+v", len(groups), groups)
+	}
+	billing := groups[0]
+	if billing.Name != "example.com/shop/billing" || billing.Hits != 3 || billing.Score != 0.9 {
+		t.Errorf("unexpected first group: %+v", billing.Package)
+	}
+	if len(billing.Results) != 2 || billing.Results[1].Chunk.FilePath != "billing/refund.go" {
+		t.Errorf("expected the 2 best billing results, got %+v", billing.Results)
+	}
+	if groups[1].Name != "docs" {
+		t.Errorf("expected docs second, got %s", groups[1].Name)
+	}
+
+	flat := FlattenGroups(groups)
+	if len(flat) != 3 {
+		t.Fatalf("expected 3 flattened results, got %d", len(flat))
+	}
+	flat[2].Notes = []store.Note{{Text: "shared"}}
+	if len(groups[1].Results[0].Notes) != 1 {
+		t.Error("expected groups to share the flattened results")
+	}
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// AddHighlights sets the spans of each result matching the query. Terms
+// are expanded like the store expands them for cfg.
+func AddHighlights(results []store.SearchResult, query string, cfg config.SearchConfig) {
+	words := highlightWords(store.MatchTerms(query, cfg.CJKBigrams, SplitFunc(cfg.Identifiers) != nil, ExpandFunc(cfg.Expansion)))
+	for i := range results {
+		results[i].Highlights = Highlights(results[i].Chunk.Content, results[i].Chunk.StartLine, words)
+	}
+}
+
+// Highlights returns the spans of chunk content matching words. Like the
+// FTS queries, a word matches the start of a token, ignoring case; CJK words
+// match anywhere in a run of CJK text. Lines are numbered from startLine,
+// after the "File:" header.
+func Highlights(content string, startLine int, words []string) []store.Highlight {
+	if len(words) == 0 {
+		return nil
+	}
+	var out []store.Highlight
+	for i, line := range strings.Split(stripFileHeader(content), "\n") {
+		for _, span := range matchSpans(line, words) {
+			out = append(out, store.Highlight{Line: startLine + i, StartCol: span[0] + 1, EndCol: span[1] + 1})
+		}
+	}
+	return out
+}
+
+// highlightWords splits match terms into the words the FTS tokenizers see,
+// runs of letters and digits, longest first so that the longest prefix of
+// a token is highlighted.
+func highlightWords(terms []string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, term := range terms {
+		for _, word := range strings.FieldsFunc(term, isNotTokenRune) {
+			if key := strings.ToLower(word); !seen[key] {
+				seen[key] = true
+				words = append(words, word)
+			}
+		}
+	}
+	sort.SliceStable(words, func(a, b int) bool { return len(words[a]) > len(words[b]) })
+	return words
+}
+
+// isNotTokenRune reports whether r separates tokens.
+func isNotTokenRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// matchSpans returns the sorted, non-overlapping byte spans of line
+// matching words.
+func matchSpans(line string, words []string) [][2]int {
+	var spans [][2]int
+	tokenStart := -1
+	check := func(end int) {
+		token := line[tokenStart:end]
+		for _, word := range words {
+			if store.HasCJK(word) {
+				for off := 0; ; {
+					idx := strings.Index(token[off:], word)
+					if idx < 0 {
+						break
+					}
+					start := tokenStart + off + idx
+					spans = append(spans, [2]int{start, start + len(word)})
+					off += idx + len(word)
+				}
+				continue
+			}
+			if len(word) <= len(token) && strings.EqualFold(token[:len(word)], word) {
+				spans = append(spans, [2]int{tokenStart, tokenStart + len(word)})
+				break
+			}
+		}
+	}
+
+	for i, r := range line {
+		if isNotTokenRune(r) {
+			if tokenStart >= 0 {
+				check(i)
+				tokenStart = -1
+			}
+			continue
+		}
+		if tokenStart < 0 {
+			tokenStart = i
+		}
+	}
+	if tokenStart >= 0 {
+		check(len(line))
+	}
+	return mergeSpans(spans)
+}
+
+// mergeSpans sorts spans and merges the overlapping ones, such as the
+// spans of overlapping CJK bigrams.
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) < 2 {
+		return spans
+	}
+	sort.Slice(spans, func(a, b int) bool { return spans[a][0] < spans[b][0] })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s[0] <= last[1] {
+			last[1] = max(last[1], s[1])
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// MarkLine renders line number lineNum of a result, passing the highlighted
+// spans through mark and the rest of the line through plain.
+func MarkLine(line string, lineNum int, highlights []store.Highlight, plain, mark func(string) string) string {
+	var b strings.Builder
+	pos := 0
+	for _, h := range highlights {
+		start, end := h.StartCol-1, h.EndCol-1
+		if h.Line != lineNum || start < pos || end > len(line) || start >= end {
+			continue
+		}
+		b.WriteString(plain(line[pos:start]))
+		b.WriteString(mark(line[start:end]))
+		pos = end
+	}
+	b.WriteString(plain(line[pos:]))
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestHighlights(t *testing.T) {
+	content := "File: auth/login.go\n\nfunc HandleLogin(user string) error {\n\t// log the login_attempt\n\treturn nil\n}"
+	words := highlightWords([]string{"login", "handle_login"})
+
+	got := Highlights(content, 10, words)
+	want := []store.Highlight{
+		{Line: 10, StartCol: 6, EndCol: 12},  // Handle of HandleLogin
+		{Line: 11, StartCol: 13, EndCol: 18}, // login of login_attempt, not log
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Highlights() = %+v, want %+v", got, want)
+	}
+
+	if got := Highlights(content, 10, nil); got != nil {
+		t.Errorf("expected no highlights without terms, got %+v", got)
+	}
+}
+
+func TestHighlights_CJK(t *testing.T) {
+	got := Highlights("连接数据库失败", 1, highlightWords([]string{"数据", "据库"}))
+	want := []store.Highlight{{Line: 1, StartCol: 7, EndCol: 16}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected overlapping bigrams to merge, got %+v", got)
+	}
+}
+
+func TestMarkLine(t *testing.T) {
+	line := "a <b> login"
+	highlights := []store.Highlight{{Line: 3, StartCol: 3, EndCol: 6}, {Line: 3, StartCol: 7, EndCol: 12}, {Line: 4, StartCol: 1, EndCol: 2}}
+	got := MarkLine(line, 3, highlights, strings.ToUpper, func(s string) string { return "[" + s + "]" })
+	if got != "A [<b>] [login]" {
+		t.Errorf("MarkLine() = %q", got)
+	}
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+var (
+	functionDeclPattern = regexp.MustCompile(`^(export\s+)?(pub(\([\w:]+\))?\s+)?(async\s+)?(func|def|function|fn)\b|^(public|private|protected|static)\s[\w<>\[\],\s]*\w+\s*\(`)
+	typeDeclPattern     = regexp.MustCompile(`^(export\s+)?(pub(\([\w:]+\))?\s+)?(abstract\s+)?(type|class|interface|struct|enum|trait)\s+\w+`)
+	commentPrefixes     = []string{"//", "#", "/*", "*", "--", `"""`, "'''", "<!--"}
+)
+
+// ChunkKind classifies a chunk by its dominant content: comment when at
+// least half of its non-blank lines are comments, else function or type
+// when it declares one (functions first), else code.
+func ChunkKind(content string) string {
+	var lines, comments, functions, types int
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines++
+		switch {
+		case isCommentLine(line):
+			comments++
+		case functionDeclPattern.MatchString(line):
+			functions++
+		case typeDeclPattern.MatchString(line):
+			types++
+		}
+	}
+
+	switch {
+	case lines > 0 && comments*2 >= lines:
+		return config.KindComment
+	case functions > 0:
+		return config.KindFunction
+	case types > 0:
+		return config.KindType
+	default:
+		return config.KindCode
+	}
+}
+
+// isCommentLine reports whether a trimmed line is a comment.
+func isCommentLine(line string) bool {
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+)
+
+func TestChunkKind(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"// Package auth handles logins.\n// It wraps the session store.\npackage auth\n", config.KindComment},
+		{"func Login(user string) error {\n\treturn nil\n}\n", config.KindFunction},
+		{"export async function login(user) {\n  return api.post(user)\n}\n", config.KindFunction},
+		{"def login(user):\n    return None\n", config.KindFunction},
+		{"    public User findById(long id) {\n        return repo.get(id);\n    }\n", config.KindFunction},
+		{"type Session struct {\n\tID string\n\tUser string\n}\n", config.KindType},
+		{"class Session:\n    id = None\n", config.KindType},
+		{"var defaultTimeout = 30\nconst maxRetries = 3\n", config.KindCode},
+		{"", config.KindCode},
+	}
+	for _, tt := range tests {
+		if got := ChunkKind(tt.content); got != tt.want {
+			t.Errorf("ChunkKind(%q) = %s, want %s", tt.content, got, tt.want)
+		}
+	}
+}
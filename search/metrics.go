@@ -0,0 +1,128 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// HashQuery identifies a query in the metrics without storing its text.
+// Case and surrounding whitespace are ignored.
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(query))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RecordQuery logs a query of kind from source (cli or mcp) to st when cfg
+// enables metrics, and returns its ID or 0 when nothing was recorded.
+// Metrics are best effort: failures are logged and never fail the query.
+func RecordQuery(ctx context.Context, cfg config.MetricsConfig, st store.MetricsStore, kind, source, query string, latency time.Duration, results int) int64 {
+	if !cfg.Enabled {
+		return 0
+	}
+	record := store.QueryRecord{
+		Kind:      kind,
+		Source:    source,
+		QueryHash: HashQuery(query),
+		LatencyMs: latency.Milliseconds(),
+		Results:   results,
+		CreatedAt: time.Now(),
+	}
+	if cfg.StoreQueries {
+		record.Query = query
+	}
+	id, err := st.RecordQuery(ctx, record)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return 0
+	}
+	return id
+}
+
+// QueryStats summarizes the recorded queries of one kind.
+type QueryStats struct {
+	Kind         string `json:"kind"`
+	Queries      int    `json:"queries"`
+	ZeroResults  int    `json:"zero_results"`
+	FollowedUp   int    `json:"followed_up"`
+	AvgLatencyMs int64  `json:"avg_latency_ms"`
+	P95LatencyMs int64  `json:"p95_latency_ms"`
+}
+
+// EmptyQuery is a query that returned no results, with how often it did.
+type EmptyQuery struct {
+	Kind      string    `json:"kind"`
+	QueryHash string    `json:"query_hash"`
+	Query     string    `json:"query,omitempty"`
+	Count     int       `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SummarizeQueries aggregates records per kind and lists the queries that
+// returned nothing, most frequent first.
+func SummarizeQueries(records []store.QueryRecord) ([]QueryStats, []EmptyQuery) {
+	latencies := make(map[string][]int64)
+	byKind := make(map[string]*QueryStats)
+	empty := make(map[string]*EmptyQuery)
+	for _, r := range records {
+		st, ok := byKind[r.Kind]
+		if !ok {
+			st = &QueryStats{Kind: r.Kind}
+			byKind[r.Kind] = st
+		}
+		st.Queries++
+		if r.FollowedUp {
+			st.FollowedUp++
+		}
+		latencies[r.Kind] = append(latencies[r.Kind], r.LatencyMs)
+		if r.Results > 0 {
+			continue
+		}
+		st.ZeroResults++
+
+		key := r.Kind + "/" + r.QueryHash
+		e, ok := empty[key]
+		if !ok {
+			e = &EmptyQuery{Kind: r.Kind, QueryHash: r.QueryHash}
+			empty[key] = e
+		}
+		e.Count++
+		e.LastSeen = r.CreatedAt
+		if r.Query != "" {
+			e.Query = r.Query
+		}
+	}
+
+	stats := make([]QueryStats, 0, len(byKind))
+	for kind, st := range byKind {
+		lat := latencies[kind]
+		slices.Sort(lat)
+		var total int64
+		for _, l := range lat {
+			total += l
+		}
+		st.AvgLatencyMs = total / int64(len(lat))
+		st.P95LatencyMs = lat[(len(lat)*95+99)/100-1]
+		stats = append(stats, *st)
+	}
+	slices.SortFunc(stats, func(a, b QueryStats) int { return strings.Compare(a.Kind, b.Kind) })
+
+	empties := make([]EmptyQuery, 0, len(empty))
+	for _, e := range empty {
+		empties = append(empties, *e)
+	}
+	slices.SortFunc(empties, func(a, b EmptyQuery) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+	return stats, empties
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestHashQuery(t *testing.T) {
+	if HashQuery("  Auth Flow ") != HashQuery("auth flow") {
+		t.Error("expected case and surrounding whitespace to be ignored")
+	}
+	if HashQuery("auth flow") == HashQuery("auth") {
+		t.Error("expected different queries to hash differently")
+	}
+}
+
+func TestSummarizeQueries(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []store.QueryRecord{
+		{Kind: store.QueryKindSearch, QueryHash: "a", LatencyMs: 10, Results: 5, FollowedUp: true, CreatedAt: now},
+		{Kind: store.QueryKindSearch, QueryHash: "b", LatencyMs: 20, CreatedAt: now.Add(time.Minute)},
+		{Kind: store.QueryKindSearch, QueryHash: "c", Query: "retry", LatencyMs: 30, CreatedAt: now.Add(2 * time.Minute)},
+		{Kind: store.QueryKindSearch, QueryHash: "c", LatencyMs: 100, CreatedAt: now.Add(3 * time.Minute)},
+		{Kind: store.QueryKindTrace, QueryHash: "d", LatencyMs: 5, Results: 2, CreatedAt: now},
+	}
+
+	stats, empties := SummarizeQueries(records)
+	if len(stats) != 2 || stats[0].Kind != store.QueryKindSearch || stats[1].Kind != store.QueryKindTrace {
+		t.Fatalf("stats = %+v, want search then trace", stats)
+	}
+	s := stats[0]
+	if s.Queries != 4 || s.ZeroResults != 3 || s.FollowedUp != 1 || s.AvgLatencyMs != 40 || s.P95LatencyMs != 100 {
+		t.Errorf("search stats = %+v", s)
+	}
+
+	if len(empties) != 2 {
+		t.Fatalf("empties = %+v, want 2", empties)
+	}
+	if e := empties[0]; e.QueryHash != "c" || e.Count != 2 || e.Query != "retry" || !e.LastSeen.Equal(now.Add(3*time.Minute)) {
+		t.Errorf("most frequent empty query = %+v", e)
+	}
+	if empties[1].QueryHash != "b" {
+		t.Errorf("second empty query = %+v, want b", empties[1])
+	}
+}
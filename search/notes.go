@@ -0,0 +1,32 @@
+package search
+
+import (
+	"context"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// AttachNotes sets the notes overlapping each result's line range. Notes are
+// loaded once per file.
+func AttachNotes(ctx context.Context, notes store.NoteStore, results []store.SearchResult) error {
+	byFile := make(map[string][]store.Note)
+	for i := range results {
+		chunk := results[i].Chunk
+		fileNotes, ok := byFile[chunk.FilePath]
+		if !ok {
+			var err error
+			if fileNotes, err = notes.ListNotes(ctx, chunk.FilePath); err != nil {
+				return err
+			}
+			byFile[chunk.FilePath] = fileNotes
+		}
+
+		results[i].Notes = nil
+		for _, n := range fileNotes {
+			if n.Overlaps(chunk.StartLine, chunk.EndLine) {
+				results[i].Notes = append(results[i].Notes, n)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+type fakeNoteStore struct {
+	notes []store.Note
+	calls int
+}
+
+func (f *fakeNoteStore) AddNote(ctx context.Context, note store.Note) (store.Note, error) {
+	return note, nil
+}
+
+func (f *fakeNoteStore) ListNotes(ctx context.Context, filePath string) ([]store.Note, error) {
+	f.calls++
+	var notes []store.Note
+	for _, n := range f.notes {
+		if n.FilePath == filePath {
+			notes = append(notes, n)
+		}
+	}
+	return notes, nil
+}
+
+func (f *fakeNoteStore) DeleteNote(ctx context.Context, id int64) error {
+	return nil
+}
+
+func TestAttachNotes(t *testing.T) {
+	notes := &fakeNoteStore{notes: []store.Note{
+		{ID: 1, FilePath: "main.go", StartLine: 10, EndLine: 12, Text: "real entrypoint"},
+		{ID: 2, FilePath: "main.go", StartLine: 40, EndLine: 40, Text: "legacy"},
+	}}
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "main.go", StartLine: 1, EndLine: 10}},
+		{Chunk: store.Chunk{FilePath: "main.go", StartLine: 20, EndLine: 30}},
+		{Chunk: store.Chunk{FilePath: "other.go", StartLine: 1, EndLine: 50}},
+	}
+
+	if err := AttachNotes(context.Background(), notes, results); err != nil {
+		t.Fatalf("AttachNotes failed: %v", err)
+	}
+
+	if len(results[0].Notes) != 1 || results[0].Notes[0].ID != 1 {
+		t.Errorf("expected note 1 on first result, got %+v", results[0].Notes)
+	}
+	if len(results[1].Notes) != 0 || len(results[2].Notes) != 0 {
+		t.Errorf("expected no notes on other results, got %+v / %+v", results[1].Notes, results[2].Notes)
+	}
+	if notes.calls != 2 {
+		t.Errorf("expected notes to be loaded once per file, got %d calls", notes.calls)
+	}
+}
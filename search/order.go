@@ -0,0 +1,49 @@
+package search
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+// testPathMarkers identify test files across common languages.
+var testPathMarkers = []string{"/test/", "/tests/", "__tests__", "_test.", ".test.", ".spec."}
+
+// SortResults orders results by score (highest first) and breaks ties
+// deterministically by file path and start line. When preferSource is true,
+// non-test files are placed before test files with the same score.
+func SortResults(results []store.SearchResult, preferSource bool) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return lessResult(results[i], results[j], preferSource)
+	})
+}
+
+// lessResult reports whether a should be ordered before b.
+func lessResult(a, b store.SearchResult, preferSource bool) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if preferSource {
+		aTest, bTest := IsTestFile(a.Chunk.FilePath), IsTestFile(b.Chunk.FilePath)
+		if aTest != bTest {
+			return !aTest
+		}
+	}
+	if a.Chunk.FilePath != b.Chunk.FilePath {
+		return a.Chunk.FilePath < b.Chunk.FilePath
+	}
+	return a.Chunk.StartLine < b.Chunk.StartLine
+}
+
+// IsTestFile reports whether a path looks like a test file.
+func IsTestFile(filePath string) bool {
+	p := "/" + filePath
+	for _, marker := range testPathMarkers {
+		if strings.Contains(p, marker) {
+			return true
+		}
+	}
+	return strings.HasPrefix(path.Base(p), "test_")
+}
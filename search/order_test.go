@@ -0,0 +1,72 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/store"
+)
+
+func result(path string, line int, score float32) store.SearchResult {
+	return store.SearchResult{Chunk: store.Chunk{FilePath: path, StartLine: line}, Score: score}
+}
+
+func TestSortResults_DeterministicTiebreak(t *testing.T) {
+	results := []store.SearchResult{
+		result("b.go", 10, 0.5),
+		result("a.go", 20, 0.5),
+		result("c.go", 1, 0.9),
+		result("a.go", 5, 0.5),
+	}
+
+	SortResults(results, false)
+
+	want := []struct {
+		path string
+		line int
+	}{{"c.go", 1}, {"a.go", 5}, {"a.go", 20}, {"b.go", 10}}
+	for i, w := range want {
+		if results[i].Chunk.FilePath != w.path || results[i].Chunk.StartLine != w.line {
+			t.Errorf("position %d: got %s:%d, want %s:%d", i, results[i].Chunk.FilePath, results[i].Chunk.StartLine, w.path, w.line)
+		}
+	}
+}
+
+func TestSortResults_PreferSource(t *testing.T) {
+	results := []store.SearchResult{
+		result("auth/login_test.go", 1, 0.5),
+		result("auth/login.go", 1, 0.5),
+		result("aaa_test.go", 1, 0.8),
+	}
+
+	SortResults(results, true)
+	if results[1].Chunk.FilePath != "auth/login.go" {
+		t.Errorf("expected source file before test on tie, got %s", results[1].Chunk.FilePath)
+	}
+	if results[0].Chunk.FilePath != "aaa_test.go" {
+		t.Errorf("higher score should still win, got %s", results[0].Chunk.FilePath)
+	}
+
+	SortResults(results, false)
+	if results[1].Chunk.FilePath != "auth/login.go" {
+		t.Errorf("expected path order without preference, got %s", results[1].Chunk.FilePath)
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"pkg/foo_test.go":          true,
+		"src/app.spec.ts":          true,
+		"src/app.test.js":          true,
+		"tests/helpers.py":         true,
+		"pkg/test_utils.py":        true,
+		"web/__tests__/x.js":       true,
+		"pkg/foo.go":               false,
+		"pkg/contest/entry.go":     false,
+		"internal/testing_util.go": false,
+	}
+	for path, want := range tests {
+		if got := IsTestFile(path); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
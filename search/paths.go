@@ -0,0 +1,68 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Path styles for file paths shown in results.
+const (
+	PathStyleRepo     = "repo"     // relative to the project root (default)
+	PathStyleAbsolute = "absolute" // absolute path on this machine
+	PathStyleCWD      = "cwd"      // relative to the current directory
+)
+
+// Paths resolves indexed paths, which are relative to the project root, for
+// output.
+type Paths struct {
+	root  string
+	style string
+	cwd   string
+}
+
+// NewPaths returns a resolver for paths under projectRoot shown in the given
+// style. An empty style means PathStyleRepo.
+func NewPaths(projectRoot, style string) (*Paths, error) {
+	p := &Paths{root: projectRoot, style: style}
+	switch style {
+	case "":
+		p.style = PathStyleRepo
+	case PathStyleRepo, PathStyleAbsolute:
+	case PathStyleCWD:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		p.cwd = cwd
+	default:
+		return nil, fmt.Errorf("invalid path style %q: use %s, %s or %s", style, PathStyleRepo, PathStyleAbsolute, PathStyleCWD)
+	}
+	return p, nil
+}
+
+// Abs returns the absolute path of an indexed file.
+func (p *Paths) Abs(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.root, filepath.FromSlash(path))
+}
+
+// Display returns an indexed path in the configured style.
+func (p *Paths) Display(path string) string {
+	if path == "" {
+		return path
+	}
+	switch p.style {
+	case PathStyleAbsolute:
+		return p.Abs(path)
+	case PathStyleCWD:
+		rel, err := filepath.Rel(p.cwd, p.Abs(path))
+		if err != nil {
+			return p.Abs(path)
+		}
+		return rel
+	}
+	return path
+}
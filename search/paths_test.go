@@ -0,0 +1,43 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "cmd")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	abs := filepath.Join(root, "internal", "auth.go")
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"", "internal/auth.go"},
+		{PathStyleRepo, "internal/auth.go"},
+		{PathStyleAbsolute, abs},
+		{PathStyleCWD, filepath.Join("..", "internal", "auth.go")},
+	}
+	for _, tt := range tests {
+		paths, err := NewPaths(root, tt.style)
+		if err != nil {
+			t.Fatalf("NewPaths(%q) failed: %v", tt.style, err)
+		}
+		if got := paths.Display("internal/auth.go"); got != tt.want {
+			t.Errorf("Display with style %q = %q, want %q", tt.style, got, tt.want)
+		}
+		if got := paths.Abs("internal/auth.go"); got != abs {
+			t.Errorf("Abs = %q, want %q", got, abs)
+		}
+	}
+
+	if _, err := NewPaths(root, "relative"); err == nil {
+		t.Error("expected an error for an unknown path style")
+	}
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// CandidateLimit returns how many FTS results to fetch so that, after merging
+// and per-file capping, enough remain to fill limit, or the reranker
+// candidates when reranking is enabled.
+func CandidateLimit(limit int, cfg config.SearchConfig) int {
+	limit = RerankLimit(limit, cfg)
+	if cfg.MaxPerFile > 0 {
+		return limit * 4
+	}
+	return limit * 2
+}
+
+// Rank post-processes raw FTS results: it applies structural boosting, orders
+// ties deterministically, merges overlapping chunks of the same file, caps
+// results per file and trims to limit.
+func Rank(results []store.SearchResult, cfg config.SearchConfig, limit int) []store.SearchResult {
+	results = ApplyBoost(results, cfg.Boost)
+	SortResults(results, cfg.PreferSourceOnTies)
+
+	results = MergeOverlapping(results)
+	results = CapPerFile(results, cfg.MaxPerFile)
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// RankAndRerank ranks results like Rank, then reorders the top candidates
+// with the reranker when index.search.rerank is enabled. When the reranker
+// fails, the results keep their full-text order and the error is returned
+// with them.
+func RankAndRerank(ctx context.Context, query string, results []store.SearchResult, cfg config.SearchConfig, limit int) ([]store.SearchResult, error) {
+	results = Rank(results, cfg, RerankLimit(limit, cfg))
+	return Rerank(ctx, query, results, cfg.Rerank, limit)
+}
+
+// Request is a search run by Run.
+type Request struct {
+	Query    string
+	Filter   store.SearchFilter
+	Limit    int     // results, or package groups with PerGroup
+	MinScore float32 // drops results scoring less; 0 keeps all
+	PerGroup int     // results kept per package; 0 does not group
+	Blame    bool    // annotate results with the last commit of their lines
+	// ProjectRoot resolves notebook cells, packages and blame
+	ProjectRoot string
+}
+
+// Response holds the results of a Request.
+type Response struct {
+	Results    []store.SearchResult
+	Groups     []Group // the results by package, with Request.PerGroup
+	Confidence string  // see AddConfidence
+	// Warnings are the failures the results do without: the reranker, which
+	// leaves the full-text order, notes, notebook cells and blame
+	Warnings []error
+}
+
+// WarningTexts returns the messages of the warnings of r.
+func (r *Response) WarningTexts() []string {
+	var texts []string
+	for _, err := range r.Warnings {
+		texts = append(texts, err.Error())
+	}
+	return texts
+}
+
+// Run searches st for req the same way for every transport: it ranks the
+// full-text matches and reranks them, drops weak matches and rates the
+// others, groups them by package, then attaches notes, notebook cells,
+// highlights and, when asked, blame.
+func Run(ctx context.Context, st store.SearchStore, cfg config.SearchConfig, req Request) (*Response, error) {
+	// Grouped searches rank enough results to fill every group
+	limit := req.Limit
+	if req.PerGroup > 0 {
+		limit = req.Limit * req.PerGroup
+	}
+	results, err := st.SearchFiltered(ctx, req.Query, CandidateLimit(limit, cfg), req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{}
+	results, err = RankAndRerank(ctx, req.Query, results, cfg, limit)
+	if err != nil {
+		resp.Warnings = append(resp.Warnings, err)
+	}
+	results = MinScore(results, req.MinScore)
+	resp.Confidence = AddConfidence(results)
+	if req.PerGroup > 0 {
+		resp.Groups = GroupByPackage(NewPackageResolver(req.ProjectRoot), results, req.Limit, req.PerGroup)
+		results = FlattenGroups(resp.Groups)
+	}
+
+	if err := AttachNotes(ctx, st, results); err != nil {
+		resp.Warnings = append(resp.Warnings, fmt.Errorf("failed to load notes: %w", err))
+	}
+	if err := AddCells(ctx, st, req.ProjectRoot, results); err != nil {
+		resp.Warnings = append(resp.Warnings, err)
+	}
+	AddHighlights(results, req.Query, cfg)
+	if req.Blame {
+		if err := AddBlame(ctx, req.ProjectRoot, results); err != nil {
+			resp.Warnings = append(resp.Warnings, err)
+		}
+	}
+	// Out of time, the warnings above are the cancellation, not failures to
+	// do without
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp.Results = results
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	st, err := store.NewSQLiteFTSStore(ctx, filepath.Join(t.TempDir(), "index.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	now := time.Now()
+	chunks := []store.Chunk{
+		{ID: "a1", FilePath: "auth/login.go", StartLine: 1, EndLine: 20, Content: "func Login() { session token }", Hash: "a1", UpdatedAt: now},
+		{ID: "a2", FilePath: "auth/logout.go", StartLine: 1, EndLine: 20, Content: "func Logout() { session token }", Hash: "a2", UpdatedAt: now},
+		{ID: "b1", FilePath: "web/handler.go", StartLine: 1, EndLine: 20, Content: "func Handle() { session }", Hash: "b1", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddNote(ctx, store.Note{FilePath: "web/handler.go", StartLine: 2, EndLine: 2, Text: "entrypoint"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing reranker leaves the full-text order and a warning
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+	cfg := config.SearchConfig{Rerank: config.RerankConfig{Enabled: true, Endpoint: server.URL, Candidates: 10, TimeoutMs: 1000}}
+
+	resp, err := Run(ctx, st, cfg, Request{Query: "session", Limit: 10, ProjectRoot: root})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(resp.Results) != 3 || len(resp.Warnings) != 1 || resp.Confidence == "" {
+		t.Fatalf("expected 3 results, a reranker warning and a confidence, got %d, %v and %q", len(resp.Results), resp.Warnings, resp.Confidence)
+	}
+	for _, r := range resp.Results {
+		if len(r.Highlights) == 0 {
+			t.Errorf("expected highlights for %s", r.Chunk.FilePath)
+		}
+		if r.Chunk.FilePath == "web/handler.go" && len(r.Notes) != 1 {
+			t.Errorf("expected the note of web/handler.go, got %v", r.Notes)
+		}
+	}
+
+	// Grouped: a result per package, the groups sharing the results
+	resp, err = Run(ctx, st, config.SearchConfig{}, Request{Query: "session", Limit: 10, PerGroup: 1, ProjectRoot: root})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(resp.Groups) != 2 || len(resp.Results) != 2 || len(resp.Warnings) != 0 {
+		t.Fatalf("expected 2 groups of a result each, got %d groups, %d results and %v", len(resp.Groups), len(resp.Results), resp.Warnings)
+	}
+	if len(resp.Groups[0].Results[0].Highlights) == 0 {
+		t.Error("expected the results of groups to be annotated")
+	}
+
+	// Weak matches are dropped
+	resp, err = Run(ctx, st, config.SearchConfig{}, Request{Query: "session", Limit: 10, MinScore: 1e9, ProjectRoot: root})
+	if err != nil || len(resp.Results) != 0 {
+		t.Errorf("expected no results above the minimum score, got %d (%v)", len(resp.Results), err)
+	}
+}
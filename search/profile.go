@@ -0,0 +1,58 @@
+package search
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// LoadProfile replaces the ranking settings of cfg with the shared profile
+// named by cfg.Profile. It does nothing when no profile is selected.
+func LoadProfile(ctx context.Context, profiles store.ProfileStore, cfg *config.SearchConfig) error {
+	profile, err := RankingProfile(ctx, profiles, cfg.Profile)
+	if err != nil || profile == nil {
+		return err
+	}
+	cfg.ApplyProfile(*profile)
+	return nil
+}
+
+// RankingProfile returns the shared profile name, or nil when name is empty.
+func RankingProfile(ctx context.Context, profiles store.ProfileStore, name string) (*config.RankingProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	p, err := profiles.GetProfile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return config.ParseRankingProfile(p.Data)
+}
+
+// OpenStore opens a store with the query settings of cfg and applies the
+// shared ranking profile. The store is reopened when the profile changes
+// the tokenizer or query expansion.
+func OpenStore(ctx context.Context, cfg *config.SearchConfig, opts store.Options) (store.SearchStore, error) {
+	expansion := cfg.Expansion
+	opts.CJKBigrams = cfg.CJKBigrams
+	opts.ExpandTerm = ExpandFunc(expansion)
+	opts.SplitIdentifiers = SplitFunc(cfg.Identifiers)
+	st, err := store.Open(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := LoadProfile(ctx, st, cfg); err != nil {
+		st.Close()
+		return nil, err
+	}
+	if opts.CJKBigrams != cfg.CJKBigrams || !reflect.DeepEqual(expansion, cfg.Expansion) {
+		st.Close()
+		opts.CJKBigrams = cfg.CJKBigrams
+		opts.ExpandTerm = ExpandFunc(cfg.Expansion)
+		return store.Open(ctx, opts)
+	}
+	return st, nil
+}
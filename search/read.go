@@ -0,0 +1,94 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+)
+
+// Slice is a line range of a file.
+type Slice struct {
+	FilePath   string `json:"file_path"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Content    string `json:"content"`
+}
+
+// ChunkSlice reassembles lines start..end of a file from its indexed chunks.
+// The end line is clamped to the last indexed line.
+func ChunkSlice(filePath string, chunks []store.Chunk, start, end int) (*Slice, error) {
+	if len(chunks) == 0 {
+		return nil, errcode.New(errcode.NotFound, "file not indexed: %s", filePath)
+	}
+	return sliceLines(filePath, chunkLines(chunks), start, end)
+}
+
+// chunkLines reassembles file lines from chunks; lines no chunk covers are
+// empty. Chunks overlap and may start mid-line, so a line is only taken from
+// a chunk's first line (or an unterminated last line) when no other chunk
+// holds it in full.
+func chunkLines(chunks []store.Chunk) []string {
+	type line struct {
+		text     string
+		complete bool
+	}
+	var lines []line
+	for _, c := range chunks {
+		body := stripFileHeader(c.Content)
+		terminated := strings.HasSuffix(body, "\n")
+		parts := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+		for i, text := range parts {
+			n := c.StartLine + i
+			for len(lines) < n {
+				lines = append(lines, line{})
+			}
+			complete := (i > 0 || c.StartLine == 1) && (i < len(parts)-1 || terminated)
+			if l := &lines[n-1]; !l.complete {
+				*l = line{text: text, complete: complete}
+			}
+		}
+	}
+
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return texts
+}
+
+// TextSlice returns lines start..end of content, clamping the end line to the
+// last line of the file.
+func TextSlice(filePath, content string, start, end int) (*Slice, error) {
+	return sliceLines(filePath, strings.Split(strings.TrimSuffix(content, "\n"), "\n"), start, end)
+}
+
+func sliceLines(filePath string, lines []string, start, end int) (*Slice, error) {
+	if start < 1 || end < start {
+		return nil, errcode.New(errcode.InvalidArgs, "invalid line range %d-%d", start, end)
+	}
+	if start > len(lines) {
+		return nil, errcode.New(errcode.InvalidArgs, "line %d is past the end of %s (%d lines)", start, filePath, len(lines))
+	}
+	end = min(end, len(lines))
+	return &Slice{
+		FilePath:   filePath,
+		StartLine:  start,
+		EndLine:    end,
+		TotalLines: len(lines),
+		Content:    strings.Join(lines[start-1:end], "\n"),
+	}, nil
+}
+
+// stripFileHeader removes the "File: <path>" context prefix the indexer adds
+// to chunk content.
+func stripFileHeader(content string) string {
+	if !strings.HasPrefix(content, "File: ") {
+		return content
+	}
+	if _, body, ok := strings.Cut(content, "\n\n"); ok {
+		return body
+	}
+	return content
+}
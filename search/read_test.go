@@ -0,0 +1,72 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+func TestChunkSlice_ReassemblesOverlappingChunks(t *testing.T) {
+	var b strings.Builder
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&b, "line %d: %s\n", i, strings.Repeat("x", i%37))
+	}
+	content := b.String()
+
+	// Small chunks with character-based overlap start mid-line
+	infos := indexer.NewChunker(64, 16).ChunkWithContext("main.go", content)
+	if len(infos) < 3 {
+		t.Fatalf("expected several chunks, got %d", len(infos))
+	}
+	chunks := make([]store.Chunk, len(infos))
+	for i, info := range infos {
+		chunks[i] = store.Chunk{FilePath: info.FilePath, StartLine: info.StartLine, EndLine: info.EndLine, Content: info.Content}
+	}
+
+	want, err := TextSlice("main.go", content, 1, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ChunkSlice("main.go", chunks, 1, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != want.Content {
+		t.Fatalf("reassembled content differs from file content")
+	}
+
+	got, err = ChunkSlice("main.go", chunks, 42, 44)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != "line 42: xxxxx\nline 43: xxxxxx\nline 44: xxxxxxx" {
+		t.Errorf("unexpected slice content: %q", got.Content)
+	}
+}
+
+func TestTextSlice_Bounds(t *testing.T) {
+	content := "a\nb\nc\n"
+
+	s, err := TextSlice("f.txt", content, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.StartLine != 2 || s.EndLine != 3 || s.TotalLines != 3 || s.Content != "b\nc" {
+		t.Errorf("unexpected clamped slice: %+v", s)
+	}
+
+	for _, r := range [][2]int{{0, 1}, {3, 2}, {4, 5}} {
+		if _, err := TextSlice("f.txt", content, r[0], r[1]); err == nil {
+			t.Errorf("expected error for range %v", r)
+		}
+	}
+}
+
+func TestChunkSlice_NotIndexed(t *testing.T) {
+	if _, err := ChunkSlice("missing.go", nil, 1, 1); err == nil {
+		t.Error("expected error for file without chunks")
+	}
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+// Orders of indexed file listings.
+const (
+	FileSortPath   = "path"   // alphabetical
+	FileSortMTime  = "mtime"  // most recently modified first
+	FileSortChunks = "chunks" // most chunks first
+)
+
+// FileSorts lists the orders SortFiles accepts.
+var FileSorts = []string{FileSortPath, FileSortMTime, FileSortChunks}
+
+// FilesSince returns the files of files that changed recently: since is
+// either an age such as 2h or 7d, matched against the modification time
+// recorded when each file was indexed, or a git revision, matched against
+// the files git diff reports between it and the working tree of the
+// repository at projectRoot. Ages take precedence over revisions.
+func FilesSince(files []store.FileStats, projectRoot, since string, now time.Time) ([]store.FileStats, error) {
+	var recent []store.FileStats
+	if age, err := config.ParseAge(since); err == nil {
+		cutoff := now.Add(-age)
+		for _, f := range files {
+			if f.ModTime.After(cutoff) {
+				recent = append(recent, f)
+			}
+		}
+		return recent, nil
+	}
+
+	diff, err := indexer.GitDiff(projectRoot, since)
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidArgs, "since %q is neither an age such as 2h or 7d nor a git revision: %v", since, err)
+	}
+	changed := make(map[string]bool, len(diff.Changed))
+	for _, path := range diff.Changed {
+		changed[filepath.ToSlash(path)] = true
+	}
+	for _, f := range files {
+		if changed[f.Path] {
+			recent = append(recent, f)
+		}
+	}
+	return recent, nil
+}
+
+// SortFiles sorts files in the order by, one of FileSorts. Ties are broken
+// by path.
+func SortFiles(files []store.FileStats, by string) error {
+	var less func(a, b store.FileStats) bool
+	switch by {
+	case FileSortPath, "":
+		less = func(a, b store.FileStats) bool { return false }
+	case FileSortMTime:
+		less = func(a, b store.FileStats) bool { return a.ModTime.After(b.ModTime) }
+	case FileSortChunks:
+		less = func(a, b store.FileStats) bool { return a.ChunkCount > b.ChunkCount }
+	default:
+		return errcode.New(errcode.InvalidArgs, "unknown sort %q: use one of %s", by, strings.Join(FileSorts, ", "))
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if less(files[i], files[j]) {
+			return true
+		}
+		if less(files[j], files[i]) {
+			return false
+		}
+		return files[i].Path < files[j].Path
+	})
+	return nil
+}
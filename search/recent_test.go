@@ -0,0 +1,124 @@
+package search
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/store"
+)
+
+func filePaths(files []store.FileStats) []string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+func TestFilesSince_Age(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	files := []store.FileStats{
+		{Path: "old.go", ModTime: now.Add(-72 * time.Hour)},
+		{Path: "hour.go", ModTime: now.Add(-time.Hour)},
+		{Path: "day.go", ModTime: now.Add(-30 * time.Hour)},
+	}
+
+	recent, err := FilesSince(files, t.TempDir(), "2h", now)
+	if err != nil {
+		t.Fatalf("FilesSince failed: %v", err)
+	}
+	if got := filePaths(recent); !reflect.DeepEqual(got, []string{"hour.go"}) {
+		t.Errorf("expected hour.go within 2h, got %v", got)
+	}
+
+	recent, err = FilesSince(files, t.TempDir(), "2d", now)
+	if err != nil {
+		t.Fatalf("FilesSince failed: %v", err)
+	}
+	if got := filePaths(recent); !reflect.DeepEqual(got, []string{"hour.go", "day.go"}) {
+		t.Errorf("expected hour.go and day.go within 2d, got %v", got)
+	}
+}
+
+func TestFilesSince_GitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("main.go", "package main\n")
+	write("pkg/util.go", "package pkg\n")
+	write("pkg/other.go", "package pkg\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Initial")
+	git("tag", "v1")
+	write("pkg/util.go", "package pkg\n\nfunc Util() {}\n")
+	git("commit", "-q", "-am", "Add Util")
+	write("main.go", "package main\n\nfunc main() {}\n")
+
+	files := []store.FileStats{{Path: "main.go"}, {Path: "pkg/other.go"}, {Path: "pkg/util.go"}}
+	recent, err := FilesSince(files, root, "v1", time.Now())
+	if err != nil {
+		t.Fatalf("FilesSince failed: %v", err)
+	}
+	if got := filePaths(recent); !reflect.DeepEqual(got, []string{"main.go", "pkg/util.go"}) {
+		t.Errorf("expected the committed and uncommitted changes since v1, got %v", got)
+	}
+
+	if _, err := FilesSince(files, root, "no-such-ref", time.Now()); errcode.Of(err) != errcode.InvalidArgs {
+		t.Errorf("expected an invalid-args error for an unknown revision, got %v", err)
+	}
+}
+
+func TestSortFiles(t *testing.T) {
+	now := time.Now()
+	files := []store.FileStats{
+		{Path: "b.go", ModTime: now.Add(-time.Hour), ChunkCount: 3},
+		{Path: "c.go", ModTime: now, ChunkCount: 1},
+		{Path: "a.go", ModTime: now.Add(-time.Hour), ChunkCount: 3},
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{FileSortPath, []string{"a.go", "b.go", "c.go"}},
+		{FileSortMTime, []string{"c.go", "a.go", "b.go"}},
+		{FileSortChunks, []string{"a.go", "b.go", "c.go"}},
+	}
+	for _, tt := range tests {
+		if err := SortFiles(files, tt.by); err != nil {
+			t.Fatalf("SortFiles(%q) failed: %v", tt.by, err)
+		}
+		if got := filePaths(files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SortFiles(%q) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+
+	if err := SortFiles(files, "size"); errcode.Of(err) != errcode.InvalidArgs {
+		t.Errorf("expected an invalid-args error for an unknown sort, got %v", err)
+	}
+}
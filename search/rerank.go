@@ -0,0 +1,155 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+// maxRerankDocBytes caps the content sent to the reranker per result;
+// cross-encoders only read the first few hundred tokens anyway.
+const maxRerankDocBytes = 4096
+
+// RerankLimit returns how many ranked results to keep for Rerank: the
+// configured candidates when reranking is enabled and they are more than
+// limit, otherwise limit.
+func RerankLimit(limit int, cfg config.SearchConfig) int {
+	if cfg.Rerank.Enabled {
+		return max(limit, cfg.Rerank.Candidates)
+	}
+	return limit
+}
+
+// rerankRequest is the body of a /v1/rerank call.
+type rerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+// rerankResponse is the answer of a /v1/rerank call: a relevance score per
+// document, identified by its index in the request.
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank reorders results, ranked by Rank with RerankLimit, by the scores
+// of the reranker of cfg for query, and trims them to limit. Their scores
+// become the reranker's relevance scores. When reranking is disabled,
+// results are only trimmed; when the reranker fails or times out, they keep
+// their full-text order and the error is returned with them.
+func Rerank(ctx context.Context, query string, results []store.SearchResult, cfg config.RerankConfig, limit int) ([]store.SearchResult, error) {
+	if !cfg.Enabled || len(results) < 2 {
+		return trimResults(results, limit), nil
+	}
+
+	scores, err := rerankScores(ctx, query, results, cfg)
+	if err != nil {
+		return trimResults(results, limit), fmt.Errorf("reranker failed, results are in full-text order: %w", err)
+	}
+
+	// Results the reranker did not score go last, in full-text order
+	type scored struct {
+		result store.SearchResult
+		score  float64
+		ok     bool
+	}
+	ranked := make([]scored, len(results))
+	for i, r := range results {
+		score, ok := scores[i]
+		ranked[i] = scored{result: r, score: score, ok: ok}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].ok != ranked[j].ok {
+			return ranked[i].ok
+		}
+		return ranked[i].ok && ranked[i].score > ranked[j].score
+	})
+	reranked := make([]store.SearchResult, len(ranked))
+	for i, r := range ranked {
+		reranked[i] = r.result
+		if r.ok {
+			reranked[i].Score = float32(r.score)
+		}
+	}
+	return trimResults(reranked, limit), nil
+}
+
+// rerankScores asks the reranker of cfg to score each result for query and
+// returns the scores by result index.
+func rerankScores(ctx context.Context, query string, results []store.SearchResult, cfg config.RerankConfig) (map[int]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	body := rerankRequest{Model: cfg.Model, Query: query, Documents: make([]string, len(results))}
+	for i, r := range results {
+		body.Documents[i] = truncateUTF8(r.Chunk.Content, maxRerankDocBytes)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rerank request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s returned %s: %s", cfg.Endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+	scores := make(map[int]float64, len(out.Results))
+	for _, r := range out.Results {
+		if r.Index >= 0 && r.Index < len(results) {
+			scores[r.Index] = r.RelevanceScore
+		}
+	}
+	if len(scores) == 0 {
+		return nil, fmt.Errorf("%s scored no results", cfg.Endpoint)
+	}
+	return scores, nil
+}
+
+// trimResults returns the first limit results.
+func trimResults(results []store.SearchResult, limit int) []store.SearchResult {
+	if len(results) > limit {
+		return results[:limit]
+	}
+	return results
+}
+
+// truncateUTF8 returns the first n bytes of s at most, never cutting a UTF-8
+// sequence.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func rerankResults() []store.SearchResult {
+	return []store.SearchResult{
+		chunkResult("a.go", 1, 2, "func parseToken() {}\n", 3),
+		chunkResult("b.go", 1, 2, "func refreshToken() {}\n", 2),
+		chunkResult("c.go", 1, 2, "// token bucket\n", 1),
+	}
+}
+
+func TestRerank(t *testing.T) {
+	var got rerankRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		// c.go is not scored and goes last
+		_, _ = w.Write([]byte(`{"results": [{"index": 0, "relevance_score": 0.2}, {"index": 1, "relevance_score": 0.9}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.RerankConfig{Enabled: true, Endpoint: server.URL, Model: "bge-reranker-v2-m3", Candidates: 50, TimeoutMs: 1000}
+	results, err := Rerank(context.Background(), "refresh token", rerankResults(), cfg, 3)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if got.Model != cfg.Model || got.Query != "refresh token" || len(got.Documents) != 3 || !strings.Contains(got.Documents[1], "refreshToken") {
+		t.Errorf("unexpected rerank request %+v", got)
+	}
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.Chunk.FilePath)
+	}
+	if strings.Join(paths, ",") != "b.go,a.go,c.go" {
+		t.Errorf("expected b.go, a.go, c.go, got %v", paths)
+	}
+	if results[0].Score != 0.9 || results[2].Score != 1 {
+		t.Errorf("expected reranker scores for scored results only, got %v and %v", results[0].Score, results[2].Score)
+	}
+
+	// Disabled: the full-text order is trimmed to the limit
+	results, err = Rerank(context.Background(), "refresh token", rerankResults(), config.RerankConfig{}, 2)
+	if err != nil || len(results) != 2 || results[0].Chunk.FilePath != "a.go" {
+		t.Errorf("expected the first 2 full-text results, got %v (%v)", results, err)
+	}
+}
+
+func TestRerank_Fallback(t *testing.T) {
+	for name, handler := range map[string]http.HandlerFunc{
+		"error": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "model not found", http.StatusNotFound)
+		},
+		"timeout": func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(500 * time.Millisecond):
+			}
+		},
+		"empty": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"results": []}`))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			cfg := config.RerankConfig{Enabled: true, Endpoint: server.URL, Model: "m", Candidates: 50, TimeoutMs: 50}
+			results, err := Rerank(context.Background(), "token", rerankResults(), cfg, 2)
+			if err == nil {
+				t.Error("expected the reranker error")
+			}
+			if len(results) != 2 || results[0].Chunk.FilePath != "a.go" || results[1].Chunk.FilePath != "b.go" {
+				t.Errorf("expected the full-text order, got %v", results)
+			}
+		})
+	}
+}
+
+func TestCandidateLimit_Rerank(t *testing.T) {
+	cfg := config.SearchConfig{MaxPerFile: 3, Rerank: config.RerankConfig{Enabled: true, Candidates: 50}}
+	if got := RerankLimit(10, cfg); got != 50 {
+		t.Errorf("RerankLimit = %d, want 50", got)
+	}
+	if got := CandidateLimit(10, cfg); got != 200 {
+		t.Errorf("CandidateLimit = %d, want 200", got)
+	}
+	cfg.Rerank.Enabled = false
+	if got := CandidateLimit(10, cfg); got != 40 {
+		t.Errorf("CandidateLimit = %d, want 40", got)
+	}
+}
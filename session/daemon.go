@@ -8,8 +8,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"sync"
-	"syscall"
 	"time"
+
+	"github.com/doveaia/agentdx/encrypt"
 )
 
 const (
@@ -21,8 +22,12 @@ const (
 
 // DaemonOptions holds optional configuration for the daemon manager
 type DaemonOptions struct {
-	PgName string // PostgreSQL container name
-	PgPort int    // PostgreSQL host port
+	PgName  string // PostgreSQL container name
+	PgPort  int    // PostgreSQL host port
+	Profile string // Indexing profile; the daemon also inherits AGENTDX_PROFILE
+	// Cipher encrypts the lines written to the session log; nil writes them
+	// in the clear
+	Cipher *encrypt.Cipher
 }
 
 // DaemonStatus represents the current state of the session daemon
@@ -47,7 +52,7 @@ func NewDaemonManager(projectRoot string) *DaemonManager {
 	return &DaemonManager{
 		ProjectRoot: projectRoot,
 		PIDFile:     NewPIDFile(projectRoot),
-		logFile:     filepath.Join(projectRoot, ".agentdx", SessionLogFileName),
+		logFile:     LogFilePath(projectRoot),
 		opts:        DaemonOptions{}, // Default options
 	}
 }
@@ -57,7 +62,7 @@ func NewDaemonManagerWithOptions(projectRoot string, opts DaemonOptions) *Daemon
 	return &DaemonManager{
 		ProjectRoot: projectRoot,
 		PIDFile:     NewPIDFile(projectRoot),
-		logFile:     filepath.Join(projectRoot, ".agentdx", SessionLogFileName),
+		logFile:     LogFilePath(projectRoot),
 		opts:        opts,
 	}
 }
@@ -97,13 +102,6 @@ func (d *DaemonManager) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	// Open log file for appending
-	logF, err := os.OpenFile(d.logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
-	}
-	defer logF.Close()
-
 	// Create the command with optional flags
 	args := []string{"watch", "--daemon"}
 	if d.opts.PgName != "" {
@@ -112,13 +110,26 @@ func (d *DaemonManager) Start(ctx context.Context) error {
 	if d.opts.PgPort != 0 {
 		args = append(args, "--pg-port", strconv.Itoa(d.opts.PgPort))
 	}
+	if d.opts.Profile != "" {
+		args = append(args, "--profile", d.opts.Profile)
+	}
 
 	cmd := exec.CommandContext(ctx, execPath, args...)
 	cmd.Dir = d.ProjectRoot
-
-	// Redirect stdout and stderr to log file
-	cmd.Stdout = logF
-	cmd.Stderr = logF
+	cmd.Env = append(os.Environ(), DaemonEnv+"=1")
+
+	// Redirect stdout and stderr to log file; the daemon logs through a
+	// rotating writer, so this only catches output before it starts and panics.
+	// An encrypted log cannot take raw output, which is discarded.
+	if d.opts.Cipher == nil {
+		logF, err := os.OpenFile(d.logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer logF.Close()
+		cmd.Stdout = logF
+		cmd.Stderr = logF
+	}
 
 	// Set up process group for clean termination (platform-specific)
 	cmd.SysProcAttr = getSysProcAttr()
@@ -141,7 +152,8 @@ func (d *DaemonManager) Start(ctx context.Context) error {
 }
 
 // Stop stops the watch daemon gracefully
-// Uses SIGTERM with timeout, falls back to SIGKILL if force is true
+// Uses SIGTERM with timeout, falls back to SIGKILL if force is true.
+// On Windows, the daemon is killed with taskkill.
 func (d *DaemonManager) Stop(ctx context.Context, force bool) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -165,32 +177,23 @@ func (d *DaemonManager) Stop(ctx context.Context, force bool) error {
 	}
 
 	// Check if process is running
-	process, err := os.FindProcess(pid)
-	if err != nil {
+	if !processExists(pid) {
 		// Process doesn't exist, clean up stale PID file
 		d.log("Cleaning up stale PID file (PID: %d)", pid)
 		return d.PIDFile.Remove()
 	}
 
-	// Try to signal the process with signal 0 to check if it's running
-	if err := process.Signal(syscall.Signal(0)); err != nil {
-		if err == syscall.ESRCH {
-			// Process doesn't exist, clean up stale PID file
-			d.log("Cleaning up stale PID file (PID: %d)", pid)
+	// Ask for a graceful shutdown (SIGTERM; taskkill on Windows)
+	d.log("[%s] Stopping daemon (PID: %d)", timestamp(), pid)
+	if err := terminateProcess(pid); err != nil {
+		if !processExists(pid) {
+			// Process already gone - this is actually success
+			d.log("Daemon already terminated (PID: %d)", pid)
 			return d.PIDFile.Remove()
 		}
-		// On some systems, sending signal to invalid PID returns "process already released"
-		// or other errors. Treat these as process not existing.
-		d.log("Process not accessible (PID: %d), cleaning up PID file", pid)
-		return d.PIDFile.Remove()
-	}
-
-	// Send SIGTERM for graceful shutdown
-	d.log("[%s] Sending SIGTERM to daemon (PID: %d)", timestamp(), pid)
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		// Process already gone - this is actually success
-		d.log("Daemon already terminated (PID: %d)", pid)
-		return d.PIDFile.Remove()
+		// Windows cannot ask a windowless process to exit
+		d.log("Graceful shutdown unavailable: %v", err)
+		force = true
 	}
 
 	// Wait for graceful shutdown (unless force is true)
@@ -199,47 +202,37 @@ func (d *DaemonManager) Stop(ctx context.Context, force bool) error {
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
 
+	wait:
 		for {
 			select {
 			case <-deadline:
 				// Timeout exceeded, fall through to force kill
 				d.log("Graceful shutdown timeout, forcing...")
-				force = true
+				break wait
 			case <-ticker.C:
-				// Check if process is still running
-				if err := process.Signal(syscall.Signal(0)); err != nil {
-					if err == syscall.ESRCH {
-						// Process terminated gracefully
-						d.log("[%s] Daemon stopped gracefully (PID: %d)", timestamp(), pid)
-						return d.PIDFile.Remove()
-					}
-				}
-				if !force {
-					continue
+				if !processExists(pid) {
+					// Process terminated gracefully
+					d.log("[%s] Daemon stopped gracefully (PID: %d)", timestamp(), pid)
+					return d.PIDFile.Remove()
 				}
 			case <-ctx.Done():
 				return ctx.Err()
 			}
-
-			if force {
-				break
-			}
 		}
 	}
 
-	// Force kill with SIGKILL
-	d.log("[%s] Sending SIGKILL to daemon (PID: %d)", timestamp(), pid)
-	if err := process.Signal(syscall.SIGKILL); err != nil {
-		// Process already gone - this is actually success
-		// ESRCH is "no such process", but there may be other errors like "process already finished"
-		d.log("Daemon already terminated (PID: %d)", pid)
-		return d.PIDFile.Remove()
+	// Force kill (SIGKILL; taskkill /F on Windows)
+	d.log("[%s] Killing daemon (PID: %d)", timestamp(), pid)
+	if err := killProcess(pid); err != nil && processExists(pid) {
+		return fmt.Errorf("failed to kill daemon (PID: %d): %w", pid, err)
 	}
 
 	// Give it a moment to terminate
 	time.Sleep(100 * time.Millisecond)
 
 	d.log("[%s] Daemon stopped (PID: %d)", timestamp(), pid)
+	// A killed daemon cannot remove its health address
+	_ = os.Remove(HealthAddrPath(d.ProjectRoot))
 	return d.PIDFile.Remove()
 }
 
@@ -286,7 +279,11 @@ func (d *DaemonManager) Status() (DaemonStatus, error) {
 // log writes a message to the session log file
 func (d *DaemonManager) log(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	logEntry := fmt.Sprintf("[%s] %s\n", timestamp(), msg)
+	logEntry := fmt.Sprintf("[%s] %s", timestamp(), msg)
+	if d.opts.Cipher != nil {
+		logEntry = d.opts.Cipher.SealLine([]byte(logEntry))
+	}
+	logEntry += "\n"
 
 	// Ensure log directory exists
 	if err := os.MkdirAll(filepath.Dir(d.logFile), 0755); err != nil {
@@ -331,7 +328,9 @@ func (d *DaemonManager) GetLogFile() string {
 	return d.logFile
 }
 
-// TailLog returns the last n lines from the session log file
+// TailLog returns the last n lines from the session log file. Encrypted
+// lines are decrypted with the cipher of the options, and returned as they
+// are without one.
 func (d *DaemonManager) TailLog(n int) ([]string, error) {
 	data, err := os.ReadFile(d.logFile)
 	if err != nil {
@@ -343,10 +342,17 @@ func (d *DaemonManager) TailLog(n int) ([]string, error) {
 
 	// Split into lines and get last n
 	lines := splitLines(data)
-	if len(lines) <= n {
-		return lines, nil
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if d.opts.Cipher != nil {
+		for i, line := range lines {
+			if lines[i], err = d.opts.Cipher.OpenLine(line); err != nil {
+				return nil, fmt.Errorf("failed to read log file: %w", err)
+			}
+		}
 	}
-	return lines[len(lines)-n:], nil
+	return lines, nil
 }
 
 // splitLines splits bytes into lines, handling both \n and \r\n
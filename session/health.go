@@ -0,0 +1,221 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HealthAddrFileName holds the address of the daemon's health endpoint
+	HealthAddrFileName = "session.addr"
+	// HeartbeatInterval is how often the watch event loop reports that it is alive
+	HeartbeatInterval = 5 * time.Second
+	// StaleAfter is how long the heartbeat may stop before the daemon is
+	// considered wedged
+	StaleAfter = time.Minute
+	// healthTimeout bounds a health query; a daemon that cannot answer in
+	// time is wedged
+	healthTimeout = 2 * time.Second
+)
+
+// Phases of a watch daemon reported by its health endpoint.
+const (
+	PhaseScanning = "scanning" // initial scan, which may take long without heartbeats
+	PhaseWatching = "watching" // event loop
+)
+
+// ErrNoHealthEndpoint is returned by QueryHealth when the daemon serves no
+// health endpoint, such as a daemon started by an older agentdx.
+var ErrNoHealthEndpoint = errors.New("daemon serves no health endpoint")
+
+// Health is the state reported by the health endpoint of a watch daemon.
+type Health struct {
+	PID           int       `json:"pid"`
+	Phase         string    `json:"phase"`
+	StartedAt     time.Time `json:"started_at"`
+	Heartbeat     time.Time `json:"heartbeat"`
+	LastEvent     time.Time `json:"last_event"`
+	LastEventPath string    `json:"last_event_path,omitempty"`
+	QueueDepth    int       `json:"queue_depth"`
+	LastPersist   time.Time `json:"last_persist"`
+}
+
+// Stale reports whether the event loop stopped beating for longer than
+// StaleAfter at now. The initial scan is never stale.
+func (h Health) Stale(now time.Time) bool {
+	return h.Phase == PhaseWatching && now.Sub(h.Heartbeat) > StaleAfter
+}
+
+// HealthAddrPath returns the file holding the health endpoint address.
+func HealthAddrPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".agentdx", HealthAddrFileName)
+}
+
+// HealthMonitor tracks the health of a watch daemon and serves it as JSON
+// at GET /health on a localhost port, recorded in .agentdx/session.addr.
+type HealthMonitor struct {
+	addrPath string
+	mu       sync.Mutex
+	health   Health
+	server   *http.Server
+}
+
+// NewHealthMonitor creates a monitor for the daemon of projectRoot, in the
+// scanning phase.
+func NewHealthMonitor(projectRoot string) *HealthMonitor {
+	now := time.Now()
+	return &HealthMonitor{
+		addrPath: HealthAddrPath(projectRoot),
+		health: Health{
+			PID:       os.Getpid(),
+			Phase:     PhaseScanning,
+			StartedAt: now,
+			Heartbeat: now,
+		},
+	}
+}
+
+// Start serves the health endpoint on a free localhost port and records its
+// address.
+func (m *HealthMonitor) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen for health queries: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m.Snapshot())
+	})
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: healthTimeout}
+	go func() { _ = m.server.Serve(listener) }()
+
+	if err := os.WriteFile(m.addrPath, []byte(listener.Addr().String()+"\n"), 0644); err != nil {
+		m.server.Close()
+		return fmt.Errorf("failed to write health address: %w", err)
+	}
+	return nil
+}
+
+// Close stops serving and removes the recorded address.
+func (m *HealthMonitor) Close() error {
+	if m.server == nil {
+		return nil
+	}
+	if err := os.Remove(m.addrPath); err != nil && !os.IsNotExist(err) {
+		m.server.Close()
+		return fmt.Errorf("failed to remove health address: %w", err)
+	}
+	return m.server.Close()
+}
+
+// Snapshot returns the current health.
+func (m *HealthMonitor) Snapshot() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.health
+}
+
+// SetPhase records the phase the daemon entered, which counts as a heartbeat.
+func (m *HealthMonitor) SetPhase(phase string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.Phase = phase
+	m.health.Heartbeat = time.Now()
+}
+
+// Beat records that the event loop is alive with queueDepth events waiting.
+func (m *HealthMonitor) Beat(queueDepth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.Heartbeat = time.Now()
+	m.health.QueueDepth = queueDepth
+}
+
+// EventProcessed records that the event for path was handled.
+func (m *HealthMonitor) EventProcessed(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.health.Heartbeat = now
+	m.health.LastEvent = now
+	m.health.LastEventPath = path
+}
+
+// Persisted records that the symbol index was written to its store.
+func (m *HealthMonitor) Persisted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.LastPersist = time.Now()
+}
+
+// QueryHealth asks the daemon of projectRoot for its health. It returns
+// ErrNoHealthEndpoint when no address is recorded, and an error when the
+// daemon does not answer in time.
+func QueryHealth(ctx context.Context, projectRoot string) (*Health, error) {
+	data, err := os.ReadFile(HealthAddrPath(projectRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoHealthEndpoint
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read health address: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+strings.TrimSpace(string(data))+"/health", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health address: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("daemon did not answer health query: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("daemon health query failed: %s", resp.Status)
+	}
+
+	var health Health
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("failed to decode daemon health: %w", err)
+	}
+	return &health, nil
+}
+
+// Wedged reports whether the running daemon with pid must be restarted: its
+// heartbeat is stale or it does not answer health queries. Daemons without
+// a health endpoint, or whose endpoint belongs to another process, are
+// given the benefit of the doubt.
+func Wedged(ctx context.Context, projectRoot string, pid int, now time.Time) (bool, string) {
+	// An address older than the PID file was left by a previous daemon; the
+	// current one may still be starting up
+	if addr, err := os.Stat(HealthAddrPath(projectRoot)); err == nil {
+		if started, err := os.Stat(NewPIDFile(projectRoot).Path); err == nil && addr.ModTime().Before(started.ModTime()) {
+			return false, ""
+		}
+	}
+
+	health, err := QueryHealth(ctx, projectRoot)
+	switch {
+	case errors.Is(err, ErrNoHealthEndpoint):
+		return false, ""
+	case err != nil:
+		return true, err.Error()
+	case health.PID != pid:
+		return false, ""
+	case health.Stale(now):
+		return true, fmt.Sprintf("no heartbeat since %s", health.Heartbeat.Format(time.RFC3339))
+	}
+	return false, ""
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealth_Stale(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * StaleAfter)
+	tests := []struct {
+		name   string
+		health Health
+		want   bool
+	}{
+		{"recent heartbeat", Health{Phase: PhaseWatching, Heartbeat: now}, false},
+		{"stopped heartbeat", Health{Phase: PhaseWatching, Heartbeat: old}, true},
+		{"initial scan", Health{Phase: PhaseScanning, Heartbeat: old}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.health.Stale(now); got != tt.want {
+				t.Errorf("Stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthMonitor_Query(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".agentdx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := QueryHealth(ctx, root); !errors.Is(err, ErrNoHealthEndpoint) {
+		t.Fatalf("expected ErrNoHealthEndpoint without a daemon, got %v", err)
+	}
+
+	m := NewHealthMonitor(root)
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	m.SetPhase(PhaseWatching)
+	m.EventProcessed("main.go")
+	m.Beat(3)
+	m.Persisted()
+
+	health, err := QueryHealth(ctx, root)
+	if err != nil {
+		t.Fatalf("QueryHealth() failed: %v", err)
+	}
+	if health.PID != os.Getpid() || health.Phase != PhaseWatching || health.LastEventPath != "main.go" || health.QueueDepth != 3 || health.LastPersist.IsZero() {
+		t.Errorf("unexpected health %+v", health)
+	}
+	if wedged, reason := Wedged(ctx, root, os.Getpid(), time.Now()); wedged {
+		t.Errorf("expected a healthy daemon, got wedged: %s", reason)
+	}
+	if wedged, _ := Wedged(ctx, root, os.Getpid(), time.Now().Add(2*StaleAfter)); !wedged {
+		t.Error("expected a daemon without heartbeat to be wedged")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if _, err := os.Stat(HealthAddrPath(root)); !os.IsNotExist(err) {
+		t.Errorf("expected the health address to be removed, got %v", err)
+	}
+}
+
+func TestWedged_Unreachable(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := NewPIDFile(root).Write(os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A port nothing listens on any more
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Left by a previous daemon: the current one may still be starting
+	addrPath := HealthAddrPath(root)
+	if err := os.WriteFile(addrPath, []byte(addr), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(addrPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if wedged, reason := Wedged(ctx, root, os.Getpid(), time.Now()); wedged {
+		t.Errorf("expected an address older than the PID file to be ignored, got wedged: %s", reason)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(addrPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if wedged, _ := Wedged(ctx, root, os.Getpid(), time.Now()); !wedged {
+		t.Error("expected a daemon that does not answer to be wedged")
+	}
+}
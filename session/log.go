@@ -0,0 +1,210 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/encrypt"
+)
+
+// DaemonEnv is set in the environment of the watch process started by the
+// daemon manager, which is already detached and must not detach again.
+const DaemonEnv = "AGENTDX_DAEMON"
+
+// LogFilePath returns the session log of the project.
+func LogFilePath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".agentdx", SessionLogFileName)
+}
+
+// IsDaemonProcess reports whether this process was started by the daemon manager.
+func IsDaemonProcess() bool {
+	return os.Getenv(DaemonEnv) != ""
+}
+
+// RotateOptions controls when a RotatingFile is rotated. Zero or negative
+// values disable a limit.
+type RotateOptions struct {
+	MaxSize    int64         // Rotate before the file grows past this many bytes
+	MaxAge     time.Duration // Rotate after writing to the file this long
+	MaxBackups int           // Rotated files kept as <path>.1 (newest) to <path>.<n>
+}
+
+// RotatingFile is an append-only log file rotated by size and age.
+type RotatingFile struct {
+	path   string
+	opts   RotateOptions
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+	now    func() time.Time
+	cipher *encrypt.Cipher // seals each line; nil writes them in the clear
+}
+
+// OpenRotatingFile opens the log file at path for appending, creating it if needed.
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, opts: opts, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetOptions changes the rotation limits, e.g. after the configuration is reloaded.
+func (r *RotatingFile) SetOptions(opts RotateOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts = opts
+}
+
+// SetCipher encrypts the lines written from now on with c, one sealed line
+// per line of text (see encrypt.Cipher.SealLine).
+func (r *RotatingFile) SetCipher(c *encrypt.Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cipher = c
+}
+
+// Write appends p to the file, rotating it first when a limit is reached.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := p
+	if r.cipher != nil {
+		out = sealLines(r.cipher, p)
+	}
+	if r.size > 0 && r.due(int64(len(out))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(out)
+	r.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sealLines encrypts each line of p. Log records are written one per call,
+// so lines are not split across calls.
+func sealLines(c *encrypt.Cipher, p []byte) []byte {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if line == "" {
+			continue
+		}
+		b.WriteString(c.SealLine([]byte(strings.TrimSuffix(line, "\n"))))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// due reports whether writing n more bytes requires a rotation.
+func (r *RotatingFile) due(n int64) bool {
+	if r.opts.MaxSize > 0 && r.size+n > r.opts.MaxSize {
+		return true
+	}
+	return r.opts.MaxAge > 0 && r.now().Sub(r.opened) >= r.opts.MaxAge
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = f
+	r.size = info.Size()
+	r.opened = r.now()
+	return nil
+}
+
+// rotate shifts the backups up by one, dropping the oldest, moves the file
+// to <path>.1 and opens a new one.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	backups := max(r.opts.MaxBackups, 0)
+	if backups == 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return r.open()
+	}
+	os.Remove(backupPath(r.path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(r.path, i), backupPath(r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(r.path, backupPath(r.path, 1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return r.open()
+}
+
+// backupPath returns the path of the i-th rotated log.
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// RedirectLog sends the output of the standard logger to w as structured
+// records, in JSON or text (key=value) format. Messages starting with
+// "Warning:" are logged at the warn level, and "Failed" ones at the error level.
+func RedirectLog(w io.Writer, format string) {
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, nil)
+	} else {
+		handler = slog.NewJSONHandler(w, nil)
+	}
+	log.SetFlags(0)
+	log.SetOutput(&logBridge{handler: handler})
+}
+
+// logBridge turns the lines of the standard logger into slog records.
+type logBridge struct {
+	handler slog.Handler
+}
+
+func (b *logBridge) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	level := slog.LevelInfo
+	switch {
+	case strings.HasPrefix(msg, "Warning:"):
+		level = slog.LevelWarn
+		msg = strings.TrimSpace(strings.TrimPrefix(msg, "Warning:"))
+	case strings.HasPrefix(msg, "Failed"):
+		level = slog.LevelError
+	}
+	record := slog.NewRecord(time.Now(), level, msg, 0)
+	if err := b.handler.Handle(context.Background(), record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,153 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/encrypt"
+)
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	r, err := OpenRotatingFile(path, RotateOptions{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() failed: %v", err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:                "fourth\n",
+		backupPath(path, 1): "third\n",
+		backupPath(path, 2): "second\n",
+	}
+	for p, content := range want {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", p, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), data, content)
+		}
+	}
+	if _, err := os.Stat(backupPath(path, 3)); !os.IsNotExist(err) {
+		t.Error("expected only 2 backups to be kept")
+	}
+}
+
+func TestRotatingFile_Encrypted(t *testing.T) {
+	c, err := encrypt.NewCipher(bytes.Repeat([]byte{7}, encrypt.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm := NewDaemonManagerWithOptions(t.TempDir(), DaemonOptions{Cipher: c})
+	r, err := OpenRotatingFile(dm.GetLogFile(), RotateOptions{})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() failed: %v", err)
+	}
+	defer r.Close()
+	r.SetCipher(c)
+
+	record := `{"msg":"Indexed billing/charge.go"}` + "\n"
+	if n, err := r.Write([]byte(record)); err != nil || n != len(record) {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	dm.log("Daemon started")
+
+	data, err := os.ReadFile(dm.GetLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("billing")) || bytes.Contains(data, []byte("Daemon")) {
+		t.Errorf("expected encrypted lines, got %q", data)
+	}
+
+	lines, err := dm.TailLog(10)
+	if err != nil {
+		t.Fatalf("TailLog() failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != strings.TrimSpace(record) || !strings.HasSuffix(lines[1], "] Daemon started") {
+		t.Errorf("TailLog() = %q", lines)
+	}
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenRotatingFile(path, RotateOptions{MaxAge: time.Hour, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() failed: %v", err)
+	}
+	defer r.Close()
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	r.opened = now
+
+	r.Write([]byte("recent\n"))
+	now = now.Add(time.Hour)
+	r.Write([]byte("new\n"))
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new\n" {
+		t.Errorf("session.log = %q, want %q", data, "new\n")
+	}
+	data, _ = os.ReadFile(backupPath(path, 1))
+	if string(data) != "old\nrecent\n" {
+		t.Errorf("session.log.1 = %q, want %q", data, "old\nrecent\n")
+	}
+}
+
+func TestRedirectLog(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.LstdFlags)
+
+	var buf bytes.Buffer
+	RedirectLog(&buf, "json")
+	log.Printf("Watching for changes...")
+	log.Printf("Warning: failed to start dashboard: %v", "port in use")
+	log.Printf("Failed to index %s", "main.go")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d: %q", len(lines), buf.String())
+	}
+	want := []struct{ level, msg string }{
+		{"INFO", "Watching for changes..."},
+		{"WARN", "failed to start dashboard: port in use"},
+		{"ERROR", "Failed to index main.go"},
+	}
+	for i, line := range lines {
+		var record struct {
+			Time  time.Time `json:"time"`
+			Level string    `json:"level"`
+			Msg   string    `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record %d is not JSON: %v", i, err)
+		}
+		if record.Level != want[i].level || record.Msg != want[i].msg || record.Time.IsZero() {
+			t.Errorf("record %d = %+v, want %+v", i, record, want[i])
+		}
+	}
+
+	buf.Reset()
+	RedirectLog(&buf, "text")
+	log.Printf("Shutting down...")
+	if got := buf.String(); !strings.Contains(got, `level=INFO msg="Shutting down..."`) {
+		t.Errorf("unexpected text record %q", got)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"syscall"
 	"time"
 )
 
@@ -108,33 +107,7 @@ func (p *PIDFile) IsProcessRunning() (bool, error) {
 		return false, nil
 	}
 
-	// On Unix systems, signal 0 checks if process exists without sending a signal
-	// syscall.Kill with signal 0 doesn't actually kill the process
-	// It returns an error if the process doesn't exist or if we don't have permission
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false, fmt.Errorf("failed to find process: %w", err)
-	}
-
-	// Try to signal the process with signal 0 (no-op signal)
-	// If the process exists, this will succeed (or return a permission error, which means it exists)
-	// If the process doesn't exist, this will return an error
-	err = process.Signal(syscall.Signal(0))
-	if err != nil {
-		// ESRCH means no such process
-		if err == syscall.ESRCH {
-			return false, nil
-		}
-		// EINVAL means invalid PID (on some systems)
-		if err == syscall.EINVAL {
-			return false, nil
-		}
-		// EPERM means we don't have permission, but the process exists
-		// On Windows, this might be different, so we handle it generally
-		return true, nil
-	}
-
-	return true, nil
+	return processExists(pid), nil
 }
 
 // Cleanup removes the PID file if the process is not running (stale)
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess stands in for the watch daemon in the tests below.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("AGENTDX_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// startHelperProcess starts a long-running child process and reaps it when
+// it exits, so that it does not linger as a zombie.
+func startHelperProcess(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "AGENTDX_TEST_HELPER_PROCESS=1")
+	cmd.SysProcAttr = getSysProcAttr()
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start helper process: %v", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	return cmd.Process.Pid
+}
+
+func TestProcessExists(t *testing.T) {
+	if !processExists(os.Getpid()) {
+		t.Error("expected the current process to exist")
+	}
+	if processExists(999999) {
+		t.Skip("PID 999999 is actually running on this system")
+	}
+}
+
+func TestDaemonManager_Stop_RunningProcess(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		dm := NewDaemonManager(t.TempDir())
+		pid := startHelperProcess(t)
+		if err := dm.PIDFile.Write(pid); err != nil {
+			t.Fatalf("Failed to write PID file: %v", err)
+		}
+
+		if err := dm.Stop(context.Background(), force); err != nil {
+			t.Fatalf("Stop(force=%v) failed: %v", force, err)
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for processExists(pid) && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if processExists(pid) {
+			t.Errorf("Stop(force=%v) left process %d running", force, pid)
+		}
+		if dm.PIDFile.Exists() {
+			t.Errorf("Stop(force=%v) left the PID file", force)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package session
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processExists reports whether a process with the given PID is running.
+// Signal 0 checks the process without signaling it; a permission error
+// means it exists but belongs to another user.
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcess asks the process to shut down gracefully (SIGTERM).
+func terminateProcess(pid int) error {
+	return signalProcess(pid, syscall.SIGTERM)
+}
+
+// killProcess stops the process immediately (SIGKILL).
+func killProcess(pid int) error {
+	return signalProcess(pid, syscall.SIGKILL)
+}
+
+func signalProcess(pid int, sig syscall.Signal) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(sig)
+}
@@ -0,0 +1,52 @@
+//go:build windows
+
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259
+
+// processExists reports whether a process with the given PID is running.
+func processExists(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means the process exists but belongs to another user
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// terminateProcess asks the process tree to close. Windows has no SIGTERM:
+// taskkill without /F only reaches processes with a window, so it fails
+// for the windowless daemon and the caller falls back to killProcess.
+func terminateProcess(pid int) error {
+	return taskkill(pid)
+}
+
+// killProcess terminates the process and its children immediately.
+func killProcess(pid int) error {
+	return taskkill(pid, "/F")
+}
+
+func taskkill(pid int, flags ...string) error {
+	args := append([]string{"/PID", strconv.Itoa(pid), "/T"}, flags...)
+	out, err := exec.Command("taskkill", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("taskkill failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RegistryFileName is the name of the file in ~/.agentdx listing the
+// projects whose daemons were started, for 'agentdx sessions'.
+const RegistryFileName = "projects.json"
+
+// RegisteredProject is a project root known to the registry.
+type RegisteredProject struct {
+	Root        string    `json:"root"`
+	AddedAt     time.Time `json:"added_at"`
+	LastStarted time.Time `json:"last_started,omitempty"`
+}
+
+// RegistryPath returns the path of the project registry in the user's home
+// directory.
+func RegistryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".agentdx", RegistryFileName), nil
+}
+
+// LoadRegistry returns the registered projects sorted by root. A missing
+// registry has no projects.
+func LoadRegistry() ([]RegisteredProject, error) {
+	path, err := RegistryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project registry: %w", err)
+	}
+	var projects []RegisteredProject
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse project registry %s: %w", path, err)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Root < projects[j].Root
+	})
+	return projects, nil
+}
+
+// Register adds the project at projectRoot to the registry, or records
+// that its daemon was started when started is set.
+func Register(projectRoot string, started bool) error {
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project root: %w", err)
+	}
+	projects, err := LoadRegistry()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	i := sort.Search(len(projects), func(i int) bool { return projects[i].Root >= root })
+	if i == len(projects) || projects[i].Root != root {
+		projects = append(projects, RegisteredProject{})
+		copy(projects[i+1:], projects[i:])
+		projects[i] = RegisteredProject{Root: root, AddedAt: now}
+	} else if !started {
+		return nil // Already known
+	}
+	if started {
+		projects[i].LastStarted = now
+	}
+	return saveRegistry(projects)
+}
+
+// Unregister removes the projects at roots from the registry and returns
+// how many it removed.
+func Unregister(roots ...string) (int, error) {
+	projects, err := LoadRegistry()
+	if err != nil {
+		return 0, err
+	}
+	remove := make(map[string]bool, len(roots))
+	for _, root := range roots {
+		remove[root] = true
+	}
+	kept := projects[:0]
+	for _, p := range projects {
+		if !remove[p.Root] {
+			kept = append(kept, p)
+		}
+	}
+	removed := len(projects) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, saveRegistry(kept)
+}
+
+// saveRegistry writes projects to the registry atomically.
+func saveRegistry(projects []RegisteredProject) error {
+	path, err := RegistryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	if projects == nil {
+		projects = []RegisteredProject{}
+	}
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project registry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), RegistryFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	return nil
+}
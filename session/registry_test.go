@@ -0,0 +1,62 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	projects, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry() failed: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("expected no projects without a registry, got %v", projects)
+	}
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	if err := Register(dirB, false); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := Register(dirA, true); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+	if err := Register(dirB, false); err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+
+	projects, err = LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry() failed: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %v", projects)
+	}
+	byRoot := map[string]RegisteredProject{}
+	for _, p := range projects {
+		byRoot[p.Root] = p
+	}
+	if byRoot[dirA].LastStarted.IsZero() {
+		t.Error("expected the start of the first project to be recorded")
+	}
+	if !byRoot[dirB].LastStarted.IsZero() {
+		t.Error("expected the second project never to have started")
+	}
+	if projects[0].Root > projects[1].Root {
+		t.Errorf("expected projects sorted by root, got %v", projects)
+	}
+
+	removed, err := Unregister(dirA, filepath.Join(dirA, "unknown"))
+	if err != nil {
+		t.Fatalf("Unregister() failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 project removed, got %d", removed)
+	}
+	projects, _ = LoadRegistry()
+	if len(projects) != 1 || projects[0].Root != dirB {
+		t.Errorf("expected only %s left, got %v", dirB, projects)
+	}
+}
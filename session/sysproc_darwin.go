@@ -5,8 +5,10 @@ package session
 import "syscall"
 
 // getSysProcAttr returns platform-specific process attributes for daemon management.
-// On macOS (Darwin), Setpgid is not available, so we return nil.
-// The process will still be detached from the parent's terminal.
+// On macOS (Darwin), Setsid starts a new session and process group without a
+// controlling terminal, detaching the daemon from the shell.
 func getSysProcAttr() *syscall.SysProcAttr {
-	return &syscall.SysProcAttr{}
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
 }
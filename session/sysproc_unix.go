@@ -5,9 +5,10 @@ package session
 import "syscall"
 
 // getSysProcAttr returns platform-specific process attributes for daemon management.
-// On Unix-like systems (Linux, BSD), Setpgid creates a new process group.
+// On Unix-like systems (Linux, BSD), Setsid starts a new session and process
+// group without a controlling terminal, detaching the daemon from the shell.
 func getSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{
-		Setpgid: true,
+		Setsid: true,
 	}
 }
@@ -2,12 +2,19 @@
 
 package session
 
-import "syscall"
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
 
 // getSysProcAttr returns platform-specific process attributes for daemon management.
-// On Windows, we use CREATE_NEW_PROCESS_GROUP to detach from the parent console.
+// On Windows, CREATE_NEW_PROCESS_GROUP detaches the daemon from the parent
+// console's Ctrl+C and CREATE_NO_WINDOW keeps it running without a console
+// window after the terminal that started it is closed.
 func getSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | windows.CREATE_NO_WINDOW,
+		HideWindow:    true,
 	}
 }
@@ -0,0 +1,29 @@
+package store
+
+import "context"
+
+// BulkSaver is implemented by backends that store many files at once faster
+// than file by file, as on the first index of a project, when every file is
+// new.
+type BulkSaver interface {
+	// SaveBulk stores the chunks and documents of many files, as
+	// SaveChunks and SaveDocument do, in one transaction.
+	SaveBulk(ctx context.Context, chunks []Chunk, docs []Document) error
+}
+
+// SaveBulk stores the chunks and documents of many files with st's
+// SaveBulk, or chunks first and documents next when st is no BulkSaver.
+func SaveBulk(ctx context.Context, st CodeStore, chunks []Chunk, docs []Document) error {
+	if bs, ok := st.(BulkSaver); ok {
+		return bs.SaveBulk(ctx, chunks, docs)
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := st.SaveDocument(ctx, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
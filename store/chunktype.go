@@ -0,0 +1,74 @@
+package store
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// Chunk types, set from the extension of the chunk's file.
+const (
+	ChunkTypeCode    = "code"
+	ChunkTypeDoc     = "doc"
+	ChunkTypeConfig  = "config"
+	ChunkTypeSummary = "summary" // directory summaries; see SummaryPath
+)
+
+var (
+	docExtensions    = []string{"md", "mdx", "markdown", "rst", "adoc", "txt"}
+	configExtensions = []string{"yaml", "yml", "json", "toml", "ini", "cfg", "conf", "env", "properties"}
+)
+
+// ChunkTypeOf returns the type of the chunks of the file at path.
+func ChunkTypeOf(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch {
+	case IsSummaryPath(path):
+		return ChunkTypeSummary
+	case slices.Contains(docExtensions, ext):
+		return ChunkTypeDoc
+	case slices.Contains(configExtensions, ext):
+		return ChunkTypeConfig
+	default:
+		return ChunkTypeCode
+	}
+}
+
+// ParseChunkTypes validates the chunk types of a search filter.
+func ParseChunkTypes(types []string) ([]string, error) {
+	parsed := make([]string, 0, len(types))
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch t {
+		case "":
+		case ChunkTypeCode, ChunkTypeDoc, ChunkTypeConfig, ChunkTypeSummary:
+			parsed = append(parsed, t)
+		default:
+			return nil, errcode.New(errcode.InvalidArgs, "invalid chunk type %q: use code, doc, config or summary", t)
+		}
+	}
+	return parsed, nil
+}
+
+// backfillChunkTypes returns the statement setting the type of chunks saved
+// before types were stored, and its arguments. op is the backend's regular
+// expression operator and placeholder returns the placeholder of the nth
+// parameter.
+func backfillChunkTypes(table, op string, placeholder func(n int) string) (string, []any) {
+	query := `UPDATE ` + table + ` SET chunk_type = CASE
+			WHEN lower(file_path) ` + op + ` ` + placeholder(1) + ` THEN '` + ChunkTypeDoc + `'
+			WHEN lower(file_path) ` + op + ` ` + placeholder(2) + ` THEN '` + ChunkTypeConfig + `'
+			ELSE '` + ChunkTypeCode + `' END
+		WHERE chunk_type = ''`
+	return query, []any{extensionsRegex(docExtensions), extensionsRegex(configExtensions)}
+}
+
+// chunkType returns the type of c, from its file when not set.
+func chunkType(c Chunk) string {
+	if c.Type != "" {
+		return c.Type
+	}
+	return ChunkTypeOf(c.FilePath)
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character. These
+// scripts are written without spaces between words, so the 'simple' and
+// unicode61 tokenizers treat a whole run of them as a single token.
+// The prolonged sound mark (ー) belongs to the Common script but is part of
+// Japanese words.
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// cjkBigrams splits every run of CJK characters in s into overlapping
+// bigrams ("数据库" -> "数据", "据库"). Runs of a single character are
+// returned as-is.
+func cjkBigrams(s string) []string {
+	var tokens []string
+	var run []rune
+	flush := func() {
+		switch {
+		case len(run) == 1:
+			tokens = append(tokens, string(run))
+		case len(run) > 1:
+			for i := 0; i+1 < len(run); i++ {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+		}
+		run = run[:0]
+	}
+
+	for _, r := range s {
+		if isCJK(r) {
+			run = append(run, r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// expandCJKText returns the text to index for content: the original content
+// followed by the bigrams of its CJK runs, so both can be matched.
+func expandCJKText(content string) string {
+	bigrams := cjkBigrams(content)
+	if len(bigrams) == 0 {
+		return content
+	}
+	return content + "\n" + strings.Join(bigrams, " ")
+}
+
+// queryTerms splits a query into search terms. With cjk enabled, CJK runs
+// are replaced by their bigrams and separated from adjacent non-CJK text.
+func queryTerms(query string, cjk bool) []string {
+	words := strings.Fields(query)
+	if !cjk {
+		return words
+	}
+
+	var terms []string
+	for _, word := range words {
+		var other strings.Builder
+		flushOther := func() {
+			if other.Len() > 0 {
+				terms = append(terms, other.String())
+				other.Reset()
+			}
+		}
+
+		start := -1
+		for i, r := range word {
+			if isCJK(r) {
+				if start < 0 {
+					flushOther()
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				terms = append(terms, cjkBigrams(word[start:i])...)
+				start = -1
+			}
+			other.WriteRune(r)
+		}
+		if start >= 0 {
+			terms = append(terms, cjkBigrams(word[start:])...)
+		}
+		flushOther()
+	}
+	return terms
+}
+
+// HasCJK reports whether s contains any CJK character.
+func HasCJK(s string) bool {
+	return strings.IndexFunc(s, isCJK) >= 0
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCJKBigrams(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"hello world", nil},
+		{"数据库", []string{"数据", "据库"}},
+		{"连接数据库 failed 错", []string{"连接", "接数", "数据", "据库", "错"}},
+		{"ユーザー認証", []string{"ユー", "ーザ", "ザー", "ー認", "認証"}},
+		{"사용자", []string{"사용", "용자"}},
+	}
+	for _, tt := range tests {
+		if got := cjkBigrams(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cjkBigrams(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		cjk   bool
+		want  []string
+	}{
+		{"user login", true, []string{"user", "login"}},
+		{"数据库连接", false, []string{"数据库连接"}},
+		{"数据库连接", true, []string{"数据", "据库", "库连", "连接"}},
+		{"user用户login", true, []string{"user", "用户", "login"}},
+		{"库", true, []string{"库"}},
+	}
+	for _, tt := range tests {
+		if got := queryTerms(tt.query, tt.cjk); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("queryTerms(%q, %v) = %v, want %v", tt.query, tt.cjk, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteFTSStore_CJKBigrams(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+	st.cjkBigrams = true
+
+	chunks := []Chunk{
+		{ID: "db.go_0", FilePath: "db.go", StartLine: 1, EndLine: 3, Content: "// 建立数据库连接\nfunc Connect() {}", Hash: "a", UpdatedAt: time.Now()},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	for _, query := range []string{"数据库", "连接", "数据库 Connect"} {
+		results, err := st.SearchFTS(ctx, query, 10)
+		if err != nil {
+			t.Fatalf("SearchFTS(%q) failed: %v", query, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("SearchFTS(%q): expected 1 result, got %d", query, len(results))
+		}
+	}
+
+	// Stored content is not altered by the bigram expansion
+	got, _ := st.GetChunksForFile(ctx, "db.go")
+	if len(got) != 1 || got[0].Content != chunks[0].Content {
+		t.Errorf("expected original content to be stored, got %+v", got)
+	}
+}
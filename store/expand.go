@@ -0,0 +1,64 @@
+package store
+
+import (
+	"slices"
+	"strings"
+)
+
+// ExpandFunc returns the alternatives to match for a query term, including
+// the term itself. A nil ExpandFunc disables query expansion.
+type ExpandFunc func(term string) []string
+
+// queryGroups splits a query into terms and expands each term into a group
+// of alternatives. A chunk matches when every group has a matching term.
+// With split, identifiers made of several words also match their
+// IdentifierVariants.
+func queryGroups(query string, cjk, split bool, expand ExpandFunc) [][]string {
+	terms := queryTerms(query, cjk)
+	groups := make([][]string, len(terms))
+	for i, term := range terms {
+		group := []string{term}
+		if expand != nil {
+			group = expand(term)
+		}
+		if split {
+			group = addAlternatives(group, IdentifierVariants(term))
+		}
+		groups[i] = group
+	}
+	return groups
+}
+
+// addAlternatives appends to group the alternatives it lacks, ignoring case.
+func addAlternatives(group, alts []string) []string {
+	for _, alt := range alts {
+		if !slices.ContainsFunc(group, func(t string) bool { return strings.EqualFold(t, alt) }) {
+			group = append(group, alt)
+		}
+	}
+	return group
+}
+
+// MatchTerms returns the terms a query matches as prefixes: every
+// alternative of every term, as searched by a store opened with cjk, split
+// and expand. The stop words of natural-language queries are left out.
+func MatchTerms(query string, cjk, split bool, expand ExpandFunc) []string {
+	var terms []string
+	for _, group := range queryGroups(planStoreQuery(query, cjk).Text, cjk, split, expand) {
+		for _, term := range group {
+			if term = sanitizeTerm(term); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// flattenGroups returns every alternative of every group, space separated.
+func flattenGroups(groups [][]string) string {
+	var terms []string
+	for _, group := range groups {
+		terms = append(terms, group...)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuildQueryGroups(t *testing.T) {
+	groups := [][]string{{"login", "auth"}, {"handler"}, {"\x00"}}
+	if got, want := buildTSQueryGroups(groups), `('login':* | 'auth':*) & 'handler':*`; got != want {
+		t.Errorf("buildTSQueryGroups = %s, want %s", got, want)
+	}
+	if got, want := buildFTS5QueryGroups(groups), `("login"* OR "auth"*) AND "handler"*`; got != want {
+		t.Errorf("buildFTS5QueryGroups = %s, want %s", got, want)
+	}
+}
+
+func TestSQLiteFTSStore_ExpandTerm(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	chunks := []Chunk{
+		{ID: "auth.go_0", FilePath: "auth.go", StartLine: 1, EndLine: 3, Content: "func Authenticate(user_name string) error {}", Hash: "a", UpdatedAt: time.Now()},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	results, err := st.SearchFTS(ctx, "login", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no results without expansion, got %d", len(results))
+	}
+
+	st.expandTerm = func(term string) []string {
+		if term == "login" {
+			return []string{"login", "authenticate"}
+		}
+		return []string{term}
+	}
+	results, err = st.SearchFTS(ctx, "login", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected expanded query to match, got %d results", len(results))
+	}
+}
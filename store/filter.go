@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// SearchFilter restricts a search to a subset of files. Path conditions are
+// evaluated by the database as part of the search query, so filtered
+// searches still return up to the requested number of results.
+type SearchFilter struct {
+	Include []string // glob patterns; files must match at least one
+	Exclude []string // glob patterns; files matching any are skipped
+	Langs   []string // languages (go, typescript) or extensions (vue, .vue)
+	Types   []string // chunk types: code, doc, config or summary
+	// Symbols and Packages restrict results to chunks whose symbol, or
+	// package, is one of those named (see Chunk)
+	Symbols  []string
+	Packages []string
+	Deleted  bool // search soft-deleted chunks instead of live ones
+}
+
+// FilteredSearcher is implemented by stores that can restrict a search to
+// files matching a SearchFilter.
+type FilteredSearcher interface {
+	SearchFiltered(ctx context.Context, query string, limit int, filter SearchFilter) ([]SearchResult, error)
+}
+
+// langExtensions maps language names to the file extensions they cover.
+// Names not listed are taken as an extension.
+var langExtensions = map[string][]string{
+	"go":         {"go"},
+	"javascript": {"js", "jsx", "mjs", "cjs"},
+	"js":         {"js", "jsx", "mjs", "cjs"},
+	"typescript": {"ts", "tsx", "mts", "cts"},
+	"ts":         {"ts", "tsx", "mts", "cts"},
+	"python":     {"py", "pyi"},
+	"py":         {"py", "pyi"},
+	"ruby":       {"rb"},
+	"rust":       {"rs"},
+	"c":          {"c", "h"},
+	"cpp":        {"cpp", "cc", "cxx", "hpp", "hh", "hxx", "h"},
+	"c++":        {"cpp", "cc", "cxx", "hpp", "hh", "hxx", "h"},
+	"csharp":     {"cs"},
+	"c#":         {"cs"},
+	"kotlin":     {"kt", "kts"},
+	"shell":      {"sh", "bash", "zsh"},
+	"markdown":   {"md", "mdx"},
+	"yaml":       {"yaml", "yml"},
+}
+
+// sqlCondition returns the SQL conditions of the path, type, symbol and
+// package filters, each prefixed with AND, or "" when none is set. prefix
+// qualifies the chunk columns, op is the backend's regular expression
+// operator and arg appends a query parameter and returns its placeholder.
+// Values are always passed as parameters.
+func (f SearchFilter) sqlCondition(prefix, op string, arg func(v any) string) (string, error) {
+	column := prefix + "file_path"
+	var b strings.Builder
+	if len(f.Include) > 0 {
+		re, err := globsRegex(f.Include)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, " AND %s %s %s", column, op, arg(re))
+	}
+	if re := langsRegex(f.Langs); re != "" {
+		fmt.Fprintf(&b, " AND %s %s %s", column, op, arg(re))
+	}
+	if len(f.Exclude) > 0 {
+		re, err := globsRegex(f.Exclude)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, " AND NOT (%s %s %s)", column, op, arg(re))
+	}
+	if len(f.Types) > 0 {
+		placeholders := make([]string, len(f.Types))
+		for i, t := range f.Types {
+			placeholders[i] = arg(t)
+		}
+		fmt.Fprintf(&b, " AND %schunk_type IN (%s)", prefix, strings.Join(placeholders, ", "))
+	}
+	for _, in := range []struct {
+		column string
+		values []string
+	}{{"symbol_name", f.Symbols}, {"package_name", f.Packages}} {
+		if len(in.values) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(in.values))
+		for i, v := range in.values {
+			placeholders[i] = arg(v)
+		}
+		fmt.Fprintf(&b, " AND %s%s IN (%s)", prefix, in.column, strings.Join(placeholders, ", "))
+	}
+	return b.String(), nil
+}
+
+// globsRegex returns a regular expression matching paths that match any of
+// the glob patterns. The syntax is the subset shared by Go's regexp package
+// and PostgreSQL's advanced regular expressions.
+func globsRegex(patterns []string) (string, error) {
+	alts := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		re, err := globRegex(pattern)
+		if err != nil {
+			return "", err
+		}
+		alts[i] = re
+	}
+	return "^(?:" + strings.Join(alts, "|") + ")$", nil
+}
+
+// globRegex translates a doublestar glob pattern. As with 'agentdx files',
+// patterns without a slash match at any depth (*.go is **/*.go), and a
+// trailing slash matches everything below a directory.
+func globRegex(pattern string) (string, error) {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**") {
+		pattern = "**/" + pattern
+	}
+	if !doublestar.ValidatePattern(pattern) {
+		return "", fmt.Errorf("invalid glob pattern: %s", pattern)
+	}
+
+	var b strings.Builder
+	braces := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '{':
+			b.WriteString("(?:")
+			braces++
+		case '}':
+			b.WriteString(")")
+			braces--
+		case ',':
+			if braces > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']') + i + 1
+			class := pattern[i+1 : end]
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		case '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	return b.String(), nil
+}
+
+// langsRegex returns a regular expression matching files of the languages,
+// or "" when langs names none.
+func langsRegex(langs []string) string {
+	var exts []string
+	for _, lang := range langs {
+		lang = strings.ToLower(strings.TrimPrefix(lang, "."))
+		if known, ok := langExtensions[lang]; ok {
+			exts = append(exts, known...)
+		} else if lang != "" {
+			exts = append(exts, lang)
+		}
+	}
+	return extensionsRegex(exts)
+}
+
+// extensionsRegex returns a regular expression matching files with any of
+// the extensions, or "" when there are none.
+func extensionsRegex(exts []string) string {
+	if len(exts) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(exts))
+	for i, ext := range exts {
+		quoted[i] = regexp.QuoteMeta(ext)
+	}
+	return `\.(?:` + strings.Join(quoted, "|") + `)$`
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+func TestGlobRegex_MatchesDoublestar(t *testing.T) {
+	paths := []string{
+		"main.go", "cli/search.go", "cli/search_test.go", "internal/auth/login.go",
+		"web/app.tsx", "web/app.ts", "docs/a,b.md", "a.b/c.go", "x1.go", "xy.go",
+	}
+	patterns := []string{
+		"*.go", "cli/*.go", "internal/**", "**/*_test.go", "web/*.{ts,tsx}",
+		"x?.go", "x[0-9].go", "x[!0-9].go", "a.b/*", "docs/a,b.md", "**",
+	}
+	for _, pattern := range patterns {
+		re, err := globsRegex([]string{pattern})
+		if err != nil {
+			t.Fatalf("globsRegex(%q) failed: %v", pattern, err)
+		}
+		compiled := regexp.MustCompile(re)
+		for _, path := range paths {
+			want, _ := doublestar.Match(normalizeTestGlob(pattern), path)
+			if got := compiled.MatchString(path); got != want {
+				t.Errorf("pattern %q (regex %s) on %q: got %v, want %v", pattern, re, path, got, want)
+			}
+		}
+	}
+}
+
+// normalizeTestGlob applies the recursive default of globRegex.
+func normalizeTestGlob(pattern string) string {
+	if !regexp.MustCompile(`/|\*\*`).MatchString(pattern) {
+		return "**/" + pattern
+	}
+	return pattern
+}
+
+func TestGlobRegex_TrailingSlashMatchesDirectory(t *testing.T) {
+	re, err := globsRegex([]string{"vendor/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiled := regexp.MustCompile(re)
+	if !compiled.MatchString("vendor/lib/a.go") || compiled.MatchString("src/vendor/a.go") {
+		t.Errorf("unexpected matches for %s", re)
+	}
+}
+
+func TestLangsRegex(t *testing.T) {
+	re := regexp.MustCompile(langsRegex([]string{"TypeScript", ".vue"}))
+	for path, want := range map[string]bool{
+		"web/app.ts":  true,
+		"web/app.tsx": true,
+		"web/App.vue": true,
+		"main.go":     false,
+		"web/ts":      false,
+	} {
+		if got := re.MatchString(path); got != want {
+			t.Errorf("%q: got %v, want %v", path, got, want)
+		}
+	}
+	if langsRegex([]string{""}) != "" {
+		t.Error("expected no condition for empty languages")
+	}
+}
+
+func TestChunkTypeOf(t *testing.T) {
+	tests := map[string]string{
+		"main.go":             ChunkTypeCode,
+		"docs/Guide.MD":       ChunkTypeDoc,
+		"notes.txt":           ChunkTypeDoc,
+		".agentdx/config.yml": ChunkTypeConfig,
+		"package.json":        ChunkTypeConfig,
+		"Makefile":            ChunkTypeCode,
+	}
+	for path, want := range tests {
+		if got := ChunkTypeOf(path); got != want {
+			t.Errorf("ChunkTypeOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if types, err := ParseChunkTypes([]string{"Doc", " config"}); err != nil || len(types) != 2 || types[0] != ChunkTypeDoc {
+		t.Errorf("ParseChunkTypes() = %v, %v", types, err)
+	}
+	if _, err := ParseChunkTypes([]string{"test"}); err == nil {
+		t.Error("expected an error for an unknown chunk type")
+	}
+}
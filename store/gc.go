@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// GCStats reports what a garbage collection pass removed.
+type GCStats struct {
+	OrphanChunks    int   // chunks not referenced by any document
+	OrphanIndexRows int   // full-text index rows without a chunk
+	SizeBefore      int64 // bytes used by the index before the pass
+	SizeAfter       int64 // bytes used by the index after the pass
+}
+
+// Reclaimed returns the bytes freed by the pass.
+func (s GCStats) Reclaimed() int64 {
+	if s.SizeAfter >= s.SizeBefore {
+		return 0
+	}
+	return s.SizeBefore - s.SizeAfter
+}
+
+// GarbageCollector is implemented by backends that can remove rows left
+// behind by deleted projects and interrupted indexing.
+type GarbageCollector interface {
+	// CollectGarbage removes chunks of every project in the database that
+	// no document references and were last updated before cutoff, drops
+	// full-text index rows without a chunk, and compacts the tables.
+	// Chunks that are soft-deleted but still referenced are left to
+	// PurgeDeleted.
+	CollectGarbage(ctx context.Context, cutoff time.Time) (*GCStats, error)
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// SplitFunc reports whether the identifiers of the file at path are indexed
+// as sub-tokens. A nil SplitFunc disables identifier splitting.
+type SplitFunc func(path string) bool
+
+// NewSplitFunc returns the SplitFunc splitting identifiers in every file when
+// split is set, except in the languages set to false in langs, and in the
+// languages set to true otherwise. Languages are named as for
+// SearchFilter.Langs (go, typescript) or by extension (vue). It returns nil
+// when no file is split.
+func NewSplitFunc(split bool, langs map[string]bool) SplitFunc {
+	overrides := make(map[string]bool)
+	enabled := split
+	for lang, on := range langs {
+		lang = strings.ToLower(strings.TrimPrefix(lang, "."))
+		exts, ok := langExtensions[lang]
+		if !ok {
+			exts = []string{lang}
+		}
+		for _, ext := range exts {
+			overrides[ext] = on
+		}
+		enabled = enabled || on
+	}
+	if !enabled {
+		return nil
+	}
+	return func(path string) bool {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		if on, ok := overrides[ext]; ok {
+			return on
+		}
+		return split
+	}
+}
+
+// SplitIdentifier splits a camelCase, PascalCase, snake_case or kebab-case
+// identifier into lowercase words.
+func SplitIdentifier(s string) []string {
+	var parts []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			parts = append(parts, strings.ToLower(string(cur)))
+			cur = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split before a hump (userName) and at the end of an
+			// acronym (HTTPServer)
+			if !unicode.IsUpper(prev) || nextLower {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return parts
+}
+
+// IdentifierVariants returns the snake_case and joined lowercase forms of an
+// identifier made of several words ("getUser" -> "get_user", "getuser"), or
+// nil for a single word. The tokenizers read get_user as the phrase
+// "get user", so the variants match identifiers written in either style.
+func IdentifierVariants(term string) []string {
+	parts := SplitIdentifier(term)
+	if len(parts) < 2 {
+		return nil
+	}
+	return []string{strings.Join(parts, "_"), strings.Join(parts, "")}
+}
+
+// isIdentifierRune reports whether r belongs to an identifier.
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// identifierTokens returns the extra tokens indexed for the identifiers of
+// content: the words of every identifier made of several words, which the
+// tokenizers keep as a single token (getUser), and the joined form of those
+// they split (get_user -> getuser). Each identifier is expanded once.
+func identifierTokens(content string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, ident := range strings.FieldsFunc(content, func(r rune) bool { return !isIdentifierRune(r) }) {
+		if seen[ident] {
+			continue
+		}
+		seen[ident] = true
+		parts := SplitIdentifier(ident)
+		if len(parts) < 2 {
+			continue
+		}
+		tokens = append(tokens, strings.Join(parts, " "))
+		if strings.ContainsAny(ident, "_-") {
+			tokens = append(tokens, strings.Join(parts, ""))
+		}
+	}
+	return tokens
+}
+
+// expandIdentifierText returns the text to index for content: the original
+// content followed by the sub-tokens of its identifiers, so that "user"
+// matches getUser and getUser matches get_user.
+func expandIdentifierText(content string) string {
+	tokens := identifierTokens(content)
+	if len(tokens) == 0 {
+		return content
+	}
+	return content + "\n" + strings.Join(tokens, " ")
+}
+
+// indexText returns the text to index for content of the file at path, with
+// CJK bigrams when cjk is set and identifier sub-tokens when split selects
+// the file.
+func indexText(path, content string, cjk bool, split SplitFunc) string {
+	if cjk {
+		content = expandCJKText(content)
+	}
+	if split != nil && split(path) {
+		content = expandIdentifierText(content)
+	}
+	return content
+}
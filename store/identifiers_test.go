@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := map[string][]string{
+		"userName":    {"user", "name"},
+		"user_name":   {"user", "name"},
+		"user-name":   {"user", "name"},
+		"HTTPServer":  {"http", "server"},
+		"parseJSON":   {"parse", "json"},
+		"checkout":    {"checkout"},
+		"__private__": {"private"},
+	}
+	for input, want := range tests {
+		if got := SplitIdentifier(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitIdentifier(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestIdentifierTokens(t *testing.T) {
+	got := identifierTokens("func getUser(user_id int) *User { return getUser(user_id) }")
+	want := []string{"get user", "user id", "userid"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("identifierTokens() = %q, want %q", got, want)
+	}
+	if got := expandIdentifierText("plain words only"); got != "plain words only" {
+		t.Errorf("expected text without compound identifiers unchanged, got %q", got)
+	}
+}
+
+func TestNewSplitFunc(t *testing.T) {
+	if NewSplitFunc(false, nil) != nil || NewSplitFunc(false, map[string]bool{"go": false}) != nil {
+		t.Error("expected nil when no language splits identifiers")
+	}
+
+	split := NewSplitFunc(true, map[string]bool{"markdown": false})
+	for path, want := range map[string]bool{"user.go": true, "README.md": false, "docs/a.MDX": false, "Makefile": true} {
+		if got := split(path); got != want {
+			t.Errorf("split(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	split = NewSplitFunc(false, map[string]bool{"ts": true, ".vue": true})
+	for path, want := range map[string]bool{"app.tsx": true, "App.vue": true, "main.go": false} {
+		if got := split(path); got != want {
+			t.Errorf("split(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestQueryGroups_SplitIdentifiers(t *testing.T) {
+	got := queryGroups("getUser by id", false, true, nil)
+	// The joined variant equals the term once lowercased by the tokenizers
+	want := [][]string{{"getUser", "get_user"}, {"by"}, {"id"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("queryGroups() = %q, want %q", got, want)
+	}
+
+	// Variants already added by expansion are not repeated
+	expand := func(term string) []string { return []string{term, "GET_USER"} }
+	if got := queryGroups("getUser", false, true, expand); len(got[0]) != 2 {
+		t.Errorf("expected duplicate variants to be dropped, got %q", got[0])
+	}
+}
+
+func TestSQLiteFTSStore_SplitIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+	st.splitIdentifiers = NewSplitFunc(true, map[string]bool{"markdown": false})
+
+	chunks := []Chunk{
+		{ID: "user.go_0", FilePath: "user.go", StartLine: 1, EndLine: 1, Content: "func getUser(id int) *User {}", Hash: "a", UpdatedAt: time.Now()},
+		{ID: "db.py_0", FilePath: "db.py", StartLine: 1, EndLine: 1, Content: "def load_account(account_id):", Hash: "b", UpdatedAt: time.Now()},
+		{ID: "notes.md_0", FilePath: "notes.md", StartLine: 1, EndLine: 1, Content: "Call resetPassword first.", Hash: "c", UpdatedAt: time.Now()},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"get_user", []string{"user.go"}},        // snake_case query, camelCase code
+		{"loadAccount", []string{"db.py"}},       // camelCase query, snake_case code
+		{"user", []string{"user.go"}},            // sub-token of an identifier
+		{"accountid", []string{"db.py"}},         // joined form
+		{"password", nil},                        // splitting is off for Markdown
+		{"reset_password", []string{"notes.md"}}, // queries still match the identifier
+	}
+	for _, tt := range tests {
+		results, err := st.SearchFTS(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("SearchFTS(%q) failed: %v", tt.query, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Chunk.FilePath)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchFTS(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Stored content is not altered by the expansion
+	got, _ := st.GetChunksForFile(ctx, "user.go")
+	if len(got) != 1 || got[0].Content != chunks[0].Content {
+		t.Errorf("expected original content to be stored, got %+v", got)
+	}
+}
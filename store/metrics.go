@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Query kinds recorded by the query metrics.
+const (
+	QueryKindSearch = "search"
+	QueryKindTrace  = "trace"
+)
+
+// QueryRecord is a search or trace query logged by the opt-in query metrics.
+type QueryRecord struct {
+	ID         int64     `json:"id"`
+	Kind       string    `json:"kind"` // search or trace
+	Source     string    `json:"source"`
+	QueryHash  string    `json:"query_hash"`
+	Query      string    `json:"query,omitempty"` // only kept with metrics.store_queries
+	LatencyMs  int64     `json:"latency_ms"`
+	Results    int       `json:"results"`
+	FollowedUp bool      `json:"followed_up"` // a result was read by a later MCP call
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// MetricsStore persists query metrics for the current project.
+type MetricsStore interface {
+	// RecordQuery stores a query and returns its ID.
+	RecordQuery(ctx context.Context, q QueryRecord) (int64, error)
+
+	// MarkFollowedUp flags a query whose results were read afterwards.
+	MarkFollowedUp(ctx context.Context, id int64) error
+
+	// ListQueries returns the queries recorded since a time, oldest first.
+	ListQueries(ctx context.Context, since time.Time) ([]QueryRecord, error)
+}
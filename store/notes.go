@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// Note is a user or agent annotation attached to a line range of a file.
+type Note struct {
+	ID        int64     `json:"id"`
+	FilePath  string    `json:"file_path"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Overlaps reports whether the note covers any line in [startLine, endLine].
+func (n Note) Overlaps(startLine, endLine int) bool {
+	return n.StartLine <= endLine && n.EndLine >= startLine
+}
+
+// Validate checks that the note has text and a valid line range.
+func (n Note) Validate() error {
+	switch {
+	case n.FilePath == "":
+		return errcode.New(errcode.InvalidArgs, "note requires a file path")
+	case n.Text == "":
+		return errcode.New(errcode.InvalidArgs, "note text is empty")
+	case n.StartLine < 1 || n.EndLine < n.StartLine:
+		return errcode.New(errcode.InvalidArgs, "invalid line range %d-%d", n.StartLine, n.EndLine)
+	}
+	return nil
+}
+
+// NoteStore persists notes for the current project.
+type NoteStore interface {
+	// AddNote stores a note and returns it with its ID and creation time set.
+	AddNote(ctx context.Context, note Note) (Note, error)
+
+	// ListNotes returns the notes for a file ordered by line, or all notes
+	// when filePath is empty.
+	ListNotes(ctx context.Context, filePath string) ([]Note, error)
+
+	// DeleteNote removes a note. Deleting an unknown ID is an error.
+	DeleteNote(ctx context.Context, id int64) error
+}
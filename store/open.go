@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/encrypt"
+	"github.com/doveaia/agentdx/errcode"
+	"github.com/doveaia/agentdx/trace"
+)
+
+// Supported storage backends.
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+)
+
+// SearchStore is a CodeStore that supports full-text search, status
+// reporting and project listing. Every backend returned by Open implements it.
+type SearchStore interface {
+	CodeStore
+	FTSSearcher
+	FilteredSearcher
+	StatusProvider
+	NoteStore
+	ProfileStore
+	MetricsStore
+	RetentionStore
+	GarbageCollector
+	RenameStore
+	ProjectRekeyer
+	IndexFingerprinter
+	ShadowSwapper
+	Snapshotter
+	SummaryStore
+	BulkSaver
+
+	// ProjectID returns the current project ID.
+	ProjectID() string
+
+	// GetAllProjects returns all unique project IDs with their file counts.
+	GetAllProjects(ctx context.Context) ([]ProjectInfo, error)
+}
+
+// Options selects and configures a storage backend.
+type Options struct {
+	Backend     string // postgres (default) or sqlite
+	PostgresDSN string
+	// PostgresNamespace gives the project its own schema; see ProjectSchema
+	PostgresNamespace string
+	SQLitePath        string
+	ProjectID         string
+	CJKBigrams        bool       // tokenize CJK text as bigrams at index and query time
+	ExpandTerm        ExpandFunc // expand query terms into alternatives; nil disables expansion
+	SplitIdentifiers  SplitFunc  // index identifier sub-tokens of the files it selects; nil disables splitting
+	// Encryption encrypts the GOB symbol index at rest; nil disables it
+	Encryption *encrypt.Source
+}
+
+// OptionsFromConfig builds the options of the index store of the project at
+// projectRoot from its configuration.
+func OptionsFromConfig(cfg *config.Config, projectRoot string) Options {
+	ids := cfg.Index.Search.Identifiers
+	return Options{
+		Backend:           cfg.Index.Store.Backend,
+		PostgresDSN:       cfg.Index.Store.Postgres.ConnString(),
+		PostgresNamespace: cfg.Index.Store.Postgres.Namespace,
+		SQLitePath:        cfg.GetSQLiteIndexPath(projectRoot),
+		ProjectID:         cfg.ProjectID(projectRoot),
+		CJKBigrams:        cfg.Index.Search.CJKBigrams,
+		SplitIdentifiers:  NewSplitFunc(ids.Split, ids.Languages),
+		Encryption:        encrypt.NewSource(cfg.Encryption),
+	}
+}
+
+// Open connects to the configured backend. Connection failures carry the
+// errcode.BackendDown code.
+func Open(ctx context.Context, opts Options) (SearchStore, error) {
+	// Return untyped nil on error so callers can compare the store to nil
+	switch opts.Backend {
+	case "", BackendPostgres:
+		st, err := newPostgresFTSStore(ctx, opts.PostgresDSN, opts.ProjectID, opts.PostgresNamespace)
+		if err != nil {
+			return nil, errcode.Wrap(errcode.BackendDown, err)
+		}
+		st.cjkBigrams = opts.CJKBigrams
+		st.expandTerm = opts.ExpandTerm
+		st.splitIdentifiers = opts.SplitIdentifiers
+		return st, nil
+	case BackendSQLite:
+		st, err := NewSQLiteFTSStore(ctx, opts.SQLitePath, opts.ProjectID)
+		if err != nil {
+			return nil, errcode.Wrap(errcode.BackendDown, err)
+		}
+		st.cjkBigrams = opts.CJKBigrams
+		st.expandTerm = opts.ExpandTerm
+		st.splitIdentifiers = opts.SplitIdentifiers
+		return st, nil
+	default:
+		return nil, errcode.New(errcode.Config, "unknown store backend: %s", opts.Backend)
+	}
+}
+
+// OpenSymbolStore opens the symbol index storage selected by backend (the
+// trace.store setting). The GOB and bolt backends keep the index in path;
+// the Postgres backend uses the database and project of opts. Only the GOB
+// index is encrypted with opts.Encryption.
+func OpenSymbolStore(ctx context.Context, backend, path string, opts Options) (trace.SymbolStore, error) {
+	switch backend {
+	case "", SymbolBackendGOB:
+		st := trace.NewGOBSymbolStore(path)
+		st.SetEncryption(opts.Encryption)
+		return st, nil
+	case SymbolBackendBolt:
+		return trace.NewBoltSymbolStore(path), nil
+	case SymbolBackendPostgres:
+		if opts.PostgresDSN == "" {
+			return nil, fmt.Errorf("trace.store: postgres requires index.store.postgres.dsn")
+		}
+		db, err := newPostgresFTSStore(ctx, opts.PostgresDSN, opts.ProjectID, opts.PostgresNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return NewPostgresSymbolStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown symbol store backend: %s", backend)
+	}
+}
+
+// OpenProjectSymbolStore opens and loads the symbol index that
+// index.trace.store selects for the project at projectRoot.
+func OpenProjectSymbolStore(ctx context.Context, cfg *config.Config, projectRoot string) (trace.SymbolStore, error) {
+	st, err := OpenSymbolStore(ctx, cfg.Index.Trace.Store, cfg.GetSymbolStorePath(projectRoot), OptionsFromConfig(cfg, projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	if err := st.Load(ctx); err != nil {
+		// Closing a GOB store persists it; only release database connections
+		if pg, ok := st.(*PostgresSymbolStore); ok {
+			pg.Close()
+		}
+		return nil, err
+	}
+	return st, nil
+}
+
+// SearchName returns a human-readable name for a backend's search engine.
+func SearchName(backend string) string {
+	if backend == BackendSQLite {
+		return "SQLite FTS5"
+	}
+	return "PostgreSQL FTS"
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+)
+
+// QueryStrategy is how a search query is matched against the index.
+type QueryStrategy string
+
+const (
+	// StrategyPrefix matches every word of the query as a prefix
+	StrategyPrefix QueryStrategy = "prefix"
+	// StrategyIdentifier matches a code identifier exactly (ParseConfig,
+	// parse_config, parseConfig()), falling back to StrategyPrefix when
+	// nothing matches so that partial identifiers still find code
+	StrategyIdentifier QueryStrategy = "identifier"
+	// StrategyPhrase matches the words of a quoted query next to each other,
+	// in order
+	StrategyPhrase QueryStrategy = "phrase"
+	// StrategyNatural matches any significant word of a sentence, ranking
+	// chunks matching more of them higher
+	StrategyNatural QueryStrategy = "natural"
+)
+
+// QueryPlan is the strategy chosen for a query and the text it matches.
+type QueryPlan struct {
+	Strategy QueryStrategy
+	Text     string
+}
+
+// questionWords start queries asked as questions.
+var questionWords = map[string]bool{
+	"how": true, "what": true, "where": true, "why": true, "when": true,
+	"which": true, "who": true, "does": true, "do": true, "is": true,
+	"are": true, "can": true, "should": true,
+}
+
+// stopWords are the words a natural-language query matches no code by.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "do": true, "does": true, "for": true,
+	"from": true, "how": true, "i": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "should": true, "that": true, "the": true,
+	"this": true, "to": true, "we": true, "what": true, "when": true,
+	"where": true, "which": true, "who": true, "why": true, "with": true,
+}
+
+// PlanQuery chooses how query is matched:
+//   - a query in double quotes is a phrase;
+//   - a single CamelCase or snake_case word, or a word followed by
+//     parentheses, is an identifier;
+//   - a question, or a sentence of three or more words with stop words,
+//     is natural language, matched by its significant words;
+//   - anything else matches every word as a prefix.
+func PlanQuery(query string) QueryPlan {
+	query = strings.TrimSpace(query)
+
+	if len(query) > 2 && strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`) {
+		if phrase := strings.TrimSpace(query[1 : len(query)-1]); phrase != "" && !strings.Contains(phrase, `"`) {
+			return QueryPlan{Strategy: StrategyPhrase, Text: phrase}
+		}
+	}
+
+	if ident, ok := identifierQuery(query); ok {
+		return QueryPlan{Strategy: StrategyIdentifier, Text: ident}
+	}
+
+	if words, ok := naturalQuery(query); ok {
+		return QueryPlan{Strategy: StrategyNatural, Text: strings.Join(words, " ")}
+	}
+
+	return QueryPlan{Strategy: StrategyPrefix, Text: query}
+}
+
+// identifierQuery returns the identifier query looks up, without the
+// parentheses of a call or signature, and whether it is one.
+func identifierQuery(query string) (string, bool) {
+	ident := query
+	call := false
+	if i := strings.IndexByte(query, '('); i > 0 {
+		ident, call = strings.TrimSpace(query[:i]), true
+	}
+	if ident == "" || strings.ContainsFunc(ident, func(r rune) bool {
+		return r != '.' && (r == '-' || !isIdentifierRune(r))
+	}) {
+		return "", false
+	}
+	if call || strings.Contains(strings.Trim(ident, "_"), "_") {
+		return ident, true
+	}
+	// A case hump: parseConfig, ParseConfig, HTTPServer
+	for _, word := range strings.Split(ident, ".") {
+		if len(SplitIdentifier(word)) > 1 {
+			return ident, true
+		}
+	}
+	return "", false
+}
+
+// naturalQuery returns the significant words of query and whether it is a
+// natural-language question or sentence.
+func naturalQuery(query string) ([]string, bool) {
+	fields := strings.Fields(query)
+	if len(fields) < 2 {
+		return nil, false
+	}
+	question := strings.HasSuffix(query, "?") || questionWords[strings.ToLower(fields[0])]
+
+	var words []string
+	stops := 0
+	for _, field := range fields {
+		word := strings.TrimFunc(field, func(r rune) bool { return unicode.IsPunct(r) && r != '_' })
+		if word == "" {
+			continue
+		}
+		if stopWords[strings.ToLower(word)] {
+			stops++
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 || !(question || (len(fields) >= 3 && stops > 0)) {
+		return nil, false
+	}
+	return words, true
+}
+
+// planStoreQuery is PlanQuery for a store: CJK queries of a store indexing
+// CJK bigrams always match bigrams as prefixes.
+func planStoreQuery(query string, cjk bool) QueryPlan {
+	if cjk && HasCJK(query) {
+		return QueryPlan{Strategy: StrategyPrefix, Text: query}
+	}
+	return PlanQuery(query)
+}
+
+// withStrategy records the strategy that matched results.
+func withStrategy(results []SearchResult, strategy QueryStrategy) []SearchResult {
+	for i := range results {
+		results[i].Strategy = strategy
+	}
+	return results
+}
@@ -0,0 +1,48 @@
+package store
+
+import "testing"
+
+func TestPlanQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		strategy QueryStrategy
+		text     string
+	}{
+		{"login", StrategyPrefix, "login"},
+		{"user login", StrategyPrefix, "user login"},
+		{"user auth login session", StrategyPrefix, "user auth login session"},
+		{`"retry with backoff"`, StrategyPhrase, "retry with backoff"},
+		{`" spaced "`, StrategyPhrase, "spaced"},
+		{`""`, StrategyPrefix, `""`},
+		{`"a" "b"`, StrategyPrefix, `"a" "b"`},
+		{"ParseConfig", StrategyIdentifier, "ParseConfig"},
+		{"parseConfig", StrategyIdentifier, "parseConfig"},
+		{"HTTPServer", StrategyIdentifier, "HTTPServer"},
+		{"parse_config", StrategyIdentifier, "parse_config"},
+		{"parseConfig()", StrategyIdentifier, "parseConfig"},
+		{"load(cfg, root)", StrategyIdentifier, "load"},
+		{"store.OpenStore", StrategyIdentifier, "store.OpenStore"},
+		{"Config", StrategyPrefix, "Config"},
+		{"__init__", StrategyPrefix, "__init__"},
+		{"get-user", StrategyPrefix, "get-user"},
+		{"how are deleted files removed?", StrategyNatural, "deleted files removed"},
+		{"where is the config loaded", StrategyNatural, "config loaded"},
+		{"retry on connection errors", StrategyNatural, "retry connection errors"},
+		{"what is it?", StrategyPrefix, "what is it?"},
+	}
+	for _, tt := range tests {
+		got := PlanQuery(tt.query)
+		if got.Strategy != tt.strategy || got.Text != tt.text {
+			t.Errorf("PlanQuery(%q) = %s %q, want %s %q", tt.query, got.Strategy, got.Text, tt.strategy, tt.text)
+		}
+	}
+}
+
+func TestPlanStoreQuery_CJK(t *testing.T) {
+	if got := planStoreQuery(`"検索 機能"`, true); got.Strategy != StrategyPrefix {
+		t.Errorf("expected CJK queries to match bigrams as prefixes, got %s", got.Strategy)
+	}
+	if got := planStoreQuery(`"検索 機能"`, false); got.Strategy != StrategyPhrase {
+		t.Errorf("expected a phrase without CJK bigrams, got %s", got.Strategy)
+	}
+}
@@ -3,9 +3,9 @@ package store
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/doveaia/agentdx/errcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,13 +14,19 @@ import (
 // It uses pg_textsearch extension for true BM25 ranking when available,
 // falling back to ts_rank with 'simple' configuration for code content.
 type PostgresFTSStore struct {
-	pool          *pgxpool.Pool
-	projectID     string
-	hasBM25       bool   // true if pg_textsearch extension is available
-	bm25IndexName string // name of the BM25 index for explicit queries
-	dsn           string
-	dbName        string
-	dbHost        string
+	pool             *pgxpool.Pool
+	projectID        string
+	hasBM25          bool   // true if pg_textsearch extension is available
+	bm25IndexName    string // name of the BM25 index for explicit queries
+	dsn              string
+	dbName           string
+	dbHost           string
+	namespace        string     // empty unless the project has its own schema
+	schema           string     // the project's schema in namespace
+	profilesTable    string     // ranking profiles, shared by the namespace's projects
+	cjkBigrams       bool       // index and query CJK text as bigrams
+	splitIdentifiers SplitFunc  // index identifier sub-tokens of the files it selects
+	expandTerm       ExpandFunc // expand query terms into alternatives
 }
 
 // BackendStatus returns the backend status
@@ -31,37 +37,57 @@ func (s *PostgresFTSStore) BackendStatus(ctx context.Context) *BackendStatus {
 			healthy = false
 		}
 	}
+	name := s.dbName
+	if s.schema != "" {
+		name += "." + s.schema
+	}
 	return &BackendStatus{
 		Type:    "postgres",
 		Host:    s.dbHost,
-		Name:    s.dbName,
+		Name:    name,
 		Healthy: healthy,
 	}
 }
 
 // NewPostgresFTSStore creates a new PostgresFTSStore with FTS support
 func NewPostgresFTSStore(ctx context.Context, dsn string, projectID string) (*PostgresFTSStore, error) {
+	return newPostgresFTSStore(ctx, dsn, projectID, "")
+}
+
+// newPostgresFTSStore opens the store of a project. With a namespace, the
+// project's tables live in its own schema (see ProjectSchema), which is
+// created on first use.
+func newPostgresFTSStore(ctx context.Context, dsn, projectID, namespace string) (*PostgresFTSStore, error) {
 	// Parse DSN to extract database name
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DSN: %w", err)
 	}
 
-	pool, err := pgxpool.New(ctx, dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
-	}
-
 	store := &PostgresFTSStore{
-		pool:          pool,
 		projectID:     projectID,
 		hasBM25:       false,
 		bm25IndexName: "idx_chunks_fts_bm25",
 		dsn:           dsn,
 		dbName:        config.ConnConfig.Config.Database,
 		dbHost:        config.ConnConfig.Config.Host,
+		profilesTable: "ranking_profiles",
+	}
+	if namespace != "" {
+		store.namespace = namespace
+		// A shadow index is rebuilt in the schema of the index it replaces
+		store.schema = ProjectSchema(namespace, liveProjectID(projectID))
+		store.profilesTable = quoteIdent(namespace) + ".ranking_profiles"
+		// Unqualified table names resolve to the project's schema
+		config.ConnConfig.RuntimeParams["search_path"] = quoteIdent(store.schema) + ", public"
 	}
 
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	store.pool = pool
+
 	if err := store.ensureSchema(ctx); err != nil {
 		pool.Close()
 		return nil, err
@@ -78,6 +104,12 @@ func (s *PostgresFTSStore) ensureSchema(ctx context.Context) error {
 	}
 	// If extension is not available, we'll fall back to ts_rank
 
+	if s.namespace != "" {
+		if err := s.provisionSchema(ctx); err != nil {
+			return err
+		}
+	}
+
 	queries := []string{
 		// Create chunks table with content for FTS
 		// Using 'simple' config to avoid stopword filtering (important for code)
@@ -88,10 +120,26 @@ func (s *PostgresFTSStore) ensureSchema(ctx context.Context) error {
 			start_line INTEGER NOT NULL,
 			end_line INTEGER NOT NULL,
 			content TEXT NOT NULL,
+			doc TEXT NOT NULL DEFAULT '',
+			chunk_type TEXT NOT NULL DEFAULT '',
+			symbol_name TEXT NOT NULL DEFAULT '',
+			symbol_kind TEXT NOT NULL DEFAULT '',
+			package_name TEXT NOT NULL DEFAULT '',
 			content_tsv tsvector,
 			hash TEXT NOT NULL,
-			updated_at TIMESTAMP NOT NULL
+			updated_at TIMESTAMP NOT NULL,
+			deleted_at TIMESTAMP
 		)`,
+		// Soft-delete columns for tables created before retention support
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		// Doc comments for tables created before they were indexed
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS doc TEXT NOT NULL DEFAULT ''`,
+		// Chunk types for tables created before they were stored
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS chunk_type TEXT NOT NULL DEFAULT ''`,
+		// Chunk metadata for tables created before it was stored
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS symbol_name TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS symbol_kind TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE chunks_fts ADD COLUMN IF NOT EXISTS package_name TEXT NOT NULL DEFAULT ''`,
 		// Index for project filtering
 		`CREATE INDEX IF NOT EXISTS idx_chunks_fts_project ON chunks_fts(project_id)`,
 		// Composite index for file-based operations
@@ -103,24 +151,71 @@ func (s *PostgresFTSStore) ensureSchema(ctx context.Context) error {
 			hash TEXT NOT NULL,
 			mod_time TIMESTAMP NOT NULL,
 			chunk_ids TEXT[] NOT NULL,
+			deleted_at TIMESTAMP,
 			PRIMARY KEY (project_id, path)
 		)`,
+		`ALTER TABLE documents_fts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		`ALTER TABLE documents_fts ADD COLUMN IF NOT EXISTS encoding TEXT NOT NULL DEFAULT ''`,
+		// Partial index for retention purges
+		`CREATE INDEX IF NOT EXISTS idx_chunks_fts_deleted ON chunks_fts(project_id, deleted_at) WHERE deleted_at IS NOT NULL`,
+		// Notes attached to line ranges; kept across re-indexing
+		`CREATE TABLE IF NOT EXISTS notes (
+			id BIGSERIAL PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_notes_file ON notes(project_id, file_path)`,
+		// Ranking profiles are shared across projects
+		`CREATE TABLE IF NOT EXISTS ` + s.profilesTable + ` (
+			name TEXT PRIMARY KEY,
+			data TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// Opt-in query metrics
+		`CREATE TABLE IF NOT EXISTS query_metrics (
+			id BIGSERIAL PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			source TEXT NOT NULL,
+			query_hash TEXT NOT NULL,
+			query TEXT NOT NULL DEFAULT '',
+			latency_ms BIGINT NOT NULL,
+			results INTEGER NOT NULL,
+			followed_up BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_query_metrics_created ON query_metrics(project_id, created_at)`,
+		// Settings each project's index was built with
+		`CREATE TABLE IF NOT EXISTS index_meta (
+			project_id TEXT PRIMARY KEY,
+			fingerprint TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
 	}
+	queries = append(queries, symbolSchema...)
 
 	for _, query := range queries {
 		if _, err := s.pool.Exec(ctx, query); err != nil {
 			return fmt.Errorf("failed to execute schema query: %w", err)
 		}
 	}
+	backfill, args := backfillChunkTypes("chunks_fts", "~", func(n int) string { return fmt.Sprintf("$%d", n) })
+	if _, err := s.pool.Exec(ctx, backfill, args...); err != nil {
+		return fmt.Errorf("failed to set chunk types: %w", err)
+	}
 
 	// Create search indexes based on available features
 	if s.hasBM25 {
 		// Use pg_textsearch BM25 index for true BM25 ranking
 		// 'simple' config preserves all tokens without stemming (important for code)
-		_, err := s.pool.Exec(ctx, fmt.Sprintf(
-			`CREATE INDEX IF NOT EXISTS %s ON chunks_fts USING bm25(content) WITH (text_config='simple')`,
-			s.bm25IndexName,
-		))
+		_, err := s.pool.Exec(ctx,
+			`CREATE INDEX IF NOT EXISTS `+quoteIdent(s.bm25IndexName)+
+				` ON chunks_fts USING bm25(content) WITH (text_config='simple')`,
+		)
 		if err != nil {
 			// BM25 index creation failed, fall back to GIN
 			s.hasBM25 = false
@@ -145,22 +240,32 @@ func (s *PostgresFTSStore) SaveChunks(ctx context.Context, chunks []Chunk) error
 	batch := &pgx.Batch{}
 
 	for _, chunk := range chunks {
+		text := indexText(chunk.FilePath, chunk.Content, s.cjkBigrams, s.splitIdentifiers)
+		doc := indexText(chunk.FilePath, chunk.Doc, s.cjkBigrams, s.splitIdentifiers)
+
 		// Use 'simple' text search configuration to preserve all tokens
 		// This is important for code since we don't want stopword removal
 		// or stemming that would drop important programming keywords
 		batch.Queue(
-			`INSERT INTO chunks_fts (id, project_id, file_path, start_line, end_line, content, content_tsv, hash, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, to_tsvector('simple', $6), $7, $8)
+			`INSERT INTO chunks_fts (id, project_id, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, content_tsv, hash, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $10, $12, $13, $14, $15, `+chunkTSV("$9", "$11")+`, $7, $8)
 			ON CONFLICT (id) DO UPDATE SET
 				file_path = EXCLUDED.file_path,
 				start_line = EXCLUDED.start_line,
 				end_line = EXCLUDED.end_line,
 				content = EXCLUDED.content,
+				doc = EXCLUDED.doc,
+				chunk_type = EXCLUDED.chunk_type,
+				symbol_name = EXCLUDED.symbol_name,
+				symbol_kind = EXCLUDED.symbol_kind,
+				package_name = EXCLUDED.package_name,
 				content_tsv = EXCLUDED.content_tsv,
 				hash = EXCLUDED.hash,
-				updated_at = EXCLUDED.updated_at`,
+				updated_at = EXCLUDED.updated_at,
+				deleted_at = NULL`,
 			chunk.ID, s.projectID, chunk.FilePath, chunk.StartLine, chunk.EndLine,
-			chunk.Content, chunk.Hash, chunk.UpdatedAt,
+			chunk.Content, chunk.Hash, chunk.UpdatedAt, text, chunk.Doc, doc, chunkType(chunk),
+			chunk.Symbol, chunk.SymbolKind, chunk.Package,
 		)
 	}
 
@@ -176,11 +281,19 @@ func (s *PostgresFTSStore) SaveChunks(ctx context.Context, chunks []Chunk) error
 	return nil
 }
 
-// DeleteByFile removes all chunks for a given file path
+// chunkTSV returns the SQL expression of a chunk's tsvector built from the
+// content and doc parameters. Doc comments get weight A so that ts_rank
+// ranks them above code, which keeps the default weight D.
+func chunkTSV(content, doc string) string {
+	return "setweight(to_tsvector('simple', " + doc + "), 'A') || to_tsvector('simple', " + content + ")"
+}
+
+// DeleteByFile soft-deletes all chunks for a given file path
 func (s *PostgresFTSStore) DeleteByFile(ctx context.Context, filePath string) error {
 	_, err := s.pool.Exec(ctx,
-		`DELETE FROM chunks_fts WHERE project_id = $1 AND file_path = $2`,
-		s.projectID, filePath,
+		`UPDATE chunks_fts SET deleted_at = $3
+		WHERE project_id = $1 AND file_path = $2 AND deleted_at IS NULL`,
+		s.projectID, filePath, time.Now(),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to delete chunks: %w", err)
@@ -192,52 +305,98 @@ func (s *PostgresFTSStore) DeleteByFile(ctx context.Context, filePath string) er
 // When pg_textsearch is available, it uses true BM25 ranking via the <@> operator.
 // Otherwise, it falls back to ts_rank with normalization.
 func (s *PostgresFTSStore) SearchFTS(ctx context.Context, query string, limit int) ([]SearchResult, error) {
-	words := strings.Fields(query)
-	if len(words) == 0 {
+	return s.SearchFiltered(ctx, query, limit, SearchFilter{})
+}
+
+// SearchDeletedFTS searches soft-deleted chunks
+func (s *PostgresFTSStore) SearchDeletedFTS(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return s.SearchFiltered(ctx, query, limit, SearchFilter{Deleted: true})
+}
+
+// docModTime selects the mod time of a chunk's document in search queries.
+const docModTime = `(SELECT d.mod_time FROM documents_fts d WHERE d.project_id = chunks_fts.project_id AND d.path = chunks_fts.file_path)`
+
+// SearchFiltered is SearchFTS restricted to files matching filter. The
+// query is matched with the strategy PlanQuery chooses for it.
+func (s *PostgresFTSStore) SearchFiltered(ctx context.Context, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	plan := planStoreQuery(query, s.cjkBigrams)
+	results, err := s.searchPlan(ctx, plan, limit, filter)
+	if err == nil && len(results) == 0 && plan.Strategy == StrategyIdentifier {
+		plan = QueryPlan{Strategy: StrategyPrefix, Text: query}
+		results, err = s.searchPlan(ctx, plan, limit, filter)
+	}
+	return withStrategy(results, plan.Strategy), err
+}
+
+// searchPlan runs the search of plan.
+func (s *PostgresFTSStore) searchPlan(ctx context.Context, plan QueryPlan, limit int, filter SearchFilter) ([]SearchResult, error) {
+	query := plan.Text
+	groups := queryGroups(query, s.cjkBigrams, s.splitIdentifiers != nil, s.expandTerm)
+	if len(groups) == 0 {
 		return nil, nil
 	}
 
+	// BM25 ranks by any matching term, so expanded alternatives are simply
+	// added to the query text
+	bm25Query := query
+	if plan.Strategy == StrategyPrefix && (s.expandTerm != nil || s.splitIdentifiers != nil) {
+		bm25Query = flattenGroups(groups)
+	}
+
+	// BM25 has no notion of word order, so phrases and identifiers, whose
+	// words must be adjacent, use the tsvector column
+	ordered := plan.Strategy == StrategyPhrase || plan.Strategy == StrategyIdentifier
+
 	var rows pgx.Rows
 	var err error
 
-	if s.hasBM25 {
+	// The BM25 index tokenizes raw content, so CJK bigram queries must use
+	// the tsvector column, which is built from the expanded text
+	if s.hasBM25 && !ordered && !(s.cjkBigrams && HasCJK(query)) {
 		// Use pg_textsearch BM25 ranking with <@> operator
 		// The operator returns negative BM25 scores (lower = more relevant)
 		// We negate the score to get positive values where higher = more relevant
 		//
 		// Using to_bm25query with explicit index name for compatibility with
-		// all query evaluation strategies
+		// all query evaluation strategies. The index name is passed as a
+		// parameter rather than interpolated.
+		args := []any{bm25Query, s.projectID, limit, s.bm25IndexName}
+		pathFilter, ferr := filter.sqlCondition("", "~", pgArg(&args))
+		if ferr != nil {
+			return nil, ferr
+		}
 		rows, err = s.pool.Query(ctx,
-			fmt.Sprintf(`SELECT id, file_path, start_line, end_line, content, hash, updated_at,
-				-(content <@> to_bm25query($1, '%s')) as score
+			`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at, deleted_at,
+				-(content <@> to_bm25query($1, $4)) as score, `+docModTime+`
 			FROM chunks_fts
-			WHERE project_id = $2
-			ORDER BY content <@> to_bm25query($1, '%s')
-			LIMIT $3`, s.bm25IndexName, s.bm25IndexName),
-			query, s.projectID, limit,
+			WHERE project_id = $2 AND `+liveFilter("deleted_at", filter.Deleted)+pathFilter+`
+			ORDER BY content <@> to_bm25query($1, $4), file_path, start_line
+			LIMIT $3`,
+			args...,
 		)
 	} else {
 		// Fall back to ts_rank with tsvector
-		// Build tsquery: word1 & word2 & word3 (all words must match)
-		tsqueryParts := make([]string, len(words))
-		for i, word := range words {
-			// Escape special characters and use prefix matching with :*
-			escapedWord := strings.ReplaceAll(word, "'", "''")
-			tsqueryParts[i] = escapedWord + ":*"
-		}
-		tsqueryStr := strings.Join(tsqueryParts, " & ")
+		// Build tsquery: 'word1':* & ('word2':* | 'alt2':*) (every word or
+		// one of its expansions must match), or the phrase or natural
+		// language tsquery of the plan
+		tsqueryFn, tsqueryStr := tsQuery(plan, groups)
 
 		// Use ts_rank with normalization to get scores
 		// Normalization 32 = divide rank by (rank + 1) to get 0-1 range
+		args := []any{tsqueryStr, s.projectID, limit}
+		pathFilter, ferr := filter.sqlCondition("", "~", pgArg(&args))
+		if ferr != nil {
+			return nil, ferr
+		}
 		rows, err = s.pool.Query(ctx,
-			`SELECT id, file_path, start_line, end_line, content, hash, updated_at,
-				ts_rank(content_tsv, to_tsquery('simple', $1), 32) as score
+			`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at, deleted_at,
+				ts_rank(content_tsv, `+tsqueryFn+`('simple', $1), 32) as score, `+docModTime+`
 			FROM chunks_fts
-			WHERE project_id = $2
-				AND content_tsv @@ to_tsquery('simple', $1)
-			ORDER BY score DESC
+			WHERE project_id = $2 AND `+liveFilter("deleted_at", filter.Deleted)+pathFilter+`
+				AND content_tsv @@ `+tsqueryFn+`('simple', $1)
+			ORDER BY score DESC, file_path, start_line
 			LIMIT $3`,
-			tsqueryStr, s.projectID, limit,
+			args...,
 		)
 	}
 
@@ -250,18 +409,23 @@ func (s *PostgresFTSStore) SearchFTS(ctx context.Context, query string, limit in
 	for rows.Next() {
 		var chunk Chunk
 		var score float32
+		var modTime *time.Time
 
 		if err := rows.Scan(
 			&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine,
-			&chunk.Content, &chunk.Hash, &chunk.UpdatedAt, &score,
+			&chunk.Content, &chunk.Type, &chunk.Symbol, &chunk.SymbolKind, &chunk.Package, &chunk.Hash, &chunk.UpdatedAt, &chunk.DeletedAt, &score, &modTime,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		results = append(results, SearchResult{
+		result := SearchResult{
 			Chunk: chunk,
 			Score: score,
-		})
+		}
+		if modTime != nil {
+			result.ModTime = *modTime
+		}
+		results = append(results, result)
 	}
 
 	return results, rows.Err()
@@ -273,9 +437,10 @@ func (s *PostgresFTSStore) GetDocument(ctx context.Context, filePath string) (*D
 	var modTime time.Time
 
 	err := s.pool.QueryRow(ctx,
-		`SELECT path, hash, mod_time, chunk_ids FROM documents_fts WHERE project_id = $1 AND path = $2`,
+		`SELECT path, hash, mod_time, chunk_ids, encoding FROM documents_fts
+		WHERE project_id = $1 AND path = $2 AND deleted_at IS NULL`,
 		s.projectID, filePath,
-	).Scan(&doc.Path, &doc.Hash, &modTime, &doc.ChunkIDs)
+	).Scan(&doc.Path, &doc.Hash, &modTime, &doc.ChunkIDs, &doc.Encoding)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -290,14 +455,8 @@ func (s *PostgresFTSStore) GetDocument(ctx context.Context, filePath string) (*D
 
 // SaveDocument stores document metadata
 func (s *PostgresFTSStore) SaveDocument(ctx context.Context, doc Document) error {
-	_, err := s.pool.Exec(ctx,
-		`INSERT INTO documents_fts (path, project_id, hash, mod_time, chunk_ids)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (project_id, path) DO UPDATE SET
-			hash = EXCLUDED.hash,
-			mod_time = EXCLUDED.mod_time,
-			chunk_ids = EXCLUDED.chunk_ids`,
-		doc.Path, s.projectID, doc.Hash, doc.ModTime, doc.ChunkIDs,
+	_, err := s.pool.Exec(ctx, saveDocumentSQL,
+		doc.Path, s.projectID, doc.Hash, doc.ModTime, doc.ChunkIDs, doc.Encoding,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save document: %w", err)
@@ -305,11 +464,116 @@ func (s *PostgresFTSStore) SaveDocument(ctx context.Context, doc Document) error
 	return nil
 }
 
-// DeleteDocument removes document metadata
+// saveDocumentSQL upserts a document from its path, project ID, hash, mod
+// time, chunk IDs and encoding.
+const saveDocumentSQL = `INSERT INTO documents_fts (path, project_id, hash, mod_time, chunk_ids, encoding)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (project_id, path) DO UPDATE SET
+		hash = EXCLUDED.hash,
+		mod_time = EXCLUDED.mod_time,
+		chunk_ids = EXCLUDED.chunk_ids,
+		encoding = EXCLUDED.encoding,
+		deleted_at = NULL`
+
+// SaveBulk copies the chunks of many files into a temporary table with
+// COPY and upserts them in a single statement, then saves their documents
+// in one batch. On the first index of a large project this is much faster
+// than SaveChunks, which sends an INSERT per chunk.
+func (s *PostgresFTSStore) SaveBulk(ctx context.Context, chunks []Chunk, docs []Document) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE chunks_load (
+		seq INTEGER,
+		id TEXT,
+		file_path TEXT,
+		start_line INTEGER,
+		end_line INTEGER,
+		content TEXT,
+		doc TEXT,
+		chunk_type TEXT,
+		symbol_name TEXT,
+		symbol_kind TEXT,
+		package_name TEXT,
+		hash TEXT,
+		updated_at TIMESTAMP,
+		content_text TEXT,
+		doc_text TEXT
+	) ON COMMIT DROP`)
+	if err != nil {
+		return fmt.Errorf("failed to create load table: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"chunks_load"},
+		[]string{"seq", "id", "file_path", "start_line", "end_line", "content", "doc", "chunk_type", "symbol_name", "symbol_kind", "package_name", "hash", "updated_at", "content_text", "doc_text"},
+		pgx.CopyFromSlice(len(chunks), func(i int) ([]any, error) {
+			c := chunks[i]
+			return []any{i, c.ID, c.FilePath, c.StartLine, c.EndLine, c.Content, c.Doc, chunkType(c), c.Symbol, c.SymbolKind, c.Package, c.Hash, c.UpdatedAt,
+				indexText(c.FilePath, c.Content, s.cjkBigrams, s.splitIdentifiers),
+				indexText(c.FilePath, c.Doc, s.cjkBigrams, s.splitIdentifiers)}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy chunks: %w", err)
+	}
+
+	// An upsert cannot update a row twice: the last copy of an ID wins, as
+	// with SaveChunks
+	_, err = tx.Exec(ctx,
+		`INSERT INTO chunks_fts (id, project_id, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, content_tsv, hash, updated_at)
+		SELECT DISTINCT ON (id) id, $1, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, `+chunkTSV("content_text", "doc_text")+`, hash, updated_at
+		FROM chunks_load
+		ORDER BY id, seq DESC
+		ON CONFLICT (id) DO UPDATE SET
+			file_path = EXCLUDED.file_path,
+			start_line = EXCLUDED.start_line,
+			end_line = EXCLUDED.end_line,
+			content = EXCLUDED.content,
+			doc = EXCLUDED.doc,
+			chunk_type = EXCLUDED.chunk_type,
+			symbol_name = EXCLUDED.symbol_name,
+			symbol_kind = EXCLUDED.symbol_kind,
+			package_name = EXCLUDED.package_name,
+			content_tsv = EXCLUDED.content_tsv,
+			hash = EXCLUDED.hash,
+			updated_at = EXCLUDED.updated_at,
+			deleted_at = NULL`,
+		s.projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save chunks: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, doc := range docs {
+		batch.Queue(saveDocumentSQL, doc.Path, s.projectID, doc.Hash, doc.ModTime, doc.ChunkIDs, doc.Encoding)
+	}
+	results := tx.SendBatch(ctx, batch)
+	for range docs {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("failed to save document: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to save documents: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit bulk save: %w", err)
+	}
+	return nil
+}
+
+// DeleteDocument soft-deletes document metadata
 func (s *PostgresFTSStore) DeleteDocument(ctx context.Context, filePath string) error {
 	_, err := s.pool.Exec(ctx,
-		`DELETE FROM documents_fts WHERE project_id = $1 AND path = $2`,
-		s.projectID, filePath,
+		`UPDATE documents_fts SET deleted_at = $3
+		WHERE project_id = $1 AND path = $2 AND deleted_at IS NULL`,
+		s.projectID, filePath, time.Now(),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
@@ -317,10 +581,21 @@ func (s *PostgresFTSStore) DeleteDocument(ctx context.Context, filePath string)
 	return nil
 }
 
-// ListDocuments returns all indexed document paths
+// ListDocuments returns all indexed document paths, except summaries
 func (s *PostgresFTSStore) ListDocuments(ctx context.Context) ([]string, error) {
+	return s.listPaths(ctx, "NOT LIKE")
+}
+
+// ListSummaries returns the paths of the indexed directory summaries
+func (s *PostgresFTSStore) ListSummaries(ctx context.Context) ([]string, error) {
+	return s.listPaths(ctx, "LIKE")
+}
+
+// listPaths returns the document paths that are (LIKE) or are not (NOT
+// LIKE) summary paths.
+func (s *PostgresFTSStore) listPaths(ctx context.Context, like string) ([]string, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT path FROM documents_fts WHERE project_id = $1`,
+		`SELECT path FROM documents_fts WHERE project_id = $1 AND deleted_at IS NULL AND path `+like+` '%/'`,
 		s.projectID,
 	)
 	if err != nil {
@@ -352,7 +627,7 @@ func (s *PostgresFTSStore) GetStats(ctx context.Context) (*IndexStats, error) {
 
 	// Get file count
 	err := s.pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM documents_fts WHERE project_id = $1`,
+		`SELECT COUNT(*) FROM documents_fts WHERE project_id = $1 AND deleted_at IS NULL AND path NOT LIKE '%/'`,
 		s.projectID,
 	).Scan(&stats.TotalFiles)
 	if err != nil {
@@ -361,7 +636,7 @@ func (s *PostgresFTSStore) GetStats(ctx context.Context) (*IndexStats, error) {
 
 	// Get chunk count and last updated
 	err = s.pool.QueryRow(ctx,
-		`SELECT COUNT(*), COALESCE(MAX(updated_at), '1970-01-01'::timestamp) FROM chunks_fts WHERE project_id = $1`,
+		`SELECT COUNT(*), COALESCE(MAX(updated_at), '1970-01-01'::timestamp) FROM chunks_fts WHERE project_id = $1 AND deleted_at IS NULL`,
 		s.projectID,
 	).Scan(&stats.TotalChunks, &stats.LastUpdated)
 	if err != nil {
@@ -377,7 +652,8 @@ func (s *PostgresFTSStore) GetStats(ctx context.Context) (*IndexStats, error) {
 // ListFilesWithStats returns all files with their chunk counts
 func (s *PostgresFTSStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT path, mod_time, array_length(chunk_ids, 1) FROM documents_fts WHERE project_id = $1`,
+		`SELECT path, mod_time, hash, array_length(chunk_ids, 1) FROM documents_fts
+		WHERE project_id = $1 AND deleted_at IS NULL AND path NOT LIKE '%/'`,
 		s.projectID,
 	)
 	if err != nil {
@@ -389,7 +665,7 @@ func (s *PostgresFTSStore) ListFilesWithStats(ctx context.Context) ([]FileStats,
 	for rows.Next() {
 		var f FileStats
 		var chunkCount *int
-		if err := rows.Scan(&f.Path, &f.ModTime, &chunkCount); err != nil {
+		if err := rows.Scan(&f.Path, &f.ModTime, &f.Hash, &chunkCount); err != nil {
 			return nil, fmt.Errorf("failed to scan file: %w", err)
 		}
 		if chunkCount != nil {
@@ -404,8 +680,8 @@ func (s *PostgresFTSStore) ListFilesWithStats(ctx context.Context) ([]FileStats,
 // GetChunksForFile returns all chunks for a specific file
 func (s *PostgresFTSStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, file_path, start_line, end_line, content, hash, updated_at
-		FROM chunks_fts WHERE project_id = $1 AND file_path = $2
+		`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at
+		FROM chunks_fts WHERE project_id = $1 AND file_path = $2 AND deleted_at IS NULL
 		ORDER BY start_line`,
 		s.projectID, filePath,
 	)
@@ -417,7 +693,7 @@ func (s *PostgresFTSStore) GetChunksForFile(ctx context.Context, filePath string
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Type, &c.Symbol, &c.SymbolKind, &c.Package, &c.Hash, &c.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
 		chunks = append(chunks, c)
@@ -429,8 +705,8 @@ func (s *PostgresFTSStore) GetChunksForFile(ctx context.Context, filePath string
 // GetAllChunks returns all chunks in the store
 func (s *PostgresFTSStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, file_path, start_line, end_line, content, hash, updated_at
-		FROM chunks_fts WHERE project_id = $1`,
+		`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at
+		FROM chunks_fts WHERE project_id = $1 AND deleted_at IS NULL`,
 		s.projectID,
 	)
 	if err != nil {
@@ -441,7 +717,7 @@ func (s *PostgresFTSStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Type, &c.Symbol, &c.SymbolKind, &c.Package, &c.Hash, &c.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
 		chunks = append(chunks, c)
@@ -470,6 +746,7 @@ func (s *PostgresFTSStore) GetAllProjects(ctx context.Context) ([]ProjectInfo, e
 	rows, err := s.pool.Query(ctx,
 		`SELECT project_id, COUNT(*) as file_count
 		FROM documents_fts
+		WHERE deleted_at IS NULL
 		GROUP BY project_id
 		ORDER BY project_id`,
 	)
@@ -484,6 +761,9 @@ func (s *PostgresFTSStore) GetAllProjects(ctx context.Context) ([]ProjectInfo, e
 		if err := rows.Scan(&p.ID, &p.FileCount); err != nil {
 			return nil, fmt.Errorf("failed to scan project: %w", err)
 		}
+		if IsShadowProjectID(p.ID) || IsSnapshotProjectID(p.ID) {
+			continue // Index being rebuilt by 'agentdx reindex', or a snapshot
+		}
 		projects = append(projects, p)
 	}
 
@@ -495,3 +775,609 @@ type ProjectInfo struct {
 	ID        string `json:"id"`
 	FileCount int    `json:"file_count"`
 }
+
+// AddNote stores a note for the current project
+func (s *PostgresFTSStore) AddNote(ctx context.Context, note Note) (Note, error) {
+	note.CreatedAt = time.Now()
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO notes (project_id, file_path, start_line, end_line, text, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		s.projectID, note.FilePath, note.StartLine, note.EndLine, note.Text, note.CreatedAt,
+	).Scan(&note.ID)
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to add note: %w", err)
+	}
+	return note, nil
+}
+
+// ListNotes returns notes for a file, or all notes when filePath is empty
+func (s *PostgresFTSStore) ListNotes(ctx context.Context, filePath string) ([]Note, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, file_path, start_line, end_line, text, created_at
+		FROM notes
+		WHERE project_id = $1 AND ($2 = '' OR file_path = $2)
+		ORDER BY file_path, start_line, id`,
+		s.projectID, filePath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.FilePath, &n.StartLine, &n.EndLine, &n.Text, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// DeleteNote removes a note from the current project
+func (s *PostgresFTSStore) DeleteNote(ctx context.Context, id int64) error {
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM notes WHERE project_id = $1 AND id = $2`,
+		s.projectID, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("note %d not found", id)
+	}
+	return nil
+}
+
+// SaveProfile creates or replaces a ranking profile
+func (s *PostgresFTSStore) SaveProfile(ctx context.Context, name, data string) error {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO `+s.profilesTable+` (name, data, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`,
+		name, data, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ranking profile: %w", err)
+	}
+	return nil
+}
+
+// GetProfile returns a ranking profile by name
+func (s *PostgresFTSStore) GetProfile(ctx context.Context, name string) (*Profile, error) {
+	p := Profile{Name: name}
+	err := s.pool.QueryRow(ctx,
+		`SELECT data, updated_at FROM `+s.profilesTable+` WHERE name = $1`,
+		name,
+	).Scan(&p.Data, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("ranking profile %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ranking profile: %w", err)
+	}
+	return &p, nil
+}
+
+// ListProfiles returns all ranking profiles
+func (s *PostgresFTSStore) ListProfiles(ctx context.Context) ([]Profile, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT name, data, updated_at FROM `+s.profilesTable+` ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ranking profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		var p Profile
+		if err := rows.Scan(&p.Name, &p.Data, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ranking profile: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// RecordQuery stores a query and returns its ID
+func (s *PostgresFTSStore) RecordQuery(ctx context.Context, q QueryRecord) (int64, error) {
+	var id int64
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO query_metrics (project_id, kind, source, query_hash, query, latency_ms, results, followed_up, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		s.projectID, q.Kind, q.Source, q.QueryHash, q.Query, q.LatencyMs, q.Results, q.FollowedUp, q.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record query: %w", err)
+	}
+	return id, nil
+}
+
+// MarkFollowedUp flags a query whose results were read afterwards
+func (s *PostgresFTSStore) MarkFollowedUp(ctx context.Context, id int64) error {
+	if _, err := s.pool.Exec(ctx,
+		`UPDATE query_metrics SET followed_up = TRUE WHERE project_id = $1 AND id = $2`,
+		s.projectID, id,
+	); err != nil {
+		return fmt.Errorf("failed to mark query followed up: %w", err)
+	}
+	return nil
+}
+
+// ListQueries returns the queries recorded since a time, oldest first
+func (s *PostgresFTSStore) ListQueries(ctx context.Context, since time.Time) ([]QueryRecord, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, kind, source, query_hash, query, latency_ms, results, followed_up, created_at
+		FROM query_metrics
+		WHERE project_id = $1 AND created_at >= $2
+		ORDER BY created_at, id`,
+		s.projectID, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queries: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []QueryRecord
+	for rows.Next() {
+		var q QueryRecord
+		if err := rows.Scan(&q.ID, &q.Kind, &q.Source, &q.QueryHash, &q.Query, &q.LatencyMs, &q.Results, &q.FollowedUp, &q.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// PurgeDeleted permanently removes rows soft-deleted before cutoff
+func (s *PostgresFTSStore) PurgeDeleted(ctx context.Context, cutoff time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM chunks_fts WHERE project_id = $1 AND deleted_at < $2`,
+		s.projectID, cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted chunks: %w", err)
+	}
+	if _, err := s.pool.Exec(ctx,
+		`DELETE FROM documents_fts WHERE project_id = $1 AND deleted_at < $2`,
+		s.projectID, cutoff,
+	); err != nil {
+		return 0, fmt.Errorf("failed to purge deleted documents: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// CollectGarbage removes orphaned chunks of all projects and vacuums the
+// index tables. Plain VACUUM makes the space reusable without locking the
+// tables; the reported size only shrinks when trailing pages are freed.
+func (s *PostgresFTSStore) CollectGarbage(ctx context.Context, cutoff time.Time) (*GCStats, error) {
+	stats := &GCStats{}
+	var err error
+	if stats.SizeBefore, err = s.tablesSize(ctx); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM chunks_fts c
+		WHERE c.updated_at < $1 AND NOT EXISTS (
+			SELECT 1 FROM documents_fts d
+			WHERE d.project_id = c.project_id AND d.path = c.file_path AND c.id = ANY(d.chunk_ids)
+		)`,
+		cutoff,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove orphaned chunks: %w", err)
+	}
+	stats.OrphanChunks = int(tag.RowsAffected())
+
+	for _, table := range []string{"chunks_fts", "documents_fts"} {
+		if _, err := s.pool.Exec(ctx, `VACUUM ANALYZE `+table); err != nil {
+			return nil, fmt.Errorf("failed to vacuum %s: %w", table, err)
+		}
+	}
+
+	if stats.SizeAfter, err = s.tablesSize(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// tablesSize returns the size of the index tables and their indexes in bytes.
+func (s *PostgresFTSStore) tablesSize(ctx context.Context) (int64, error) {
+	var size int64
+	err := s.pool.QueryRow(ctx,
+		`SELECT pg_total_relation_size('chunks_fts') + pg_total_relation_size('documents_fts')`,
+	).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index size: %w", err)
+	}
+	return size, nil
+}
+
+// DocumentsByHash returns the live and soft-deleted documents with a hash.
+func (s *PostgresFTSStore) DocumentsByHash(ctx context.Context, hash string) ([]Document, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT path, hash, mod_time, chunk_ids, encoding FROM documents_fts
+		WHERE project_id = $1 AND hash = $2`,
+		s.projectID, hash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := rows.Scan(&doc.Path, &doc.Hash, &doc.ModTime, &doc.ChunkIDs, &doc.Encoding); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// RenameFile moves a file's document, chunks and notes to newPath.
+func (s *PostgresFTSStore) RenameFile(ctx context.Context, oldPath, newPath string, relink func(Chunk) Chunk) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var oldIDs []string
+	err = tx.QueryRow(ctx,
+		`SELECT chunk_ids FROM documents_fts WHERE project_id = $1 AND path = $2`,
+		s.projectID, oldPath,
+	).Scan(&oldIDs)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("document not found: %s", oldPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	// Drop what was indexed at the new path
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM chunks_fts WHERE project_id = $1 AND file_path = $2`,
+		s.projectID, newPath,
+	); err != nil {
+		return fmt.Errorf("failed to replace chunks: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM documents_fts WHERE project_id = $1 AND path = $2`,
+		s.projectID, newPath,
+	); err != nil {
+		return fmt.Errorf("failed to replace document: %w", err)
+	}
+
+	newIDs := make([]string, 0, len(oldIDs))
+	for _, id := range oldIDs {
+		var c Chunk
+		err := tx.QueryRow(ctx,
+			`SELECT id, file_path, start_line, end_line, content, doc, symbol_name, symbol_kind, package_name, hash, updated_at
+			FROM chunks_fts WHERE project_id = $1 AND id = $2`,
+			s.projectID, id,
+		).Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Doc, &c.Symbol, &c.SymbolKind, &c.Package, &c.Hash, &c.UpdatedAt)
+		if err == pgx.ErrNoRows {
+			continue // Purged or replaced since the document was saved
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get chunk: %w", err)
+		}
+
+		c = relink(c)
+		text := indexText(newPath, c.Content, s.cjkBigrams, s.splitIdentifiers)
+		doc := indexText(newPath, c.Doc, s.cjkBigrams, s.splitIdentifiers)
+		if _, err := tx.Exec(ctx,
+			`UPDATE chunks_fts SET id = $1, file_path = $2, content = $3,
+				content_tsv = `+chunkTSV("$4", "$7")+`, chunk_type = $8, package_name = $9, deleted_at = NULL
+			WHERE project_id = $5 AND id = $6`,
+			c.ID, newPath, c.Content, text, s.projectID, id, doc, ChunkTypeOf(newPath), c.Package,
+		); err != nil {
+			return fmt.Errorf("failed to move chunk: %w", err)
+		}
+		newIDs = append(newIDs, c.ID)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE documents_fts SET path = $1, chunk_ids = $2, deleted_at = NULL
+		WHERE project_id = $3 AND path = $4`,
+		newPath, newIDs, s.projectID, oldPath,
+	); err != nil {
+		return fmt.Errorf("failed to move document: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE notes SET file_path = $1 WHERE project_id = $2 AND file_path = $3`,
+		newPath, s.projectID, oldPath,
+	); err != nil {
+		return fmt.Errorf("failed to move notes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit rename: %w", err)
+	}
+	return nil
+}
+
+// postgresProjectTables are the tables whose rows are keyed by project ID.
+var postgresProjectTables = []string{
+	"chunks_fts", "documents_fts", "notes", "query_metrics", "index_meta",
+	"symbol_files", "symbols", "symbol_refs", "symbol_meta",
+}
+
+// RekeyProject moves the project's rows to newID. In a namespace, the
+// project's schema is renamed to the schema of newID as well.
+func (s *PostgresFTSStore) RekeyProject(ctx context.Context, newID string) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	newSchema := ""
+	if s.namespace != "" {
+		newSchema = ProjectSchema(s.namespace, newID)
+		var exists bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)`, newSchema,
+		).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to check schema %s: %w", newSchema, err)
+		}
+		if exists {
+			return 0, fmt.Errorf("project %s already has a schema (%s) in namespace %s; delete it first", newID, newSchema, s.namespace)
+		}
+	} else {
+		var existing int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM documents_fts WHERE project_id = $1`, newID,
+		).Scan(&existing); err != nil {
+			return 0, fmt.Errorf("failed to check project %s: %w", newID, err)
+		}
+		if existing > 0 {
+			return 0, fmt.Errorf("project %s already has %d indexed files", newID, existing)
+		}
+	}
+
+	var moved int64
+	for _, table := range postgresProjectTables {
+		// Symbol tables only exist once trace.store postgres was used
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to check table %s: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		tag, err := tx.Exec(ctx,
+			`UPDATE `+table+` SET project_id = $1 WHERE project_id = $2`, newID, s.projectID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rekey %s: %w", table, err)
+		}
+		moved += tag.RowsAffected()
+	}
+
+	if newSchema != "" {
+		if _, err := tx.Exec(ctx, `ALTER SCHEMA `+quoteIdent(s.schema)+` RENAME TO `+quoteIdent(newSchema)); err != nil {
+			return 0, fmt.Errorf("failed to rename schema %s: %w", s.schema, err)
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE `+quoteIdent(s.namespace)+`.projects SET schema_name = $1, project_id = $2 WHERE schema_name = $3`,
+			newSchema, newID, s.schema,
+		); err != nil {
+			return 0, fmt.Errorf("failed to register schema %s: %w", newSchema, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rekey: %w", err)
+	}
+	return moved, nil
+}
+
+// IndexFingerprint returns the fingerprint recorded for the project's index.
+func (s *PostgresFTSStore) IndexFingerprint(ctx context.Context) (string, error) {
+	var fingerprint string
+	err := s.pool.QueryRow(ctx,
+		`SELECT fingerprint FROM index_meta WHERE project_id = $1`, s.projectID,
+	).Scan(&fingerprint)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get index fingerprint: %w", err)
+	}
+	return fingerprint, nil
+}
+
+// SetIndexFingerprint records the fingerprint of the project's index.
+func (s *PostgresFTSStore) SetIndexFingerprint(ctx context.Context, fingerprint string) error {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO index_meta (project_id, fingerprint, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (project_id) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			updated_at = EXCLUDED.updated_at`,
+		s.projectID, fingerprint, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set index fingerprint: %w", err)
+	}
+	return nil
+}
+
+// DiscardShadow removes the project's shadow index.
+func (s *PostgresFTSStore) DiscardShadow(ctx context.Context) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := deletePostgresIndex(ctx, tx, ShadowProjectID(s.projectID)); err != nil {
+		return fmt.Errorf("failed to discard shadow index: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit discard: %w", err)
+	}
+	return nil
+}
+
+// deletePostgresIndex removes the chunks, documents and fingerprint of
+// projectID.
+func deletePostgresIndex(ctx context.Context, tx pgx.Tx, projectID string) error {
+	for _, table := range []string{"chunks_fts", "documents_fts", "index_meta"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE project_id = $1`, projectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot copies the project's live index under the snapshot project
+// ID of commit.
+func (s *PostgresFTSStore) SaveSnapshot(ctx context.Context, commit string) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	snapshotID := SnapshotProjectID(s.projectID, commit)
+	prefix := snapshotChunkPrefix(commit)
+	if err := deletePostgresIndex(ctx, tx, snapshotID); err != nil {
+		return 0, fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO chunks_fts (id, project_id, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, content_tsv, hash, updated_at)
+		SELECT $1 || id, $2, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, content_tsv, hash, updated_at
+		FROM chunks_fts WHERE project_id = $3 AND deleted_at IS NULL`,
+		prefix, snapshotID, s.projectID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to copy chunks: %w", err)
+	}
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO documents_fts (path, project_id, hash, mod_time, chunk_ids, encoding)
+		SELECT path, $1, hash, mod_time,
+			ARRAY(SELECT $2 || id FROM unnest(chunk_ids) WITH ORDINALITY AS c(id, n) ORDER BY n), encoding
+		FROM documents_fts WHERE project_id = $3 AND deleted_at IS NULL`,
+		snapshotID, prefix, s.projectID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy documents: %w", err)
+	}
+	// The fingerprint row records when the snapshot was taken
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO index_meta (project_id, fingerprint, updated_at)
+		VALUES ($1, COALESCE((SELECT fingerprint FROM index_meta WHERE project_id = $2), ''), $3)`,
+		snapshotID, s.projectID, time.Now(),
+	); err != nil {
+		return 0, fmt.Errorf("failed to record snapshot: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ListSnapshots returns the project's snapshots, newest first.
+func (s *PostgresFTSStore) ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT m.project_id, m.updated_at,
+			(SELECT COUNT(*) FROM documents_fts d WHERE d.project_id = m.project_id)
+		FROM index_meta m
+		WHERE starts_with(m.project_id, $1)
+		ORDER BY m.updated_at DESC, m.project_id`,
+		snapshotPrefix,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+	return scanSnapshots(rows, s.projectID)
+}
+
+// DeleteSnapshot removes the project's snapshot of commit.
+func (s *PostgresFTSStore) DeleteSnapshot(ctx context.Context, commit string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	snapshotID := SnapshotProjectID(s.projectID, commit)
+	var exists int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM index_meta WHERE project_id = $1`, snapshotID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if exists == 0 {
+		return errcode.New(errcode.NotFound, "no snapshot of %s", commit)
+	}
+	if err := deletePostgresIndex(ctx, tx, snapshotID); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit snapshot deletion: %w", err)
+	}
+	return nil
+}
+
+// SwapShadow replaces the project's index with its shadow index.
+func (s *PostgresFTSStore) SwapShadow(ctx context.Context) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	shadowID := ShadowProjectID(s.projectID)
+	// Live rows are replaced unless they are soft-deleted files the shadow
+	// index does not have
+	for _, query := range []string{
+		`DELETE FROM chunks_fts WHERE project_id = $1 AND (deleted_at IS NULL
+			OR file_path IN (SELECT path FROM documents_fts WHERE project_id = $2))`,
+		`DELETE FROM documents_fts WHERE project_id = $1 AND (deleted_at IS NULL
+			OR path IN (SELECT path FROM documents_fts WHERE project_id = $2))`,
+	} {
+		if _, err := tx.Exec(ctx, query, s.projectID, shadowID); err != nil {
+			return 0, fmt.Errorf("failed to replace index: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM index_meta WHERE project_id = $1`, s.projectID); err != nil {
+		return 0, fmt.Errorf("failed to replace index fingerprint: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE chunks_fts SET project_id = $1, id = substr(id, $2) WHERE project_id = $3`,
+		s.projectID, len(shadowPrefix)+1, shadowID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to swap chunks: %w", err)
+	}
+	tag, err := tx.Exec(ctx,
+		`UPDATE documents_fts SET project_id = $1,
+			chunk_ids = ARRAY(SELECT substr(id, $2) FROM unnest(chunk_ids) WITH ORDINALITY AS c(id, n) ORDER BY n)
+		WHERE project_id = $3`,
+		s.projectID, len(shadowPrefix)+1, shadowID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to swap documents: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE index_meta SET project_id = $1 WHERE project_id = $2`, s.projectID, shadowID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to swap index fingerprint: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit swap: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
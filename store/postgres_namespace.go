@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NamespaceProject is a project schema provisioned in a namespace.
+type NamespaceProject struct {
+	Schema    string    `json:"schema"`
+	ProjectID string    `json:"project_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UsedAt    time.Time `json:"used_at"` // last time the project's store was opened
+}
+
+// ProjectSchema returns the schema holding a project's index in a
+// namespace. A project ID may be any string, such as an absolute path, a
+// UUID or a git remote URL, so it is hashed to fit in an identifier.
+func ProjectSchema(namespace, projectID string) string {
+	sum := sha256.Sum256([]byte(projectID))
+	return namespace + "_" + hex.EncodeToString(sum[:6])
+}
+
+// namespaceSchema returns the SQL creating the namespace's schema and the
+// registry of its project schemas.
+func namespaceSchema(namespace string) []string {
+	return []string{
+		`CREATE SCHEMA IF NOT EXISTS ` + quoteIdent(namespace),
+		`CREATE TABLE IF NOT EXISTS ` + quoteIdent(namespace) + `.projects (
+			schema_name TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP NOT NULL
+		)`,
+	}
+}
+
+// provisionSchema creates the project's schema and registers it in the
+// namespace.
+func (s *PostgresFTSStore) provisionSchema(ctx context.Context) error {
+	queries := append(namespaceSchema(s.namespace), `CREATE SCHEMA IF NOT EXISTS `+quoteIdent(s.schema))
+	for _, query := range queries {
+		if _, err := s.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", s.schema, err)
+		}
+	}
+
+	now := time.Now()
+	if _, err := s.pool.Exec(ctx,
+		`INSERT INTO `+quoteIdent(s.namespace)+`.projects (schema_name, project_id, created_at, used_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (schema_name) DO UPDATE SET used_at = EXCLUDED.used_at`,
+		s.schema, s.projectID, now,
+	); err != nil {
+		return fmt.Errorf("failed to register schema %s: %w", s.schema, err)
+	}
+	return nil
+}
+
+// ListNamespaceProjects returns the project schemas provisioned in a
+// namespace of the database at dsn.
+func ListNamespaceProjects(ctx context.Context, dsn, namespace string) ([]NamespaceProject, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer pool.Close()
+
+	for _, query := range namespaceSchema(namespace) {
+		if _, err := pool.Exec(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+		}
+	}
+	rows, err := pool.Query(ctx,
+		`SELECT schema_name, project_id, created_at, used_at
+		FROM `+quoteIdent(namespace)+`.projects ORDER BY project_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []NamespaceProject
+	for rows.Next() {
+		var p NamespaceProject
+		if err := rows.Scan(&p.Schema, &p.ProjectID, &p.CreatedAt, &p.UsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// DropNamespaceProject deletes a project schema and everything indexed in
+// it, and removes it from the namespace.
+func DropNamespaceProject(ctx context.Context, dsn, namespace, schema string) error {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DROP SCHEMA IF EXISTS `+quoteIdent(schema)+` CASCADE`); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", schema, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM `+quoteIdent(namespace)+`.projects WHERE schema_name = $1`, schema,
+	); err != nil {
+		return fmt.Errorf("failed to unregister schema %s: %w", schema, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectSchema(t *testing.T) {
+	a := ProjectSchema("alice", "/home/alice/src/app")
+	if a != ProjectSchema("alice", "/home/alice/src/app") {
+		t.Error("expected a stable schema name")
+	}
+	if !strings.HasPrefix(a, "alice_") || len(a) > 63 {
+		t.Errorf("expected a namespaced identifier, got %q", a)
+	}
+	if a == ProjectSchema("alice", "/home/alice/src/app/services/api") || a == ProjectSchema("bob", "/home/alice/src/app") {
+		t.Error("expected projects and namespaces to get distinct schemas")
+	}
+}
@@ -0,0 +1,510 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/trace"
+	"github.com/jackc/pgx/v5"
+)
+
+// Symbol index storage backends (trace.store).
+const (
+	SymbolBackendGOB      = "gob"
+	SymbolBackendBolt     = "bolt"
+	SymbolBackendPostgres = "postgres"
+)
+
+// symbolSchema holds the symbol index tables. Call graph edges are the
+// references made from inside a named caller.
+var symbolSchema = []string{
+	`CREATE TABLE IF NOT EXISTS symbol_files (
+		project_id TEXT NOT NULL,
+		path TEXT NOT NULL,
+		hash TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (project_id, path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS symbols (
+		project_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		line INTEGER NOT NULL,
+		end_line INTEGER NOT NULL DEFAULT 0,
+		signature TEXT NOT NULL DEFAULT '',
+		receiver TEXT NOT NULL DEFAULT '',
+		package TEXT NOT NULL DEFAULT '',
+		exported BOOLEAN NOT NULL DEFAULT FALSE,
+		language TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_symbols_name ON symbols(project_id, name)`,
+	`CREATE INDEX IF NOT EXISTS idx_symbols_file ON symbols(project_id, file_path)`,
+	`CREATE TABLE IF NOT EXISTS symbol_refs (
+		project_id TEXT NOT NULL,
+		symbol_name TEXT NOT NULL,
+		qualifier TEXT NOT NULL DEFAULT '',
+		file_path TEXT NOT NULL,
+		line INTEGER NOT NULL,
+		col INTEGER NOT NULL DEFAULT 0,
+		context TEXT NOT NULL DEFAULT '',
+		caller_name TEXT NOT NULL DEFAULT '',
+		caller_file TEXT NOT NULL DEFAULT '',
+		caller_line INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_symbol_refs_symbol ON symbol_refs(project_id, symbol_name)`,
+	`CREATE INDEX IF NOT EXISTS idx_symbol_refs_caller ON symbol_refs(project_id, caller_name)`,
+	`CREATE INDEX IF NOT EXISTS idx_symbol_refs_file ON symbol_refs(project_id, file_path)`,
+	// Extraction mode and last update per project
+	`CREATE TABLE IF NOT EXISTS symbol_meta (
+		project_id TEXT PRIMARY KEY,
+		mode TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL
+	)`,
+}
+
+// PostgresSymbolStore implements trace.SymbolStore on the tables of a
+// PostgresFTSStore, so traces can be shared and queried with SQL. Writes go
+// straight to the database; file hashes and the extraction mode are cached
+// by Load for the incremental update checks.
+type PostgresSymbolStore struct {
+	db        *PostgresFTSStore
+	fileHash  map[string]string
+	mode      string
+	updatedAt time.Time
+	mu        sync.RWMutex
+}
+
+var _ trace.SymbolStore = (*PostgresSymbolStore)(nil)
+
+// NewPostgresSymbolStore creates a symbol store on db. Closing the symbol
+// store closes db.
+func NewPostgresSymbolStore(db *PostgresFTSStore) *PostgresSymbolStore {
+	return &PostgresSymbolStore{
+		db:       db,
+		fileHash: make(map[string]string),
+	}
+}
+
+// Load reads the indexed files and extraction mode of the project.
+func (s *PostgresSymbolStore) Load(ctx context.Context) error {
+	rows, err := s.db.pool.Query(ctx,
+		`SELECT path, hash FROM symbol_files WHERE project_id = $1`,
+		s.db.projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load symbol files: %w", err)
+	}
+	defer rows.Close()
+
+	fileHash := make(map[string]string)
+	for rows.Next() {
+		var path, hash string
+		if err := rows.Scan(&path, &hash); err != nil {
+			return fmt.Errorf("failed to scan symbol file: %w", err)
+		}
+		fileHash[path] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load symbol files: %w", err)
+	}
+
+	var mode string
+	var updatedAt time.Time
+	err = s.db.pool.QueryRow(ctx,
+		`SELECT mode, updated_at FROM symbol_meta WHERE project_id = $1`,
+		s.db.projectID,
+	).Scan(&mode, &updatedAt)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to load symbol index metadata: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fileHash = fileHash
+	s.mode = mode
+	s.updatedAt = updatedAt
+	return nil
+}
+
+// Persist records the extraction mode and update time. Symbols are written
+// as files are saved.
+func (s *PostgresSymbolStore) Persist(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updatedAt = time.Now()
+	_, err := s.db.pool.Exec(ctx,
+		`INSERT INTO symbol_meta (project_id, mode, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id) DO UPDATE SET mode = EXCLUDED.mode, updated_at = EXCLUDED.updated_at`,
+		s.db.projectID, s.mode, s.updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to persist symbol index metadata: %w", err)
+	}
+	return nil
+}
+
+// SaveFile persists symbols and references for a file.
+func (s *PostgresSymbolStore) SaveFile(ctx context.Context, filePath string, symbols []trace.Symbol, refs []trace.Reference) error {
+	return s.SaveFileWithHash(ctx, filePath, "", symbols, refs)
+}
+
+// SaveFileWithHash replaces the symbols and references of a file in one
+// transaction.
+func (s *PostgresSymbolStore) SaveFileWithHash(ctx context.Context, filePath string, hash string, symbols []trace.Symbol, refs []trace.Reference) error {
+	tx, err := s.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.deleteFile(ctx, tx, filePath); err != nil {
+		return err
+	}
+
+	projectID := s.db.projectID
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"symbols"},
+		[]string{"project_id", "name", "kind", "file_path", "line", "end_line", "signature", "receiver", "package", "exported", "language"},
+		pgx.CopyFromSlice(len(symbols), func(i int) ([]any, error) {
+			sym := symbols[i]
+			return []any{projectID, sym.Name, string(sym.Kind), sym.File, sym.Line, sym.EndLine, sym.Signature, sym.Receiver, sym.Package, sym.Exported, sym.Language}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save symbols: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"symbol_refs"},
+		[]string{"project_id", "symbol_name", "qualifier", "file_path", "line", "col", "context", "caller_name", "caller_file", "caller_line"},
+		pgx.CopyFromSlice(len(refs), func(i int) ([]any, error) {
+			ref := refs[i]
+			return []any{projectID, ref.SymbolName, ref.Qualifier, ref.File, ref.Line, ref.Column, ref.Context, ref.CallerName, ref.CallerFile, ref.CallerLine}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save references: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO symbol_files (project_id, path, hash) VALUES ($1, $2, $3)`,
+		projectID, filePath, hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save symbol file: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit symbols: %w", err)
+	}
+
+	s.mu.Lock()
+	s.fileHash[filePath] = hash
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteFile removes all symbols and references for a file.
+func (s *PostgresSymbolStore) DeleteFile(ctx context.Context, filePath string) error {
+	tx, err := s.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.deleteFile(ctx, tx, filePath); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit symbol deletion: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.fileHash, filePath)
+	s.mu.Unlock()
+	return nil
+}
+
+// RenameFile moves the symbols and references of a file to newPath.
+func (s *PostgresSymbolStore) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	if oldPath == newPath || !s.IsFileIndexed(oldPath) {
+		return nil
+	}
+	tx, err := s.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.deleteFile(ctx, tx, newPath); err != nil {
+		return err
+	}
+	for _, query := range []string{
+		`UPDATE symbols SET file_path = $3 WHERE project_id = $1 AND file_path = $2`,
+		`UPDATE symbol_refs SET caller_file = $3 WHERE project_id = $1 AND file_path = $2 AND caller_file = $2`,
+		`UPDATE symbol_refs SET file_path = $3 WHERE project_id = $1 AND file_path = $2`,
+		`UPDATE symbol_files SET path = $3 WHERE project_id = $1 AND path = $2`,
+	} {
+		if _, err := tx.Exec(ctx, query, s.db.projectID, oldPath, newPath); err != nil {
+			return fmt.Errorf("failed to rename symbols: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit symbol rename: %w", err)
+	}
+
+	s.mu.Lock()
+	s.fileHash[newPath] = s.fileHash[oldPath]
+	delete(s.fileHash, oldPath)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *PostgresSymbolStore) deleteFile(ctx context.Context, tx pgx.Tx, filePath string) error {
+	for _, query := range []string{
+		`DELETE FROM symbols WHERE project_id = $1 AND file_path = $2`,
+		`DELETE FROM symbol_refs WHERE project_id = $1 AND file_path = $2`,
+		`DELETE FROM symbol_files WHERE project_id = $1 AND path = $2`,
+	} {
+		if _, err := tx.Exec(ctx, query, s.db.projectID, filePath); err != nil {
+			return fmt.Errorf("failed to delete symbols: %w", err)
+		}
+	}
+	return nil
+}
+
+const symbolColumns = `name, kind, file_path, line, end_line, signature, receiver, package, exported, language`
+
+const refColumns = `symbol_name, qualifier, file_path, line, col, context, caller_name, caller_file, caller_line`
+
+// LookupSymbol finds symbol definitions by name.
+func (s *PostgresSymbolStore) LookupSymbol(ctx context.Context, name string) ([]trace.Symbol, error) {
+	return s.querySymbols(ctx,
+		`SELECT `+symbolColumns+` FROM symbols
+		WHERE project_id = $1 AND name = $2
+		ORDER BY file_path, line`,
+		s.db.projectID, name,
+	)
+}
+
+// FindSymbols returns symbols whose name contains query (case-insensitive),
+// ordered by name. An empty query matches every symbol. A limit of 0 means
+// no limit.
+func (s *PostgresSymbolStore) FindSymbols(ctx context.Context, query string, limit int) ([]trace.Symbol, error) {
+	return s.querySymbols(ctx,
+		`SELECT `+symbolColumns+` FROM symbols
+		WHERE project_id = $1 AND strpos(lower(name), lower($2)) > 0
+		ORDER BY name, file_path, line
+		LIMIT NULLIF($3, 0)`,
+		s.db.projectID, query, limit,
+	)
+}
+
+// LookupCallers finds all references/callers of a symbol.
+func (s *PostgresSymbolStore) LookupCallers(ctx context.Context, symbolName string) ([]trace.Reference, error) {
+	return s.queryRefs(ctx,
+		`SELECT `+refColumns+` FROM symbol_refs
+		WHERE project_id = $1 AND symbol_name = $2
+		ORDER BY file_path, line`,
+		s.db.projectID, symbolName,
+	)
+}
+
+// LookupCallees finds all symbols called by a function.
+func (s *PostgresSymbolStore) LookupCallees(ctx context.Context, symbolName string, file string) ([]trace.Reference, error) {
+	return s.queryRefs(ctx,
+		`SELECT DISTINCT ON (file_path, line) `+refColumns+` FROM symbol_refs
+		WHERE project_id = $1 AND caller_name = $2
+		ORDER BY file_path, line`,
+		s.db.projectID, symbolName,
+	)
+}
+
+// GetCallGraph builds a call graph from a starting symbol, following both
+// callers and callees up to depth.
+func (s *PostgresSymbolStore) GetCallGraph(ctx context.Context, symbolName string, depth int) (*trace.CallGraph, error) {
+	graph := &trace.CallGraph{
+		Root:  symbolName,
+		Nodes: make(map[string]trace.Symbol),
+		Edges: []trace.CallEdge{},
+		Depth: depth,
+	}
+
+	visited := make(map[string]bool)
+	edgeSeen := make(map[string]bool)
+	level := []string{symbolName}
+	for d := 0; d <= depth && len(level) > 0; d++ {
+		var next []string
+		for _, name := range level {
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			symbols, err := s.LookupSymbol(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if len(symbols) > 0 {
+				graph.Nodes[name] = symbols[0]
+			}
+
+			edges, err := s.edgesOf(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, edge := range edges {
+				key := edge.Caller + "->" + edge.Callee
+				if !edgeSeen[key] {
+					edgeSeen[key] = true
+					graph.Edges = append(graph.Edges, edge)
+				}
+				for _, other := range []string{edge.Caller, edge.Callee} {
+					if !visited[other] {
+						next = append(next, other)
+					}
+				}
+			}
+		}
+		level = next
+	}
+
+	return graph, nil
+}
+
+// edgesOf returns the call edges into and out of a symbol.
+func (s *PostgresSymbolStore) edgesOf(ctx context.Context, name string) ([]trace.CallEdge, error) {
+	rows, err := s.db.pool.Query(ctx,
+		`SELECT caller_name, symbol_name, file_path, line FROM symbol_refs
+		WHERE project_id = $1 AND (caller_name = $2 OR symbol_name = $2)
+			AND caller_name NOT IN ('', '<top-level>')
+		ORDER BY file_path, line`,
+		s.db.projectID, name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query call graph: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []trace.CallEdge
+	for rows.Next() {
+		edge := trace.CallEdge{CallType: "direct"}
+		if err := rows.Scan(&edge.Caller, &edge.Callee, &edge.File, &edge.Line); err != nil {
+			return nil, fmt.Errorf("failed to scan call edge: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
+
+func (s *PostgresSymbolStore) querySymbols(ctx context.Context, query string, args ...any) ([]trace.Symbol, error) {
+	rows, err := s.db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbols: %w", err)
+	}
+	defer rows.Close()
+
+	symbols := []trace.Symbol{}
+	for rows.Next() {
+		var sym trace.Symbol
+		var kind string
+		if err := rows.Scan(&sym.Name, &kind, &sym.File, &sym.Line, &sym.EndLine,
+			&sym.Signature, &sym.Receiver, &sym.Package, &sym.Exported, &sym.Language); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
+		}
+		sym.Kind = trace.SymbolKind(kind)
+		symbols = append(symbols, sym)
+	}
+	return symbols, rows.Err()
+}
+
+func (s *PostgresSymbolStore) queryRefs(ctx context.Context, query string, args ...any) ([]trace.Reference, error) {
+	rows, err := s.db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []trace.Reference{}
+	for rows.Next() {
+		var ref trace.Reference
+		if err := rows.Scan(&ref.SymbolName, &ref.Qualifier, &ref.File, &ref.Line, &ref.Column,
+			&ref.Context, &ref.CallerName, &ref.CallerFile, &ref.CallerLine); err != nil {
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// Close closes the underlying database connection.
+func (s *PostgresSymbolStore) Close() error {
+	return s.db.Close()
+}
+
+// GetStats returns statistics about the symbol index.
+func (s *PostgresSymbolStore) GetStats(ctx context.Context) (*trace.SymbolStats, error) {
+	var stats trace.SymbolStats
+	err := s.db.pool.QueryRow(ctx,
+		`SELECT
+			(SELECT COUNT(*) FROM symbols WHERE project_id = $1),
+			(SELECT COUNT(*) FROM symbol_refs WHERE project_id = $1),
+			(SELECT COUNT(*) FROM symbol_files WHERE project_id = $1),
+			pg_total_relation_size('symbols') + pg_total_relation_size('symbol_refs')`,
+		s.db.projectID,
+	).Scan(&stats.TotalSymbols, &stats.TotalReferences, &stats.TotalFiles, &stats.IndexSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol stats: %w", err)
+	}
+
+	s.mu.RLock()
+	stats.LastUpdated = s.updatedAt
+	s.mu.RUnlock()
+	return &stats, nil
+}
+
+// FileHash returns the content hash recorded for a file, or "" if unknown.
+func (s *PostgresSymbolStore) FileHash(filePath string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fileHash[filePath]
+}
+
+// IsFileIndexed checks if a file has been indexed.
+func (s *PostgresSymbolStore) IsFileIndexed(filePath string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.fileHash[filePath]
+	return ok
+}
+
+// IndexedFiles returns the paths of all files in the index.
+func (s *PostgresSymbolStore) IndexedFiles() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := make([]string, 0, len(s.fileHash))
+	for path := range s.fileHash {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Mode returns the extraction mode the index was built with.
+func (s *PostgresSymbolStore) Mode() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mode
+}
+
+// SetMode records the extraction mode the index is built with. It is
+// written by Persist.
+func (s *PostgresSymbolStore) SetMode(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode = mode
+}
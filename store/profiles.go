@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Profile is a named ranking profile. Profiles are shared by every project in
+// the database so a team can apply the same tuned ranking.
+type Profile struct {
+	Name      string    `json:"name"`
+	Data      string    `json:"data"` // YAML-encoded ranking settings
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProfileStore persists ranking profiles.
+type ProfileStore interface {
+	// SaveProfile creates or replaces a profile.
+	SaveProfile(ctx context.Context, name, data string) error
+
+	// GetProfile returns a profile. An unknown name is an error.
+	GetProfile(ctx context.Context, name string) (*Profile, error)
+
+	// ListProfiles returns all profiles ordered by name.
+	ListProfiles(ctx context.Context) ([]Profile, error)
+}
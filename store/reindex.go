@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"strings"
+)
+
+// shadowPrefix marks the project ID and chunk IDs of an index rebuilt beside
+// the live one. Chunk IDs are unique across projects, so shadow chunks need
+// IDs of their own until they are swapped in.
+const shadowPrefix = "reindex:"
+
+// ShadowProjectID returns the project ID under which the index of projectID
+// is rebuilt by 'agentdx reindex'. Searches never see it.
+func ShadowProjectID(projectID string) string {
+	return shadowPrefix + projectID
+}
+
+// IsShadowProjectID reports whether projectID holds an index being rebuilt.
+func IsShadowProjectID(projectID string) bool {
+	return strings.HasPrefix(projectID, shadowPrefix)
+}
+
+// liveProjectID returns the project a shadow project ID rebuilds or a
+// snapshot project ID copies, or projectID itself.
+func liveProjectID(projectID string) string {
+	if _, project, ok := parseSnapshotProjectID(projectID); ok {
+		return project
+	}
+	return strings.TrimPrefix(projectID, shadowPrefix)
+}
+
+// IndexFingerprinter is implemented by backends that record which settings
+// the index of a project was built with (see config.IndexFingerprint).
+type IndexFingerprinter interface {
+	// IndexFingerprint returns the recorded fingerprint, or "" when the
+	// index predates fingerprints or is empty.
+	IndexFingerprint(ctx context.Context) (string, error)
+
+	// SetIndexFingerprint records the fingerprint of the index.
+	SetIndexFingerprint(ctx context.Context, fingerprint string) error
+}
+
+// StaleIndexFingerprint is recorded with an index that a schema migration
+// left incomplete. It matches no configuration, so the index is reported as
+// built with other settings until it is rebuilt.
+const StaleIndexFingerprint = "stale"
+
+// ShadowSwapper is implemented by backends that can rebuild a project's
+// index under its ShadowProjectID while the live index keeps serving
+// searches, and then replace the live index in one transaction.
+type ShadowSwapper interface {
+	// DiscardShadow removes the shadow index of the store's project, such
+	// as one left by an interrupted rebuild.
+	DiscardShadow(ctx context.Context) error
+
+	// SwapShadow replaces the live index of the store's project, including
+	// its fingerprint, with the shadow index and returns the number of files
+	// swapped in. Soft-deleted files missing from the shadow index are kept
+	// until they are purged. Notes and query metrics are not touched.
+	SwapShadow(ctx context.Context) (int, error)
+}
+
+// NewShadowWriter wraps the store of a ShadowProjectID so that the chunks an
+// indexer writes get shadow chunk IDs, which SwapShadow restores.
+func NewShadowWriter(st CodeStore) CodeStore {
+	return shadowWriter{st}
+}
+
+type shadowWriter struct {
+	CodeStore
+}
+
+func (w shadowWriter) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	return w.CodeStore.SaveChunks(ctx, shadowChunks(chunks))
+}
+
+func (w shadowWriter) SaveDocument(ctx context.Context, doc Document) error {
+	return w.CodeStore.SaveDocument(ctx, shadowDocument(doc))
+}
+
+func (w shadowWriter) SaveBulk(ctx context.Context, chunks []Chunk, docs []Document) error {
+	shadow := make([]Document, len(docs))
+	for i, doc := range docs {
+		shadow[i] = shadowDocument(doc)
+	}
+	return SaveBulk(ctx, w.CodeStore, shadowChunks(chunks), shadow)
+}
+
+// shadowChunks returns chunks with shadow chunk IDs.
+func shadowChunks(chunks []Chunk) []Chunk {
+	shadow := make([]Chunk, len(chunks))
+	for i, c := range chunks {
+		c.ID = shadowPrefix + c.ID
+		shadow[i] = c
+	}
+	return shadow
+}
+
+// shadowDocument returns doc with shadow chunk IDs.
+func shadowDocument(doc Document) Document {
+	ids := make([]string, len(doc.ChunkIDs))
+	for i, id := range doc.ChunkIDs {
+		ids[i] = shadowPrefix + id
+	}
+	doc.ChunkIDs = ids
+	return doc
+}
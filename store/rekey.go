@@ -0,0 +1,13 @@
+package store
+
+import "context"
+
+// ProjectRekeyer is implemented by backends that can move a project's
+// index to another project ID, e.g. when the project ID strategy changes.
+type ProjectRekeyer interface {
+	// RekeyProject moves the chunks, documents, notes, query metrics and
+	// symbols indexed under the store's project ID to newID, and returns the
+	// number of rows moved. It fails if newID already has indexed files.
+	// The store keeps its old project ID; reopen it to use newID.
+	RekeyProject(ctx context.Context, newID string) (int64, error)
+}
@@ -0,0 +1,17 @@
+package store
+
+import "context"
+
+// RenameStore is implemented by backends that can move the index rows of a
+// renamed file to its new path instead of re-indexing it.
+type RenameStore interface {
+	// DocumentsByHash returns the documents, including soft-deleted ones,
+	// whose content hash is hash.
+	DocumentsByHash(ctx context.Context, hash string) ([]Document, error)
+
+	// RenameFile moves the document at oldPath, the chunks it lists and the
+	// notes on oldPath to newPath, replacing whatever was indexed at newPath.
+	// relink returns a chunk's new ID and content. Moved rows keep their
+	// identity and are restored if they were soft-deleted.
+	RenameFile(ctx context.Context, oldPath, newPath string, relink func(Chunk) Chunk) error
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionStore is implemented by backends that soft-delete removed files.
+// DeleteByFile and DeleteDocument mark rows with a deletion time instead of
+// dropping them, so concurrent readers never observe a file vanishing during
+// re-indexing. Live queries ignore soft-deleted rows until they are purged.
+type RetentionStore interface {
+	// SearchDeletedFTS searches chunks that were removed from the index and
+	// not yet purged. Results have Chunk.DeletedAt set.
+	SearchDeletedFTS(ctx context.Context, query string, limit int) ([]SearchResult, error)
+
+	// PurgeDeleted permanently removes rows soft-deleted before cutoff and
+	// returns the number of chunks removed.
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+// snapshotPrefix marks the project ID and chunk IDs of a snapshot of an
+// index. Chunk IDs are unique across projects, so snapshot chunks get IDs
+// of their own.
+const snapshotPrefix = "snapshot:"
+
+// minSnapshotPrefix is the shortest abbreviated commit ResolveSnapshot
+// accepts, as for git.
+const minSnapshotPrefix = 4
+
+// SnapshotProjectID returns the project ID under which the snapshot of the
+// index of projectID taken at commit is kept. Searches of the live index
+// never see it; 'agentdx search --at' opens it.
+func SnapshotProjectID(projectID, commit string) string {
+	return snapshotPrefix + commit + ":" + projectID
+}
+
+// IsSnapshotProjectID reports whether projectID holds a snapshot.
+func IsSnapshotProjectID(projectID string) bool {
+	return strings.HasPrefix(projectID, snapshotPrefix)
+}
+
+// parseSnapshotProjectID returns the commit and the project of a snapshot
+// project ID.
+func parseSnapshotProjectID(projectID string) (commit, project string, ok bool) {
+	rest, ok := strings.CutPrefix(projectID, snapshotPrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ":")
+}
+
+// snapshotChunkPrefix returns the prefix of the chunk IDs of the snapshot
+// at commit.
+func snapshotChunkPrefix(commit string) string {
+	return snapshotPrefix + commit + ":"
+}
+
+// Snapshot is a copy of a project's index tagged with a commit.
+type Snapshot struct {
+	Commit    string    `json:"commit"`
+	Files     int       `json:"files"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshotter is implemented by backends that can keep copies of a
+// project's index tagged with commits, so that the content of the code at
+// that commit can be searched after it changed.
+type Snapshotter interface {
+	// SaveSnapshot copies the live chunks and documents of the store's
+	// project, and its fingerprint, under SnapshotProjectID(commit),
+	// replacing an earlier snapshot of commit, and returns the number of
+	// files copied.
+	SaveSnapshot(ctx context.Context, commit string) (int, error)
+
+	// ListSnapshots returns the snapshots of the store's project, newest
+	// first.
+	ListSnapshots(ctx context.Context) ([]Snapshot, error)
+
+	// DeleteSnapshot removes the snapshot of commit. Deleting an unknown
+	// snapshot is an error.
+	DeleteSnapshot(ctx context.Context, commit string) error
+}
+
+// ResolveSnapshot returns the snapshot of snapshots whose commit is rev or
+// starts with rev, which must then be at least 4 characters long and match
+// a single snapshot.
+func ResolveSnapshot(snapshots []Snapshot, rev string) (Snapshot, error) {
+	rev = strings.ToLower(strings.TrimSpace(rev))
+	var matches []Snapshot
+	for _, s := range snapshots {
+		if s.Commit == rev {
+			return s, nil
+		}
+		if len(rev) >= minSnapshotPrefix && strings.HasPrefix(s.Commit, rev) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Snapshot{}, errcode.New(errcode.NotFound, "no snapshot of %s; take one with 'agentdx index snapshot --ref %s'", rev, rev)
+	case 1:
+		return matches[0], nil
+	}
+	return Snapshot{}, errcode.New(errcode.InvalidArgs, "%s matches %d snapshots; use a longer commit", rev, len(matches))
+}
+
+// snapshotRows are rows of snapshot project IDs, creation times and file
+// counts.
+type snapshotRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// scanSnapshots returns the snapshots of projectID among rows.
+func scanSnapshots(rows snapshotRows, projectID string) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	for rows.Next() {
+		var id string
+		var snap Snapshot
+		if err := rows.Scan(&id, &snap.CreatedAt, &snap.Files); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		commit, project, ok := parseSnapshotProjectID(id)
+		if !ok || project != projectID {
+			continue
+		}
+		snap.Commit = commit
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, rows.Err()
+}
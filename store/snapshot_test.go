@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/doveaia/agentdx/errcode"
+)
+
+func TestSnapshotProjectID(t *testing.T) {
+	id := SnapshotProjectID("/work/repo", "abc123")
+	if !IsSnapshotProjectID(id) {
+		t.Errorf("expected %q to be a snapshot project ID", id)
+	}
+	if got := liveProjectID(id); got != "/work/repo" {
+		t.Errorf("liveProjectID(%q) = %q, want /work/repo", id, got)
+	}
+	if IsSnapshotProjectID("/work/repo") {
+		t.Error("expected a live project ID not to be a snapshot")
+	}
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	snapshots := []Snapshot{{Commit: "abcd1234"}, {Commit: "abcd9876"}, {Commit: "ffff0000"}}
+	tests := []struct {
+		rev  string
+		want string
+		code errcode.Code
+	}{
+		{"ffff0000", "ffff0000", ""},
+		{"FFFF", "ffff0000", ""},
+		{"abcd1", "abcd1234", ""},
+		{"abcd", "", errcode.InvalidArgs},
+		{"fff", "", errcode.NotFound},
+		{"0000", "", errcode.NotFound},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSnapshot(snapshots, tt.rev)
+		if tt.code != "" {
+			if errcode.Of(err) != tt.code {
+				t.Errorf("ResolveSnapshot(%q): expected %s, got %v", tt.rev, tt.code, err)
+			}
+			continue
+		}
+		if err != nil || got.Commit != tt.want {
+			t.Errorf("ResolveSnapshot(%q) = %+v (%v), want %s", tt.rev, got, err, tt.want)
+		}
+	}
+}
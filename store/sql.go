@@ -0,0 +1,140 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SQL construction helpers. Values are always passed as query parameters;
+// these helpers cover the places where parameters cannot be used (identifiers)
+// and the query languages parsed by the search engines themselves.
+
+// quoteIdent quotes a PostgreSQL identifier such as an index name.
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// sanitizeTerm drops NUL bytes and invalid UTF-8 from a search term. Neither
+// can be represented in a query: SQLite treats NUL as the end of the string
+// and PostgreSQL rejects both in text parameters.
+func sanitizeTerm(word string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(word, ""), "\x00", "")
+}
+
+// buildTSQuery converts query terms into a to_tsquery expression where every
+// word is a quoted prefix lexeme: 'word1':* & 'word2':*. Quoting keeps tsquery
+// operators in user input (&, |, !, <->, parentheses) from being interpreted.
+func buildTSQuery(words []string) string {
+	return buildTSQueryGroups(singleGroups(words))
+}
+
+// buildTSQueryGroups is buildTSQuery for expanded terms: the alternatives of
+// a group are OR-ed, ('a':* | 'b':*) & 'c':*.
+func buildTSQueryGroups(groups [][]string) string {
+	return joinGroups(groups, " | ", " & ", func(word string) string {
+		escaped := strings.ReplaceAll(word, `\`, `\\`)
+		escaped = strings.ReplaceAll(escaped, `'`, `''`)
+		return `'` + escaped + `':*`
+	})
+}
+
+// buildFTS5Query converts query terms into an FTS5 MATCH expression where
+// every word is a quoted prefix term: "word1"* AND "word2"*.
+func buildFTS5Query(words []string) string {
+	return buildFTS5QueryGroups(singleGroups(words))
+}
+
+// buildFTS5QueryGroups is buildFTS5Query for expanded terms: the
+// alternatives of a group are OR-ed, ("a"* OR "b"*) AND "c"*.
+func buildFTS5QueryGroups(groups [][]string) string {
+	return joinGroups(groups, " OR ", " AND ", func(word string) string {
+		return `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	})
+}
+
+// tsQuery returns the PostgreSQL function parsing the tsquery of plan and
+// its argument: phraseto_tsquery for phrases and identifiers, whose words must
+// be adjacent, websearch_to_tsquery for natural language, whose significant
+// words are OR-ed, and to_tsquery with prefix groups otherwise. The function
+// name is a constant, never input.
+func tsQuery(plan QueryPlan, groups [][]string) (fn, arg string) {
+	switch plan.Strategy {
+	case StrategyPhrase, StrategyIdentifier:
+		return "phraseto_tsquery", sanitizeTerm(plan.Text)
+	case StrategyNatural:
+		return "websearch_to_tsquery", sanitizeTerm(strings.Join(strings.Fields(plan.Text), " or "))
+	}
+	return "to_tsquery", buildTSQueryGroups(groups)
+}
+
+// buildFTS5Plan converts the query text of plan into an FTS5 MATCH
+// expression: a quoted string, which FTS5 reads as a phrase, for phrases and
+// identifiers, the OR-ed words for natural language, and prefix groups
+// otherwise.
+func buildFTS5Plan(plan QueryPlan, groups [][]string) string {
+	quote := func(word string) string {
+		return `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	switch plan.Strategy {
+	case StrategyPhrase, StrategyIdentifier:
+		if text := sanitizeTerm(plan.Text); strings.TrimSpace(text) != "" {
+			return quote(text)
+		}
+		return ""
+	case StrategyNatural:
+		return joinGroups([][]string{strings.Fields(plan.Text)}, " OR ", "", quote)
+	}
+	return buildFTS5QueryGroups(groups)
+}
+
+// joinGroups quotes every sanitized term, joins alternatives with or and
+// groups with and. Groups with several alternatives are parenthesized.
+func joinGroups(groups [][]string, or, and string, quote func(string) string) string {
+	parts := make([]string, 0, len(groups))
+	for _, group := range groups {
+		alts := make([]string, 0, len(group))
+		for _, word := range group {
+			if word = sanitizeTerm(word); word != "" {
+				alts = append(alts, quote(word))
+			}
+		}
+		switch len(alts) {
+		case 0:
+			continue
+		case 1:
+			parts = append(parts, alts[0])
+		default:
+			parts = append(parts, "("+strings.Join(alts, or)+")")
+		}
+	}
+	return strings.Join(parts, and)
+}
+
+// singleGroups wraps each word in a group of its own.
+func singleGroups(words []string) [][]string {
+	groups := make([][]string, len(words))
+	for i, word := range words {
+		groups[i] = []string{word}
+	}
+	return groups
+}
+
+// liveFilter returns the condition selecting live rows, or soft-deleted rows
+// when deleted is true. column is a trusted column reference, never input.
+func liveFilter(column string, deleted bool) string {
+	if deleted {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
+// pgArg returns a function that appends a query parameter to args and
+// returns its PostgreSQL placeholder ($n).
+func pgArg(args *[]any) func(v any) string {
+	return func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"idx_chunks_fts_bm25", `"idx_chunks_fts_bm25"`},
+		{`idx"; DROP TABLE chunks_fts; --`, `"idx""; DROP TABLE chunks_fts; --"`},
+	}
+	for _, tt := range tests {
+		if got := quoteIdent(tt.name); got != tt.want {
+			t.Errorf("quoteIdent(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"user", "login"}, `'user':* & 'login':*`},
+		{[]string{"o'brien"}, `'o''brien':*`},
+		{[]string{`a\b`}, `'a\\b':*`},
+		{[]string{"foo|bar", "!baz", "(x)"}, `'foo|bar':* & '!baz':* & '(x)':*`},
+		{[]string{"a\x00b", "\x00"}, `'ab':*`},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := buildTSQuery(tt.words); got != tt.want {
+			t.Errorf("buildTSQuery(%q) = %s, want %s", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestTSQuery(t *testing.T) {
+	groups := [][]string{{"user"}, {"login"}}
+	tests := []struct {
+		plan QueryPlan
+		fn   string
+		arg  string
+	}{
+		{QueryPlan{StrategyPrefix, "user login"}, "to_tsquery", `'user':* & 'login':*`},
+		{QueryPlan{StrategyPhrase, "user login"}, "phraseto_tsquery", "user login"},
+		{QueryPlan{StrategyIdentifier, "user_login"}, "phraseto_tsquery", "user_login"},
+		{QueryPlan{StrategyNatural, "user login"}, "websearch_to_tsquery", "user or login"},
+	}
+	for _, tt := range tests {
+		if fn, arg := tsQuery(tt.plan, groups); fn != tt.fn || arg != tt.arg {
+			t.Errorf("tsQuery(%+v) = %s(%q), want %s(%q)", tt.plan, fn, arg, tt.fn, tt.arg)
+		}
+	}
+}
+
+func TestBuildFTS5Plan(t *testing.T) {
+	groups := [][]string{{"user"}, {"login"}}
+	tests := []struct {
+		plan QueryPlan
+		want string
+	}{
+		{QueryPlan{StrategyPrefix, "user login"}, `"user"* AND "login"*`},
+		{QueryPlan{StrategyPhrase, `say "hi"`}, `"say ""hi"""`},
+		{QueryPlan{StrategyIdentifier, "parseConfig"}, `"parseConfig"`},
+		{QueryPlan{StrategyNatural, "user login"}, `("user" OR "login")`},
+		{QueryPlan{StrategyPhrase, "\x00"}, ""},
+	}
+	for _, tt := range tests {
+		if got := buildFTS5Plan(tt.plan, groups); got != tt.want {
+			t.Errorf("buildFTS5Plan(%+v) = %s, want %s", tt.plan, got, tt.want)
+		}
+	}
+}
+
+// parseTSQuery reverses buildTSQuery following the tsquery lexeme rules:
+// quotes are doubled and backslash escapes the next character. It returns
+// false if the expression contains anything other than quoted prefix terms.
+func parseTSQuery(expr string) ([]string, bool) {
+	var words []string
+	for i := 0; i < len(expr); {
+		if len(words) > 0 {
+			if !strings.HasPrefix(expr[i:], " & ") {
+				return nil, false
+			}
+			i += 3
+		}
+		if i >= len(expr) || expr[i] != '\'' {
+			return nil, false
+		}
+		i++
+
+		var word strings.Builder
+		for {
+			if i >= len(expr) {
+				return nil, false
+			}
+			c := expr[i]
+			if c == '\\' && i+1 < len(expr) {
+				word.WriteByte(expr[i+1])
+				i += 2
+				continue
+			}
+			if c == '\'' {
+				if i+1 < len(expr) && expr[i+1] == '\'' {
+					word.WriteByte('\'')
+					i += 2
+					continue
+				}
+				break
+			}
+			word.WriteByte(c)
+			i++
+		}
+		if !strings.HasPrefix(expr[i:], "':*") {
+			return nil, false
+		}
+		i += 3
+		words = append(words, word.String())
+	}
+	return words, true
+}
+
+func FuzzBuildTSQuery(f *testing.F) {
+	for _, seed := range []string{"user login", "o'brien", `a\b`, "foo & bar | !baz", "'':* <-> (x)", "数据库 connect"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		var words []string
+		for _, w := range queryTerms(query, true) {
+			if w = sanitizeTerm(w); w != "" {
+				words = append(words, w)
+			}
+		}
+		got, ok := parseTSQuery(buildTSQuery(words))
+		if !ok {
+			t.Fatalf("buildTSQuery(%q) produced malformed expression %q", words, buildTSQuery(words))
+		}
+		if !reflect.DeepEqual(got, words) {
+			t.Errorf("round trip mismatch: got %q, want %q", got, words)
+		}
+	})
+}
+
+func FuzzBuildFTS5Query(f *testing.F) {
+	for _, seed := range []string{"user login", `say "hi"`, "a AND b OR NOT c", "col:value", "^start* (x)", "NEAR(a b)", "数据库", `"say ""hi"`, `"NEAR(a"`, "parse_config()", `how does "it" work?`, "where is a OR b?"} {
+		f.Add(seed)
+	}
+
+	st, err := NewSQLiteFTSStore(context.Background(), ":memory:", "/project")
+	if err != nil {
+		f.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+	err = st.SaveChunks(context.Background(), []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 1, Content: `func Login(user string) { say("hi") }`, Hash: "a", UpdatedAt: time.Now()},
+	})
+	if err != nil {
+		f.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		// Every user query must yield a valid MATCH expression
+		if _, err := st.SearchFTS(context.Background(), query, 10); err != nil {
+			t.Errorf("SearchFTS(%q) failed: %v", query, err)
+		}
+	})
+}
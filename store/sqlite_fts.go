@@ -0,0 +1,1361 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/doveaia/agentdx/errcode"
+	"modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteRegexps caches the patterns compiled by the REGEXP function.
+var sqliteRegexps sync.Map
+
+// SQLite has no built-in REGEXP implementation; "X REGEXP Y" calls the
+// user function regexp(Y, X). Search filters use it to match file paths.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("regexp: pattern must be text")
+		}
+		value, _ := args[1].(string)
+		re, ok := sqliteRegexps.Load(pattern)
+		if !ok {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			re, _ = sqliteRegexps.LoadOrStore(pattern, compiled)
+		}
+		return re.(*regexp.Regexp).MatchString(value), nil
+	})
+}
+
+// SQLiteFTSStore implements CodeStore using SQLite FTS5 with BM25 ranking.
+// The index lives in a single file, so no external database is required.
+type SQLiteFTSStore struct {
+	db               *sql.DB
+	path             string
+	projectID        string
+	cjkBigrams       bool       // index and query CJK text as bigrams
+	splitIdentifiers SplitFunc  // index identifier sub-tokens of the files it selects
+	expandTerm       ExpandFunc // expand query terms into alternatives
+}
+
+// NewSQLiteFTSStore opens (or creates) an SQLite FTS5 index at path.
+func NewSQLiteFTSStore(ctx context.Context, path string, projectID string) (*SQLiteFTSStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite index: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteFTSStore{
+		db:        db,
+		path:      path,
+		projectID: projectID,
+	}
+
+	if err := store.ensureSchema(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *SQLiteFTSStore) ensureSchema(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS chunks (
+			id TEXT PRIMARY KEY,
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			deleted_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chunks_file ON chunks(project_id, file_path)`,
+		// FTS5 index keyed by the rowid of the chunks table
+		`CREATE VIRTUAL TABLE IF NOT EXISTS chunks_fts USING fts5(content, doc)`,
+		`CREATE TABLE IF NOT EXISTS documents (
+			path TEXT NOT NULL,
+			project_id TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			mod_time TIMESTAMP NOT NULL,
+			chunk_ids TEXT NOT NULL,
+			deleted_at TIMESTAMP,
+			encoding TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (project_id, path)
+		)`,
+		// Notes attached to line ranges; kept across re-indexing
+		`CREATE TABLE IF NOT EXISTS notes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_notes_file ON notes(project_id, file_path)`,
+		// Ranking profiles are shared across projects
+		`CREATE TABLE IF NOT EXISTS ranking_profiles (
+			name TEXT PRIMARY KEY,
+			data TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// Opt-in query metrics
+		`CREATE TABLE IF NOT EXISTS query_metrics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			source TEXT NOT NULL,
+			query_hash TEXT NOT NULL,
+			query TEXT NOT NULL DEFAULT '',
+			latency_ms INTEGER NOT NULL,
+			results INTEGER NOT NULL,
+			followed_up BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_query_metrics_created ON query_metrics(project_id, created_at)`,
+		// Settings each project's index was built with
+		`CREATE TABLE IF NOT EXISTS index_meta (
+			project_id TEXT PRIMARY KEY,
+			fingerprint TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to execute schema query: %w", err)
+		}
+	}
+
+	// Soft-delete columns for indexes created before retention support
+	for _, table := range []string{"chunks", "documents"} {
+		if err := s.addColumnIfMissing(ctx, table, "deleted_at", "TIMESTAMP"); err != nil {
+			return err
+		}
+	}
+	if err := s.addColumnIfMissing(ctx, "documents", "encoding", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing(ctx, "chunks", "doc", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing(ctx, "chunks", "chunk_type", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// Chunk metadata for indexes created before it was stored
+	for _, column := range []string{"symbol_name", "symbol_kind", "package_name"} {
+		if err := s.addColumnIfMissing(ctx, "chunks", column, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	backfill, args := backfillChunkTypes("chunks", "REGEXP", func(int) string { return "?" })
+	if _, err := s.db.ExecContext(ctx, backfill, args...); err != nil {
+		return fmt.Errorf("failed to set chunk types: %w", err)
+	}
+	return s.migrateDocColumn(ctx)
+}
+
+// migrateDocColumn rebuilds an FTS index created before doc comments were
+// indexed, which has no doc column. FTS5 tables cannot be altered. The chunks
+// have no doc comments yet and the tokenizer settings are not known before
+// the store options are applied, so the fingerprint of every migrated
+// project is set to StaleIndexFingerprint to have it rebuilt.
+func (s *SQLiteFTSStore) migrateDocColumn(ctx context.Context) error {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM pragma_table_info('chunks_fts') WHERE name = 'doc'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect table chunks_fts: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DROP TABLE chunks_fts`,
+		`CREATE VIRTUAL TABLE chunks_fts USING fts5(content, doc)`,
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to migrate chunk index: %w", err)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT rowid, file_path, content FROM chunks`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate chunk index: %w", err)
+	}
+	type entry struct {
+		rowID   int64
+		path    string
+		content string
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.rowID, &e.path, &e.content); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to migrate chunk index: %w", err)
+	}
+	// The CJK setting is not known yet; bigrams only add tokens, so they
+	// keep the chunks searchable until the rebuild
+	for _, e := range entries {
+		if err := s.indexChunk(ctx, tx, e.rowID, e.path, expandCJKText(e.content), ""); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO index_meta (project_id, fingerprint, updated_at)
+		SELECT DISTINCT project_id, ?, ? FROM chunks WHERE true
+		ON CONFLICT(project_id) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			updated_at = excluded.updated_at`,
+		StaleIndexFingerprint, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark index for rebuild: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+	return nil
+}
+
+// indexChunk replaces the FTS entry of the chunk of the file at path at rowID.
+func (s *SQLiteFTSStore) indexChunk(ctx context.Context, tx *sql.Tx, rowID int64, path, content, doc string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE rowid = ?`, rowID); err != nil {
+		return fmt.Errorf("failed to update chunk index: %w", err)
+	}
+	content = indexText(path, content, s.cjkBigrams, s.splitIdentifiers)
+	doc = indexText(path, doc, s.cjkBigrams, s.splitIdentifiers)
+	if _, err := tx.ExecContext(ctx, `INSERT INTO chunks_fts (rowid, content, doc) VALUES (?, ?, ?)`, rowID, content, doc); err != nil {
+		return fmt.Errorf("failed to update chunk index: %w", err)
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so the table schema is checked first.
+func (s *SQLiteFTSStore) addColumnIfMissing(ctx context.Context, table, column, decl string) error {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
+		table, column,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+decl); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// BackendStatus returns the backend status
+func (s *SQLiteFTSStore) BackendStatus(ctx context.Context) *BackendStatus {
+	return &BackendStatus{
+		Type:    "sqlite",
+		Host:    s.path,
+		Name:    filepath.Base(s.path),
+		Healthy: s.db.PingContext(ctx) == nil,
+	}
+}
+
+// SaveChunks stores multiple chunks and their FTS entries atomically
+func (s *SQLiteFTSStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.saveChunks(ctx, tx, chunks); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chunks: %w", err)
+	}
+	return nil
+}
+
+// SaveBulk stores the chunks and documents of many files in one
+// transaction, which SQLite commits much faster than one per file.
+func (s *SQLiteFTSStore) SaveBulk(ctx context.Context, chunks []Chunk, docs []Document) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.saveChunks(ctx, tx, chunks); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := s.saveDocument(ctx, tx, doc); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bulk save: %w", err)
+	}
+	return nil
+}
+
+// saveChunks upserts chunks and their FTS entries within tx.
+func (s *SQLiteFTSStore) saveChunks(ctx context.Context, tx *sql.Tx, chunks []Chunk) error {
+	for _, chunk := range chunks {
+		var rowID int64
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				file_path = excluded.file_path,
+				start_line = excluded.start_line,
+				end_line = excluded.end_line,
+				content = excluded.content,
+				doc = excluded.doc,
+				chunk_type = excluded.chunk_type,
+				symbol_name = excluded.symbol_name,
+				symbol_kind = excluded.symbol_kind,
+				package_name = excluded.package_name,
+				hash = excluded.hash,
+				updated_at = excluded.updated_at,
+				deleted_at = NULL
+			RETURNING rowid`,
+			chunk.ID, s.projectID, chunk.FilePath, chunk.StartLine, chunk.EndLine,
+			chunk.Content, chunk.Doc, chunkType(chunk), chunk.Symbol, chunk.SymbolKind, chunk.Package, chunk.Hash, chunk.UpdatedAt,
+		).Scan(&rowID)
+		if err != nil {
+			return fmt.Errorf("failed to save chunk: %w", err)
+		}
+		if err := s.indexChunk(ctx, tx, rowID, chunk.FilePath, chunk.Content, chunk.Doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByFile soft-deletes all chunks for a given file path. Their FTS
+// entries are kept for SearchDeletedFTS until the chunks are purged.
+func (s *SQLiteFTSStore) DeleteByFile(ctx context.Context, filePath string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE chunks SET deleted_at = ?
+		WHERE project_id = ? AND file_path = ? AND deleted_at IS NULL`,
+		time.Now(), s.projectID, filePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// SearchFTS performs full-text search ranked by FTS5's BM25 implementation.
+// All query words must match; each word is matched as a prefix, unless the
+// query is a phrase, an identifier or a sentence (see PlanQuery).
+func (s *SQLiteFTSStore) SearchFTS(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return s.SearchFiltered(ctx, query, limit, SearchFilter{})
+}
+
+// SearchDeletedFTS searches soft-deleted chunks
+func (s *SQLiteFTSStore) SearchDeletedFTS(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return s.SearchFiltered(ctx, query, limit, SearchFilter{Deleted: true})
+}
+
+// SearchFiltered is SearchFTS restricted to files matching filter. The
+// query is matched with the strategy PlanQuery chooses for it.
+func (s *SQLiteFTSStore) SearchFiltered(ctx context.Context, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	plan := planStoreQuery(query, s.cjkBigrams)
+	results, err := s.searchPlan(ctx, plan, limit, filter)
+	if err == nil && len(results) == 0 && plan.Strategy == StrategyIdentifier {
+		plan = QueryPlan{Strategy: StrategyPrefix, Text: query}
+		results, err = s.searchPlan(ctx, plan, limit, filter)
+	}
+	return withStrategy(results, plan.Strategy), err
+}
+
+// searchPlan runs the search of plan.
+func (s *SQLiteFTSStore) searchPlan(ctx context.Context, plan QueryPlan, limit int, filter SearchFilter) ([]SearchResult, error) {
+	match := buildFTS5Plan(plan, queryGroups(plan.Text, s.cjkBigrams, s.splitIdentifiers != nil, s.expandTerm))
+	if match == "" {
+		return nil, nil
+	}
+
+	args := []any{match, s.projectID}
+	pathFilter, err := filter.sqlCondition("c.", "REGEXP", func(v any) string {
+		args = append(args, v)
+		return "?"
+	})
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, limit)
+
+	// bm25() returns lower values for better matches; negate for a
+	// higher-is-better score like the Postgres backend. Matches in doc
+	// comments weigh more than matches in code.
+	rank := fmt.Sprintf("bm25(chunks_fts, 1.0, %.1f)", docWeight)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT c.id, c.file_path, c.start_line, c.end_line, c.content, c.chunk_type, c.symbol_name, c.symbol_kind, c.package_name, c.hash, c.updated_at, c.deleted_at,
+			-`+rank+` AS score, d.mod_time
+		FROM chunks_fts
+		JOIN chunks c ON c.rowid = chunks_fts.rowid
+		LEFT JOIN documents d ON d.project_id = c.project_id AND d.path = c.file_path
+		WHERE chunks_fts MATCH ? AND c.project_id = ? AND `+liveFilter("c.deleted_at", filter.Deleted)+pathFilter+`
+		ORDER BY `+rank+`, c.file_path, c.start_line
+		LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var chunk Chunk
+		var score float64
+		var modTime *time.Time
+		if err := rows.Scan(
+			&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine,
+			&chunk.Content, &chunk.Type, &chunk.Symbol, &chunk.SymbolKind, &chunk.Package, &chunk.Hash, &chunk.UpdatedAt, &chunk.DeletedAt, &score, &modTime,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		result := SearchResult{Chunk: chunk, Score: float32(score)}
+		if modTime != nil {
+			result.ModTime = *modTime
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// GetDocument retrieves document metadata by path
+func (s *SQLiteFTSStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	var doc Document
+	var chunkIDs string
+
+	err := s.db.QueryRowContext(ctx,
+		`SELECT path, hash, mod_time, chunk_ids, encoding FROM documents
+		WHERE project_id = ? AND path = ? AND deleted_at IS NULL`,
+		s.projectID, filePath,
+	).Scan(&doc.Path, &doc.Hash, &doc.ModTime, &chunkIDs, &doc.Encoding)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(chunkIDs), &doc.ChunkIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk ids: %w", err)
+	}
+	return &doc, nil
+}
+
+// SaveDocument stores document metadata
+func (s *SQLiteFTSStore) SaveDocument(ctx context.Context, doc Document) error {
+	return s.saveDocument(ctx, s.db, doc)
+}
+
+// saveDocument upserts doc with db, the store's database or a transaction.
+func (s *SQLiteFTSStore) saveDocument(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, doc Document) error {
+	chunkIDs := doc.ChunkIDs
+	if chunkIDs == nil {
+		chunkIDs = []string{}
+	}
+	encoded, err := json.Marshal(chunkIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk ids: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO documents (path, project_id, hash, mod_time, chunk_ids, encoding)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, path) DO UPDATE SET
+			hash = excluded.hash,
+			mod_time = excluded.mod_time,
+			chunk_ids = excluded.chunk_ids,
+			encoding = excluded.encoding,
+			deleted_at = NULL`,
+		doc.Path, s.projectID, doc.Hash, doc.ModTime, string(encoded), doc.Encoding,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+// DeleteDocument soft-deletes document metadata
+func (s *SQLiteFTSStore) DeleteDocument(ctx context.Context, filePath string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE documents SET deleted_at = ?
+		WHERE project_id = ? AND path = ? AND deleted_at IS NULL`,
+		time.Now(), s.projectID, filePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// ListDocuments returns all indexed document paths, except summaries
+func (s *SQLiteFTSStore) ListDocuments(ctx context.Context) ([]string, error) {
+	return s.listPaths(ctx, "NOT LIKE")
+}
+
+// ListSummaries returns the paths of the indexed directory summaries
+func (s *SQLiteFTSStore) ListSummaries(ctx context.Context) ([]string, error) {
+	return s.listPaths(ctx, "LIKE")
+}
+
+// listPaths returns the document paths that are (LIKE) or are not (NOT
+// LIKE) summary paths.
+func (s *SQLiteFTSStore) listPaths(ctx context.Context, like string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path FROM documents WHERE project_id = ? AND deleted_at IS NULL AND path `+like+` '%/'`,
+		s.projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, rows.Err()
+}
+
+// Close closes the database
+func (s *SQLiteFTSStore) Close() error {
+	return s.db.Close()
+}
+
+// GetStats returns index statistics
+func (s *SQLiteFTSStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	var stats IndexStats
+
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM documents WHERE project_id = ? AND deleted_at IS NULL AND path NOT LIKE '%/'`,
+		s.projectID,
+	).Scan(&stats.TotalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM chunks WHERE project_id = ? AND deleted_at IS NULL`,
+		s.projectID,
+	).Scan(&stats.TotalChunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+
+	// Select the column itself rather than MAX(updated_at): aggregates lose
+	// the TIMESTAMP type and come back as text
+	err = s.db.QueryRowContext(ctx,
+		`SELECT updated_at FROM chunks WHERE project_id = ? AND deleted_at IS NULL
+		ORDER BY updated_at DESC LIMIT 1`,
+		s.projectID,
+	).Scan(&stats.LastUpdated)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get last update time: %w", err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		stats.IndexSize = info.Size()
+	}
+
+	return &stats, nil
+}
+
+// ListFilesWithStats returns all files with their chunk counts
+func (s *SQLiteFTSStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, mod_time, hash, json_array_length(chunk_ids) FROM documents
+		WHERE project_id = ? AND deleted_at IS NULL AND path NOT LIKE '%/'`,
+		s.projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []FileStats
+	for rows.Next() {
+		var f FileStats
+		if err := rows.Scan(&f.Path, &f.ModTime, &f.Hash, &f.ChunkCount); err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// GetChunksForFile returns all chunks for a specific file
+func (s *SQLiteFTSStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	return s.queryChunks(ctx,
+		`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at
+		FROM chunks WHERE project_id = ? AND file_path = ? AND deleted_at IS NULL
+		ORDER BY start_line`,
+		s.projectID, filePath,
+	)
+}
+
+// GetAllChunks returns all chunks in the store
+func (s *SQLiteFTSStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	return s.queryChunks(ctx,
+		`SELECT id, file_path, start_line, end_line, content, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at
+		FROM chunks WHERE project_id = ? AND deleted_at IS NULL`,
+		s.projectID,
+	)
+}
+
+func (s *SQLiteFTSStore) queryChunks(ctx context.Context, query string, args ...interface{}) ([]Chunk, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []Chunk
+	for rows.Next() {
+		var c Chunk
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Type, &c.Symbol, &c.SymbolKind, &c.Package, &c.Hash, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunks = append(chunks, c)
+	}
+
+	return chunks, rows.Err()
+}
+
+// ProjectID returns the current project ID.
+func (s *SQLiteFTSStore) ProjectID() string {
+	return s.projectID
+}
+
+// GetAllProjects returns all unique project IDs with their file counts.
+func (s *SQLiteFTSStore) GetAllProjects(ctx context.Context) ([]ProjectInfo, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT project_id, COUNT(*) FROM documents
+		WHERE deleted_at IS NULL
+		GROUP BY project_id ORDER BY project_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []ProjectInfo
+	for rows.Next() {
+		var p ProjectInfo
+		if err := rows.Scan(&p.ID, &p.FileCount); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		if IsShadowProjectID(p.ID) || IsSnapshotProjectID(p.ID) {
+			continue // Index being rebuilt by 'agentdx reindex', or a snapshot
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// AddNote stores a note for the current project
+func (s *SQLiteFTSStore) AddNote(ctx context.Context, note Note) (Note, error) {
+	note.CreatedAt = time.Now()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO notes (project_id, file_path, start_line, end_line, text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		s.projectID, note.FilePath, note.StartLine, note.EndLine, note.Text, note.CreatedAt,
+	)
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to add note: %w", err)
+	}
+	if note.ID, err = res.LastInsertId(); err != nil {
+		return Note{}, fmt.Errorf("failed to add note: %w", err)
+	}
+	return note, nil
+}
+
+// ListNotes returns notes for a file, or all notes when filePath is empty
+func (s *SQLiteFTSStore) ListNotes(ctx context.Context, filePath string) ([]Note, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, file_path, start_line, end_line, text, created_at
+		FROM notes
+		WHERE project_id = ? AND (? = '' OR file_path = ?)
+		ORDER BY file_path, start_line, id`,
+		s.projectID, filePath, filePath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.FilePath, &n.StartLine, &n.EndLine, &n.Text, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// DeleteNote removes a note from the current project
+func (s *SQLiteFTSStore) DeleteNote(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM notes WHERE project_id = ? AND id = ?`,
+		s.projectID, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("note %d not found", id)
+	}
+	return nil
+}
+
+// SaveProfile creates or replaces a ranking profile
+func (s *SQLiteFTSStore) SaveProfile(ctx context.Context, name, data string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO ranking_profiles (name, data, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		name, data, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ranking profile: %w", err)
+	}
+	return nil
+}
+
+// GetProfile returns a ranking profile by name
+func (s *SQLiteFTSStore) GetProfile(ctx context.Context, name string) (*Profile, error) {
+	p := Profile{Name: name}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data, updated_at FROM ranking_profiles WHERE name = ?`,
+		name,
+	).Scan(&p.Data, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ranking profile %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ranking profile: %w", err)
+	}
+	return &p, nil
+}
+
+// ListProfiles returns all ranking profiles
+func (s *SQLiteFTSStore) ListProfiles(ctx context.Context) ([]Profile, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, data, updated_at FROM ranking_profiles ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ranking profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		var p Profile
+		if err := rows.Scan(&p.Name, &p.Data, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ranking profile: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// RecordQuery stores a query and returns its ID
+func (s *SQLiteFTSStore) RecordQuery(ctx context.Context, q QueryRecord) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO query_metrics (project_id, kind, source, query_hash, query, latency_ms, results, followed_up, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.projectID, q.Kind, q.Source, q.QueryHash, q.Query, q.LatencyMs, q.Results, q.FollowedUp, q.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record query: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record query: %w", err)
+	}
+	return id, nil
+}
+
+// MarkFollowedUp flags a query whose results were read afterwards
+func (s *SQLiteFTSStore) MarkFollowedUp(ctx context.Context, id int64) error {
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE query_metrics SET followed_up = TRUE WHERE project_id = ? AND id = ?`,
+		s.projectID, id,
+	); err != nil {
+		return fmt.Errorf("failed to mark query followed up: %w", err)
+	}
+	return nil
+}
+
+// ListQueries returns the queries recorded since a time, oldest first
+func (s *SQLiteFTSStore) ListQueries(ctx context.Context, since time.Time) ([]QueryRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, kind, source, query_hash, query, latency_ms, results, followed_up, created_at
+		FROM query_metrics
+		WHERE project_id = ? AND created_at >= ?
+		ORDER BY created_at, id`,
+		s.projectID, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queries: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []QueryRecord
+	for rows.Next() {
+		var q QueryRecord
+		if err := rows.Scan(&q.ID, &q.Kind, &q.Source, &q.QueryHash, &q.Query, &q.LatencyMs, &q.Results, &q.FollowedUp, &q.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// PurgeDeleted permanently removes rows soft-deleted before cutoff
+func (s *SQLiteFTSStore) PurgeDeleted(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM chunks_fts WHERE rowid IN (
+			SELECT rowid FROM chunks WHERE project_id = ? AND deleted_at < ?
+		)`,
+		s.projectID, cutoff,
+	); err != nil {
+		return 0, fmt.Errorf("failed to purge deleted chunks: %w", err)
+	}
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM chunks WHERE project_id = ? AND deleted_at < ?`,
+		s.projectID, cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted chunks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM documents WHERE project_id = ? AND deleted_at < ?`,
+		s.projectID, cutoff,
+	); err != nil {
+		return 0, fmt.Errorf("failed to purge deleted documents: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	purged, _ := res.RowsAffected()
+	return int(purged), nil
+}
+
+// CollectGarbage removes orphaned chunks and index rows of all projects and
+// vacuums the database file.
+func (s *SQLiteFTSStore) CollectGarbage(ctx context.Context, cutoff time.Time) (*GCStats, error) {
+	stats := &GCStats{}
+	var err error
+	if stats.SizeBefore, err = s.databaseSize(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const orphaned = `SELECT c.rowid FROM chunks c
+		WHERE c.updated_at < ? AND NOT EXISTS (
+			SELECT 1 FROM documents d, json_each(d.chunk_ids) j
+			WHERE d.project_id = c.project_id AND d.path = c.file_path AND j.value = c.id
+		)`
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE rowid IN (`+orphaned+`)`, cutoff); err != nil {
+		return nil, fmt.Errorf("failed to remove orphaned chunks: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM chunks WHERE rowid IN (`+orphaned+`)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove orphaned chunks: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	stats.OrphanChunks = int(n)
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM chunks_fts WHERE rowid NOT IN (SELECT rowid FROM chunks)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove orphaned index rows: %w", err)
+	}
+	n, _ = res.RowsAffected()
+	stats.OrphanIndexRows = int(n)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit garbage collection: %w", err)
+	}
+
+	// Merge the FTS5 segments, then rebuild the file without free pages
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO chunks_fts (chunks_fts) VALUES ('optimize')`); err != nil {
+		return nil, fmt.Errorf("failed to optimize chunk index: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return nil, fmt.Errorf("failed to vacuum index: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return nil, fmt.Errorf("failed to checkpoint index: %w", err)
+	}
+
+	if stats.SizeAfter, err = s.databaseSize(ctx); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// databaseSize returns the size of the database pages in bytes.
+func (s *SQLiteFTSStore) databaseSize(ctx context.Context) (int64, error) {
+	var size int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`,
+	).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index size: %w", err)
+	}
+	return size, nil
+}
+
+// DocumentsByHash returns the live and soft-deleted documents with a hash.
+func (s *SQLiteFTSStore) DocumentsByHash(ctx context.Context, hash string) ([]Document, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, hash, mod_time, chunk_ids, encoding FROM documents
+		WHERE project_id = ? AND hash = ?`,
+		s.projectID, hash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		var chunkIDs string
+		if err := rows.Scan(&doc.Path, &doc.Hash, &doc.ModTime, &chunkIDs, &doc.Encoding); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if err := json.Unmarshal([]byte(chunkIDs), &doc.ChunkIDs); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk ids: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// RenameFile moves a file's document, chunks and notes to newPath.
+func (s *SQLiteFTSStore) RenameFile(ctx context.Context, oldPath, newPath string, relink func(Chunk) Chunk) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var encoded string
+	err = tx.QueryRowContext(ctx,
+		`SELECT chunk_ids FROM documents WHERE project_id = ? AND path = ?`,
+		s.projectID, oldPath,
+	).Scan(&encoded)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("document not found: %s", oldPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+	var oldIDs []string
+	if err := json.Unmarshal([]byte(encoded), &oldIDs); err != nil {
+		return fmt.Errorf("failed to decode chunk ids: %w", err)
+	}
+
+	// Drop what was indexed at the new path
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM chunks_fts WHERE rowid IN (
+			SELECT rowid FROM chunks WHERE project_id = ? AND file_path = ?
+		)`,
+		s.projectID, newPath,
+	); err != nil {
+		return fmt.Errorf("failed to replace chunks: %w", err)
+	}
+	for _, query := range []string{
+		`DELETE FROM chunks WHERE project_id = ? AND file_path = ?`,
+		`DELETE FROM documents WHERE project_id = ? AND path = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, s.projectID, newPath); err != nil {
+			return fmt.Errorf("failed to replace document: %w", err)
+		}
+	}
+
+	newIDs := make([]string, 0, len(oldIDs))
+	for _, id := range oldIDs {
+		var rowID int64
+		var c Chunk
+		err := tx.QueryRowContext(ctx,
+			`SELECT rowid, id, file_path, start_line, end_line, content, doc, symbol_name, symbol_kind, package_name, hash, updated_at
+			FROM chunks WHERE project_id = ? AND id = ?`,
+			s.projectID, id,
+		).Scan(&rowID, &c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Doc, &c.Symbol, &c.SymbolKind, &c.Package, &c.Hash, &c.UpdatedAt)
+		if err == sql.ErrNoRows {
+			continue // Purged or replaced since the document was saved
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get chunk: %w", err)
+		}
+
+		c = relink(c)
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE chunks SET id = ?, file_path = ?, content = ?, chunk_type = ?, package_name = ?, deleted_at = NULL WHERE rowid = ?`,
+			c.ID, newPath, c.Content, ChunkTypeOf(newPath), c.Package, rowID,
+		); err != nil {
+			return fmt.Errorf("failed to move chunk: %w", err)
+		}
+		if err := s.indexChunk(ctx, tx, rowID, newPath, c.Content, c.Doc); err != nil {
+			return err
+		}
+		newIDs = append(newIDs, c.ID)
+	}
+
+	encodedIDs, err := json.Marshal(newIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk ids: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE documents SET path = ?, chunk_ids = ?, deleted_at = NULL WHERE project_id = ? AND path = ?`,
+		newPath, string(encodedIDs), s.projectID, oldPath,
+	); err != nil {
+		return fmt.Errorf("failed to move document: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE notes SET file_path = ? WHERE project_id = ? AND file_path = ?`,
+		newPath, s.projectID, oldPath,
+	); err != nil {
+		return fmt.Errorf("failed to move notes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rename: %w", err)
+	}
+	return nil
+}
+
+// sqliteProjectTables are the tables whose rows are keyed by project ID.
+var sqliteProjectTables = []string{"chunks", "documents", "notes", "query_metrics", "index_meta"}
+
+// RekeyProject moves the project's rows to newID.
+func (s *SQLiteFTSStore) RekeyProject(ctx context.Context, newID string) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM documents WHERE project_id = ?`, newID,
+	).Scan(&existing); err != nil {
+		return 0, fmt.Errorf("failed to check project %s: %w", newID, err)
+	}
+	if existing > 0 {
+		return 0, fmt.Errorf("project %s already has %d indexed files", newID, existing)
+	}
+
+	var moved int64
+	for _, table := range sqliteProjectTables {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE `+table+` SET project_id = ? WHERE project_id = ?`, newID, s.projectID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rekey %s: %w", table, err)
+		}
+		n, _ := res.RowsAffected()
+		moved += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rekey: %w", err)
+	}
+	return moved, nil
+}
+
+// IndexFingerprint returns the fingerprint recorded for the project's index.
+func (s *SQLiteFTSStore) IndexFingerprint(ctx context.Context) (string, error) {
+	var fingerprint string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT fingerprint FROM index_meta WHERE project_id = ?`, s.projectID,
+	).Scan(&fingerprint)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get index fingerprint: %w", err)
+	}
+	return fingerprint, nil
+}
+
+// SetIndexFingerprint records the fingerprint of the project's index.
+func (s *SQLiteFTSStore) SetIndexFingerprint(ctx context.Context, fingerprint string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO index_meta (project_id, fingerprint, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			updated_at = excluded.updated_at`,
+		s.projectID, fingerprint, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set index fingerprint: %w", err)
+	}
+	return nil
+}
+
+// DiscardShadow removes the project's shadow index.
+func (s *SQLiteFTSStore) DiscardShadow(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteSQLiteIndex(ctx, tx, ShadowProjectID(s.projectID)); err != nil {
+		return fmt.Errorf("failed to discard shadow index: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit discard: %w", err)
+	}
+	return nil
+}
+
+// deleteSQLiteIndex removes the chunks, documents and fingerprint of
+// projectID.
+func deleteSQLiteIndex(ctx context.Context, tx *sql.Tx, projectID string) error {
+	for _, query := range []string{
+		`DELETE FROM chunks_fts WHERE rowid IN (SELECT rowid FROM chunks WHERE project_id = ?)`,
+		`DELETE FROM chunks WHERE project_id = ?`,
+		`DELETE FROM documents WHERE project_id = ?`,
+		`DELETE FROM index_meta WHERE project_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, projectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot copies the project's live index under the snapshot project
+// ID of commit. The FTS entries of the copies are those of the live chunks.
+func (s *SQLiteFTSStore) SaveSnapshot(ctx context.Context, commit string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshotID := SnapshotProjectID(s.projectID, commit)
+	prefix := snapshotChunkPrefix(commit)
+	if err := deleteSQLiteIndex(ctx, tx, snapshotID); err != nil {
+		return 0, fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at)
+		SELECT ?1 || id, ?2, file_path, start_line, end_line, content, doc, chunk_type, symbol_name, symbol_kind, package_name, hash, updated_at
+		FROM chunks WHERE project_id = ?3 AND deleted_at IS NULL`,
+		prefix, snapshotID, s.projectID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to copy chunks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO chunks_fts (rowid, content, doc)
+		SELECT c.rowid, f.content, f.doc FROM chunks c
+		JOIN chunks l ON l.id = substr(c.id, ?1)
+		JOIN chunks_fts f ON f.rowid = l.rowid
+		WHERE c.project_id = ?2`,
+		len(prefix)+1, snapshotID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to copy chunk index: %w", err)
+	}
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO documents (path, project_id, hash, mod_time, chunk_ids, encoding)
+		SELECT path, ?1, hash, mod_time, (SELECT json_group_array(?2 || value) FROM json_each(chunk_ids)), encoding
+		FROM documents WHERE project_id = ?3 AND deleted_at IS NULL`,
+		snapshotID, prefix, s.projectID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy documents: %w", err)
+	}
+	// The fingerprint row records when the snapshot was taken
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO index_meta (project_id, fingerprint, updated_at)
+		VALUES (?1, COALESCE((SELECT fingerprint FROM index_meta WHERE project_id = ?2), ''), ?3)`,
+		snapshotID, s.projectID, time.Now(),
+	); err != nil {
+		return 0, fmt.Errorf("failed to record snapshot: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	files, _ := res.RowsAffected()
+	return int(files), nil
+}
+
+// ListSnapshots returns the project's snapshots, newest first.
+func (s *SQLiteFTSStore) ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT m.project_id, m.updated_at,
+			(SELECT COUNT(*) FROM documents d WHERE d.project_id = m.project_id)
+		FROM index_meta m
+		WHERE substr(m.project_id, 1, ?) = ?
+		ORDER BY m.updated_at DESC, m.project_id`,
+		len(snapshotPrefix), snapshotPrefix,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+	return scanSnapshots(rows, s.projectID)
+}
+
+// DeleteSnapshot removes the project's snapshot of commit.
+func (s *SQLiteFTSStore) DeleteSnapshot(ctx context.Context, commit string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshotID := SnapshotProjectID(s.projectID, commit)
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM index_meta WHERE project_id = ?`, snapshotID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if exists == 0 {
+		return errcode.New(errcode.NotFound, "no snapshot of %s", commit)
+	}
+	if err := deleteSQLiteIndex(ctx, tx, snapshotID); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit snapshot deletion: %w", err)
+	}
+	return nil
+}
+
+// SwapShadow replaces the project's index with its shadow index. The FTS
+// entries of shadow chunks are keyed by rowid, which the update keeps.
+func (s *SQLiteFTSStore) SwapShadow(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	shadowID := ShadowProjectID(s.projectID)
+	// Live rows are replaced unless they are soft-deleted files the shadow
+	// index does not have
+	for _, query := range []string{
+		`DELETE FROM chunks_fts WHERE rowid IN (
+			SELECT rowid FROM chunks WHERE project_id = ?1 AND (deleted_at IS NULL
+				OR file_path IN (SELECT path FROM documents WHERE project_id = ?2))
+		)`,
+		`DELETE FROM chunks WHERE project_id = ?1 AND (deleted_at IS NULL
+			OR file_path IN (SELECT path FROM documents WHERE project_id = ?2))`,
+		`DELETE FROM documents WHERE project_id = ?1 AND (deleted_at IS NULL
+			OR path IN (SELECT path FROM documents WHERE project_id = ?2))`,
+		`DELETE FROM index_meta WHERE project_id = ?1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, s.projectID, shadowID); err != nil {
+			return 0, fmt.Errorf("failed to replace index: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE chunks SET project_id = ?, id = substr(id, ?) WHERE project_id = ?`,
+		s.projectID, len(shadowPrefix)+1, shadowID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to swap chunks: %w", err)
+	}
+	res, err := tx.ExecContext(ctx,
+		`UPDATE documents SET project_id = ?, chunk_ids = replace(chunk_ids, ?, '"') WHERE project_id = ?`,
+		s.projectID, `"`+shadowPrefix, shadowID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to swap documents: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE index_meta SET project_id = ? WHERE project_id = ?`, s.projectID, shadowID,
+	); err != nil {
+		return 0, fmt.Errorf("failed to swap index fingerprint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit swap: %w", err)
+	}
+	swapped, _ := res.RowsAffected()
+	return int(swapped), nil
+}
@@ -0,0 +1,1076 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/trace"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteFTSStore {
+	t.Helper()
+	st, err := NewSQLiteFTSStore(context.Background(), filepath.Join(t.TempDir(), "index.db"), "/project")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestSQLiteFTSStore_SaveAndSearch(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "auth.go_0", FilePath: "auth.go", StartLine: 1, EndLine: 10, Content: "func HandleLogin(user string) error", Hash: "a", UpdatedAt: now},
+		{ID: "db.go_0", FilePath: "db.go", StartLine: 1, EndLine: 5, Content: "func OpenDatabase() error", Hash: "b", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	results, err := st.SearchFTS(ctx, "handle", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "auth.go" {
+		t.Fatalf("expected prefix match on auth.go, got %+v", results)
+	}
+	if results[0].Score <= 0 {
+		t.Errorf("expected positive score, got %f", results[0].Score)
+	}
+	if !results[0].ModTime.IsZero() {
+		t.Errorf("expected no mod time without a document, got %v", results[0].ModTime)
+	}
+
+	// Results carry the mod time of their document
+	modTime := now.Add(-time.Hour).Truncate(time.Second)
+	if err := st.SaveDocument(ctx, Document{Path: "auth.go", Hash: "a", ModTime: modTime, ChunkIDs: []string{"auth.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if results, _ := st.SearchFTS(ctx, "handle", 10); len(results) != 1 || !results[0].ModTime.Equal(modTime) {
+		t.Errorf("expected mod time %v, got %+v", modTime, results)
+	}
+
+	// All words must match
+	if results, _ := st.SearchFTS(ctx, "func missing", 10); len(results) != 0 {
+		t.Errorf("expected no results when a word is missing, got %d", len(results))
+	}
+
+	// Quotes and operators in queries must not break the MATCH syntax
+	if _, err := st.SearchFTS(ctx, `"error" AND (`, 10); err != nil {
+		t.Errorf("expected special characters to be escaped, got %v", err)
+	}
+
+	// Updating a chunk replaces its indexed content
+	chunks[0].Content = "func HandleLogout()"
+	if err := st.SaveChunks(ctx, chunks[:1]); err != nil {
+		t.Fatalf("SaveChunks update failed: %v", err)
+	}
+	if results, _ := st.SearchFTS(ctx, "HandleLogin", 10); len(results) != 0 {
+		t.Errorf("expected stale content to be removed from index, got %d results", len(results))
+	}
+
+	if err := st.DeleteByFile(ctx, "auth.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if results, _ := st.SearchFTS(ctx, "HandleLogout", 10); len(results) != 0 {
+		t.Errorf("expected deleted chunks to be removed from index, got %d results", len(results))
+	}
+}
+
+func TestSQLiteFTSStore_SearchFiltered(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	var chunks []Chunk
+	for _, path := range []string{"main.go", "internal/auth/login.go", "internal/auth/login_test.go", "web/login.ts", "vendor/lib/login.go", "docs/login.md"} {
+		chunks = append(chunks, Chunk{ID: path, FilePath: path, StartLine: 1, EndLine: 1, Content: "handle login error", Hash: path, UpdatedAt: now})
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   []string
+	}{
+		{"no filter", SearchFilter{}, []string{"docs/login.md", "internal/auth/login.go", "internal/auth/login_test.go", "main.go", "vendor/lib/login.go", "web/login.ts"}},
+		{"include dir", SearchFilter{Include: []string{"internal/**"}}, []string{"internal/auth/login.go", "internal/auth/login_test.go"}},
+		{"include and exclude", SearchFilter{Include: []string{"*.go"}, Exclude: []string{"*_test.go", "vendor/"}}, []string{"internal/auth/login.go", "main.go"}},
+		{"lang", SearchFilter{Langs: []string{"typescript"}}, []string{"web/login.ts"}},
+		{"lang and include", SearchFilter{Include: []string{"internal/"}, Langs: []string{"go"}, Exclude: []string{"**/*_test.go"}}, []string{"internal/auth/login.go"}},
+		{"doc type", SearchFilter{Types: []string{ChunkTypeDoc}}, []string{"docs/login.md"}},
+		{"code type and include", SearchFilter{Include: []string{"web/", "docs/"}, Types: []string{ChunkTypeCode, ChunkTypeConfig}}, []string{"web/login.ts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := st.SearchFiltered(ctx, "login", 10, tt.filter)
+			if err != nil {
+				t.Fatalf("SearchFiltered failed: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Chunk.FilePath)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// The limit applies after filtering
+	results, err := st.SearchFiltered(ctx, "login", 1, SearchFilter{Langs: []string{"ts"}})
+	if err != nil || len(results) != 1 || results[0].Chunk.FilePath != "web/login.ts" {
+		t.Errorf("expected the filtered match within the limit, got %+v (%v)", results, err)
+	}
+
+	if _, err := st.SearchFiltered(ctx, "login", 10, SearchFilter{Include: []string{"[a-"}}); err == nil {
+		t.Error("expected an error for an invalid glob pattern")
+	}
+}
+
+func TestSQLiteFTSStore_SymbolMetadata(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "a", FilePath: "auth/login.go", StartLine: 1, EndLine: 9, Content: "func Login() { check session }", Hash: "a", UpdatedAt: now, Symbol: "Login", SymbolKind: "function", Package: "auth"},
+		{ID: "b", FilePath: "auth/session.go", StartLine: 1, EndLine: 9, Content: "type Session struct { check }", Hash: "b", UpdatedAt: now, Symbol: "Session", SymbolKind: "type", Package: "auth"},
+		{ID: "c", FilePath: "web/check.go", StartLine: 1, EndLine: 9, Content: "func Login() { check form }", Hash: "c", UpdatedAt: now, Symbol: "Login", SymbolKind: "function", Package: "web"},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   []string
+	}{
+		{"symbol", SearchFilter{Symbols: []string{"Login"}}, []string{"auth/login.go", "web/check.go"}},
+		{"package", SearchFilter{Packages: []string{"auth"}}, []string{"auth/login.go", "auth/session.go"}},
+		{"symbol and package", SearchFilter{Symbols: []string{"Login"}, Packages: []string{"web"}}, []string{"web/check.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := st.SearchFiltered(ctx, "check", 10, tt.filter)
+			if err != nil {
+				t.Fatalf("SearchFiltered failed: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Chunk.FilePath)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	results, err := st.SearchFiltered(ctx, "session", 10, SearchFilter{})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d (%v)", len(results), err)
+	}
+	for _, r := range results {
+		if r.Chunk.FilePath == "auth/session.go" && (r.Chunk.Symbol != "Session" || r.Chunk.SymbolKind != "type" || r.Chunk.Package != "auth") {
+			t.Errorf("expected the metadata of the chunk, got %+v", r.Chunk)
+		}
+	}
+	stored, err := st.GetChunksForFile(ctx, "web/check.go")
+	if err != nil || len(stored) != 1 || stored[0].Symbol != "Login" || stored[0].Package != "web" {
+		t.Errorf("expected the metadata from GetChunksForFile, got %+v (%v)", stored, err)
+	}
+}
+
+func TestSQLiteFTSStore_QueryStrategies(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "config.go_0", FilePath: "config.go", StartLine: 1, EndLine: 3, Content: "func ParseConfig(path string) error\n// retry with backoff", Hash: "a", UpdatedAt: now},
+		{ID: "file.go_0", FilePath: "file.go", StartLine: 1, EndLine: 3, Content: "func ParseConfigFile() error\n// backoff with retry", Hash: "b", UpdatedAt: now},
+		{ID: "db.go_0", FilePath: "db.go", StartLine: 1, EndLine: 2, Content: "func parse_config() error", Hash: "c", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		strategy QueryStrategy
+		want     []string
+	}{
+		// Identifiers match exactly, not as prefixes
+		{"ParseConfig()", StrategyIdentifier, []string{"config.go"}},
+		{"parse_config", StrategyIdentifier, []string{"db.go"}},
+		// Partial identifiers fall back to prefix matching
+		{"ParseConf", StrategyPrefix, []string{"config.go", "file.go"}},
+		// Phrases match words in order
+		{`"retry with backoff"`, StrategyPhrase, []string{"config.go"}},
+		// Sentences match any significant word
+		{"where do we retry parsing?", StrategyNatural, []string{"config.go", "file.go"}},
+		{"parse", StrategyPrefix, []string{"config.go", "db.go", "file.go"}},
+	}
+	for _, tt := range tests {
+		results, err := st.SearchFTS(ctx, tt.query, 10)
+		if err != nil {
+			t.Fatalf("SearchFTS(%q) failed: %v", tt.query, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Chunk.FilePath)
+			if r.Strategy != tt.strategy {
+				t.Errorf("SearchFTS(%q): expected strategy %s, got %s", tt.query, tt.strategy, r.Strategy)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SearchFTS(%q): expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestSQLiteFTSStore_Documents(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	doc := Document{Path: "main.go", Hash: "h1", ModTime: time.Now().Truncate(time.Second), ChunkIDs: []string{"main.go_0", "main.go_1"}, Encoding: "Shift_JIS"}
+	if err := st.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	got, err := st.GetDocument(ctx, "main.go")
+	if err != nil || got == nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if got.Hash != "h1" || len(got.ChunkIDs) != 2 || !got.ModTime.Equal(doc.ModTime) || got.Encoding != "Shift_JIS" {
+		t.Errorf("unexpected document: %+v", got)
+	}
+
+	files, err := st.ListFilesWithStats(ctx)
+	if err != nil || len(files) != 1 || files[0].ChunkCount != 2 || files[0].Hash != "h1" {
+		t.Errorf("unexpected file stats: %+v (%v)", files, err)
+	}
+
+	if missing, err := st.GetDocument(ctx, "missing.go"); err != nil || missing != nil {
+		t.Errorf("expected nil for missing document, got %+v (%v)", missing, err)
+	}
+
+	if err := st.DeleteDocument(ctx, "main.go"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	paths, _ := st.ListDocuments(ctx)
+	if len(paths) != 0 {
+		t.Errorf("expected no documents after delete, got %v", paths)
+	}
+}
+
+func TestSQLiteFTSStore_Summaries(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "billing/invoice.go_0", FilePath: "billing/invoice.go", StartLine: 1, EndLine: 1, Content: "type Invoice struct", Hash: "a", UpdatedAt: now},
+		{ID: "billing/_0", FilePath: SummaryPath("billing"), StartLine: 1, EndLine: 3, Content: "Summary of billing/: invoices and payments", Type: ChunkTypeSummary, Hash: "s", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, c := range chunks {
+		if err := st.SaveDocument(ctx, Document{Path: c.FilePath, Hash: c.Hash, ModTime: now, ChunkIDs: []string{c.ID}}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+
+	// Summaries are not files
+	paths, err := st.ListDocuments(ctx)
+	if err != nil || strings.Join(paths, ",") != "billing/invoice.go" {
+		t.Errorf("expected only the file in ListDocuments, got %v (%v)", paths, err)
+	}
+	files, err := st.ListFilesWithStats(ctx)
+	if err != nil || len(files) != 1 || files[0].Path != "billing/invoice.go" {
+		t.Errorf("expected only the file in ListFilesWithStats, got %+v (%v)", files, err)
+	}
+	if stats, err := st.GetStats(ctx); err != nil || stats.TotalFiles != 1 || stats.TotalChunks != 2 {
+		t.Errorf("expected 1 file and 2 chunks, got %+v (%v)", stats, err)
+	}
+	summaries, err := st.ListSummaries(ctx)
+	if err != nil || strings.Join(summaries, ",") != "billing/" {
+		t.Errorf("expected the billing summary, got %v (%v)", summaries, err)
+	}
+
+	results, err := st.SearchFiltered(ctx, "payments", 10, SearchFilter{Types: []string{ChunkTypeSummary}})
+	if err != nil || len(results) != 1 || results[0].Chunk.Type != ChunkTypeSummary {
+		t.Errorf("expected the summary for --type summary, got %+v (%v)", results, err)
+	}
+}
+
+func TestSQLiteFTSStore_Stats(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	stats, err := st.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats on empty index failed: %v", err)
+	}
+	if stats.TotalChunks != 0 || !stats.LastUpdated.IsZero() {
+		t.Errorf("unexpected empty stats: %+v", stats)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := st.SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 1, Content: "a", Hash: "a", UpdatedAt: now}}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	stats, err = st.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalChunks != 1 || !stats.LastUpdated.Equal(now) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if status := st.BackendStatus(ctx); status.Type != BackendSQLite || !status.Healthy {
+		t.Errorf("unexpected backend status: %+v", status)
+	}
+}
+
+func TestSQLiteFTSStore_Notes(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	added, err := st.AddNote(ctx, Note{FilePath: "cli/root.go", StartLine: 10, EndLine: 25, Text: "real entrypoint"})
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if added.ID == 0 || added.CreatedAt.IsZero() {
+		t.Errorf("expected ID and CreatedAt to be set, got %+v", added)
+	}
+	if _, err := st.AddNote(ctx, Note{FilePath: "db.go", StartLine: 1, EndLine: 1, Text: "legacy"}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	all, _ := st.ListNotes(ctx, "")
+	if len(all) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(all))
+	}
+	notes, _ := st.ListNotes(ctx, "cli/root.go")
+	if len(notes) != 1 || notes[0].Text != "real entrypoint" {
+		t.Fatalf("unexpected notes for file: %+v", notes)
+	}
+	if !notes[0].Overlaps(20, 40) || notes[0].Overlaps(26, 40) {
+		t.Errorf("unexpected overlap result for %+v", notes[0])
+	}
+
+	if err := st.DeleteNote(ctx, added.ID); err != nil {
+		t.Fatalf("DeleteNote failed: %v", err)
+	}
+	if err := st.DeleteNote(ctx, added.ID); err == nil {
+		t.Error("expected error deleting a missing note")
+	}
+	if notes, _ := st.ListNotes(ctx, "cli/root.go"); len(notes) != 0 {
+		t.Errorf("expected note to be deleted, got %+v", notes)
+	}
+}
+
+func TestSQLiteFTSStore_QueryMetrics(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	old := QueryRecord{Kind: QueryKindSearch, Source: "cli", QueryHash: "a", Results: 3, CreatedAt: now.Add(-48 * time.Hour)}
+	if _, err := st.RecordQuery(ctx, old); err != nil {
+		t.Fatalf("RecordQuery failed: %v", err)
+	}
+	id, err := st.RecordQuery(ctx, QueryRecord{Kind: QueryKindTrace, Source: "mcp", QueryHash: "b", Query: "Login", LatencyMs: 12, CreatedAt: now})
+	if err != nil || id == 0 {
+		t.Fatalf("RecordQuery = %d, %v", id, err)
+	}
+	if err := st.MarkFollowedUp(ctx, id); err != nil {
+		t.Fatalf("MarkFollowedUp failed: %v", err)
+	}
+
+	queries, err := st.ListQueries(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListQueries failed: %v", err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("expected 1 recent query, got %+v", queries)
+	}
+	q := queries[0]
+	if q.ID != id || q.Kind != QueryKindTrace || q.Query != "Login" || q.LatencyMs != 12 || !q.FollowedUp {
+		t.Errorf("unexpected query %+v", q)
+	}
+}
+
+func TestSQLiteFTSStore_Profiles(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	if _, err := st.GetProfile(ctx, "team"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+
+	if err := st.SaveProfile(ctx, "team", "max_per_file: 2\n"); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if err := st.SaveProfile(ctx, "team", "max_per_file: 4\n"); err != nil {
+		t.Fatalf("SaveProfile update failed: %v", err)
+	}
+
+	p, err := st.GetProfile(ctx, "team")
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if p.Data != "max_per_file: 4\n" {
+		t.Errorf("expected updated profile data, got %q", p.Data)
+	}
+
+	// Profiles are shared across projects in the same database
+	other, err := NewSQLiteFTSStore(ctx, st.path, "/other")
+	if err != nil {
+		t.Fatalf("failed to open second project: %v", err)
+	}
+	defer other.Close()
+	if profiles, _ := other.ListProfiles(ctx); len(profiles) != 1 || profiles[0].Name != "team" {
+		t.Errorf("expected shared profile, got %+v", profiles)
+	}
+}
+
+func TestSQLiteFTSStore_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{{ID: "legacy.go_0", FilePath: "legacy.go", StartLine: 1, EndLine: 3, Content: "func LegacyCheckout()", Hash: "a", UpdatedAt: now}}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.SaveDocument(ctx, Document{Path: "legacy.go", Hash: "h", ModTime: now, ChunkIDs: []string{"legacy.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	if err := st.DeleteByFile(ctx, "legacy.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if err := st.DeleteDocument(ctx, "legacy.go"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+
+	// Live queries no longer see the file
+	if results, _ := st.SearchFTS(ctx, "LegacyCheckout", 10); len(results) != 0 {
+		t.Errorf("expected no live results, got %d", len(results))
+	}
+	if doc, _ := st.GetDocument(ctx, "legacy.go"); doc != nil {
+		t.Errorf("expected soft-deleted document to be hidden, got %+v", doc)
+	}
+	if stats, _ := st.GetStats(ctx); stats.TotalFiles != 0 || stats.TotalChunks != 0 {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+
+	// Deleted code stays searchable until purged
+	deleted, err := st.SearchDeletedFTS(ctx, "LegacyCheckout", 10)
+	if err != nil {
+		t.Fatalf("SearchDeletedFTS failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].Chunk.DeletedAt == nil {
+		t.Fatalf("expected one deleted result with DeletedAt set, got %+v", deleted)
+	}
+
+	if purged, err := st.PurgeDeleted(ctx, now.Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("expected nothing purged before the deletion time, got %d (%v)", purged, err)
+	}
+	if purged, err := st.PurgeDeleted(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+		t.Errorf("expected 1 purged chunk, got %d (%v)", purged, err)
+	}
+	if deleted, _ := st.SearchDeletedFTS(ctx, "LegacyCheckout", 10); len(deleted) != 0 {
+		t.Errorf("expected purged chunk to be gone, got %d results", len(deleted))
+	}
+
+	// Re-indexing a soft-deleted chunk restores it
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.DeleteByFile(ctx, "legacy.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if results, _ := st.SearchFTS(ctx, "LegacyCheckout", 10); len(results) != 1 || results[0].Chunk.DeletedAt != nil {
+		t.Errorf("expected restored live chunk, got %+v", results)
+	}
+}
+
+func TestSQLiteFTSStore_RenameFile(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{{ID: "old.go_0", FilePath: "old.go", StartLine: 1, EndLine: 3, Content: "File: old.go\n\nfunc RenamedCheckout()", Hash: "a", UpdatedAt: now}}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.SaveDocument(ctx, Document{Path: "old.go", Hash: "h", ModTime: now, ChunkIDs: []string{"old.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if _, err := st.AddNote(ctx, Note{FilePath: "old.go", StartLine: 1, EndLine: 3, Text: "keep me"}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	// Renames are usually seen after the old path was deleted
+	if err := st.DeleteByFile(ctx, "old.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if err := st.DeleteDocument(ctx, "old.go"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	docs, err := st.DocumentsByHash(ctx, "h")
+	if err != nil {
+		t.Fatalf("DocumentsByHash failed: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Path != "old.go" {
+		t.Fatalf("expected the deleted document, got %+v", docs)
+	}
+
+	relink := func(c Chunk) Chunk {
+		c.ID = "new.go_0"
+		c.Content = "File: new.go\n\nfunc RenamedCheckout()"
+		return c
+	}
+	if err := st.RenameFile(ctx, "old.go", "new.go", relink); err != nil {
+		t.Fatalf("RenameFile failed: %v", err)
+	}
+
+	results, err := st.SearchFTS(ctx, "RenamedCheckout", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "new.go" || results[0].Chunk.ID != "new.go_0" || results[0].Chunk.DeletedAt != nil {
+		t.Fatalf("expected one live chunk at new.go, got %+v", results)
+	}
+	if doc, _ := st.GetDocument(ctx, "new.go"); doc == nil || len(doc.ChunkIDs) != 1 || doc.ChunkIDs[0] != "new.go_0" {
+		t.Errorf("expected document at new.go, got %+v", doc)
+	}
+	if doc, _ := st.GetDocument(ctx, "old.go"); doc != nil {
+		t.Errorf("expected no document at old.go, got %+v", doc)
+	}
+	if notes, _ := st.ListNotes(ctx, "new.go"); len(notes) != 1 || notes[0].Text != "keep me" {
+		t.Errorf("expected note moved to new.go, got %+v", notes)
+	}
+	if deleted, _ := st.SearchDeletedFTS(ctx, "RenamedCheckout", 10); len(deleted) != 0 {
+		t.Errorf("expected no deleted chunks left, got %+v", deleted)
+	}
+}
+
+func TestSQLiteFTSStore_RekeyProject(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.db")
+	st, err := NewSQLiteFTSStore(ctx, path, "/old/project")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	now := time.Now()
+	if err := st.SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 2, Content: "func RekeyedHandler()", Hash: "a", UpdatedAt: now}}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.SaveDocument(ctx, Document{Path: "a.go", Hash: "h", ModTime: now, ChunkIDs: []string{"a.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if _, err := st.AddNote(ctx, Note{FilePath: "a.go", StartLine: 1, EndLine: 2, Text: "keep me"}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	moved, err := st.RekeyProject(ctx, "github.com/org/repo")
+	if err != nil {
+		t.Fatalf("RekeyProject failed: %v", err)
+	}
+	if moved != 3 {
+		t.Errorf("expected 3 rows moved, got %d", moved)
+	}
+
+	rekeyed, err := NewSQLiteFTSStore(ctx, path, "github.com/org/repo")
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer rekeyed.Close()
+	if results, _ := rekeyed.SearchFTS(ctx, "RekeyedHandler", 10); len(results) != 1 {
+		t.Errorf("expected the chunk under the new ID, got %+v", results)
+	}
+	if notes, _ := rekeyed.ListNotes(ctx, "a.go"); len(notes) != 1 {
+		t.Errorf("expected the note under the new ID, got %+v", notes)
+	}
+	if docs, _ := st.ListDocuments(ctx); len(docs) != 0 {
+		t.Errorf("expected nothing left under the old ID, got %v", docs)
+	}
+
+	if _, err := rekeyed.RekeyProject(ctx, "github.com/org/repo"); err == nil {
+		t.Error("expected an error re-keying onto a project with indexed files")
+	}
+}
+
+func TestSQLiteFTSStore_SwapShadow(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	if err := st.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 9, Content: "func OldChunking()", Hash: "a", UpdatedAt: now},
+		{ID: "gone.go_0", FilePath: "gone.go", StartLine: 1, EndLine: 2, Content: "func RemovedHandler()", Hash: "g", UpdatedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, doc := range []Document{
+		{Path: "a.go", Hash: "h", ModTime: now, ChunkIDs: []string{"a.go_0"}},
+		{Path: "gone.go", Hash: "g", ModTime: now, ChunkIDs: []string{"gone.go_0"}},
+	} {
+		if err := st.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+	if err := st.DeleteByFile(ctx, "gone.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if err := st.DeleteDocument(ctx, "gone.go"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if err := st.SetIndexFingerprint(ctx, "old"); err != nil {
+		t.Fatalf("SetIndexFingerprint failed: %v", err)
+	}
+
+	// Rebuild a.go with two chunks beside the live index
+	shadowStore, err := NewSQLiteFTSStore(ctx, st.path, ShadowProjectID(st.projectID))
+	if err != nil {
+		t.Fatalf("failed to open shadow store: %v", err)
+	}
+	defer shadowStore.Close()
+	shadow := NewShadowWriter(shadowStore)
+	if err := shadow.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 4, Content: "func NewChunking()", Hash: "b", UpdatedAt: now},
+		{ID: "a.go_1", FilePath: "a.go", StartLine: 5, EndLine: 9, Content: "func SecondChunk()", Hash: "c", UpdatedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := shadow.SaveDocument(ctx, Document{Path: "a.go", Hash: "h", ModTime: now, ChunkIDs: []string{"a.go_0", "a.go_1"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if err := shadowStore.SetIndexFingerprint(ctx, "new"); err != nil {
+		t.Fatalf("SetIndexFingerprint failed: %v", err)
+	}
+
+	// The live index serves searches until the swap
+	if results, _ := st.SearchFTS(ctx, "NewChunking", 10); len(results) != 0 {
+		t.Errorf("expected the shadow index to be hidden, got %+v", results)
+	}
+	if projects, _ := st.GetAllProjects(ctx); len(projects) != 1 {
+		t.Errorf("expected the shadow project to be hidden, got %+v", projects)
+	}
+
+	swapped, err := st.SwapShadow(ctx)
+	if err != nil {
+		t.Fatalf("SwapShadow failed: %v", err)
+	}
+	if swapped != 1 {
+		t.Errorf("expected 1 file swapped in, got %d", swapped)
+	}
+
+	if results, _ := st.SearchFTS(ctx, "OldChunking", 10); len(results) != 0 {
+		t.Errorf("expected the old chunks to be replaced, got %+v", results)
+	}
+	results, _ := st.SearchFTS(ctx, "SecondChunk", 10)
+	if len(results) != 1 || results[0].Chunk.ID != "a.go_1" {
+		t.Errorf("expected the rebuilt chunk with its live ID, got %+v", results)
+	}
+	doc, err := st.GetDocument(ctx, "a.go")
+	if err != nil || doc == nil || len(doc.ChunkIDs) != 2 || doc.ChunkIDs[1] != "a.go_1" {
+		t.Errorf("expected live chunk IDs in the document, got %+v (%v)", doc, err)
+	}
+	if deleted, _ := st.SearchDeletedFTS(ctx, "RemovedHandler", 10); len(deleted) != 1 {
+		t.Errorf("expected the soft-deleted file to be kept, got %+v", deleted)
+	}
+	if fingerprint, _ := st.IndexFingerprint(ctx); fingerprint != "new" {
+		t.Errorf("expected the shadow fingerprint, got %q", fingerprint)
+	}
+	if docs, _ := shadowStore.ListDocuments(ctx); len(docs) != 0 {
+		t.Errorf("expected nothing left under the shadow ID, got %v", docs)
+	}
+}
+
+func TestSQLiteFTSStore_DiscardShadow(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	shadowStore, err := NewSQLiteFTSStore(ctx, st.path, ShadowProjectID(st.projectID))
+	if err != nil {
+		t.Fatalf("failed to open shadow store: %v", err)
+	}
+	defer shadowStore.Close()
+	now := time.Now()
+	if err := NewShadowWriter(shadowStore).SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 2, Content: "func Interrupted()", Hash: "a", UpdatedAt: now}}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	if err := st.DiscardShadow(ctx); err != nil {
+		t.Fatalf("DiscardShadow failed: %v", err)
+	}
+	if results, _ := shadowStore.SearchFTS(ctx, "Interrupted", 10); len(results) != 0 {
+		t.Errorf("expected the shadow index to be discarded, got %+v", results)
+	}
+}
+
+func TestSQLiteFTSStore_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now().Add(-2 * time.Hour)
+	if err := st.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 3, Content: "func LegacyParser()", Hash: "a", UpdatedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.SaveDocument(ctx, Document{Path: "a.go", Hash: "h1", ModTime: now, ChunkIDs: []string{"a.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	if err := st.SetIndexFingerprint(ctx, "fp"); err != nil {
+		t.Fatalf("SetIndexFingerprint failed: %v", err)
+	}
+
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	files, err := st.SaveSnapshot(ctx, commit)
+	if err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	if files != 1 {
+		t.Errorf("expected 1 file in the snapshot, got %d", files)
+	}
+	// Taking it again replaces it
+	if _, err := st.SaveSnapshot(ctx, commit); err != nil {
+		t.Fatalf("SaveSnapshot again failed: %v", err)
+	}
+
+	// The refactor replaces the live content
+	if err := st.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 3, Content: "func NewParser()", Hash: "b", UpdatedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if results, _ := st.SearchFTS(ctx, "LegacyParser", 10); len(results) != 0 {
+		t.Errorf("expected the snapshot to be hidden from live searches, got %+v", results)
+	}
+	if projects, _ := st.GetAllProjects(ctx); len(projects) != 1 {
+		t.Errorf("expected the snapshot project to be hidden, got %+v", projects)
+	}
+
+	snapshots, err := st.ListSnapshots(ctx)
+	if err != nil || len(snapshots) != 1 || snapshots[0].Commit != commit || snapshots[0].Files != 1 {
+		t.Fatalf("expected the snapshot to be listed, got %+v (%v)", snapshots, err)
+	}
+	snap, err := ResolveSnapshot(snapshots, "0123456")
+	if err != nil || snap.Commit != commit {
+		t.Errorf("expected the abbreviated commit to resolve, got %+v (%v)", snap, err)
+	}
+
+	at, err := NewSQLiteFTSStore(ctx, st.path, SnapshotProjectID(st.projectID, commit))
+	if err != nil {
+		t.Fatalf("failed to open snapshot store: %v", err)
+	}
+	defer at.Close()
+	results, err := at.SearchFTS(ctx, "LegacyParser", 10)
+	if err != nil || len(results) != 1 || results[0].Chunk.FilePath != "a.go" {
+		t.Errorf("expected the snapshot content, got %+v (%v)", results, err)
+	}
+	if doc, _ := at.GetDocument(ctx, "a.go"); doc == nil || doc.Hash != "h1" || len(doc.ChunkIDs) != 1 {
+		t.Errorf("expected the snapshot document, got %+v", doc)
+	}
+	if fingerprint, _ := at.IndexFingerprint(ctx); fingerprint != "fp" {
+		t.Errorf("expected the live fingerprint, got %q", fingerprint)
+	}
+
+	// Garbage collection keeps the snapshot chunks, which its documents
+	// reference
+	if _, err := st.CollectGarbage(ctx, time.Now()); err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if results, _ := at.SearchFTS(ctx, "LegacyParser", 10); len(results) != 1 {
+		t.Errorf("expected the snapshot to survive garbage collection, got %+v", results)
+	}
+
+	if err := st.DeleteSnapshot(ctx, commit); err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+	if results, _ := at.SearchFTS(ctx, "LegacyParser", 10); len(results) != 0 {
+		t.Errorf("expected the snapshot to be deleted, got %+v", results)
+	}
+	if err := st.DeleteSnapshot(ctx, commit); err == nil {
+		t.Error("expected an error deleting an unknown snapshot")
+	}
+}
+
+func TestSQLiteFTSStore_CollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	old := time.Now().Add(-time.Hour)
+	chunks := []Chunk{
+		{ID: "live.go_0", FilePath: "live.go", StartLine: 1, EndLine: 3, Content: "func LiveHandler()", Hash: "a", UpdatedAt: old},
+		{ID: "live.go_1", FilePath: "live.go", StartLine: 4, EndLine: 6, Content: "func StaleHandler()", Hash: "b", UpdatedAt: old},
+		{ID: "fresh.go_0", FilePath: "fresh.go", StartLine: 1, EndLine: 3, Content: "func FreshHandler()", Hash: "c", UpdatedAt: time.Now()},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	// live.go_1 is a stale chunk ID; fresh.go is still being indexed
+	if err := st.SaveDocument(ctx, Document{Path: "live.go", Hash: "h", ModTime: old, ChunkIDs: []string{"live.go_0"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	// Chunks of a project whose documents are gone
+	other, err := NewSQLiteFTSStore(ctx, st.path, "/deleted-project")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer other.Close()
+	if err := other.SaveChunks(ctx, []Chunk{{ID: "gone.go_0", FilePath: "gone.go", StartLine: 1, EndLine: 2, Content: "func GoneHandler()", Hash: "d", UpdatedAt: old}}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	stats, err := st.CollectGarbage(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if stats.OrphanChunks != 2 {
+		t.Errorf("expected 2 orphaned chunks, got %d", stats.OrphanChunks)
+	}
+	if stats.SizeBefore == 0 || stats.SizeAfter == 0 {
+		t.Errorf("expected index sizes, got %+v", stats)
+	}
+
+	for query, want := range map[string]int{"LiveHandler": 1, "FreshHandler": 1, "StaleHandler": 0} {
+		if results, _ := st.SearchFTS(ctx, query, 10); len(results) != want {
+			t.Errorf("search %q: expected %d results, got %d", query, want, len(results))
+		}
+	}
+	if results, _ := other.SearchFTS(ctx, "GoneHandler", 10); len(results) != 0 {
+		t.Errorf("expected chunks of the deleted project to be removed, got %d", len(results))
+	}
+
+	// Index rows left without a chunk are dropped too
+	if _, err := st.db.ExecContext(ctx, `INSERT INTO chunks_fts (rowid, content) VALUES (9999, 'func GhostHandler()')`); err != nil {
+		t.Fatalf("failed to insert index row: %v", err)
+	}
+	stats, err = st.CollectGarbage(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if stats.OrphanChunks != 0 || stats.OrphanIndexRows != 1 {
+		t.Errorf("expected 1 orphaned index row, got %+v", stats)
+	}
+}
+
+func TestSQLiteFTSStore_MigratesSoftDeleteColumns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.db")
+
+	// Schema from before soft-delete support
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{
+		`CREATE TABLE chunks (id TEXT PRIMARY KEY, project_id TEXT NOT NULL, file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL, end_line INTEGER NOT NULL, content TEXT NOT NULL,
+			hash TEXT NOT NULL, updated_at TIMESTAMP NOT NULL)`,
+		`CREATE TABLE documents (path TEXT NOT NULL, project_id TEXT NOT NULL, hash TEXT NOT NULL,
+			mod_time TIMESTAMP NOT NULL, chunk_ids TEXT NOT NULL, PRIMARY KEY (project_id, path))`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	st, err := NewSQLiteFTSStore(ctx, path, "/project")
+	if err != nil {
+		t.Fatalf("failed to open legacy index: %v", err)
+	}
+	defer st.Close()
+	if err := st.DeleteDocument(ctx, "missing.go"); err != nil {
+		t.Errorf("expected soft delete to work after migration, got %v", err)
+	}
+}
+
+func TestSQLiteFTSStore_DocCommentsRankHigher(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "pool.go_0", FilePath: "pool.go", StartLine: 1, EndLine: 2, Content: "func Pool(connection Conn) {}", Hash: "a", UpdatedAt: now},
+		{ID: "dial.go_0", FilePath: "dial.go", StartLine: 1, EndLine: 2, Content: "// Dial opens a connection\nfunc Dial() {}", Doc: "Dial opens a connection", Hash: "b", UpdatedAt: now},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	results, err := st.SearchFTS(ctx, "connection", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Chunk.FilePath != "dial.go" {
+		t.Fatalf("expected the documented chunk first, got %+v", results)
+	}
+}
+
+func TestSQLiteFTSStore_MigratesDocColumn(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.db")
+
+	// Schema from before doc comments were indexed
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{
+		`CREATE TABLE chunks (id TEXT PRIMARY KEY, project_id TEXT NOT NULL, file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL, end_line INTEGER NOT NULL, content TEXT NOT NULL,
+			hash TEXT NOT NULL, updated_at TIMESTAMP NOT NULL, deleted_at TIMESTAMP)`,
+		`CREATE VIRTUAL TABLE chunks_fts USING fts5(content)`,
+		`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, hash, updated_at)
+			VALUES ('old.go_0', '/project', 'old.go', 1, 1, 'func LegacyHandler()', 'h', CURRENT_TIMESTAMP)`,
+		`INSERT INTO chunks_fts (rowid, content) SELECT rowid, content FROM chunks`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	st, err := NewSQLiteFTSStore(ctx, path, "/project")
+	if err != nil {
+		t.Fatalf("failed to open legacy index: %v", err)
+	}
+	defer st.Close()
+	results, err := st.SearchFTS(ctx, "LegacyHandler", 10)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected existing chunk to stay searchable, got %d results (%v)", len(results), err)
+	}
+	if results[0].Chunk.Type != ChunkTypeCode {
+		t.Errorf("expected existing chunk to get type %q, got %q", ChunkTypeCode, results[0].Chunk.Type)
+	}
+	// The chunks have no doc comments; the index must be flagged for a rebuild
+	fingerprint, err := st.IndexFingerprint(ctx)
+	if err != nil {
+		t.Fatalf("IndexFingerprint failed: %v", err)
+	}
+	if fingerprint != StaleIndexFingerprint {
+		t.Errorf("expected migrated index fingerprint %q, got %q", StaleIndexFingerprint, fingerprint)
+	}
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	if _, err := Open(context.Background(), Options{Backend: "mysql"}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestOpenSymbolStore(t *testing.T) {
+	ctx := context.Background()
+	gobPath := filepath.Join(t.TempDir(), "symbols.gob")
+
+	for _, backend := range []string{"", SymbolBackendGOB} {
+		st, err := OpenSymbolStore(ctx, backend, gobPath, Options{})
+		if err != nil {
+			t.Fatalf("OpenSymbolStore(%q) failed: %v", backend, err)
+		}
+		if _, ok := st.(*trace.GOBSymbolStore); !ok {
+			t.Errorf("OpenSymbolStore(%q): expected GOB store, got %T", backend, st)
+		}
+	}
+
+	if _, err := OpenSymbolStore(ctx, SymbolBackendPostgres, gobPath, Options{}); err == nil {
+		t.Error("expected error for postgres symbol store without a DSN")
+	}
+	if _, err := OpenSymbolStore(ctx, "mysql", gobPath, Options{}); err == nil {
+		t.Error("expected error for unknown symbol store backend")
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Index.Store.Backend = BackendSQLite
+	cfg.Index.Search.CJKBigrams = true
+	cfg.Index.Search.Identifiers.Split = true
+
+	opts := OptionsFromConfig(cfg, root)
+	if opts.Backend != BackendSQLite || opts.SQLitePath != cfg.GetSQLiteIndexPath(root) || opts.ProjectID != cfg.ProjectID(root) {
+		t.Errorf("unexpected backend options: %+v", opts)
+	}
+	if !opts.CJKBigrams || opts.SplitIdentifiers == nil {
+		t.Errorf("expected tokenizer options from index.search, got %+v", opts)
+	}
+	if opts.Encryption != nil {
+		t.Error("expected no encryption without encryption.enabled")
+	}
+}
+
+func TestSQLiteFTSStore_SaveBulk(t *testing.T) {
+	ctx := context.Background()
+	st := newTestSQLiteStore(t)
+
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 3, Content: "func BulkAlpha()", Hash: "a", UpdatedAt: now},
+		{ID: "b.go_0", FilePath: "b.go", StartLine: 1, EndLine: 3, Content: "func BulkBeta()", Hash: "b", UpdatedAt: now},
+	}
+	docs := []Document{
+		{Path: "a.go", Hash: "ha", ModTime: now, ChunkIDs: []string{"a.go_0"}},
+		{Path: "b.go", Hash: "hb", ModTime: now, ChunkIDs: []string{"b.go_0"}},
+	}
+	if err := SaveBulk(ctx, st, chunks, docs); err != nil {
+		t.Fatalf("SaveBulk failed: %v", err)
+	}
+
+	paths, err := st.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("expected 2 documents, got %v", paths)
+	}
+	results, err := st.SearchFTS(ctx, "BulkBeta", 10)
+	if err != nil {
+		t.Fatalf("SearchFTS failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "b.go" {
+		t.Errorf("expected b.go to be searchable, got %+v", results)
+	}
+
+	// Shadow writers keep their chunk IDs apart
+	if err := SaveBulk(ctx, NewShadowWriter(st), chunks[:1], docs[:1]); err != nil {
+		t.Fatalf("SaveBulk through a shadow writer failed: %v", err)
+	}
+	doc, err := st.GetDocument(ctx, "a.go")
+	if err != nil || doc == nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if len(doc.ChunkIDs) != 1 || doc.ChunkIDs[0] != shadowPrefix+"a.go_0" {
+		t.Errorf("expected a shadow chunk ID, got %v", doc.ChunkIDs)
+	}
+}
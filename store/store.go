@@ -2,18 +2,32 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
+// docWeight is how much more a match in a chunk's doc comments counts than
+// a match in its code. PostgreSQL indexes them with tsvector weights A and
+// D, which ts_rank weighs 1.0 and 0.1 by default.
+const docWeight = 10.0
+
 // Chunk represents a piece of code with its embedding
 type Chunk struct {
-	ID        string    `json:"id"`
-	FilePath  string    `json:"file_path"`
-	StartLine int       `json:"start_line"`
-	EndLine   int       `json:"end_line"`
-	Content   string    `json:"content"`
-	Hash      string    `json:"hash"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string `json:"id"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+	Doc       string `json:"doc,omitempty"`  // leading doc comments, ranked above code
+	Type      string `json:"type,omitempty"` // code, doc, config or summary; see ChunkTypeOf
+	// Symbol defined in the chunk, or enclosing it, and its kind (function,
+	// method, type...), from the symbol extractor
+	Symbol     string     `json:"symbol,omitempty"`
+	SymbolKind string     `json:"symbol_kind,omitempty"`
+	Package    string     `json:"package,omitempty"` // package or module of the file, as --group-by package names it
+	Hash       string     `json:"hash"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"` // set on soft-deleted chunks
 }
 
 // Document represents a file with its chunks
@@ -22,12 +36,71 @@ type Document struct {
 	Hash     string    `json:"hash"`
 	ModTime  time.Time `json:"mod_time"`
 	ChunkIDs []string  `json:"chunk_ids"`
+	Encoding string    `json:"encoding,omitempty"` // Encoding of the file when not UTF-8; chunks are UTF-8
 }
 
 // SearchResult represents a search match with its relevance score
 type SearchResult struct {
 	Chunk Chunk   `json:"chunk"`
 	Score float32 `json:"score"`
+	Notes []Note  `json:"notes,omitempty"` // notes overlapping the chunk
+
+	// Highlights are the spans of the chunk matching the query
+	Highlights []Highlight `json:"highlights,omitempty"`
+
+	// Confidence is high when the result stands out from the other matches
+	// of the query and low otherwise, as rated by search.AddConfidence
+	Confidence string `json:"confidence,omitempty"`
+
+	// Blame is the last commit that changed the matched lines, as set by
+	// search.AddBlame
+	Blame *Blame `json:"blame,omitempty"`
+
+	// Strategy is how the query was matched (see PlanQuery)
+	Strategy QueryStrategy `json:"strategy,omitempty"`
+
+	// Cells are the notebook cells the chunk spans, numbered from 1, as set
+	// by search.AddCells
+	Cells []int `json:"cells,omitempty"`
+
+	// ModTime is the modification time of the result's file when it was
+	// indexed, used by recency boosts
+	ModTime time.Time `json:"-"`
+}
+
+// Highlight is a span of a result line that matches a query term. Columns
+// are 1-based byte offsets in the line; EndCol is exclusive.
+type Highlight struct {
+	Line     int `json:"line"`
+	StartCol int `json:"start_col"`
+	EndCol   int `json:"end_col"`
+}
+
+// Blame is the last commit that changed lines of a file.
+type Blame struct {
+	Commit  string    `json:"commit,omitempty"`
+	Author  string    `json:"author,omitempty"`
+	Date    time.Time `json:"date,omitzero"`
+	Subject string    `json:"subject,omitempty"` // first line of the commit message
+	// Uncommitted is set when some of the lines have uncommitted changes
+	Uncommitted bool `json:"uncommitted,omitempty"`
+}
+
+// Summary describes b on one line: the short commit, author, date and
+// subject, and whether there are uncommitted changes.
+func (b *Blame) Summary() string {
+	if b.Commit == "" {
+		return "uncommitted changes"
+	}
+	short := b.Commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	s := fmt.Sprintf("%s %s, %s: %s", short, b.Author, b.Date.Format("2006-01-02"), b.Subject)
+	if b.Uncommitted {
+		s += " (and uncommitted changes)"
+	}
+	return s
 }
 
 // IndexStats contains statistics about the index
@@ -43,6 +116,7 @@ type FileStats struct {
 	Path       string    `json:"path"`
 	ChunkCount int       `json:"chunk_count"`
 	ModTime    time.Time `json:"mod_time"`
+	Hash       string    `json:"hash,omitempty"` // content hash the file was chunked from
 }
 
 // BackendStatus represents the status of a storage backend
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// SummaryPath returns the path the summary of the directory dir is indexed
+// under: the directory with a trailing slash, or "./" for the project root.
+// No file path ends with a slash, so summaries never collide with files.
+func SummaryPath(dir string) string {
+	dir = path.Clean(strings.TrimSuffix(dir, "/"))
+	return dir + "/"
+}
+
+// IsSummaryPath reports whether path is the path of a directory summary.
+func IsSummaryPath(path string) bool {
+	return strings.HasSuffix(path, "/")
+}
+
+// SummaryStore is implemented by backends that index directory summaries
+// as documents of ChunkTypeSummary chunks. Summaries are not files: they are
+// left out of ListDocuments, ListFilesWithStats and the file count of
+// GetStats, so that indexing never removes them as deleted files.
+type SummaryStore interface {
+	// ListSummaries returns the paths of the indexed directory summaries.
+	ListSummaries(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,326 @@
+// Package summary writes short summaries of the directories of a project,
+// indexed as chunks of type summary so that broad queries such as "where is
+// billing handled" find the package that handles it.
+package summary
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/indexer"
+	"github.com/doveaia/agentdx/store"
+)
+
+const (
+	maxReadmeBytes  = 600       // first README paragraph kept
+	maxFileBytes    = 64 * 1024 // content read per file for doc comments
+	maxDocs         = 12        // file doc comments in a summary
+	maxListedFiles  = 20        // file names listed in a summary
+	maxExcerptFiles = 12        // files excerpted in an LLM prompt
+	maxExcerptLines = 30        // lines per excerpt
+)
+
+// readmeNames are the README files whose first paragraph describes a
+// directory, in order of preference.
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// Dir is what the summary of a directory is written from.
+type Dir struct {
+	Path     string    // slash-separated path, "." for the project root
+	Files    []string  // indexed files directly in the directory, sorted
+	Readme   string    // first paragraph of the directory's README
+	Docs     []string  // first doc comment of files, as "name: doc"
+	Excerpts []Excerpt // first lines of files, for LLM prompts
+}
+
+// Excerpt is the beginning of a file.
+type Excerpt struct {
+	Name string
+	Text string
+}
+
+// Group returns the indexed files by directory, "." for the project root.
+func Group(files []store.FileStats) map[string][]string {
+	dirs := make(map[string][]string)
+	for _, f := range files {
+		dir := path.Dir(f.Path)
+		dirs[dir] = append(dirs[dir], f.Path)
+	}
+	for _, paths := range dirs {
+		sort.Strings(paths)
+	}
+	return dirs
+}
+
+// ReadDir reads what the summary of dir is written from, its files being
+// read from the project at root. Unreadable files are skipped.
+func ReadDir(root, dir string, files []string) Dir {
+	d := Dir{Path: dir, Files: files}
+	abs := filepath.Join(root, filepath.FromSlash(dir))
+	for _, name := range readmeNames {
+		if data, err := os.ReadFile(filepath.Join(abs, name)); err == nil {
+			d.Readme = firstParagraph(string(data))
+			break
+		}
+	}
+
+	for _, file := range files {
+		content, err := readHead(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		name := path.Base(file)
+		if doc, _, _ := strings.Cut(indexer.DocComments(file, content), "\n"); doc != "" && len(d.Docs) < maxDocs && store.ChunkTypeOf(file) == store.ChunkTypeCode {
+			d.Docs = append(d.Docs, name+": "+doc)
+		}
+		if len(d.Excerpts) < maxExcerptFiles {
+			d.Excerpts = append(d.Excerpts, Excerpt{Name: name, Text: firstLines(content, maxExcerptLines)})
+		}
+	}
+	return d
+}
+
+// Describe writes the summary of d without an LLM: the first paragraph of
+// its README and the doc comments of its files.
+func (d Dir) Describe() string {
+	var lines []string
+	if d.Readme != "" {
+		lines = append(lines, d.Readme)
+	}
+	for _, doc := range d.Docs {
+		lines = append(lines, "- "+doc)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Prompt returns the request for an LLM summary of d.
+func (d Dir) Prompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Directory: %s\n", store.SummaryPath(d.Path))
+	fmt.Fprintf(&b, "Files: %s\n", listFiles(d.Files))
+	if d.Readme != "" {
+		fmt.Fprintf(&b, "\nREADME:\n%s\n", d.Readme)
+	}
+	for _, e := range d.Excerpts {
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", e.Name, e.Text)
+	}
+	return b.String()
+}
+
+// Chunk returns the summary chunk of d with text, and the document holding
+// it; hash identifies what the summary was written from.
+func Chunk(d Dir, text, hash string, now time.Time) (store.Chunk, store.Document) {
+	p := store.SummaryPath(d.Path)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of %s (%d files)\n", p, len(d.Files))
+	if text != "" {
+		fmt.Fprintf(&b, "\n%s\n", text)
+	}
+	fmt.Fprintf(&b, "\nFiles: %s\n", listFiles(d.Files))
+	content := b.String()
+
+	chunk := store.Chunk{
+		ID:        p + "_0",
+		FilePath:  p,
+		StartLine: 1,
+		EndLine:   strings.Count(content, "\n"),
+		Content:   content,
+		Doc:       text,
+		Type:      store.ChunkTypeSummary,
+		Hash:      hash,
+		UpdatedAt: now,
+	}
+	doc := store.Document{Path: p, Hash: hash, ModTime: now, ChunkIDs: []string{chunk.ID}}
+	return chunk, doc
+}
+
+// Summarizer writes directory summaries, with the LLM of cfg when it has an
+// endpoint and with Dir.Describe otherwise.
+type Summarizer struct {
+	cfg config.SummaryConfig
+}
+
+// New returns a summarizer for cfg.
+func New(cfg config.SummaryConfig) *Summarizer {
+	return &Summarizer{cfg: cfg}
+}
+
+// UsesLLM reports whether summaries are written by an LLM.
+func (s *Summarizer) UsesLLM() bool {
+	return s.cfg.Endpoint != ""
+}
+
+// Hash identifies the summary of d: it changes when d or the LLM does, so
+// that unchanged directories are not summarized again.
+func (s *Summarizer) Hash(d Dir) string {
+	input := "describe\n" + d.Describe()
+	if s.UsesLLM() {
+		input = s.cfg.Endpoint + "\n" + s.cfg.Model + "\n" + d.Prompt()
+	}
+	input += "\n" + strings.Join(d.Files, "\n")
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:8])
+}
+
+// systemPrompt instructs the LLM writing summaries.
+const systemPrompt = `You write summaries of source code directories for a code search index.
+In 2 to 4 plain sentences, say what the directory is responsible for, its main types
+and functions, and the domain terms a developer would search for. No Markdown, no
+preamble.`
+
+// chatRequest is the body of a chat completions call.
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	Stream      bool          `json:"stream"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatResponse is the answer of a chat completions call.
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize writes the summary of d.
+func (s *Summarizer) Summarize(ctx context.Context, d Dir) (string, error) {
+	if !s.UsesLLM() {
+		return d.Describe(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.TimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	body := chatRequest{
+		Model: s.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: d.Prompt()},
+		},
+		Temperature: 0.2,
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode summary request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create summary request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := os.Getenv(config.SummaryAPIKeyEnv); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("%s returned %s: %s", s.cfg.Endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode summary response: %w", err)
+	}
+	if len(out.Choices) == 0 || strings.TrimSpace(out.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("%s returned no summary", s.cfg.Endpoint)
+	}
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
+
+// readHead returns the first maxFileBytes of the file at path.
+func readHead(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxFileBytes))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// firstParagraph returns the first paragraph of prose in a README, skipping
+// headings, badges and HTML, cut to maxReadmeBytes.
+func firstParagraph(content string) string {
+	var para []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if len(para) > 0 {
+				return cutText(strings.Join(para, " "), maxReadmeBytes)
+			}
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!["), strings.HasPrefix(line, "[!["),
+			strings.HasPrefix(line, "<"), strings.HasPrefix(line, "==="), strings.HasPrefix(line, "---"):
+			if len(para) > 0 {
+				return cutText(strings.Join(para, " "), maxReadmeBytes)
+			}
+		default:
+			para = append(para, line)
+		}
+	}
+	return cutText(strings.Join(para, " "), maxReadmeBytes)
+}
+
+// cutText cuts s to n bytes at most, at a word boundary.
+func cutText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	return s + "..."
+}
+
+// firstLines returns the first n lines of content.
+func firstLines(content string, n int) string {
+	lines := strings.SplitN(content, "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// listFiles returns the base names of files, listing maxListedFiles at most.
+func listFiles(files []string) string {
+	names := make([]string, 0, min(len(files), maxListedFiles))
+	for _, f := range files {
+		if len(names) == maxListedFiles {
+			break
+		}
+		names = append(names, path.Base(f))
+	}
+	list := strings.Join(names, ", ")
+	if more := len(files) - len(names); more > 0 {
+		list += fmt.Sprintf(" (+%d more)", more)
+	}
+	return list
+}
@@ -0,0 +1,100 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/store"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	groups := Group([]store.FileStats{{Path: "main.go"}, {Path: "billing/stripe.go"}, {Path: "billing/invoice.go"}})
+	if got := strings.Join(groups["billing"], ","); got != "billing/invoice.go,billing/stripe.go" {
+		t.Errorf("expected the billing files sorted, got %s", got)
+	}
+	if got := strings.Join(groups["."], ","); got != "main.go" {
+		t.Errorf("expected main.go in the root directory, got %s", got)
+	}
+}
+
+func TestReadDir_Describe(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"billing/README.md":  "# Billing\n\n[![CI](badge.svg)](ci)\n\nCharges customers and\nissues invoices.\n\nMore details.\n",
+		"billing/invoice.go": "// Copyright Acme\n\n// Package billing charges customers.\npackage billing\n",
+		"billing/stripe.go":  "package billing\n\nfunc charge() {}\n",
+	})
+
+	d := ReadDir(root, "billing", []string{"billing/README.md", "billing/invoice.go", "billing/stripe.go"})
+	if d.Readme != "Charges customers and issues invoices." {
+		t.Errorf("expected the first README paragraph, got %q", d.Readme)
+	}
+	want := "Charges customers and issues invoices.\n- invoice.go: Package billing charges customers."
+	if got := d.Describe(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	chunk, doc := Chunk(d, d.Describe(), "h", time.Now())
+	if chunk.FilePath != "billing/" || chunk.Type != store.ChunkTypeSummary || doc.Path != "billing/" || doc.ChunkIDs[0] != chunk.ID {
+		t.Errorf("unexpected summary chunk %+v and document %+v", chunk, doc)
+	}
+	if !strings.Contains(chunk.Content, "Files: README.md, invoice.go, stripe.go") || chunk.EndLine != strings.Count(chunk.Content, "\n") {
+		t.Errorf("unexpected summary content:\n%s", chunk.Content)
+	}
+}
+
+func TestSummarizer_LLM(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Handles billing. "}}]}`))
+	}))
+	defer srv.Close()
+
+	d := Dir{Path: "billing", Files: []string{"billing/invoice.go"}, Excerpts: []Excerpt{{Name: "invoice.go", Text: "package billing"}}}
+	s := New(config.SummaryConfig{Endpoint: srv.URL, Model: "m", TimeoutMs: 1000})
+	text, err := s.Summarize(context.Background(), d)
+	if err != nil || text != "Handles billing." {
+		t.Fatalf("expected the LLM summary, got %q (%v)", text, err)
+	}
+	if got.Model != "m" || len(got.Messages) != 2 || !strings.Contains(got.Messages[1].Content, "--- invoice.go ---\npackage billing") {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	// The hash changes with the model
+	other := New(config.SummaryConfig{Endpoint: srv.URL, Model: "other", TimeoutMs: 1000})
+	if s.Hash(d) == other.Hash(d) || s.Hash(d) != s.Hash(d) {
+		t.Error("expected the hash to be stable and to depend on the model")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	if _, err := New(config.SummaryConfig{Endpoint: failing.URL, TimeoutMs: 1000}).Summarize(context.Background(), d); err == nil {
+		t.Error("expected an error when the LLM fails")
+	}
+}
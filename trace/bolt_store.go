@@ -0,0 +1,545 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt buckets. Symbol and reference keys start with the symbol name and
+// caller keys with the caller name, so lookups are prefix scans.
+var (
+	bucketSymbols = []byte("symbols") // name\x00file\x00seq -> Symbol
+	bucketRefs    = []byte("refs")    // symbol\x00file\x00seq -> Reference
+	bucketCallers = []byte("callers") // caller\x00file\x00seq -> Reference
+	bucketFiles   = []byte("files")   // path -> boltFile
+	bucketMeta    = []byte("meta")
+
+	metaMode      = []byte("mode")
+	metaUpdatedAt = []byte("updated_at")
+)
+
+// boltLockTimeout bounds how long to wait for another process (usually the
+// watch daemon) holding the index.
+const boltLockTimeout = 10 * time.Second
+
+// boltFile records the hash of an indexed file and the keys written for it.
+type boltFile struct {
+	Hash    string   `json:"hash,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+	Refs    []string `json:"refs,omitempty"`
+	Callers []string `json:"callers,omitempty"`
+}
+
+// BoltSymbolStore implements SymbolStore on a bbolt database. Files are
+// written individually and lookups read the memory-mapped file, so the index
+// is never loaded whole nor rewritten on Persist. The database is opened
+// only while operations run so other processes can use it in between.
+type BoltSymbolStore struct {
+	path string
+	mode string
+
+	mu   sync.Mutex // guards db, refs and mode
+	db   *bolt.DB
+	refs int
+}
+
+var _ SymbolStore = (*BoltSymbolStore)(nil)
+
+// NewBoltSymbolStore creates a bbolt-based symbol store.
+func NewBoltSymbolStore(path string) *BoltSymbolStore {
+	return &BoltSymbolStore{path: path}
+}
+
+// acquire opens the database or shares the handle already open.
+func (s *BoltSymbolStore) acquire() (*bolt.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db == nil {
+		db, err := bolt.Open(s.path, 0644, &bolt.Options{Timeout: boltLockTimeout})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open symbol index: %w", err)
+		}
+		s.db = db
+	}
+	s.refs++
+	return s.db, nil
+}
+
+// release closes the database once no operation uses it.
+func (s *BoltSymbolStore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refs--
+	if s.refs == 0 && s.db != nil {
+		s.db.Close()
+		s.db = nil
+	}
+}
+
+func (s *BoltSymbolStore) view(fn func(tx *bolt.Tx) error) error {
+	db, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	return db.View(fn)
+}
+
+// update runs fn in a write transaction. Concurrent updates are committed
+// together, so fn may run more than once and must be idempotent.
+func (s *BoltSymbolStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	return db.Batch(fn)
+}
+
+// Load creates the buckets and reads the extraction mode.
+func (s *BoltSymbolStore) Load(ctx context.Context) error {
+	var mode string
+	err := s.update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketSymbols, bucketRefs, bucketCallers, bucketFiles, bucketMeta} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		mode = string(tx.Bucket(bucketMeta).Get(metaMode))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load symbol index: %w", err)
+	}
+
+	s.mu.Lock()
+	s.mode = mode
+	s.mu.Unlock()
+	return nil
+}
+
+// Persist records the extraction mode and update time. Symbols are written
+// as files are saved.
+func (s *BoltSymbolStore) Persist(ctx context.Context) error {
+	mode := s.Mode()
+	err := s.update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(bucketMeta)
+		if err := meta.Put(metaMode, []byte(mode)); err != nil {
+			return err
+		}
+		return meta.Put(metaUpdatedAt, []byte(time.Now().Format(time.RFC3339Nano)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist symbol index: %w", err)
+	}
+	return nil
+}
+
+// SaveFile persists symbols and references for a file.
+func (s *BoltSymbolStore) SaveFile(ctx context.Context, filePath string, symbols []Symbol, refs []Reference) error {
+	return s.SaveFileWithHash(ctx, filePath, "", symbols, refs)
+}
+
+// SaveFileWithHash replaces the symbols and references of a file along with
+// the hash of the content they were extracted from.
+func (s *BoltSymbolStore) SaveFileWithHash(ctx context.Context, filePath string, hash string, symbols []Symbol, refs []Reference) error {
+	err := s.update(func(tx *bolt.Tx) error {
+		return saveBoltFile(tx, filePath, hash, symbols, refs)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save symbols for %s: %w", filePath, err)
+	}
+	return nil
+}
+
+func saveBoltFile(tx *bolt.Tx, filePath string, hash string, symbols []Symbol, refs []Reference) error {
+	if err := deleteBoltFile(tx, filePath); err != nil {
+		return err
+	}
+
+	entry := boltFile{Hash: hash}
+	for i, sym := range symbols {
+		key := boltKey(sym.Name, filePath, i)
+		if err := putJSON(tx.Bucket(bucketSymbols), key, sym); err != nil {
+			return err
+		}
+		entry.Symbols = append(entry.Symbols, key)
+	}
+	for i, ref := range refs {
+		key := boltKey(ref.SymbolName, filePath, i)
+		if err := putJSON(tx.Bucket(bucketRefs), key, ref); err != nil {
+			return err
+		}
+		entry.Refs = append(entry.Refs, key)
+
+		if isCallEdge(ref) {
+			key := boltKey(ref.CallerName, filePath, i)
+			if err := putJSON(tx.Bucket(bucketCallers), key, ref); err != nil {
+				return err
+			}
+			entry.Callers = append(entry.Callers, key)
+		}
+	}
+	return putJSON(tx.Bucket(bucketFiles), filePath, entry)
+}
+
+// RenameFile moves the symbols and references of a file to newPath.
+func (s *BoltSymbolStore) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	err := s.update(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketFiles).Get([]byte(oldPath))
+		if data == nil {
+			return nil
+		}
+		var entry boltFile
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+
+		symbols := make([]Symbol, len(entry.Symbols))
+		for i, key := range entry.Symbols {
+			if err := json.Unmarshal(tx.Bucket(bucketSymbols).Get([]byte(key)), &symbols[i]); err != nil {
+				return err
+			}
+			symbols[i].File = newPath
+		}
+		refs := make([]Reference, len(entry.Refs))
+		for i, key := range entry.Refs {
+			if err := json.Unmarshal(tx.Bucket(bucketRefs).Get([]byte(key)), &refs[i]); err != nil {
+				return err
+			}
+			refs[i].File = newPath
+			if refs[i].CallerFile == oldPath {
+				refs[i].CallerFile = newPath
+			}
+		}
+
+		if err := deleteBoltFile(tx, oldPath); err != nil {
+			return err
+		}
+		return saveBoltFile(tx, newPath, entry.Hash, symbols, refs)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename symbols of %s: %w", oldPath, err)
+	}
+	return nil
+}
+
+// DeleteFile removes all symbols and references for a file.
+func (s *BoltSymbolStore) DeleteFile(ctx context.Context, filePath string) error {
+	err := s.update(func(tx *bolt.Tx) error {
+		return deleteBoltFile(tx, filePath)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete symbols for %s: %w", filePath, err)
+	}
+	return nil
+}
+
+func deleteBoltFile(tx *bolt.Tx, filePath string) error {
+	files := tx.Bucket(bucketFiles)
+	data := files.Get([]byte(filePath))
+	if data == nil {
+		return nil
+	}
+
+	var entry boltFile
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	for bucket, keys := range map[string][]string{
+		string(bucketSymbols): entry.Symbols,
+		string(bucketRefs):    entry.Refs,
+		string(bucketCallers): entry.Callers,
+	} {
+		b := tx.Bucket([]byte(bucket))
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return files.Delete([]byte(filePath))
+}
+
+// LookupSymbol finds symbol definitions by name.
+func (s *BoltSymbolStore) LookupSymbol(ctx context.Context, name string) ([]Symbol, error) {
+	symbols := []Symbol{}
+	err := s.view(func(tx *bolt.Tx) error {
+		return scanPrefix(tx.Bucket(bucketSymbols), name, func(sym Symbol) {
+			symbols = append(symbols, sym)
+		})
+	})
+	return symbols, err
+}
+
+// LookupCallers finds all references/callers of a symbol.
+func (s *BoltSymbolStore) LookupCallers(ctx context.Context, symbolName string) ([]Reference, error) {
+	refs := []Reference{}
+	err := s.view(func(tx *bolt.Tx) error {
+		return scanPrefix(tx.Bucket(bucketRefs), symbolName, func(ref Reference) {
+			refs = append(refs, ref)
+		})
+	})
+	return refs, err
+}
+
+// LookupCallees finds all symbols called by a function.
+func (s *BoltSymbolStore) LookupCallees(ctx context.Context, symbolName string, file string) ([]Reference, error) {
+	var callees []Reference
+	seen := make(map[string]bool)
+	err := s.view(func(tx *bolt.Tx) error {
+		return scanPrefix(tx.Bucket(bucketCallers), symbolName, func(ref Reference) {
+			key := fmt.Sprintf("%s:%d", ref.File, ref.Line)
+			if !seen[key] {
+				seen[key] = true
+				callees = append(callees, ref)
+			}
+		})
+	})
+	return callees, err
+}
+
+// GetCallGraph builds a call graph from a starting symbol, following both
+// callers and callees up to depth.
+func (s *BoltSymbolStore) GetCallGraph(ctx context.Context, symbolName string, depth int) (*CallGraph, error) {
+	graph := &CallGraph{
+		Root:  symbolName,
+		Nodes: make(map[string]Symbol),
+		Edges: []CallEdge{},
+		Depth: depth,
+	}
+
+	err := s.view(func(tx *bolt.Tx) error {
+		symbols := tx.Bucket(bucketSymbols)
+		refs := tx.Bucket(bucketRefs)
+		callers := tx.Bucket(bucketCallers)
+
+		visited := make(map[string]bool)
+		edgeSeen := make(map[string]bool)
+		level := []string{symbolName}
+		for d := 0; d <= depth && len(level) > 0; d++ {
+			var next []string
+			for _, name := range level {
+				if visited[name] {
+					continue
+				}
+				visited[name] = true
+
+				found := false
+				if err := scanPrefix(symbols, name, func(sym Symbol) {
+					if !found {
+						graph.Nodes[name] = sym
+						found = true
+					}
+				}); err != nil {
+					return err
+				}
+
+				addEdge := func(ref Reference) {
+					if !isCallEdge(ref) {
+						return
+					}
+					edge := CallEdge{Caller: ref.CallerName, Callee: ref.SymbolName, File: ref.File, Line: ref.Line, CallType: "direct"}
+					if key := edge.Caller + "->" + edge.Callee; !edgeSeen[key] {
+						edgeSeen[key] = true
+						graph.Edges = append(graph.Edges, edge)
+					}
+					for _, other := range []string{edge.Caller, edge.Callee} {
+						if !visited[other] {
+							next = append(next, other)
+						}
+					}
+				}
+				if err := scanPrefix(callers, name, addEdge); err != nil {
+					return err
+				}
+				if err := scanPrefix(refs, name, addEdge); err != nil {
+					return err
+				}
+			}
+			level = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// Close persists the index metadata.
+func (s *BoltSymbolStore) Close() error {
+	return s.Persist(context.Background())
+}
+
+// GetStats returns statistics about the symbol index.
+func (s *BoltSymbolStore) GetStats(ctx context.Context) (*SymbolStats, error) {
+	stats := &SymbolStats{}
+	err := s.view(func(tx *bolt.Tx) error {
+		stats.TotalSymbols = countKeys(tx.Bucket(bucketSymbols))
+		stats.TotalReferences = countKeys(tx.Bucket(bucketRefs))
+		stats.TotalFiles = countKeys(tx.Bucket(bucketFiles))
+		if meta := tx.Bucket(bucketMeta); meta != nil {
+			stats.LastUpdated, _ = time.Parse(time.RFC3339Nano, string(meta.Get(metaUpdatedAt)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		stats.IndexSize = info.Size()
+	}
+	return stats, nil
+}
+
+// FileHash returns the content hash recorded for a file, or "" if unknown.
+func (s *BoltSymbolStore) FileHash(filePath string) string {
+	entry, _ := s.file(filePath)
+	if entry == nil {
+		return ""
+	}
+	return entry.Hash
+}
+
+// IsFileIndexed checks if a file has been indexed.
+func (s *BoltSymbolStore) IsFileIndexed(filePath string) bool {
+	entry, _ := s.file(filePath)
+	return entry != nil
+}
+
+func (s *BoltSymbolStore) file(filePath string) (*boltFile, error) {
+	var entry *boltFile
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketFiles)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(filePath))
+		if data == nil {
+			return nil
+		}
+		entry = &boltFile{}
+		return json.Unmarshal(data, entry)
+	})
+	return entry, err
+}
+
+// IndexedFiles returns the paths of all files in the index.
+func (s *BoltSymbolStore) IndexedFiles() []string {
+	files := []string{}
+	_ = s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketFiles)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			files = append(files, string(k))
+			return nil
+		})
+	})
+	return files
+}
+
+// Mode returns the extraction mode the index was built with, or "" for a
+// new index.
+func (s *BoltSymbolStore) Mode() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mode
+}
+
+// SetMode records the extraction mode the index is built with. It is
+// written by Persist.
+func (s *BoltSymbolStore) SetMode(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode = mode
+}
+
+// FindSymbols returns symbols whose name contains query (case-insensitive),
+// ordered by name. An empty query matches every symbol. A limit of 0 means
+// no limit.
+func (s *BoltSymbolStore) FindSymbols(ctx context.Context, query string, limit int) ([]Symbol, error) {
+	query = strings.ToLower(query)
+	var result []Symbol
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSymbols)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if limit > 0 && len(result) >= limit {
+				return nil
+			}
+			name, _, _ := strings.Cut(string(k), "\x00")
+			if !strings.Contains(strings.ToLower(name), query) {
+				continue
+			}
+			var sym Symbol
+			if err := json.Unmarshal(v, &sym); err != nil {
+				return err
+			}
+			result = append(result, sym)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// isCallEdge reports whether ref is a call made from inside a named caller.
+func isCallEdge(ref Reference) bool {
+	return ref.CallerName != "" && ref.CallerName != "<top-level>"
+}
+
+// boltKey builds a key sorting entries by name, then file, then position.
+func boltKey(name, filePath string, seq int) string {
+	return fmt.Sprintf("%s\x00%s\x00%08d", name, filePath, seq)
+}
+
+func putJSON(b *bolt.Bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// scanPrefix decodes every value whose key belongs to name.
+func scanPrefix[T any](b *bolt.Bucket, name string, fn func(T)) error {
+	if b == nil {
+		return nil
+	}
+	prefix := []byte(name + "\x00")
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var item T
+		if err := json.Unmarshal(v, &item); err != nil {
+			return err
+		}
+		fn(item)
+	}
+	return nil
+}
+
+func countKeys(b *bolt.Bucket) int {
+	if b == nil {
+		return 0
+	}
+	return b.Stats().KeyN
+}
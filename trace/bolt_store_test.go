@@ -0,0 +1,112 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func newTestBoltStore(t *testing.T) *BoltSymbolStore {
+	t.Helper()
+	store := NewBoltSymbolStore(filepath.Join(t.TempDir(), "symbols.db"))
+	if err := store.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return store
+}
+
+func TestBoltSymbolStore_Lookups(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBoltStore(t)
+
+	err := store.SaveFileWithHash(ctx, "a.go", "h1",
+		[]Symbol{{Name: "Alpha", Kind: KindFunction, File: "a.go", Line: 3}},
+		[]Reference{
+			{SymbolName: "Beta", File: "a.go", Line: 4, CallerName: "Alpha", CallerFile: "a.go", CallerLine: 3},
+			{SymbolName: "Beta", File: "a.go", Line: 5, CallerName: "Alpha", CallerFile: "a.go", CallerLine: 3},
+		})
+	if err != nil {
+		t.Fatalf("SaveFileWithHash failed: %v", err)
+	}
+	err = store.SaveFile(ctx, "b.go",
+		[]Symbol{{Name: "Beta", Kind: KindFunction, File: "b.go", Line: 1}},
+		[]Reference{{SymbolName: "Gamma", File: "b.go", Line: 2, CallerName: "Beta", CallerFile: "b.go", CallerLine: 1}})
+	if err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	if syms, _ := store.LookupSymbol(ctx, "Alpha"); len(syms) != 1 || syms[0].Line != 3 {
+		t.Errorf("unexpected Alpha symbols: %+v", syms)
+	}
+	if syms, _ := store.LookupSymbol(ctx, "Alph"); len(syms) != 0 {
+		t.Errorf("expected exact name lookup, got %+v", syms)
+	}
+	if refs, _ := store.LookupCallers(ctx, "Beta"); len(refs) != 2 {
+		t.Errorf("expected 2 callers of Beta, got %+v", refs)
+	}
+	if refs, _ := store.LookupCallees(ctx, "Alpha", "a.go"); len(refs) != 2 || refs[0].SymbolName != "Beta" {
+		t.Errorf("unexpected callees of Alpha: %+v", refs)
+	}
+	if syms, _ := store.FindSymbols(ctx, "ETA", 0); len(syms) != 1 || syms[0].Name != "Beta" {
+		t.Errorf("unexpected FindSymbols result: %+v", syms)
+	}
+
+	graph, err := store.GetCallGraph(ctx, "Beta", 1)
+	if err != nil {
+		t.Fatalf("GetCallGraph failed: %v", err)
+	}
+	if len(graph.Edges) != 2 || graph.Nodes["Alpha"].File != "a.go" {
+		t.Errorf("unexpected call graph: %+v", graph)
+	}
+
+	if store.FileHash("a.go") != "h1" || !store.IsFileIndexed("b.go") {
+		t.Error("expected indexed files to be recorded")
+	}
+
+	// Re-saving a file replaces its entries
+	if err := store.SaveFile(ctx, "a.go", nil, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if refs, _ := store.LookupCallers(ctx, "Beta"); len(refs) != 0 {
+		t.Errorf("expected old references to be replaced, got %+v", refs)
+	}
+
+	if err := store.DeleteFile(ctx, "b.go"); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+	stats, err := store.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalSymbols != 0 || stats.TotalReferences != 0 || stats.TotalFiles != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if files := store.IndexedFiles(); len(files) != 1 || files[0] != "a.go" {
+		t.Errorf("unexpected indexed files: %v", files)
+	}
+}
+
+func TestBoltSymbolStore_ModeSurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "symbols.db")
+
+	store := NewBoltSymbolStore(path)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	store.SetMode(ModeFast)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	store = NewBoltSymbolStore(path)
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.Mode() != ModeFast {
+		t.Errorf("expected mode %q after reopen, got %q", ModeFast, store.Mode())
+	}
+	if stats, _ := store.GetStats(ctx); stats.LastUpdated.IsZero() {
+		t.Error("expected last update time to be recorded")
+	}
+}
@@ -23,7 +23,7 @@ func NewRegexExtractor() (*RegexExtractor, error) {
 
 // Mode returns the extraction mode.
 func (e *RegexExtractor) Mode() string {
-	return "fast"
+	return ModeFast
 }
 
 // SupportedLanguages returns list of supported file extensions.
@@ -65,6 +65,9 @@ func (e *RegexExtractor) ExtractSymbols(ctx context.Context, filePath string, co
 	for _, re := range patterns.Interfaces {
 		symbols = append(symbols, e.extractMatches(re, content, filePath, patterns.Language, KindInterface)...)
 	}
+	if ext == ".go" {
+		symbols = append(symbols, extractGoInterfaceMethods(filePath, content)...)
+	}
 
 	// Extract types
 	for _, re := range patterns.Types {
@@ -18,43 +18,58 @@ import (
 
 // TreeSitterExtractor implements SymbolExtractor using tree-sitter AST parsing.
 type TreeSitterExtractor struct {
-	parsers map[string]*sitter.Parser
+	languages map[string]*sitter.Language
 }
 
 // NewTreeSitterExtractor creates a new tree-sitter based extractor.
 func NewTreeSitterExtractor() (*TreeSitterExtractor, error) {
-	ext := &TreeSitterExtractor{
-		parsers: make(map[string]*sitter.Parser),
-	}
+	return &TreeSitterExtractor{
+		languages: map[string]*sitter.Language{
+			".go":  golang.GetLanguage(),
+			".js":  javascript.GetLanguage(),
+			".jsx": javascript.GetLanguage(),
+			".ts":  typescript.GetLanguage(),
+			".tsx": typescript.GetLanguage(),
+			".py":  python.GetLanguage(),
+			".php": php.GetLanguage(),
+		},
+	}, nil
+}
 
-	languages := map[string]*sitter.Language{
-		".go":  golang.GetLanguage(),
-		".js":  javascript.GetLanguage(),
-		".jsx": javascript.GetLanguage(),
-		".ts":  typescript.GetLanguage(),
-		".tsx": typescript.GetLanguage(),
-		".py":  python.GetLanguage(),
-		".php": php.GetLanguage(),
-	}
+// newPreciseExtractor returns the extractor used for trace.mode "precise".
+func newPreciseExtractor() (SymbolExtractor, error) {
+	return NewTreeSitterExtractor()
+}
 
-	for extension, lang := range languages {
-		parser := sitter.NewParser()
-		parser.SetLanguage(lang)
-		ext.parsers[extension] = parser
+// parse parses content with a fresh parser. Parsers are not safe for
+// concurrent use, and files are extracted in parallel. It returns a nil tree
+// for unsupported languages.
+func (e *TreeSitterExtractor) parse(ctx context.Context, ext string, content []byte) (*sitter.Tree, error) {
+	lang, ok := e.languages[ext]
+	if !ok {
+		return nil, nil
 	}
 
-	return ext, nil
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(lang)
+
+	tree, err := parser.ParseCtx(ctx, nil, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+	return tree, nil
 }
 
 // Mode returns the extraction mode.
 func (e *TreeSitterExtractor) Mode() string {
-	return "precise"
+	return ModePrecise
 }
 
 // SupportedLanguages returns list of supported file extensions.
 func (e *TreeSitterExtractor) SupportedLanguages() []string {
-	langs := make([]string, 0, len(e.parsers))
-	for ext := range e.parsers {
+	langs := make([]string, 0, len(e.languages))
+	for ext := range e.languages {
 		langs = append(langs, ext)
 	}
 	return langs
@@ -63,21 +78,17 @@ func (e *TreeSitterExtractor) SupportedLanguages() []string {
 // ExtractSymbols extracts all symbol definitions from a file using tree-sitter.
 func (e *TreeSitterExtractor) ExtractSymbols(ctx context.Context, filePath string, content string) ([]Symbol, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
-	parser, ok := e.parsers[ext]
-	if !ok {
-		return nil, nil
-	}
-
-	tree, err := parser.ParseCtx(ctx, nil, []byte(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse file: %w", err)
+	tree, err := e.parse(ctx, ext, []byte(content))
+	if tree == nil || err != nil {
+		return nil, err
 	}
 	defer tree.Close()
 
 	var symbols []Symbol
-	root := tree.RootNode()
-
-	e.walkNodeForSymbols(root, []byte(content), filePath, ext, &symbols)
+	e.walkNodeForSymbols(tree.RootNode(), []byte(content), filePath, ext, &symbols)
+	if ext == ".go" {
+		symbols = append(symbols, extractGoInterfaceMethods(filePath, content)...)
+	}
 
 	return symbols, nil
 }
@@ -129,23 +140,18 @@ func (e *TreeSitterExtractor) extractGoSymbol(node *sitter.Node, nodeType string
 			name := nameNode.Content(content)
 			var receiver string
 			if receiverNode != nil {
-				for i := 0; i < int(receiverNode.ChildCount()); i++ {
-					child := receiverNode.Child(i)
-					if child.Type() == "type_identifier" || child.Type() == "pointer_type" {
-						receiver = child.Content(content)
-						break
-					}
-				}
+				receiver = goReceiverType(receiverNode, content)
 			}
 			*symbols = append(*symbols, Symbol{
-				Name:     name,
-				Kind:     KindMethod,
-				File:     filePath,
-				Line:     int(node.StartPoint().Row) + 1,
-				EndLine:  int(node.EndPoint().Row) + 1,
-				Receiver: receiver,
-				Exported: isExported(name, "go"),
-				Language: "go",
+				Name:      name,
+				Kind:      KindMethod,
+				File:      filePath,
+				Line:      int(node.StartPoint().Row) + 1,
+				EndLine:   int(node.EndPoint().Row) + 1,
+				Signature: truncateSignature(string(content[node.StartByte():node.EndByte()])),
+				Receiver:  receiver,
+				Exported:  isExported(name, "go"),
+				Language:  "go",
 			})
 		}
 
@@ -182,6 +188,20 @@ func (e *TreeSitterExtractor) extractGoSymbol(node *sitter.Node, nodeType string
 
 func (e *TreeSitterExtractor) extractJSSymbol(node *sitter.Node, nodeType string, content []byte, filePath string, lang string, symbols *[]Symbol) {
 	switch nodeType {
+	case "method_definition":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode != nil {
+			*symbols = append(*symbols, Symbol{
+				Name:     nameNode.Content(content),
+				Kind:     KindMethod,
+				File:     filePath,
+				Line:     int(node.StartPoint().Row) + 1,
+				EndLine:  int(node.EndPoint().Row) + 1,
+				Receiver: enclosingClassName(node, content),
+				Language: lang,
+			})
+		}
+
 	case "function_declaration":
 		nameNode := node.ChildByFieldName("name")
 		if nameNode != nil {
@@ -196,7 +216,7 @@ func (e *TreeSitterExtractor) extractJSSymbol(node *sitter.Node, nodeType string
 			})
 		}
 
-	case "class_declaration":
+	case "class_declaration", "abstract_class_declaration":
 		nameNode := node.ChildByFieldName("name")
 		if nameNode != nil {
 			name := nameNode.Content(content)
@@ -254,17 +274,20 @@ func (e *TreeSitterExtractor) extractPythonSymbol(node *sitter.Node, nodeType st
 		if nameNode != nil {
 			name := nameNode.Content(content)
 			kind := KindFunction
+			var receiver string
 			// Check if it's a method (inside a class)
 			parent := node.Parent()
 			if parent != nil && parent.Type() == "block" {
 				grandparent := parent.Parent()
 				if grandparent != nil && grandparent.Type() == "class_definition" {
 					kind = KindMethod
+					receiver = enclosingClassName(node, content)
 				}
 			}
 			*symbols = append(*symbols, Symbol{
 				Name:     name,
 				Kind:     kind,
+				Receiver: receiver,
 				File:     filePath,
 				Line:     int(node.StartPoint().Row) + 1,
 				EndLine:  int(node.EndPoint().Row) + 1,
@@ -316,6 +339,7 @@ func (e *TreeSitterExtractor) extractPHPSymbol(node *sitter.Node, nodeType strin
 				File:     filePath,
 				Line:     int(node.StartPoint().Row) + 1,
 				EndLine:  int(node.EndPoint().Row) + 1,
+				Receiver: enclosingClassName(node, content),
 				Language: "php",
 			})
 		}
@@ -349,73 +373,256 @@ func (e *TreeSitterExtractor) extractPHPSymbol(node *sitter.Node, nodeType strin
 	}
 }
 
-// ExtractReferences extracts all symbol references from a file.
+// ExtractReferences extracts all symbol references from a file. Calls made
+// through an imported package or module are qualified with its import path;
+// other method calls are qualified with the receiver expression.
 func (e *TreeSitterExtractor) ExtractReferences(ctx context.Context, filePath string, content string) ([]Reference, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
-	parser, ok := e.parsers[ext]
-	if !ok {
-		return nil, nil
-	}
-
-	tree, err := parser.ParseCtx(ctx, nil, []byte(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse file: %w", err)
+	tree, err := e.parse(ctx, ext, []byte(content))
+	if tree == nil || err != nil {
+		return nil, err
 	}
 	defer tree.Close()
 
-	var refs []Reference
 	root := tree.RootNode()
+	imports := make(map[string]string)
+	collectImports(root, []byte(content), ext, imports)
 
-	e.walkNodeForCalls(root, []byte(content), filePath, ext, &refs)
+	var refs []Reference
+	e.walkNodeForCalls(root, []byte(content), filePath, ext, imports, &refs)
 
 	return refs, nil
 }
 
-func (e *TreeSitterExtractor) walkNodeForCalls(node *sitter.Node, content []byte, filePath string, ext string, refs *[]Reference) {
-	nodeType := node.Type()
+func (e *TreeSitterExtractor) walkNodeForCalls(node *sitter.Node, content []byte, filePath string, ext string, imports map[string]string, refs *[]Reference) {
+	if name, qualifier := callTarget(node, content); name != "" {
+		if path, ok := imports[qualifier]; ok {
+			qualifier = path
+		} else if path, ok := imports[name]; ok && qualifier == "" {
+			qualifier = path
+		}
+
+		caller, callerLine := e.findContainingFunction(node, content)
+
+		*refs = append(*refs, Reference{
+			SymbolName: name,
+			Qualifier:  qualifier,
+			File:       filePath,
+			Line:       int(node.StartPoint().Row) + 1,
+			Column:     int(node.StartPoint().Column),
+			Context:    truncateContext(string(content[node.StartByte():node.EndByte()])),
+			CallerName: caller,
+			CallerFile: filePath,
+			CallerLine: callerLine,
+		})
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		e.walkNodeForCalls(child, content, filePath, ext, imports, refs)
+	}
+}
 
-	if nodeType == "call_expression" {
-		funcNode := node.ChildByFieldName("function")
-		if funcNode != nil {
-			name := funcNode.Content(content)
-			// Get just the function name (remove receiver if present)
-			if idx := strings.LastIndex(name, "."); idx >= 0 {
-				name = name[idx+1:]
+// callTarget returns the called name and the expression it is called on for
+// a call node, or an empty name if node is not a call.
+func callTarget(node *sitter.Node, content []byte) (name, qualifier string) {
+	switch node.Type() {
+	case "call_expression", "call", "function_call_expression":
+		// Go, JavaScript/TypeScript, Python and PHP function calls
+		fn := node.ChildByFieldName("function")
+		if fn == nil {
+			return "", ""
+		}
+		switch fn.Type() {
+		case "selector_expression":
+			return fieldContent(fn, "field", content), fieldContent(fn, "operand", content)
+		case "member_expression":
+			return fieldContent(fn, "property", content), fieldContent(fn, "object", content)
+		case "attribute":
+			return fieldContent(fn, "attribute", content), fieldContent(fn, "object", content)
+		case "identifier", "name":
+			return fn.Content(content), ""
+		}
+		// Anything else (qualified names, generic instantiations, ...):
+		// keep the last segment as before
+		name = fn.Content(content)
+		if idx := strings.IndexAny(name, "[<("); idx > 0 {
+			name = name[:idx]
+		}
+		if idx := strings.LastIndexAny(name, `.\`); idx >= 0 {
+			qualifier, name = name[:idx], name[idx+1:]
+		}
+		return name, qualifier
+
+	case "member_call_expression", "nullsafe_member_call_expression":
+		// PHP $obj->method()
+		return fieldContent(node, "name", content), fieldContent(node, "object", content)
+
+	case "scoped_call_expression":
+		// PHP Class::method()
+		return fieldContent(node, "name", content), fieldContent(node, "scope", content)
+	}
+	return "", ""
+}
+
+func fieldContent(node *sitter.Node, field string, content []byte) string {
+	child := node.ChildByFieldName(field)
+	if child == nil {
+		return ""
+	}
+	return child.Content(content)
+}
+
+// collectImports maps the local names bound by import statements to the
+// imported package or module path.
+func collectImports(node *sitter.Node, content []byte, ext string, imports map[string]string) {
+	switch node.Type() {
+	case "import_spec":
+		// Go: import alias "path"
+		path := strings.Trim(fieldContent(node, "path", content), "\"`")
+		local := fieldContent(node, "name", content)
+		if local == "" {
+			local = goPackageName(path)
+		}
+		if local != "_" && local != "." {
+			imports[local] = path
+		}
+		return
+
+	case "import_statement":
+		if ext == ".py" {
+			// Python: import a.b, import a.b as c
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				child := node.NamedChild(i)
+				switch child.Type() {
+				case "dotted_name":
+					// import a.b binds a
+					pkg := strings.SplitN(child.Content(content), ".", 2)[0]
+					imports[pkg] = pkg
+				case "aliased_import":
+					imports[fieldContent(child, "alias", content)] = fieldContent(child, "name", content)
+				}
 			}
+			return
+		}
+		// JavaScript/TypeScript: import x, * as ns, { a as b } from "module"
+		source := strings.Trim(fieldContent(node, "source", content), "'\"`")
+		if source == "" {
+			return
+		}
+		bindJSImports(node, content, source, imports)
+		return
+
+	case "import_from_statement":
+		// Python: from module import a, b as c
+		module := fieldContent(node, "module_name", content)
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child == node.ChildByFieldName("module_name") {
+				continue
+			}
+			switch child.Type() {
+			case "dotted_name":
+				imports[child.Content(content)] = module
+			case "aliased_import":
+				imports[fieldContent(child, "alias", content)] = module
+			}
+		}
+		return
+	}
 
-			caller := e.findContainingFunction(node, content)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		collectImports(node.NamedChild(i), content, ext, imports)
+	}
+}
 
-			*refs = append(*refs, Reference{
-				SymbolName: name,
-				File:       filePath,
-				Line:       int(node.StartPoint().Row) + 1,
-				Column:     int(node.StartPoint().Column),
-				Context:    truncateContext(string(content[node.StartByte():node.EndByte()])),
-				CallerName: caller,
-				CallerFile: filePath,
-			})
+// bindJSImports records the names bound by a JavaScript/TypeScript import clause.
+func bindJSImports(node *sitter.Node, content []byte, source string, imports map[string]string) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "import_clause", "named_imports":
+			bindJSImports(child, content, source, imports)
+		case "identifier":
+			imports[child.Content(content)] = source
+		case "namespace_import":
+			if child.NamedChildCount() > 0 {
+				imports[child.NamedChild(0).Content(content)] = source
+			}
+		case "import_specifier":
+			local := fieldContent(child, "alias", content)
+			if local == "" {
+				local = fieldContent(child, "name", content)
+			}
+			imports[local] = source
 		}
 	}
+}
 
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		e.walkNodeForCalls(child, content, filePath, ext, refs)
+// goPackageName guesses the package name of a Go import path from its last
+// element, skipping major version suffixes ("github.com/x/pgx/v5" -> "pgx",
+// "gopkg.in/yaml.v3" -> "yaml").
+func goPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
 	}
+	if idx := strings.Index(name, ".v"); idx > 0 {
+		name = name[:idx]
+	}
+	return name
 }
 
-func (e *TreeSitterExtractor) findContainingFunction(node *sitter.Node, content []byte) string {
-	parent := node.Parent()
-	for parent != nil {
+// goReceiverType returns the type name of a Go method receiver without
+// pointer or type parameters: (s *Store[T]) -> Store.
+func goReceiverType(receiver *sitter.Node, content []byte) string {
+	for i := 0; i < int(receiver.NamedChildCount()); i++ {
+		param := receiver.NamedChild(i)
+		if param.Type() != "parameter_declaration" {
+			continue
+		}
+		name := strings.TrimPrefix(fieldContent(param, "type", content), "*")
+		if idx := strings.Index(name, "["); idx >= 0 {
+			name = name[:idx]
+		}
+		return strings.TrimSpace(name)
+	}
+	return ""
+}
+
+// enclosingClassName returns the name of the class, interface or trait that
+// contains node, or "" at top level.
+func enclosingClassName(node *sitter.Node, content []byte) string {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
 		switch parent.Type() {
-		case "function_declaration", "method_declaration", "function_definition":
-			nameNode := parent.ChildByFieldName("name")
-			if nameNode != nil {
-				return nameNode.Content(content)
+		case "class_declaration", "abstract_class_declaration", "class_definition", "class",
+			"interface_declaration", "trait_declaration":
+			return fieldContent(parent, "name", content)
+		}
+	}
+	return ""
+}
+
+// findContainingFunction returns the name and start line of the function or
+// method containing node.
+func (e *TreeSitterExtractor) findContainingFunction(node *sitter.Node, content []byte) (string, int) {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		switch parent.Type() {
+		case "function_declaration", "method_declaration", "function_definition", "method_definition":
+			if name := fieldContent(parent, "name", content); name != "" {
+				return name, int(parent.StartPoint().Row) + 1
+			}
+		case "arrow_function", "function", "function_expression":
+			// const handler = () => { ... }
+			if decl := parent.Parent(); decl != nil && decl.Type() == "variable_declarator" {
+				if name := fieldContent(decl, "name", content); name != "" {
+					return name, int(decl.StartPoint().Row) + 1
+				}
 			}
 		}
-		parent = parent.Parent()
 	}
-	return "<top-level>"
+	return "<top-level>", 0
 }
 
 // ExtractAll extracts both symbols and references in one pass.
@@ -0,0 +1,9 @@
+//go:build !treesitter
+
+package trace
+
+// newPreciseExtractor returns the extractor used for trace.mode "precise".
+// Tree-sitter requires cgo and is only compiled in with -tags treesitter.
+func newPreciseExtractor() (SymbolExtractor, error) {
+	return nil, ErrTreeSitterUnavailable
+}
@@ -0,0 +1,166 @@
+//go:build treesitter
+
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func findSymbol(symbols []Symbol, name string) *Symbol {
+	for i := range symbols {
+		if symbols[i].Name == name {
+			return &symbols[i]
+		}
+	}
+	return nil
+}
+
+func findRef(refs []Reference, name string) *Reference {
+	for i := range refs {
+		if refs[i].SymbolName == name {
+			return &refs[i]
+		}
+	}
+	return nil
+}
+
+func TestTreeSitterExtractor_Go(t *testing.T) {
+	ext, err := NewTreeSitterExtractor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := `package store
+
+import (
+	"strings"
+	pg "github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+)
+
+type Store[T any] struct{}
+
+func (s *Store[T]) Save(name string) error {
+	parts := strings.Split(name, ".")
+	_ = yaml.Marshal(parts)
+	s.flush()
+	return nil
+}
+
+func (s Store[T]) flush() {
+	pg.Connect(nil, "")
+}
+`
+	symbols, refs, err := ext.ExtractAll(context.Background(), "store.go", content)
+	if err != nil {
+		t.Fatalf("ExtractAll failed: %v", err)
+	}
+
+	if sym := findSymbol(symbols, "Save"); sym == nil || sym.Receiver != "Store" || sym.Kind != KindMethod {
+		t.Errorf("expected method Save with receiver Store, got %+v", sym)
+	}
+	if sym := findSymbol(symbols, "flush"); sym == nil || sym.Receiver != "Store" {
+		t.Errorf("expected method flush with receiver Store, got %+v", sym)
+	}
+
+	tests := []struct {
+		name, qualifier, caller string
+		callerLine              int
+	}{
+		{"Split", "strings", "Save", 11},
+		{"Marshal", "gopkg.in/yaml.v3", "Save", 11},
+		{"flush", "s", "Save", 11},
+		{"Connect", "github.com/jackc/pgx/v5", "flush", 18},
+	}
+	for _, tt := range tests {
+		ref := findRef(refs, tt.name)
+		if ref == nil {
+			t.Errorf("missing reference to %s", tt.name)
+			continue
+		}
+		if ref.Qualifier != tt.qualifier || ref.CallerName != tt.caller || ref.CallerLine != tt.callerLine {
+			t.Errorf("%s: got qualifier %q caller %s:%d, want %q %s:%d",
+				tt.name, ref.Qualifier, ref.CallerName, ref.CallerLine, tt.qualifier, tt.caller, tt.callerLine)
+		}
+	}
+}
+
+func TestTreeSitterExtractor_TypeScript(t *testing.T) {
+	ext, _ := NewTreeSitterExtractor()
+
+	content := `import * as fs from "fs";
+import { join as pathJoin } from "path";
+
+export class Loader {
+  load(name: string) {
+    const data = fs.readFileSync(pathJoin("dir", name));
+    return this.parse(data);
+  }
+}
+
+const handler = () => {
+  new Loader().load("x");
+};
+`
+	symbols, refs, err := ext.ExtractAll(context.Background(), "loader.ts", content)
+	if err != nil {
+		t.Fatalf("ExtractAll failed: %v", err)
+	}
+
+	if sym := findSymbol(symbols, "load"); sym == nil || sym.Kind != KindMethod || sym.Receiver != "Loader" {
+		t.Errorf("expected method load on Loader, got %+v", sym)
+	}
+	if ref := findRef(refs, "readFileSync"); ref == nil || ref.Qualifier != "fs" || ref.CallerName != "load" {
+		t.Errorf("unexpected readFileSync reference: %+v", ref)
+	}
+	if ref := findRef(refs, "pathJoin"); ref == nil || ref.Qualifier != "path" {
+		t.Errorf("unexpected pathJoin reference: %+v", ref)
+	}
+	if ref := findRef(refs, "parse"); ref == nil || ref.Qualifier != "this" {
+		t.Errorf("unexpected parse reference: %+v", ref)
+	}
+}
+
+func TestTreeSitterExtractor_Python(t *testing.T) {
+	ext, _ := NewTreeSitterExtractor()
+
+	content := `import os.path
+from json import loads as parse_json
+
+class Config:
+    def load(self, path):
+        if os.path.exists(path):
+            return parse_json(open(path).read())
+`
+	symbols, refs, err := ext.ExtractAll(context.Background(), "config.py", content)
+	if err != nil {
+		t.Fatalf("ExtractAll failed: %v", err)
+	}
+
+	if sym := findSymbol(symbols, "load"); sym == nil || sym.Kind != KindMethod || sym.Receiver != "Config" {
+		t.Errorf("expected method load on Config, got %+v", sym)
+	}
+	if ref := findRef(refs, "parse_json"); ref == nil || ref.Qualifier != "json" || ref.CallerName != "load" {
+		t.Errorf("unexpected parse_json reference: %+v", ref)
+	}
+	if ref := findRef(refs, "exists"); ref == nil || ref.Qualifier != "os.path" {
+		t.Errorf("unexpected exists reference: %+v", ref)
+	}
+}
+
+func TestNewExtractor_PreciseFallsBackToRegex(t *testing.T) {
+	ext, err := NewExtractor(ModePrecise)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	// Java has no tree-sitter grammar here; the regex extractor handles it
+	symbols, _, err := ext.ExtractAll(context.Background(), "Main.java", "public class Main {\n  public void run() {}\n}\n")
+	if err != nil {
+		t.Fatalf("ExtractAll failed: %v", err)
+	}
+	if findSymbol(symbols, "Main") == nil {
+		t.Errorf("expected regex fallback to extract Main, got %+v", symbols)
+	}
+}
@@ -0,0 +1,250 @@
+package trace
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Implementation is a type implementing an interface. For method queries,
+// Method is the type's method implementing the interface method.
+type Implementation struct {
+	Interface Symbol  `json:"interface"`
+	Type      Symbol  `json:"type"`
+	Method    *Symbol `json:"method,omitempty"`
+}
+
+// extractGoInterfaceMethods returns the methods declared by the interfaces
+// of a Go file as method symbols whose receiver is the interface. Methods
+// of interfaces embedded from the same file are included; those of other
+// files are not resolved. Files that do not parse yield what parsed.
+func extractGoInterfaceMethods(filePath, content string) []Symbol {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			if it, ok := ts.Type.(*ast.InterfaceType); ok && it.Methods != nil {
+				interfaces[ts.Name.Name] = it
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+
+	var symbols []Symbol
+	for _, name := range names {
+		for _, field := range interfaceMethods(interfaces, name, map[string]bool{}) {
+			ft := field.Type.(*ast.FuncType)
+			method := field.Names[0].Name
+			symbols = append(symbols, Symbol{
+				Name:      method,
+				Kind:      KindMethod,
+				File:      filePath,
+				Line:      fset.Position(field.Pos()).Line,
+				EndLine:   fset.Position(field.End()).Line,
+				Signature: method + strings.TrimPrefix(types.ExprString(ft), "func"),
+				Receiver:  name,
+				Exported:  isExported(method, "go"),
+				Language:  "go",
+			})
+		}
+	}
+	return symbols
+}
+
+// interfaceMethods returns the method fields of the named interface,
+// following the embedded interfaces found in interfaces.
+func interfaceMethods(interfaces map[string]*ast.InterfaceType, name string, seen map[string]bool) []*ast.Field {
+	if seen[name] {
+		return nil
+	}
+	seen[name] = true
+
+	var fields []*ast.Field
+	for _, field := range interfaces[name].Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+			fields = append(fields, field)
+			continue
+		}
+		if ident, ok := field.Type.(*ast.Ident); ok && interfaces[ident.Name] != nil {
+			fields = append(fields, interfaceMethods(interfaces, ident.Name, seen)...)
+		}
+	}
+	return fields
+}
+
+// isInterfaceMethod reports whether sym is a method declared by a Go
+// interface rather than by a concrete type. Only the declarations of
+// concrete methods start with the func keyword.
+func isInterfaceMethod(sym Symbol) bool {
+	return sym.Kind == KindMethod && sym.Language == "go" && sym.Receiver != "" &&
+		!strings.HasPrefix(sym.Signature, "func")
+}
+
+// typeKey identifies a Go type by its package directory and name.
+type typeKey struct {
+	dir  string
+	name string
+}
+
+// methodSet is the methods of a Go type or interface by name.
+type methodSet map[string]Symbol
+
+// ResolveImplementations matches the method sets of the Go types in
+// symbols against those of the interfaces, by method name. A type
+// implements an interface when it has a method of every name the interface
+// declares, on a value or pointer receiver; interfaces with unexported
+// methods are only implemented within their package.
+//
+// query names an interface, a method of interfaces, or both as
+// Interface.Method. For interfaces the implementing types are returned;
+// for methods, the methods implementing them.
+func ResolveImplementations(symbols []Symbol, query string) []Implementation {
+	ifaceName, methodName, qualified := strings.Cut(query, ".")
+	if !qualified {
+		ifaceName, methodName = query, ""
+	}
+
+	ifaces := make(map[typeKey]methodSet)
+	concrete := make(map[typeKey]methodSet)
+	decls := make(map[typeKey]Symbol)
+	for _, sym := range symbols {
+		if sym.Language != "go" {
+			continue
+		}
+		key := typeKey{dir: path.Dir(sym.File), name: sym.Receiver}
+		switch {
+		case isInterfaceMethod(sym):
+			if ifaces[key] == nil {
+				ifaces[key] = make(methodSet)
+			}
+			ifaces[key][sym.Name] = sym
+		case sym.Kind == KindMethod && sym.Receiver != "":
+			if concrete[key] == nil {
+				concrete[key] = make(methodSet)
+			}
+			concrete[key][sym.Name] = sym
+		case sym.Kind == KindInterface || sym.Kind == KindClass || sym.Kind == KindType:
+			key.name = sym.Name
+			// Prefer the interface symbol when the regex extractor also
+			// recorded it as a type
+			if prev, ok := decls[key]; !ok || prev.Kind != KindInterface {
+				decls[key] = sym
+			}
+		}
+	}
+
+	// An unqualified name is an interface when one is declared with it,
+	// else a method name
+	if !qualified {
+		for key := range ifaces {
+			if key.name == query {
+				qualified = true
+				break
+			}
+		}
+		if !qualified {
+			ifaceName, methodName = "", query
+		}
+	}
+
+	var result []Implementation
+	for ikey, imethods := range ifaces {
+		if ifaceName != "" && ikey.name != ifaceName {
+			continue
+		}
+		if _, ok := imethods[methodName]; methodName != "" && !ok {
+			continue
+		}
+		iface := declaration(decls, ikey, imethods, KindInterface)
+		samePackage := false
+		for name := range imethods {
+			if !isExported(name, "go") {
+				samePackage = true
+			}
+		}
+
+		for tkey, tmethods := range concrete {
+			if samePackage && tkey.dir != ikey.dir {
+				continue
+			}
+			if !tmethods.covers(imethods) {
+				continue
+			}
+			impl := Implementation{
+				Interface: iface,
+				Type:      declaration(decls, tkey, tmethods, KindType),
+			}
+			if methodName != "" {
+				method := tmethods[methodName]
+				impl.Method = &method
+			}
+			result = append(result, impl)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Interface.File != b.Interface.File {
+			return a.Interface.File < b.Interface.File
+		}
+		if a.Interface.Line != b.Interface.Line {
+			return a.Interface.Line < b.Interface.Line
+		}
+		if a.Type.File != b.Type.File {
+			return a.Type.File < b.Type.File
+		}
+		return a.Type.Line < b.Type.Line
+	})
+	return result
+}
+
+// covers reports whether m has a method of every name in other.
+func (m methodSet) covers(other methodSet) bool {
+	for name := range other {
+		if _, ok := m[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// declaration returns the declaration of the type key, or one built from
+// its first method when the type is not in the index, e.g. an unexported
+// type the regex extractor skips.
+func declaration(decls map[typeKey]Symbol, key typeKey, methods methodSet, kind SymbolKind) Symbol {
+	if sym, ok := decls[key]; ok {
+		return sym
+	}
+	var first Symbol
+	for _, m := range methods {
+		if first.File == "" || m.File < first.File || (m.File == first.File && m.Line < first.Line) {
+			first = m
+		}
+	}
+	return Symbol{
+		Name:     key.name,
+		Kind:     kind,
+		File:     first.File,
+		Line:     first.Line,
+		Exported: isExported(key.name, "go"),
+		Language: "go",
+	}
+}
@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+const implementsSource = `package store
+
+type Reader interface {
+	Get(key string) (string, error)
+}
+
+type Store interface {
+	Reader
+	Put(key, value string) error
+}
+
+type Disk struct{}
+
+func (d *Disk) Get(key string) (string, error) { return "", nil }
+func (d *Disk) Put(key, value string) error  { return nil }
+`
+
+func TestRegexExtractor_InterfaceMethods(t *testing.T) {
+	extractor, err := NewRegexExtractor()
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+	symbols, err := extractor.ExtractSymbols(context.Background(), "store/store.go", implementsSource)
+	if err != nil {
+		t.Fatalf("ExtractSymbols failed: %v", err)
+	}
+
+	methods := make(map[string]Symbol)
+	for _, sym := range symbols {
+		if isInterfaceMethod(sym) {
+			methods[sym.Receiver+"."+sym.Name] = sym
+		}
+	}
+	// Store embeds Reader from the same file
+	for _, name := range []string{"Reader.Get", "Store.Get", "Store.Put"} {
+		if _, ok := methods[name]; !ok {
+			t.Errorf("missing interface method %s in %v", name, methods)
+		}
+	}
+	if len(methods) != 3 {
+		t.Errorf("expected 3 interface methods, got %d: %v", len(methods), methods)
+	}
+	if got := methods["Store.Put"].Signature; got != "Put(key, value string) error" {
+		t.Errorf("Store.Put signature = %q", got)
+	}
+	if got := methods["Reader.Get"].Line; got != 4 {
+		t.Errorf("Reader.Get line = %d, want 4", got)
+	}
+}
+
+func TestResolveImplementations(t *testing.T) {
+	extractor, err := NewRegexExtractor()
+	if err != nil {
+		t.Fatalf("failed to create extractor: %v", err)
+	}
+	ctx := context.Background()
+	symbols, _ := extractor.ExtractSymbols(ctx, "store/store.go", implementsSource)
+	other, _ := extractor.ExtractSymbols(ctx, "mem/mem.go", `package mem
+
+type Mem struct{}
+
+func (m Mem) Get(key string) (string, error) { return "", nil }
+
+type sealed interface {
+	Get(key string) (string, error)
+	seal()
+}
+`)
+	symbols = append(symbols, other...)
+
+	tests := []struct {
+		query string
+		want  []string // Interface Type[.Method]
+	}{
+		{"Store", []string{"Store Disk"}},
+		{"Reader", []string{"Reader Mem", "Reader Disk"}},
+		{"Store.Put", []string{"Store Disk.Put"}},
+		{"Get", []string{"Reader Mem.Get", "Reader Disk.Get", "Store Disk.Get"}},
+		{"sealed", nil},
+		{"Unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, impl := range ResolveImplementations(symbols, tt.query) {
+				s := impl.Interface.Name + " " + impl.Type.Name
+				if impl.Method != nil {
+					s += "." + impl.Method.Name
+				}
+				got = append(got, s)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ResolveImplementations(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ResolveImplementations(%q)[%d] = %q, want %q", tt.query, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// SourceFile is a file considered for symbol extraction.
+type SourceFile struct {
+	Path    string
+	Content string
+	Hash    string
+}
+
+// UpdateStats summarizes an incremental symbol index update.
+type UpdateStats struct {
+	Extracted int // files whose symbols were (re)extracted
+	Skipped   int // files unchanged since the last update
+	Removed   int // files dropped from the index because they no longer exist
+	Renamed   int // files whose symbols moved from a removed file with the same content
+	Failed    int // files that could not be extracted
+	Symbols   int // symbols extracted from changed files
+}
+
+// UpdateIndex brings the symbol index in sync with files. Files whose content
+// hash matches the hash stored in the index are skipped; the rest are
+// extracted concurrently by up to workers goroutines (runtime.NumCPU() when
+// workers <= 0). Indexed files that are not in files are removed, unless a
+// new file has the same content, in which case their symbols are moved to it.
+// When the extractor's mode differs from the one the index was built with,
+// every file is re-extracted.
+func UpdateIndex(ctx context.Context, store SymbolStore, extractor SymbolExtractor, files []SourceFile, workers int) (UpdateStats, error) {
+	var stats UpdateStats
+	sameMode := store.Mode() == extractor.Mode()
+
+	// Find files that disappeared since the last run
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		present[f.Path] = true
+	}
+	var gone []string
+	for _, path := range store.IndexedFiles() {
+		if !present[path] {
+			gone = append(gone, path)
+		}
+	}
+
+	// Move the symbols of renamed files instead of extracting them again
+	renamed := make(map[string]bool)
+	if sameMode {
+		for _, f := range files {
+			if old := renameSource(store, gone, f); old != "" {
+				if err := store.RenameFile(ctx, old, f.Path); err != nil {
+					return stats, err
+				}
+				renamed[f.Path] = true
+				stats.Renamed++
+			}
+		}
+	}
+
+	for _, path := range gone {
+		if !store.IsFileIndexed(path) {
+			continue // Renamed
+		}
+		if err := store.DeleteFile(ctx, path); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+	}
+
+	// Only extract files that changed
+	store.SetMode(extractor.Mode())
+
+	var changed []SourceFile
+	for _, f := range files {
+		if renamed[f.Path] {
+			continue
+		}
+		if sameMode && f.Hash != "" && store.IsFileIndexed(f.Path) && store.FileHash(f.Path) == f.Hash {
+			stats.Skipped++
+			continue
+		}
+		changed = append(changed, f)
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(changed) {
+		workers = len(changed)
+	}
+
+	jobs := make(chan SourceFile)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				symbols, refs, err := extractor.ExtractAll(ctx, f.Path, f.Content)
+				if err == nil {
+					err = store.SaveFileWithHash(ctx, f.Path, f.Hash, symbols, refs)
+				}
+
+				mu.Lock()
+				if err != nil {
+					stats.Failed++
+				} else {
+					stats.Extracted++
+					stats.Symbols += len(symbols)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, f := range changed {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stats, ctx.Err()
+}
+
+// RebuildIndex replaces the symbol index with symbols extracted from every
+// file in files, whatever the hashes recorded in the index.
+func RebuildIndex(ctx context.Context, store SymbolStore, extractor SymbolExtractor, files []SourceFile, workers int) (UpdateStats, error) {
+	for _, path := range store.IndexedFiles() {
+		if err := store.DeleteFile(ctx, path); err != nil {
+			return UpdateStats{}, err
+		}
+	}
+	return UpdateIndex(ctx, store, extractor, files, workers)
+}
+
+// RenameSource returns the indexed file that f was renamed from: a file
+// with the same content and extension for which exists reports false. It
+// returns "" when f is already indexed or no such file exists.
+func RenameSource(store SymbolStore, f SourceFile, exists func(path string) bool) string {
+	if f.Hash == "" || store.IsFileIndexed(f.Path) {
+		return ""
+	}
+	var gone []string
+	for _, path := range store.IndexedFiles() {
+		if !exists(path) {
+			gone = append(gone, path)
+		}
+	}
+	return renameSource(store, gone, f)
+}
+
+// renameSource returns the file among gone that f was renamed from, or "".
+func renameSource(store SymbolStore, gone []string, f SourceFile) string {
+	if f.Hash == "" || store.IsFileIndexed(f.Path) {
+		return ""
+	}
+	for _, path := range gone {
+		if store.IsFileIndexed(path) && store.FileHash(path) == f.Hash && filepath.Ext(path) == filepath.Ext(f.Path) {
+			return path
+		}
+	}
+	return ""
+}
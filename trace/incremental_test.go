@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// countingExtractor wraps an extractor and counts ExtractAll calls.
+type countingExtractor struct {
+	SymbolExtractor
+	calls atomic.Int32
+}
+
+func (c *countingExtractor) ExtractAll(ctx context.Context, filePath string, content string) ([]Symbol, []Reference, error) {
+	c.calls.Add(1)
+	return c.SymbolExtractor.ExtractAll(ctx, filePath, content)
+}
+
+func TestUpdateIndex_SkipsUnchangedFiles(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "symbols.gob")
+	testUpdateIndexSkipsUnchangedFiles(t, func() SymbolStore { return NewGOBSymbolStore(indexPath) })
+}
+
+func TestUpdateIndex_SkipsUnchangedFilesBolt(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "symbols.db")
+	testUpdateIndexSkipsUnchangedFiles(t, func() SymbolStore { return NewBoltSymbolStore(indexPath) })
+}
+
+// testUpdateIndexSkipsUnchangedFiles runs incremental updates against the
+// stores returned by open, which must share their storage.
+func testUpdateIndexSkipsUnchangedFiles(t *testing.T, open func() SymbolStore) {
+	ctx := context.Background()
+
+	regex, err := NewRegexExtractor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extractor := &countingExtractor{SymbolExtractor: regex}
+
+	files := []SourceFile{
+		{Path: "a.go", Content: "package a\n\nfunc Alpha() {\n\tBeta()\n}\n", Hash: "h1"},
+		{Path: "b.go", Content: "package a\n\nfunc Beta() {}\n", Hash: "h2"},
+		{Path: "c.go", Content: "package a\n\nfunc Gamma() {}\n", Hash: "h3"},
+	}
+
+	store := open()
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := UpdateIndex(ctx, store, extractor, files, 2)
+	if err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	if stats.Extracted != 3 || stats.Skipped != 0 {
+		t.Errorf("first run: expected 3 extracted, got %+v", stats)
+	}
+	if err := store.Persist(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload from disk: hashes must survive persistence
+	store = open()
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	files[1] = SourceFile{Path: "b.go", Content: "package a\n\nfunc BetaRenamed() {}\n", Hash: "h2b"}
+	files = files[:2] // c.go was deleted
+	extractor.calls.Store(0)
+
+	stats, err = UpdateIndex(ctx, store, extractor, files, 2)
+	if err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	if stats.Extracted != 1 || stats.Skipped != 1 || stats.Removed != 1 {
+		t.Errorf("second run: expected 1 extracted, 1 skipped, 1 removed, got %+v", stats)
+	}
+	if got := extractor.calls.Load(); got != 1 {
+		t.Errorf("expected only the changed file to be extracted, got %d calls", got)
+	}
+
+	if syms, _ := store.LookupSymbol(ctx, "Gamma"); len(syms) != 0 {
+		t.Error("expected symbols from deleted file to be removed")
+	}
+	if syms, _ := store.LookupSymbol(ctx, "BetaRenamed"); len(syms) != 1 {
+		t.Error("expected symbols from changed file to be re-extracted")
+	}
+	if syms, _ := store.LookupSymbol(ctx, "Alpha"); len(syms) != 1 {
+		t.Error("expected symbols from unchanged file to be kept")
+	}
+}
+
+func TestUpdateIndex_MovesRenamedFiles(t *testing.T) {
+	dir := t.TempDir()
+	testUpdateIndexMovesRenamedFiles(t, NewGOBSymbolStore(filepath.Join(dir, "symbols.gob")))
+	testUpdateIndexMovesRenamedFiles(t, NewBoltSymbolStore(filepath.Join(dir, "symbols.db")))
+}
+
+func testUpdateIndexMovesRenamedFiles(t *testing.T, store SymbolStore) {
+	ctx := context.Background()
+	defer store.Close()
+
+	regex, err := NewRegexExtractor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extractor := &countingExtractor{SymbolExtractor: regex}
+	if err := store.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []SourceFile{
+		{Path: "a.go", Content: "package a\n\nfunc Alpha() {\n\tBeta()\n}\n", Hash: "h1"},
+		{Path: "b.go", Content: "package a\n\nfunc Beta() {}\n", Hash: "h2"},
+	}
+	if _, err := UpdateIndex(ctx, store, extractor, files, 1); err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+
+	files[0].Path = "pkg/alpha.go"
+	extractor.calls.Store(0)
+	stats, err := UpdateIndex(ctx, store, extractor, files, 1)
+	if err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	if stats.Renamed != 1 || stats.Removed != 0 || stats.Extracted != 0 {
+		t.Errorf("expected 1 renamed file, got %+v", stats)
+	}
+	if got := extractor.calls.Load(); got != 0 {
+		t.Errorf("expected no extraction for a renamed file, got %d calls", got)
+	}
+	if store.IsFileIndexed("a.go") || store.FileHash("pkg/alpha.go") != "h1" {
+		t.Errorf("expected a.go to be indexed as pkg/alpha.go, got %v", store.IndexedFiles())
+	}
+	if syms, _ := store.LookupSymbol(ctx, "Alpha"); len(syms) != 1 || syms[0].File != "pkg/alpha.go" {
+		t.Errorf("expected Alpha in pkg/alpha.go, got %+v", syms)
+	}
+	callers, _ := store.LookupCallers(ctx, "Beta")
+	moved := false
+	for _, ref := range callers {
+		if ref.File == "a.go" || ref.CallerFile == "a.go" {
+			t.Errorf("expected no references in a.go, got %+v", ref)
+		}
+		moved = moved || (ref.CallerName == "Alpha" && ref.CallerFile == "pkg/alpha.go")
+	}
+	if !moved {
+		t.Errorf("expected Beta called from pkg/alpha.go, got %+v", callers)
+	}
+}
+
+func TestUpdateIndex_NoFiles(t *testing.T) {
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	regex, _ := NewRegexExtractor()
+
+	stats, err := UpdateIndex(context.Background(), store, regex, nil, 0)
+	if err != nil {
+		t.Fatalf("UpdateIndex failed: %v", err)
+	}
+	if stats != (UpdateStats{}) {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+}
+
+func TestRebuildIndex(t *testing.T) {
+	ctx := context.Background()
+	regex, err := NewRegexExtractor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extractor := &countingExtractor{SymbolExtractor: regex}
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+
+	files := []SourceFile{
+		{Path: "a.go", Content: "package a\n\nfunc Alpha() {}\n", Hash: "h1"},
+		{Path: "b.go", Content: "package a\n\nfunc Beta() {}\n", Hash: "h2"},
+	}
+	if _, err := UpdateIndex(ctx, store, extractor, files, 1); err != nil {
+		t.Fatal(err)
+	}
+	// A file indexed from content that no longer exists
+	if err := store.SaveFileWithHash(ctx, "gone.go", "h3", []Symbol{{Name: "Gone", File: "gone.go"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	extractor.calls.Store(0)
+	stats, err := RebuildIndex(ctx, store, extractor, files, 1)
+	if err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if stats.Extracted != 2 || extractor.calls.Load() != 2 {
+		t.Errorf("expected every file to be extracted again, got %+v (%d calls)", stats, extractor.calls.Load())
+	}
+	if store.IsFileIndexed("gone.go") || store.FileHash("a.go") != "h1" {
+		t.Errorf("indexed files = %v", store.IndexedFiles())
+	}
+}
@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"path"
+	"strings"
+)
+
+// DefinitionCallers are the callers of one definition of a name defined
+// several times, e.g. same-named functions in different packages.
+type DefinitionCallers struct {
+	Symbol  Symbol       `json:"symbol"`
+	Callers []CallerInfo `json:"callers"`
+}
+
+// GroupCallers attributes each caller to the definition of defs it calls.
+// callers are aligned with refs, the references they were built from.
+// Callers that cannot be attributed to a single definition are returned
+// separately rather than guessed.
+func GroupCallers(defs []Symbol, refs []Reference, callers []CallerInfo) ([]DefinitionCallers, []CallerInfo) {
+	groups := make([]DefinitionCallers, len(defs))
+	for i, def := range defs {
+		groups[i] = DefinitionCallers{Symbol: def, Callers: []CallerInfo{}}
+	}
+
+	var unresolved []CallerInfo
+	for i, ref := range refs {
+		if d := resolveDefinition(defs, ref); d >= 0 {
+			groups[d].Callers = append(groups[d].Callers, callers[i])
+		} else {
+			unresolved = append(unresolved, callers[i])
+		}
+	}
+	return groups, unresolved
+}
+
+// resolveDefinition returns the index of the definition in defs that ref
+// calls, or -1 when the reference matches several of them equally.
+//
+// Calls qualified with an import path (precise mode) go to the definitions
+// in the imported package. Unqualified calls go to a definition in the same
+// file, else the same directory, preferring functions over methods. Calls
+// through a receiver expression go to methods.
+func resolveDefinition(defs []Symbol, ref Reference) int {
+	candidates := make([]int, len(defs))
+	for i := range defs {
+		candidates[i] = i
+	}
+	// narrow keeps the candidates matching keep, unless none does
+	narrow := func(keep func(def Symbol) bool) {
+		var kept []int
+		for _, i := range candidates {
+			if keep(defs[i]) {
+				kept = append(kept, i)
+			}
+		}
+		if len(kept) > 0 {
+			candidates = kept
+		}
+	}
+
+	switch {
+	case ref.Qualifier == "":
+		narrow(func(def Symbol) bool { return def.File == ref.File })
+		narrow(func(def Symbol) bool { return path.Dir(def.File) == path.Dir(ref.File) })
+		narrow(func(def Symbol) bool { return def.Receiver == "" && def.Kind != KindMethod })
+	case importsDefinition(ref, defs):
+		narrow(func(def Symbol) bool { return importMatches(ref, def) })
+	default:
+		narrow(func(def Symbol) bool { return def.Receiver != "" || def.Kind == KindMethod })
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return -1
+}
+
+// importsDefinition reports whether ref's qualifier is the import path of
+// the package of one of defs, rather than a receiver expression.
+func importsDefinition(ref Reference, defs []Symbol) bool {
+	for _, def := range defs {
+		if importMatches(ref, def) {
+			return true
+		}
+	}
+	return false
+}
+
+// importMatches reports whether ref's qualifier imports the file or
+// directory def is defined in. Go import paths end with the package
+// directory; relative JavaScript imports are resolved from the calling file
+// and dotted Python modules name the file from the project root.
+func importMatches(ref Reference, def Symbol) bool {
+	// Go import paths are prefixed with the module path; other qualifiers
+	// must name the target exactly, so that receiver expressions never match
+	q := ref.Qualifier
+	exact := true
+	switch {
+	case strings.HasPrefix(q, "./") || strings.HasPrefix(q, "../"):
+		q = path.Join(path.Dir(ref.File), q)
+	case path.Ext(ref.File) == ".py":
+		q = strings.ReplaceAll(q, ".", "/")
+	default:
+		exact = false
+	}
+
+	dir := path.Dir(def.File)
+	module := strings.TrimSuffix(def.File, path.Ext(def.File))
+	for _, target := range []string{dir, module} {
+		if target == "." {
+			continue
+		}
+		if (exact && q == target) || (!exact && strings.HasSuffix(q, "/"+target)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroupCallers(t *testing.T) {
+	defs := []Symbol{
+		{Name: "New", Kind: KindFunction, File: "auth/auth.go", Line: 10},
+		{Name: "New", Kind: KindFunction, File: "billing/billing.go", Line: 20},
+		{Name: "New", Kind: KindMethod, File: "billing/factory.go", Line: 5, Receiver: "*Factory"},
+	}
+	refs := []Reference{
+		// Same file as a definition
+		{SymbolName: "New", File: "auth/auth.go", Line: 30},
+		// Same package, function preferred over the method
+		{SymbolName: "New", File: "billing/invoice.go", Line: 8},
+		// Imported package (precise mode)
+		{SymbolName: "New", Qualifier: "github.com/acme/app/auth", File: "cmd/main.go", Line: 12},
+		// Through a receiver: the only method
+		{SymbolName: "New", Qualifier: "f", File: "cmd/main.go", Line: 14},
+		// Unqualified from another package: ambiguous
+		{SymbolName: "New", File: "cmd/main.go", Line: 16},
+	}
+	callers := make([]CallerInfo, len(refs))
+	for i, ref := range refs {
+		callers[i] = CallerInfo{CallSite: CallSite{File: ref.File, Line: ref.Line}}
+	}
+
+	groups, unresolved := GroupCallers(defs, refs, callers)
+	if len(groups) != len(defs) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(defs))
+	}
+
+	want := map[string][]int{
+		"auth/auth.go":       {30, 12},
+		"billing/billing.go": {8},
+		"billing/factory.go": {14},
+	}
+	for _, g := range groups {
+		var lines []int
+		for _, c := range g.Callers {
+			lines = append(lines, c.CallSite.Line)
+		}
+		if !slices.Equal(lines, want[g.Symbol.File]) {
+			t.Errorf("callers of %s = %v, want %v", g.Symbol.File, lines, want[g.Symbol.File])
+		}
+	}
+	if len(unresolved) != 1 || unresolved[0].CallSite.Line != 16 {
+		t.Errorf("unresolved = %+v, want the call at line 16", unresolved)
+	}
+}
+
+func TestImportMatches(t *testing.T) {
+	tests := []struct {
+		ref  Reference
+		def  Symbol
+		want bool
+	}{
+		{Reference{Qualifier: "github.com/acme/app/internal/auth", File: "main.go"}, Symbol{File: "internal/auth/auth.go"}, true},
+		{Reference{Qualifier: "github.com/acme/app/auth", File: "main.go"}, Symbol{File: "internal/auth/auth.go"}, false},
+		{Reference{Qualifier: "./auth", File: "src/app.ts"}, Symbol{File: "src/auth.ts"}, true},
+		{Reference{Qualifier: "../lib/auth", File: "src/app.ts"}, Symbol{File: "lib/auth/index.ts"}, true},
+		{Reference{Qualifier: "app.auth", File: "main.py"}, Symbol{File: "app/auth.py"}, true},
+		{Reference{Qualifier: "self.auth", File: "app/views.py"}, Symbol{File: "auth/auth.py"}, false},
+		{Reference{Qualifier: "auth", File: "main.go"}, Symbol{File: "auth/auth.go"}, false}, // a receiver named auth
+	}
+	for _, tt := range tests {
+		if got := importMatches(tt.ref, tt.def); got != tt.want {
+			t.Errorf("importMatches(%q from %s, %s) = %v, want %v", tt.ref.Qualifier, tt.ref.File, tt.def.File, got, tt.want)
+		}
+	}
+}
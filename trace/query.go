@@ -0,0 +1,289 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Callers returns the functions calling name. With exhaustive, callers are
+// grouped per definition of name, and those not attributable to a single
+// definition are listed as unresolved. The result has no Symbol when name
+// is not defined. Mode is left for the caller to set.
+func Callers(ctx context.Context, st SymbolStore, name string, exhaustive bool) (*TraceResult, error) {
+	result := &TraceResult{Query: name}
+	symbols, err := st.LookupSymbol(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup symbol: %w", err)
+	}
+	if len(symbols) == 0 {
+		return result, nil
+	}
+	result.Symbol = &symbols[0]
+
+	refs, err := st.LookupCallers(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup callers: %w", err)
+	}
+	result.Callers = callerInfos(ctx, st, refs)
+	if exhaustive {
+		result.Definitions, result.Unresolved = GroupCallers(symbols, refs, result.Callers)
+		result.Callers = nil
+	}
+	return result, nil
+}
+
+// Callees returns the functions called by the first definition of name.
+// The result has no Symbol when name is not defined.
+func Callees(ctx context.Context, st SymbolStore, name string) (*TraceResult, error) {
+	result := &TraceResult{Query: name}
+	symbols, err := st.LookupSymbol(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup symbol: %w", err)
+	}
+	if len(symbols) == 0 {
+		return result, nil
+	}
+	result.Symbol = &symbols[0]
+
+	refs, err := st.LookupCallees(ctx, name, symbols[0].File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup callees: %w", err)
+	}
+	result.Callees = calleeInfos(ctx, st, refs)
+	return result, nil
+}
+
+// callerInfos returns the callers making the calls refs.
+func callerInfos(ctx context.Context, st SymbolStore, refs []Reference) []CallerInfo {
+	var callers []CallerInfo
+	for _, ref := range refs {
+		caller := Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+		if syms, _ := st.LookupSymbol(ctx, ref.CallerName); len(syms) > 0 {
+			caller = syms[0]
+		}
+		callers = append(callers, CallerInfo{
+			Symbol:   caller,
+			CallSite: CallSite{File: ref.File, Line: ref.Line, Context: ref.Context},
+		})
+	}
+	return callers
+}
+
+// calleeInfos returns the callees of the calls refs.
+func calleeInfos(ctx context.Context, st SymbolStore, refs []Reference) []CalleeInfo {
+	var callees []CalleeInfo
+	for _, ref := range refs {
+		callee := Symbol{Name: ref.SymbolName}
+		if syms, _ := st.LookupSymbol(ctx, ref.SymbolName); len(syms) > 0 {
+			callee = syms[0]
+		}
+		callees = append(callees, CalleeInfo{
+			Symbol:   callee,
+			CallSite: CallSite{File: ref.File, Line: ref.Line, Context: ref.Context},
+		})
+	}
+	return callees
+}
+
+// ExpandCallers follows the callers of result, a result of Callers, up to
+// depth levels, or until every path ends or cycles when depth is 0 or less.
+// Each level is appended after the previous one, with its Depth and the
+// symbol it calls in Via; see walk for how cycles end the traversal.
+func ExpandCallers(ctx context.Context, st SymbolStore, result *TraceResult, depth int) error {
+	if result.Symbol == nil || depth == 1 {
+		return nil
+	}
+	first := make([]hop, len(result.Callers))
+	for i, c := range result.Callers {
+		first[i] = hop(c)
+	}
+	hops, err := walk(result.Symbol.Name, first, depth, func(sym Symbol) ([]hop, error) {
+		refs, err := st.LookupCallers(ctx, sym.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup callers: %w", err)
+		}
+		var next []hop
+		for _, c := range callerInfos(ctx, st, refs) {
+			next = append(next, hop(c))
+		}
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+	result.Callers = make([]CallerInfo, len(hops))
+	for i, h := range hops {
+		result.Callers[i] = CallerInfo(h)
+	}
+	return nil
+}
+
+// ExpandCallees follows the callees of result, a result of Callees, like
+// ExpandCallers follows callers. Callees not defined in the project, such
+// as those of the standard library, are not followed.
+func ExpandCallees(ctx context.Context, st SymbolStore, result *TraceResult, depth int) error {
+	if result.Symbol == nil || depth == 1 {
+		return nil
+	}
+	first := make([]hop, len(result.Callees))
+	for i, c := range result.Callees {
+		first[i] = hop(c)
+	}
+	hops, err := walk(result.Symbol.Name, first, depth, func(sym Symbol) ([]hop, error) {
+		if sym.File == "" {
+			return nil, nil
+		}
+		refs, err := st.LookupCallees(ctx, sym.Name, sym.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup callees: %w", err)
+		}
+		var next []hop
+		for _, c := range calleeInfos(ctx, st, refs) {
+			next = append(next, hop(c))
+		}
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+	result.Callees = make([]CalleeInfo, len(hops))
+	for i, h := range hops {
+		result.Callees[i] = CalleeInfo(h)
+	}
+	return nil
+}
+
+// hop is a call found by a recursive trace, a CallerInfo or a CalleeInfo.
+type hop struct {
+	Symbol   Symbol
+	CallSite CallSite
+	Depth    int
+	Via      string
+	Cycle    bool
+}
+
+// walk traces calls breadth first from root, whose direct calls are first,
+// following each symbol with follow up to depth levels (no limit when depth
+// is 0 or less). Every symbol is followed once, from the shortest path that
+// reaches it; a call to a symbol already on that path, root included, is
+// kept with Cycle set and not followed. References of a symbol to itself at
+// its own declaration, which extraction records as calls, are dropped.
+func walk(root string, first []hop, depth int, follow func(Symbol) ([]hop, error)) ([]hop, error) {
+	parent := map[string]string{root: ""} // symbol followed -> the symbol it was reached from
+	onPath := func(name, from string) bool {
+		for n := from; n != ""; n = parent[n] {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var all []hop
+	level := first
+	for d := 1; len(level) > 0; d++ {
+		var frontier []Symbol
+		kept := level[:0]
+		for _, h := range level {
+			h.Depth = d
+			from := h.Via
+			if d == 1 {
+				from = root
+			}
+			if h.Symbol.Name == from && h.CallSite.File == h.Symbol.File && h.CallSite.Line == h.Symbol.Line {
+				continue
+			}
+			if onPath(h.Symbol.Name, from) {
+				h.Cycle = true
+			} else if _, followed := parent[h.Symbol.Name]; !followed {
+				parent[h.Symbol.Name] = from
+				frontier = append(frontier, h.Symbol)
+			}
+			kept = append(kept, h)
+		}
+		all = append(all, kept...)
+		if depth > 0 && d >= depth {
+			break
+		}
+
+		level = nil
+		for _, sym := range frontier {
+			next, err := follow(sym)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range next {
+				h.Via = sym.Name
+				level = append(level, h)
+			}
+		}
+	}
+	return all, nil
+}
+
+// Flatten keeps one entry per symbol among the callers and callees of r,
+// the first and so the shallowest found, for agents that need the set of
+// symbols a change reaches rather than every call.
+func (r *TraceResult) Flatten() {
+	seen := make(map[string]bool)
+	key := func(sym Symbol) string { return sym.Name + "\x00" + sym.File }
+	callers := r.Callers[:0]
+	for _, c := range r.Callers {
+		if !seen[key(c.Symbol)] {
+			seen[key(c.Symbol)] = true
+			callers = append(callers, c)
+		}
+	}
+	r.Callers = callers
+
+	clear(seen)
+	callees := r.Callees[:0]
+	for _, c := range r.Callees {
+		if !seen[key(c.Symbol)] {
+			seen[key(c.Symbol)] = true
+			callees = append(callees, c)
+		}
+	}
+	r.Callees = callees
+}
+
+// Graph returns the call graph around name up to depth levels.
+func Graph(ctx context.Context, st SymbolStore, name string, depth int) (*TraceResult, error) {
+	graph, err := st.GetCallGraph(ctx, name, depth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build call graph: %w", err)
+	}
+	return &TraceResult{Query: name, Graph: graph}, nil
+}
+
+// Implementations returns the Go types implementing the interface query,
+// or the methods implementing the interface method query (Method or
+// Interface.Method). The result has no Symbol when the interface or method
+// is not defined.
+func Implementations(ctx context.Context, st SymbolStore, query string) (*TraceResult, error) {
+	result := &TraceResult{Query: query}
+	name, _, _ := strings.Cut(query, ".")
+	symbols, err := st.LookupSymbol(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup symbol: %w", err)
+	}
+	if len(symbols) == 0 {
+		return result, nil
+	}
+	result.Symbol = &symbols[0]
+	// Prefer the interface or interface method to a same-named method
+	for i, sym := range symbols {
+		if sym.Kind == KindInterface || isInterfaceMethod(sym) {
+			result.Symbol = &symbols[i]
+			break
+		}
+	}
+
+	all, err := st.FindSymbols(ctx, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+	result.Implementations = ResolveImplementations(all, query)
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// saveCalls saves one function per file, calling the functions listed, with
+// the reference to itself that extraction records at its declaration.
+func saveCalls(t *testing.T, st SymbolStore, calls map[string][]string) {
+	t.Helper()
+	ctx := context.Background()
+	for caller, callees := range calls {
+		file := strings.ToLower(caller) + ".go"
+		refs := []Reference{{SymbolName: caller, File: file, Line: 1, CallerName: caller, CallerFile: file, CallerLine: 1}}
+		for i, callee := range callees {
+			refs = append(refs, Reference{SymbolName: callee, File: file, Line: i + 2, CallerName: caller, CallerFile: file, CallerLine: 1})
+		}
+		if err := st.SaveFile(ctx, file, []Symbol{{Name: caller, Kind: KindFunction, File: file, Line: 1}}, refs); err != nil {
+			t.Fatalf("SaveFile failed: %v", err)
+		}
+	}
+}
+
+// levels describes callees or callers as "name@depth<via", with a
+// trailing ! for cycles; direct calls of a trace that was not expanded have
+// no depth.
+func levels[T CallerInfo | CalleeInfo](infos []T) string {
+	var parts []string
+	for _, info := range infos {
+		h := hop(info)
+		s := h.Symbol.Name
+		if h.Depth > 0 {
+			s += fmt.Sprintf("@%d", h.Depth)
+		}
+		if h.Via != "" {
+			s += "<" + h.Via
+		}
+		if h.Cycle {
+			s += "!"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestExpandCallees(t *testing.T) {
+	ctx := context.Background()
+	st := newTestBoltStore(t)
+	// A -> B -> C -> A is a cycle; A -> D -> C reaches C twice
+	saveCalls(t, st, map[string][]string{
+		"A": {"B", "D"},
+		"B": {"C"},
+		"C": {"A", "Println"},
+		"D": {"C"},
+	})
+
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{1, "A B D"},
+		{2, "B@1 D@1 C@2<B C@2<D"},
+		{0, "B@1 D@1 C@2<B C@2<D A@3<C! Println@3<C"},
+	}
+	for _, tt := range tests {
+		result, err := Callees(ctx, st, "A")
+		if err != nil {
+			t.Fatalf("Callees failed: %v", err)
+		}
+		if err := ExpandCallees(ctx, st, result, tt.depth); err != nil {
+			t.Fatalf("ExpandCallees failed: %v", err)
+		}
+		if got := levels(result.Callees); got != tt.want {
+			t.Errorf("depth %d: got %s, want %s", tt.depth, got, tt.want)
+		}
+	}
+
+	result, _ := Callees(ctx, st, "A")
+	_ = ExpandCallees(ctx, st, result, 0)
+	result.Flatten()
+	if got := levels(result.Callees); got != "B@1 D@1 C@2<B A@3<C! Println@3<C" {
+		t.Errorf("flattened callees: got %s", got)
+	}
+}
+
+func TestExpandCallers(t *testing.T) {
+	ctx := context.Background()
+	st := newTestBoltStore(t)
+	saveCalls(t, st, map[string][]string{
+		"Main":   {"Serve"},
+		"Serve":  {"Handle"},
+		"Retry":  {"Handle", "Retry"},
+		"Handle": nil,
+	})
+
+	result, err := Callers(ctx, st, "Handle", false)
+	if err != nil {
+		t.Fatalf("Callers failed: %v", err)
+	}
+	if err := ExpandCallers(ctx, st, result, 0); err != nil {
+		t.Fatalf("ExpandCallers failed: %v", err)
+	}
+	// Callers are listed in store order within a level
+	got := strings.Fields(levels(result.Callers))
+	want := map[string]bool{"Serve@1": true, "Retry@1": true, "Main@2<Serve": true, "Retry@2<Retry!": true}
+	if len(got) != len(want) {
+		t.Fatalf("got callers %v, want %v", got, want)
+	}
+	for _, g := range got {
+		if !want[g] {
+			t.Errorf("unexpected caller %s in %v", g, got)
+		}
+	}
+}
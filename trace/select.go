@@ -0,0 +1,93 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Extraction modes (trace.mode in the config).
+const (
+	ModeFast    = "fast"
+	ModePrecise = "precise"
+)
+
+// ErrTreeSitterUnavailable is returned by NewExtractor for the precise mode
+// when the binary was built without tree-sitter support.
+var ErrTreeSitterUnavailable = errors.New("tree-sitter support not compiled in (build with -tags treesitter)")
+
+// NewExtractor returns the symbol extractor for a trace mode. In precise mode
+// files in languages without a tree-sitter grammar fall back to the regex
+// extractor.
+func NewExtractor(mode string) (SymbolExtractor, error) {
+	regex, err := NewRegexExtractor()
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case "", ModeFast:
+		return regex, nil
+	case ModePrecise:
+		precise, err := newPreciseExtractor()
+		if err != nil {
+			return nil, err
+		}
+		return newFallbackExtractor(precise, regex), nil
+	default:
+		return nil, fmt.Errorf("unknown trace mode: %s", mode)
+	}
+}
+
+// fallbackExtractor routes each file to the primary extractor when it
+// supports the file's language and to the fallback extractor otherwise.
+type fallbackExtractor struct {
+	primary   SymbolExtractor
+	fallback  SymbolExtractor
+	supported map[string]bool
+}
+
+func newFallbackExtractor(primary, fallback SymbolExtractor) *fallbackExtractor {
+	supported := make(map[string]bool)
+	for _, ext := range primary.SupportedLanguages() {
+		supported[ext] = true
+	}
+	return &fallbackExtractor{primary: primary, fallback: fallback, supported: supported}
+}
+
+func (e *fallbackExtractor) extractorFor(filePath string) SymbolExtractor {
+	if e.supported[strings.ToLower(filepath.Ext(filePath))] {
+		return e.primary
+	}
+	return e.fallback
+}
+
+// Mode returns the primary extractor's mode.
+func (e *fallbackExtractor) Mode() string {
+	return e.primary.Mode()
+}
+
+// SupportedLanguages returns the extensions supported by either extractor.
+func (e *fallbackExtractor) SupportedLanguages() []string {
+	langs := e.primary.SupportedLanguages()
+	for _, ext := range e.fallback.SupportedLanguages() {
+		if !e.supported[ext] {
+			langs = append(langs, ext)
+		}
+	}
+	return langs
+}
+
+func (e *fallbackExtractor) ExtractSymbols(ctx context.Context, filePath string, content string) ([]Symbol, error) {
+	return e.extractorFor(filePath).ExtractSymbols(ctx, filePath, content)
+}
+
+func (e *fallbackExtractor) ExtractReferences(ctx context.Context, filePath string, content string) ([]Reference, error) {
+	return e.extractorFor(filePath).ExtractReferences(ctx, filePath, content)
+}
+
+func (e *fallbackExtractor) ExtractAll(ctx context.Context, filePath string, content string) ([]Symbol, []Reference, error) {
+	return e.extractorFor(filePath).ExtractAll(ctx, filePath, content)
+}
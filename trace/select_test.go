@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewExtractor(t *testing.T) {
+	ext, err := NewExtractor(ModeFast)
+	if err != nil {
+		t.Fatalf("NewExtractor(fast) failed: %v", err)
+	}
+	if ext.Mode() != ModeFast {
+		t.Errorf("expected fast mode, got %s", ext.Mode())
+	}
+
+	if _, err := NewExtractor("bogus"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+
+	// Precise mode either works (built with -tags treesitter) or reports
+	// that tree-sitter is unavailable so callers can fall back
+	ext, err = NewExtractor(ModePrecise)
+	if err != nil {
+		if !errors.Is(err, ErrTreeSitterUnavailable) {
+			t.Errorf("expected ErrTreeSitterUnavailable, got %v", err)
+		}
+		return
+	}
+	if ext.Mode() != ModePrecise {
+		t.Errorf("expected precise mode, got %s", ext.Mode())
+	}
+}
+
+// stubExtractor returns a single symbol named after its mode.
+type stubExtractor struct {
+	mode  string
+	langs []string
+}
+
+func (s *stubExtractor) ExtractSymbols(ctx context.Context, filePath string, content string) ([]Symbol, error) {
+	return []Symbol{{Name: s.mode, File: filePath}}, nil
+}
+
+func (s *stubExtractor) ExtractReferences(ctx context.Context, filePath string, content string) ([]Reference, error) {
+	return nil, nil
+}
+
+func (s *stubExtractor) ExtractAll(ctx context.Context, filePath string, content string) ([]Symbol, []Reference, error) {
+	symbols, _ := s.ExtractSymbols(ctx, filePath, content)
+	return symbols, nil, nil
+}
+
+func (s *stubExtractor) SupportedLanguages() []string { return s.langs }
+
+func (s *stubExtractor) Mode() string { return s.mode }
+
+func TestFallbackExtractor(t *testing.T) {
+	primary := &stubExtractor{mode: ModePrecise, langs: []string{".go"}}
+	fallback := &stubExtractor{mode: ModeFast, langs: []string{".go", ".java"}}
+	ext := newFallbackExtractor(primary, fallback)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"main.go", ModePrecise},
+		{"Main.JAVA", ModeFast},
+	}
+	for _, tt := range tests {
+		symbols, _, err := ext.ExtractAll(context.Background(), tt.file, "")
+		if err != nil {
+			t.Fatalf("ExtractAll(%s) failed: %v", tt.file, err)
+		}
+		if len(symbols) != 1 || symbols[0].Name != tt.want {
+			t.Errorf("ExtractAll(%s): expected %s extractor, got %+v", tt.file, tt.want, symbols)
+		}
+	}
+
+	if ext.Mode() != ModePrecise {
+		t.Errorf("expected precise mode, got %s", ext.Mode())
+	}
+	if got := len(ext.SupportedLanguages()); got != 2 {
+		t.Errorf("expected 2 supported languages, got %d", got)
+	}
+}
+
+func TestUpdateIndex_ReextractsOnModeChange(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	files := []SourceFile{{Path: "a.go", Content: "package a", Hash: "h1"}}
+
+	fast := &stubExtractor{mode: ModeFast, langs: []string{".go"}}
+	if _, err := UpdateIndex(ctx, store, fast, files, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	precise := &stubExtractor{mode: ModePrecise, langs: []string{".go"}}
+	stats, err := UpdateIndex(ctx, store, precise, files, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Extracted != 1 || stats.Skipped != 0 {
+		t.Errorf("expected unchanged file to be re-extracted after mode change, got %+v", stats)
+	}
+	if store.Mode() != ModePrecise {
+		t.Errorf("expected index mode precise, got %s", store.Mode())
+	}
+}
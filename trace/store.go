@@ -1,12 +1,17 @@
 package trace
 
 import (
+	"bytes"
 	"context"
 	"encoding/gob"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/doveaia/agentdx/encrypt"
 )
 
 // GOBSymbolStore implements SymbolStore using GOB encoding.
@@ -14,14 +19,21 @@ type GOBSymbolStore struct {
 	indexPath string
 	index     *SymbolIndex
 	fileIndex map[string]bool
+	fileHash  map[string]string // content hash per file, used to skip unchanged files
+	mode      string            // extraction mode the index was built with
+	keys      *encrypt.Source   // encrypts the index file; nil stores it in the clear
 	mu        sync.RWMutex
 }
 
 type gobSymbolData struct {
-	Index     SymbolIndex
-	FileIndex map[string]bool
+	Index      SymbolIndex
+	FileIndex  map[string]bool
+	FileHashes map[string]string
+	Mode       string
 }
 
+var _ SymbolStore = (*GOBSymbolStore)(nil)
+
 // NewGOBSymbolStore creates a new GOB-based symbol store.
 func NewGOBSymbolStore(indexPath string) *GOBSymbolStore {
 	return &GOBSymbolStore{
@@ -33,30 +45,53 @@ func NewGOBSymbolStore(indexPath string) *GOBSymbolStore {
 			Version:    1,
 		},
 		fileIndex: make(map[string]bool),
+		fileHash:  make(map[string]string),
 	}
 }
 
+// SetEncryption encrypts the index file with the key of keys when it is
+// persisted. Indexes written in the clear are still loaded, and encrypted on
+// the next Persist.
+func (s *GOBSymbolStore) SetEncryption(keys *encrypt.Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
 // Load reads the index from storage.
 func (s *GOBSymbolStore) Load(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	file, err := os.Open(s.indexPath)
+	raw, err := os.ReadFile(s.indexPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
 		return fmt.Errorf("failed to open symbol index: %w", err)
 	}
-	defer file.Close()
+	if encrypt.IsSealedFile(raw) {
+		c, err := s.keys.Cipher()
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return fmt.Errorf("symbol index is encrypted: enable encryption in the configuration to read it")
+		}
+		if raw, err = c.OpenFile(raw); err != nil {
+			return fmt.Errorf("failed to read symbol index: %w", err)
+		}
+	}
 
 	var data gobSymbolData
-	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&data); err != nil {
 		return fmt.Errorf("failed to decode symbol index: %w", err)
 	}
 
 	s.index = &data.Index
 	s.fileIndex = data.FileIndex
+	s.fileHash = data.FileHashes
+	s.mode = data.Mode
 
 	if s.index.Symbols == nil {
 		s.index.Symbols = make(map[string][]Symbol)
@@ -70,6 +105,9 @@ func (s *GOBSymbolStore) Load(ctx context.Context) error {
 	if s.fileIndex == nil {
 		s.fileIndex = make(map[string]bool)
 	}
+	if s.fileHash == nil {
+		s.fileHash = make(map[string]string)
+	}
 
 	return nil
 }
@@ -79,27 +117,42 @@ func (s *GOBSymbolStore) Persist(ctx context.Context) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	file, err := os.Create(s.indexPath)
+	c, err := s.keys.Cipher()
 	if err != nil {
-		return fmt.Errorf("failed to create symbol index file: %w", err)
+		return err
 	}
-	defer file.Close()
 
 	s.index.UpdatedAt = time.Now()
 	data := gobSymbolData{
-		Index:     *s.index,
-		FileIndex: s.fileIndex,
+		Index:      *s.index,
+		FileIndex:  s.fileIndex,
+		FileHashes: s.fileHash,
+		Mode:       s.mode,
 	}
 
-	if err := gob.NewEncoder(file).Encode(data); err != nil {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
 		return fmt.Errorf("failed to encode symbol index: %w", err)
 	}
+	raw := buf.Bytes()
+	if c != nil {
+		raw = c.SealFile(raw)
+	}
+	if err := os.WriteFile(s.indexPath, raw, 0644); err != nil {
+		return fmt.Errorf("failed to create symbol index file: %w", err)
+	}
 
 	return nil
 }
 
 // SaveFile persists symbols and references for a file.
 func (s *GOBSymbolStore) SaveFile(ctx context.Context, filePath string, symbols []Symbol, refs []Reference) error {
+	return s.SaveFileWithHash(ctx, filePath, "", symbols, refs)
+}
+
+// SaveFileWithHash persists symbols and references for a file along with the
+// hash of the content they were extracted from.
+func (s *GOBSymbolStore) SaveFileWithHash(ctx context.Context, filePath string, hash string, symbols []Symbol, refs []Reference) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -130,6 +183,9 @@ func (s *GOBSymbolStore) SaveFile(ctx context.Context, filePath string, symbols
 	}
 
 	s.fileIndex[filePath] = true
+	if hash != "" {
+		s.fileHash[filePath] = hash
+	}
 	return nil
 }
 
@@ -141,6 +197,47 @@ func (s *GOBSymbolStore) DeleteFile(ctx context.Context, filePath string) error
 	return nil
 }
 
+// RenameFile moves the symbols and references of a file to newPath.
+func (s *GOBSymbolStore) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.fileIndex[oldPath] || oldPath == newPath {
+		return nil
+	}
+	s.deleteFileUnlocked(newPath)
+
+	for _, symbols := range s.index.Symbols {
+		for i := range symbols {
+			if symbols[i].File == oldPath {
+				symbols[i].File = newPath
+			}
+		}
+	}
+	for _, refs := range s.index.References {
+		for i := range refs {
+			if refs[i].File == oldPath {
+				refs[i].File = newPath
+				if refs[i].CallerFile == oldPath {
+					refs[i].CallerFile = newPath
+				}
+			}
+		}
+	}
+	for i := range s.index.CallGraph {
+		if s.index.CallGraph[i].File == oldPath {
+			s.index.CallGraph[i].File = newPath
+		}
+	}
+
+	s.fileIndex[newPath] = true
+	if hash, ok := s.fileHash[oldPath]; ok {
+		s.fileHash[newPath] = hash
+	}
+	delete(s.fileIndex, oldPath)
+	delete(s.fileHash, oldPath)
+	return nil
+}
+
 func (s *GOBSymbolStore) deleteFileUnlocked(filePath string) {
 	// Remove symbols from this file
 	for name, symbols := range s.index.Symbols {
@@ -182,6 +279,7 @@ func (s *GOBSymbolStore) deleteFileUnlocked(filePath string) {
 	s.index.CallGraph = filtered
 
 	delete(s.fileIndex, filePath)
+	delete(s.fileHash, filePath)
 }
 
 // LookupSymbol finds symbol definitions by name.
@@ -345,9 +443,72 @@ func (s *GOBSymbolStore) GetStats(ctx context.Context) (*SymbolStats, error) {
 	}, nil
 }
 
+// FileHash returns the content hash recorded for a file, or "" if unknown.
+func (s *GOBSymbolStore) FileHash(filePath string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fileHash[filePath]
+}
+
+// Mode returns the extraction mode the index was built with, or "" for
+// indexes written before the mode was recorded.
+func (s *GOBSymbolStore) Mode() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mode
+}
+
+// SetMode records the extraction mode the index is built with.
+func (s *GOBSymbolStore) SetMode(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode = mode
+}
+
+// IndexedFiles returns the paths of all files in the index.
+func (s *GOBSymbolStore) IndexedFiles() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := make([]string, 0, len(s.fileIndex))
+	for path := range s.fileIndex {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // IsFileIndexed checks if a file has been indexed.
 func (s *GOBSymbolStore) IsFileIndexed(filePath string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.fileIndex[filePath]
 }
+
+// FindSymbols returns symbols whose name contains query (case-insensitive),
+// ordered by name. An empty query matches every symbol. A limit of 0 means
+// no limit.
+func (s *GOBSymbolStore) FindSymbols(ctx context.Context, query string, limit int) ([]Symbol, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	names := make([]string, 0, len(s.index.Symbols))
+	for name := range s.index.Symbols {
+		if strings.Contains(strings.ToLower(name), query) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var result []Symbol
+	for _, name := range names {
+		for _, sym := range s.index.Symbols[name] {
+			if limit > 0 && len(result) >= limit {
+				return result, nil
+			}
+			result = append(result, sym)
+		}
+	}
+	return result, nil
+}
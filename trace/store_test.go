@@ -0,0 +1,56 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doveaia/agentdx/config"
+	"github.com/doveaia/agentdx/encrypt"
+)
+
+func TestGOBSymbolStore_Encryption(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "symbols.gob")
+	t.Setenv(config.EncryptionKeyEnv, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, encrypt.KeySize)))
+	keys := encrypt.NewSource(config.EncryptionConfig{Enabled: true})
+
+	// An index written in the clear is read, then encrypted on persist
+	plain := NewGOBSymbolStore(path)
+	saveCalls(t, plain, map[string][]string{"ChargeCard": {"Authorize"}})
+	if err := plain.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	st := NewGOBSymbolStore(path)
+	st.SetEncryption(keys)
+	if err := st.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := st.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypt.IsSealedFile(data) || bytes.Contains(data, []byte("ChargeCard")) {
+		t.Fatal("expected the index to be encrypted")
+	}
+
+	reloaded := NewGOBSymbolStore(path)
+	reloaded.SetEncryption(keys)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if syms, _ := reloaded.LookupSymbol(ctx, "ChargeCard"); len(syms) != 1 {
+		t.Errorf("expected ChargeCard after reload, got %v", syms)
+	}
+
+	if err := NewGOBSymbolStore(path).Load(ctx); err == nil {
+		t.Error("expected an encrypted index to fail to load without a key")
+	}
+}
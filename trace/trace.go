@@ -3,6 +3,8 @@ package trace
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -19,6 +21,28 @@ const (
 	KindConstant  SymbolKind = "constant"
 )
 
+// kindAliases maps the short names accepted by ParseKind to symbol kinds.
+var kindAliases = map[string]SymbolKind{
+	"func":  KindFunction,
+	"fn":    KindFunction,
+	"var":   KindVariable,
+	"const": KindConstant,
+}
+
+// ParseKind parses a symbol kind or one of its short names (func, fn, var,
+// const), ignoring case.
+func ParseKind(s string) (SymbolKind, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if kind, ok := kindAliases[s]; ok {
+		return kind, nil
+	}
+	switch kind := SymbolKind(s); kind {
+	case KindFunction, KindMethod, KindClass, KindInterface, KindType, KindVariable, KindConstant:
+		return kind, nil
+	}
+	return "", fmt.Errorf("invalid symbol kind %q (expected function, method, class, interface, type, variable or constant)", s)
+}
+
 // Symbol represents a symbol definition in the codebase.
 type Symbol struct {
 	Name      string     `json:"name"`
@@ -36,6 +60,7 @@ type Symbol struct {
 // Reference represents a usage/call of a symbol.
 type Reference struct {
 	SymbolName string `json:"symbol_name"`
+	Qualifier  string `json:"qualifier,omitempty"` // import path or receiver expression (precise mode)
 	File       string `json:"file"`
 	Line       int    `json:"line"`
 	Column     int    `json:"column,omitempty"`
@@ -69,20 +94,45 @@ type TraceResult struct {
 	Mode    string       `json:"mode"`
 	Symbol  *Symbol      `json:"symbol,omitempty"`
 	Callers []CallerInfo `json:"callers,omitempty"`
-	Callees []CalleeInfo `json:"callees,omitempty"`
-	Graph   *CallGraph   `json:"graph,omitempty"`
+	// Exhaustive callers: grouped per definition of the name, and those
+	// not attributable to a single definition
+	Definitions []DefinitionCallers `json:"definitions,omitempty"`
+	Unresolved  []CallerInfo        `json:"unresolved,omitempty"`
+	Callees     []CalleeInfo        `json:"callees,omitempty"`
+	Graph       *CallGraph          `json:"graph,omitempty"`
+	// Types or methods implementing an interface or interface method
+	Implementations []Implementation `json:"implementations,omitempty"`
+}
+
+// Size returns the number of callers, callees, call graph edges or
+// implementations found.
+func (r *TraceResult) Size() int {
+	n := len(r.Callers) + len(r.Unresolved) + len(r.Callees) + len(r.Implementations)
+	for _, d := range r.Definitions {
+		n += len(d.Callers)
+	}
+	if r.Graph != nil {
+		n += len(r.Graph.Edges)
+	}
+	return n
 }
 
 // CallerInfo represents a function that calls the target.
 type CallerInfo struct {
 	Symbol   Symbol   `json:"symbol"`
 	CallSite CallSite `json:"call_site"`
+	Depth    int      `json:"depth,omitempty"` // level of a recursive trace, 1 for direct callers
+	Via      string   `json:"via,omitempty"`   // symbol this one calls, below the first level
+	Cycle    bool     `json:"cycle,omitempty"` // the call closes a cycle and was not followed
 }
 
 // CalleeInfo represents a function called by the target.
 type CalleeInfo struct {
 	Symbol   Symbol   `json:"symbol"`
 	CallSite CallSite `json:"call_site"`
+	Depth    int      `json:"depth,omitempty"` // level of a recursive trace, 1 for direct callees
+	Via      string   `json:"via,omitempty"`   // symbol calling this one, below the first level
+	Cycle    bool     `json:"cycle,omitempty"` // the call closes a cycle and was not followed
 }
 
 // CallSite represents the location of a function call.
@@ -132,9 +182,17 @@ type SymbolStore interface {
 	// SaveFile persists symbols and references for a file.
 	SaveFile(ctx context.Context, filePath string, symbols []Symbol, refs []Reference) error
 
+	// SaveFileWithHash is SaveFile that also records the content hash the
+	// symbols were extracted from.
+	SaveFileWithHash(ctx context.Context, filePath string, hash string, symbols []Symbol, refs []Reference) error
+
 	// DeleteFile removes all symbols and references for a file.
 	DeleteFile(ctx context.Context, filePath string) error
 
+	// RenameFile moves the symbols and references of a file to newPath,
+	// replacing those indexed for newPath. Unknown files are ignored.
+	RenameFile(ctx context.Context, oldPath, newPath string) error
+
 	// LookupSymbol finds symbol definitions by name.
 	LookupSymbol(ctx context.Context, name string) ([]Symbol, error)
 
@@ -158,4 +216,22 @@ type SymbolStore interface {
 
 	// GetStats returns statistics about the symbol index.
 	GetStats(ctx context.Context) (*SymbolStats, error)
+
+	// FindSymbols returns symbols whose name contains query.
+	FindSymbols(ctx context.Context, query string, limit int) ([]Symbol, error)
+
+	// FileHash returns the content hash recorded for a file.
+	FileHash(filePath string) string
+
+	// IsFileIndexed checks if a file has been indexed.
+	IsFileIndexed(filePath string) bool
+
+	// IndexedFiles returns the paths of all files in the index.
+	IndexedFiles() []string
+
+	// Mode returns the extraction mode the index was built with.
+	Mode() string
+
+	// SetMode records the extraction mode the index is built with.
+	SetMode(mode string)
 }
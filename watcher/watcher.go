@@ -2,11 +2,18 @@ package watcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/doveaia/agentdx/indexer"
@@ -20,49 +27,124 @@ const (
 	EventModify
 	EventDelete
 	EventRename
+	// EventIgnoreChange reports that an ignore file changed. The ignore
+	// matcher has been reloaded when it is delivered.
+	EventIgnoreChange
+	// EventBulkChange reports that more files changed within a debounce
+	// window than the bulk threshold, as on a git checkout or rebase. The
+	// files are not reported one by one: the whole project should be
+	// rescanned instead.
+	EventBulkChange
 )
 
 type FileEvent struct {
 	Type EventType
 	Path string
+	// Count is the number of paths that changed, for EventBulkChange.
+	Count int
 }
 
+// Backend is the source of file change notifications of a Watcher.
+type Backend string
+
+const (
+	// BackendAuto uses watchman when it is installed and fsnotify otherwise
+	BackendAuto Backend = "auto"
+	// BackendFSNotify adds an inotify (Linux), kqueue (macOS) or
+	// ReadDirectoryChangesW (Windows) watch per directory
+	BackendFSNotify Backend = "fsnotify"
+	// BackendWatchman subscribes to the watchman service, which watches
+	// large trees without a watch per directory (FSEvents on macOS)
+	BackendWatchman Backend = "watchman"
+)
+
 type Watcher struct {
-	root       string
-	watcher    *fsnotify.Watcher
+	root string
+	// Backend in use; fsnotify when watchman was picked automatically but
+	// failed to start
+	backend  Backend
+	auto     bool
+	watcher  *fsnotify.Watcher
+	watchman *watchmanSource
+	// Whether watchman sent the first, complete, file list
+	freshSeen bool
+	// Whether a directory could not be watched for lack of inotify watches
+	limitHit   atomic.Bool
 	ignore     *indexer.IgnoreMatcher
 	debounceMs int
-	events     chan FileEvent
-	done       chan struct{}
+	// Number of paths changing within a debounce window from which they are
+	// reported as a single EventBulkChange; 0 disables bulk detection
+	bulkThreshold int
+	events        chan FileEvent
+	done          chan struct{}
 
 	// Debouncing state
-	pending   map[string]FileEvent
+	pending   map[string]pendingEvent
 	pendingMu sync.Mutex
 	timer     *time.Timer
 }
 
-func NewWatcher(root string, ignore *indexer.IgnoreMatcher, debounceMs int) (*Watcher, error) {
-	fsw, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
+// NewWatcher creates a watcher of the files under root. backend is auto
+// (the default when empty), fsnotify or watchman.
+func NewWatcher(root string, ignore *indexer.IgnoreMatcher, debounceMs, bulkThreshold int, backend Backend) (*Watcher, error) {
+	auto := false
+	switch backend {
+	case "", BackendAuto:
+		auto = true
+		backend = BackendFSNotify
+		if WatchmanAvailable() {
+			backend = BackendWatchman
+		}
+	case BackendFSNotify, BackendWatchman:
+	default:
+		return nil, fmt.Errorf("unknown watcher backend %q (expected auto, fsnotify or watchman)", backend)
 	}
 
 	return &Watcher{
-		root:       root,
-		watcher:    fsw,
-		ignore:     ignore,
-		debounceMs: debounceMs,
-		events:     make(chan FileEvent, 100),
-		done:       make(chan struct{}),
-		pending:    make(map[string]FileEvent),
+		root:          root,
+		backend:       backend,
+		auto:          auto,
+		ignore:        ignore,
+		debounceMs:    debounceMs,
+		bulkThreshold: max(bulkThreshold, 0),
+		events:        make(chan FileEvent, 100),
+		done:          make(chan struct{}),
+		pending:       make(map[string]pendingEvent),
 	}, nil
 }
 
 func (w *Watcher) Start(ctx context.Context) error {
+	if w.backend == BackendWatchman {
+		src, err := startWatchman(ctx, w.root)
+		if err == nil {
+			w.watchman = src
+			go w.readWatchman()
+			return nil
+		}
+		if !w.auto {
+			return err
+		}
+		log.Printf("Failed to start watchman, watching with fsnotify: %v", err)
+		w.backend = BackendFSNotify
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	w.watcher = fsw
+
 	// Add root directory and all subdirectories
 	if err := w.addRecursive(w.root); err != nil {
 		return err
 	}
+	if !w.limitHit.Load() {
+		if limit, ok := inotifyLimit(); ok {
+			if advice := watchLimitAdvice(len(fsw.WatchList()), limit, false); advice != "" {
+				log.Print(advice)
+			}
+		}
+	}
 
 	// Start event processing
 	go w.processEvents(ctx)
@@ -70,13 +152,48 @@ func (w *Watcher) Start(ctx context.Context) error {
 	return nil
 }
 
+// Backend returns the backend the watcher uses.
+func (w *Watcher) Backend() Backend {
+	return w.backend
+}
+
 func (w *Watcher) Events() <-chan FileEvent {
 	return w.events
 }
 
+// QueueDepth returns the number of events waiting to be delivered or
+// debounced.
+func (w *Watcher) QueueDepth() int {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	return len(w.events) + len(w.pending)
+}
+
+// Settling reports whether a bulk change is under way: as many paths as the
+// bulk threshold changed and the debounce window has not elapsed since the
+// last of them. Work that would be redone after the rescan, like saving the
+// symbol index, is better postponed while the watcher is settling.
+func (w *Watcher) Settling() bool {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	return w.isBulk()
+}
+
+// isBulk reports whether the pending paths are a bulk change. The caller
+// must hold pendingMu.
+func (w *Watcher) isBulk() bool {
+	return w.bulkThreshold > 0 && len(w.pending) >= w.bulkThreshold
+}
+
 func (w *Watcher) Close() error {
 	close(w.done)
-	return w.watcher.Close()
+	if w.watchman != nil {
+		w.watchman.close()
+	}
+	if w.watcher != nil {
+		return w.watcher.Close()
+	}
+	return nil
 }
 
 func (w *Watcher) addRecursive(root string) error {
@@ -91,7 +208,7 @@ func (w *Watcher) addRecursive(root string) error {
 		}
 
 		// Check if path should be ignored
-		if w.ignore.ShouldIgnore(relPath) {
+		if w.ignore.ShouldIgnoreEntry(relPath, info.IsDir()) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -100,6 +217,14 @@ func (w *Watcher) addRecursive(root string) error {
 
 		if info.IsDir() {
 			if err := w.watcher.Add(path); err != nil {
+				// Every other directory would fail the same way
+				if errors.Is(err, syscall.ENOSPC) {
+					if !w.limitHit.Swap(true) {
+						limit, _ := inotifyLimit()
+						log.Print(watchLimitAdvice(len(w.watcher.WatchList()), limit, true))
+					}
+					return filepath.SkipAll
+				}
 				log.Printf("Failed to watch %s: %v", path, err)
 			}
 		}
@@ -135,17 +260,16 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		return
 	}
 
-	// Ignore hidden files and ignored paths
-	if strings.HasPrefix(filepath.Base(relPath), ".") {
+	switch w.classify(relPath) {
+	case pathIgnoreFile:
+		w.debounceEvent(FileEvent{
+			Type: EventIgnoreChange,
+			Path: relPath,
+		})
 		return
-	}
-	if w.ignore.ShouldIgnore(relPath) {
+	case pathSkipped:
 		return
-	}
-
-	// Check if it's a supported file
-	ext := strings.ToLower(filepath.Ext(event.Name))
-	if !indexer.SupportedExtensions[ext] {
+	case pathOther:
 		// Check if it's a directory (for watching new directories)
 		info, err := os.Stat(event.Name)
 		if err != nil || !info.IsDir() {
@@ -181,17 +305,41 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	})
 }
 
+// pathKind is how a changed path is handled.
+type pathKind int
+
+const (
+	pathSkipped    pathKind = iota // hidden or ignored
+	pathIgnoreFile                 // an ignore file, which changes which paths are indexed
+	pathSource                     // a file of a supported language
+	pathOther                      // anything else, such as a directory
+)
+
+// classify returns how a change of relPath is handled.
+func (w *Watcher) classify(relPath string) pathKind {
+	if indexer.IsIgnoreFile(relPath) && !w.ignore.ShouldIgnore(filepath.Dir(relPath)) {
+		return pathIgnoreFile
+	}
+	if strings.HasPrefix(filepath.Base(relPath), ".") || w.ignore.ShouldIgnore(relPath) {
+		return pathSkipped
+	}
+	if !indexer.SupportedExtensions[strings.ToLower(filepath.Ext(relPath))] {
+		return pathOther
+	}
+	return pathSource
+}
+
 func (w *Watcher) debounceEvent(event FileEvent) {
 	w.pendingMu.Lock()
 	defer w.pendingMu.Unlock()
 
-	// Merge events: delete > create/modify
-	existing, exists := w.pending[event.Path]
-	if exists && existing.Type == EventDelete && event.Type != EventDelete {
-		// Keep delete if file was deleted then recreated quickly
-		// This will be handled as delete + create
+	// Collapse the events of a path into the first and last, which decide
+	// the operation handed to the indexer
+	if existing, exists := w.pending[event.Path]; exists {
+		existing.last = event.Type
+		w.pending[event.Path] = existing
 	} else {
-		w.pending[event.Path] = event
+		w.pending[event.Path] = pendingEvent{first: event.Type, last: event.Type}
 	}
 
 	// Reset timer
@@ -201,15 +349,83 @@ func (w *Watcher) debounceEvent(event FileEvent) {
 	w.timer = time.AfterFunc(time.Duration(w.debounceMs)*time.Millisecond, w.flush)
 }
 
+// pendingEvent is the event sequence of a path within the debounce window.
+type pendingEvent struct {
+	first EventType
+	last  EventType
+}
+
+// coalesce returns the single operation equivalent to an event sequence, or
+// false when there is nothing to do. Editors that save through a temporary
+// file (vim, JetBrains safe-write) delete or rename a file and create it
+// again: what matters is whether the file exists at the end of the sequence
+// and whether it existed before.
+func coalesce(p pendingEvent) (EventType, bool) {
+	switch p.last {
+	case EventIgnoreChange:
+		return EventIgnoreChange, true
+	case EventCreate, EventModify:
+		if p.first == EventCreate {
+			return EventCreate, true
+		}
+		// Replaced or written in place: the file was indexed before
+		return EventModify, true
+	default:
+		if p.first == EventCreate {
+			// A file created and removed within the window was never indexed
+			return 0, false
+		}
+		return p.last, true
+	}
+}
+
 func (w *Watcher) flush() {
 	w.pendingMu.Lock()
+	if w.isBulk() {
+		count := len(w.pending)
+		ignoreChanged := false
+		for _, p := range w.pending {
+			if p.last == EventIgnoreChange {
+				ignoreChanged = true
+				break
+			}
+		}
+		w.pending = make(map[string]pendingEvent)
+		w.pendingMu.Unlock()
+
+		if ignoreChanged {
+			w.reloadIgnore()
+		}
+		w.sendBulk(count)
+		return
+	}
 	events := make([]FileEvent, 0, len(w.pending))
-	for _, event := range w.pending {
-		events = append(events, event)
+	for path, p := range w.pending {
+		if evType, ok := coalesce(p); ok {
+			events = append(events, FileEvent{Type: evType, Path: path})
+		}
 	}
-	w.pending = make(map[string]FileEvent)
+	w.pending = make(map[string]pendingEvent)
 	w.pendingMu.Unlock()
 
+	// A rename shows as the removal of the old path and the creation of the
+	// new one. Files that appeared are handled first, while the index still
+	// has the old path, so that its chunks and symbols move instead of being
+	// dropped and extracted again.
+	sort.Slice(events, func(i, j int) bool {
+		if ri, rj := isRemoval(events[i].Type), isRemoval(events[j].Type); ri != rj {
+			return rj
+		}
+		return events[i].Path < events[j].Path
+	})
+
+	for _, event := range events {
+		if event.Type == EventIgnoreChange {
+			w.reloadIgnore()
+			break
+		}
+	}
+
 	for _, event := range events {
 		select {
 		case w.events <- event:
@@ -219,6 +435,72 @@ func (w *Watcher) flush() {
 	}
 }
 
+// sendBulk reports that count paths changed at once. A single event
+// replaces the whole batch: it waits for room rather than being dropped.
+func (w *Watcher) sendBulk(count int) {
+	select {
+	case w.events <- FileEvent{Type: EventBulkChange, Count: count}:
+	case <-w.done:
+	}
+}
+
+// isRemoval reports whether an event removes its path from the index.
+func isRemoval(t EventType) bool {
+	return t == EventDelete || t == EventRename
+}
+
+// reloadIgnore reloads the ignore matcher and watches directories that are
+// no longer ignored. Newly ignored directories stay watched; their events are
+// filtered out.
+func (w *Watcher) reloadIgnore() {
+	if err := w.ignore.Reload(); err != nil {
+		log.Printf("Failed to reload ignore files: %v", err)
+		return
+	}
+	if w.watcher == nil {
+		// Watchman watches every directory
+		return
+	}
+	if err := w.addRecursive(w.root); err != nil {
+		log.Printf("Failed to watch directories: %v", err)
+	}
+}
+
+// inotifyLimitPath holds the number of inotify watches each user may add on
+// Linux, shared by all of their programs.
+const inotifyLimitPath = "/proc/sys/fs/inotify/max_user_watches"
+
+// inotifyLimit returns the inotify watch limit, when fsnotify uses inotify.
+func inotifyLimit() (int, bool) {
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+	data, err := os.ReadFile(inotifyLimitPath)
+	if err != nil {
+		return 0, false
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return limit, err == nil && limit > 0
+}
+
+// watchLimitAdvice returns the warning logged when watched directories
+// reached the inotify watch limit (exhausted) or use 80% of it, or "" when
+// there is room left. limit is 0 when unknown.
+func watchLimitAdvice(watched, limit int, exhausted bool) string {
+	var msg string
+	switch {
+	case exhausted && limit > 0:
+		msg = fmt.Sprintf("Only %d directories are watched: the inotify watch limit (fs.inotify.max_user_watches = %d) is reached and changes in other directories are missed.", watched, limit)
+	case exhausted:
+		msg = fmt.Sprintf("Only %d directories are watched: the inotify watch limit is reached and changes in other directories are missed.", watched)
+	case limit > 0 && watched*10 >= limit*8:
+		msg = fmt.Sprintf("%d directories are watched, close to the inotify watch limit (fs.inotify.max_user_watches = %d) shared by all programs of the user.", watched, limit)
+	default:
+		return ""
+	}
+	return msg + " Raise it with 'sudo sysctl fs.inotify.max_user_watches=524288' (and in /etc/sysctl.conf to keep it), or install watchman and set index.watch.backend: watchman."
+}
+
 func (e EventType) String() string {
 	switch e {
 	case EventCreate:
@@ -229,6 +511,10 @@ func (e EventType) String() string {
 		return "DELETE"
 	case EventRename:
 		return "RENAME"
+	case EventIgnoreChange:
+		return "IGNORE"
+	case EventBulkChange:
+		return "BULK"
 	default:
 		return "UNKNOWN"
 	}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []EventType
+		want   EventType
+		keep   bool
+	}{
+		{"write", []EventType{EventModify, EventModify}, EventModify, true},
+		{"new file", []EventType{EventCreate, EventModify}, EventCreate, true},
+		{"delete", []EventType{EventModify, EventDelete}, EventDelete, true},
+		{"rename away", []EventType{EventRename}, EventRename, true},
+		{"vim backup rename", []EventType{EventRename, EventCreate, EventModify}, EventModify, true},
+		{"delete and recreate", []EventType{EventDelete, EventCreate, EventModify}, EventModify, true},
+		{"temp file", []EventType{EventCreate, EventModify, EventRename}, 0, false},
+		{"created and deleted", []EventType{EventCreate, EventDelete}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Watcher{debounceMs: 60_000, pending: make(map[string]pendingEvent), events: make(chan FileEvent, 10)}
+			for _, ev := range tt.events {
+				w.debounceEvent(FileEvent{Type: ev, Path: "main.go"})
+			}
+			w.timer.Stop()
+			w.flush()
+
+			select {
+			case got := <-w.events:
+				if !tt.keep {
+					t.Fatalf("expected no event, got %s", got.Type)
+				}
+				if got.Type != tt.want || got.Path != "main.go" {
+					t.Errorf("got %s %s, want %s main.go", got.Type, got.Path, tt.want)
+				}
+			default:
+				if tt.keep {
+					t.Fatalf("expected %s, got no event", tt.want)
+				}
+			}
+			if len(w.events) != 0 {
+				t.Errorf("expected a single event, got %d more", len(w.events))
+			}
+		})
+	}
+}
+
+func TestFlush_RemovalsLast(t *testing.T) {
+	w := &Watcher{debounceMs: 60_000, pending: make(map[string]pendingEvent), events: make(chan FileEvent, 10)}
+	for _, ev := range []FileEvent{
+		{Type: EventRename, Path: "old.go"},
+		{Type: EventDelete, Path: "b.go"},
+		{Type: EventCreate, Path: "pkg/new.go"},
+		{Type: EventModify, Path: "a.go"},
+	} {
+		w.debounceEvent(ev)
+	}
+	w.timer.Stop()
+	w.flush()
+
+	want := []FileEvent{
+		{Type: EventModify, Path: "a.go"},
+		{Type: EventCreate, Path: "pkg/new.go"},
+		{Type: EventDelete, Path: "b.go"},
+		{Type: EventRename, Path: "old.go"},
+	}
+	for i, e := range want {
+		select {
+		case got := <-w.events:
+			if got != e {
+				t.Errorf("event %d = %s %s, want %s %s", i, got.Type, got.Path, e.Type, e.Path)
+			}
+		default:
+			t.Fatalf("expected %d events, got %d", len(want), i)
+		}
+	}
+}
+
+func TestFlush_BulkChange(t *testing.T) {
+	w := &Watcher{debounceMs: 60_000, bulkThreshold: 3, pending: make(map[string]pendingEvent), events: make(chan FileEvent, 10)}
+	w.debounceEvent(FileEvent{Type: EventModify, Path: "a.go"})
+	w.debounceEvent(FileEvent{Type: EventModify, Path: "a.go"})
+	w.debounceEvent(FileEvent{Type: EventCreate, Path: "b.go"})
+	if w.Settling() {
+		t.Error("expected no bulk change below the threshold")
+	}
+	w.debounceEvent(FileEvent{Type: EventDelete, Path: "c.go"})
+	if !w.Settling() {
+		t.Error("expected a bulk change at the threshold")
+	}
+	w.timer.Stop()
+	w.flush()
+
+	if w.Settling() {
+		t.Error("expected the bulk change to settle after the flush")
+	}
+	select {
+	case got := <-w.events:
+		if got.Type != EventBulkChange || got.Count != 3 {
+			t.Errorf("got %s with %d paths, want BULK with 3", got.Type, got.Count)
+		}
+	default:
+		t.Fatal("expected a bulk change event")
+	}
+	if len(w.events) != 0 {
+		t.Errorf("expected the bulk change to replace the file events, got %d more", len(w.events))
+	}
+}
+
+func TestWatchLimitAdvice(t *testing.T) {
+	if got := watchLimitAdvice(100, 8192, false); got != "" {
+		t.Errorf("expected no advice with room left, got %q", got)
+	}
+	for _, got := range []string{
+		watchLimitAdvice(7000, 8192, false),
+		watchLimitAdvice(8000, 8192, true),
+		watchLimitAdvice(8000, 0, true),
+	} {
+		if !strings.Contains(got, "fs.inotify.max_user_watches=") || !strings.Contains(got, "index.watch.backend: watchman") {
+			t.Errorf("expected sysctl and watchman guidance, got %q", got)
+		}
+	}
+}
+
+func TestNewWatcher_UnknownBackend(t *testing.T) {
+	if _, err := NewWatcher(t.TempDir(), nil, 100, 0, "inotify"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
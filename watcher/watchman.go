@@ -0,0 +1,201 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"path/filepath"
+)
+
+// watchmanSubscription names the subscription of the watcher.
+const watchmanSubscription = "agentdx"
+
+// WatchmanAvailable reports whether the watchman binary is on the PATH.
+func WatchmanAvailable() bool {
+	_, err := exec.LookPath("watchman")
+	return err == nil
+}
+
+// watchmanFile is a file of a subscription update.
+type watchmanFile struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+	New    bool   `json:"new"`
+}
+
+// eventType returns the event a change of the file is reported as.
+func (f watchmanFile) eventType() EventType {
+	switch {
+	case !f.Exists:
+		return EventDelete
+	case f.New:
+		return EventCreate
+	default:
+		return EventModify
+	}
+}
+
+// watchmanPDU is a response or subscription update read from watchman.
+type watchmanPDU struct {
+	Error           string         `json:"error"`
+	Warning         string         `json:"warning"`
+	Watch           string         `json:"watch"`
+	RelativePath    string         `json:"relative_path"`
+	Subscription    string         `json:"subscription"`
+	IsFreshInstance bool           `json:"is_fresh_instance"`
+	Canceled        bool           `json:"canceled"`
+	Files           []watchmanFile `json:"files"`
+}
+
+// watchmanSource streams the changes watchman reports under a project root.
+type watchmanSource struct {
+	cmd     *exec.Cmd
+	cancel  context.CancelFunc
+	decoder *json.Decoder
+}
+
+// startWatchman watches root with watchman and subscribes to changes of its
+// files. Watchman settles changes and crawls new directories itself, so no
+// watch is added per directory.
+func startWatchman(ctx context.Context, root string) (*watchmanSource, error) {
+	resp, err := watchmanCommand(ctx, []any{"watch-project", root})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Warning != "" {
+		log.Printf("watchman: %s", resp.Warning)
+	}
+
+	query := map[string]any{
+		"expression": []any{"type", "f"},
+		"fields":     []string{"name", "exists", "new"},
+	}
+	if resp.RelativePath != "" {
+		query["relative_root"] = resp.RelativePath
+	}
+	input, err := json.Marshal([]any{"subscribe", resp.Watch, watchmanSubscription, query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode watchman subscription: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, "watchman", "--no-pretty", "--json-command", "--persistent")
+	cmd.Stdin = bytes.NewReader(input)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start watchman: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start watchman: %w", err)
+	}
+	s := &watchmanSource{cmd: cmd, cancel: cancel, decoder: json.NewDecoder(stdout)}
+
+	// The first PDU answers the subscribe command
+	pdu, err := s.next()
+	if err == nil && pdu.Error != "" {
+		err = fmt.Errorf("watchman: %s", pdu.Error)
+	}
+	if err != nil {
+		s.close()
+		return nil, fmt.Errorf("failed to subscribe to watchman: %w", err)
+	}
+	return s, nil
+}
+
+// watchmanCommand runs a single watchman command and returns its response.
+func watchmanCommand(ctx context.Context, command []any) (watchmanPDU, error) {
+	input, err := json.Marshal(command)
+	if err != nil {
+		return watchmanPDU{}, fmt.Errorf("failed to encode watchman command: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "watchman", "--no-pretty", "--json-command")
+	cmd.Stdin = bytes.NewReader(input)
+	out, err := cmd.Output()
+	var resp watchmanPDU
+	if jsonErr := json.Unmarshal(out, &resp); jsonErr != nil {
+		if err == nil {
+			err = jsonErr
+		}
+		return resp, fmt.Errorf("failed to run watchman %v: %w", command[0], err)
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("watchman %v: %s", command[0], resp.Error)
+	}
+	return resp, nil
+}
+
+// next returns the next PDU read from watchman. It returns io.EOF once
+// watchman exited.
+func (s *watchmanSource) next() (watchmanPDU, error) {
+	var pdu watchmanPDU
+	if err := s.decoder.Decode(&pdu); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return pdu, err
+	}
+	return pdu, nil
+}
+
+// close stops the watchman client. The watchman server keeps watching the
+// project for other clients.
+func (s *watchmanSource) close() {
+	s.cancel()
+	_ = s.cmd.Wait()
+}
+
+// readWatchman delivers the updates of the watchman subscription until it
+// ends.
+func (w *Watcher) readWatchman() {
+	for {
+		pdu, err := w.watchman.next()
+		if err != nil {
+			select {
+			case <-w.done:
+			default:
+				log.Printf("watchman stopped (%v); file changes are no longer watched, restart 'agentdx watch'", err)
+			}
+			return
+		}
+		w.handleWatchmanPDU(pdu)
+	}
+}
+
+// handleWatchmanPDU reports the changes of a subscription update.
+func (w *Watcher) handleWatchmanPDU(pdu watchmanPDU) {
+	switch {
+	case pdu.Error != "":
+		log.Printf("watchman error: %s", pdu.Error)
+		return
+	case pdu.Subscription != watchmanSubscription:
+		return
+	case pdu.Canceled:
+		log.Printf("watchman canceled the subscription; file changes are no longer watched")
+		return
+	case pdu.IsFreshInstance:
+		// The first update lists every file, which the initial scan
+		// indexed. A later one follows a recrawl, after which changes may
+		// have been missed: rescan the project.
+		if w.freshSeen {
+			w.sendBulk(len(pdu.Files))
+		}
+		w.freshSeen = true
+		return
+	}
+
+	for _, f := range pdu.Files {
+		relPath := filepath.FromSlash(f.Name)
+		switch w.classify(relPath) {
+		case pathIgnoreFile:
+			w.debounceEvent(FileEvent{Type: EventIgnoreChange, Path: relPath})
+		case pathSource:
+			w.debounceEvent(FileEvent{Type: f.eventType(), Path: relPath})
+		}
+	}
+}
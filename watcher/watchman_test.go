@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/doveaia/agentdx/indexer"
+)
+
+// fakeWatchman answers watch-project with the project root and streams a
+// fresh instance followed by a subscription update.
+const fakeWatchman = `#!/bin/sh
+cat >/dev/null
+case "$*" in
+*--persistent*)
+	echo '{"version":"fake","subscribe":"agentdx","clock":"c:1"}'
+	echo '{"subscription":"agentdx","is_fresh_instance":true,"files":[{"name":"main.go","exists":true,"new":false}]}'
+	echo '{"subscription":"agentdx","files":[{"name":"pkg/new.go","exists":true,"new":true},{"name":"gone.go","exists":false,"new":false},{"name":"main.go","exists":true,"new":false},{"name":"notes.bin","exists":true,"new":true},{"name":".hidden.go","exists":true,"new":true}]}'
+	exec sleep 30
+	;;
+*)
+	echo '{"version":"fake","watch":"'"$WATCHMAN_ROOT"'"}'
+	;;
+esac
+`
+
+func TestWatcher_Watchman(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake watchman is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "watchman"), []byte(fakeWatchman), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	root := t.TempDir()
+	t.Setenv("WATCHMAN_ROOT", root)
+
+	ignore, err := indexer.NewIgnoreMatcher(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(root, ignore, 10, 0, BackendAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w.Backend() != BackendWatchman {
+		t.Fatalf("expected the watchman backend, got %s", w.Backend())
+	}
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < 3 {
+		select {
+		case ev := <-w.Events():
+			got = append(got, ev.Type.String()+" "+filepath.ToSlash(ev.Path))
+		case <-timeout:
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+	sort.Strings(got)
+	want := []string{"CREATE pkg/new.go", "DELETE gone.go", "MODIFY main.go"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWatcher_WatchmanFallback(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ignore, err := indexer.NewIgnoreMatcher(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(t.TempDir(), ignore, 10, 0, BackendWatchman)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Start(context.Background()); err == nil {
+		w.Close()
+		t.Fatal("expected an error when watchman is required but missing")
+	}
+
+	w, err = NewWatcher(t.TempDir(), ignore, 10, 0, BackendAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w.Backend() != BackendFSNotify {
+		t.Errorf("expected auto to fall back to fsnotify, got %s", w.Backend())
+	}
+}